@@ -0,0 +1,195 @@
+# ---------------------------------------------------------------------------------------------------------------------
+# PIN TERRAFORM VERSION TO >= 0.12
+# The examples have been upgraded to 0.12 syntax
+# ---------------------------------------------------------------------------------------------------------------------
+
+terraform {
+  # This module is now only being tested with Terraform 0.13.x. However, to make upgrading easier, we are setting
+  # 0.12.26 as the minimum version, as that version added support for required_providers with source URLs, making it
+  # forwards compatible with 0.13.x code.
+  required_version = ">= 0.12.26"
+
+  required_providers {
+    tls = {
+      source  = "hashicorp/tls"
+      version = "~> 3.1"
+    }
+  }
+}
+
+provider "aws" {
+  region = var.region
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# CREATE A CERTIFICATE AUTHORITY AND THE SERVER AND CLIENT CERTIFICATES IT ISSUES
+# Client VPN uses mutual authentication: the server certificate is offered to the clients, and clients must present a
+# certificate issued by the same CA.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "tls_private_key" "ca" {
+  algorithm = "RSA"
+}
+
+resource "tls_self_signed_cert" "ca" {
+  key_algorithm         = tls_private_key.ca.algorithm
+  private_key_pem       = tls_private_key.ca.private_key_pem
+  is_ca_certificate     = true
+  validity_period_hours = 24
+
+  subject {
+    common_name = "${var.name}.ca.terratest.example"
+  }
+
+  allowed_uses = [
+    "cert_signing",
+    "crl_signing",
+  ]
+}
+
+resource "tls_private_key" "server" {
+  algorithm = "RSA"
+}
+
+resource "tls_cert_request" "server" {
+  key_algorithm   = tls_private_key.server.algorithm
+  private_key_pem = tls_private_key.server.private_key_pem
+  dns_names       = ["${var.name}.server.terratest.example"]
+
+  subject {
+    common_name = "${var.name}.server.terratest.example"
+  }
+}
+
+resource "tls_locally_signed_cert" "server" {
+  cert_request_pem      = tls_cert_request.server.cert_request_pem
+  ca_key_algorithm      = tls_private_key.ca.algorithm
+  ca_private_key_pem    = tls_private_key.ca.private_key_pem
+  ca_cert_pem           = tls_self_signed_cert.ca.cert_pem
+  validity_period_hours = 24
+
+  allowed_uses = [
+    "key_encipherment",
+    "digital_signature",
+    "server_auth",
+  ]
+}
+
+resource "tls_private_key" "client" {
+  algorithm = "RSA"
+}
+
+resource "tls_cert_request" "client" {
+  key_algorithm   = tls_private_key.client.algorithm
+  private_key_pem = tls_private_key.client.private_key_pem
+  dns_names       = ["${var.name}.client.terratest.example"]
+
+  subject {
+    common_name = "${var.name}.client.terratest.example"
+  }
+}
+
+resource "tls_locally_signed_cert" "client" {
+  cert_request_pem      = tls_cert_request.client.cert_request_pem
+  ca_key_algorithm      = tls_private_key.ca.algorithm
+  ca_private_key_pem    = tls_private_key.ca.private_key_pem
+  ca_cert_pem           = tls_self_signed_cert.ca.cert_pem
+  validity_period_hours = 24
+
+  allowed_uses = [
+    "key_encipherment",
+    "digital_signature",
+    "client_auth",
+  ]
+}
+
+resource "aws_acm_certificate" "server" {
+  private_key       = tls_private_key.server.private_key_pem
+  certificate_body  = tls_locally_signed_cert.server.cert_pem
+  certificate_chain = tls_self_signed_cert.ca.cert_pem
+}
+
+resource "aws_acm_certificate" "client" {
+  private_key       = tls_private_key.client.private_key_pem
+  certificate_body  = tls_locally_signed_cert.client.cert_pem
+  certificate_chain = tls_self_signed_cert.ca.cert_pem
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# DEPLOY THE CLIENT VPN ENDPOINT INTO THE DEFAULT VPC
+# Split tunneling is enabled, so that only traffic to the VPC goes through the VPN.
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "aws_vpc" "default" {
+  default = true
+}
+
+data "aws_subnet_ids" "default" {
+  vpc_id = data.aws_vpc.default.id
+}
+
+resource "aws_ec2_client_vpn_endpoint" "example" {
+  description            = var.name
+  server_certificate_arn = aws_acm_certificate.server.arn
+  client_cidr_block      = var.client_cidr_block
+  split_tunnel           = true
+
+  authentication_options {
+    type                       = "certificate-authentication"
+    root_certificate_chain_arn = aws_acm_certificate.client.arn
+  }
+
+  connection_log_options {
+    enabled = false
+  }
+}
+
+resource "aws_ec2_client_vpn_network_association" "example" {
+  client_vpn_endpoint_id = aws_ec2_client_vpn_endpoint.example.id
+  subnet_id              = tolist(data.aws_subnet_ids.default.ids)[0]
+}
+
+resource "aws_ec2_client_vpn_authorization_rule" "example" {
+  client_vpn_endpoint_id = aws_ec2_client_vpn_endpoint.example.id
+  target_network_cidr    = data.aws_vpc.default.cidr_block
+  authorize_all_groups   = true
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# DEPLOY A PRIVATE RESOURCE TO REACH OVER THE VPN
+# Traffic from VPN clients comes from the network interface of the endpoint in the associated subnet, so allow SSH from
+# the VPC only.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "aws_security_group" "example" {
+  name_prefix = var.name
+  vpc_id      = data.aws_vpc.default.id
+
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = [data.aws_vpc.default.cidr_block]
+  }
+}
+
+data "aws_ami" "amazon_linux_2" {
+  most_recent = true
+  owners      = ["amazon"]
+
+  filter {
+    name   = "name"
+    values = ["amzn2-ami-hvm*"]
+  }
+}
+
+resource "aws_instance" "example" {
+  ami                    = data.aws_ami.amazon_linux_2.id
+  instance_type          = var.instance_type
+  subnet_id              = tolist(data.aws_subnet_ids.default.ids)[0]
+  vpc_security_group_ids = [aws_security_group.example.id]
+
+  tags = {
+    Name = var.name
+  }
+}
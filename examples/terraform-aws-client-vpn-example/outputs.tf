@@ -0,0 +1,20 @@
+output "client_vpn_endpoint_id" {
+  value = aws_ec2_client_vpn_endpoint.example.id
+}
+
+output "client_certificate_pem" {
+  value = tls_locally_signed_cert.client.cert_pem
+}
+
+output "client_private_key_pem" {
+  value     = tls_private_key.client.private_key_pem
+  sensitive = true
+}
+
+output "instance_private_ip" {
+  value = aws_instance.example.private_ip
+}
+
+output "vpc_cidr_block" {
+  value = data.aws_vpc.default.cidr_block
+}
@@ -0,0 +1,41 @@
+# ---------------------------------------------------------------------------------------------------------------------
+# ENVIRONMENT VARIABLES
+# Define these secrets as environment variables
+# ---------------------------------------------------------------------------------------------------------------------
+
+# AWS_ACCESS_KEY_ID
+# AWS_SECRET_ACCESS_KEY
+
+# ---------------------------------------------------------------------------------------------------------------------
+# REQUIRED PARAMETERS
+# You must provide a value for each of these parameters.
+# ---------------------------------------------------------------------------------------------------------------------
+
+# ---------------------------------------------------------------------------------------------------------------------
+# OPTIONAL PARAMETERS
+# These parameters have reasonable defaults.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "region" {
+  type        = string
+  description = "The AWS region to deploy into"
+  default     = "us-east-1"
+}
+
+variable "name" {
+  description = "The name used for the resources and certificates of the example."
+  type        = string
+  default     = "terratest-client-vpn"
+}
+
+variable "client_cidr_block" {
+  description = "The CIDR block to assign VPN client IPs from. Must not overlap with the CIDR block of the default VPC."
+  type        = string
+  default     = "10.100.0.0/22"
+}
+
+variable "instance_type" {
+  description = "The EC2 instance type to run."
+  type        = string
+  default     = "t2.micro"
+}
@@ -0,0 +1,90 @@
+// Package ansible allows to validate and run Ansible playbooks, e.g. against the instances deployed by a test.
+package ansible
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// Options are the options for running ansible-playbook.
+type Options struct {
+	Playbook   string            // The path to the playbook to run
+	Inventory  string            // The path to an inventory file, or a comma separated list of hosts. Ignored by the *OnHosts functions.
+	ExtraVars  map[string]string // Variables to pass to the playbook using the --extra-vars option
+	Limit      string            // If specified, only run the playbook against the hosts matching this pattern
+	Tags       []string          // If specified, only run the tasks with these tags
+	SkipTags   []string          // If specified, skip the tasks with these tags
+	Become     bool              // If set, run the tasks with privilege escalation (--become)
+	ExtraArgs  []string          // Extra arguments to pass to ansible-playbook as-is
+	Env        map[string]string // Custom environment variables to set when running ansible-playbook
+	WorkingDir string            // The directory to run ansible-playbook in
+	Logger     *logger.Logger    // If set, use a non-default logger
+}
+
+// RunAnsiblePlaybookCommandE runs ansible-playbook with the playbook and flags in the given options, followed by the
+// given additional arguments, and returns the combined stdout/stderr.
+func RunAnsiblePlaybookCommandE(t testing.TestingT, options *Options, additionalArgs ...string) (string, error) {
+	cmd := shell.Command{
+		Command:    "ansible-playbook",
+		Args:       append(formatPlaybookArgs(options), additionalArgs...),
+		Env:        ansibleEnv(options),
+		WorkingDir: options.WorkingDir,
+		Logger:     options.Logger,
+	}
+
+	return shell.RunCommandAndGetOutputE(t, cmd)
+}
+
+// formatPlaybookArgs converts the options into arguments for ansible-playbook. The command has the format:
+//
+// ansible-playbook [OPTIONS] playbook
+func formatPlaybookArgs(options *Options) []string {
+	args := []string{}
+
+	if options.Inventory != "" {
+		args = append(args, "--inventory", options.Inventory)
+	}
+
+	keys := make([]string, 0, len(options.ExtraVars))
+	for key := range options.ExtraVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--extra-vars", fmt.Sprintf("%s=%s", key, options.ExtraVars[key]))
+	}
+
+	if options.Limit != "" {
+		args = append(args, "--limit", options.Limit)
+	}
+
+	for _, tag := range options.Tags {
+		args = append(args, "--tags", tag)
+	}
+
+	for _, tag := range options.SkipTags {
+		args = append(args, "--skip-tags", tag)
+	}
+
+	if options.Become {
+		args = append(args, "--become")
+	}
+
+	args = append(args, options.ExtraArgs...)
+
+	return append(args, options.Playbook)
+}
+
+// ansibleEnv returns the environment to run ansible-playbook with. Host key checking is disabled by default since test
+// instances are freshly created with unknown host keys.
+func ansibleEnv(options *Options) map[string]string {
+	env := map[string]string{"ANSIBLE_HOST_KEY_CHECKING": "False"}
+	for key, value := range options.Env {
+		env[key] = value
+	}
+	return env
+}
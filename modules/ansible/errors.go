@@ -0,0 +1,37 @@
+package ansible
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PlaybookFailedError is returned when the PLAY RECAP of a playbook run reports failed or unreachable hosts.
+type PlaybookFailedError struct {
+	Hosts []string
+}
+
+func (err PlaybookFailedError) Error() string {
+	return fmt.Sprintf("Playbook failed on hosts %s", strings.Join(err.Hosts, ", "))
+}
+
+// PlaybookNotIdempotentError is returned when a second run of a playbook still changes hosts.
+type PlaybookNotIdempotentError struct {
+	ChangedTasks map[string]int // The number of changed tasks per host
+}
+
+func (err PlaybookNotIdempotentError) Error() string {
+	hosts := []string{}
+	for host, changed := range err.ChangedTasks {
+		hosts = append(hosts, fmt.Sprintf("%s (%d changed)", host, changed))
+	}
+	sort.Strings(hosts)
+	return fmt.Sprintf("Playbook is not idempotent, the second run changed hosts %s", strings.Join(hosts, ", "))
+}
+
+// PlayRecapNotFoundError is returned when the output of ansible-playbook does not contain a PLAY RECAP.
+type PlayRecapNotFoundError struct{}
+
+func (err PlayRecapNotFoundError) Error() string {
+	return "Could not find the PLAY RECAP in the ansible-playbook output"
+}
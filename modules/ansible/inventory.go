@@ -0,0 +1,54 @@
+package ansible
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// writeInventoryE writes an INI inventory for the given hosts to the given directory, together with the private keys
+// of their key pairs, and returns its path. Each host is named after its Hostname and uses the same authentication
+// methods as the ssh package: the key pair, an in-process SSH agent, or a password (which requires sshpass). With
+// SshAgent set, Ansible uses the agent of the current environment.
+func writeInventoryE(dir string, hosts []ssh.Host) (string, error) {
+	lines := []string{"[all]"}
+
+	for i, host := range hosts {
+		vars := []string{
+			fmt.Sprintf("ansible_host=%s", host.Hostname),
+			fmt.Sprintf("ansible_user=%s", host.SshUserName),
+		}
+
+		if host.CustomPort != 0 {
+			vars = append(vars, fmt.Sprintf("ansible_port=%d", host.CustomPort))
+		}
+
+		if host.SshKeyPair != nil {
+			keyPath := filepath.Join(dir, fmt.Sprintf("host-%d.pem", i))
+			if err := ioutil.WriteFile(keyPath, []byte(host.SshKeyPair.PrivateKey), 0600); err != nil {
+				return "", err
+			}
+			vars = append(vars, fmt.Sprintf("ansible_ssh_private_key_file=%s", keyPath))
+		}
+
+		if host.OverrideSshAgent != nil {
+			vars = append(vars, fmt.Sprintf("ansible_ssh_common_args='-o IdentityAgent=%s'", host.OverrideSshAgent.SocketFile()))
+		}
+
+		if host.Password != "" {
+			vars = append(vars, fmt.Sprintf("ansible_password=%q", host.Password))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s", host.Hostname, strings.Join(vars, " ")))
+	}
+
+	inventoryPath := filepath.Join(dir, "inventory.ini")
+	if err := ioutil.WriteFile(inventoryPath, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return "", err
+	}
+
+	return inventoryPath, nil
+}
@@ -0,0 +1,58 @@
+package ansible
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteInventory(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "terratest-ansible-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	hosts := []ssh.Host{
+		{Hostname: "10.0.0.1", SshUserName: "ubuntu", SshKeyPair: &ssh.KeyPair{PrivateKey: "private-key"}},
+		{Hostname: "10.0.0.2", SshUserName: "admin", CustomPort: 2222, Password: "pass word"},
+	}
+
+	inventoryPath, err := writeInventoryE(dir, hosts)
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(inventoryPath)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(dir, "host-0.pem")
+	assert.Equal(t, strings.Join([]string{
+		"[all]",
+		"10.0.0.1 ansible_host=10.0.0.1 ansible_user=ubuntu ansible_ssh_private_key_file=" + keyPath,
+		`10.0.0.2 ansible_host=10.0.0.2 ansible_user=admin ansible_port=2222 ansible_password="pass word"`,
+		"",
+	}, "\n"), string(content))
+
+	key, err := ioutil.ReadFile(keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, "private-key", string(key))
+}
+
+func TestFormatPlaybookArgs(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{
+		Playbook:  "site.yml",
+		Inventory: "hosts.ini",
+		ExtraVars: map[string]string{"version": "1.2.3", "env": "test"},
+		Tags:      []string{"web"},
+		Become:    true,
+	}
+
+	assert.Equal(t, "--inventory hosts.ini --extra-vars env=test --extra-vars version=1.2.3 --tags web --become site.yml", strings.Join(formatPlaybookArgs(options), " "))
+}
@@ -0,0 +1,129 @@
+package ansible
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// SyntaxCheckPlaybook runs 'ansible-playbook --syntax-check' on the playbook in the given options and returns the
+// output. This will fail the test if the playbook is invalid.
+func SyntaxCheckPlaybook(t testing.TestingT, options *Options) string {
+	out, err := SyntaxCheckPlaybookE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// SyntaxCheckPlaybookE runs 'ansible-playbook --syntax-check' on the playbook in the given options and returns the
+// output.
+func SyntaxCheckPlaybookE(t testing.TestingT, options *Options) (string, error) {
+	options.Logger.Logf(t, "Checking the syntax of playbook %s", options.Playbook)
+	return RunAnsiblePlaybookCommandE(t, options, "--syntax-check")
+}
+
+// CheckPlaybook runs the playbook in the given options in check mode ('ansible-playbook --check --diff'), which reports
+// what would change without changing anything, and returns the PLAY RECAP of each host. This will fail the test if
+// there is an error or any host fails.
+func CheckPlaybook(t testing.TestingT, options *Options) map[string]PlayRecap {
+	recaps, err := CheckPlaybookE(t, options)
+	require.NoError(t, err)
+	return recaps
+}
+
+// CheckPlaybookE runs the playbook in the given options in check mode ('ansible-playbook --check --diff'), which
+// reports what would change without changing anything, and returns the PLAY RECAP of each host.
+func CheckPlaybookE(t testing.TestingT, options *Options) (map[string]PlayRecap, error) {
+	options.Logger.Logf(t, "Running playbook %s in check mode", options.Playbook)
+	return runPlaybookAndParseRecapE(t, options, "--check", "--diff")
+}
+
+// RunPlaybook runs the playbook in the given options and returns the PLAY RECAP of each host. This will fail the test
+// if there is an error or any host fails.
+func RunPlaybook(t testing.TestingT, options *Options) map[string]PlayRecap {
+	recaps, err := RunPlaybookE(t, options)
+	require.NoError(t, err)
+	return recaps
+}
+
+// RunPlaybookE runs the playbook in the given options and returns the PLAY RECAP of each host.
+func RunPlaybookE(t testing.TestingT, options *Options) (map[string]PlayRecap, error) {
+	options.Logger.Logf(t, "Running playbook %s", options.Playbook)
+	return runPlaybookAndParseRecapE(t, options)
+}
+
+// RunPlaybookOnHosts runs the playbook in the given options against the given hosts, using the same connection
+// information as the ssh package, and returns the PLAY RECAP of each host, keyed by Hostname. The Inventory of the
+// options is ignored. This will fail the test if there is an error or any host fails.
+func RunPlaybookOnHosts(t testing.TestingT, options *Options, hosts []ssh.Host) map[string]PlayRecap {
+	recaps, err := RunPlaybookOnHostsE(t, options, hosts)
+	require.NoError(t, err)
+	return recaps
+}
+
+// RunPlaybookOnHostsE runs the playbook in the given options against the given hosts, using the same connection
+// information as the ssh package, and returns the PLAY RECAP of each host, keyed by Hostname. The Inventory of the
+// options is ignored.
+func RunPlaybookOnHostsE(t testing.TestingT, options *Options, hosts []ssh.Host) (map[string]PlayRecap, error) {
+	dir, err := ioutil.TempDir("", "terratest-ansible-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	inventoryPath, err := writeInventoryE(dir, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	hostOptions := *options
+	hostOptions.Inventory = inventoryPath
+	return RunPlaybookE(t, &hostOptions)
+}
+
+// AssertPlaybookIdempotentOnHosts runs the playbook in the given options against the given hosts twice and checks
+// that the second run does not change any host. This will fail the test if either run fails or the second run changes
+// anything.
+func AssertPlaybookIdempotentOnHosts(t testing.TestingT, options *Options, hosts []ssh.Host) {
+	require.NoError(t, AssertPlaybookIdempotentOnHostsE(t, options, hosts))
+}
+
+// AssertPlaybookIdempotentOnHostsE runs the playbook in the given options against the given hosts twice and checks
+// that the second run does not change any host.
+func AssertPlaybookIdempotentOnHostsE(t testing.TestingT, options *Options, hosts []ssh.Host) error {
+	if _, err := RunPlaybookOnHostsE(t, options, hosts); err != nil {
+		return err
+	}
+
+	recaps, err := RunPlaybookOnHostsE(t, options, hosts)
+	if err != nil {
+		return err
+	}
+
+	if changed := changedTasks(recaps); len(changed) > 0 {
+		return PlaybookNotIdempotentError{ChangedTasks: changed}
+	}
+	return nil
+}
+
+// runPlaybookAndParseRecapE runs the playbook with the given additional arguments and parses its PLAY RECAP, returning
+// a PlaybookFailedError if any host failed.
+func runPlaybookAndParseRecapE(t testing.TestingT, options *Options, additionalArgs ...string) (map[string]PlayRecap, error) {
+	out, runErr := RunAnsiblePlaybookCommandE(t, options, additionalArgs...)
+
+	recaps, err := ParsePlayRecap(out)
+	if err != nil {
+		// Without a recap, the playbook did not run at all, e.g. because of a syntax error
+		if runErr != nil {
+			return nil, runErr
+		}
+		return nil, err
+	}
+
+	if hosts := failedHosts(recaps); len(hosts) > 0 {
+		return recaps, PlaybookFailedError{Hosts: hosts}
+	}
+	return recaps, runErr
+}
@@ -0,0 +1,89 @@
+package ansible
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PlayRecap is the summary of a playbook run for a single host, as printed in the PLAY RECAP section of the output.
+type PlayRecap struct {
+	Ok          int
+	Changed     int
+	Unreachable int
+	Failed      int
+	Skipped     int
+	Rescued     int
+	Ignored     int
+}
+
+// A line of the PLAY RECAP, e.g. "web1 : ok=3    changed=1    unreachable=0    failed=0    skipped=0"
+var playRecapLineRegexp = regexp.MustCompile(`^(\S+)\s+:\s+((?:\w+=\d+\s*)+)$`)
+
+// ParsePlayRecap parses the PLAY RECAP section of the given ansible-playbook output into a map of host to PlayRecap.
+func ParsePlayRecap(output string) (map[string]PlayRecap, error) {
+	start := strings.LastIndex(output, "PLAY RECAP")
+	if start < 0 {
+		return nil, PlayRecapNotFoundError{}
+	}
+
+	recaps := map[string]PlayRecap{}
+	for _, line := range strings.Split(output[start:], "\n")[1:] {
+		matches := playRecapLineRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		recap := PlayRecap{}
+		for _, counter := range strings.Fields(matches[2]) {
+			parts := strings.SplitN(counter, "=", 2)
+			value, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			switch parts[0] {
+			case "ok":
+				recap.Ok = value
+			case "changed":
+				recap.Changed = value
+			case "unreachable":
+				recap.Unreachable = value
+			case "failed":
+				recap.Failed = value
+			case "skipped":
+				recap.Skipped = value
+			case "rescued":
+				recap.Rescued = value
+			case "ignored":
+				recap.Ignored = value
+			}
+		}
+		recaps[matches[1]] = recap
+	}
+
+	return recaps, nil
+}
+
+// failedHosts returns the sorted hosts that failed or were unreachable in the given recaps.
+func failedHosts(recaps map[string]PlayRecap) []string {
+	hosts := []string{}
+	for host, recap := range recaps {
+		if recap.Failed > 0 || recap.Unreachable > 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// changedTasks returns the number of changed tasks of each host that was changed in the given recaps.
+func changedTasks(recaps map[string]PlayRecap) map[string]int {
+	changed := map[string]int{}
+	for host, recap := range recaps {
+		if recap.Changed > 0 {
+			changed[host] = recap.Changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,41 @@
+package ansible
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const examplePlaybookOutput = `
+PLAY [all] *********************************************************************
+
+TASK [Install nginx] ***********************************************************
+changed: [10.0.0.1]
+fatal: [10.0.0.2]: UNREACHABLE! => {"changed": false, "unreachable": true}
+
+PLAY RECAP *********************************************************************
+10.0.0.1                   : ok=2    changed=1    unreachable=0    failed=0    skipped=1    rescued=0    ignored=0
+10.0.0.2                   : ok=0    changed=0    unreachable=1    failed=0    skipped=0    rescued=0    ignored=0
+`
+
+func TestParsePlayRecap(t *testing.T) {
+	t.Parallel()
+
+	recaps, err := ParsePlayRecap(examplePlaybookOutput)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]PlayRecap{
+		"10.0.0.1": {Ok: 2, Changed: 1, Skipped: 1},
+		"10.0.0.2": {Unreachable: 1},
+	}, recaps)
+	assert.Equal(t, []string{"10.0.0.2"}, failedHosts(recaps))
+	assert.Equal(t, map[string]int{"10.0.0.1": 1}, changedTasks(recaps))
+}
+
+func TestParsePlayRecapNoRecapPresent(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParsePlayRecap("ERROR! the playbook: site.yml could not be found")
+	assert.Equal(t, PlayRecapNotFoundError{}, err)
+}
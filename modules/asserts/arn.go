@@ -0,0 +1,42 @@
+package asserts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertValidArnOfService checks that the given value is a well formed ARN of the given AWS service (e.g. "iam",
+// "s3" or "lambda"), with a partition and a resource.
+func AssertValidArnOfService(t testing.TestingT, value string, service string) {
+	require.NoError(t, AssertValidArnOfServiceE(value, service))
+}
+
+// AssertValidArnOfServiceE checks that the given value is a well formed ARN of the given AWS service (e.g. "iam",
+// "s3" or "lambda"), with a partition and a resource.
+func AssertValidArnOfServiceE(value string, service string) error {
+	parsed, err := arn.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid ARN, expected the format arn:partition:service:region:account-id:resource: %s", value, err)
+	}
+
+	problems := []string{}
+	if parsed.Partition == "" {
+		problems = append(problems, "the partition is empty")
+	}
+	if parsed.Service != service {
+		problems = append(problems, fmt.Sprintf("the service is %q instead of %q", parsed.Service, service))
+	}
+	if parsed.Resource == "" {
+		problems = append(problems, "the resource is empty")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%q is not a valid ARN of service %s: %s", value, service, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,3 @@
+// Package asserts provides assertions for values that are common in infrastructure tests, such as CIDR blocks, IP
+// addresses, ARNs, versions and JSON documents, with failure messages that explain why the value does not match.
+package asserts
@@ -0,0 +1,54 @@
+package asserts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertCidrContains(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, AssertCidrContainsE("10.0.0.0/16", "10.0.1.0/24"))
+	assert.NoError(t, AssertCidrContainsE("10.0.0.0/16", "10.0.255.255"))
+	assert.EqualError(t, AssertCidrContainsE("10.0.0.0/16", "10.1.0.0/24"), "CIDR block 10.0.0.0/16 (10.0.0.0 - 10.0.255.255) does not contain 10.1.0.0/24 (10.1.0.0 - 10.1.0.255)")
+	assert.Error(t, AssertCidrContainsE("10.0.0.0/16", "10.0.0.0/8"))
+	assert.Error(t, AssertCidrContainsE("not-a-cidr", "10.0.0.1"))
+}
+
+func TestAssertIpInSubnet(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, AssertIpInSubnetE("10.0.1.17", "10.0.1.0/24"))
+	assert.EqualError(t, AssertIpInSubnetE("10.0.2.17", "10.0.1.0/24"), "IP address 10.0.2.17 is not in subnet 10.0.1.0/24, which ranges from 10.0.1.0 to 10.0.1.255")
+	assert.Error(t, AssertIpInSubnetE("10.0.1", "10.0.1.0/24"))
+}
+
+func TestAssertValidArnOfService(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, AssertValidArnOfServiceE("arn:aws:iam::123456789012:role/example", "iam"))
+	assert.NoError(t, AssertValidArnOfServiceE("arn:aws-us-gov:s3:::my-bucket", "s3"))
+	assert.EqualError(t, AssertValidArnOfServiceE("arn:aws:iam::123456789012:", "lambda"), `"arn:aws:iam::123456789012:" is not a valid ARN of service lambda: the service is "iam" instead of "lambda", the resource is empty`)
+	assert.Error(t, AssertValidArnOfServiceE("role/example", "iam"))
+}
+
+func TestAssertSemverConstraint(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, AssertSemverConstraintE("v1.2.3", ">= 1.2, < 2.0"))
+	assert.NoError(t, AssertSemverConstraintE("1.2.9", "~> 1.2.0"))
+	assert.EqualError(t, AssertSemverConstraintE("2.0.0", ">= 1.2, < 2.0"), `Version 2.0.0 does not satisfy ">= 1.2, < 2.0": it fails "< 2.0"`)
+	assert.Error(t, AssertSemverConstraintE("latest", ">= 1.0"))
+}
+
+func TestAssertJsonPathEquals(t *testing.T) {
+	t.Parallel()
+
+	policy := []byte(`{"Statement": [{"Effect": "Allow", "Action": ["s3:GetObject", "s3:ListBucket"], "Priority": 1}]}`)
+
+	assert.NoError(t, AssertJsonPathEqualsE(t, policy, "{.Statement[0].Effect}", "Allow"))
+	assert.NoError(t, AssertJsonPathEqualsE(t, policy, "{.Statement[0].Priority}", 1))
+	assert.NoError(t, AssertJsonPathEqualsE(t, policy, "{.Statement[0].Action[*]}", []string{"s3:GetObject", "s3:ListBucket"}))
+	assert.EqualError(t, AssertJsonPathEqualsE(t, policy, "{.Statement[0].Effect}", "Deny"), `JSONPath {.Statement[0].Effect} is "Allow", expected "Deny"`)
+}
@@ -0,0 +1,54 @@
+package asserts
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertJsonPathEquals checks that the value at the given JSONPath (e.g. "{.Statement[0].Effect}") of the given JSON
+// document, such as an IAM policy or a Terraform output, equals the expected value. Values are compared after
+// converting the expected value to JSON, so numbers can be given as any Go numeric type. If the path matches several
+// values, expected must be a slice of all of them. See k8s.UnmarshalJSONPath for the JSONPath syntax.
+func AssertJsonPathEquals(t testing.TestingT, jsonData []byte, jsonPath string, expected interface{}) {
+	require.NoError(t, AssertJsonPathEqualsE(t, jsonData, jsonPath, expected))
+}
+
+// AssertJsonPathEqualsE checks that the value at the given JSONPath (e.g. "{.Statement[0].Effect}") of the given JSON
+// document, such as an IAM policy or a Terraform output, equals the expected value. Values are compared after
+// converting the expected value to JSON, so numbers can be given as any Go numeric type. If the path matches several
+// values, expected must be a slice of all of them. See k8s.UnmarshalJSONPath for the JSONPath syntax.
+func AssertJsonPathEqualsE(t testing.TestingT, jsonData []byte, jsonPath string, expected interface{}) error {
+	var matches []interface{}
+	if err := k8s.UnmarshalJSONPathE(t, jsonData, jsonPath, &matches); err != nil {
+		return err
+	}
+
+	var actual interface{} = matches
+	if len(matches) == 1 {
+		actual = matches[0]
+	}
+
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		return err
+	}
+	var normalizedExpected interface{}
+	if err := json.Unmarshal(expectedJSON, &normalizedExpected); err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(normalizedExpected, actual) {
+		actualJSON, _ := json.Marshal(actual)
+		if len(matches) == 0 {
+			return fmt.Errorf("JSONPath %s matched nothing, expected %s", jsonPath, expectedJSON)
+		}
+		return fmt.Errorf("JSONPath %s is %s, expected %s", jsonPath, actualJSON, expectedJSON)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package asserts
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertCidrContains checks that the given CIDR block (e.g. "10.0.0.0/16") contains the given IP address or CIDR
+// block (e.g. "10.0.1.0/24"), which is useful to check that subnets are carved out of their VPC.
+func AssertCidrContains(t testing.TestingT, cidr string, ipOrCidr string) {
+	require.NoError(t, AssertCidrContainsE(cidr, ipOrCidr))
+}
+
+// AssertCidrContainsE checks that the given CIDR block (e.g. "10.0.0.0/16") contains the given IP address or CIDR
+// block (e.g. "10.0.1.0/24"), which is useful to check that subnets are carved out of their VPC.
+func AssertCidrContainsE(cidr string, ipOrCidr string) error {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid CIDR block: %s", cidr, err)
+	}
+
+	if !strings.Contains(ipOrCidr, "/") {
+		return AssertIpInSubnetE(ipOrCidr, cidr)
+	}
+
+	_, inner, err := net.ParseCIDR(ipOrCidr)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid CIDR block: %s", ipOrCidr, err)
+	}
+
+	outerSize, _ := network.Mask.Size()
+	innerSize, _ := inner.Mask.Size()
+	if !network.Contains(inner.IP) || innerSize < outerSize {
+		first, last := cidrRange(network)
+		innerFirst, innerLast := cidrRange(inner)
+		return fmt.Errorf("CIDR block %s (%s - %s) does not contain %s (%s - %s)", network, first, last, inner, innerFirst, innerLast)
+	}
+
+	return nil
+}
+
+// AssertIpInSubnet checks that the given IP address is within the given subnet CIDR block.
+func AssertIpInSubnet(t testing.TestingT, ip string, subnetCidr string) {
+	require.NoError(t, AssertIpInSubnetE(ip, subnetCidr))
+}
+
+// AssertIpInSubnetE checks that the given IP address is within the given subnet CIDR block.
+func AssertIpInSubnetE(ip string, subnetCidr string) error {
+	_, subnet, err := net.ParseCIDR(subnetCidr)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid CIDR block: %s", subnetCidr, err)
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Errorf("%q is not a valid IP address", ip)
+	}
+
+	if !subnet.Contains(parsed) {
+		first, last := cidrRange(subnet)
+		return fmt.Errorf("IP address %s is not in subnet %s, which ranges from %s to %s", ip, subnet, first, last)
+	}
+
+	return nil
+}
+
+// cidrRange returns the first and last addresses of the given network.
+func cidrRange(network *net.IPNet) (net.IP, net.IP) {
+	first := network.IP.Mask(network.Mask)
+	last := make(net.IP, len(first))
+	for i := range first {
+		last[i] = first[i] | ^network.Mask[i]
+	}
+	return first, last
+}
@@ -0,0 +1,38 @@
+package asserts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertSemverConstraint checks that the given version (e.g. "1.2.3" or "v1.2.3") satisfies the given constraint
+// (e.g. ">= 1.2, < 2.0" or "~> 1.2").
+func AssertSemverConstraint(t testing.TestingT, versionString string, constraint string) {
+	require.NoError(t, AssertSemverConstraintE(versionString, constraint))
+}
+
+// AssertSemverConstraintE checks that the given version (e.g. "1.2.3" or "v1.2.3") satisfies the given constraint
+// (e.g. ">= 1.2, < 2.0" or "~> 1.2").
+func AssertSemverConstraintE(versionString string, constraint string) error {
+	parsedVersion, err := version.NewVersion(versionString)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid version: %s", versionString, err)
+	}
+
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid version constraint: %s", constraint, err)
+	}
+
+	for _, single := range constraints {
+		if !single.Check(parsedVersion) {
+			return fmt.Errorf("Version %s does not satisfy %q: it fails %q", parsedVersion, constraint, strings.TrimSpace(single.String()))
+		}
+	}
+
+	return nil
+}
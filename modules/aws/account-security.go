@@ -0,0 +1,391 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/guardduty"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3control"
+	"github.com/aws/aws-sdk-go/service/securityhub"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// GetS3AccountPublicAccessBlock returns the account-wide S3 public access block configuration of the current account.
+func GetS3AccountPublicAccessBlock(t testing.TestingT) *s3control.PublicAccessBlockConfiguration {
+	config, err := GetS3AccountPublicAccessBlockE(t)
+	require.NoError(t, err)
+	return config
+}
+
+// GetS3AccountPublicAccessBlockE returns the account-wide S3 public access block configuration of the current account.
+// If the account has no public access block configured, a configuration with all settings disabled is returned.
+func GetS3AccountPublicAccessBlockE(t testing.TestingT) (*s3control.PublicAccessBlockConfiguration, error) {
+	accountID, err := GetAccountIdE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := NewS3ControlClientE(t, defaultRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.GetPublicAccessBlock(&s3control.GetPublicAccessBlockInput{AccountId: aws.String(accountID)})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == s3control.ErrCodeNoSuchPublicAccessBlockConfiguration {
+			return &s3control.PublicAccessBlockConfiguration{
+				BlockPublicAcls:       aws.Bool(false),
+				BlockPublicPolicy:     aws.Bool(false),
+				IgnorePublicAcls:      aws.Bool(false),
+				RestrictPublicBuckets: aws.Bool(false),
+			}, nil
+		}
+		return nil, err
+	}
+
+	return output.PublicAccessBlockConfiguration, nil
+}
+
+// AssertS3AccountPublicAccessBlocked checks that all four account-wide S3 public access block settings are enabled.
+func AssertS3AccountPublicAccessBlocked(t testing.TestingT) {
+	err := AssertS3AccountPublicAccessBlockedE(t)
+	require.NoError(t, err)
+}
+
+// AssertS3AccountPublicAccessBlockedE checks that all four account-wide S3 public access block settings are enabled
+// and returns an error if any of them is not.
+func AssertS3AccountPublicAccessBlockedE(t testing.TestingT) error {
+	config, err := GetS3AccountPublicAccessBlockE(t)
+	if err != nil {
+		return err
+	}
+
+	disabled := []string{}
+	if !aws.BoolValue(config.BlockPublicAcls) {
+		disabled = append(disabled, "BlockPublicAcls")
+	}
+	if !aws.BoolValue(config.BlockPublicPolicy) {
+		disabled = append(disabled, "BlockPublicPolicy")
+	}
+	if !aws.BoolValue(config.IgnorePublicAcls) {
+		disabled = append(disabled, "IgnorePublicAcls")
+	}
+	if !aws.BoolValue(config.RestrictPublicBuckets) {
+		disabled = append(disabled, "RestrictPublicBuckets")
+	}
+
+	if len(disabled) > 0 {
+		return fmt.Errorf("The following S3 account public access block settings are disabled: %s", strings.Join(disabled, ", "))
+	}
+
+	return nil
+}
+
+// GetEbsEncryptionByDefault returns true if EBS encryption by default is enabled in the given region.
+func GetEbsEncryptionByDefault(t testing.TestingT, region string) bool {
+	enabled, err := GetEbsEncryptionByDefaultE(t, region)
+	require.NoError(t, err)
+	return enabled
+}
+
+// GetEbsEncryptionByDefaultE returns true if EBS encryption by default is enabled in the given region.
+func GetEbsEncryptionByDefaultE(t testing.TestingT, region string) (bool, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return false, err
+	}
+
+	output, err := client.GetEbsEncryptionByDefault(&ec2.GetEbsEncryptionByDefaultInput{})
+	if err != nil {
+		return false, err
+	}
+
+	return aws.BoolValue(output.EbsEncryptionByDefault), nil
+}
+
+// AssertEbsEncryptionByDefaultEnabled checks that EBS encryption by default is enabled in the given region.
+func AssertEbsEncryptionByDefaultEnabled(t testing.TestingT, region string) {
+	err := AssertEbsEncryptionByDefaultEnabledE(t, region)
+	require.NoError(t, err)
+}
+
+// AssertEbsEncryptionByDefaultEnabledE checks that EBS encryption by default is enabled in the given region and returns
+// an error if it is not.
+func AssertEbsEncryptionByDefaultEnabledE(t testing.TestingT, region string) error {
+	enabled, err := GetEbsEncryptionByDefaultE(t, region)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		return fmt.Errorf("EBS encryption by default is not enabled in %s", region)
+	}
+
+	return nil
+}
+
+// HasDefaultVpc returns true if there is a default VPC in the given region.
+func HasDefaultVpc(t testing.TestingT, region string) bool {
+	exists, err := HasDefaultVpcE(t, region)
+	require.NoError(t, err)
+	return exists
+}
+
+// HasDefaultVpcE returns true if there is a default VPC in the given region.
+func HasDefaultVpcE(t testing.TestingT, region string) (bool, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return false, err
+	}
+
+	defaultVpcFilter := ec2.Filter{Name: aws.String(isDefaultFilterName), Values: []*string{aws.String(isDefaultFilterValue)}}
+	output, err := client.DescribeVpcs(&ec2.DescribeVpcsInput{Filters: []*ec2.Filter{&defaultVpcFilter}})
+	if err != nil {
+		return false, err
+	}
+
+	return len(output.Vpcs) > 0, nil
+}
+
+// AssertNoDefaultVpc checks that the default VPC has been removed from the given region.
+func AssertNoDefaultVpc(t testing.TestingT, region string) {
+	err := AssertNoDefaultVpcE(t, region)
+	require.NoError(t, err)
+}
+
+// AssertNoDefaultVpcE checks that the default VPC has been removed from the given region and returns an error if it
+// has not.
+func AssertNoDefaultVpcE(t testing.TestingT, region string) error {
+	exists, err := HasDefaultVpcE(t, region)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("Found a default VPC in %s", region)
+	}
+
+	return nil
+}
+
+// IamPasswordPolicyRequirements are the minimum requirements an IAM account password policy must meet. Zero values are
+// not checked.
+type IamPasswordPolicyRequirements struct {
+	MinimumPasswordLength      int64
+	RequireSymbols             bool
+	RequireNumbers             bool
+	RequireUppercaseCharacters bool
+	RequireLowercaseCharacters bool
+	MaxPasswordAge             int64 // Maximum number of days a password may be used. Passwords that never expire do not meet this.
+	PasswordReusePrevention    int64 // Minimum number of previous passwords that may not be reused
+}
+
+// GetIamPasswordPolicy returns the IAM account password policy of the current account.
+func GetIamPasswordPolicy(t testing.TestingT) *iam.PasswordPolicy {
+	policy, err := GetIamPasswordPolicyE(t)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetIamPasswordPolicyE returns the IAM account password policy of the current account.
+func GetIamPasswordPolicyE(t testing.TestingT) (*iam.PasswordPolicy, error) {
+	client, err := NewIamClientE(t, defaultRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.GetAccountPasswordPolicy(&iam.GetAccountPasswordPolicyInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.PasswordPolicy, nil
+}
+
+// AssertIamPasswordPolicy checks that the IAM account password policy of the current account meets the given
+// requirements.
+func AssertIamPasswordPolicy(t testing.TestingT, requirements IamPasswordPolicyRequirements) {
+	err := AssertIamPasswordPolicyE(t, requirements)
+	require.NoError(t, err)
+}
+
+// AssertIamPasswordPolicyE checks that the IAM account password policy of the current account meets the given
+// requirements and returns an error listing every requirement that is not met.
+func AssertIamPasswordPolicyE(t testing.TestingT, requirements IamPasswordPolicyRequirements) error {
+	policy, err := GetIamPasswordPolicyE(t)
+	if err != nil {
+		return err
+	}
+
+	return checkIamPasswordPolicy(policy, requirements)
+}
+
+// checkIamPasswordPolicy returns an error listing every requirement the given password policy does not meet.
+func checkIamPasswordPolicy(policy *iam.PasswordPolicy, requirements IamPasswordPolicyRequirements) error {
+	violations := []string{}
+
+	if length := aws.Int64Value(policy.MinimumPasswordLength); length < requirements.MinimumPasswordLength {
+		violations = append(violations, fmt.Sprintf("minimum password length is %d, expected at least %d", length, requirements.MinimumPasswordLength))
+	}
+	if requirements.RequireSymbols && !aws.BoolValue(policy.RequireSymbols) {
+		violations = append(violations, "symbols are not required")
+	}
+	if requirements.RequireNumbers && !aws.BoolValue(policy.RequireNumbers) {
+		violations = append(violations, "numbers are not required")
+	}
+	if requirements.RequireUppercaseCharacters && !aws.BoolValue(policy.RequireUppercaseCharacters) {
+		violations = append(violations, "uppercase characters are not required")
+	}
+	if requirements.RequireLowercaseCharacters && !aws.BoolValue(policy.RequireLowercaseCharacters) {
+		violations = append(violations, "lowercase characters are not required")
+	}
+	if requirements.MaxPasswordAge > 0 {
+		if !aws.BoolValue(policy.ExpirePasswords) {
+			violations = append(violations, "passwords never expire")
+		} else if age := aws.Int64Value(policy.MaxPasswordAge); age > requirements.MaxPasswordAge {
+			violations = append(violations, fmt.Sprintf("maximum password age is %d days, expected at most %d", age, requirements.MaxPasswordAge))
+		}
+	}
+	if reuse := aws.Int64Value(policy.PasswordReusePrevention); reuse < requirements.PasswordReusePrevention {
+		violations = append(violations, fmt.Sprintf("password reuse prevention is %d, expected at least %d", reuse, requirements.PasswordReusePrevention))
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("IAM password policy does not meet the requirements: %s", strings.Join(violations, "; "))
+	}
+
+	return nil
+}
+
+// IsGuardDutyEnabled returns true if there is an enabled GuardDuty detector in the given region.
+func IsGuardDutyEnabled(t testing.TestingT, region string) bool {
+	enabled, err := IsGuardDutyEnabledE(t, region)
+	require.NoError(t, err)
+	return enabled
+}
+
+// IsGuardDutyEnabledE returns true if there is an enabled GuardDuty detector in the given region.
+func IsGuardDutyEnabledE(t testing.TestingT, region string) (bool, error) {
+	client, err := NewGuardDutyClientE(t, region)
+	if err != nil {
+		return false, err
+	}
+
+	detectors, err := client.ListDetectors(&guardduty.ListDetectorsInput{})
+	if err != nil {
+		return false, err
+	}
+
+	for _, detectorID := range detectors.DetectorIds {
+		detector, err := client.GetDetector(&guardduty.GetDetectorInput{DetectorId: detectorID})
+		if err != nil {
+			return false, err
+		}
+		if aws.StringValue(detector.Status) == guardduty.DetectorStatusEnabled {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// AssertGuardDutyEnabled checks that GuardDuty is enabled in the given region.
+func AssertGuardDutyEnabled(t testing.TestingT, region string) {
+	err := AssertGuardDutyEnabledE(t, region)
+	require.NoError(t, err)
+}
+
+// AssertGuardDutyEnabledE checks that GuardDuty is enabled in the given region and returns an error if it is not.
+func AssertGuardDutyEnabledE(t testing.TestingT, region string) error {
+	enabled, err := IsGuardDutyEnabledE(t, region)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		return fmt.Errorf("GuardDuty is not enabled in %s", region)
+	}
+
+	return nil
+}
+
+// IsSecurityHubEnabled returns true if Security Hub is enabled in the given region.
+func IsSecurityHubEnabled(t testing.TestingT, region string) bool {
+	enabled, err := IsSecurityHubEnabledE(t, region)
+	require.NoError(t, err)
+	return enabled
+}
+
+// IsSecurityHubEnabledE returns true if Security Hub is enabled in the given region.
+func IsSecurityHubEnabledE(t testing.TestingT, region string) (bool, error) {
+	client, err := NewSecurityHubClientE(t, region)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.DescribeHub(&securityhub.DescribeHubInput{})
+	if err != nil {
+		// Security Hub reports an account that is not subscribed as an access error
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == securityhub.ErrCodeInvalidAccessException {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// AssertSecurityHubEnabled checks that Security Hub is enabled in the given region.
+func AssertSecurityHubEnabled(t testing.TestingT, region string) {
+	err := AssertSecurityHubEnabledE(t, region)
+	require.NoError(t, err)
+}
+
+// AssertSecurityHubEnabledE checks that Security Hub is enabled in the given region and returns an error if it is not.
+func AssertSecurityHubEnabledE(t testing.TestingT, region string) error {
+	enabled, err := IsSecurityHubEnabledE(t, region)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		return fmt.Errorf("Security Hub is not enabled in %s", region)
+	}
+
+	return nil
+}
+
+// NewS3ControlClientE creates an S3 Control client.
+func NewS3ControlClientE(t testing.TestingT, region string) (*s3control.S3Control, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return s3control.New(sess), nil
+}
+
+// NewGuardDutyClientE creates a GuardDuty client.
+func NewGuardDutyClientE(t testing.TestingT, region string) (*guardduty.GuardDuty, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return guardduty.New(sess), nil
+}
+
+// NewSecurityHubClientE creates a Security Hub client.
+func NewSecurityHubClientE(t testing.TestingT, region string) (*securityhub.SecurityHub, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return securityhub.New(sess), nil
+}
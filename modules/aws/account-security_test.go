@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIamPasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	requirements := IamPasswordPolicyRequirements{
+		MinimumPasswordLength:   14,
+		RequireSymbols:          true,
+		MaxPasswordAge:          90,
+		PasswordReusePrevention: 24,
+	}
+
+	compliantPolicy := &iam.PasswordPolicy{
+		MinimumPasswordLength:   aws.Int64(16),
+		RequireSymbols:          aws.Bool(true),
+		ExpirePasswords:         aws.Bool(true),
+		MaxPasswordAge:          aws.Int64(60),
+		PasswordReusePrevention: aws.Int64(24),
+	}
+	assert.NoError(t, checkIamPasswordPolicy(compliantPolicy, requirements))
+
+	weakPolicy := &iam.PasswordPolicy{
+		MinimumPasswordLength: aws.Int64(8),
+		ExpirePasswords:       aws.Bool(false),
+	}
+	err := checkIamPasswordPolicy(weakPolicy, requirements)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "minimum password length is 8")
+		assert.Contains(t, err.Error(), "symbols are not required")
+		assert.Contains(t, err.Error(), "passwords never expire")
+		assert.Contains(t, err.Error(), "password reuse prevention is 0")
+	}
+}
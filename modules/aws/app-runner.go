@@ -0,0 +1,200 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apprunner"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// GetAppRunnerService gets the App Runner service with the given ARN.
+func GetAppRunnerService(t testing.TestingT, region string, serviceArn string) *apprunner.Service {
+	service, err := GetAppRunnerServiceE(t, region, serviceArn)
+	require.NoError(t, err)
+	return service
+}
+
+// GetAppRunnerServiceE gets the App Runner service with the given ARN.
+func GetAppRunnerServiceE(t testing.TestingT, region string, serviceArn string) (*apprunner.Service, error) {
+	client, err := NewAppRunnerClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeService(&apprunner.DescribeServiceInput{ServiceArn: aws.String(serviceArn)})
+	if err != nil {
+		return nil, err
+	}
+	return output.Service, nil
+}
+
+// WaitForAppRunnerServiceRunning waits until the given App Runner service is RUNNING. This will fail the test if that
+// does not happen within the given number of retries.
+func WaitForAppRunnerServiceRunning(t testing.TestingT, region string, serviceArn string, maxRetries int, sleepBetweenRetries time.Duration) *apprunner.Service {
+	service, err := WaitForAppRunnerServiceRunningE(t, region, serviceArn, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return service
+}
+
+// WaitForAppRunnerServiceRunningE waits until the given App Runner service is RUNNING, returning an
+// AppRunnerServiceFailed error early if it reaches a failed, paused or deleted state instead.
+func WaitForAppRunnerServiceRunningE(t testing.TestingT, region string, serviceArn string, maxRetries int, sleepBetweenRetries time.Duration) (*apprunner.Service, error) {
+	var service *apprunner.Service
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("App Runner service %s", serviceArn),
+		DesiredState: apprunner.ServiceStatusRunning,
+		Extract: func() (WaitState, error) {
+			found, err := GetAppRunnerServiceE(t, region, serviceArn)
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			status := aws.StringValue(found.Status)
+			switch status {
+			case apprunner.ServiceStatusRunning:
+				service = found
+				return WaitState{Current: status, Done: true}, nil
+			case apprunner.ServiceStatusOperationInProgress:
+				return WaitState{Current: status}, nil
+			default:
+				return WaitState{}, retry.FatalError{Underlying: AppRunnerServiceFailed{ServiceArn: serviceArn, Status: status}}
+			}
+		},
+	}
+
+	err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	return service, err
+}
+
+// GetAppRunnerServiceEnvironmentVariables gets the runtime environment variables of the given App Runner service,
+// whether it is deployed from an image or from source code.
+func GetAppRunnerServiceEnvironmentVariables(t testing.TestingT, region string, serviceArn string) map[string]string {
+	variables, err := GetAppRunnerServiceEnvironmentVariablesE(t, region, serviceArn)
+	require.NoError(t, err)
+	return variables
+}
+
+// GetAppRunnerServiceEnvironmentVariablesE gets the runtime environment variables of the given App Runner service,
+// whether it is deployed from an image or from source code.
+func GetAppRunnerServiceEnvironmentVariablesE(t testing.TestingT, region string, serviceArn string) (map[string]string, error) {
+	service, err := GetAppRunnerServiceE(t, region, serviceArn)
+	if err != nil {
+		return nil, err
+	}
+	return getAppRunnerServiceEnvironmentVariables(service), nil
+}
+
+// GetAppRunnerServiceOperations gets up to the given number of the most recent operations (deployments, pauses and so
+// on) of the given App Runner service, newest first, formatted as "<start time> <type> <status>".
+func GetAppRunnerServiceOperations(t testing.TestingT, region string, serviceArn string, maxOperations int64) []string {
+	operations, err := GetAppRunnerServiceOperationsE(t, region, serviceArn, maxOperations)
+	require.NoError(t, err)
+	return operations
+}
+
+// GetAppRunnerServiceOperationsE gets up to the given number of the most recent operations (deployments, pauses and so
+// on) of the given App Runner service, newest first, formatted as "<start time> <type> <status>".
+func GetAppRunnerServiceOperationsE(t testing.TestingT, region string, serviceArn string, maxOperations int64) ([]string, error) {
+	client, err := NewAppRunnerClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.ListOperations(&apprunner.ListOperationsInput{
+		ServiceArn: aws.String(serviceArn),
+		MaxResults: aws.Int64(maxOperations),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	operations := []string{}
+	for _, operation := range output.OperationSummaryList {
+		operations = append(operations, fmt.Sprintf("%s %s %s", aws.TimeValue(operation.StartedAt).Format(time.RFC3339), aws.StringValue(operation.Type), aws.StringValue(operation.Status)))
+	}
+	return operations, nil
+}
+
+// GetAppRunnerServiceUrl gets the default HTTPS URL of the given App Runner service.
+func GetAppRunnerServiceUrl(t testing.TestingT, region string, serviceArn string) string {
+	url, err := GetAppRunnerServiceUrlE(t, region, serviceArn)
+	require.NoError(t, err)
+	return url
+}
+
+// GetAppRunnerServiceUrlE gets the default HTTPS URL of the given App Runner service.
+func GetAppRunnerServiceUrlE(t testing.TestingT, region string, serviceArn string) (string, error) {
+	service, err := GetAppRunnerServiceE(t, region, serviceArn)
+	if err != nil {
+		return "", err
+	}
+
+	if aws.StringValue(service.ServiceUrl) == "" {
+		return "", fmt.Errorf("App Runner service %s has no URL", serviceArn)
+	}
+	return "https://" + aws.StringValue(service.ServiceUrl), nil
+}
+
+// ProbeAppRunnerService sends GET requests for the given path to the URL of the given App Runner service until it
+// responds with the expected status code, and returns the body of the response. This will fail the test if that does
+// not happen within the given number of retries.
+func ProbeAppRunnerService(t testing.TestingT, region string, serviceArn string, path string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := ProbeAppRunnerServiceE(t, region, serviceArn, path, expectedStatus, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return body
+}
+
+// ProbeAppRunnerServiceE sends GET requests for the given path to the URL of the given App Runner service until it
+// responds with the expected status code, and returns the body of the response.
+func ProbeAppRunnerServiceE(t testing.TestingT, region string, serviceArn string, path string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	url, err := GetAppRunnerServiceUrlE(t, region, serviceArn)
+	if err != nil {
+		return "", err
+	}
+	return http_helper.HTTPDoWithRetryE(t, "GET", url+"/"+strings.TrimPrefix(path, "/"), nil, nil, expectedStatus, retries, sleepBetweenRetries, nil)
+}
+
+// NewAppRunnerClient creates an App Runner client.
+func NewAppRunnerClient(t testing.TestingT, region string) *apprunner.AppRunner {
+	client, err := NewAppRunnerClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewAppRunnerClientE creates an App Runner client.
+func NewAppRunnerClientE(t testing.TestingT, region string) (*apprunner.AppRunner, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return apprunner.New(sess), nil
+}
+
+// getAppRunnerServiceEnvironmentVariables returns the runtime environment variables of the image or code
+// configuration of the given service.
+func getAppRunnerServiceEnvironmentVariables(service *apprunner.Service) map[string]string {
+	variables := map[string]string{}
+	source := service.SourceConfiguration
+	if source == nil {
+		return variables
+	}
+
+	var values map[string]*string
+	if source.ImageRepository != nil && source.ImageRepository.ImageConfiguration != nil {
+		values = source.ImageRepository.ImageConfiguration.RuntimeEnvironmentVariables
+	} else if source.CodeRepository != nil && source.CodeRepository.CodeConfiguration != nil && source.CodeRepository.CodeConfiguration.CodeConfigurationValues != nil {
+		values = source.CodeRepository.CodeConfiguration.CodeConfigurationValues.RuntimeEnvironmentVariables
+	}
+
+	for name, value := range values {
+		variables[name] = aws.StringValue(value)
+	}
+	return variables
+}
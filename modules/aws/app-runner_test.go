@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apprunner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAppRunnerServiceEnvironmentVariables(t *testing.T) {
+	t.Parallel()
+
+	imageService := &apprunner.Service{SourceConfiguration: &apprunner.SourceConfiguration{
+		ImageRepository: &apprunner.ImageRepository{ImageConfiguration: &apprunner.ImageConfiguration{
+			RuntimeEnvironmentVariables: map[string]*string{"STAGE": aws.String("test")},
+		}},
+	}}
+	assert.Equal(t, map[string]string{"STAGE": "test"}, getAppRunnerServiceEnvironmentVariables(imageService))
+
+	codeService := &apprunner.Service{SourceConfiguration: &apprunner.SourceConfiguration{
+		CodeRepository: &apprunner.CodeRepository{CodeConfiguration: &apprunner.CodeConfiguration{
+			CodeConfigurationValues: &apprunner.CodeConfigurationValues{
+				RuntimeEnvironmentVariables: map[string]*string{"PORT": aws.String("8080")},
+			},
+		}},
+	}}
+	assert.Equal(t, map[string]string{"PORT": "8080"}, getAppRunnerServiceEnvironmentVariables(codeService))
+
+	assert.Empty(t, getAppRunnerServiceEnvironmentVariables(&apprunner.Service{}))
+}
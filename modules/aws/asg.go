@@ -8,8 +8,6 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/stretchr/testify/require"
 
-	"github.com/gruntwork-io/terratest/modules/logger"
-	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
@@ -63,6 +61,41 @@ func GetInstanceIdsForAsg(t testing.TestingT, asgName string, awsRegion string)
 
 // GetInstanceIdsForAsgE gets the IDs of EC2 Instances in the given ASG.
 func GetInstanceIdsForAsgE(t testing.TestingT, asgName string, awsRegion string) ([]string, error) {
+	return getCachedInstanceIdsForAsgE(t, asgName, awsRegion, false)
+}
+
+// GetHealthyInstanceIdsForAsg gets the IDs of the EC2 Instances in the given ASG that are InService and Healthy.
+func GetHealthyInstanceIdsForAsg(t testing.TestingT, asgName string, awsRegion string) []string {
+	ids, err := GetHealthyInstanceIdsForAsgE(t, asgName, awsRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+// GetHealthyInstanceIdsForAsgE gets the IDs of the EC2 Instances in the given ASG that are InService and Healthy,
+// leaving out the ones that are still Pending, are being replaced or terminated, or failed their health checks, e.g.
+// during a rolling deploy.
+func GetHealthyInstanceIdsForAsgE(t testing.TestingT, asgName string, awsRegion string) ([]string, error) {
+	return getCachedInstanceIdsForAsgE(t, asgName, awsRegion, true)
+}
+
+func getCachedInstanceIdsForAsgE(t testing.TestingT, asgName string, awsRegion string, healthyOnly bool) ([]string, error) {
+	kind := "asg-instances"
+	if healthyOnly {
+		kind = "asg-healthy-instances"
+	}
+
+	instanceIDs, err := cachedDescribeE(t, describeCacheKey(kind, awsRegion, asgName), func() (interface{}, error) {
+		return getInstanceIdsForAsgE(t, asgName, awsRegion, healthyOnly)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{}, instanceIDs.([]string)...), nil
+}
+
+func getInstanceIdsForAsgE(t testing.TestingT, asgName string, awsRegion string, healthyOnly bool) ([]string, error) {
 	asgClient, err := NewAsgClientE(t, awsRegion)
 	if err != nil {
 		return nil, err
@@ -74,14 +107,27 @@ func GetInstanceIdsForAsgE(t testing.TestingT, asgName string, awsRegion string)
 		return nil, err
 	}
 
+	return asgInstanceIds(output.AutoScalingGroups, healthyOnly), nil
+}
+
+// asgInstanceIds returns the IDs of the instances of the given ASGs, only including the ones that are InService and
+// Healthy if healthyOnly is true.
+func asgInstanceIds(groups []*autoscaling.Group, healthyOnly bool) []string {
 	instanceIDs := []string{}
-	for _, asg := range output.AutoScalingGroups {
+	for _, asg := range groups {
 		for _, instance := range asg.Instances {
+			if healthyOnly && !isHealthyAsgInstance(instance) {
+				continue
+			}
 			instanceIDs = append(instanceIDs, aws.StringValue(instance.InstanceId))
 		}
 	}
+	return instanceIDs
+}
 
-	return instanceIDs, nil
+// isHealthyAsgInstance returns true if the given instance of an ASG is InService and passing its health checks.
+func isHealthyAsgInstance(instance *autoscaling.Instance) bool {
+	return aws.StringValue(instance.LifecycleState) == autoscaling.LifecycleStateInService && aws.StringValue(instance.HealthStatus) == "Healthy"
 }
 
 // WaitForCapacity waits for the currently set desired capacity to be reached on the ASG
@@ -104,24 +150,21 @@ func WaitForCapacityE(
 	maxRetries int,
 	sleepBetweenRetries time.Duration,
 ) error {
-	msg, err := retry.DoWithRetryE(
-		t,
-		fmt.Sprintf("Waiting for ASG %s to reach desired capacity.", asgName),
-		maxRetries,
-		sleepBetweenRetries,
-		func() (string, error) {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("ASG %s", asgName),
+		DesiredState: "at desired capacity",
+		Extract: func() (WaitState, error) {
 			capacityInfo, err := GetCapacityInfoForAsgE(t, asgName, region)
 			if err != nil {
-				return "", err
-			}
-			if capacityInfo.CurrentCapacity != capacityInfo.DesiredCapacity {
-				return "", NewAsgCapacityNotMetError(asgName, capacityInfo.DesiredCapacity, capacityInfo.CurrentCapacity)
+				return WaitState{}, err
 			}
-			return fmt.Sprintf("ASG %s is now at desired capacity %d", asgName, capacityInfo.DesiredCapacity), nil
+
+			current := fmt.Sprintf("%d/%d instances", capacityInfo.CurrentCapacity, capacityInfo.DesiredCapacity)
+			return WaitState{Current: current, Done: capacityInfo.CurrentCapacity == capacityInfo.DesiredCapacity}, nil
 		},
-	)
-	logger.Log(t, msg)
-	return err
+	}
+
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
 }
 
 // NewAsgClient creates an Auto Scaling Group client.
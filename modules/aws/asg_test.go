@@ -158,3 +158,20 @@ func scaleAsgToZero(t *testing.T, name string, region string) {
 	// scaling activity so we add a 5 second pause here to work around it.
 	time.Sleep(5 * time.Second)
 }
+
+func TestAsgInstanceIdsSkipsUnhealthyInstances(t *testing.T) {
+	t.Parallel()
+
+	newInstance := func(id string, lifecycleState string, healthStatus string) *autoscaling.Instance {
+		return &autoscaling.Instance{InstanceId: aws.String(id), LifecycleState: aws.String(lifecycleState), HealthStatus: aws.String(healthStatus)}
+	}
+	groups := []*autoscaling.Group{{Instances: []*autoscaling.Instance{
+		newInstance("i-1", autoscaling.LifecycleStateInService, "Healthy"),
+		newInstance("i-2", autoscaling.LifecycleStatePending, "Healthy"),
+		newInstance("i-3", autoscaling.LifecycleStateTerminating, "Healthy"),
+		newInstance("i-4", autoscaling.LifecycleStateInService, "Unhealthy"),
+	}}}
+
+	assert.Equal(t, []string{"i-1", "i-2", "i-3", "i-4"}, asgInstanceIds(groups, false))
+	assert.Equal(t, []string{"i-1"}, asgInstanceIds(groups, true))
+}
@@ -0,0 +1,161 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	athenaQueryMaxRetries          = 150
+	athenaQuerySleepBetweenRetries = 2 * time.Second
+)
+
+// RunAthenaQuery runs the given query against the given Athena database, writing the results to the given S3 location
+// (e.g. "s3://my-bucket/athena-results/"), waits for it to finish and returns the result rows as maps of column name
+// to value. NULL values are returned as empty strings.
+func RunAthenaQuery(t testing.TestingT, region string, query string, database string, outputLocation string) []map[string]string {
+	rows, err := RunAthenaQueryE(t, region, query, database, outputLocation)
+	require.NoError(t, err)
+	return rows
+}
+
+// RunAthenaQueryE runs the given query against the given Athena database, writing the results to the given S3 location
+// (e.g. "s3://my-bucket/athena-results/"), waits for it to finish and returns the result rows as maps of column name
+// to value. NULL values are returned as empty strings.
+func RunAthenaQueryE(t testing.TestingT, region string, query string, database string, outputLocation string) ([]map[string]string, error) {
+	client, err := NewAthenaClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &athena.StartQueryExecutionInput{
+		QueryString:         aws.String(query),
+		ResultConfiguration: &athena.ResultConfiguration{OutputLocation: aws.String(outputLocation)},
+	}
+	if database != "" {
+		input.QueryExecutionContext = &athena.QueryExecutionContext{Database: aws.String(database)}
+	}
+
+	logger.Logf(t, "Running Athena query: %s", query)
+	output, err := client.StartQueryExecution(input)
+	if err != nil {
+		return nil, err
+	}
+	queryExecutionID := aws.StringValue(output.QueryExecutionId)
+
+	if err := waitForAthenaQueryE(t, client, queryExecutionID); err != nil {
+		return nil, err
+	}
+
+	rows := []map[string]string{}
+	var columns []string
+	err = client.GetQueryResultsPages(&athena.GetQueryResultsInput{QueryExecutionId: aws.String(queryExecutionID)}, func(page *athena.GetQueryResultsOutput, lastPage bool) bool {
+		if columns == nil {
+			columns = getAthenaColumnNames(page.ResultSet.ResultSetMetadata)
+		}
+		for _, row := range page.ResultSet.Rows {
+			rows = append(rows, athenaRowToMap(columns, row))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dropAthenaHeaderRow(columns, rows), nil
+}
+
+// NewAthenaClient creates an Athena client.
+func NewAthenaClient(t testing.TestingT, region string) *athena.Athena {
+	client, err := NewAthenaClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewAthenaClientE creates an Athena client.
+func NewAthenaClientE(t testing.TestingT, region string) (*athena.Athena, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return athena.New(sess), nil
+}
+
+// waitForAthenaQueryE waits until the given Athena query execution has finished, returning an AthenaQueryFailed error
+// if it failed or was cancelled.
+func waitForAthenaQueryE(t testing.TestingT, client *athena.Athena, queryExecutionID string) error {
+	description := fmt.Sprintf("Waiting for Athena query %s to finish", queryExecutionID)
+	_, err := retry.DoWithRetryE(t, description, athenaQueryMaxRetries, athenaQuerySleepBetweenRetries, func() (string, error) {
+		output, err := client.GetQueryExecution(&athena.GetQueryExecutionInput{QueryExecutionId: aws.String(queryExecutionID)})
+		if err != nil {
+			return "", err
+		}
+
+		status := output.QueryExecution.Status
+		state := aws.StringValue(status.State)
+		switch state {
+		case athena.QueryExecutionStateSucceeded:
+			return state, nil
+		case athena.QueryExecutionStateFailed, athena.QueryExecutionStateCancelled:
+			return "", retry.FatalError{Underlying: AthenaQueryFailed{
+				QueryExecutionId: queryExecutionID,
+				State:            state,
+				Reason:           aws.StringValue(status.StateChangeReason),
+			}}
+		default:
+			return "", fmt.Errorf("Athena query %s is in state %s", queryExecutionID, state)
+		}
+	})
+
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return actualErr.Underlying
+	}
+	return err
+}
+
+// getAthenaColumnNames returns the names of the columns in the given Athena result set metadata.
+func getAthenaColumnNames(metadata *athena.ResultSetMetadata) []string {
+	columns := []string{}
+	if metadata == nil {
+		return columns
+	}
+
+	for _, column := range metadata.ColumnInfo {
+		columns = append(columns, aws.StringValue(column.Name))
+	}
+	return columns
+}
+
+// athenaRowToMap converts the given Athena result row to a map of column name to value.
+func athenaRowToMap(columns []string, row *athena.Row) map[string]string {
+	values := map[string]string{}
+	for i, datum := range row.Data {
+		if i < len(columns) {
+			values[columns[i]] = aws.StringValue(datum.VarCharValue)
+		}
+	}
+	return values
+}
+
+// dropAthenaHeaderRow removes the first row of the given Athena results if it holds the column names, which Athena
+// returns as the first row of SELECT query results.
+func dropAthenaHeaderRow(columns []string, rows []map[string]string) []map[string]string {
+	if len(rows) == 0 || len(columns) == 0 {
+		return rows
+	}
+
+	for _, column := range columns {
+		if rows[0][column] != column {
+			return rows
+		}
+	}
+	return rows[1:]
+}
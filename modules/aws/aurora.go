@@ -0,0 +1,290 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// AuroraClusterEndpoints are the endpoints of an Aurora cluster, and the instances behind them.
+type AuroraClusterEndpoints struct {
+	Writer            RdsEndpoint // The cluster endpoint, which always points at the writer instance
+	Reader            RdsEndpoint // The reader endpoint, which balances connections across the reader instances
+	WriterInstanceID  string
+	ReaderInstanceIDs []string
+}
+
+// GetAuroraCluster gets the details of the Aurora cluster with the given ID. This will fail the test if there is an
+// error.
+func GetAuroraCluster(t testing.TestingT, dbClusterID string, awsRegion string) *rds.DBCluster {
+	cluster, err := GetAuroraClusterE(t, dbClusterID, awsRegion)
+	require.NoError(t, err)
+	return cluster
+}
+
+// GetAuroraClusterE gets the details of the Aurora cluster with the given ID.
+func GetAuroraClusterE(t testing.TestingT, dbClusterID string, awsRegion string) (*rds.DBCluster, error) {
+	client, err := NewRdsClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeDBClusters(&rds.DescribeDBClustersInput{DBClusterIdentifier: aws.String(dbClusterID)})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.DBClusters) == 0 {
+		return nil, AuroraClusterNotFound{DbClusterID: dbClusterID, AwsRegion: awsRegion}
+	}
+	return output.DBClusters[0], nil
+}
+
+// GetAuroraClusterEndpoints gets the writer and reader endpoints of the Aurora cluster with the given ID, and the
+// instances behind them. This will fail the test if there is an error.
+func GetAuroraClusterEndpoints(t testing.TestingT, dbClusterID string, awsRegion string) AuroraClusterEndpoints {
+	endpoints, err := GetAuroraClusterEndpointsE(t, dbClusterID, awsRegion)
+	require.NoError(t, err)
+	return endpoints
+}
+
+// GetAuroraClusterEndpointsE gets the writer and reader endpoints of the Aurora cluster with the given ID, and the
+// instances behind them.
+func GetAuroraClusterEndpointsE(t testing.TestingT, dbClusterID string, awsRegion string) (AuroraClusterEndpoints, error) {
+	cluster, err := GetAuroraClusterE(t, dbClusterID, awsRegion)
+	if err != nil {
+		return AuroraClusterEndpoints{}, err
+	}
+	return auroraClusterEndpoints(cluster), nil
+}
+
+// auroraClusterEndpoints returns the endpoints of the given Aurora cluster, and the instances behind them.
+func auroraClusterEndpoints(cluster *rds.DBCluster) AuroraClusterEndpoints {
+	port := aws.Int64Value(cluster.Port)
+	endpoints := AuroraClusterEndpoints{
+		Writer:            RdsEndpoint{Address: aws.StringValue(cluster.Endpoint), Port: port},
+		Reader:            RdsEndpoint{Address: aws.StringValue(cluster.ReaderEndpoint), Port: port},
+		ReaderInstanceIDs: []string{},
+	}
+	for _, member := range cluster.DBClusterMembers {
+		if aws.BoolValue(member.IsClusterWriter) {
+			endpoints.WriterInstanceID = aws.StringValue(member.DBInstanceIdentifier)
+		} else {
+			endpoints.ReaderInstanceIDs = append(endpoints.ReaderInstanceIDs, aws.StringValue(member.DBInstanceIdentifier))
+		}
+	}
+	sort.Strings(endpoints.ReaderInstanceIDs)
+	return endpoints
+}
+
+// WaitForAuroraClusterAvailable waits until the Aurora cluster with the given ID and all its instances are available.
+// This will fail the test if they are not.
+func WaitForAuroraClusterAvailable(t testing.TestingT, dbClusterID string, awsRegion string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForAuroraClusterAvailableE(t, dbClusterID, awsRegion, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForAuroraClusterAvailableE waits until the Aurora cluster with the given ID and all its instances are available,
+// so that both its writer and reader endpoints accept connections. Returns an AuroraClusterNotAvailable or
+// RdsInstanceNotAvailable error as soon as the cluster or one of its instances is in a state it does not recover from
+// by itself.
+func WaitForAuroraClusterAvailableE(t testing.TestingT, dbClusterID string, awsRegion string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("Aurora cluster %s", dbClusterID),
+		DesiredState: "available",
+		Extract: func() (WaitState, error) {
+			cluster, instances, err := getAuroraClusterAndInstancesE(t, dbClusterID, awsRegion)
+			if err != nil {
+				return WaitState{}, err
+			}
+			return auroraClusterWaitState(cluster, instances)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// getAuroraClusterAndInstancesE gets the details of the Aurora cluster with the given ID and of its instances.
+func getAuroraClusterAndInstancesE(t testing.TestingT, dbClusterID string, awsRegion string) (*rds.DBCluster, []*rds.DBInstance, error) {
+	cluster, err := GetAuroraClusterE(t, dbClusterID, awsRegion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := NewRdsClientE(t, awsRegion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	instances := []*rds.DBInstance{}
+	input := &rds.DescribeDBInstancesInput{
+		Filters: []*rds.Filter{{Name: aws.String("db-cluster-id"), Values: []*string{aws.String(dbClusterID)}}},
+	}
+	err = client.DescribeDBInstancesPages(input, func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+		instances = append(instances, page.DBInstances...)
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cluster, instances, nil
+}
+
+// auroraClusterWaitState returns the state of the given Aurora cluster and its instances for
+// WaitForAuroraClusterAvailableE. Returns a retry.FatalError if the cluster or one of its instances will not become
+// available.
+func auroraClusterWaitState(cluster *rds.DBCluster, instances []*rds.DBInstance) (WaitState, error) {
+	status := aws.StringValue(cluster.Status)
+	if rdsInstanceFailedStatuses[status] {
+		return WaitState{}, retry.FatalError{Underlying: AuroraClusterNotAvailable{
+			DbClusterID: aws.StringValue(cluster.DBClusterIdentifier),
+			Status:      status,
+		}}
+	}
+
+	notAvailable := []string{}
+	for _, instance := range instances {
+		instanceState, err := rdsInstanceWaitState(instance)
+		if err != nil {
+			return WaitState{}, err
+		}
+		if !instanceState.Done {
+			notAvailable = append(notAvailable, fmt.Sprintf("%s is %s", aws.StringValue(instance.DBInstanceIdentifier), instanceState.Current))
+		}
+	}
+
+	current := status
+	if len(notAvailable) > 0 {
+		current = fmt.Sprintf("%s (%s)", status, strings.Join(notAvailable, ", "))
+	}
+	// A cluster without instances is available, but has nothing to connect to
+	done := status == "available" && len(instances) > 0 && len(notAvailable) == 0
+	return WaitState{Current: current, Done: done}, nil
+}
+
+// TriggerAuroraFailoverAndWait fails the Aurora cluster with the given ID over to one of its readers, waits until the
+// reader is promoted to writer and the cluster is available again, and returns the ID of the new writer instance. This
+// will fail the test if there is an error.
+func TriggerAuroraFailoverAndWait(t testing.TestingT, dbClusterID string, awsRegion string, targetInstanceID string, maxRetries int, sleepBetweenRetries time.Duration) string {
+	writerInstanceID, err := TriggerAuroraFailoverAndWaitE(t, dbClusterID, awsRegion, targetInstanceID, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return writerInstanceID
+}
+
+// TriggerAuroraFailoverAndWaitE fails the Aurora cluster with the given ID over to the given reader instance, or to the
+// one Aurora picks by promotion tier if it is empty, waits until the reader is promoted to writer and the cluster and
+// all its instances are available again, and returns the ID of the new writer instance. The cluster endpoint then
+// points at the new writer, which a test can verify by connecting to it. Returns an AuroraClusterHasNoReaders error if
+// there is no reader to fail over to.
+func TriggerAuroraFailoverAndWaitE(t testing.TestingT, dbClusterID string, awsRegion string, targetInstanceID string, maxRetries int, sleepBetweenRetries time.Duration) (string, error) {
+	if dryrun.Skip(t, "fail over Aurora cluster %s in %s", dbClusterID, awsRegion) {
+		return "", nil
+	}
+
+	endpoints, err := GetAuroraClusterEndpointsE(t, dbClusterID, awsRegion)
+	if err != nil {
+		return "", err
+	}
+	if len(endpoints.ReaderInstanceIDs) == 0 {
+		return "", AuroraClusterHasNoReaders{DbClusterID: dbClusterID}
+	}
+
+	client, err := NewRdsClientE(t, awsRegion)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Failing over Aurora cluster %s from writer %s", dbClusterID, endpoints.WriterInstanceID)
+	input := &rds.FailoverDBClusterInput{DBClusterIdentifier: aws.String(dbClusterID)}
+	if targetInstanceID != "" {
+		input.TargetDBInstanceIdentifier = aws.String(targetInstanceID)
+	}
+	if _, err := client.FailoverDBCluster(input); err != nil {
+		return "", err
+	}
+
+	writerInstanceID := ""
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("failover of Aurora cluster %s", dbClusterID),
+		DesiredState: "complete",
+		Extract: func() (WaitState, error) {
+			cluster, instances, err := getAuroraClusterAndInstancesE(t, dbClusterID, awsRegion)
+			if err != nil {
+				return WaitState{}, err
+			}
+			writerInstanceID = auroraClusterEndpoints(cluster).WriterInstanceID
+			return auroraFailoverWaitState(cluster, instances, endpoints.WriterInstanceID, targetInstanceID)
+		},
+	}
+	if err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries); err != nil {
+		return "", err
+	}
+	return writerInstanceID, nil
+}
+
+// auroraFailoverWaitState returns the state of a failover of the given Aurora cluster away from the given previous
+// writer instance, and to the given target instance if it is not empty, for TriggerAuroraFailoverAndWaitE.
+func auroraFailoverWaitState(cluster *rds.DBCluster, instances []*rds.DBInstance, previousWriterID string, targetInstanceID string) (WaitState, error) {
+	clusterState, err := auroraClusterWaitState(cluster, instances)
+	if err != nil {
+		return WaitState{}, err
+	}
+
+	writerInstanceID := auroraClusterEndpoints(cluster).WriterInstanceID
+	promoted := writerInstanceID != "" && writerInstanceID != previousWriterID
+	if targetInstanceID != "" {
+		promoted = writerInstanceID == targetInstanceID
+	}
+	return WaitState{
+		Current: fmt.Sprintf("writer %s, cluster %s", writerInstanceID, clusterState.Current),
+		Done:    promoted && clusterState.Done,
+	}, nil
+}
+
+// AuroraClusterNotFound is an error that occurs when an Aurora cluster does not exist in a region
+type AuroraClusterNotFound struct {
+	DbClusterID string
+	AwsRegion   string
+}
+
+func (err AuroraClusterNotFound) Error() string {
+	return fmt.Sprintf("Could not find Aurora cluster %s in %s", err.DbClusterID, err.AwsRegion)
+}
+
+// Is returns true if the target is ErrNotFound.
+func (err AuroraClusterNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// AuroraClusterNotAvailable is an error that occurs when an Aurora cluster is in a state it does not recover from by
+// itself
+type AuroraClusterNotAvailable struct {
+	DbClusterID string
+	Status      string
+}
+
+func (err AuroraClusterNotAvailable) Error() string {
+	return fmt.Sprintf("Aurora cluster %s is not available: its status is %s", err.DbClusterID, err.Status)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err AuroraClusterNotAvailable) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// AuroraClusterHasNoReaders is an error that occurs when failing over an Aurora cluster that has no reader instances to
+// promote
+type AuroraClusterHasNoReaders struct {
+	DbClusterID string
+}
+
+func (err AuroraClusterHasNoReaders) Error() string {
+	return fmt.Sprintf("Aurora cluster %s has no reader instances to fail over to", err.DbClusterID)
+}
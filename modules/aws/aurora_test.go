@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAuroraCluster(status string, writer string, readers ...string) *rds.DBCluster {
+	cluster := &rds.DBCluster{
+		DBClusterIdentifier: aws.String("my-cluster"),
+		Status:              aws.String(status),
+		Endpoint:            aws.String("my-cluster.cluster-abc123.us-east-1.rds.amazonaws.com"),
+		ReaderEndpoint:      aws.String("my-cluster.cluster-ro-abc123.us-east-1.rds.amazonaws.com"),
+		Port:                aws.Int64(3306),
+		DBClusterMembers:    []*rds.DBClusterMember{{DBInstanceIdentifier: aws.String(writer), IsClusterWriter: aws.Bool(true)}},
+	}
+	for _, reader := range readers {
+		cluster.DBClusterMembers = append(cluster.DBClusterMembers, &rds.DBClusterMember{DBInstanceIdentifier: aws.String(reader), IsClusterWriter: aws.Bool(false)})
+	}
+	return cluster
+}
+
+func testRdsInstance(id string, status string) *rds.DBInstance {
+	return &rds.DBInstance{DBInstanceIdentifier: aws.String(id), DBInstanceStatus: aws.String(status)}
+}
+
+func TestAuroraClusterEndpoints(t *testing.T) {
+	t.Parallel()
+
+	endpoints := auroraClusterEndpoints(testAuroraCluster("available", "db-1", "db-3", "db-2"))
+	assert.Equal(t, AuroraClusterEndpoints{
+		Writer:            RdsEndpoint{Address: "my-cluster.cluster-abc123.us-east-1.rds.amazonaws.com", Port: 3306},
+		Reader:            RdsEndpoint{Address: "my-cluster.cluster-ro-abc123.us-east-1.rds.amazonaws.com", Port: 3306},
+		WriterInstanceID:  "db-1",
+		ReaderInstanceIDs: []string{"db-2", "db-3"},
+	}, endpoints)
+}
+
+func TestAuroraClusterWaitState(t *testing.T) {
+	t.Parallel()
+
+	state, err := auroraClusterWaitState(testAuroraCluster("available", "db-1", "db-2"), []*rds.DBInstance{testRdsInstance("db-1", "available"), testRdsInstance("db-2", "creating")})
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "available (db-2 is creating)"}, state)
+
+	state, err = auroraClusterWaitState(testAuroraCluster("available", "db-1"), []*rds.DBInstance{})
+	require.NoError(t, err)
+	assert.False(t, state.Done)
+
+	state, err = auroraClusterWaitState(testAuroraCluster("available", "db-1", "db-2"), []*rds.DBInstance{testRdsInstance("db-1", "available"), testRdsInstance("db-2", "available")})
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "available", Done: true}, state)
+
+	_, err = auroraClusterWaitState(testAuroraCluster("inaccessible-encryption-credentials", "db-1"), nil)
+	require.IsType(t, retry.FatalError{}, err)
+	assert.Equal(t, AuroraClusterNotAvailable{DbClusterID: "my-cluster", Status: "inaccessible-encryption-credentials"}, err.(retry.FatalError).Underlying)
+}
+
+func TestAuroraFailoverWaitState(t *testing.T) {
+	t.Parallel()
+
+	available := []*rds.DBInstance{testRdsInstance("db-1", "available"), testRdsInstance("db-2", "available"), testRdsInstance("db-3", "available")}
+
+	testCases := []struct {
+		name     string
+		cluster  *rds.DBCluster
+		target   string
+		expected bool
+	}{
+		{"not failed over yet", testAuroraCluster("available", "db-1", "db-2", "db-3"), "", false},
+		{"failing over", testAuroraCluster("failing-over", "db-2", "db-1", "db-3"), "", false},
+		{"failed over", testAuroraCluster("available", "db-2", "db-1", "db-3"), "", true},
+		{"failed over to another instance than the target", testAuroraCluster("available", "db-2", "db-1", "db-3"), "db-3", false},
+		{"failed over to the target", testAuroraCluster("available", "db-3", "db-1", "db-2"), "db-3", true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			state, err := auroraFailoverWaitState(testCase.cluster, available, "db-1", testCase.target)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, state.Done, state.Current)
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package aws
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// The line OpenVPN logs once the tunnel is up and the routes pushed by the server are installed.
+	openVpnConnectedMessage = "Initialization Sequence Completed"
+
+	// How long to wait for each TCP connection over the tunnel.
+	clientVpnDialTimeout = 10 * time.Second
+)
+
+// GetClientVpnEndpoint gets the Client VPN endpoint with the given ID. This will fail the test if there is an error.
+func GetClientVpnEndpoint(t testing.TestingT, region string, endpointID string) *ec2.ClientVpnEndpoint {
+	endpoint, err := GetClientVpnEndpointE(t, region, endpointID)
+	require.NoError(t, err)
+	return endpoint
+}
+
+// GetClientVpnEndpointE gets the Client VPN endpoint with the given ID.
+func GetClientVpnEndpointE(t testing.TestingT, region string, endpointID string) (*ec2.ClientVpnEndpoint, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeClientVpnEndpoints(&ec2.DescribeClientVpnEndpointsInput{
+		ClientVpnEndpointIds: []*string{aws.String(endpointID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.ClientVpnEndpoints) == 0 {
+		return nil, ClientVpnEndpointNotFound{EndpointID: endpointID, Region: region}
+	}
+	return output.ClientVpnEndpoints[0], nil
+}
+
+// GetClientVpnAuthorizationRules gets the authorization rules of the given Client VPN endpoint. This will fail the
+// test if there is an error.
+func GetClientVpnAuthorizationRules(t testing.TestingT, region string, endpointID string) []*ec2.AuthorizationRule {
+	rules, err := GetClientVpnAuthorizationRulesE(t, region, endpointID)
+	require.NoError(t, err)
+	return rules
+}
+
+// GetClientVpnAuthorizationRulesE gets the authorization rules of the given Client VPN endpoint, which say which
+// networks the clients, or groups of them, may access.
+func GetClientVpnAuthorizationRulesE(t testing.TestingT, region string, endpointID string) ([]*ec2.AuthorizationRule, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []*ec2.AuthorizationRule{}
+	input := &ec2.DescribeClientVpnAuthorizationRulesInput{ClientVpnEndpointId: aws.String(endpointID)}
+	err = client.DescribeClientVpnAuthorizationRulesPages(input, func(page *ec2.DescribeClientVpnAuthorizationRulesOutput, lastPage bool) bool {
+		rules = append(rules, page.AuthorizationRules...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetClientVpnRoutes gets the routes of the given Client VPN endpoint. This will fail the test if there is an error.
+func GetClientVpnRoutes(t testing.TestingT, region string, endpointID string) []*ec2.ClientVpnRoute {
+	routes, err := GetClientVpnRoutesE(t, region, endpointID)
+	require.NoError(t, err)
+	return routes
+}
+
+// GetClientVpnRoutesE gets the routes of the given Client VPN endpoint, including the ones Client VPN adds for the VPC
+// of each associated subnet.
+func GetClientVpnRoutesE(t testing.TestingT, region string, endpointID string) ([]*ec2.ClientVpnRoute, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []*ec2.ClientVpnRoute{}
+	input := &ec2.DescribeClientVpnRoutesInput{ClientVpnEndpointId: aws.String(endpointID)}
+	err = client.DescribeClientVpnRoutesPages(input, func(page *ec2.DescribeClientVpnRoutesOutput, lastPage bool) bool {
+		routes = append(routes, page.Routes...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// WaitForClientVpnEndpointAvailable waits until the given Client VPN endpoint is available and all its authorization
+// rules and routes are active. This will fail the test if they are not.
+func WaitForClientVpnEndpointAvailable(t testing.TestingT, region string, endpointID string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForClientVpnEndpointAvailableE(t, region, endpointID, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForClientVpnEndpointAvailableE waits until the given Client VPN endpoint is available, which it becomes once a
+// subnet is associated with it, and all its authorization rules and routes are active, so that clients can connect and
+// reach the networks behind it. Associating a subnet usually takes several minutes. Returns a ClientVpnEndpointFailed
+// error as soon as the endpoint is being deleted, or one of its authorization rules or routes failed.
+func WaitForClientVpnEndpointAvailableE(t testing.TestingT, region string, endpointID string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("Client VPN endpoint %s", endpointID),
+		DesiredState: "available",
+		Extract: func() (WaitState, error) {
+			endpoint, err := GetClientVpnEndpointE(t, region, endpointID)
+			if err != nil {
+				return WaitState{}, err
+			}
+			rules, err := GetClientVpnAuthorizationRulesE(t, region, endpointID)
+			if err != nil {
+				return WaitState{}, err
+			}
+			routes, err := GetClientVpnRoutesE(t, region, endpointID)
+			if err != nil {
+				return WaitState{}, err
+			}
+			return clientVpnWaitState(endpoint, rules, routes)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// clientVpnWaitState returns the state of the given Client VPN endpoint and its authorization rules and routes for
+// WaitForClientVpnEndpointAvailableE. Returns a retry.FatalError if the endpoint will not become usable.
+func clientVpnWaitState(endpoint *ec2.ClientVpnEndpoint, rules []*ec2.AuthorizationRule, routes []*ec2.ClientVpnRoute) (WaitState, error) {
+	endpointID := aws.StringValue(endpoint.ClientVpnEndpointId)
+	status := ""
+	if endpoint.Status != nil {
+		status = aws.StringValue(endpoint.Status.Code)
+	}
+	if status == ec2.ClientVpnEndpointStatusCodeDeleting || status == ec2.ClientVpnEndpointStatusCodeDeleted {
+		return WaitState{}, retry.FatalError{Underlying: ClientVpnEndpointFailed{EndpointID: endpointID, Resource: "endpoint", Status: status}}
+	}
+
+	pending := 0
+	for _, rule := range rules {
+		ruleStatus := ""
+		if rule.Status != nil {
+			ruleStatus = aws.StringValue(rule.Status.Code)
+		}
+		if ruleStatus == ec2.ClientVpnAuthorizationRuleStatusCodeFailed {
+			return WaitState{}, retry.FatalError{Underlying: ClientVpnEndpointFailed{
+				EndpointID: endpointID,
+				Resource:   fmt.Sprintf("authorization rule for %s", aws.StringValue(rule.DestinationCidr)),
+				Status:     ruleStatus,
+				Message:    aws.StringValue(rule.Status.Message),
+			}}
+		}
+		if ruleStatus != ec2.ClientVpnAuthorizationRuleStatusCodeActive {
+			pending++
+		}
+	}
+	for _, route := range routes {
+		routeStatus := ""
+		if route.Status != nil {
+			routeStatus = aws.StringValue(route.Status.Code)
+		}
+		if routeStatus == ec2.ClientVpnRouteStatusCodeFailed {
+			return WaitState{}, retry.FatalError{Underlying: ClientVpnEndpointFailed{
+				EndpointID: endpointID,
+				Resource:   fmt.Sprintf("route to %s", aws.StringValue(route.DestinationCidr)),
+				Status:     routeStatus,
+				Message:    aws.StringValue(route.Status.Message),
+			}}
+		}
+		if routeStatus != ec2.ClientVpnRouteStatusCodeActive {
+			pending++
+		}
+	}
+
+	return WaitState{
+		Current: fmt.Sprintf("%s, %d authorization rules and routes pending", status, pending),
+		Done:    status == ec2.ClientVpnEndpointStatusCodeAvailable && pending == 0,
+	}, nil
+}
+
+// GetClientVpnClientConfiguration gets the OpenVPN client configuration of the given Client VPN endpoint. This will
+// fail the test if there is an error.
+func GetClientVpnClientConfiguration(t testing.TestingT, region string, endpointID string) string {
+	configuration, err := GetClientVpnClientConfigurationE(t, region, endpointID)
+	require.NoError(t, err)
+	return configuration
+}
+
+// GetClientVpnClientConfigurationE gets the OpenVPN client configuration of the given Client VPN endpoint, as
+// downloaded from the console. For endpoints that use mutual authentication, add the certificate and key of a client
+// to it with AddClientCertificateToOpenVpnConfig before connecting.
+func GetClientVpnClientConfigurationE(t testing.TestingT, region string, endpointID string) (string, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.ExportClientVpnClientConfiguration(&ec2.ExportClientVpnClientConfigurationInput{
+		ClientVpnEndpointId: aws.String(endpointID),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.ClientConfiguration), nil
+}
+
+// Matches the inline <cert> and <key> blocks of an OpenVPN configuration.
+var openVpnInlineCredentialsRegexp = regexp.MustCompile(`(?s)\n?<(cert|key)>.*?</(cert|key)>\n?`)
+
+// AddClientCertificateToOpenVpnConfig returns the given OpenVPN client configuration with the given PEM encoded client
+// certificate and private key inlined, replacing any that it already has, e.g. for a test client whose certificate is
+// issued by the module under test.
+func AddClientCertificateToOpenVpnConfig(configuration string, certificatePem string, privateKeyPem string) string {
+	configuration = strings.TrimRight(openVpnInlineCredentialsRegexp.ReplaceAllString(configuration, "\n"), "\n")
+	return fmt.Sprintf("%s\n<cert>\n%s\n</cert>\n<key>\n%s\n</key>\n", configuration, strings.TrimSpace(certificatePem), strings.TrimSpace(privateKeyPem))
+}
+
+// ClientVpnConnection is an OpenVPN client connected to a Client VPN endpoint. Close it to disconnect.
+type ClientVpnConnection struct {
+	command    *exec.Cmd
+	configPath string
+}
+
+// Close disconnects the OpenVPN client and removes its configuration file.
+func (connection *ClientVpnConnection) Close() error {
+	defer os.Remove(connection.configPath)
+
+	killErr := connection.command.Process.Kill()
+	// Reap the process, which exits with an error as it was killed
+	connection.command.Wait()
+	if connection.command.ProcessState != nil {
+		// The process is gone, whether it was killed or had exited already
+		return nil
+	}
+	return killErr
+}
+
+// ConnectToClientVpn connects to a Client VPN endpoint with OpenVPN using the given client configuration, and waits
+// until the tunnel is up. This will fail the test if there is an error.
+func ConnectToClientVpn(t testing.TestingT, configuration string, timeout time.Duration) *ClientVpnConnection {
+	connection, err := ConnectToClientVpnE(t, configuration, timeout)
+	require.NoError(t, err)
+	return connection
+}
+
+// ConnectToClientVpnE connects to a Client VPN endpoint with OpenVPN using the given client configuration, e.g. from
+// GetClientVpnClientConfigurationE with a client certificate added, and waits until the tunnel is up and the routes of
+// the endpoint are installed. This needs the openvpn binary on the PATH and the privileges to create a tun device,
+// e.g. running as root in CI. Returns an OpenVpnConnectionFailed error with the output of OpenVPN if it exits or does
+// not connect before the timeout. Close the connection to disconnect.
+func ConnectToClientVpnE(t testing.TestingT, configuration string, timeout time.Duration) (*ClientVpnConnection, error) {
+	configFile, err := ioutil.TempFile("", "terratest-client-vpn-*.ovpn")
+	if err != nil {
+		return nil, err
+	}
+	defer configFile.Close()
+	if _, err := configFile.WriteString(configuration); err != nil {
+		os.Remove(configFile.Name())
+		return nil, err
+	}
+
+	command := exec.Command("openvpn", "--config", configFile.Name(), "--verb", "3")
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		os.Remove(configFile.Name())
+		return nil, err
+	}
+	// OpenVPN logs to stdout, so log its errors in the same stream
+	command.Stderr = command.Stdout
+
+	logger.Logf(t, "Connecting to Client VPN with OpenVPN")
+	if err := command.Start(); err != nil {
+		os.Remove(configFile.Name())
+		return nil, err
+	}
+	connection := &ClientVpnConnection{command: command, configPath: configFile.Name()}
+
+	connected := make(chan bool, 1)
+	output := []string{}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			line := scanner.Text()
+			output = append(output, line)
+			if strings.Contains(line, openVpnConnectedMessage) {
+				connected <- true
+				// Keep reading, so that OpenVPN does not block on a full pipe
+				for scanner.Scan() {
+				}
+				return
+			}
+		}
+		connected <- false
+	}()
+
+	select {
+	case ok := <-connected:
+		if ok {
+			logger.Logf(t, "Connected to Client VPN")
+			return connection, nil
+		}
+		connection.Close()
+		return nil, OpenVpnConnectionFailed{Reason: "OpenVPN exited", Output: output}
+	case <-time.After(timeout):
+		connection.Close()
+		// Wait for the reader to see the end of the output, so that it is not appended to while returning it
+		<-connected
+		return nil, OpenVpnConnectionFailed{Reason: fmt.Sprintf("not connected after %s", timeout), Output: output}
+	}
+}
+
+// WaitForTcpReachableOverClientVpn waits until a TCP connection can be made to the given address, e.g. a private IP
+// and port of an instance behind a Client VPN endpoint. This will fail the test if it can't be.
+func WaitForTcpReachableOverClientVpn(t testing.TestingT, address string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForTcpReachableOverClientVpnE(t, address, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForTcpReachableOverClientVpnE waits until a TCP connection can be made to the given address, e.g. a private IP
+// and port of an instance behind a Client VPN endpoint, which verifies that the authorization rules, routes and
+// security groups of the endpoint let the client through. Connections are retried, as the routes of the tunnel can take
+// a few seconds to work after connecting.
+func WaitForTcpReachableOverClientVpnE(t testing.TestingT, address string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Connect to %s over Client VPN", address)
+	_, err := retry.DoWithRetryE(t, description, maxRetries, sleepBetweenRetries, func() (string, error) {
+		conn, err := net.DialTimeout("tcp", address, clientVpnDialTimeout)
+		if err != nil {
+			return "", err
+		}
+		conn.Close()
+		return "", nil
+	})
+	return err
+}
@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClientVpnEndpoint(status string) *ec2.ClientVpnEndpoint {
+	return &ec2.ClientVpnEndpoint{
+		ClientVpnEndpointId: aws.String("cvpn-endpoint-123"),
+		Status:              &ec2.ClientVpnEndpointStatus{Code: aws.String(status)},
+	}
+}
+
+func TestClientVpnWaitState(t *testing.T) {
+	t.Parallel()
+
+	activeRule := &ec2.AuthorizationRule{DestinationCidr: aws.String("10.0.0.0/16"), Status: &ec2.ClientVpnAuthorizationRuleStatus{Code: aws.String("active")}}
+	authorizingRule := &ec2.AuthorizationRule{DestinationCidr: aws.String("10.1.0.0/16"), Status: &ec2.ClientVpnAuthorizationRuleStatus{Code: aws.String("authorizing")}}
+	activeRoute := &ec2.ClientVpnRoute{DestinationCidr: aws.String("10.0.0.0/16"), Status: &ec2.ClientVpnRouteStatus{Code: aws.String("active")}}
+	creatingRoute := &ec2.ClientVpnRoute{DestinationCidr: aws.String("10.1.0.0/16"), Status: &ec2.ClientVpnRouteStatus{Code: aws.String("creating")}}
+
+	testCases := []struct {
+		name     string
+		endpoint *ec2.ClientVpnEndpoint
+		rules    []*ec2.AuthorizationRule
+		routes   []*ec2.ClientVpnRoute
+		expected WaitState
+	}{
+		{
+			"pending associate",
+			testClientVpnEndpoint("pending-associate"), nil, nil,
+			WaitState{Current: "pending-associate, 0 authorization rules and routes pending"},
+		},
+		{
+			"rules and routes pending",
+			testClientVpnEndpoint("available"), []*ec2.AuthorizationRule{activeRule, authorizingRule}, []*ec2.ClientVpnRoute{activeRoute, creatingRoute},
+			WaitState{Current: "available, 2 authorization rules and routes pending"},
+		},
+		{
+			"available",
+			testClientVpnEndpoint("available"), []*ec2.AuthorizationRule{activeRule}, []*ec2.ClientVpnRoute{activeRoute},
+			WaitState{Current: "available, 0 authorization rules and routes pending", Done: true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			state, err := clientVpnWaitState(testCase.endpoint, testCase.rules, testCase.routes)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, state)
+		})
+	}
+}
+
+func TestClientVpnWaitStateFailed(t *testing.T) {
+	t.Parallel()
+
+	_, err := clientVpnWaitState(testClientVpnEndpoint("deleting"), nil, nil)
+	require.IsType(t, retry.FatalError{}, err)
+	assert.Equal(t, ClientVpnEndpointFailed{EndpointID: "cvpn-endpoint-123", Resource: "endpoint", Status: "deleting"}, err.(retry.FatalError).Underlying)
+
+	failedRoute := &ec2.ClientVpnRoute{
+		DestinationCidr: aws.String("0.0.0.0/0"),
+		Status:          &ec2.ClientVpnRouteStatus{Code: aws.String("failed"), Message: aws.String("no internet gateway")},
+	}
+	_, err = clientVpnWaitState(testClientVpnEndpoint("available"), nil, []*ec2.ClientVpnRoute{failedRoute})
+	require.IsType(t, retry.FatalError{}, err)
+	assert.EqualError(t, err.(retry.FatalError).Underlying, "The route to 0.0.0.0/0 of Client VPN endpoint cvpn-endpoint-123 is failed: no internet gateway")
+}
+
+func TestAddClientCertificateToOpenVpnConfig(t *testing.T) {
+	t.Parallel()
+
+	configuration := "client\ndev tun\nremote cvpn-endpoint-123.prod.clientvpn.us-east-1.amazonaws.com 443\n<ca>\nCA\n</ca>\n"
+	expected := "client\ndev tun\nremote cvpn-endpoint-123.prod.clientvpn.us-east-1.amazonaws.com 443\n<ca>\nCA\n</ca>\n<cert>\nCERT\n</cert>\n<key>\nKEY\n</key>\n"
+
+	withCertificate := AddClientCertificateToOpenVpnConfig(configuration, "CERT\n", "KEY\n")
+	assert.Equal(t, expected, withCertificate)
+
+	// Adding another certificate replaces the one that is there
+	assert.Equal(t, expected, AddClientCertificateToOpenVpnConfig(AddClientCertificateToOpenVpnConfig(configuration, "OLD", "OLD"), "CERT", "KEY"))
+}
@@ -0,0 +1,257 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cloudFormationDriftDetectionMaxRetries          = 60
+	cloudFormationDriftDetectionSleepBetweenRetries = 5 * time.Second
+)
+
+// CloudFormationStackDrift is the result of a drift detection on a CloudFormation stack.
+type CloudFormationStackDrift struct {
+	StackName        string
+	Status           string // One of the cloudformation.StackDriftStatus values, e.g. IN_SYNC or DRIFTED
+	DriftedResources []*cloudformation.StackResourceDrift
+}
+
+// GetCloudFormationStack gets the CloudFormation stack with the given name or ID.
+func GetCloudFormationStack(t testing.TestingT, region string, stackName string) *cloudformation.Stack {
+	stack, err := GetCloudFormationStackE(t, region, stackName)
+	require.NoError(t, err)
+	return stack
+}
+
+// GetCloudFormationStackE gets the CloudFormation stack with the given name or ID.
+func GetCloudFormationStackE(t testing.TestingT, region string, stackName string) (*cloudformation.Stack, error) {
+	client, err := NewCloudFormationClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.Stacks) != 1 {
+		return nil, fmt.Errorf("Expected to find one CloudFormation stack named %s but found %d", stackName, len(output.Stacks))
+	}
+	return output.Stacks[0], nil
+}
+
+// WaitForCloudFormationStackComplete waits until the given CloudFormation stack reaches CREATE_COMPLETE,
+// UPDATE_COMPLETE or IMPORT_COMPLETE, failing early if it reaches a failed or rolled back state instead.
+func WaitForCloudFormationStackComplete(t testing.TestingT, region string, stackName string, maxRetries int, sleepBetweenRetries time.Duration) *cloudformation.Stack {
+	stack, err := WaitForCloudFormationStackCompleteE(t, region, stackName, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return stack
+}
+
+// WaitForCloudFormationStackCompleteE waits until the given CloudFormation stack reaches CREATE_COMPLETE,
+// UPDATE_COMPLETE or IMPORT_COMPLETE, returning a CloudFormationStackFailed error early if it reaches a failed or
+// rolled back state instead.
+func WaitForCloudFormationStackCompleteE(t testing.TestingT, region string, stackName string, maxRetries int, sleepBetweenRetries time.Duration) (*cloudformation.Stack, error) {
+	var stack *cloudformation.Stack
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("CloudFormation stack %s", stackName),
+		DesiredState: "complete",
+		Extract: func() (WaitState, error) {
+			found, err := GetCloudFormationStackE(t, region, stackName)
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			status := aws.StringValue(found.StackStatus)
+			switch {
+			case isCloudFormationStackComplete(status):
+				stack = found
+				return WaitState{Current: status, Done: true}, nil
+			case strings.HasSuffix(status, "_IN_PROGRESS"):
+				return WaitState{Current: status}, nil
+			default:
+				return WaitState{}, retry.FatalError{Underlying: CloudFormationStackFailed{
+					StackName: stackName,
+					Status:    status,
+					Reason:    aws.StringValue(found.StackStatusReason),
+				}}
+			}
+		},
+	}
+
+	err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	return stack, err
+}
+
+// GetCloudFormationStackOutputs gets the outputs of the given CloudFormation stack as a map of output key to value.
+func GetCloudFormationStackOutputs(t testing.TestingT, region string, stackName string) map[string]string {
+	outputs, err := GetCloudFormationStackOutputsE(t, region, stackName)
+	require.NoError(t, err)
+	return outputs
+}
+
+// GetCloudFormationStackOutputsE gets the outputs of the given CloudFormation stack as a map of output key to value.
+func GetCloudFormationStackOutputsE(t testing.TestingT, region string, stackName string) (map[string]string, error) {
+	stack, err := GetCloudFormationStackE(t, region, stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]string{}
+	for _, output := range stack.Outputs {
+		outputs[aws.StringValue(output.OutputKey)] = aws.StringValue(output.OutputValue)
+	}
+	return outputs, nil
+}
+
+// GetCloudFormationStackOutput gets the value of the output with the given key of the given CloudFormation stack.
+func GetCloudFormationStackOutput(t testing.TestingT, region string, stackName string, outputKey string) string {
+	value, err := GetCloudFormationStackOutputE(t, region, stackName, outputKey)
+	require.NoError(t, err)
+	return value
+}
+
+// GetCloudFormationStackOutputE gets the value of the output with the given key of the given CloudFormation stack.
+func GetCloudFormationStackOutputE(t testing.TestingT, region string, stackName string, outputKey string) (string, error) {
+	outputs, err := GetCloudFormationStackOutputsE(t, region, stackName)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := outputs[outputKey]
+	if !ok {
+		return "", CloudFormationStackOutputNotFound{StackName: stackName, OutputKey: outputKey}
+	}
+	return value, nil
+}
+
+// DetectCloudFormationStackDrift runs drift detection on the given CloudFormation stack, waits for it to finish and
+// returns the drift status along with the resources that were modified or deleted outside of CloudFormation.
+func DetectCloudFormationStackDrift(t testing.TestingT, region string, stackName string) *CloudFormationStackDrift {
+	drift, err := DetectCloudFormationStackDriftE(t, region, stackName)
+	require.NoError(t, err)
+	return drift
+}
+
+// DetectCloudFormationStackDriftE runs drift detection on the given CloudFormation stack, waits for it to finish and
+// returns the drift status along with the resources that were modified or deleted outside of CloudFormation.
+func DetectCloudFormationStackDriftE(t testing.TestingT, region string, stackName string) (*CloudFormationStackDrift, error) {
+	client, err := NewCloudFormationClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	detection, err := client.DetectStackDrift(&cloudformation.DetectStackDriftInput{StackName: aws.String(stackName)})
+	if err != nil {
+		return nil, err
+	}
+	detectionID := aws.StringValue(detection.StackDriftDetectionId)
+
+	drift := &CloudFormationStackDrift{StackName: stackName, DriftedResources: []*cloudformation.StackResourceDrift{}}
+	description := fmt.Sprintf("Waiting for drift detection %s of CloudFormation stack %s", detectionID, stackName)
+	_, err = retry.DoWithRetryE(t, description, cloudFormationDriftDetectionMaxRetries, cloudFormationDriftDetectionSleepBetweenRetries, func() (string, error) {
+		output, err := client.DescribeStackDriftDetectionStatus(&cloudformation.DescribeStackDriftDetectionStatusInput{StackDriftDetectionId: aws.String(detectionID)})
+		if err != nil {
+			return "", err
+		}
+
+		switch status := aws.StringValue(output.DetectionStatus); status {
+		case cloudformation.StackDriftDetectionStatusDetectionComplete:
+			drift.Status = aws.StringValue(output.StackDriftStatus)
+			return status, nil
+		case cloudformation.StackDriftDetectionStatusDetectionFailed:
+			return "", retry.FatalError{Underlying: fmt.Errorf("Drift detection of CloudFormation stack %s failed: %s", stackName, aws.StringValue(output.DetectionStatusReason))}
+		default:
+			return "", fmt.Errorf("Drift detection of CloudFormation stack %s is in state %s", stackName, status)
+		}
+	})
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return nil, actualErr.Underlying
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cloudformation.DescribeStackResourceDriftsInput{
+		StackName: aws.String(stackName),
+		StackResourceDriftStatusFilters: aws.StringSlice([]string{
+			cloudformation.StackResourceDriftStatusModified,
+			cloudformation.StackResourceDriftStatusDeleted,
+		}),
+	}
+	err = client.DescribeStackResourceDriftsPages(input, func(page *cloudformation.DescribeStackResourceDriftsOutput, lastPage bool) bool {
+		drift.DriftedResources = append(drift.DriftedResources, page.StackResourceDrifts...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return drift, nil
+}
+
+// DeleteCloudFormationStack deletes the given CloudFormation stack and waits for the deletion to complete. This is
+// typically deferred right after creating a stack in a test.
+func DeleteCloudFormationStack(t testing.TestingT, region string, stackName string) {
+	err := DeleteCloudFormationStackE(t, region, stackName)
+	require.NoError(t, err)
+}
+
+// DeleteCloudFormationStackE deletes the given CloudFormation stack and waits for the deletion to complete. This is
+// typically deferred right after creating a stack in a test.
+func DeleteCloudFormationStackE(t testing.TestingT, region string, stackName string) error {
+	if dryrun.Skip(t, "delete CloudFormation stack %s in %s", stackName, region) {
+		return nil
+	}
+
+	client, err := NewCloudFormationClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Deleting CloudFormation stack %s", stackName)
+	if _, err := client.DeleteStack(&cloudformation.DeleteStackInput{StackName: aws.String(stackName)}); err != nil {
+		return err
+	}
+
+	return client.WaitUntilStackDeleteComplete(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+}
+
+// NewCloudFormationClient creates a CloudFormation client.
+func NewCloudFormationClient(t testing.TestingT, region string) *cloudformation.CloudFormation {
+	client, err := NewCloudFormationClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewCloudFormationClientE creates a CloudFormation client.
+func NewCloudFormationClientE(t testing.TestingT, region string) (*cloudformation.CloudFormation, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudformation.New(sess), nil
+}
+
+// isCloudFormationStackComplete returns true if the given stack status means the last create, update or import of the
+// stack succeeded.
+func isCloudFormationStackComplete(status string) bool {
+	switch status {
+	case cloudformation.StackStatusCreateComplete, cloudformation.StackStatusUpdateComplete, cloudformation.StackStatusImportComplete:
+		return true
+	default:
+		return false
+	}
+}
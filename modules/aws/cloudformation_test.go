@@ -0,0 +1,18 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCloudFormationStackComplete(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []string{"CREATE_COMPLETE", "UPDATE_COMPLETE", "IMPORT_COMPLETE"} {
+		assert.True(t, isCloudFormationStackComplete(status), status)
+	}
+	for _, status := range []string{"CREATE_IN_PROGRESS", "UPDATE_COMPLETE_CLEANUP_IN_PROGRESS", "ROLLBACK_COMPLETE", "UPDATE_ROLLBACK_COMPLETE", "DELETE_COMPLETE"} {
+		assert.False(t, isCloudFormationStackComplete(status), status)
+	}
+}
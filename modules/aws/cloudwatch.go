@@ -1,11 +1,27 @@
 package aws
 
 import (
+	"fmt"
+	"regexp"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/gruntwork-io/terratest/modules/wait"
+	"github.com/stretchr/testify/require"
 )
 
+// cloudWatchLogsPollInterval is how often WaitForLogMessageE looks for new log events.
+const cloudWatchLogsPollInterval = 5 * time.Second
+
+// CloudWatchLogEvent is an event of a CloudWatch log stream.
+type CloudWatchLogEvent struct {
+	LogStreamName string
+	Timestamp     time.Time
+	Message       string
+}
+
 // GetCloudWatchLogEntries returns the CloudWatch log messages in the given region for the given log stream and log group.
 func GetCloudWatchLogEntries(t testing.TestingT, awsRegion string, logStreamName string, logGroupName string) []string {
 	out, err := GetCloudWatchLogEntriesE(t, awsRegion, logStreamName, logGroupName)
@@ -39,6 +55,152 @@ func GetCloudWatchLogEntriesE(t testing.TestingT, awsRegion string, logStreamNam
 	return entries, nil
 }
 
+// GetCloudWatchLogEvents returns the events of the given log stream of the given log group that were logged since the
+// given time, oldest first. This will fail the test if there is an error.
+func GetCloudWatchLogEvents(t testing.TestingT, awsRegion string, logGroupName string, logStreamName string, since time.Time) []CloudWatchLogEvent {
+	events, err := GetCloudWatchLogEventsE(t, awsRegion, logGroupName, logStreamName, since)
+	require.NoError(t, err)
+	return events
+}
+
+// GetCloudWatchLogEventsE returns the events of the given log stream of the given log group that were logged since the
+// given time, oldest first.
+func GetCloudWatchLogEventsE(t testing.TestingT, awsRegion string, logGroupName string, logStreamName string, since time.Time) ([]CloudWatchLogEvent, error) {
+	client, err := NewCloudWatchLogsClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(logGroupName),
+		LogStreamName: aws.String(logStreamName),
+		StartTime:     aws.Int64(aws.TimeUnixMilli(since)),
+		StartFromHead: aws.Bool(true),
+	}
+
+	events := []CloudWatchLogEvent{}
+	for {
+		output, err := client.GetLogEvents(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range output.Events {
+			events = append(events, cloudWatchLogEvent(logStreamName, event.Timestamp, event.Message))
+		}
+
+		// The end of the stream is reached when the same token is returned again
+		if output.NextForwardToken == nil || aws.StringValue(output.NextForwardToken) == aws.StringValue(input.NextToken) {
+			return events, nil
+		}
+		input.NextToken = output.NextForwardToken
+	}
+}
+
+// FilterCloudWatchLogs returns the events of all the log streams of the given log group that were logged since the
+// given time and match the given filter pattern. This will fail the test if there is an error.
+func FilterCloudWatchLogs(t testing.TestingT, awsRegion string, logGroupName string, filterPattern string, since time.Time) []CloudWatchLogEvent {
+	events, err := FilterCloudWatchLogsE(t, awsRegion, logGroupName, filterPattern, since)
+	require.NoError(t, err)
+	return events
+}
+
+// FilterCloudWatchLogsE returns the events of all the log streams of the given log group that were logged since the
+// given time and match the given filter pattern, in the CloudWatch Logs filter pattern syntax (e.g. ERROR, or
+// { $.level = "error" } for JSON events). An empty pattern matches every event.
+func FilterCloudWatchLogsE(t testing.TestingT, awsRegion string, logGroupName string, filterPattern string, since time.Time) ([]CloudWatchLogEvent, error) {
+	client, err := NewCloudWatchLogsClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(logGroupName),
+		StartTime:    aws.Int64(aws.TimeUnixMilli(since)),
+	}
+	if filterPattern != "" {
+		input.FilterPattern = aws.String(filterPattern)
+	}
+
+	events := []CloudWatchLogEvent{}
+	err = client.FilterLogEventsPages(input, func(page *cloudwatchlogs.FilterLogEventsOutput, lastPage bool) bool {
+		for _, event := range page.Events {
+			events = append(events, cloudWatchLogEvent(aws.StringValue(event.LogStreamName), event.Timestamp, event.Message))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// WaitForLogMessage waits until any log stream of the given log group has an event logged since the given time whose
+// message matches the given regular expression, and returns it. This will fail the test if that does not happen within
+// the given timeout.
+func WaitForLogMessage(t testing.TestingT, awsRegion string, logGroupName string, regex string, since time.Time, timeout time.Duration) CloudWatchLogEvent {
+	event, err := WaitForLogMessageE(t, awsRegion, logGroupName, regex, since, timeout)
+	require.NoError(t, err)
+	return event
+}
+
+// WaitForLogMessageE waits until any log stream of the given log group has an event logged since the given time whose
+// message matches the given regular expression, and returns it, e.g. to check that an application logged that it
+// started after it was deployed. Log events are available a few seconds after they are logged, so pass a time from
+// before the deployment. Returns a wait.TimedOut error if there is no such event within the given timeout.
+func WaitForLogMessageE(t testing.TestingT, awsRegion string, logGroupName string, regex string, since time.Time, timeout time.Duration) (CloudWatchLogEvent, error) {
+	compiled, err := regexp.Compile(regex)
+	if err != nil {
+		return CloudWatchLogEvent{}, err
+	}
+
+	var match *CloudWatchLogEvent
+	options := wait.Options{
+		Description: fmt.Sprintf("a log message matching %s in log group %s", regex, logGroupName),
+		Timeout:     timeout,
+		Trigger:     wait.Poll(cloudWatchLogsPollInterval),
+	}
+	err = wait.UntilE(t, options, func() (bool, error) {
+		events, err := FilterCloudWatchLogsE(t, awsRegion, logGroupName, "", since)
+		if err != nil {
+			return false, err
+		}
+
+		match = firstMatchingCloudWatchLogEvent(events, compiled)
+		if match == nil && len(events) > 0 {
+			// Only look at new events next time. Events logged in the same millisecond as the last one are looked at
+			// again, as more of them may arrive.
+			since = events[len(events)-1].Timestamp
+		}
+		return match != nil, nil
+	})
+	if err != nil {
+		return CloudWatchLogEvent{}, err
+	}
+	return *match, nil
+}
+
+// firstMatchingCloudWatchLogEvent returns the first of the given log events whose message matches the given regular
+// expression, or nil if there is none.
+func firstMatchingCloudWatchLogEvent(events []CloudWatchLogEvent, regex *regexp.Regexp) *CloudWatchLogEvent {
+	for i := range events {
+		if regex.MatchString(events[i].Message) {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+// cloudWatchLogEvent converts the given fields of an event of the given log stream to a CloudWatchLogEvent.
+func cloudWatchLogEvent(logStreamName string, timestamp *int64, message *string) CloudWatchLogEvent {
+	milliseconds := aws.Int64Value(timestamp)
+	return CloudWatchLogEvent{
+		LogStreamName: logStreamName,
+		Timestamp:     time.Unix(0, milliseconds*int64(time.Millisecond)),
+		Message:       aws.StringValue(message),
+	}
+}
+
 // NewCloudWatchLogsClient creates a new CloudWatch Logs client.
 func NewCloudWatchLogsClient(t testing.TestingT, region string) *cloudwatchlogs.CloudWatchLogs {
 	client, err := NewCloudWatchLogsClientE(t, region)
@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstMatchingCloudWatchLogEvent(t *testing.T) {
+	t.Parallel()
+
+	events := []CloudWatchLogEvent{
+		{LogStreamName: "a", Message: "Starting server"},
+		{LogStreamName: "b", Message: "Listening on port 8080"},
+		{LogStreamName: "a", Message: "Listening on port 9090"},
+	}
+
+	match := firstMatchingCloudWatchLogEvent(events, regexp.MustCompile(`Listening on port \d+`))
+	require.NotNil(t, match)
+	assert.Equal(t, events[1], *match)
+
+	assert.Nil(t, firstMatchingCloudWatchLogEvent(events, regexp.MustCompile("panic")))
+	assert.Nil(t, firstMatchingCloudWatchLogEvent(nil, regexp.MustCompile(".*")))
+}
+
+func TestCloudWatchLogEvent(t *testing.T) {
+	t.Parallel()
+
+	event := cloudWatchLogEvent("my-stream", aws.Int64(1600000000123), aws.String("hello\n"))
+
+	assert.Equal(t, "my-stream", event.LogStreamName)
+	assert.Equal(t, "hello\n", event.Message)
+	assert.True(t, time.Date(2020, 9, 13, 12, 26, 40, 123000000, time.UTC).Equal(event.Timestamp))
+}
@@ -0,0 +1,127 @@
+package aws
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// describeCache holds the results of read-only describe calls made by a test, so that helpers called repeatedly, e.g.
+// in retry loops, don't run into the rate limits of the AWS APIs.
+type describeCache struct {
+	ttl        time.Duration
+	mutex      sync.Mutex
+	entries    map[string]describeCacheEntry
+	generation int // incremented on every invalidation, so results of describe calls that were in flight are not cached
+}
+
+type describeCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+var (
+	describeCachesMutex sync.Mutex
+	describeCaches      = map[testing.TestingT]*describeCache{}
+)
+
+// EnableDescribeCache makes the read-only describe calls of this package made for the given test, such as looking up
+// the instances of an ASG or the IPs of EC2 Instances, reuse their results for up to the given time to live. This is
+// useful for tests that call such helpers in polling loops, e.g. while retrying to fetch files from instances. The
+// cache is dropped when the test finishes if t supports Cleanup, as testing.T does; otherwise call
+// DisableDescribeCache. Mutating helpers of this package, such as TerminateInstanceE, invalidate the cache; call
+// InvalidateDescribeCache after changing resources by other means, e.g. by running terraform apply.
+func EnableDescribeCache(t testing.TestingT, ttl time.Duration) {
+	describeCachesMutex.Lock()
+	describeCaches[t] = &describeCache{ttl: ttl, entries: map[string]describeCacheEntry{}}
+	describeCachesMutex.Unlock()
+
+	cleanupT, ok := t.(interface{ Cleanup(func()) })
+	if !ok {
+		logger.Logf(t, "WARNING: Cannot register the describe cache for cleanup because %T does not support Cleanup", t)
+		return
+	}
+	cleanupT.Cleanup(func() { DisableDescribeCache(t) })
+}
+
+// DisableDescribeCache stops caching the describe calls made for the given test and drops what is cached.
+func DisableDescribeCache(t testing.TestingT) {
+	describeCachesMutex.Lock()
+	defer describeCachesMutex.Unlock()
+
+	delete(describeCaches, t)
+}
+
+// InvalidateDescribeCache drops the cached results of the describe calls made for the given test, e.g. after changing
+// the resources they describe. This is a no-op if caching is not enabled for the test.
+func InvalidateDescribeCache(t testing.TestingT) {
+	cache := getDescribeCache(t)
+	if cache == nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries = map[string]describeCacheEntry{}
+	cache.generation++
+}
+
+func getDescribeCache(t testing.TestingT) *describeCache {
+	describeCachesMutex.Lock()
+	defer describeCachesMutex.Unlock()
+
+	return describeCaches[t]
+}
+
+// cachedDescribeE returns the cached result for the given key if caching is enabled for the given test and the result
+// has not expired yet. Otherwise it calls describe and, if that succeeds and caching is enabled, caches its result.
+// Errors are never cached. The caller must not modify the returned value, as it may be shared with other callers.
+func cachedDescribeE(t testing.TestingT, key string, describe func() (interface{}, error)) (interface{}, error) {
+	cache := getDescribeCache(t)
+	if cache == nil {
+		return describe()
+	}
+
+	cache.mutex.Lock()
+	entry, ok := cache.entries[key]
+	generation := cache.generation
+	cache.mutex.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := describe()
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mutex.Lock()
+	if cache.generation == generation {
+		cache.entries[key] = describeCacheEntry{value: value, expires: time.Now().Add(cache.ttl)}
+	}
+	cache.mutex.Unlock()
+
+	return value, nil
+}
+
+// describeCacheKey returns the cache key of the describe call of the given kind in the given region for the given
+// resource IDs, which may be in any order.
+func describeCacheKey(kind string, region string, ids ...string) string {
+	sortedIds := append([]string{}, ids...)
+	sort.Strings(sortedIds)
+	return strings.Join(append([]string{kind, region}, sortedIds...), "/")
+}
+
+// copyStringMap returns a copy of the given map, so that callers can't modify a cached map.
+func copyStringMap(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	for key, value := range in {
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedDescribeE(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	describe := func() (interface{}, error) {
+		calls++
+		return []string{"i-0123"}, nil
+	}
+
+	// Without the cache enabled, every call is made
+	_, err := cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	_, err = cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	EnableDescribeCache(t, time.Hour)
+
+	value, err := cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-0123"}, value)
+	_, err = cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	_, err = cachedDescribeE(t, "other-key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+
+	InvalidateDescribeCache(t)
+	_, err = cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, 5, calls)
+
+	// Errors are not cached
+	_, err = cachedDescribeE(t, "failing-key", func() (interface{}, error) { return nil, errors.New("throttled") })
+	assert.Error(t, err)
+	_, err = cachedDescribeE(t, "failing-key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, 6, calls)
+
+	DisableDescribeCache(t)
+	_, err = cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, 7, calls)
+}
+
+func TestCachedDescribeEExpires(t *testing.T) {
+	t.Parallel()
+
+	EnableDescribeCache(t, time.Nanosecond)
+
+	calls := 0
+	describe := func() (interface{}, error) {
+		calls++
+		return nil, nil
+	}
+
+	_, err := cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = cachedDescribeE(t, "key", describe)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDescribeCacheKeyIgnoresIdOrder(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, describeCacheKey("ec2-public-ips", "us-east-1", "i-1", "i-2"), describeCacheKey("ec2-public-ips", "us-east-1", "i-2", "i-1"))
+	assert.NotEqual(t, describeCacheKey("ec2-public-ips", "us-east-1", "i-1"), describeCacheKey("ec2-public-ips", "us-west-2", "i-1"))
+}
@@ -1,8 +1,13 @@
 package aws
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -62,6 +67,188 @@ func GetDynamoDBTableE(t testing.TestingT, region string, tableName string) (*dy
 	return out.Table, err
 }
 
+// PutDynamoDbItem marshals the given item, e.g. a struct with dynamodbav tags, and writes it to the given table. This
+// will fail the test if there is an error.
+func PutDynamoDbItem(t testing.TestingT, region string, tableName string, item interface{}) {
+	err := PutDynamoDbItemE(t, region, tableName, item)
+	require.NoError(t, err)
+}
+
+// PutDynamoDbItemE marshals the given item, e.g. a struct with dynamodbav tags, and writes it to the given table,
+// replacing any item with the same key.
+func PutDynamoDbItemE(t testing.TestingT, region string, tableName string, item interface{}) error {
+	attributes, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+
+	client, err := NewDynamoDBClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      attributes,
+	})
+	return err
+}
+
+// GetDynamoDbItem reads the item with the given key, e.g. a struct with just the key attributes of the table, from the
+// given table and unmarshals it into out. This will fail the test if there is an error or the item does not exist.
+func GetDynamoDbItem(t testing.TestingT, region string, tableName string, key interface{}, out interface{}) {
+	err := GetDynamoDbItemE(t, region, tableName, key, out)
+	require.NoError(t, err)
+}
+
+// GetDynamoDbItemE reads the item with the given key, e.g. a struct with just the key attributes of the table, from the
+// given table and unmarshals it into out, which must be a pointer, e.g. to a struct with dynamodbav tags. The read is
+// strongly consistent, so an item written right before is found. Returns a DynamoDbItemNotFound error if there is no
+// item with the key.
+func GetDynamoDbItemE(t testing.TestingT, region string, tableName string, key interface{}, out interface{}) error {
+	keyAttributes, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		return err
+	}
+
+	client, err := NewDynamoDBClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	output, err := client.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(tableName),
+		Key:            keyAttributes,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return err
+	}
+	if len(output.Item) == 0 {
+		return DynamoDbItemNotFound{TableName: tableName, Key: fmt.Sprintf("%+v", key)}
+	}
+	return dynamodbattribute.UnmarshalMap(output.Item, out)
+}
+
+// WaitForDynamoDbTableActive waits until the given DynamoDB table is active. This will fail the test if it does not.
+func WaitForDynamoDbTableActive(t testing.TestingT, region string, tableName string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForDynamoDbTableActiveE(t, region, tableName, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForDynamoDbTableActiveE waits until the given DynamoDB table is active, e.g. after it is created or its capacity
+// is updated. Returns a DynamoDbTableUnavailable error as soon as the table is being deleted, archived, or can't be
+// accessed because of its KMS key.
+func WaitForDynamoDbTableActiveE(t testing.TestingT, region string, tableName string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("DynamoDB table %s", tableName),
+		DesiredState: dynamodb.TableStatusActive,
+		Extract: func() (WaitState, error) {
+			table, err := GetDynamoDBTableE(t, region, tableName)
+			if err != nil {
+				return WaitState{}, err
+			}
+			return dynamoDbTableWaitState(table)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// dynamoDbTableWaitState returns the state of the given DynamoDB table for WaitForDynamoDbTableActiveE. Returns a
+// retry.FatalError if the table will not become active.
+func dynamoDbTableWaitState(table *dynamodb.TableDescription) (WaitState, error) {
+	status := aws.StringValue(table.TableStatus)
+	switch status {
+	case dynamodb.TableStatusActive:
+		return WaitState{Current: status, Done: true}, nil
+	case dynamodb.TableStatusCreating, dynamodb.TableStatusUpdating:
+		return WaitState{Current: status}, nil
+	default:
+		return WaitState{}, retry.FatalError{Underlying: DynamoDbTableUnavailable{TableName: aws.StringValue(table.TableName), Status: status}}
+	}
+}
+
+// WaitForDynamoDbGsiActive waits until the given global secondary index of the given DynamoDB table is active and
+// backfilled. This will fail the test if it does not.
+func WaitForDynamoDbGsiActive(t testing.TestingT, region string, tableName string, indexName string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForDynamoDbGsiActiveE(t, region, tableName, indexName, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForDynamoDbGsiActiveE waits until the given global secondary index of the given DynamoDB table is active and
+// done backfilling the existing items of the table, so that queries on it see all of them. Returns a
+// DynamoDbTableUnavailable error as soon as the index is being deleted.
+func WaitForDynamoDbGsiActiveE(t testing.TestingT, region string, tableName string, indexName string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("global secondary index %s of DynamoDB table %s", indexName, tableName),
+		DesiredState: dynamodb.IndexStatusActive,
+		Extract: func() (WaitState, error) {
+			table, err := GetDynamoDBTableE(t, region, tableName)
+			if err != nil {
+				return WaitState{}, err
+			}
+			return dynamoDbGsiWaitState(table, indexName)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// dynamoDbGsiWaitState returns the state of the given global secondary index of the given DynamoDB table for
+// WaitForDynamoDbGsiActiveE. Returns a retry.FatalError if the index is being deleted.
+func dynamoDbGsiWaitState(table *dynamodb.TableDescription, indexName string) (WaitState, error) {
+	for _, index := range table.GlobalSecondaryIndexes {
+		if aws.StringValue(index.IndexName) != indexName {
+			continue
+		}
+
+		status := aws.StringValue(index.IndexStatus)
+		if status == dynamodb.IndexStatusDeleting {
+			return WaitState{}, retry.FatalError{Underlying: DynamoDbTableUnavailable{
+				TableName: aws.StringValue(table.TableName),
+				IndexName: indexName,
+				Status:    status,
+			}}
+		}
+		// New indexes are active while they are still backfilling
+		if aws.BoolValue(index.Backfilling) {
+			return WaitState{Current: status + " (backfilling)"}, nil
+		}
+		return WaitState{Current: status, Done: status == dynamodb.IndexStatusActive}, nil
+	}
+	// The index may not show up right away after the table is updated to add it
+	return WaitState{Current: "not found"}, nil
+}
+
+// AssertDynamoDbTableHasPointInTimeRecovery checks that point in time recovery is enabled for the given DynamoDB table.
+func AssertDynamoDbTableHasPointInTimeRecovery(t testing.TestingT, region string, tableName string) {
+	err := AssertDynamoDbTableHasPointInTimeRecoveryE(t, region, tableName)
+	require.NoError(t, err)
+}
+
+// AssertDynamoDbTableHasPointInTimeRecoveryE checks that point in time recovery is enabled for the given DynamoDB table
+// and returns an error if it is not.
+func AssertDynamoDbTableHasPointInTimeRecoveryE(t testing.TestingT, region string, tableName string) error {
+	client, err := NewDynamoDBClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	output, err := client.DescribeContinuousBackups(&dynamodb.DescribeContinuousBackupsInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return err
+	}
+
+	status := dynamodb.PointInTimeRecoveryStatusDisabled
+	if backups := output.ContinuousBackupsDescription; backups != nil && backups.PointInTimeRecoveryDescription != nil {
+		status = aws.StringValue(backups.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus)
+	}
+	if status != dynamodb.PointInTimeRecoveryStatusEnabled {
+		return fmt.Errorf("Point in time recovery of DynamoDB table %s is %s", tableName, status)
+	}
+
+	return nil
+}
+
 // NewDynamoDBClient creates a DynamoDB client.
 func NewDynamoDBClient(t testing.TestingT, region string) *dynamodb.DynamoDB {
 	client, err := NewDynamoDBClientE(t, region)
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoDbTableWaitState(t *testing.T) {
+	t.Parallel()
+
+	state, err := dynamoDbTableWaitState(&dynamodb.TableDescription{TableName: aws.String("my-table"), TableStatus: aws.String(dynamodb.TableStatusCreating)})
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "CREATING"}, state)
+
+	state, err = dynamoDbTableWaitState(&dynamodb.TableDescription{TableName: aws.String("my-table"), TableStatus: aws.String(dynamodb.TableStatusActive)})
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "ACTIVE", Done: true}, state)
+
+	_, err = dynamoDbTableWaitState(&dynamodb.TableDescription{TableName: aws.String("my-table"), TableStatus: aws.String(dynamodb.TableStatusDeleting)})
+	require.IsType(t, retry.FatalError{}, err)
+	assert.Equal(t, DynamoDbTableUnavailable{TableName: "my-table", Status: "DELETING"}, err.(retry.FatalError).Underlying)
+	assert.True(t, errors.Is(err.(retry.FatalError).Underlying, ErrOperationFailed))
+}
+
+func TestDynamoDbGsiWaitState(t *testing.T) {
+	t.Parallel()
+
+	table := func(status string, backfilling bool) *dynamodb.TableDescription {
+		return &dynamodb.TableDescription{
+			TableName: aws.String("my-table"),
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+				{IndexName: aws.String("other-index"), IndexStatus: aws.String(dynamodb.IndexStatusActive)},
+				{IndexName: aws.String("my-index"), IndexStatus: aws.String(status), Backfilling: aws.Bool(backfilling)},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		table    *dynamodb.TableDescription
+		expected WaitState
+	}{
+		{"not found", &dynamodb.TableDescription{TableName: aws.String("my-table")}, WaitState{Current: "not found"}},
+		{"creating", table(dynamodb.IndexStatusCreating, false), WaitState{Current: "CREATING"}},
+		{"backfilling", table(dynamodb.IndexStatusActive, true), WaitState{Current: "ACTIVE (backfilling)"}},
+		{"active", table(dynamodb.IndexStatusActive, false), WaitState{Current: "ACTIVE", Done: true}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			state, err := dynamoDbGsiWaitState(testCase.table, "my-index")
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, state)
+		})
+	}
+
+	_, err := dynamoDbGsiWaitState(table(dynamodb.IndexStatusDeleting, false), "my-index")
+	require.IsType(t, retry.FatalError{}, err)
+	assert.Equal(t, DynamoDbTableUnavailable{TableName: "my-table", IndexName: "my-index", Status: "DELETING"}, err.(retry.FatalError).Underlying)
+}
@@ -3,6 +3,7 @@ package aws
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
@@ -17,6 +18,10 @@ func DeleteEbsSnapshot(t testing.TestingT, region string, snapshot string) {
 
 // DeleteEbsSnapshot deletes the given EBS snapshot
 func DeleteEbsSnapshotE(t testing.TestingT, region string, snapshot string) error {
+	if dryrun.Skip(t, "delete EBS snapshot %s in %s", snapshot, region) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting EBS snapshot %s", snapshot)
 	ec2Client, err := NewEc2ClientE(t, region)
 	if err != nil {
@@ -0,0 +1,234 @@
+package aws
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Ec2InstanceAttributes contains the instance-level settings of an EC2 Instance that are commonly used to harden or
+// tune it.
+type Ec2InstanceAttributes struct {
+	InstanceType              string
+	EnaSupport                bool
+	EbsOptimized              bool
+	EnclaveEnabled            bool  // Whether AWS Nitro Enclaves are enabled
+	CoreCount                 int64 // Number of CPU cores
+	ThreadsPerCore            int64
+	HibernationConfigured     bool
+	SourceDestCheck           bool
+	DisableApiTermination     bool
+	MetadataHttpTokens        string // "required" when IMDSv2 is enforced, "optional" otherwise
+	MetadataHttpEndpoint      string // "enabled" or "disabled"
+	MetadataHopLimit          int64
+	RootDeviceType            string
+	IamInstanceProfileArn     string
+	MonitoringState           string
+	VirtualizationType        string
+	CapacityReservationTarget string // ID of the capacity reservation targeted at launch, if any
+}
+
+// GetInstanceAttributes returns the instance-level settings of the EC2 Instance with the given ID in the given region.
+func GetInstanceAttributes(t testing.TestingT, region string, instanceID string) Ec2InstanceAttributes {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	require.NoError(t, err)
+	return attributes
+}
+
+// GetInstanceAttributesE returns the instance-level settings of the EC2 Instance with the given ID in the given region.
+func GetInstanceAttributesE(t testing.TestingT, region string, instanceID string) (Ec2InstanceAttributes, error) {
+	instance, err := GetEc2InstanceE(t, region, instanceID)
+	if err != nil {
+		return Ec2InstanceAttributes{}, err
+	}
+
+	attributes := newEc2InstanceAttributes(instance)
+
+	// Termination protection is not part of the DescribeInstances response, so we have to look it up separately
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return Ec2InstanceAttributes{}, err
+	}
+
+	input := ec2.DescribeInstanceAttributeInput{
+		InstanceId: aws.String(instanceID),
+		Attribute:  aws.String(ec2.InstanceAttributeNameDisableApiTermination),
+	}
+	output, err := client.DescribeInstanceAttribute(&input)
+	if err != nil {
+		return Ec2InstanceAttributes{}, err
+	}
+	if output.DisableApiTermination != nil {
+		attributes.DisableApiTermination = aws.BoolValue(output.DisableApiTermination.Value)
+	}
+
+	return attributes, nil
+}
+
+// newEc2InstanceAttributes extracts the instance-level settings from the given EC2 Instance description.
+func newEc2InstanceAttributes(instance *ec2.Instance) Ec2InstanceAttributes {
+	attributes := Ec2InstanceAttributes{
+		InstanceType:       aws.StringValue(instance.InstanceType),
+		EnaSupport:         aws.BoolValue(instance.EnaSupport),
+		EbsOptimized:       aws.BoolValue(instance.EbsOptimized),
+		SourceDestCheck:    aws.BoolValue(instance.SourceDestCheck),
+		RootDeviceType:     aws.StringValue(instance.RootDeviceType),
+		VirtualizationType: aws.StringValue(instance.VirtualizationType),
+	}
+
+	if instance.EnclaveOptions != nil {
+		attributes.EnclaveEnabled = aws.BoolValue(instance.EnclaveOptions.Enabled)
+	}
+	if instance.CpuOptions != nil {
+		attributes.CoreCount = aws.Int64Value(instance.CpuOptions.CoreCount)
+		attributes.ThreadsPerCore = aws.Int64Value(instance.CpuOptions.ThreadsPerCore)
+	}
+	if instance.HibernationOptions != nil {
+		attributes.HibernationConfigured = aws.BoolValue(instance.HibernationOptions.Configured)
+	}
+	if instance.MetadataOptions != nil {
+		attributes.MetadataHttpTokens = aws.StringValue(instance.MetadataOptions.HttpTokens)
+		attributes.MetadataHttpEndpoint = aws.StringValue(instance.MetadataOptions.HttpEndpoint)
+		attributes.MetadataHopLimit = aws.Int64Value(instance.MetadataOptions.HttpPutResponseHopLimit)
+	}
+	if instance.IamInstanceProfile != nil {
+		attributes.IamInstanceProfileArn = aws.StringValue(instance.IamInstanceProfile.Arn)
+	}
+	if instance.Monitoring != nil {
+		attributes.MonitoringState = aws.StringValue(instance.Monitoring.State)
+	}
+	if instance.CapacityReservationSpecification != nil && instance.CapacityReservationSpecification.CapacityReservationTarget != nil {
+		attributes.CapacityReservationTarget = aws.StringValue(instance.CapacityReservationSpecification.CapacityReservationTarget.CapacityReservationId)
+	}
+
+	return attributes
+}
+
+// AssertEc2InstanceEnclaveEnabled checks that AWS Nitro Enclaves are enabled on the EC2 Instance with the given ID.
+func AssertEc2InstanceEnclaveEnabled(t testing.TestingT, region string, instanceID string) {
+	err := AssertEc2InstanceEnclaveEnabledE(t, region, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceEnclaveEnabledE checks that AWS Nitro Enclaves are enabled on the EC2 Instance with the given ID and
+// returns an error if they are not.
+func AssertEc2InstanceEnclaveEnabledE(t testing.TestingT, region string, instanceID string) error {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+	return checkEc2InstanceBoolAttribute(instanceID, "enclave support", true, attributes.EnclaveEnabled)
+}
+
+// AssertEc2InstanceEbsOptimized checks that the EC2 Instance with the given ID is EBS-optimized.
+func AssertEc2InstanceEbsOptimized(t testing.TestingT, region string, instanceID string) {
+	err := AssertEc2InstanceEbsOptimizedE(t, region, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceEbsOptimizedE checks that the EC2 Instance with the given ID is EBS-optimized and returns an error
+// if it is not.
+func AssertEc2InstanceEbsOptimizedE(t testing.TestingT, region string, instanceID string) error {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+	return checkEc2InstanceBoolAttribute(instanceID, "EBS optimization", true, attributes.EbsOptimized)
+}
+
+// AssertEc2InstanceEnaSupport checks that enhanced networking with ENA is enabled on the EC2 Instance with the given
+// ID.
+func AssertEc2InstanceEnaSupport(t testing.TestingT, region string, instanceID string) {
+	err := AssertEc2InstanceEnaSupportE(t, region, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceEnaSupportE checks that enhanced networking with ENA is enabled on the EC2 Instance with the given
+// ID and returns an error if it is not.
+func AssertEc2InstanceEnaSupportE(t testing.TestingT, region string, instanceID string) error {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+	return checkEc2InstanceBoolAttribute(instanceID, "ENA support", true, attributes.EnaSupport)
+}
+
+// AssertEc2InstanceHibernationConfigured checks that the EC2 Instance with the given ID was launched with hibernation
+// enabled.
+func AssertEc2InstanceHibernationConfigured(t testing.TestingT, region string, instanceID string) {
+	err := AssertEc2InstanceHibernationConfiguredE(t, region, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceHibernationConfiguredE checks that the EC2 Instance with the given ID was launched with hibernation
+// enabled and returns an error if it was not.
+func AssertEc2InstanceHibernationConfiguredE(t testing.TestingT, region string, instanceID string) error {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+	return checkEc2InstanceBoolAttribute(instanceID, "hibernation", true, attributes.HibernationConfigured)
+}
+
+// AssertEc2InstanceRequiresImdsV2 checks that the EC2 Instance with the given ID only allows access to the instance
+// metadata service with session tokens (IMDSv2).
+func AssertEc2InstanceRequiresImdsV2(t testing.TestingT, region string, instanceID string) {
+	err := AssertEc2InstanceRequiresImdsV2E(t, region, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceRequiresImdsV2E checks that the EC2 Instance with the given ID only allows access to the instance
+// metadata service with session tokens (IMDSv2) and returns an error if it does not.
+func AssertEc2InstanceRequiresImdsV2E(t testing.TestingT, region string, instanceID string) error {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+	return checkEc2InstanceAttribute(instanceID, "metadata http tokens", ec2.HttpTokensStateRequired, attributes.MetadataHttpTokens)
+}
+
+// AssertEc2InstanceTerminationProtected checks that termination protection is enabled on the EC2 Instance with the
+// given ID.
+func AssertEc2InstanceTerminationProtected(t testing.TestingT, region string, instanceID string) {
+	err := AssertEc2InstanceTerminationProtectedE(t, region, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceTerminationProtectedE checks that termination protection is enabled on the EC2 Instance with the
+// given ID and returns an error if it is not.
+func AssertEc2InstanceTerminationProtectedE(t testing.TestingT, region string, instanceID string) error {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+	return checkEc2InstanceBoolAttribute(instanceID, "termination protection", true, attributes.DisableApiTermination)
+}
+
+// AssertEc2InstanceCpuOptions checks that the EC2 Instance with the given ID was launched with the given number of CPU
+// cores and threads per core.
+func AssertEc2InstanceCpuOptions(t testing.TestingT, region string, instanceID string, coreCount int64, threadsPerCore int64) {
+	err := AssertEc2InstanceCpuOptionsE(t, region, instanceID, coreCount, threadsPerCore)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceCpuOptionsE checks that the EC2 Instance with the given ID was launched with the given number of CPU
+// cores and threads per core and returns an error if it was not.
+func AssertEc2InstanceCpuOptionsE(t testing.TestingT, region string, instanceID string, coreCount int64, threadsPerCore int64) error {
+	attributes, err := GetInstanceAttributesE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEc2InstanceAttribute(instanceID, "core count", strconv.FormatInt(coreCount, 10), strconv.FormatInt(attributes.CoreCount, 10)); err != nil {
+		return err
+	}
+	return checkEc2InstanceAttribute(instanceID, "threads per core", strconv.FormatInt(threadsPerCore, 10), strconv.FormatInt(attributes.ThreadsPerCore, 10))
+}
+
+// checkEc2InstanceBoolAttribute returns an Ec2InstanceAttributeMismatch error if expected and actual differ.
+func checkEc2InstanceBoolAttribute(instanceID string, attribute string, expected bool, actual bool) error {
+	return checkEc2InstanceAttribute(instanceID, attribute, strconv.FormatBool(expected), strconv.FormatBool(actual))
+}
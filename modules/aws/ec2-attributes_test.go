@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEc2InstanceAttributes(t *testing.T) {
+	t.Parallel()
+
+	instance := &ec2.Instance{
+		InstanceType:       aws.String("m5.xlarge"),
+		EnaSupport:         aws.Bool(true),
+		EbsOptimized:       aws.Bool(true),
+		SourceDestCheck:    aws.Bool(false),
+		EnclaveOptions:     &ec2.EnclaveOptions{Enabled: aws.Bool(true)},
+		CpuOptions:         &ec2.CpuOptions{CoreCount: aws.Int64(2), ThreadsPerCore: aws.Int64(1)},
+		HibernationOptions: &ec2.HibernationOptions{Configured: aws.Bool(true)},
+		MetadataOptions: &ec2.InstanceMetadataOptionsResponse{
+			HttpTokens:              aws.String(ec2.HttpTokensStateRequired),
+			HttpEndpoint:            aws.String(ec2.InstanceMetadataEndpointStateEnabled),
+			HttpPutResponseHopLimit: aws.Int64(1),
+		},
+	}
+
+	attributes := newEc2InstanceAttributes(instance)
+	assert.Equal(t, "m5.xlarge", attributes.InstanceType)
+	assert.True(t, attributes.EnaSupport)
+	assert.True(t, attributes.EbsOptimized)
+	assert.False(t, attributes.SourceDestCheck)
+	assert.True(t, attributes.EnclaveEnabled)
+	assert.Equal(t, int64(2), attributes.CoreCount)
+	assert.Equal(t, int64(1), attributes.ThreadsPerCore)
+	assert.True(t, attributes.HibernationConfigured)
+	assert.Equal(t, ec2.HttpTokensStateRequired, attributes.MetadataHttpTokens)
+	assert.Equal(t, int64(1), attributes.MetadataHopLimit)
+}
+
+func TestCheckEc2InstanceBoolAttribute(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, checkEc2InstanceBoolAttribute("i-123", "ENA support", true, true))
+	assert.EqualError(t, checkEc2InstanceBoolAttribute("i-123", "ENA support", true, false), "Expected ENA support of EC2 Instance i-123 to be true but got false")
+}
@@ -0,0 +1,198 @@
+package aws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2instanceconnect"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+// The serial console only ever exposes port 0 of an instance. See
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/connect-to-serial-console.html
+const serialConsolePort = 0
+
+// GetConsoleScreenshot returns a JPG screenshot of the console of the EC2 Instance with the given ID in the given
+// region. This is very useful for debugging instances that never become reachable over the network, such as Windows
+// instances stuck at boot.
+func GetConsoleScreenshot(t testing.TestingT, awsRegion string, instanceID string) []byte {
+	screenshot, err := GetConsoleScreenshotE(t, awsRegion, instanceID)
+	require.NoError(t, err)
+	return screenshot
+}
+
+// GetConsoleScreenshotE returns a JPG screenshot of the console of the EC2 Instance with the given ID in the given
+// region. This is very useful for debugging instances that never become reachable over the network, such as Windows
+// instances stuck at boot.
+func GetConsoleScreenshotE(t testing.TestingT, awsRegion string, instanceID string) ([]byte, error) {
+	logger.Logf(t, "Fetching console screenshot for Instance %s in %s", instanceID, awsRegion)
+
+	client, err := NewEc2ClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	input := ec2.GetConsoleScreenshotInput{
+		InstanceId: aws.String(instanceID),
+		WakeUp:     aws.Bool(true),
+	}
+	out, err := client.GetConsoleScreenshot(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(aws.StringValue(out.ImageData))
+}
+
+// GetLatestConsoleOutput returns the most recent console output of the EC2 Instance with the given ID in the given
+// region. Unlike GetSyslogForInstance, this does not wait for the output to become available, so it may return an
+// empty string for instances that have just launched.
+func GetLatestConsoleOutput(t testing.TestingT, awsRegion string, instanceID string) string {
+	out, err := GetLatestConsoleOutputE(t, awsRegion, instanceID)
+	require.NoError(t, err)
+	return out
+}
+
+// GetLatestConsoleOutputE returns the most recent console output of the EC2 Instance with the given ID in the given
+// region. Unlike GetSyslogForInstanceE, this does not wait for the output to become available, so it may return an
+// empty string for instances that have just launched.
+func GetLatestConsoleOutputE(t testing.TestingT, awsRegion string, instanceID string) (string, error) {
+	client, err := NewEc2ClientE(t, awsRegion)
+	if err != nil {
+		return "", err
+	}
+
+	input := ec2.GetConsoleOutputInput{
+		InstanceId: aws.String(instanceID),
+		Latest:     aws.Bool(true),
+	}
+	out, err := client.GetConsoleOutput(&input)
+	if err != nil {
+		return "", err
+	}
+
+	outputBytes, err := base64.StdEncoding.DecodeString(aws.StringValue(out.Output))
+	if err != nil {
+		return "", err
+	}
+
+	return string(outputBytes), nil
+}
+
+// IsSerialConsoleAccessEnabled returns true if EC2 serial console access is enabled for the account in the given
+// region.
+func IsSerialConsoleAccessEnabled(t testing.TestingT, awsRegion string) bool {
+	enabled, err := IsSerialConsoleAccessEnabledE(t, awsRegion)
+	require.NoError(t, err)
+	return enabled
+}
+
+// IsSerialConsoleAccessEnabledE returns true if EC2 serial console access is enabled for the account in the given
+// region.
+func IsSerialConsoleAccessEnabledE(t testing.TestingT, awsRegion string) (bool, error) {
+	client, err := NewEc2ClientE(t, awsRegion)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := client.GetSerialConsoleAccessStatus(&ec2.GetSerialConsoleAccessStatusInput{})
+	if err != nil {
+		return false, err
+	}
+
+	return aws.BoolValue(out.SerialConsoleAccessEnabled), nil
+}
+
+// CaptureSerialConsoleOutput pushes the public key of the given Key Pair to the serial console of the EC2 Instance with
+// the given ID, connects to the serial console over SSH and returns everything the instance writes to it during the
+// given duration. Serial console access must be enabled for the account (see IsSerialConsoleAccessEnabled).
+func CaptureSerialConsoleOutput(t testing.TestingT, awsRegion string, instanceID string, keyPair *ssh.KeyPair, duration time.Duration) string {
+	out, err := CaptureSerialConsoleOutputE(t, awsRegion, instanceID, keyPair, duration)
+	require.NoError(t, err)
+	return out
+}
+
+// CaptureSerialConsoleOutputE pushes the public key of the given Key Pair to the serial console of the EC2 Instance
+// with the given ID, connects to the serial console over SSH and returns everything the instance writes to it during
+// the given duration. Serial console access must be enabled for the account (see IsSerialConsoleAccessEnabledE).
+func CaptureSerialConsoleOutputE(t testing.TestingT, awsRegion string, instanceID string, keyPair *ssh.KeyPair, duration time.Duration) (string, error) {
+	sess, err := NewAuthenticatedSession(awsRegion)
+	if err != nil {
+		return "", err
+	}
+	client := ec2instanceconnect.New(sess)
+
+	logger.Logf(t, "Sending SSH public key to the serial console of Instance %s in %s", instanceID, awsRegion)
+	input := ec2instanceconnect.SendSerialConsoleSSHPublicKeyInput{
+		InstanceId:   aws.String(instanceID),
+		SSHPublicKey: aws.String(keyPair.PublicKey),
+		SerialPort:   aws.Int64(serialConsolePort),
+	}
+	if _, err := client.SendSerialConsoleSSHPublicKey(&input); err != nil {
+		return "", err
+	}
+
+	host := ssh.Host{
+		Hostname:    fmt.Sprintf("serial-console.ec2-instance-connect.%s.aws", awsRegion),
+		SshUserName: fmt.Sprintf("%s.port%d", instanceID, serialConsolePort),
+		SshKeyPair:  keyPair,
+	}
+
+	return ssh.CaptureShellOutputE(t, host, duration)
+}
+
+// SaveBootDiagnostics collects the latest console output and a console screenshot of the EC2 Instance with the given
+// ID and stores them locally at localDirectory/<instanceID>/. Call this when an SSH-based check fails to connect to an
+// instance to find out whether it is stuck in a boot loop or failed to run its User Data. FetchFilesFromAsgs calls it
+// when RemoteFileSpecification.BootDiagnosticsDir is set.
+func SaveBootDiagnostics(t testing.TestingT, awsRegion string, instanceID string, localDirectory string) {
+	err := SaveBootDiagnosticsE(t, awsRegion, instanceID, localDirectory)
+	require.NoError(t, err)
+}
+
+// SaveBootDiagnosticsE collects the latest console output and a console screenshot of the EC2 Instance with the given
+// ID and stores them locally at localDirectory/<instanceID>/. Call this when an SSH-based check fails to connect to an
+// instance to find out whether it is stuck in a boot loop or failed to run its User Data. Both artifacts are collected
+// even if one of them fails, and all errors are returned together. FetchFilesFromAsgsE calls it when
+// RemoteFileSpecification.BootDiagnosticsDir is set.
+func SaveBootDiagnosticsE(t testing.TestingT, awsRegion string, instanceID string, localDirectory string) error {
+	finalLocalDestDir := filepath.Join(localDirectory, instanceID)
+	if err := os.MkdirAll(finalLocalDestDir, 0755); err != nil {
+		return err
+	}
+
+	var errorsOccurred = new(multierror.Error)
+
+	consoleOutput, err := GetLatestConsoleOutputE(t, awsRegion, instanceID)
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(finalLocalDestDir, "console-output.log"), []byte(consoleOutput), 0644)
+	}
+	if err != nil {
+		errorsOccurred = multierror.Append(errorsOccurred, err)
+	}
+
+	screenshot, err := GetConsoleScreenshotE(t, awsRegion, instanceID)
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(finalLocalDestDir, "console-screenshot.jpg"), screenshot, 0644)
+	}
+	if err != nil {
+		errorsOccurred = multierror.Append(errorsOccurred, err)
+	}
+
+	if err := errorsOccurred.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Saved boot diagnostics for Instance %s in %s to %s", instanceID, awsRegion, finalLocalDestDir)
+	return nil
+}
@@ -0,0 +1,207 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// elasticIpPollInterval is how often WaitForElasticIpToReachInstanceE checks where an Elastic IP leads.
+const elasticIpPollInterval = 5 * time.Second
+
+// ElasticIpAssociation describes an Elastic IP and what it is associated with, if anything.
+type ElasticIpAssociation struct {
+	AllocationId       string
+	PublicIp           string
+	AssociationId      string // Empty if the Elastic IP is not associated
+	InstanceId         string // Empty if the Elastic IP is not associated with an EC2 Instance
+	NetworkInterfaceId string
+	PrivateIp          string
+}
+
+// ElasticIpProbe connects to the given public IP and returns the ID of the EC2 Instance that answers, e.g. by reading it
+// from a health check endpoint of the module under test. See NewSshElasticIpProbe.
+type ElasticIpProbe func(publicIp string) (string, error)
+
+// GetElasticIpAssociation returns the Elastic IP with the given allocation ID in the given region and what it is
+// associated with.
+func GetElasticIpAssociation(t testing.TestingT, region string, allocationID string) ElasticIpAssociation {
+	association, err := GetElasticIpAssociationE(t, region, allocationID)
+	require.NoError(t, err)
+	return association
+}
+
+// GetElasticIpAssociationE returns the Elastic IP with the given allocation ID in the given region and what it is
+// associated with.
+func GetElasticIpAssociationE(t testing.TestingT, region string, allocationID string) (ElasticIpAssociation, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return ElasticIpAssociation{}, err
+	}
+
+	output, err := client.DescribeAddresses(&ec2.DescribeAddressesInput{AllocationIds: aws.StringSlice([]string{allocationID})})
+	if err != nil {
+		return ElasticIpAssociation{}, err
+	}
+
+	if len(output.Addresses) == 0 {
+		return ElasticIpAssociation{}, NewNotFoundError("Elastic IP", allocationID, region)
+	}
+
+	return newElasticIpAssociation(output.Addresses[0]), nil
+}
+
+// newElasticIpAssociation extracts the association details from the given Elastic IP description.
+func newElasticIpAssociation(address *ec2.Address) ElasticIpAssociation {
+	return ElasticIpAssociation{
+		AllocationId:       aws.StringValue(address.AllocationId),
+		PublicIp:           aws.StringValue(address.PublicIp),
+		AssociationId:      aws.StringValue(address.AssociationId),
+		InstanceId:         aws.StringValue(address.InstanceId),
+		NetworkInterfaceId: aws.StringValue(address.NetworkInterfaceId),
+		PrivateIp:          aws.StringValue(address.PrivateIpAddress),
+	}
+}
+
+// AssertElasticIpAssociatedWithInstance checks that the Elastic IP with the given allocation ID is associated with the
+// EC2 Instance with the given ID.
+func AssertElasticIpAssociatedWithInstance(t testing.TestingT, region string, allocationID string, instanceID string) {
+	err := AssertElasticIpAssociatedWithInstanceE(t, region, allocationID, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertElasticIpAssociatedWithInstanceE checks that the Elastic IP with the given allocation ID is associated with the
+// EC2 Instance with the given ID and returns an ElasticIpAssociationMismatch error if it is not.
+func AssertElasticIpAssociatedWithInstanceE(t testing.TestingT, region string, allocationID string, instanceID string) error {
+	association, err := GetElasticIpAssociationE(t, region, allocationID)
+	if err != nil {
+		return err
+	}
+
+	return checkElasticIpAssociation(association, instanceID)
+}
+
+// checkElasticIpAssociation returns an ElasticIpAssociationMismatch error if the given Elastic IP is not associated with
+// the EC2 Instance with the given ID.
+func checkElasticIpAssociation(association ElasticIpAssociation, instanceID string) error {
+	if association.InstanceId != instanceID {
+		return ElasticIpAssociationMismatch{
+			AllocationId:       association.AllocationId,
+			PublicIp:           association.PublicIp,
+			ExpectedInstanceId: instanceID,
+			ActualInstanceId:   association.InstanceId,
+		}
+	}
+	return nil
+}
+
+// ReassociateElasticIp associates the Elastic IP with the given allocation ID with the EC2 Instance with the given ID,
+// moving it from whatever it is associated with, e.g. to simulate the failover of a module that flips an Elastic IP
+// between instances. Returns the ID of the new association. This will fail the test if there is an error.
+func ReassociateElasticIp(t testing.TestingT, region string, allocationID string, instanceID string) string {
+	associationID, err := ReassociateElasticIpE(t, region, allocationID, instanceID)
+	require.NoError(t, err)
+	return associationID
+}
+
+// ReassociateElasticIpE associates the Elastic IP with the given allocation ID with the EC2 Instance with the given ID,
+// moving it from whatever it is associated with, e.g. to simulate the failover of a module that flips an Elastic IP
+// between instances. Returns the ID of the new association. The Elastic IP may take a few seconds to route to the new
+// instance; use WaitForElasticIpToReachInstanceE to wait for that.
+func ReassociateElasticIpE(t testing.TestingT, region string, allocationID string, instanceID string) (string, error) {
+	if dryrun.Skip(t, "associate Elastic IP %s with Instance %s", allocationID, instanceID) {
+		return "", nil
+	}
+
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Associating Elastic IP %s with EC2 Instance %s", allocationID, instanceID)
+
+	output, err := client.AssociateAddress(&ec2.AssociateAddressInput{
+		AllocationId:       aws.String(allocationID),
+		InstanceId:         aws.String(instanceID),
+		AllowReassociation: aws.Bool(true),
+	})
+	InvalidateDescribeCache(t)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.AssociationId), nil
+}
+
+// WaitForElasticIpToReachInstance waits until the Elastic IP with the given allocation ID is associated with the EC2
+// Instance with the given ID and the given probe reaches that instance through it, or the given timeout expires. This
+// will fail the test if it does not.
+func WaitForElasticIpToReachInstance(t testing.TestingT, region string, allocationID string, instanceID string, probe ElasticIpProbe, timeout time.Duration) {
+	err := WaitForElasticIpToReachInstanceE(t, region, allocationID, instanceID, probe, timeout)
+	require.NoError(t, err)
+}
+
+// WaitForElasticIpToReachInstanceE waits until the Elastic IP with the given allocation ID is associated with the EC2
+// Instance with the given ID and the given probe reaches that instance through it, or the given timeout expires. This
+// verifies that connectivity follows the Elastic IP after a failover, not just its association, as connections can
+// keep reaching the previous instance for a while. Returns a WaitTimedOut error if that does not happen in time.
+func WaitForElasticIpToReachInstanceE(t testing.TestingT, region string, allocationID string, instanceID string, probe ElasticIpProbe, timeout time.Duration) error {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("Elastic IP %s", allocationID),
+		DesiredState: fmt.Sprintf("reaching EC2 Instance %s", instanceID),
+		Extract: func() (WaitState, error) {
+			association, err := GetElasticIpAssociationE(t, region, allocationID)
+			if err != nil {
+				return WaitState{}, err
+			}
+			return elasticIpWaitState(association, instanceID, probe), nil
+		},
+	}
+
+	maxRetries := int(timeout / elasticIpPollInterval)
+	return WaitForConditionE(t, condition, maxRetries, elasticIpPollInterval)
+}
+
+// elasticIpWaitState returns the state of the given Elastic IP for WaitForElasticIpToReachInstanceE, probing it only
+// once it is associated with the given EC2 Instance.
+func elasticIpWaitState(association ElasticIpAssociation, instanceID string, probe ElasticIpProbe) WaitState {
+	if association.InstanceId != instanceID {
+		associatedWith := association.InstanceId
+		if associatedWith == "" {
+			associatedWith = "nothing"
+		}
+		return WaitState{Current: fmt.Sprintf("associated with %s", associatedWith)}
+	}
+
+	reached, err := probe(association.PublicIp)
+	if err != nil {
+		return WaitState{Current: fmt.Sprintf("associated, %s unreachable (%s)", association.PublicIp, err)}
+	}
+	return WaitState{
+		Current: fmt.Sprintf("associated, %s reaches %s", association.PublicIp, reached),
+		Done:    reached == instanceID,
+	}
+}
+
+// NewSshElasticIpProbe returns an ElasticIpProbe that connects to the Elastic IP via SSH with the given username and Key
+// Pair and reads the ID of the EC2 Instance that answers from the instance data of cloud-init, which most Linux AMIs run.
+// As all the instances that the Elastic IP can be flipped between must accept the Key Pair, this suits modules whose
+// instances share one.
+func NewSshElasticIpProbe(t testing.TestingT, sshUserName string, keyPair *Ec2Keypair) ElasticIpProbe {
+	return func(publicIp string) (string, error) {
+		host := newInstanceSshHost(publicIp, nil, sshUserName, keyPair)
+		output, err := ssh.CheckSshCommandE(t, host, "cat /var/lib/cloud/data/instance-id")
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(output), nil
+	}
+}
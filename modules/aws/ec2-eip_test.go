@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckElasticIpAssociation(t *testing.T) {
+	t.Parallel()
+
+	association := newElasticIpAssociation(&ec2.Address{
+		AllocationId:  aws.String("eipalloc-123"),
+		PublicIp:      aws.String("203.0.113.10"),
+		AssociationId: aws.String("eipassoc-123"),
+		InstanceId:    aws.String("i-primary"),
+	})
+
+	assert.NoError(t, checkElasticIpAssociation(association, "i-primary"))
+
+	err := checkElasticIpAssociation(association, "i-standby")
+	assert.EqualError(t, err, "Expected Elastic IP eipalloc-123 (203.0.113.10) to be associated with EC2 Instance i-standby but it is associated with i-primary")
+
+	err = checkElasticIpAssociation(ElasticIpAssociation{AllocationId: "eipalloc-123", PublicIp: "203.0.113.10"}, "i-standby")
+	assert.EqualError(t, err, "Expected Elastic IP eipalloc-123 (203.0.113.10) to be associated with EC2 Instance i-standby but it is not associated with any instance")
+}
+
+func TestElasticIpWaitState(t *testing.T) {
+	t.Parallel()
+
+	probed := []string{}
+	reaching := "i-primary"
+	probe := func(publicIp string) (string, error) {
+		probed = append(probed, publicIp)
+		if reaching == "" {
+			return "", errors.New("connection refused")
+		}
+		return reaching, nil
+	}
+
+	association := ElasticIpAssociation{AllocationId: "eipalloc-123", PublicIp: "203.0.113.10", InstanceId: "i-primary"}
+
+	// The probe is not called until the Elastic IP is associated with the instance
+	assert.Equal(t, WaitState{Current: "associated with i-primary"}, elasticIpWaitState(association, "i-standby", probe))
+	assert.Equal(t, WaitState{Current: "associated with nothing"}, elasticIpWaitState(ElasticIpAssociation{PublicIp: "203.0.113.10"}, "i-standby", probe))
+	assert.Empty(t, probed)
+
+	association.InstanceId = "i-standby"
+	assert.Equal(t, WaitState{Current: "associated, 203.0.113.10 reaches i-primary"}, elasticIpWaitState(association, "i-standby", probe))
+
+	reaching = ""
+	assert.Equal(t, WaitState{Current: "associated, 203.0.113.10 unreachable (connection refused)"}, elasticIpWaitState(association, "i-standby", probe))
+
+	reaching = "i-standby"
+	assert.Equal(t, WaitState{Current: "associated, 203.0.113.10 reaches i-standby", Done: true}, elasticIpWaitState(association, "i-standby", probe))
+	assert.Equal(t, []string{"203.0.113.10", "203.0.113.10", "203.0.113.10"}, probed)
+}
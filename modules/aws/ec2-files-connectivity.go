@@ -0,0 +1,264 @@
+package aws
+
+import (
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+// ConnectivityMethod is a way of connecting to an EC2 Instance to fetch files from it.
+type ConnectivityMethod string
+
+const (
+	// ConnectViaPublicIp connects to the public IP of the Instance via SSH, or to its address of the configured
+	// AddressMode if there is one.
+	ConnectViaPublicIp ConnectivityMethod = "public-ip"
+
+	// ConnectViaBastion connects to the private IP of the Instance via SSH, or to its address of the configured
+	// AddressMode if there is one, proxying the connection through a bastion host.
+	ConnectViaBastion ConnectivityMethod = "bastion"
+
+	// ConnectViaSsm runs commands on the Instance with SSM, which needs no network access to it at all. See
+	// FetchFilesFromInstanceViaSSM.
+	ConnectViaSsm ConnectivityMethod = "ssm"
+)
+
+// AddressMode is which address of an EC2 Instance to connect to via SSH.
+type AddressMode string
+
+const (
+	// AddressPublicIp connects to the public IPv4 address of the Instance.
+	AddressPublicIp AddressMode = "public-ip"
+
+	// AddressPrivateIp connects to the private IPv4 address of the Instance, e.g. over a VPN into its VPC.
+	AddressPrivateIp AddressMode = "private-ip"
+
+	// AddressIpv6 connects to the IPv6 address of the primary network interface of the Instance, e.g. for instances in
+	// IPv6-only subnets.
+	AddressIpv6 AddressMode = "ipv6"
+
+	// AddressPublicDns connects to the public DNS name of the Instance.
+	AddressPublicDns AddressMode = "public-dns"
+)
+
+// orDefault returns the address mode, or if it is not set, the private IP if the connection goes through the given
+// bastion host and the public IP otherwise.
+func (mode AddressMode) orDefault(bastion *ssh.Host) AddressMode {
+	if mode != "" {
+		return mode
+	}
+	if bastion != nil {
+		return AddressPrivateIp
+	}
+	return AddressPublicIp
+}
+
+// getEc2InstanceAddressesE returns a map of instance ID to the address of the given mode of each of the given EC2
+// Instances.
+func getEc2InstanceAddressesE(t testing.TestingT, instanceIDs []string, awsRegion string, mode AddressMode) (map[string]string, error) {
+	switch mode {
+	case AddressPublicIp:
+		return GetPublicIpsOfEc2InstancesE(t, instanceIDs, awsRegion)
+	case AddressPrivateIp:
+		return GetPrivateIpsOfEc2InstancesE(t, instanceIDs, awsRegion)
+	case AddressIpv6:
+		return GetIpv6sOfEc2InstancesE(t, instanceIDs, awsRegion)
+	case AddressPublicDns:
+		return GetPublicHostnamesOfEc2InstancesE(t, instanceIDs, awsRegion)
+	default:
+		return nil, UnknownAddressMode{Mode: mode}
+	}
+}
+
+// getEc2InstanceAddressE returns the address of the given mode of the EC2 Instance with the given ID.
+func getEc2InstanceAddressE(t testing.TestingT, instanceID string, awsRegion string, mode AddressMode) (string, error) {
+	addresses, err := getEc2InstanceAddressesE(t, []string{instanceID}, awsRegion, mode)
+	if err != nil {
+		return "", err
+	}
+	return findEc2InstanceAddressE(addresses, instanceID, awsRegion, mode)
+}
+
+// findEc2InstanceAddressE returns the address of the EC2 Instance with the given ID from the given map of instance ID
+// to address, or an IpForEc2InstanceNotFound or HostnameForEc2InstanceNotFound error if it has no address of the given
+// mode, e.g. an IPv6 address in an IPv4-only subnet.
+func findEc2InstanceAddressE(addresses map[string]string, instanceID string, awsRegion string, mode AddressMode) (string, error) {
+	address := addresses[instanceID]
+	if address != "" {
+		return address, nil
+	}
+
+	switch mode {
+	case AddressPrivateIp:
+		return "", IpForEc2InstanceNotFound{InstanceId: instanceID, AwsRegion: awsRegion, Type: "private"}
+	case AddressIpv6:
+		return "", IpForEc2InstanceNotFound{InstanceId: instanceID, AwsRegion: awsRegion, Type: "IPv6"}
+	case AddressPublicDns:
+		return "", HostnameForEc2InstanceNotFound{InstanceId: instanceID, AwsRegion: awsRegion, Type: "public"}
+	default:
+		return "", IpForEc2InstanceNotFound{InstanceId: instanceID, AwsRegion: awsRegion, Type: "public"}
+	}
+}
+
+// instanceConnectivity is how to connect to EC2 Instances to fetch files from them.
+type instanceConnectivity struct {
+	order       []ConnectivityMethod
+	bastion     *ssh.Host
+	sshUserName string
+	keyPair     *Ec2Keypair
+	addressMode AddressMode       // Which address of the instances to connect to; defaults per method if not set
+	ips         *instanceIpLookup // Looks up the IPs of the instances in batches, if set
+}
+
+// methods returns the connectivity methods to try, in order. If none are configured, this is a bastion connection if a
+// bastion host is configured and a public IP connection otherwise.
+func (conn instanceConnectivity) methods() []ConnectivityMethod {
+	if len(conn.order) > 0 {
+		return conn.order
+	}
+	if conn.bastion != nil {
+		return []ConnectivityMethod{ConnectViaBastion}
+	}
+	return []ConnectivityMethod{ConnectViaPublicIp}
+}
+
+// sshBastion returns the bastion host to use for SSH connections with the given method.
+func (conn instanceConnectivity) sshBastion(method ConnectivityMethod) (*ssh.Host, error) {
+	switch method {
+	case ConnectViaPublicIp:
+		return nil, nil
+	case ConnectViaBastion:
+		if conn.bastion == nil {
+			return nil, BastionNotConfigured{}
+		}
+		return conn.bastion, nil
+	default:
+		return nil, UnknownConnectivityMethod{Method: method}
+	}
+}
+
+// sshHostE returns the host to connect to the EC2 Instance with the given ID via SSH with the given method.
+func (conn instanceConnectivity) sshHostE(t testing.TestingT, awsRegion string, method ConnectivityMethod, instanceID string) (ssh.Host, error) {
+	bastion, err := conn.sshBastion(method)
+	if err != nil {
+		return ssh.Host{}, err
+	}
+	if conn.ips == nil {
+		return getSshHostForInstanceE(t, awsRegion, bastion, conn.addressMode, conn.sshUserName, conn.keyPair, instanceID)
+	}
+
+	ip, err := conn.ips.getIpE(instanceID, awsRegion, conn.addressMode.orDefault(bastion))
+	if err != nil {
+		return ssh.Host{}, err
+	}
+	return newInstanceSshHost(ip, bastion, conn.sshUserName, conn.keyPair), nil
+}
+
+// withConnectivityFallbackE calls the given function with each of the given connectivity methods in order, until one
+// succeeds. If all of them fail, it returns a *multierror.Error with a ConnectivityMethodFailed for each method.
+func withConnectivityFallbackE(t testing.TestingT, instanceID string, methods []ConnectivityMethod, connect func(method ConnectivityMethod) error) error {
+	var errorsOccurred *multierror.Error
+
+	for _, method := range methods {
+		err := connect(method)
+		if err == nil {
+			return nil
+		}
+		if len(methods) > 1 {
+			logger.Logf(t, "Failed to connect to EC2 Instance %s via %s: %s", instanceID, method, err)
+		}
+		errorsOccurred = multierror.Append(errorsOccurred, ConnectivityMethodFailed{Method: method, Underlying: err})
+	}
+
+	return errorsOccurred.ErrorOrNil()
+}
+
+// FetchContentsOfFilesFromInstanceWithAuth connects to the EC2 Instance with the given ID as the given user with the
+// given SshAuth, trying each of its ConnectivityOrder methods in turn until one succeeds, fetches the contents of the
+// files at the given paths (using sudo if useSudo is true), and returns a map from file path to the contents of that
+// file as a string.
+func FetchContentsOfFilesFromInstanceWithAuth(t testing.TestingT, awsRegion string, auth *SshAuth, sshUserName string, instanceID string, useSudo bool, filePaths ...string) map[string]string {
+	out, err := FetchContentsOfFilesFromInstanceWithAuthE(t, awsRegion, auth, sshUserName, instanceID, useSudo, filePaths...)
+	require.NoError(t, err)
+	return out
+}
+
+// FetchContentsOfFilesFromInstanceWithAuthE connects to the EC2 Instance with the given ID as the given user with the
+// given SshAuth, trying each of its ConnectivityOrder methods in turn until one succeeds, fetches the contents of the
+// files at the given paths (using sudo if useSudo is true), and returns a map from file path to the contents of that
+// file as a string. If the SshAuth uses EC2 Instance Connect, its public key is pushed to the Instance, and to the
+// bastion if the connection goes through it and BastionInstanceId is set, before each file is fetched, as each file is
+// fetched over a new SSH connection and pushed keys only last 60 seconds.
+func FetchContentsOfFilesFromInstanceWithAuthE(t testing.TestingT, awsRegion string, auth *SshAuth, sshUserName string, instanceID string, useSudo bool, filePaths ...string) (map[string]string, error) {
+	conn := instanceConnectivity{
+		order:       auth.ConnectivityOrder,
+		bastion:     auth.Bastion,
+		sshUserName: sshUserName,
+		keyPair:     auth.KeyPair,
+		addressMode: auth.AddressMode,
+	}
+
+	var filePathToContents map[string]string
+	err := withConnectivityFallbackE(t, instanceID, conn.methods(), func(method ConnectivityMethod) error {
+		var err error
+		if method == ConnectViaSsm {
+			filePathToContents, err = FetchContentsOfFilesFromInstanceViaSSME(t, awsRegion, instanceID, filePaths...)
+			return err
+		}
+
+		host, err := conn.sshHostE(t, awsRegion, method, instanceID)
+		if err != nil {
+			return err
+		}
+
+		filePathToContents = map[string]string{}
+		for _, filePath := range filePaths {
+			if auth.Ec2InstanceConnect {
+				if err := sendInstanceConnectKeysE(t, awsRegion, auth, host, instanceID); err != nil {
+					return err
+				}
+			}
+
+			contents, err := ssh.FetchContentsOfFileE(t, host, useSudo, filePath)
+			if err != nil {
+				return err
+			}
+			filePathToContents[filePath] = contents
+		}
+		return nil
+	})
+
+	return filePathToContents, err
+}
+
+// sendInstanceConnectKeysE pushes the public key of the given SshAuth with EC2 Instance Connect to the EC2 Instance with
+// the given ID, for the user of the given host, and to the bastion if the host is connected to through it and the
+// SshAuth has a BastionInstanceId.
+func sendInstanceConnectKeysE(t testing.TestingT, awsRegion string, auth *SshAuth, host ssh.Host, instanceID string) error {
+	if host.JumpHost != nil && auth.BastionInstanceId != "" {
+		if err := SendSshPublicKeyE(t, awsRegion, auth.BastionInstanceId, host.JumpHost.SshUserName, auth.KeyPair.PublicKey); err != nil {
+			return err
+		}
+	}
+
+	return SendSshPublicKeyE(t, awsRegion, instanceID, host.SshUserName, auth.KeyPair.PublicKey)
+}
+
+// fetchFilesFromInstanceWithFallbackE downloads the files matching filenameFilters at the given remoteDirectory of the
+// EC2 Instance with the given ID, trying each of the connectivity methods in turn until one succeeds. The mode applies
+// to the files fetched via SSH.
+func fetchFilesFromInstanceWithFallbackE(t testing.TestingT, awsRegion string, conn instanceConnectivity, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string, mode fetchFilesMode) error {
+	return withConnectivityFallbackE(t, instanceID, conn.methods(), func(method ConnectivityMethod) error {
+		if method == ConnectViaSsm {
+			return FetchFilesFromInstanceViaSSME(t, awsRegion, instanceID, remoteDirectory, localDirectory, filenameFilters)
+		}
+
+		host, err := conn.sshHostE(t, awsRegion, method, instanceID)
+		if err != nil {
+			return err
+		}
+		return fetchFilesFromHostE(t, host, useSudo, remoteDirectory, localDirectory, filenameFilters, mode)
+	})
+}
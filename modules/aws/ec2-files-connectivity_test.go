@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceConnectivityMethodsDefaults(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []ConnectivityMethod{ConnectViaPublicIp}, instanceConnectivity{}.methods())
+	assert.Equal(t, []ConnectivityMethod{ConnectViaBastion}, instanceConnectivity{bastion: &ssh.Host{}}.methods())
+
+	order := []ConnectivityMethod{ConnectViaPublicIp, ConnectViaBastion, ConnectViaSsm}
+	assert.Equal(t, order, instanceConnectivity{order: order, bastion: &ssh.Host{}}.methods())
+}
+
+func TestInstanceConnectivitySshBastion(t *testing.T) {
+	t.Parallel()
+
+	bastion := &ssh.Host{Hostname: "bastion"}
+	conn := instanceConnectivity{bastion: bastion}
+
+	host, err := conn.sshBastion(ConnectViaPublicIp)
+	assert.NoError(t, err)
+	assert.Nil(t, host)
+
+	host, err = conn.sshBastion(ConnectViaBastion)
+	assert.NoError(t, err)
+	assert.Equal(t, bastion, host)
+
+	_, err = instanceConnectivity{}.sshBastion(ConnectViaBastion)
+	assert.Equal(t, BastionNotConfigured{}, err)
+
+	_, err = conn.sshBastion("carrier-pigeon")
+	assert.Equal(t, UnknownConnectivityMethod{Method: "carrier-pigeon"}, err)
+}
+
+func TestAddressModeDefaults(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, AddressPublicIp, AddressMode("").orDefault(nil))
+	assert.Equal(t, AddressPrivateIp, AddressMode("").orDefault(&ssh.Host{}))
+	assert.Equal(t, AddressIpv6, AddressIpv6.orDefault(&ssh.Host{}))
+	assert.Equal(t, AddressPublicDns, AddressPublicDns.orDefault(nil))
+}
+
+func TestFindEc2InstanceAddressE(t *testing.T) {
+	t.Parallel()
+
+	addresses := map[string]string{"i-1": "2600:1f18::1", "i-2": ""}
+
+	address, err := findEc2InstanceAddressE(addresses, "i-1", "us-east-1", AddressIpv6)
+	require.NoError(t, err)
+	assert.Equal(t, "2600:1f18::1", address)
+
+	_, err = findEc2InstanceAddressE(addresses, "i-2", "us-east-1", AddressIpv6)
+	assert.Equal(t, IpForEc2InstanceNotFound{InstanceId: "i-2", AwsRegion: "us-east-1", Type: "IPv6"}, err)
+
+	_, err = findEc2InstanceAddressE(addresses, "i-3", "us-east-1", AddressPublicDns)
+	assert.Equal(t, HostnameForEc2InstanceNotFound{InstanceId: "i-3", AwsRegion: "us-east-1", Type: "public"}, err)
+
+	_, err = findEc2InstanceAddressE(addresses, "i-3", "us-east-1", AddressPrivateIp)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGetEc2InstanceAddressesEUnknownMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := getEc2InstanceAddressesE(t, []string{"i-1"}, "us-east-1", "carrier-pigeon")
+	assert.Equal(t, UnknownAddressMode{Mode: "carrier-pigeon"}, err)
+}
+
+func TestWithConnectivityFallbackE(t *testing.T) {
+	t.Parallel()
+
+	methods := []ConnectivityMethod{ConnectViaPublicIp, ConnectViaBastion, ConnectViaSsm}
+
+	tried := []ConnectivityMethod{}
+	err := withConnectivityFallbackE(t, "i-0123", methods, func(method ConnectivityMethod) error {
+		tried = append(tried, method)
+		if method == ConnectViaSsm {
+			return nil
+		}
+		return errors.New("connection refused")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, methods, tried)
+
+	err = withConnectivityFallbackE(t, "i-0123", methods, func(method ConnectivityMethod) error {
+		return errors.New("connection refused")
+	})
+	require.Error(t, err)
+	merr, ok := err.(*multierror.Error)
+	require.True(t, ok)
+	require.Len(t, merr.Errors, 3)
+	assert.Equal(t, ConnectivityMethodFailed{Method: ConnectViaSsm, Underlying: errors.New("connection refused")}, merr.Errors[2])
+}
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// FetchContentsOfFileFromInstanceWithContext looks up the public IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH using the given username and Key Pair, fetches the contents of the file at the given
+// path (using sudo if useSudo is true), and returns the contents of that file as a string, giving up once the given
+// context is done. This will fail the test if there is an error.
+func FetchContentsOfFileFromInstanceWithContext(ctx context.Context, t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string) string {
+	out, err := FetchContentsOfFileFromInstanceWithContextE(ctx, t, awsRegion, sshUserName, keyPair, instanceID, useSudo, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// FetchContentsOfFileFromInstanceWithContextE looks up the public IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH using the given username and Key Pair, fetches the contents of the file at the given
+// path (using sudo if useSudo is true), and returns the contents of that file as a string. Returns a
+// FetchFilesAbandoned error as soon as the given context is done, e.g. because its deadline leaves just enough time
+// before the deadline of the test to report the failure.
+func FetchContentsOfFileFromInstanceWithContextE(ctx context.Context, t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string) (string, error) {
+	var contents string
+	err := withFetchContextE(ctx, instanceID, func() error {
+		var err error
+		contents, err = FetchContentsOfFileFromInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, filePath)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return contents, nil
+}
+
+// FetchFilesFromInstanceWithContext looks up the public IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH using the given username and Key Pair, downloads the files matching filenameFilters at the given
+// remoteDirectory (using sudo if useSudo is true), and stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>, giving up once the given context is done. This will fail the test if
+// there is an error.
+func FetchFilesFromInstanceWithContext(ctx context.Context, t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) {
+	err := FetchFilesFromInstanceWithContextE(ctx, t, awsRegion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// FetchFilesFromInstanceWithContextE looks up the public IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH using the given username and Key Pair, downloads the files matching filenameFilters at the given
+// remoteDirectory (using sudo if useSudo is true), and stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>. Returns a FetchFilesAbandoned error as soon as the given context is
+// done. The abandoned transfer is left to finish or fail in the background, so files may still show up locally.
+func FetchFilesFromInstanceWithContextE(ctx context.Context, t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) error {
+	return withFetchContextE(ctx, instanceID, func() error {
+		return FetchFilesFromInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters)
+	})
+}
+
+// FetchFilesFromAsgsWithContext fetches the files given in the RemoteFileSpecification from its instances like
+// FetchFilesFromAsgs, giving up on the instances that are not done yet once the given context is done.
+func FetchFilesFromAsgsWithContext(ctx context.Context, t testing.TestingT, awsRegion string, spec RemoteFileSpecification) {
+	err := FetchFilesFromAsgsWithContextE(ctx, t, awsRegion, spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// FetchFilesFromAsgsWithContextE fetches the files given in the RemoteFileSpecification from its instances like
+// FetchFilesFromAsgsE. Once the given context is done, the instances that are not done yet are given up on, each with a
+// FetchFilesAbandoned error, so use a context whose deadline leaves enough time before the deadline of the test to
+// report the failures. spec.InstanceTimeout bounds each instance on top of that.
+func FetchFilesFromAsgsWithContextE(ctx context.Context, t testing.TestingT, awsRegion string, spec RemoteFileSpecification) error {
+	return fetchFilesFromAsgsE(ctx, t, awsRegion, spec)
+}
+
+// withInstanceTimeout returns a context derived from the given one that is done after the given timeout, or only once
+// the given context is done if the timeout is not set.
+func withInstanceTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withFetchContextE calls the given action and returns its error, or a FetchFilesAbandoned error for the given instance
+// as soon as the given context is done. As the SSH helpers can't be interrupted, an abandoned action keeps running in
+// the background, so callers must not read what it sets unless this returns nil.
+func withFetchContextE(ctx context.Context, instanceID string, action func() error) error {
+	if err := ctx.Err(); err != nil {
+		return FetchFilesAbandoned{InstanceId: instanceID, Underlying: err}
+	}
+	if ctx.Done() == nil {
+		return action()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- action() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return FetchFilesAbandoned{InstanceId: instanceID, Underlying: ctx.Err()}
+	}
+}
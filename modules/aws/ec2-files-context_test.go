@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFetchContextEAbandonsHungAction(t *testing.T) {
+	t.Parallel()
+
+	hung := make(chan struct{})
+	defer close(hung)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := withFetchContextE(ctx, "i-123", func() error {
+		<-hung
+		return nil
+	})
+	assert.Equal(t, FetchFilesAbandoned{InstanceId: "i-123", Underlying: context.DeadlineExceeded}, err)
+	assert.True(t, errors.Is(err, ErrTimedOut))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	err = withFetchContextE(canceled, "i-123", func() error {
+		t.Fatal("the action should not run once the context is done")
+		return nil
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, ErrTimedOut))
+
+	assert.EqualError(t, withFetchContextE(context.Background(), "i-123", func() error { return errors.New("boom") }), "boom")
+}
+
+func TestRunFetchFilesJobsGivesUpOnHungInstances(t *testing.T) {
+	t.Parallel()
+
+	hung := make(chan struct{})
+	defer close(hung)
+
+	jobs := []fetchFilesJob{
+		{asgName: "asg", instanceID: "i-hung", remoteDir: "/var/log"},
+		{asgName: "asg", instanceID: "i-hung", remoteDir: "/etc/app"},
+		{asgName: "asg", instanceID: "i-ok", remoteDir: "/var/log"},
+	}
+
+	var fetched int32
+	failures := runFetchFilesJobs(context.Background(), jobs, 2, 20*time.Millisecond, func(job fetchFilesJob) error {
+		if job.instanceID == "i-hung" {
+			<-hung
+		}
+		atomic.AddInt32(&fetched, 1)
+		return nil
+	})
+
+	// The second directory of the hung instance is given up on without being fetched, as the instance timed out
+	require.Len(t, failures, 2)
+	for i, remoteDir := range []string{"/var/log", "/etc/app"} {
+		assert.Equal(t, FetchFilesFailed{AsgName: "asg", InstanceId: "i-hung", RemoteDir: remoteDir, Underlying: FetchFilesAbandoned{InstanceId: "i-hung", Underlying: context.DeadlineExceeded}}, failures[i])
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+}
+
+func TestRunFetchFilesJobsStopsWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	jobs := []fetchFilesJob{{instanceID: "i-1", remoteDir: "/var/log"}, {instanceID: "i-2", remoteDir: "/var/log"}}
+	failures := runFetchFilesJobs(ctx, jobs, 1, 0, func(job fetchFilesJob) error {
+		t.Fatal("no job should run once the context is done")
+		return nil
+	})
+
+	require.Len(t, failures, 2)
+	assert.True(t, errors.Is(failures[1], context.Canceled))
+}
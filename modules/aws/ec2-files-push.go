@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// PushFilesToInstance looks up the public IP address of the EC2 Instance with the given ID, connects to the Instance
+// via SSH using the given username and Key Pair, and uploads the files in localDirectory matching filenameFilters to
+// remoteDirectory, creating it if needed (using sudo if useSudo is true, e.g. to stage files in /etc).
+func PushFilesToInstance(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, localDirectory string, remoteDirectory string, filenameFilters []string) {
+	err := PushFilesToInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, localDirectory, remoteDirectory, filenameFilters)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// PushFilesToInstanceE looks up the public IP address of the EC2 Instance with the given ID, connects to the Instance
+// via SSH using the given username and Key Pair, and uploads the files in localDirectory matching filenameFilters to
+// remoteDirectory, creating it if needed (using sudo if useSudo is true, e.g. to stage files in /etc). The filters
+// support bash-style wildcards and match all files if empty. NOTE: only the files directly within localDirectory are
+// uploaded, not its subdirectories. The files keep their local permissions.
+func PushFilesToInstanceE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, localDirectory string, remoteDirectory string, filenameFilters []string) error {
+	localFilePaths, err := listFilesToPushE(localDirectory, filenameFilters)
+	if err != nil {
+		return err
+	}
+
+	host, err := getSshHostForInstanceE(t, awsRegion, nil, "", sshUserName, keyPair, instanceID)
+	if err != nil {
+		return err
+	}
+
+	return pushFilesToHostE(t, host, useSudo, localFilePaths, remoteDirectory)
+}
+
+// PushFilesToAsg looks up the EC2 Instances in the given ASG, looks up the public IPs of those EC2 Instances, connects
+// to each Instance via SSH using the given username and Key Pair, and uploads the files in localDirectory matching
+// filenameFilters to remoteDirectory, creating it if needed (using sudo if useSudo is true).
+func PushFilesToAsg(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, asgName string, useSudo bool, localDirectory string, remoteDirectory string, filenameFilters []string) {
+	err := PushFilesToAsgE(t, awsRegion, sshUserName, keyPair, asgName, useSudo, localDirectory, remoteDirectory, filenameFilters)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// PushFilesToAsgE looks up the EC2 Instances in the given ASG, looks up the public IPs of those EC2 Instances, connects
+// to each Instance via SSH using the given username and Key Pair, and uploads the files in localDirectory matching
+// filenameFilters to remoteDirectory, creating it if needed (using sudo if useSudo is true). See PushFilesToInstanceE.
+func PushFilesToAsgE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, asgName string, useSudo bool, localDirectory string, remoteDirectory string, filenameFilters []string) error {
+	instanceIDs, err := GetInstanceIdsForAsgE(t, asgName, awsRegion)
+	if err != nil {
+		return err
+	}
+
+	for _, instanceID := range instanceIDs {
+		if err := PushFilesToInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, localDirectory, remoteDirectory, filenameFilters); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pushFilesToHostE uploads the given local files to remoteDirectory on the given host, creating it if needed. As SCP
+// can only write where the SSH user can, files are uploaded to /tmp first and moved into place with sudo if useSudo is
+// true.
+func pushFilesToHostE(t testing.TestingT, host ssh.Host, useSudo bool, localFilePaths []string, remoteDirectory string) error {
+	sudo := ""
+	if useSudo {
+		sudo = "sudo "
+	}
+
+	if _, err := ssh.CheckSshCommandE(t, host, fmt.Sprintf("%smkdir -p %s", sudo, shellQuote(remoteDirectory))); err != nil {
+		return err
+	}
+
+	for _, localFilePath := range localFilePaths {
+		contents, err := ioutil.ReadFile(localFilePath)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(localFilePath)
+		if err != nil {
+			return err
+		}
+
+		remoteFilePath := path.Join(remoteDirectory, filepath.Base(localFilePath))
+		logger.Logf(t, "Copying local file: %s to remote path %s on %s", localFilePath, remoteFilePath, host.Hostname)
+
+		if !useSudo {
+			if err := ssh.ScpFileToE(t, host, info.Mode().Perm(), remoteFilePath, string(contents)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		stagingFilePath := path.Join("/tmp", fmt.Sprintf("terratest-%s-%s", random.UniqueId(), filepath.Base(localFilePath)))
+		if err := ssh.ScpFileToE(t, host, info.Mode().Perm(), stagingFilePath, string(contents)); err != nil {
+			return err
+		}
+		if _, err := ssh.CheckSshCommandE(t, host, fmt.Sprintf("sudo mv %s %s", shellQuote(stagingFilePath), shellQuote(remoteFilePath))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listFilesToPushE returns the paths of the files directly within the given local directory whose names match any of
+// the given filters, which support bash-style wildcards, or all of them if there are no filters.
+func listFilesToPushE(localDirectory string, filenameFilters []string) ([]string, error) {
+	entries, err := ioutil.ReadDir(localDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	filePaths := []string{}
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		matches := len(filenameFilters) == 0
+		for _, filter := range filenameFilters {
+			matched, err := filepath.Match(filter, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			matches = matches || matched
+		}
+
+		if matches {
+			filePaths = append(filePaths, filepath.Join(localDirectory, entry.Name()))
+		}
+	}
+
+	return filePaths, nil
+}
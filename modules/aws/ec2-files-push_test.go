@@ -0,0 +1,35 @@
+package aws
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFilesToPushE(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "terratest-push-files")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"app.conf", "harness.sh", "notes.txt"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir.conf"), 0755))
+
+	filePaths, err := listFilesToPushE(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "app.conf"), filepath.Join(dir, "harness.sh"), filepath.Join(dir, "notes.txt")}, filePaths)
+
+	filePaths, err = listFilesToPushE(dir, []string{"*.conf", "*.sh"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "app.conf"), filepath.Join(dir, "harness.sh")}, filePaths)
+
+	_, err = listFilesToPushE(filepath.Join(dir, "missing"), nil)
+	assert.Error(t, err)
+}
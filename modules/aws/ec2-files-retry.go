@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// FetchContentsOfFileFromInstanceWithRetry looks up the public IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH using the given username and Key Pair, fetches the contents of the file at the given
+// path (using sudo if useSudo is true), and returns the contents of that file as a string. If the Instance can't be
+// connected to, e.g. because it is not SSH-ready yet, this is retried up to maxRetries times, sleeping for
+// sleepBetweenRetries in between.
+func FetchContentsOfFileFromInstanceWithRetry(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string, maxRetries int, sleepBetweenRetries time.Duration) string {
+	out, err := FetchContentsOfFileFromInstanceWithRetryE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, filePath, maxRetries, sleepBetweenRetries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// FetchContentsOfFileFromInstanceWithRetryE looks up the public IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH using the given username and Key Pair, fetches the contents of the file at the given
+// path (using sudo if useSudo is true), and returns the contents of that file as a string. If the Instance can't be
+// connected to, e.g. because it is not SSH-ready yet, this is retried up to maxRetries times, sleeping for
+// sleepBetweenRetries in between. Other errors, such as authentication failures or a missing file, are returned right
+// away.
+func FetchContentsOfFileFromInstanceWithRetryE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string, maxRetries int, sleepBetweenRetries time.Duration) (string, error) {
+	var contents string
+	description := fmt.Sprintf("Fetching contents of %s from EC2 Instance %s", filePath, instanceID)
+	err := withSshConnectionRetryE(t, description, maxRetries, sleepBetweenRetries, func() error {
+		var err error
+		contents, err = FetchContentsOfFileFromInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, filePath)
+		return err
+	})
+	return contents, err
+}
+
+// FetchFilesFromInstanceWithRetry looks up the public IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH using the given username and Key Pair, downloads the files matching filenameFilters at the given
+// remoteDirectory (using sudo if useSudo is true), and stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>. If the Instance can't be connected to, this is retried up to
+// maxRetries times, sleeping for sleepBetweenRetries in between.
+func FetchFilesFromInstanceWithRetry(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := FetchFilesFromInstanceWithRetryE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters, maxRetries, sleepBetweenRetries)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// FetchFilesFromInstanceWithRetryE looks up the public IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH using the given username and Key Pair, downloads the files matching filenameFilters at the given
+// remoteDirectory (using sudo if useSudo is true), and stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>. If the Instance can't be connected to, this is retried up to
+// maxRetries times, sleeping for sleepBetweenRetries in between. Other errors are returned right away.
+func FetchFilesFromInstanceWithRetryE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Fetching files in %s from EC2 Instance %s", remoteDirectory, instanceID)
+	return withSshConnectionRetryE(t, description, maxRetries, sleepBetweenRetries, func() error {
+		return FetchFilesFromInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters)
+	})
+}
+
+// withSshConnectionRetryE calls the given function, retrying it up to maxRetries times, sleeping for
+// sleepBetweenRetries in between, as long as it fails because an SSH connection could not be established
+// (ssh.ErrConnectionFailed). Any other error is returned right away. With no retries, the function is called once.
+func withSshConnectionRetryE(t testing.TestingT, description string, maxRetries int, sleepBetweenRetries time.Duration, action func() error) error {
+	if maxRetries <= 0 {
+		return action()
+	}
+
+	_, err := retry.DoWithRetryInterfaceE(t, description, maxRetries, sleepBetweenRetries, func() (interface{}, error) {
+		err := action()
+		if err != nil && !errors.Is(err, ssh.ErrConnectionFailed) {
+			return nil, retry.FatalError{Underlying: err}
+		}
+		return nil, err
+	})
+
+	if fatalErr, isFatalErr := err.(retry.FatalError); isFatalErr {
+		return fatalErr.Underlying
+	}
+	return err
+}
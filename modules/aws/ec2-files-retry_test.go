@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSshConnectionRetryRetriesConnectionFailures(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := withSshConnectionRetryE(t, "fetch", 3, 0, func() error {
+		calls++
+		if calls < 3 {
+			return ssh.ConnectionFailed{Address: "54.0.0.1:22", Kind: ssh.ErrConnectionFailed, Underlying: errors.New("connection refused")}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithSshConnectionRetryReturnsOtherErrorsRightAway(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	authErr := ssh.ConnectionFailed{Address: "54.0.0.1:22", Kind: ssh.ErrAuthFailed, Underlying: errors.New("unable to authenticate")}
+	err := withSshConnectionRetryE(t, "fetch", 3, 0, func() error {
+		calls++
+		return authErr
+	})
+
+	assert.Equal(t, authErr, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithSshConnectionRetryGivesUpAfterMaxRetries(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := withSshConnectionRetryE(t, "fetch", 2, 0, func() error {
+		calls++
+		return ConnectivityMethodFailed{Method: ConnectViaPublicIp, Underlying: ssh.ConnectionFailed{Kind: ssh.ErrConnectionFailed, Underlying: errors.New("connection refused")}}
+	})
+
+	assert.IsType(t, retry.MaxRetriesExceeded{}, err)
+	assert.Equal(t, 3, calls)
+}
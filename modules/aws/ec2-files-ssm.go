@@ -0,0 +1,181 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ssmFetchTimeout is how long to wait for each SSM command run to fetch files.
+const ssmFetchTimeout = 1 * time.Minute
+
+// ssmFetchChunkSize is the number of bytes of a file fetched by each SSM command. SSM truncates the output of a command
+// to 24,000 characters, which this chunk size stays under once base64 encoded.
+const ssmFetchChunkSize = 16 * 1024
+
+// FetchContentsOfFileFromInstanceViaSSM connects to the EC2 Instance with the given ID via SSM, fetches the contents of
+// the file at the given path and returns the contents of that file as a string. Unlike
+// FetchContentsOfFileFromInstance, this needs no network access to the Instance, but it does need the SSM agent to be
+// running on it. The commands run as root, so there is no need for sudo.
+func FetchContentsOfFileFromInstanceViaSSM(t testing.TestingT, awsRegion string, instanceID string, filePath string) string {
+	out, err := FetchContentsOfFileFromInstanceViaSSME(t, awsRegion, instanceID, filePath)
+	require.NoError(t, err)
+	return out
+}
+
+// FetchContentsOfFileFromInstanceViaSSME connects to the EC2 Instance with the given ID via SSM, fetches the contents
+// of the file at the given path and returns the contents of that file as a string. Unlike
+// FetchContentsOfFileFromInstanceE, this needs no network access to the Instance, but it does need the SSM agent to be
+// running on it. The commands run as root, so there is no need for sudo.
+func FetchContentsOfFileFromInstanceViaSSME(t testing.TestingT, awsRegion string, instanceID string, filePath string) (string, error) {
+	return fetchOutputInChunksViaSSME(t, awsRegion, instanceID, func(chunk int) string {
+		return readFileChunkCommand(filePath, chunk)
+	})
+}
+
+// FetchContentsOfFilesFromInstanceViaSSM connects to the EC2 Instance with the given ID via SSM, fetches the contents
+// of the files at the given paths and returns a map from file path to the contents of that file as a string.
+func FetchContentsOfFilesFromInstanceViaSSM(t testing.TestingT, awsRegion string, instanceID string, filePaths ...string) map[string]string {
+	out, err := FetchContentsOfFilesFromInstanceViaSSME(t, awsRegion, instanceID, filePaths...)
+	require.NoError(t, err)
+	return out
+}
+
+// FetchContentsOfFilesFromInstanceViaSSME connects to the EC2 Instance with the given ID via SSM, fetches the contents
+// of the files at the given paths and returns a map from file path to the contents of that file as a string.
+func FetchContentsOfFilesFromInstanceViaSSME(t testing.TestingT, awsRegion string, instanceID string, filePaths ...string) (map[string]string, error) {
+	filePathToContents := map[string]string{}
+
+	for _, filePath := range filePaths {
+		contents, err := FetchContentsOfFileFromInstanceViaSSME(t, awsRegion, instanceID, filePath)
+		if err != nil {
+			return nil, err
+		}
+		filePathToContents[filePath] = contents
+	}
+
+	return filePathToContents, nil
+}
+
+// FetchFilesFromInstanceViaSSM connects to the EC2 Instance with the given ID via SSM, downloads the files matching
+// filenameFilters at the given remoteDirectory, and stores the files locally at
+// localDirectory/<instanceid>/<remoteFolderName>
+func FetchFilesFromInstanceViaSSM(t testing.TestingT, awsRegion string, instanceID string, remoteDirectory string, localDirectory string, filenameFilters []string) {
+	require.NoError(t, FetchFilesFromInstanceViaSSME(t, awsRegion, instanceID, remoteDirectory, localDirectory, filenameFilters))
+}
+
+// FetchFilesFromInstanceViaSSME connects to the EC2 Instance with the given ID via SSM, downloads the files matching
+// filenameFilters at the given remoteDirectory, and stores the files locally at
+// localDirectory/<instanceid>/<remoteFolderName>. The Instance ID is used rather than its IP, as the Instance may not
+// have one that is reachable from here.
+func FetchFilesFromInstanceViaSSME(t testing.TestingT, awsRegion string, instanceID string, remoteDirectory string, localDirectory string, filenameFilters []string) error {
+	// The listing may be as long as any file, so it is fetched in chunks too
+	listing, err := fetchOutputInChunksViaSSME(t, awsRegion, instanceID, func(chunk int) string {
+		return listFilesChunkCommand(remoteDirectory, filenameFilters, chunk)
+	})
+	if err != nil {
+		return err
+	}
+
+	finalLocalDestDir := filepath.Join(localDirectory, instanceID, filepath.Base(remoteDirectory))
+	if err := os.MkdirAll(finalLocalDestDir, 0755); err != nil {
+		return err
+	}
+
+	for _, remoteFilePath := range strings.Split(listing, "\n") {
+		if remoteFilePath = strings.TrimSpace(remoteFilePath); remoteFilePath == "" {
+			continue
+		}
+
+		contents, err := FetchContentsOfFileFromInstanceViaSSME(t, awsRegion, instanceID, remoteFilePath)
+		if err != nil {
+			return err
+		}
+
+		localFilePath := filepath.Join(finalLocalDestDir, filepath.Base(remoteFilePath))
+		logger.Logf(t, "Copying remote file: %s to local path %s", remoteFilePath, localFilePath)
+
+		if err := ioutil.WriteFile(localFilePath, []byte(contents), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchOutputInChunksViaSSME runs the commands returned by chunkCommand for chunk 0, 1, 2, etc. on the EC2 Instance with
+// the given ID via SSM, until one prints less than ssmFetchChunkSize bytes, and returns the concatenation of what they
+// printed. Each command must print its chunk of ssmFetchChunkSize bytes base64 encoded, as SSM truncates the output of
+// commands.
+func fetchOutputInChunksViaSSME(t testing.TestingT, awsRegion string, instanceID string, chunkCommand func(chunk int) string) (string, error) {
+	var contents bytes.Buffer
+
+	for chunk := 0; ; chunk++ {
+		output, err := CheckSsmCommandE(t, awsRegion, instanceID, chunkCommand(chunk), ssmFetchTimeout)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(output.Stdout))
+		if err != nil {
+			return "", err
+		}
+		contents.Write(data)
+
+		if len(data) < ssmFetchChunkSize {
+			return contents.String(), nil
+		}
+	}
+}
+
+// readFileChunkCommand returns a command that prints the given chunk of ssmFetchChunkSize bytes of the given file,
+// base64 encoded, failing if the file does not exist.
+func readFileChunkCommand(filePath string, chunk int) string {
+	quotedPath := shellQuote(filePath)
+	return fmt.Sprintf(
+		"test -f %s || { echo %s >&2; exit 1; }; dd if=%s bs=%d skip=%d count=1 2>/dev/null | base64 -w 0",
+		quotedPath, shellQuote("No such file: "+filePath), quotedPath, ssmFetchChunkSize, chunk,
+	)
+}
+
+// findFilesCommand returns a command that lists the files in the given directory that match any of the given filters,
+// which support bash-style wildcards.
+func findFilesCommand(remoteDirectory string, filenameFilters []string) string {
+	args := []string{"find", shellQuote(remoteDirectory), "-type", "f"}
+
+	if len(filenameFilters) > 0 {
+		nameArgs := []string{}
+		for _, filter := range filenameFilters {
+			nameArgs = append(nameArgs, "-name "+shellQuote(filter))
+		}
+		args = append(args, "\\(", strings.Join(nameArgs, " -o "), "\\)")
+	}
+
+	return strings.Join(args, " ")
+}
+
+// listFilesChunkCommand returns a command that prints the given chunk of ssmFetchChunkSize bytes of the list of files
+// found by findFilesCommand, base64 encoded, failing if the directory does not exist. The list is sorted so that it is
+// the same for every chunk.
+func listFilesChunkCommand(remoteDirectory string, filenameFilters []string, chunk int) string {
+	quotedDirectory := shellQuote(remoteDirectory)
+	return fmt.Sprintf(
+		"test -d %s || { echo %s >&2; exit 1; }; %s | LC_ALL=C sort | tail -c +%d | head -c %d | base64 -w 0",
+		quotedDirectory, shellQuote("No such directory: "+remoteDirectory), findFilesCommand(remoteDirectory, filenameFilters),
+		chunk*ssmFetchChunkSize+1, ssmFetchChunkSize,
+	)
+}
+
+// shellQuote quotes the given string for use as a single word in a shell command.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindFilesCommand(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "find '/var/log' -type f", findFilesCommand("/var/log", nil))
+	assert.Equal(t, "find '/var/log' -type f \\( -name '*.log' -o -name 'syslog' \\)", findFilesCommand("/var/log", []string{"*.log", "syslog"}))
+}
+
+func TestReadFileChunkCommand(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(
+		t,
+		"test -f '/tmp/it'\\''s.log' || { echo 'No such file: /tmp/it'\\''s.log' >&2; exit 1; }; dd if='/tmp/it'\\''s.log' bs=16384 skip=2 count=1 2>/dev/null | base64 -w 0",
+		readFileChunkCommand("/tmp/it's.log", 2),
+	)
+}
+
+func TestListFilesChunkCommand(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(
+		t,
+		"test -d '/var/log' || { echo 'No such directory: /var/log' >&2; exit 1; }; find '/var/log' -type f \\( -name '*.log' \\) | LC_ALL=C sort | tail -c +32769 | head -c 16384 | base64 -w 0",
+		listFilesChunkCommand("/var/log", []string{"*.log"}, 2),
+	)
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/gruntwork-io/terratest/modules/winrm"
+)
+
+// FetchContentsOfFileFromWindowsInstance looks up the public IP address of the Windows EC2 Instance with the given ID,
+// connects to the Instance over WinRM with the credentials and settings of the given host, fetches the contents of
+// the file at the given path, and returns the contents of that file as a string.
+func FetchContentsOfFileFromWindowsInstance(t testing.TestingT, awsRegion string, host winrm.Host, instanceID string, filePath string) string {
+	out, err := FetchContentsOfFileFromWindowsInstanceE(t, awsRegion, host, instanceID, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// FetchContentsOfFileFromWindowsInstanceE looks up the public IP address of the Windows EC2 Instance with the given
+// ID, connects to the Instance over WinRM with the credentials and settings of the given host, fetches the contents of
+// the file at the given path, and returns the contents of that file as a string. The Hostname of the given host is
+// ignored.
+func FetchContentsOfFileFromWindowsInstanceE(t testing.TestingT, awsRegion string, host winrm.Host, instanceID string, filePath string) (string, error) {
+	host, err := getWinrmHostForInstanceE(t, awsRegion, host, instanceID)
+	if err != nil {
+		return "", err
+	}
+
+	return winrm.FetchContentsOfFileE(t, host, filePath)
+}
+
+// FetchFilesFromWindowsInstance looks up the public IP address of the Windows EC2 Instance with the given ID, connects
+// to the Instance over WinRM with the credentials and settings of the given host, downloads the files matching
+// filenameFilters at the given remoteDirectory, and stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>
+func FetchFilesFromWindowsInstance(t testing.TestingT, awsRegion string, host winrm.Host, instanceID string, remoteDirectory string, localDirectory string, filenameFilters []string) {
+	err := FetchFilesFromWindowsInstanceE(t, awsRegion, host, instanceID, remoteDirectory, localDirectory, filenameFilters)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// FetchFilesFromWindowsInstanceE looks up the public IP address of the Windows EC2 Instance with the given ID,
+// connects to the Instance over WinRM with the credentials and settings of the given host, downloads the files
+// matching filenameFilters at the given remoteDirectory, and stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>, the same layout as FetchFilesFromInstanceE. The Hostname of the given
+// host is ignored. The filters support PowerShell wildcards, e.g. *.log, and match all files if empty.
+func FetchFilesFromWindowsInstanceE(t testing.TestingT, awsRegion string, host winrm.Host, instanceID string, remoteDirectory string, localDirectory string, filenameFilters []string) error {
+	host, err := getWinrmHostForInstanceE(t, awsRegion, host, instanceID)
+	if err != nil {
+		return err
+	}
+
+	finalLocalDestDir := filepath.Join(localDirectory, host.Hostname, windowsBase(remoteDirectory))
+
+	return winrm.FetchFilesE(t, host, remoteDirectory, finalLocalDestDir, filenameFilters)
+}
+
+// getWinrmHostForInstanceE returns the given WinRM host with its Hostname set to the public IP of the EC2 Instance with
+// the given ID.
+func getWinrmHostForInstanceE(t testing.TestingT, awsRegion string, host winrm.Host, instanceID string) (winrm.Host, error) {
+	ip, err := GetPublicIpOfEc2InstanceE(t, instanceID, awsRegion)
+	if err != nil {
+		return winrm.Host{}, err
+	}
+
+	host.Hostname = ip
+	return host, nil
+}
+
+// windowsBase returns the last element of the given Windows path, e.g. "logs" for C:\ProgramData\app\logs\.
+func windowsBase(path string) string {
+	path = strings.TrimRight(path, `\/`)
+	return path[strings.LastIndexAny(path, `\/`)+1:]
+}
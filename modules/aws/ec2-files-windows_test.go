@@ -0,0 +1,15 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowsBase(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "logs", windowsBase(`C:\ProgramData\app\logs\`))
+	assert.Equal(t, "logs", windowsBase(`C:/ProgramData/app/logs`))
+	assert.Equal(t, "logs", windowsBase(`logs`))
+}
@@ -1,10 +1,20 @@
 package aws
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/ssh"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/hashicorp/go-multierror"
@@ -13,11 +23,23 @@ import (
 // RemoteFileSpecification describes which files you want to copy from your instances
 type RemoteFileSpecification struct {
 	AsgNames               []string            //ASGs where our instances will be
+	TagFilters             map[string]string   //tags of standalone instances to fetch files from as well, e.g. {"Name": "my-test-host"}. Running instances that have all of these tags are included.
 	RemotePathToFileFilter map[string][]string //A map of the files to fetch, where the keys are directories on the remote host and the values are filters for what files to fetch from the directory. The filters support bash-style wildcards.
 	UseSudo                bool
 	SshUser                string
 	KeyPair                *Ec2Keypair
-	LocalDestinationDir    string //base path where to store downloaded artifacts locally. The final path of each resource will include the ip of the host and the name of the immediate parent folder.
+	LocalDestinationDir    string               //base path where to store downloaded artifacts locally. The final path of each resource will include the ip of the host and the name of the immediate parent folder.
+	MaxParallel            int                  //maximum number of instances to fetch files from at the same time. Defaults to 1, i.e. one instance at a time.
+	Bastion                *ssh.Host            //bastion host to connect to the instances through, using their private IPs, e.g. for instances in private subnets. If unset, the instances are connected to directly on their public IPs.
+	ConnectivityOrder      []ConnectivityMethod //methods to try, in order, to connect to each instance, falling back to the next one if a method fails. Defaults to ConnectViaBastion if Bastion is set and ConnectViaPublicIp otherwise.
+	MaxRetries             int                  //how many times to retry fetching files from an instance that can't be connected to, e.g. because it is not SSH-ready yet. Defaults to 0, i.e. no retries.
+	SleepBetweenRetries    time.Duration        //how long to wait between retries.
+	VerifyChecksums        bool                 //verify that the sha256 of each file fetched via SSH matches the one computed on the instance, returning an ssh.ChecksumMismatch for each file that does not.
+	TransferAsArchive      bool                 //fetch the files of each remote directory via SSH as a single tar.gz archive instead of one at a time, which is much faster for many small files.
+	OnlyHealthyInstances   bool                 //only fetch from the instances of the ASGs that are InService and Healthy, skipping the ones still Pending or Terminating, e.g. during a rolling deploy.
+	AddressMode            AddressMode          //which address of each instance to connect to via SSH, e.g. AddressIpv6 for IPv6-only subnets or AddressPrivateIp for private networks reached over a VPN. Defaults to the private IP if Bastion is set and the public IP otherwise. The address is used in the local paths.
+	InstanceTimeout        time.Duration        //how long to spend fetching files from each instance, retries included, before giving up on it with a FetchFilesAbandoned error, so a hung SSH session can't stall the test. Defaults to 0, i.e. no timeout.
+	BootDiagnosticsDir     string               //if set, the console output and a console screenshot of each instance that could not be connected to via SSH, retries included, are saved at BootDiagnosticsDir/<instanceid>/ with SaveBootDiagnosticsE, to find out whether it failed to boot. Defaults to "", i.e. disabled.
 }
 
 // FetchContentsOfFileFromInstance looks up the public IP address of the EC2 Instance with the given ID, connects to
@@ -35,20 +57,87 @@ func FetchContentsOfFileFromInstance(t testing.TestingT, awsRegion string, sshUs
 // the Instance via SSH using the given username and Key Pair, fetches the contents of the file at the given path
 // (using sudo if useSudo is true), and returns the contents of that file as a string.
 func FetchContentsOfFileFromInstanceE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string) (string, error) {
-	publicIp, err := GetPublicIpOfEc2InstanceE(t, instanceID, awsRegion)
+	return fetchContentsOfFileFromInstanceE(t, awsRegion, nil, sshUserName, keyPair, instanceID, useSudo, filePath)
+}
+
+// FetchContentsOfFileFromInstanceViaBastion looks up the private IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH through the given bastion host using the given username and Key Pair, fetches the
+// contents of the file at the given path (using sudo if useSudo is true), and returns the contents of that file as a
+// string.
+func FetchContentsOfFileFromInstanceViaBastion(t testing.TestingT, awsRegion string, bastion ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string) string {
+	out, err := FetchContentsOfFileFromInstanceViaBastionE(t, awsRegion, bastion, sshUserName, keyPair, instanceID, useSudo, filePath)
 	if err != nil {
-		return "", err
+		t.Fatal(err)
 	}
+	return out
+}
 
-	host := ssh.Host{
-		SshUserName: sshUserName,
-		SshKeyPair:  keyPair.KeyPair,
-		Hostname:    publicIp,
+// FetchContentsOfFileFromInstanceViaBastionE looks up the private IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH through the given bastion host using the given username and Key Pair, fetches the
+// contents of the file at the given path (using sudo if useSudo is true), and returns the contents of that file as a
+// string.
+func FetchContentsOfFileFromInstanceViaBastionE(t testing.TestingT, awsRegion string, bastion ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string) (string, error) {
+	return fetchContentsOfFileFromInstanceE(t, awsRegion, &bastion, sshUserName, keyPair, instanceID, useSudo, filePath)
+}
+
+func fetchContentsOfFileFromInstanceE(t testing.TestingT, awsRegion string, bastion *ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string) (string, error) {
+	host, err := getSshHostForInstanceE(t, awsRegion, bastion, "", sshUserName, keyPair, instanceID)
+	if err != nil {
+		return "", err
 	}
 
 	return ssh.FetchContentsOfFileE(t, host, useSudo, filePath)
 }
 
+// StreamContentsOfFileFromInstance looks up the public IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH using the given username and Key Pair, and writes the contents of the file at the given path
+// (using sudo if useSudo is true) to the given writer as they are received. Unlike FetchContentsOfFileFromInstance,
+// this does not hold the whole file in memory, so use it for large files such as logs. See ssh.StreamOptions for size
+// limits and progress reporting. This method returns the number of bytes written.
+func StreamContentsOfFileFromInstance(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string, writer io.Writer, options ssh.StreamOptions) int64 {
+	written, err := StreamContentsOfFileFromInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, filePath, writer, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return written
+}
+
+// StreamContentsOfFileFromInstanceE looks up the public IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH using the given username and Key Pair, and writes the contents of the file at the given path
+// (using sudo if useSudo is true) to the given writer as they are received. See ssh.StreamContentsOfFileE. This method
+// returns the number of bytes written.
+func StreamContentsOfFileFromInstanceE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string, writer io.Writer, options ssh.StreamOptions) (int64, error) {
+	host, err := getSshHostForInstanceE(t, awsRegion, nil, "", sshUserName, keyPair, instanceID)
+	if err != nil {
+		return 0, err
+	}
+
+	return ssh.StreamContentsOfFileE(t, host, useSudo, filePath, writer, options)
+}
+
+// DownloadFileFromInstance looks up the public IP address of the EC2 Instance with the given ID, connects to the
+// Instance via SSH using the given username and Key Pair, and streams the file at the given path (using sudo if
+// useSudo is true) to the given local path. This method returns the number of bytes written.
+func DownloadFileFromInstance(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string, localPath string, options ssh.StreamOptions) int64 {
+	written, err := DownloadFileFromInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, filePath, localPath, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return written
+}
+
+// DownloadFileFromInstanceE looks up the public IP address of the EC2 Instance with the given ID, connects to the
+// Instance via SSH using the given username and Key Pair, and streams the file at the given path (using sudo if
+// useSudo is true) to the given local path. See ssh.DownloadFileE. This method returns the number of bytes written.
+func DownloadFileFromInstanceE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePath string, localPath string, options ssh.StreamOptions) (int64, error) {
+	host, err := getSshHostForInstanceE(t, awsRegion, nil, "", sshUserName, keyPair, instanceID)
+	if err != nil {
+		return 0, err
+	}
+
+	return ssh.DownloadFileE(t, host, useSudo, filePath, localPath, options)
+}
+
 // FetchContentsOfFilesFromInstance looks up the public IP address of the EC2 Instance with the given ID, connects to
 // the Instance via SSH using the given username and Key Pair, fetches the contents of the files at the given paths
 // (using sudo if useSudo is true), and returns a map from file path to the contents of that file as a string.
@@ -64,15 +153,33 @@ func FetchContentsOfFilesFromInstance(t testing.TestingT, awsRegion string, sshU
 // the Instance via SSH using the given username and Key Pair, fetches the contents of the files at the given paths
 // (using sudo if useSudo is true), and returns a map from file path to the contents of that file as a string.
 func FetchContentsOfFilesFromInstanceE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePaths ...string) (map[string]string, error) {
-	publicIp, err := GetPublicIpOfEc2InstanceE(t, instanceID, awsRegion)
+	return fetchContentsOfFilesFromInstanceE(t, awsRegion, nil, sshUserName, keyPair, instanceID, useSudo, filePaths...)
+}
+
+// FetchContentsOfFilesFromInstanceViaBastion looks up the private IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH through the given bastion host using the given username and Key Pair, fetches the
+// contents of the files at the given paths (using sudo if useSudo is true), and returns a map from file path to the
+// contents of that file as a string.
+func FetchContentsOfFilesFromInstanceViaBastion(t testing.TestingT, awsRegion string, bastion ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePaths ...string) map[string]string {
+	out, err := FetchContentsOfFilesFromInstanceViaBastionE(t, awsRegion, bastion, sshUserName, keyPair, instanceID, useSudo, filePaths...)
 	if err != nil {
-		return nil, err
+		t.Fatal(err)
 	}
+	return out
+}
 
-	host := ssh.Host{
-		SshUserName: sshUserName,
-		SshKeyPair:  keyPair.KeyPair,
-		Hostname:    publicIp,
+// FetchContentsOfFilesFromInstanceViaBastionE looks up the private IP address of the EC2 Instance with the given ID,
+// connects to the Instance via SSH through the given bastion host using the given username and Key Pair, fetches the
+// contents of the files at the given paths (using sudo if useSudo is true), and returns a map from file path to the
+// contents of that file as a string.
+func FetchContentsOfFilesFromInstanceViaBastionE(t testing.TestingT, awsRegion string, bastion ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePaths ...string) (map[string]string, error) {
+	return fetchContentsOfFilesFromInstanceE(t, awsRegion, &bastion, sshUserName, keyPair, instanceID, useSudo, filePaths...)
+}
+
+func fetchContentsOfFilesFromInstanceE(t testing.TestingT, awsRegion string, bastion *ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, filePaths ...string) (map[string]string, error) {
+	host, err := getSshHostForInstanceE(t, awsRegion, bastion, "", sshUserName, keyPair, instanceID)
+	if err != nil {
+		return nil, err
 	}
 
 	return ssh.FetchContentsOfFilesE(t, host, useSudo, filePaths...)
@@ -93,7 +200,8 @@ func FetchContentsOfFileFromAsg(t testing.TestingT, awsRegion string, sshUserNam
 // FetchContentsOfFileFromAsgE looks up the EC2 Instances in the given ASG, looks up the public IPs of those EC2
 // Instances, connects to each Instance via SSH using the given username and Key Pair, fetches the contents of the file
 // at the given path (using sudo if useSudo is true), and returns a map from Instance ID to the contents of that file
-// as a string.
+// as a string. This stops at the first Instance the file can't be fetched from; see
+// FetchContentsOfFilesFromAsgInstancesE to carry on and get the contents and errors per instance instead.
 func FetchContentsOfFileFromAsgE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, asgName string, useSudo bool, filePath string) (map[string]string, error) {
 	instanceIDs, err := GetInstanceIdsForAsgE(t, asgName, awsRegion)
 	if err != nil {
@@ -128,7 +236,8 @@ func FetchContentsOfFilesFromAsg(t testing.TestingT, awsRegion string, sshUserNa
 // FetchContentsOfFilesFromAsgE looks up the EC2 Instances in the given ASG, looks up the public IPs of those EC2
 // Instances, connects to each Instance via SSH using the given username and Key Pair, fetches the contents of the files
 // at the given paths (using sudo if useSudo is true), and returns a map from Instance ID to a map of file path to the
-// contents of that file as a string.
+// contents of that file as a string. This stops at the first Instance the files can't be fetched from; see
+// FetchContentsOfFilesFromAsgInstancesE to carry on and get the contents and errors per instance instead.
 func FetchContentsOfFilesFromAsgE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, asgName string, useSudo bool, filePaths ...string) (map[string]map[string]string, error) {
 	instanceIDs, err := GetInstanceIdsForAsgE(t, asgName, awsRegion)
 	if err != nil {
@@ -148,6 +257,73 @@ func FetchContentsOfFilesFromAsgE(t testing.TestingT, awsRegion string, sshUserN
 	return instanceIdToFilePathToContents, err
 }
 
+// AsgFileContents is what was fetched from each EC2 Instance of an ASG by FetchContentsOfFilesFromAsgInstancesE, so that
+// tests can still check the healthy instances when some are broken.
+type AsgFileContents struct {
+	AsgName  string
+	Contents map[string]map[string]string // Instance ID to file path to contents, for the instances the files were fetched from
+	Errors   map[string]error             // Instance ID to error, for the instances the files could not be fetched from
+}
+
+// Err returns nil if the files were fetched from all the instances, and otherwise a FetchFilesFailed for each instance
+// they could not be fetched from in a *multierror.Error.
+func (result AsgFileContents) Err() error {
+	instanceIDs := []string{}
+	for instanceID := range result.Errors {
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+	sort.Strings(instanceIDs)
+
+	var errorsOccurred = &multierror.Error{ErrorFormat: formatMultiError}
+	for _, instanceID := range instanceIDs {
+		errorsOccurred = multierror.Append(errorsOccurred, FetchFilesFailed{AsgName: result.AsgName, InstanceId: instanceID, Underlying: result.Errors[instanceID]})
+	}
+	return errorsOccurred.ErrorOrNil()
+}
+
+// FetchContentsOfFilesFromAsgInstances looks up the EC2 Instances in the given ASG, looks up the public IPs of those
+// EC2 Instances, connects to each Instance via SSH using the given username and Key Pair, fetches the contents of the
+// files at the given paths (using sudo if useSudo is true), and returns what was fetched and what failed per instance.
+// This will fail the test if the instances of the ASG can't be looked up, but not if fetching from some of them fails.
+func FetchContentsOfFilesFromAsgInstances(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, asgName string, useSudo bool, filePaths ...string) AsgFileContents {
+	out, err := FetchContentsOfFilesFromAsgInstancesE(t, awsRegion, sshUserName, keyPair, asgName, useSudo, filePaths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// FetchContentsOfFilesFromAsgInstancesE looks up the EC2 Instances in the given ASG, looks up the public IPs of those
+// EC2 Instances, connects to each Instance via SSH using the given username and Key Pair, fetches the contents of the
+// files at the given paths (using sudo if useSudo is true), and returns what was fetched and what failed per instance.
+// Unlike FetchContentsOfFilesFromAsgE, this carries on when fetching from an instance fails, and only returns an error
+// if the instances of the ASG can't be looked up. Use the Err method of the result to fail on any failed instance.
+func FetchContentsOfFilesFromAsgInstancesE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, asgName string, useSudo bool, filePaths ...string) (AsgFileContents, error) {
+	instanceIDs, err := GetInstanceIdsForAsgE(t, asgName, awsRegion)
+	if err != nil {
+		return AsgFileContents{}, err
+	}
+
+	return fetchContentsOfFilesFromInstances(asgName, instanceIDs, func(instanceID string) (map[string]string, error) {
+		return FetchContentsOfFilesFromInstanceE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, filePaths...)
+	}), nil
+}
+
+// fetchContentsOfFilesFromInstances calls fetch for each of the given instances of the given ASG, one after the other,
+// and collects the contents and errors it returns per instance.
+func fetchContentsOfFilesFromInstances(asgName string, instanceIDs []string, fetch func(instanceID string) (map[string]string, error)) AsgFileContents {
+	result := AsgFileContents{AsgName: asgName, Contents: map[string]map[string]string{}, Errors: map[string]error{}}
+	for _, instanceID := range instanceIDs {
+		contents, err := fetch(instanceID)
+		if err != nil {
+			result.Errors[instanceID] = err
+			continue
+		}
+		result.Contents[instanceID] = contents
+	}
+	return result
+}
+
 // FetchFilesFromInstance looks up the EC2 Instances in the given ASG, looks up the public IPs of those EC2
 // Instances, connects to each Instance via SSH using the given username and Key Pair, downloads the files
 // matching filenameFilters at the given remoteDirectory (using sudo if useSudo is true), and stores the files locally
@@ -165,19 +341,93 @@ func FetchFilesFromInstance(t testing.TestingT, awsRegion string, sshUserName st
 // matching filenameFilters at the given remoteDirectory (using sudo if useSudo is true), and stores the files locally
 // at localDirectory/<publicip>/<remoteFolderName>
 func FetchFilesFromInstanceE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) error {
-	publicIp, err := GetPublicIpOfEc2InstanceE(t, instanceID, awsRegion)
+	return fetchFilesFromInstanceE(t, awsRegion, nil, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters, fetchFilesMode{})
+}
+
+// FetchFilesFromInstanceWithChecksums looks up the public IP address of the EC2 Instance with the given ID, connects
+// to the Instance via SSH using the given username and Key Pair, downloads the files matching filenameFilters at the
+// given remoteDirectory (using sudo if useSudo is true), stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>, and verifies that the sha256 of each local copy matches the one of the
+// remote file.
+func FetchFilesFromInstanceWithChecksums(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) {
+	err := FetchFilesFromInstanceWithChecksumsE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters)
 
 	if err != nil {
-		return err
+		t.Fatal(err)
 	}
+}
 
-	host := ssh.Host{
-		Hostname:    publicIp,
-		SshUserName: sshUserName,
-		SshKeyPair:  keyPair.KeyPair,
+// FetchFilesFromInstanceWithChecksumsE looks up the public IP address of the EC2 Instance with the given ID, connects
+// to the Instance via SSH using the given username and Key Pair, downloads the files matching filenameFilters at the
+// given remoteDirectory (using sudo if useSudo is true), stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>, and verifies that the sha256 of each local copy matches the one
+// computed on the Instance. An ssh.ChecksumMismatch error is returned for each file that does not, e.g. because its
+// transfer was truncated.
+func FetchFilesFromInstanceWithChecksumsE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) error {
+	return fetchFilesFromInstanceE(t, awsRegion, nil, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters, fetchFilesMode{verifyChecksums: true})
+}
+
+// FetchFilesFromInstanceAsArchive looks up the public IP address of the EC2 Instance with the given ID, connects to the
+// Instance via SSH using the given username and Key Pair, packs the files matching filenameFilters at the given
+// remoteDirectory into a tar.gz archive (using sudo if useSudo is true), downloads it, and unpacks the files locally at
+// localDirectory/<publicip>/<remoteFolderName>
+func FetchFilesFromInstanceAsArchive(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) {
+	err := FetchFilesFromInstanceAsArchiveE(t, awsRegion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// FetchFilesFromInstanceAsArchiveE looks up the public IP address of the EC2 Instance with the given ID, connects to the
+// Instance via SSH using the given username and Key Pair, packs the files matching filenameFilters at the given
+// remoteDirectory into a tar.gz archive (using sudo if useSudo is true), downloads it, and unpacks the files locally at
+// localDirectory/<publicip>/<remoteFolderName>, the same layout as FetchFilesFromInstanceE. Transferring a single
+// archive is much faster than FetchFilesFromInstanceE when there are many small files. Requires GNU tar on the Instance.
+func FetchFilesFromInstanceAsArchiveE(t testing.TestingT, awsRegion string, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) error {
+	return fetchFilesFromInstanceE(t, awsRegion, nil, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters, fetchFilesMode{archive: true})
+}
+
+// FetchFilesFromInstanceViaBastion looks up the private IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH through the given bastion host using the given username and Key Pair, downloads the files
+// matching filenameFilters at the given remoteDirectory (using sudo if useSudo is true), and stores the files locally
+// at localDirectory/<privateip>/<remoteFolderName>
+func FetchFilesFromInstanceViaBastion(t testing.TestingT, awsRegion string, bastion ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) {
+	err := FetchFilesFromInstanceViaBastionE(t, awsRegion, bastion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters)
+
+	if err != nil {
+		t.Fatal(err)
 	}
+}
 
-	finalLocalDestDir := filepath.Join(localDirectory, publicIp, filepath.Base(remoteDirectory))
+// FetchFilesFromInstanceViaBastionE looks up the private IP address of the EC2 Instance with the given ID, connects to
+// the Instance via SSH through the given bastion host using the given username and Key Pair, downloads the files
+// matching filenameFilters at the given remoteDirectory (using sudo if useSudo is true), and stores the files locally
+// at localDirectory/<privateip>/<remoteFolderName>
+func FetchFilesFromInstanceViaBastionE(t testing.TestingT, awsRegion string, bastion ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string) error {
+	return fetchFilesFromInstanceE(t, awsRegion, &bastion, sshUserName, keyPair, instanceID, useSudo, remoteDirectory, localDirectory, filenameFilters, fetchFilesMode{})
+}
+
+// fetchFilesMode is how fetchFilesFromInstanceE transfers files.
+type fetchFilesMode struct {
+	verifyChecksums bool // Verify the sha256 of each fetched file
+	archive         bool // Transfer the files as a single tar.gz archive
+}
+
+func fetchFilesFromInstanceE(t testing.TestingT, awsRegion string, bastion *ssh.Host, sshUserName string, keyPair *Ec2Keypair, instanceID string, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string, mode fetchFilesMode) error {
+	host, err := getSshHostForInstanceE(t, awsRegion, bastion, "", sshUserName, keyPair, instanceID)
+
+	if err != nil {
+		return err
+	}
+
+	return fetchFilesFromHostE(t, host, useSudo, remoteDirectory, localDirectory, filenameFilters, mode)
+}
+
+// fetchFilesFromHostE downloads the files matching filenameFilters at the given remoteDirectory of the given host and
+// stores them locally at localDirectory/<hostname>/<remoteFolderName>.
+func fetchFilesFromHostE(t testing.TestingT, host ssh.Host, useSudo bool, remoteDirectory string, localDirectory string, filenameFilters []string, mode fetchFilesMode) error {
+	finalLocalDestDir := filepath.Join(localDirectory, host.Hostname, filepath.Base(remoteDirectory))
 
 	if !files.FileExists(finalLocalDestDir) {
 		os.MkdirAll(finalLocalDestDir, 0755)
@@ -188,15 +438,17 @@ func FetchFilesFromInstanceE(t testing.TestingT, awsRegion string, sshUserName s
 		RemoteDir:       remoteDirectory,
 		LocalDir:        finalLocalDestDir,
 		FileNameFilters: filenameFilters,
+		VerifyChecksums: mode.verifyChecksums,
+		Archive:         mode.archive,
 	}
 
 	return ssh.ScpDirFromE(t, scpOptions, useSudo)
 }
 
-// FetchFilesFromAsgs looks up the EC2 Instances in all the ASGs given in the RemoteFileSpecification,
-// looks up the public IPs of those EC2 Instances, connects to each Instance via SSH using the given
-// username and Key Pair, downloads the files matching filenameFilters at the given
-// remoteDirectory (using sudo if useSudo is true), and stores the files locally at
+// FetchFilesFromAsgs looks up the EC2 Instances in all the ASGs given in the RemoteFileSpecification, and the
+// running EC2 Instances that have all of its TagFilters if any, looks up the public IPs of those EC2 Instances,
+// connects to each Instance via SSH using the given username and Key Pair, downloads the files matching
+// filenameFilters at the given remoteDirectory (using sudo if useSudo is true), and stores the files locally at
 // localDirectory/<publicip>/<remoteFolderName>
 func FetchFilesFromAsgs(t testing.TestingT, awsRegion string, spec RemoteFileSpecification) {
 	err := FetchFilesFromAsgsE(t, awsRegion, spec)
@@ -206,30 +458,230 @@ func FetchFilesFromAsgs(t testing.TestingT, awsRegion string, spec RemoteFileSpe
 	}
 }
 
-// FetchFilesFromAsgsE looks up the EC2 Instances in all the ASGs given in the RemoteFileSpecification,
-// looks up the public IPs of those EC2 Instances, connects to each Instance via SSH using the given
-// username and Key Pair, downloads the files matching filenameFilters at the given
-// remoteDirectory (using sudo if useSudo is true), and stores the files locally at
-// localDirectory/<publicip>/<remoteFolderName>
+// FetchFilesFromAsgsE looks up the EC2 Instances in all the ASGs given in the RemoteFileSpecification, and the
+// running EC2 Instances that have all of its TagFilters if any, looks up the public IPs of those EC2 Instances,
+// connects to each Instance via SSH using the given username and Key Pair, downloads the files matching
+// filenameFilters at the given remoteDirectory (using sudo if useSudo is true), and stores the files locally at
+// localDirectory/<publicip>/<remoteFolderName>. If spec.Bastion is set, the connections are proxied through it to the
+// private IPs of the EC2 Instances instead, which are then used in the local paths. If spec.AddressMode is set, that
+// address of the EC2 Instances is connected to instead, e.g. their IPv6 addresses. If spec.ConnectivityOrder is set,
+// each of its methods is tried in turn until one succeeds; files fetched via SSM are stored at
+// localDirectory/<instanceid>/<remoteFolderName>. If spec.MaxRetries is set, fetching from an Instance that can't be
+// connected to yet, e.g. because it is still booting, is retried. Files are fetched from up to spec.MaxParallel
+// instances at the same time, one remote directory at a time per instance, and the IPs of the instances are looked up
+// in batches, so that large fleets are not hammered with connections and DescribeInstances calls (use the ratelimit
+// package to throttle the calls further). If spec.OnlyHealthyInstances is set, the instances of the ASGs that are not
+// InService and Healthy are skipped. If spec.InstanceTimeout is set, instances that take longer are given up on. If
+// spec.BootDiagnosticsDir is set, the boot diagnostics of the instances that can't be connected to are saved there.
+// Every failure is returned as a FetchFilesFailed in a *multierror.Error, whose message groups identical failures and
+// lists the ASGs and instances they occurred on. See FetchFilesFromAsgsWithContextE to also bound the whole fetch.
 func FetchFilesFromAsgsE(t testing.TestingT, awsRegion string, spec RemoteFileSpecification) error {
-	var errorsOccurred = new(multierror.Error)
+	return FetchFilesFromAsgsWithContextE(context.Background(), t, awsRegion, spec)
+}
+
+// fetchFilesFromAsgsE fetches the files given in the RemoteFileSpecification from its instances, giving up on the
+// instances that are not done yet when the given context is done. See FetchFilesFromAsgsWithContextE.
+func fetchFilesFromAsgsE(ctx context.Context, t testing.TestingT, awsRegion string, spec RemoteFileSpecification) error {
+	var errorsOccurred = &multierror.Error{ErrorFormat: formatMultiError}
+
+	jobs := []fetchFilesJob{}
+	getAsgInstanceIdsE := GetInstanceIdsForAsgE
+	if spec.OnlyHealthyInstances {
+		getAsgInstanceIdsE = GetHealthyInstanceIdsForAsgE
+	}
 
 	for _, curAsg := range spec.AsgNames {
-		for curRemoteDir, fileFilters := range spec.RemotePathToFileFilter {
-
-			instanceIDs, err := GetInstanceIdsForAsgE(t, curAsg, awsRegion)
-			if err != nil {
-				errorsOccurred = multierror.Append(errorsOccurred, err)
-			} else {
-				for _, instanceID := range instanceIDs {
-					err = FetchFilesFromInstanceE(t, awsRegion, spec.SshUser, spec.KeyPair, instanceID, spec.UseSudo, curRemoteDir, spec.LocalDestinationDir, fileFilters)
-
-					if err != nil {
-						errorsOccurred = multierror.Append(errorsOccurred, err)
-					}
+		instanceIDs, err := getAsgInstanceIdsE(t, curAsg, awsRegion)
+		if err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, FetchFilesFailed{AsgName: curAsg, Underlying: err})
+			continue
+		}
+
+		for curRemoteDir := range spec.RemotePathToFileFilter {
+			for _, instanceID := range instanceIDs {
+				jobs = append(jobs, fetchFilesJob{asgName: curAsg, instanceID: instanceID, remoteDir: curRemoteDir})
+			}
+		}
+	}
+
+	if len(spec.TagFilters) > 0 {
+		tags := formatTagFilters(spec.TagFilters)
+		instanceIDs, err := getRunningInstanceIdsByTagsE(t, awsRegion, spec.TagFilters)
+		if err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, FetchFilesFailed{Tags: tags, Underlying: err})
+		}
+
+		for curRemoteDir := range spec.RemotePathToFileFilter {
+			for _, instanceID := range instanceIDs {
+				// Skip the instances of the ASGs that have the tags too, so files are fetched from each instance once
+				if !hasFetchFilesJob(jobs, instanceID, curRemoteDir) {
+					jobs = append(jobs, fetchFilesJob{tags: tags, instanceID: instanceID, remoteDir: curRemoteDir})
 				}
 			}
 		}
 	}
+
+	conn := instanceConnectivity{
+		order:       spec.ConnectivityOrder,
+		bastion:     spec.Bastion,
+		sshUserName: spec.SshUser,
+		keyPair:     spec.KeyPair,
+		addressMode: spec.AddressMode,
+		ips:         newInstanceIpLookup(t, awsRegion, fetchFilesJobInstanceIDs(jobs)),
+	}
+	fetchErrors := runFetchFilesJobs(ctx, jobs, spec.MaxParallel, spec.InstanceTimeout, func(job fetchFilesJob) error {
+		description := fmt.Sprintf("Fetching files in %s from EC2 Instance %s", job.remoteDir, job.instanceID)
+		err := withSshConnectionRetryE(t, description, spec.MaxRetries, spec.SleepBetweenRetries, func() error {
+			return fetchFilesFromInstanceWithFallbackE(t, awsRegion, conn, job.instanceID, spec.UseSudo, job.remoteDir, spec.LocalDestinationDir, spec.RemotePathToFileFilter[job.remoteDir], fetchFilesMode{verifyChecksums: spec.VerifyChecksums, archive: spec.TransferAsArchive})
+		})
+		if spec.BootDiagnosticsDir != "" && errors.Is(err, ssh.ErrConnectionFailed) {
+			// The diagnostics only help explain the connection failure, so failing to save them is not an error
+			if diagnosticsErr := SaveBootDiagnosticsE(t, awsRegion, job.instanceID, spec.BootDiagnosticsDir); diagnosticsErr != nil {
+				logger.Logf(t, "Failed to save boot diagnostics for Instance %s: %s", job.instanceID, diagnosticsErr)
+			}
+		}
+		return err
+	})
+	errorsOccurred = multierror.Append(errorsOccurred, fetchErrors...)
+
 	return errorsOccurred.ErrorOrNil()
 }
+
+// getSshHostForInstanceE returns the host to connect to the EC2 Instance with the given ID via SSH, at its address of the
+// given mode. If a bastion host is given, the connection is proxied through it, by default to the private IP of the
+// Instance, otherwise its public IP is used by default.
+func getSshHostForInstanceE(t testing.TestingT, awsRegion string, bastion *ssh.Host, mode AddressMode, sshUserName string, keyPair *Ec2Keypair, instanceID string) (ssh.Host, error) {
+	ip, err := getEc2InstanceAddressE(t, instanceID, awsRegion, mode.orDefault(bastion))
+	if err != nil {
+		return ssh.Host{}, err
+	}
+
+	return newInstanceSshHost(ip, bastion, sshUserName, keyPair), nil
+}
+
+// newInstanceSshHost returns the host to connect to an EC2 Instance at the given IP via SSH, through the given bastion
+// host if it is not nil.
+func newInstanceSshHost(ip string, bastion *ssh.Host, sshUserName string, keyPair *Ec2Keypair) ssh.Host {
+	return ssh.Host{
+		Hostname:    ip,
+		SshUserName: sshUserName,
+		SshKeyPair:  keyPair.KeyPair,
+		JumpHost:    bastion,
+	}
+}
+
+// getRunningInstanceIdsByTagsE returns the IDs of the running EC2 Instances in the given region that have all the given
+// tags.
+func getRunningInstanceIdsByTagsE(t testing.TestingT, awsRegion string, tags map[string]string) ([]string, error) {
+	ec2Filters := map[string][]string{
+		"instance-state-name": {ec2.InstanceStateNameRunning},
+	}
+	for name, value := range tags {
+		ec2Filters[fmt.Sprintf("tag:%s", name)] = []string{value}
+	}
+	return GetEc2InstanceIdsByFiltersE(t, awsRegion, ec2Filters)
+}
+
+// formatTagFilters returns the given tags as name=value pairs sorted by name, e.g. "Env=test,Name=my-test-host".
+func formatTagFilters(tags map[string]string) string {
+	pairs := []string{}
+	for name, value := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// fetchFilesJob is a directory of an instance of an ASG, or of an instance found by its tags, to fetch files from.
+type fetchFilesJob struct {
+	asgName    string
+	tags       string // The tags the instance was found by, if it is not an instance of an ASG
+	instanceID string
+	remoteDir  string
+}
+
+// hasFetchFilesJob returns true if one of the given jobs fetches files from the given directory of the given instance.
+func hasFetchFilesJob(jobs []fetchFilesJob, instanceID string, remoteDir string) bool {
+	for _, job := range jobs {
+		if job.instanceID == instanceID && job.remoteDir == remoteDir {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchFilesJobInstanceIDs returns the IDs of the instances of the given jobs, without duplicates.
+func fetchFilesJobInstanceIDs(jobs []fetchFilesJob) []string {
+	instanceIDs := []string{}
+	seen := map[string]bool{}
+	for _, job := range jobs {
+		if !seen[job.instanceID] {
+			seen[job.instanceID] = true
+			instanceIDs = append(instanceIDs, job.instanceID)
+		}
+	}
+	return instanceIDs
+}
+
+// runFetchFilesJobs runs the given jobs with the given fetch function on up to maxParallel instances at the same time,
+// running the jobs of each instance one after the other so that no instance gets more than one connection at a time.
+// The jobs of an instance are given up on once the given context is done or, if instanceTimeout is set, once they have
+// run for that long. It returns a FetchFilesFailed for each job that failed, in the order of the jobs.
+func runFetchFilesJobs(ctx context.Context, jobs []fetchFilesJob, maxParallel int, instanceTimeout time.Duration, fetch func(job fetchFilesJob) error) []error {
+	if maxParallel <= 0 {
+		maxParallel = 1
+	}
+
+	// The indexes of the jobs of each instance, in the order the instances first appear in the jobs
+	instanceIDs := []string{}
+	jobsByInstance := map[string][]int{}
+	for i, job := range jobs {
+		if _, seen := jobsByInstance[job.instanceID]; !seen {
+			instanceIDs = append(instanceIDs, job.instanceID)
+		}
+		jobsByInstance[job.instanceID] = append(jobsByInstance[job.instanceID], i)
+	}
+
+	results := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxParallel)
+
+	for _, instanceID := range instanceIDs {
+		// Once the context is done, don't wait for a slot: the remaining jobs are given up on right away
+		acquired := false
+		select {
+		case slots <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+
+		wg.Add(1)
+		go func(jobIndexes []int, acquired bool) {
+			defer wg.Done()
+			if acquired {
+				defer func() { <-slots }()
+			}
+
+			instanceCtx, cancel := withInstanceTimeout(ctx, instanceTimeout)
+			defer cancel()
+
+			// Each job writes to its own localDirectory/<publicip>/<remoteFolderName>, and to its own slot of results,
+			// so no locking is needed
+			for _, i := range jobIndexes {
+				job := jobs[i]
+				if err := withFetchContextE(instanceCtx, job.instanceID, func() error { return fetch(job) }); err != nil {
+					results[i] = FetchFilesFailed{AsgName: job.asgName, Tags: job.tags, InstanceId: job.instanceID, RemoteDir: job.remoteDir, Underlying: err}
+				}
+			}
+		}(jobsByInstance[instanceID], acquired)
+	}
+	wg.Wait()
+
+	failures := []error{}
+	for _, result := range results {
+		if result != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
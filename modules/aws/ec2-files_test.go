@@ -0,0 +1,145 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFetchFilesJobsBoundsParallelismAndCollectsErrors(t *testing.T) {
+	t.Parallel()
+
+	jobs := []fetchFilesJob{}
+	for i := 0; i < 12; i++ {
+		jobs = append(jobs, fetchFilesJob{asgName: "asg", instanceID: fmt.Sprintf("i-%d", i), remoteDir: "/var/log"})
+	}
+
+	var running, maxRunning int32
+	failures := runFetchFilesJobs(context.Background(), jobs, 4, 0, func(job fetchFilesJob) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if job.instanceID == "i-3" || job.instanceID == "i-7" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	assert.True(t, atomic.LoadInt32(&maxRunning) <= 4)
+	assert.True(t, atomic.LoadInt32(&maxRunning) > 1)
+	require.Len(t, failures, 2)
+	assert.Equal(t, FetchFilesFailed{AsgName: "asg", InstanceId: "i-3", RemoteDir: "/var/log", Underlying: errors.New("connection refused")}, failures[0])
+	assert.Equal(t, "i-7", failures[1].(FetchFilesFailed).InstanceId)
+}
+
+func TestRunFetchFilesJobsConnectsToEachInstanceOnceAtATime(t *testing.T) {
+	t.Parallel()
+
+	jobs := []fetchFilesJob{}
+	for i := 0; i < 3; i++ {
+		for _, remoteDir := range []string{"/var/log", "/etc/app", "/opt/app/logs"} {
+			jobs = append(jobs, fetchFilesJob{asgName: "asg", instanceID: fmt.Sprintf("i-%d", i), remoteDir: remoteDir})
+		}
+	}
+
+	var mutex sync.Mutex
+	running := map[string]int{}
+	overlapped := false
+	failures := runFetchFilesJobs(context.Background(), jobs, 10, 0, func(job fetchFilesJob) error {
+		mutex.Lock()
+		running[job.instanceID]++
+		overlapped = overlapped || running[job.instanceID] > 1
+		mutex.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mutex.Lock()
+		running[job.instanceID]--
+		mutex.Unlock()
+		return nil
+	})
+
+	assert.Empty(t, failures)
+	assert.False(t, overlapped)
+}
+
+func TestFetchFilesJobInstanceIDs(t *testing.T) {
+	t.Parallel()
+
+	jobs := []fetchFilesJob{{instanceID: "i-1", remoteDir: "/a"}, {instanceID: "i-2", remoteDir: "/a"}, {instanceID: "i-1", remoteDir: "/b"}}
+	assert.Equal(t, []string{"i-1", "i-2"}, fetchFilesJobInstanceIDs(jobs))
+}
+
+func TestNewInstanceSshHost(t *testing.T) {
+	t.Parallel()
+
+	keyPair := &Ec2Keypair{KeyPair: &ssh.KeyPair{PublicKey: "public", PrivateKey: "private"}}
+
+	host := newInstanceSshHost("54.0.0.1", nil, "ubuntu", keyPair)
+	assert.Equal(t, ssh.Host{Hostname: "54.0.0.1", SshUserName: "ubuntu", SshKeyPair: keyPair.KeyPair}, host)
+
+	bastion := &ssh.Host{Hostname: "bastion.example.com", SshUserName: "ec2-user", SshAgent: true}
+	host = newInstanceSshHost("10.0.0.1", bastion, "ubuntu", keyPair)
+	assert.Equal(t, "10.0.0.1", host.Hostname)
+	assert.Equal(t, bastion, host.JumpHost)
+}
+
+func TestFormatTagFilters(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Env=test,Name=my-test-host", formatTagFilters(map[string]string{"Name": "my-test-host", "Env": "test"}))
+}
+
+func TestHasFetchFilesJob(t *testing.T) {
+	t.Parallel()
+
+	jobs := []fetchFilesJob{{asgName: "asg", instanceID: "i-1", remoteDir: "/var/log"}}
+	assert.True(t, hasFetchFilesJob(jobs, "i-1", "/var/log"))
+	assert.False(t, hasFetchFilesJob(jobs, "i-1", "/etc"))
+	assert.False(t, hasFetchFilesJob(jobs, "i-2", "/var/log"))
+}
+
+func TestFetchFilesFailedItemForTaggedInstances(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "instances tagged Name=host", FetchFilesFailed{Tags: "Name=host"}.Item())
+	assert.Equal(t, "instance i-1 tagged Name=host, directory /var/log", FetchFilesFailed{Tags: "Name=host", InstanceId: "i-1", RemoteDir: "/var/log"}.Item())
+}
+
+func TestFetchContentsOfFilesFromInstancesKeepsHealthyInstances(t *testing.T) {
+	t.Parallel()
+
+	result := fetchContentsOfFilesFromInstances("asg", []string{"i-1", "i-2", "i-3"}, func(instanceID string) (map[string]string, error) {
+		if instanceID == "i-2" {
+			return nil, errors.New("connection refused")
+		}
+		return map[string]string{"/etc/hostname": instanceID}, nil
+	})
+
+	assert.Equal(t, map[string]map[string]string{"i-1": {"/etc/hostname": "i-1"}, "i-3": {"/etc/hostname": "i-3"}}, result.Contents)
+	assert.Equal(t, map[string]error{"i-2": errors.New("connection refused")}, result.Errors)
+
+	err := result.Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused\n      - ASG asg, instance i-2")
+
+	healthy := fetchContentsOfFilesFromInstances("asg", []string{"i-1"}, func(instanceID string) (map[string]string, error) {
+		return map[string]string{}, nil
+	})
+	assert.NoError(t, healthy.Err())
+}
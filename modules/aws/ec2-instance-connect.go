@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2instanceconnect"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// NewEc2InstanceConnectSshAuth returns an SshAuth that connects to instances with EC2 Instance Connect: an ephemeral
+// key is generated locally and pushed to each instance right before connecting to it, so no EC2 Key Pair or SSH agent
+// is needed. The instances must run the EC2 Instance Connect agent, as Amazon Linux 2 and Ubuntu AMIs do.
+func NewEc2InstanceConnectSshAuth(t testing.TestingT, region string) *SshAuth {
+	auth, err := NewEc2InstanceConnectSshAuthE(t, region)
+	require.NoError(t, err)
+	return auth
+}
+
+// NewEc2InstanceConnectSshAuthE returns an SshAuth that connects to instances with EC2 Instance Connect: an ephemeral
+// key is generated locally and pushed to each instance right before connecting to it, so no EC2 Key Pair or SSH agent
+// is needed. The instances must run the EC2 Instance Connect agent, as Amazon Linux 2 and Ubuntu AMIs do.
+func NewEc2InstanceConnectSshAuthE(t testing.TestingT, region string) (*SshAuth, error) {
+	// EC2 Instance Connect only accepts RSA keys of at least 2048 bits
+	keyPair, err := ssh.GenerateRSAKeyPairE(t, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SshAuth{
+		KeyPair:            &Ec2Keypair{KeyPair: keyPair, Region: region},
+		Region:             region,
+		Ec2InstanceConnect: true,
+	}, nil
+}
+
+// SendSshPublicKey pushes the given SSH public key to the EC2 Instance with the given ID with EC2 Instance Connect, so
+// that the given OS user can connect with the matching private key for the next 60 seconds.
+func SendSshPublicKey(t testing.TestingT, region string, instanceID string, osUser string, publicKey string) {
+	require.NoError(t, SendSshPublicKeyE(t, region, instanceID, osUser, publicKey))
+}
+
+// SendSshPublicKeyE pushes the given SSH public key to the EC2 Instance with the given ID with EC2 Instance Connect, so
+// that the given OS user can connect with the matching private key for the next 60 seconds.
+func SendSshPublicKeyE(t testing.TestingT, region string, instanceID string, osUser string, publicKey string) error {
+	// The API needs the Availability Zone of the instance, even though the ID alone identifies it
+	placement, err := GetEc2InstancePlacementE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+
+	client, err := NewEc2InstanceConnectClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Sending SSH public key for user %s to EC2 Instance %s in %s", osUser, instanceID, region)
+	_, err = client.SendSSHPublicKey(&ec2instanceconnect.SendSSHPublicKeyInput{
+		AvailabilityZone: aws.String(placement.AvailabilityZone),
+		InstanceId:       aws.String(instanceID),
+		InstanceOSUser:   aws.String(osUser),
+		SSHPublicKey:     aws.String(publicKey),
+	})
+	return err
+}
+
+// NewEc2InstanceConnectClient creates an EC2 Instance Connect client.
+func NewEc2InstanceConnectClient(t testing.TestingT, region string) *ec2instanceconnect.EC2InstanceConnect {
+	client, err := NewEc2InstanceConnectClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewEc2InstanceConnectClientE creates an EC2 Instance Connect client.
+func NewEc2InstanceConnectClientE(t testing.TestingT, region string) (*ec2instanceconnect.EC2InstanceConnect, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return ec2instanceconnect.New(sess), nil
+}
@@ -0,0 +1,23 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEc2InstanceConnectSshAuth(t *testing.T) {
+	t.Parallel()
+
+	auth := NewEc2InstanceConnectSshAuth(t, "us-east-1")
+
+	assert.True(t, auth.Ec2InstanceConnect)
+	assert.Empty(t, auth.KeyPair.Name)
+	// EC2 Instance Connect rejects public keys shorter than 256 characters
+	assert.True(t, len(auth.KeyPair.PublicKey) >= 256)
+	assert.Equal(t, auth.KeyPair.KeyPair, auth.Host("10.0.0.1", "ec2-user").SshKeyPair)
+
+	// There is no EC2 Key Pair to delete
+	require.NoError(t, DeleteTemporarySshAccessE(t, auth))
+}
@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// describeInstancesBatchSize is the number of instances to look up per DescribeInstances call.
+const describeInstancesBatchSize = 500
+
+// instanceIpLookup looks up the IPs of a fleet of EC2 Instances in batches, the first time the IP of any of them is
+// needed, so that fetching files from a large fleet makes a DescribeInstances call per batch of instances rather than
+// one per instance. It is safe for concurrent use.
+type instanceIpLookup struct {
+	instanceIDs []string
+	describe    func(instanceIDs []string, mode AddressMode) (map[string]string, error)
+
+	mutex sync.Mutex
+	ips   map[AddressMode]map[string]string // The mode of the addresses, to the addresses by instance ID
+}
+
+// newInstanceIpLookup returns a lookup of the IPs of the given EC2 Instances in the given region.
+func newInstanceIpLookup(t testing.TestingT, awsRegion string, instanceIDs []string) *instanceIpLookup {
+	return &instanceIpLookup{
+		instanceIDs: instanceIDs,
+		describe: func(instanceIDs []string, mode AddressMode) (map[string]string, error) {
+			return getEc2InstanceAddressesE(t, instanceIDs, awsRegion, mode)
+		},
+		ips: map[AddressMode]map[string]string{},
+	}
+}
+
+// getIpE returns the address of the given mode, e.g. the public or private IP, of the given EC2 Instance, looking up the
+// addresses of all the instances of the lookup if they have not been yet. Failed lookups are retried by the next call.
+func (lookup *instanceIpLookup) getIpE(instanceID string, awsRegion string, mode AddressMode) (string, error) {
+	lookup.mutex.Lock()
+	defer lookup.mutex.Unlock()
+
+	ips, looked := lookup.ips[mode]
+	if !looked {
+		ips = map[string]string{}
+		for start := 0; start < len(lookup.instanceIDs); start += describeInstancesBatchSize {
+			end := start + describeInstancesBatchSize
+			if end > len(lookup.instanceIDs) {
+				end = len(lookup.instanceIDs)
+			}
+
+			batch, err := lookup.describe(lookup.instanceIDs[start:end], mode)
+			if err != nil {
+				return "", err
+			}
+			for id, ip := range batch {
+				ips[id] = ip
+			}
+		}
+		lookup.ips[mode] = ips
+	}
+
+	return findEc2InstanceAddressE(ips, instanceID, awsRegion, mode)
+}
@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceIpLookupBatchesAndRetriesFailures(t *testing.T) {
+	t.Parallel()
+
+	instanceIDs := []string{}
+	for i := 0; i < describeInstancesBatchSize+1; i++ {
+		instanceIDs = append(instanceIDs, fmt.Sprintf("i-%d", i))
+	}
+
+	calls := [][]string{}
+	fail := true
+	lookup := &instanceIpLookup{
+		instanceIDs: instanceIDs,
+		describe: func(ids []string, mode AddressMode) (map[string]string, error) {
+			calls = append(calls, ids)
+			if fail {
+				fail = false
+				return nil, errors.New("RequestLimitExceeded")
+			}
+			ips := map[string]string{}
+			for _, id := range ids {
+				if id != "i-7" {
+					ips[id] = fmt.Sprintf("%s/%s", mode, id)
+				}
+			}
+			return ips, nil
+		},
+		ips: map[AddressMode]map[string]string{},
+	}
+
+	_, err := lookup.getIpE("i-0", "us-east-1", AddressPublicIp)
+	assert.EqualError(t, err, "RequestLimitExceeded")
+
+	ip, err := lookup.getIpE(fmt.Sprintf("i-%d", describeInstancesBatchSize), "us-east-1", AddressPublicIp)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("public-ip/i-%d", describeInstancesBatchSize), ip)
+
+	ip, err = lookup.getIpE("i-1", "us-east-1", AddressPublicIp)
+	require.NoError(t, err)
+	assert.Equal(t, "public-ip/i-1", ip)
+
+	// One failed call, then one call per batch; the second lookup is served from the first
+	require.Len(t, calls, 3)
+	assert.Len(t, calls[1], describeInstancesBatchSize)
+	assert.Len(t, calls[2], 1)
+
+	ip, err = lookup.getIpE("i-1", "us-east-1", AddressPrivateIp)
+	require.NoError(t, err)
+	assert.Equal(t, "private-ip/i-1", ip)
+
+	_, err = lookup.getIpE("i-7", "us-east-1", AddressPublicIp)
+	assert.Equal(t, IpForEc2InstanceNotFound{InstanceId: "i-7", AwsRegion: "us-east-1", Type: "public"}, err)
+
+	_, err = lookup.getIpE("i-7", "us-east-1", AddressIpv6)
+	assert.Equal(t, IpForEc2InstanceNotFound{InstanceId: "i-7", AwsRegion: "us-east-1", Type: "IPv6"}, err)
+}
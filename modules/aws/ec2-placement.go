@@ -0,0 +1,252 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Ec2InstancePlacement describes where an EC2 Instance was launched.
+type Ec2InstancePlacement struct {
+	AvailabilityZone      string
+	PlacementGroup        string
+	PartitionNumber       int64
+	Tenancy               string // One of default, dedicated or host
+	HostId                string // Only set for instances with host tenancy
+	CapacityReservationId string // Only set for instances running in a targeted capacity reservation
+}
+
+// GetEc2InstancePlacement returns the placement details of the EC2 Instance with the given ID in the given region.
+func GetEc2InstancePlacement(t testing.TestingT, region string, instanceID string) Ec2InstancePlacement {
+	placement, err := GetEc2InstancePlacementE(t, region, instanceID)
+	require.NoError(t, err)
+	return placement
+}
+
+// GetEc2InstancePlacementE returns the placement details of the EC2 Instance with the given ID in the given region.
+func GetEc2InstancePlacementE(t testing.TestingT, region string, instanceID string) (Ec2InstancePlacement, error) {
+	instance, err := GetEc2InstanceE(t, region, instanceID)
+	if err != nil {
+		return Ec2InstancePlacement{}, err
+	}
+
+	return newEc2InstancePlacement(instance), nil
+}
+
+// newEc2InstancePlacement extracts the placement details from the given EC2 Instance description.
+func newEc2InstancePlacement(instance *ec2.Instance) Ec2InstancePlacement {
+	placement := Ec2InstancePlacement{
+		CapacityReservationId: aws.StringValue(instance.CapacityReservationId),
+	}
+
+	if instance.Placement != nil {
+		placement.AvailabilityZone = aws.StringValue(instance.Placement.AvailabilityZone)
+		placement.PlacementGroup = aws.StringValue(instance.Placement.GroupName)
+		placement.PartitionNumber = aws.Int64Value(instance.Placement.PartitionNumber)
+		placement.Tenancy = aws.StringValue(instance.Placement.Tenancy)
+		placement.HostId = aws.StringValue(instance.Placement.HostId)
+	}
+
+	return placement
+}
+
+// AssertEc2InstanceInPlacementGroup checks that the EC2 Instance with the given ID was launched in the given placement
+// group.
+func AssertEc2InstanceInPlacementGroup(t testing.TestingT, region string, instanceID string, placementGroup string) {
+	err := AssertEc2InstanceInPlacementGroupE(t, region, instanceID, placementGroup)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceInPlacementGroupE checks that the EC2 Instance with the given ID was launched in the given placement
+// group and returns an error if it was not.
+func AssertEc2InstanceInPlacementGroupE(t testing.TestingT, region string, instanceID string, placementGroup string) error {
+	placement, err := GetEc2InstancePlacementE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+
+	return checkEc2InstanceAttribute(instanceID, "placement group", placementGroup, placement.PlacementGroup)
+}
+
+// AssertEc2InstanceTenancy checks that the EC2 Instance with the given ID has the given tenancy (default, dedicated or
+// host).
+func AssertEc2InstanceTenancy(t testing.TestingT, region string, instanceID string, tenancy string) {
+	err := AssertEc2InstanceTenancyE(t, region, instanceID, tenancy)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceTenancyE checks that the EC2 Instance with the given ID has the given tenancy (default, dedicated or
+// host) and returns an error if it does not.
+func AssertEc2InstanceTenancyE(t testing.TestingT, region string, instanceID string, tenancy string) error {
+	placement, err := GetEc2InstancePlacementE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+
+	return checkEc2InstanceAttribute(instanceID, "tenancy", tenancy, placement.Tenancy)
+}
+
+// AssertEc2InstanceOnDedicatedHost checks that the EC2 Instance with the given ID is running on the given dedicated
+// host.
+func AssertEc2InstanceOnDedicatedHost(t testing.TestingT, region string, instanceID string, hostID string) {
+	err := AssertEc2InstanceOnDedicatedHostE(t, region, instanceID, hostID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceOnDedicatedHostE checks that the EC2 Instance with the given ID is running on the given dedicated
+// host and returns an error if it is not.
+func AssertEc2InstanceOnDedicatedHostE(t testing.TestingT, region string, instanceID string, hostID string) error {
+	placement, err := GetEc2InstancePlacementE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+
+	return checkEc2InstanceAttribute(instanceID, "dedicated host", hostID, placement.HostId)
+}
+
+// AssertEc2InstanceInCapacityReservation checks that the EC2 Instance with the given ID is running in the given
+// capacity reservation.
+func AssertEc2InstanceInCapacityReservation(t testing.TestingT, region string, instanceID string, capacityReservationID string) {
+	err := AssertEc2InstanceInCapacityReservationE(t, region, instanceID, capacityReservationID)
+	require.NoError(t, err)
+}
+
+// AssertEc2InstanceInCapacityReservationE checks that the EC2 Instance with the given ID is running in the given
+// capacity reservation and returns an error if it is not.
+func AssertEc2InstanceInCapacityReservationE(t testing.TestingT, region string, instanceID string, capacityReservationID string) error {
+	placement, err := GetEc2InstancePlacementE(t, region, instanceID)
+	if err != nil {
+		return err
+	}
+
+	return checkEc2InstanceAttribute(instanceID, "capacity reservation", capacityReservationID, placement.CapacityReservationId)
+}
+
+// GetPlacementGroup returns the placement group with the given name in the given region.
+func GetPlacementGroup(t testing.TestingT, region string, placementGroup string) *ec2.PlacementGroup {
+	group, err := GetPlacementGroupE(t, region, placementGroup)
+	require.NoError(t, err)
+	return group
+}
+
+// GetPlacementGroupE returns the placement group with the given name in the given region.
+func GetPlacementGroupE(t testing.TestingT, region string, placementGroup string) (*ec2.PlacementGroup, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribePlacementGroups(&ec2.DescribePlacementGroupsInput{GroupNames: aws.StringSlice([]string{placementGroup})})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.PlacementGroups) == 0 {
+		return nil, NewNotFoundError("Placement Group", placementGroup, region)
+	}
+
+	return output.PlacementGroups[0], nil
+}
+
+// GetDedicatedHostInstanceIds returns the IDs of the EC2 Instances running on the given dedicated host.
+func GetDedicatedHostInstanceIds(t testing.TestingT, region string, hostID string) []string {
+	instanceIDs, err := GetDedicatedHostInstanceIdsE(t, region, hostID)
+	require.NoError(t, err)
+	return instanceIDs
+}
+
+// GetDedicatedHostInstanceIdsE returns the IDs of the EC2 Instances running on the given dedicated host.
+func GetDedicatedHostInstanceIdsE(t testing.TestingT, region string, hostID string) ([]string, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeHosts(&ec2.DescribeHostsInput{HostIds: aws.StringSlice([]string{hostID})})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.Hosts) == 0 {
+		return nil, NewNotFoundError("Dedicated Host", hostID, region)
+	}
+
+	instanceIDs := []string{}
+	for _, instance := range output.Hosts[0].Instances {
+		instanceIDs = append(instanceIDs, aws.StringValue(instance.InstanceId))
+	}
+
+	return instanceIDs, nil
+}
+
+// GetCapacityReservation returns the capacity reservation with the given ID in the given region.
+func GetCapacityReservation(t testing.TestingT, region string, capacityReservationID string) *ec2.CapacityReservation {
+	reservation, err := GetCapacityReservationE(t, region, capacityReservationID)
+	require.NoError(t, err)
+	return reservation
+}
+
+// GetCapacityReservationE returns the capacity reservation with the given ID in the given region.
+func GetCapacityReservationE(t testing.TestingT, region string, capacityReservationID string) (*ec2.CapacityReservation, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := ec2.DescribeCapacityReservationsInput{CapacityReservationIds: aws.StringSlice([]string{capacityReservationID})}
+	output, err := client.DescribeCapacityReservations(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.CapacityReservations) == 0 {
+		return nil, NewNotFoundError("Capacity Reservation", capacityReservationID, region)
+	}
+
+	return output.CapacityReservations[0], nil
+}
+
+// AssertCapacityReservationUtilization checks that exactly expectedUsed instances are running in the given capacity
+// reservation.
+func AssertCapacityReservationUtilization(t testing.TestingT, region string, capacityReservationID string, expectedUsed int64) {
+	err := AssertCapacityReservationUtilizationE(t, region, capacityReservationID, expectedUsed)
+	require.NoError(t, err)
+}
+
+// AssertCapacityReservationUtilizationE checks that exactly expectedUsed instances are running in the given capacity
+// reservation and returns an error if that is not the case.
+func AssertCapacityReservationUtilizationE(t testing.TestingT, region string, capacityReservationID string, expectedUsed int64) error {
+	reservation, err := GetCapacityReservationE(t, region, capacityReservationID)
+	if err != nil {
+		return err
+	}
+
+	return checkCapacityReservationUtilization(reservation, expectedUsed)
+}
+
+// checkCapacityReservationUtilization returns an error if the number of instances running in the given capacity
+// reservation is not expectedUsed.
+func checkCapacityReservationUtilization(reservation *ec2.CapacityReservation, expectedUsed int64) error {
+	total := aws.Int64Value(reservation.TotalInstanceCount)
+	used := total - aws.Int64Value(reservation.AvailableInstanceCount)
+
+	if used != expectedUsed {
+		return CapacityReservationUtilizationMismatch{
+			CapacityReservationId: aws.StringValue(reservation.CapacityReservationId),
+			ExpectedUsed:          expectedUsed,
+			ActualUsed:            used,
+			Total:                 total,
+		}
+	}
+
+	return nil
+}
+
+// checkEc2InstanceAttribute returns an Ec2InstanceAttributeMismatch error if expected and actual differ.
+func checkEc2InstanceAttribute(instanceID string, attribute string, expected string, actual string) error {
+	if expected != actual {
+		return Ec2InstanceAttributeMismatch{InstanceId: instanceID, Attribute: attribute, Expected: expected, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEc2InstancePlacement(t *testing.T) {
+	t.Parallel()
+
+	instance := &ec2.Instance{
+		CapacityReservationId: aws.String("cr-0123456789abcdef0"),
+		Placement: &ec2.Placement{
+			AvailabilityZone: aws.String("us-east-1a"),
+			GroupName:        aws.String("hpc-cluster"),
+			PartitionNumber:  aws.Int64(2),
+			Tenancy:          aws.String(ec2.TenancyHost),
+			HostId:           aws.String("h-0123456789abcdef0"),
+		},
+	}
+
+	expected := Ec2InstancePlacement{
+		AvailabilityZone:      "us-east-1a",
+		PlacementGroup:        "hpc-cluster",
+		PartitionNumber:       2,
+		Tenancy:               ec2.TenancyHost,
+		HostId:                "h-0123456789abcdef0",
+		CapacityReservationId: "cr-0123456789abcdef0",
+	}
+	assert.Equal(t, expected, newEc2InstancePlacement(instance))
+	assert.Equal(t, Ec2InstancePlacement{}, newEc2InstancePlacement(&ec2.Instance{}))
+}
+
+func TestCheckCapacityReservationUtilization(t *testing.T) {
+	t.Parallel()
+
+	reservation := &ec2.CapacityReservation{
+		CapacityReservationId:  aws.String("cr-0123456789abcdef0"),
+		TotalInstanceCount:     aws.Int64(4),
+		AvailableInstanceCount: aws.Int64(1),
+	}
+
+	require.NoError(t, checkCapacityReservationUtilization(reservation, 3))
+
+	err := checkCapacityReservationUtilization(reservation, 4)
+	require.Error(t, err)
+	assert.Equal(t, CapacityReservationUtilizationMismatch{CapacityReservationId: "cr-0123456789abcdef0", ExpectedUsed: 4, ActualUsed: 3, Total: 4}, err)
+}
@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// instanceStatusPollInterval is how often WaitForInstanceStatusOkE looks up the status checks, which EC2 only updates
+// every few seconds anyway.
+const instanceStatusPollInterval = 10 * time.Second
+
+// WaitForInstanceStatusOk waits until the EC2 Instance with the given ID in the given region is running and passes
+// both its system and instance status checks, or the given timeout expires. This will fail the test if it does not.
+func WaitForInstanceStatusOk(t testing.TestingT, instanceID string, region string, timeout time.Duration) {
+	err := WaitForInstanceStatusOkE(t, instanceID, region, timeout)
+	require.NoError(t, err)
+}
+
+// WaitForInstanceStatusOkE waits until the EC2 Instance with the given ID in the given region is running and passes
+// both its system and instance status checks, or the given timeout expires. An Instance is "running" as soon as it
+// boots, well before its network is up and SSH or WinRM accept connections, so call this before connecting to a new
+// Instance. Returns a WaitTimedOut error if the checks don't pass in time, or an error right away if the Instance stops
+// or terminates.
+func WaitForInstanceStatusOkE(t testing.TestingT, instanceID string, region string, timeout time.Duration) error {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("EC2 Instance %s", instanceID),
+		DesiredState: "running with status checks ok",
+		Extract: func() (WaitState, error) {
+			out, err := client.DescribeInstanceStatus(&ec2.DescribeInstanceStatusInput{
+				InstanceIds:         aws.StringSlice([]string{instanceID}),
+				IncludeAllInstances: aws.Bool(true),
+			})
+			if err != nil {
+				return WaitState{}, err
+			}
+			if len(out.InstanceStatuses) == 0 {
+				return WaitState{Current: "not found"}, nil
+			}
+			return instanceStatusWaitState(instanceID, out.InstanceStatuses[0])
+		},
+	}
+
+	maxRetries := int(timeout / instanceStatusPollInterval)
+	return WaitForConditionE(t, condition, maxRetries, instanceStatusPollInterval)
+}
+
+// instanceStatusWaitState returns the state of the given Instance status for WaitForInstanceStatusOkE. Returns a
+// retry.FatalError if the Instance is stopping or terminating, as it will never pass its status checks then.
+func instanceStatusWaitState(instanceID string, status *ec2.InstanceStatus) (WaitState, error) {
+	state := aws.StringValue(status.InstanceState.Name)
+	switch state {
+	case ec2.InstanceStateNameShuttingDown, ec2.InstanceStateNameTerminated, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped:
+		return WaitState{}, retry.FatalError{Underlying: InstanceNotRunning{InstanceID: instanceID, State: state}}
+	}
+
+	systemStatus := summaryStatus(status.SystemStatus)
+	instanceStatus := summaryStatus(status.InstanceStatus)
+	return WaitState{
+		Current: fmt.Sprintf("%s, system status %s, instance status %s", state, systemStatus, instanceStatus),
+		Done:    state == ec2.InstanceStateNameRunning && systemStatus == ec2.SummaryStatusOk && instanceStatus == ec2.SummaryStatusOk,
+	}, nil
+}
+
+// summaryStatus returns the status of the given status check summary, which is "not-applicable" until the Instance is
+// running.
+func summaryStatus(summary *ec2.InstanceStatusSummary) string {
+	if summary == nil || summary.Status == nil {
+		return ec2.SummaryStatusNotApplicable
+	}
+	return aws.StringValue(summary.Status)
+}
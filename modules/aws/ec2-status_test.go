@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInstanceStatus(state string, systemStatus string, instanceStatus string) *ec2.InstanceStatus {
+	return &ec2.InstanceStatus{
+		InstanceState:  &ec2.InstanceState{Name: aws.String(state)},
+		SystemStatus:   &ec2.InstanceStatusSummary{Status: aws.String(systemStatus)},
+		InstanceStatus: &ec2.InstanceStatusSummary{Status: aws.String(instanceStatus)},
+	}
+}
+
+func TestInstanceStatusWaitState(t *testing.T) {
+	t.Parallel()
+
+	state, err := instanceStatusWaitState("i-123", newInstanceStatus("running", "ok", "initializing"))
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "running, system status ok, instance status initializing"}, state)
+
+	state, err = instanceStatusWaitState("i-123", newInstanceStatus("running", "ok", "ok"))
+	require.NoError(t, err)
+	assert.True(t, state.Done)
+
+	state, err = instanceStatusWaitState("i-123", &ec2.InstanceStatus{InstanceState: &ec2.InstanceState{Name: aws.String("pending")}})
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "pending, system status not-applicable, instance status not-applicable"}, state)
+
+	_, err = instanceStatusWaitState("i-123", newInstanceStatus("terminated", "not-applicable", "not-applicable"))
+	fatalErr, isFatalErr := err.(retry.FatalError)
+	require.True(t, isFatalErr)
+	assert.Equal(t, InstanceNotRunning{InstanceID: "i-123", State: "terminated"}, fatalErr.Underlying)
+	assert.True(t, errors.Is(fatalErr.Underlying, ErrOperationFailed))
+}
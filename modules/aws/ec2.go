@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
@@ -42,6 +43,16 @@ func GetPrivateIpsOfEc2Instances(t testing.TestingT, instanceIDs []string, awsRe
 
 // GetPrivateIpsOfEc2InstancesE gets the private IP address of the given EC2 Instance in the given region. Returns a map of instance ID to IP address.
 func GetPrivateIpsOfEc2InstancesE(t testing.TestingT, instanceIDs []string, awsRegion string) (map[string]string, error) {
+	ips, err := cachedDescribeE(t, describeCacheKey("ec2-private-ips", awsRegion, instanceIDs...), func() (interface{}, error) {
+		return getPrivateIpsOfEc2InstancesE(t, instanceIDs, awsRegion)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyStringMap(ips.(map[string]string)), nil
+}
+
+func getPrivateIpsOfEc2InstancesE(t testing.TestingT, instanceIDs []string, awsRegion string) (map[string]string, error) {
 	ec2Client := NewEc2Client(t, awsRegion)
 	// TODO: implement pagination for cases that extend beyond limit (1000 instances)
 	input := ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice(instanceIDs)}
@@ -147,6 +158,16 @@ func GetPublicIpsOfEc2Instances(t testing.TestingT, instanceIDs []string, awsReg
 
 // GetPublicIpsOfEc2InstancesE gets the public IP address of the given EC2 Instance in the given region. Returns a map of instance ID to IP address.
 func GetPublicIpsOfEc2InstancesE(t testing.TestingT, instanceIDs []string, awsRegion string) (map[string]string, error) {
+	ips, err := cachedDescribeE(t, describeCacheKey("ec2-public-ips", awsRegion, instanceIDs...), func() (interface{}, error) {
+		return getPublicIpsOfEc2InstancesE(t, instanceIDs, awsRegion)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyStringMap(ips.(map[string]string)), nil
+}
+
+func getPublicIpsOfEc2InstancesE(t testing.TestingT, instanceIDs []string, awsRegion string) (map[string]string, error) {
 	ec2Client := NewEc2Client(t, awsRegion)
 	// TODO: implement pagination for cases that extend beyond limit (1000 instances)
 	input := ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice(instanceIDs)}
@@ -166,6 +187,137 @@ func GetPublicIpsOfEc2InstancesE(t testing.TestingT, instanceIDs []string, awsRe
 	return ips, nil
 }
 
+// GetIpv6OfEc2Instance gets the IPv6 address of the primary network interface of the given EC2 Instance in the given
+// region.
+func GetIpv6OfEc2Instance(t testing.TestingT, instanceID string, awsRegion string) string {
+	ip, err := GetIpv6OfEc2InstanceE(t, instanceID, awsRegion)
+	require.NoError(t, err)
+	return ip
+}
+
+// GetIpv6OfEc2InstanceE gets the IPv6 address of the primary network interface of the given EC2 Instance in the given
+// region.
+func GetIpv6OfEc2InstanceE(t testing.TestingT, instanceID string, awsRegion string) (string, error) {
+	ips, err := GetIpv6sOfEc2InstancesE(t, []string{instanceID}, awsRegion)
+	if err != nil {
+		return "", err
+	}
+
+	ip := ips[instanceID]
+
+	if ip == "" {
+		return "", IpForEc2InstanceNotFound{InstanceId: instanceID, AwsRegion: awsRegion, Type: "IPv6"}
+	}
+
+	return ip, nil
+}
+
+// GetIpv6sOfEc2Instances gets the IPv6 addresses of the primary network interfaces of the given EC2 Instances in the
+// given region. Returns a map of instance ID to IP address.
+func GetIpv6sOfEc2Instances(t testing.TestingT, instanceIDs []string, awsRegion string) map[string]string {
+	ips, err := GetIpv6sOfEc2InstancesE(t, instanceIDs, awsRegion)
+	require.NoError(t, err)
+	return ips
+}
+
+// GetIpv6sOfEc2InstancesE gets the IPv6 addresses of the primary network interfaces of the given EC2 Instances in the
+// given region. Returns a map of instance ID to IP address, which is empty for instances without an IPv6 address.
+func GetIpv6sOfEc2InstancesE(t testing.TestingT, instanceIDs []string, awsRegion string) (map[string]string, error) {
+	ips, err := cachedDescribeE(t, describeCacheKey("ec2-ipv6s", awsRegion, instanceIDs...), func() (interface{}, error) {
+		return getEc2InstanceAttributesE(t, instanceIDs, awsRegion, primaryIpv6OfInstance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyStringMap(ips.(map[string]string)), nil
+}
+
+// primaryIpv6OfInstance returns the first IPv6 address of the primary network interface of the given EC2 Instance, or
+// an empty string if it has none.
+func primaryIpv6OfInstance(instance *ec2.Instance) string {
+	for _, networkInterface := range instance.NetworkInterfaces {
+		if networkInterface.Attachment == nil || aws.Int64Value(networkInterface.Attachment.DeviceIndex) != 0 {
+			continue
+		}
+		for _, address := range networkInterface.Ipv6Addresses {
+			if ip := aws.StringValue(address.Ipv6Address); ip != "" {
+				return ip
+			}
+		}
+	}
+	return ""
+}
+
+// GetPublicHostnameOfEc2Instance gets the public DNS name of the given EC2 Instance in the given region.
+func GetPublicHostnameOfEc2Instance(t testing.TestingT, instanceID string, awsRegion string) string {
+	hostname, err := GetPublicHostnameOfEc2InstanceE(t, instanceID, awsRegion)
+	require.NoError(t, err)
+	return hostname
+}
+
+// GetPublicHostnameOfEc2InstanceE gets the public DNS name of the given EC2 Instance in the given region.
+func GetPublicHostnameOfEc2InstanceE(t testing.TestingT, instanceID string, awsRegion string) (string, error) {
+	hostnames, err := GetPublicHostnamesOfEc2InstancesE(t, []string{instanceID}, awsRegion)
+	if err != nil {
+		return "", err
+	}
+
+	hostname := hostnames[instanceID]
+
+	if hostname == "" {
+		return "", HostnameForEc2InstanceNotFound{InstanceId: instanceID, AwsRegion: awsRegion, Type: "public"}
+	}
+
+	return hostname, nil
+}
+
+// GetPublicHostnamesOfEc2Instances gets the public DNS names of the given EC2 Instances in the given region. Returns a
+// map of instance ID to DNS name.
+func GetPublicHostnamesOfEc2Instances(t testing.TestingT, instanceIDs []string, awsRegion string) map[string]string {
+	hostnames, err := GetPublicHostnamesOfEc2InstancesE(t, instanceIDs, awsRegion)
+	require.NoError(t, err)
+	return hostnames
+}
+
+// GetPublicHostnamesOfEc2InstancesE gets the public DNS names of the given EC2 Instances in the given region. Returns a
+// map of instance ID to DNS name, which is empty for instances without one, e.g. in VPCs without DNS hostnames enabled.
+func GetPublicHostnamesOfEc2InstancesE(t testing.TestingT, instanceIDs []string, awsRegion string) (map[string]string, error) {
+	hostnames, err := cachedDescribeE(t, describeCacheKey("ec2-public-hostnames", awsRegion, instanceIDs...), func() (interface{}, error) {
+		return getEc2InstanceAttributesE(t, instanceIDs, awsRegion, func(instance *ec2.Instance) string {
+			return aws.StringValue(instance.PublicDnsName)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyStringMap(hostnames.(map[string]string)), nil
+}
+
+// getEc2InstanceAttributesE describes the given EC2 Instances and returns a map of instance ID to the attribute that
+// the given function extracts from each of them.
+func getEc2InstanceAttributesE(t testing.TestingT, instanceIDs []string, awsRegion string, attribute func(instance *ec2.Instance) string) (map[string]string, error) {
+	ec2Client, err := NewEc2ClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+	// TODO: implement pagination for cases that extend beyond limit (1000 instances)
+	input := ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice(instanceIDs)}
+	output, err := ec2Client.DescribeInstances(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]string{}
+
+	for _, reserveration := range output.Reservations {
+		for _, instance := range reserveration.Instances {
+			attributes[aws.StringValue(instance.InstanceId)] = attribute(instance)
+		}
+	}
+
+	return attributes, nil
+}
+
 // GetEc2InstanceIdsByTag returns all the IDs of EC2 instances in the given region with the given tag.
 func GetEc2InstanceIdsByTag(t testing.TestingT, region string, tagName string, tagValue string) []string {
 	out, err := GetEc2InstanceIdsByTagE(t, region, tagName, tagValue)
@@ -268,6 +420,10 @@ func DeleteAmi(t testing.TestingT, region string, imageID string) {
 
 // DeleteAmiE deletes the given AMI in the given region.
 func DeleteAmiE(t testing.TestingT, region string, imageID string) error {
+	if dryrun.Skip(t, "deregister AMI %s in %s", imageID, region) {
+		return nil
+	}
+
 	logger.Logf(t, "Deregistering AMI %s", imageID)
 
 	client, err := NewEc2ClientE(t, region)
@@ -312,8 +468,13 @@ func TerminateInstance(t testing.TestingT, region string, instanceID string) {
 	require.NoError(t, TerminateInstanceE(t, region, instanceID))
 }
 
-// TerminateInstanceE terminates the EC2 instance with the given ID in the given region.
+// TerminateInstanceE terminates the EC2 instance with the given ID in the given region. This invalidates the describe
+// cache of the test, if enabled.
 func TerminateInstanceE(t testing.TestingT, region string, instanceID string) error {
+	if dryrun.Skip(t, "terminate Instance %s", instanceID) {
+		return nil
+	}
+
 	logger.Logf(t, "Terminating Instance %s", instanceID)
 
 	client, err := NewEc2ClientE(t, region)
@@ -326,6 +487,7 @@ func TerminateInstanceE(t testing.TestingT, region string, instanceID string) er
 			aws.String(instanceID),
 		},
 	})
+	InvalidateDescribeCache(t)
 
 	return err
 }
@@ -524,6 +686,36 @@ func getAllAvailabilityZonesE(client *ec2.EC2) ([]string, error) {
 	return azs, nil
 }
 
+// GetEc2Instance returns the description of the EC2 Instance with the given ID in the given region.
+func GetEc2Instance(t testing.TestingT, region string, instanceID string) *ec2.Instance {
+	instance, err := GetEc2InstanceE(t, region, instanceID)
+	require.NoError(t, err)
+	return instance
+}
+
+// GetEc2InstanceE returns the description of the EC2 Instance with the given ID in the given region.
+func GetEc2InstanceE(t testing.TestingT, region string, instanceID string) (*ec2.Instance, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice([]string{instanceID})})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reservation := range output.Reservations {
+		for _, instance := range reservation.Instances {
+			if aws.StringValue(instance.InstanceId) == instanceID {
+				return instance, nil
+			}
+		}
+	}
+
+	return nil, NewNotFoundError(ec2InstanceObjectType, instanceID, region)
+}
+
 // NewEc2Client creates an EC2 client.
 func NewEc2Client(t testing.TestingT, region string) *ec2.EC2 {
 	client, err := NewEc2ClientE(t, region)
@@ -200,3 +200,23 @@ func offerings(offerings map[string][]string) []*ec2.InstanceTypeOffering {
 
 	return out
 }
+
+func TestPrimaryIpv6OfInstance(t *testing.T) {
+	t.Parallel()
+
+	instance := &ec2.Instance{NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+		{
+			Attachment:    &ec2.InstanceNetworkInterfaceAttachment{DeviceIndex: aws.Int64(1)},
+			Ipv6Addresses: []*ec2.InstanceIpv6Address{{Ipv6Address: aws.String("2600:1f18::2")}},
+		},
+		{
+			Attachment:    &ec2.InstanceNetworkInterfaceAttachment{DeviceIndex: aws.Int64(0)},
+			Ipv6Addresses: []*ec2.InstanceIpv6Address{{Ipv6Address: aws.String("2600:1f18::1")}, {Ipv6Address: aws.String("2600:1f18::3")}},
+		},
+	}}
+	assert.Equal(t, "2600:1f18::1", primaryIpv6OfInstance(instance))
+
+	assert.Equal(t, "", primaryIpv6OfInstance(&ec2.Instance{NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+		{Attachment: &ec2.InstanceNetworkInterfaceAttachment{DeviceIndex: aws.Int64(0)}},
+	}}))
+}
@@ -0,0 +1,134 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/gruntwork-io/terratest/modules/imagescan"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ecrImageScanMaxRetries          = 60
+	ecrImageScanSleepBetweenRetries = 10 * time.Second
+)
+
+// GetECRImageScanFindings waits for the basic scan of the given image (a tag or a "sha256:" digest) in the given ECR
+// repository to complete, and returns the vulnerabilities it found. The scan must have been started, either by scan on
+// push or by StartImageScan. This will fail the test if there is an error.
+func GetECRImageScanFindings(t testing.TestingT, region string, repoName string, image string) []imagescan.Vulnerability {
+	vulnerabilities, err := GetECRImageScanFindingsE(t, region, repoName, image)
+	require.NoError(t, err)
+	return vulnerabilities
+}
+
+// GetECRImageScanFindingsE waits for the basic scan of the given image (a tag or a "sha256:" digest) in the given ECR
+// repository to complete, and returns the vulnerabilities it found. The scan must have been started, either by scan on
+// push or by StartImageScan. Returns an imagescan.ScanNotComplete error if the scan failed.
+func GetECRImageScanFindingsE(t testing.TestingT, region string, repoName string, image string) ([]imagescan.Vulnerability, error) {
+	client, err := NewECRClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	imageName := ecrImageName(repoName, image)
+	input := &ecr.DescribeImageScanFindingsInput{
+		RepositoryName: aws.String(repoName),
+		ImageId:        newECRImageIdentifier(image),
+	}
+
+	description := fmt.Sprintf("Waiting for the scan of ECR image %s to complete", imageName)
+	_, err = retry.DoWithRetryE(t, description, ecrImageScanMaxRetries, ecrImageScanSleepBetweenRetries, func() (string, error) {
+		output, err := client.DescribeImageScanFindings(input)
+		if err != nil {
+			// Scan on push starts the scan shortly after the push, so it may not exist yet
+			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecr.ErrCodeScanNotFoundException {
+				return "", err
+			}
+			return "", retry.FatalError{Underlying: err}
+		}
+
+		switch status := aws.StringValue(output.ImageScanStatus.Status); status {
+		case ecr.ScanStatusComplete:
+			return status, nil
+		case ecr.ScanStatusFailed:
+			return "", retry.FatalError{Underlying: imagescan.ScanNotComplete{
+				Image:  imageName,
+				Status: status,
+				Reason: aws.StringValue(output.ImageScanStatus.Description),
+			}}
+		default:
+			return "", fmt.Errorf("Scan of ECR image %s is in state %s", imageName, status)
+		}
+	})
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return nil, actualErr.Underlying
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vulnerabilities := []imagescan.Vulnerability{}
+	err = client.DescribeImageScanFindingsPages(input, func(page *ecr.DescribeImageScanFindingsOutput, lastPage bool) bool {
+		if page.ImageScanFindings != nil {
+			vulnerabilities = append(vulnerabilities, newVulnerabilitiesFromECRFindings(page.ImageScanFindings.Findings)...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vulnerabilities, nil
+}
+
+// AssertNoECRImageVulnerabilities waits for the scan of the given image (a tag or a "sha256:" digest) in the given ECR
+// repository to complete, and fails the test if it found any vulnerability that violates the given policy.
+func AssertNoECRImageVulnerabilities(t testing.TestingT, region string, repoName string, image string, policy imagescan.Policy) {
+	vulnerabilities := GetECRImageScanFindings(t, region, repoName, image)
+	imagescan.AssertNoVulnerabilities(t, ecrImageName(repoName, image), vulnerabilities, policy)
+}
+
+// newECRImageIdentifier returns the identifier of the image with the given tag or "sha256:" digest.
+func newECRImageIdentifier(image string) *ecr.ImageIdentifier {
+	if strings.HasPrefix(image, "sha256:") {
+		return &ecr.ImageIdentifier{ImageDigest: aws.String(image)}
+	}
+	return &ecr.ImageIdentifier{ImageTag: aws.String(image)}
+}
+
+// ecrImageName returns the name of the image with the given tag or "sha256:" digest in the given repository.
+func ecrImageName(repoName string, image string) string {
+	if strings.HasPrefix(image, "sha256:") {
+		return repoName + "@" + image
+	}
+	return repoName + ":" + image
+}
+
+// newVulnerabilitiesFromECRFindings converts the findings of an ECR basic scan into vulnerabilities. ECR reports the
+// affected package in the package_name and package_version attributes of each finding.
+func newVulnerabilitiesFromECRFindings(findings []*ecr.ImageScanFinding) []imagescan.Vulnerability {
+	vulnerabilities := []imagescan.Vulnerability{}
+	for _, finding := range findings {
+		vulnerability := imagescan.Vulnerability{
+			ID:       aws.StringValue(finding.Name),
+			Severity: imagescan.ParseSeverity(aws.StringValue(finding.Severity)),
+		}
+		for _, attribute := range finding.Attributes {
+			switch aws.StringValue(attribute.Key) {
+			case "package_name":
+				vulnerability.Package = aws.StringValue(attribute.Value)
+			case "package_version":
+				vulnerability.InstalledVersion = aws.StringValue(attribute.Value)
+			}
+		}
+		vulnerabilities = append(vulnerabilities, vulnerability)
+	}
+	return vulnerabilities
+}
@@ -0,0 +1,39 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/gruntwork-io/terratest/modules/imagescan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVulnerabilitiesFromECRFindings(t *testing.T) {
+	t.Parallel()
+
+	findings := []*ecr.ImageScanFinding{
+		{
+			Name:     aws.String("CVE-2021-3711"),
+			Severity: aws.String(ecr.FindingSeverityCritical),
+			Attributes: []*ecr.Attribute{
+				{Key: aws.String("package_version"), Value: aws.String("1.1.1d-0+deb10u6")},
+				{Key: aws.String("package_name"), Value: aws.String("openssl")},
+				{Key: aws.String("CVSS2_SCORE"), Value: aws.String("7.5")},
+			},
+		},
+		{Name: aws.String("CVE-2019-0001"), Severity: aws.String(ecr.FindingSeverityInformational)},
+	}
+
+	assert.Equal(t, []imagescan.Vulnerability{
+		{ID: "CVE-2021-3711", Severity: imagescan.SeverityCritical, Package: "openssl", InstalledVersion: "1.1.1d-0+deb10u6"},
+		{ID: "CVE-2019-0001", Severity: imagescan.SeverityUnknown},
+	}, newVulnerabilitiesFromECRFindings(findings))
+}
+
+func TestNewECRImageIdentifier(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "v1.0", aws.StringValue(newECRImageIdentifier("v1.0").ImageTag))
+	assert.Equal(t, "sha256:abc", aws.StringValue(newECRImageIdentifier("sha256:abc").ImageDigest))
+}
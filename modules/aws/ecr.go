@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -59,6 +60,10 @@ func DeleteECRRepo(t testing.TestingT, region string, repo *ecr.Repository) {
 
 // DeleteECRRepoE will force delete the ECR repo by deleting all images prior to deleting the ECR repository.
 func DeleteECRRepoE(t testing.TestingT, region string, repo *ecr.Repository) error {
+	if dryrun.Skip(t, "delete ECR repo %s in %s", aws.StringValue(repo.RepositoryName), region) {
+		return nil
+	}
+
 	client := NewECRClient(t, region)
 	resp, err := client.ListImages(&ecr.ListImagesInput{RepositoryName: repo.RepositoryName})
 	if err != nil {
@@ -2,9 +2,14 @@ package aws
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -92,6 +97,10 @@ func DeleteEcsCluster(t testing.TestingT, region string, cluster *ecs.Cluster) {
 
 // DeleteEcsClusterE deletes existing ECS cluster in the given region.
 func DeleteEcsClusterE(t testing.TestingT, region string, cluster *ecs.Cluster) error {
+	if dryrun.Skip(t, "delete ECS cluster %s in %s", aws.StringValue(cluster.ClusterName), region) {
+		return nil
+	}
+
 	client := NewEcsClient(t, region)
 	_, err := client.DeleteCluster(&ecs.DeleteClusterInput{
 		Cluster: aws.String(*cluster.ClusterName),
@@ -145,6 +154,211 @@ func GetEcsTaskDefinitionE(t testing.TestingT, region string, taskDefinition str
 	return output.TaskDefinition, nil
 }
 
+// WaitForEcsServiceStable waits until the given ECS service is stable, i.e. it has a single deployment with as many
+// running tasks as desired. This will fail the test if it isn't in time.
+func WaitForEcsServiceStable(t testing.TestingT, region string, clusterName string, serviceName string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForEcsServiceStableE(t, region, clusterName, serviceName, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForEcsServiceStableE waits until the given ECS service is stable, i.e. it has a single deployment, whose rollout
+// is complete, with as many running tasks as desired, which is when the tasks of the previous deployments are drained.
+// Returns an EcsDeploymentFailed error early if the deployment circuit breaker fails the rollout, and a WaitTimedOut
+// error if the service isn't stable in time.
+func WaitForEcsServiceStableE(t testing.TestingT, region string, clusterName string, serviceName string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("ECS service %s in cluster %s", serviceName, clusterName),
+		DesiredState: "stable",
+		Extract: func() (WaitState, error) {
+			service, err := GetEcsServiceE(t, region, clusterName, serviceName)
+			if err != nil {
+				return WaitState{}, err
+			}
+			return ecsServiceWaitState(service)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// ecsServiceWaitState returns the state of the given ECS service for WaitForEcsServiceStableE. Returns a
+// retry.FatalError if the rollout of its primary deployment failed.
+func ecsServiceWaitState(service *ecs.Service) (WaitState, error) {
+	var primary *ecs.Deployment
+	for _, deployment := range service.Deployments {
+		if aws.StringValue(deployment.Status) == "PRIMARY" {
+			primary = deployment
+		}
+	}
+	if primary == nil {
+		return WaitState{Current: "no primary deployment"}, nil
+	}
+
+	if aws.StringValue(primary.RolloutState) == ecs.DeploymentRolloutStateFailed {
+		return WaitState{}, retry.FatalError{Underlying: EcsDeploymentFailed{
+			ServiceName:  aws.StringValue(service.ServiceName),
+			DeploymentId: aws.StringValue(primary.Id),
+			Reason:       aws.StringValue(primary.RolloutStateReason),
+		}}
+	}
+
+	running := aws.Int64Value(primary.RunningCount)
+	desired := aws.Int64Value(primary.DesiredCount)
+	// Services without the deployment circuit breaker don't report a rollout state
+	rolledOut := primary.RolloutState == nil || aws.StringValue(primary.RolloutState) == ecs.DeploymentRolloutStateCompleted
+	return WaitState{
+		Current: fmt.Sprintf("%d deployments, %d/%d tasks running", len(service.Deployments), running, desired),
+		Done:    len(service.Deployments) == 1 && running == desired && rolledOut,
+	}, nil
+}
+
+// RunEcsTaskAndWait runs a single ECS task with the given input, waits until it stops and returns it. This will fail
+// the test if there is an error or any of its containers fail.
+func RunEcsTaskAndWait(t testing.TestingT, region string, input *ecs.RunTaskInput, maxRetries int, sleepBetweenRetries time.Duration) *ecs.Task {
+	task, err := RunEcsTaskAndWaitE(t, region, input, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return task
+}
+
+// RunEcsTaskAndWaitE runs a single ECS task with the given input, e.g. a database migration or a smoke test on
+// Fargate, waits until it stops and returns it. Returns an EcsTaskFailed error if any of its containers did not exit
+// with status 0, e.g. because its image could not be pulled. The task is returned in that case too, so that its logs
+// can be fetched with FetchEcsTaskLogsE to see why it failed.
+func RunEcsTaskAndWaitE(t testing.TestingT, region string, input *ecs.RunTaskInput, maxRetries int, sleepBetweenRetries time.Duration) (*ecs.Task, error) {
+	client, err := NewEcsClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Running ECS task %s in cluster %s", aws.StringValue(input.TaskDefinition), aws.StringValue(input.Cluster))
+	output, err := client.RunTask(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Failures) > 0 {
+		failure := output.Failures[0]
+		return nil, fmt.Errorf("Failed to run ECS task %s: %s: %s", aws.StringValue(input.TaskDefinition), aws.StringValue(failure.Reason), aws.StringValue(failure.Detail))
+	}
+	if len(output.Tasks) != 1 {
+		return nil, fmt.Errorf("Expected RunTask to start 1 ECS task, but it started %d", len(output.Tasks))
+	}
+
+	task := output.Tasks[0]
+	taskArn := aws.StringValue(task.TaskArn)
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("ECS task %s", taskArn),
+		DesiredState: ecs.DesiredStatusStopped,
+		Extract: func() (WaitState, error) {
+			described, err := client.DescribeTasks(&ecs.DescribeTasksInput{Cluster: input.Cluster, Tasks: aws.StringSlice([]string{taskArn})})
+			if err != nil {
+				return WaitState{}, err
+			}
+			if len(described.Tasks) != 1 {
+				return WaitState{}, fmt.Errorf("Expected to find 1 ECS task %s, but found %d", taskArn, len(described.Tasks))
+			}
+
+			task = described.Tasks[0]
+			status := aws.StringValue(task.LastStatus)
+			return WaitState{Current: status, Done: status == ecs.DesiredStatusStopped}, nil
+		},
+	}
+
+	if err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries); err != nil {
+		return task, err
+	}
+	return task, checkEcsTaskSucceeded(task)
+}
+
+// checkEcsTaskSucceeded returns an EcsTaskFailed error if any of the containers of the given stopped ECS task did not
+// exit with status 0.
+func checkEcsTaskSucceeded(task *ecs.Task) error {
+	failed := []string{}
+	for _, container := range task.Containers {
+		name := aws.StringValue(container.Name)
+		switch {
+		case container.ExitCode == nil:
+			failed = append(failed, fmt.Sprintf("%s did not run: %s", name, aws.StringValue(container.Reason)))
+		case aws.Int64Value(container.ExitCode) != 0:
+			failed = append(failed, fmt.Sprintf("%s exited with status %d", name, aws.Int64Value(container.ExitCode)))
+		}
+	}
+
+	if len(failed) > 0 {
+		return EcsTaskFailed{
+			TaskArn:       aws.StringValue(task.TaskArn),
+			StoppedReason: aws.StringValue(task.StoppedReason),
+			Containers:    failed,
+		}
+	}
+	return nil
+}
+
+// FetchEcsTaskLogs returns the CloudWatch log messages of the containers of the given ECS task, by container name.
+// This will fail the test if there is an error.
+func FetchEcsTaskLogs(t testing.TestingT, region string, task *ecs.Task) map[string][]string {
+	logs, err := FetchEcsTaskLogsE(t, region, task)
+	require.NoError(t, err)
+	return logs
+}
+
+// FetchEcsTaskLogsE returns the CloudWatch log messages of the containers of the given ECS task, by container name.
+// Only the containers that log with the awslogs driver and an awslogs-stream-prefix, as Fargate requires, are
+// included, as the log streams of the others can't be told apart.
+func FetchEcsTaskLogsE(t testing.TestingT, region string, task *ecs.Task) (map[string][]string, error) {
+	taskDefinition, err := GetEcsTaskDefinitionE(t, region, aws.StringValue(task.TaskDefinitionArn))
+	if err != nil {
+		return nil, err
+	}
+
+	logs := map[string][]string{}
+	for containerName, stream := range ecsTaskLogStreams(taskDefinition, task, region) {
+		entries, err := GetCloudWatchLogEntriesE(t, stream.region, stream.name, stream.group)
+		if err != nil {
+			return nil, err
+		}
+		logs[containerName] = entries
+	}
+	return logs, nil
+}
+
+// ecsLogStream is the CloudWatch log stream a container of an ECS task logs to.
+type ecsLogStream struct {
+	region string
+	group  string
+	name   string
+}
+
+// ecsTaskLogStreams returns the CloudWatch log streams of the containers of the given ECS task, by container name. The
+// awslogs driver names them <awslogs-stream-prefix>/<container name>/<task ID>.
+func ecsTaskLogStreams(taskDefinition *ecs.TaskDefinition, task *ecs.Task, region string) map[string]ecsLogStream {
+	taskArn := aws.StringValue(task.TaskArn)
+	taskId := taskArn[strings.LastIndex(taskArn, "/")+1:]
+
+	streams := map[string]ecsLogStream{}
+	for _, container := range taskDefinition.ContainerDefinitions {
+		logConfiguration := container.LogConfiguration
+		if logConfiguration == nil || aws.StringValue(logConfiguration.LogDriver) != ecs.LogDriverAwslogs {
+			continue
+		}
+
+		options := aws.StringValueMap(logConfiguration.Options)
+		if options["awslogs-group"] == "" || options["awslogs-stream-prefix"] == "" {
+			continue
+		}
+
+		streamRegion := region
+		if options["awslogs-region"] != "" {
+			streamRegion = options["awslogs-region"]
+		}
+		containerName := aws.StringValue(container.Name)
+		streams[containerName] = ecsLogStream{
+			region: streamRegion,
+			group:  options["awslogs-group"],
+			name:   fmt.Sprintf("%s/%s/%s", options["awslogs-stream-prefix"], containerName, taskId),
+		}
+	}
+	return streams
+}
+
 // NewEcsClient creates en ECS client.
 func NewEcsClient(t testing.TestingT, region string) *ecs.ECS {
 	client, err := NewEcsClientE(t, region)
@@ -1,12 +1,15 @@
 package aws
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEcsCluster(t *testing.T) {
@@ -60,3 +63,70 @@ func TestEcsClusterWithInclude(t *testing.T) {
 	assert.NotEmpty(t, c3.Statistics)
 	assert.Empty(t, c3.Tags)
 }
+
+func TestEcsServiceWaitState(t *testing.T) {
+	t.Parallel()
+
+	deployment := func(status string, rolloutState *string, running int64) *ecs.Deployment {
+		return &ecs.Deployment{Id: aws.String("ecs-svc/1"), Status: aws.String(status), RolloutState: rolloutState, RunningCount: aws.Int64(running), DesiredCount: aws.Int64(2)}
+	}
+
+	state, err := ecsServiceWaitState(&ecs.Service{Deployments: []*ecs.Deployment{deployment("PRIMARY", nil, 2), deployment("ACTIVE", nil, 1)}})
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "2 deployments, 2/2 tasks running"}, state)
+
+	state, err = ecsServiceWaitState(&ecs.Service{Deployments: []*ecs.Deployment{deployment("PRIMARY", aws.String(ecs.DeploymentRolloutStateInProgress), 2)}})
+	require.NoError(t, err)
+	assert.False(t, state.Done)
+
+	state, err = ecsServiceWaitState(&ecs.Service{Deployments: []*ecs.Deployment{deployment("PRIMARY", aws.String(ecs.DeploymentRolloutStateCompleted), 2)}})
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "1 deployments, 2/2 tasks running", Done: true}, state)
+
+	failed := deployment("PRIMARY", aws.String(ecs.DeploymentRolloutStateFailed), 0)
+	failed.RolloutStateReason = aws.String("ECS deployment circuit breaker: tasks failed to start.")
+	_, err = ecsServiceWaitState(&ecs.Service{ServiceName: aws.String("app"), Deployments: []*ecs.Deployment{failed}})
+	fatalErr, ok := err.(retry.FatalError)
+	require.True(t, ok)
+	assert.EqualError(t, fatalErr.Underlying, "Deployment ecs-svc/1 of ECS service app failed: ECS deployment circuit breaker: tasks failed to start.")
+	assert.True(t, errors.Is(fatalErr.Underlying, ErrOperationFailed))
+}
+
+func TestCheckEcsTaskSucceeded(t *testing.T) {
+	t.Parallel()
+
+	task := &ecs.Task{
+		TaskArn:       aws.String("arn:aws:ecs:us-east-1:123456789012:task/test/abc123"),
+		StoppedReason: aws.String("Essential container in task exited"),
+		Containers: []*ecs.Container{
+			{Name: aws.String("sidecar"), ExitCode: aws.Int64(0)},
+			{Name: aws.String("app"), ExitCode: aws.Int64(1)},
+			{Name: aws.String("init"), Reason: aws.String("CannotPullContainerError")},
+		},
+	}
+	err := checkEcsTaskSucceeded(task)
+	assert.EqualError(t, err, "ECS task arn:aws:ecs:us-east-1:123456789012:task/test/abc123 failed (Essential container in task exited): app exited with status 1; init did not run: CannotPullContainerError")
+
+	task.Containers = task.Containers[:1]
+	assert.NoError(t, checkEcsTaskSucceeded(task))
+}
+
+func TestEcsTaskLogStreams(t *testing.T) {
+	t.Parallel()
+
+	awslogs := func(options map[string]string) *ecs.LogConfiguration {
+		return &ecs.LogConfiguration{LogDriver: aws.String(ecs.LogDriverAwslogs), Options: aws.StringMap(options)}
+	}
+	taskDefinition := &ecs.TaskDefinition{ContainerDefinitions: []*ecs.ContainerDefinition{
+		{Name: aws.String("app"), LogConfiguration: awslogs(map[string]string{"awslogs-group": "/ecs/app", "awslogs-stream-prefix": "ecs"})},
+		{Name: aws.String("proxy"), LogConfiguration: awslogs(map[string]string{"awslogs-group": "/ecs/proxy", "awslogs-stream-prefix": "proxy", "awslogs-region": "eu-west-1"})},
+		{Name: aws.String("unprefixed"), LogConfiguration: awslogs(map[string]string{"awslogs-group": "/ecs/other"})},
+		{Name: aws.String("splunk"), LogConfiguration: &ecs.LogConfiguration{LogDriver: aws.String(ecs.LogDriverSplunk)}},
+	}}
+	task := &ecs.Task{TaskArn: aws.String("arn:aws:ecs:us-east-1:123456789012:task/test/abc123")}
+
+	assert.Equal(t, map[string]ecsLogStream{
+		"app":   {region: "us-east-1", group: "/ecs/app", name: "ecs/app/abc123"},
+		"proxy": {region: "eu-west-1", group: "/ecs/proxy", name: "proxy/proxy/abc123"},
+	}, ecsTaskLogStreams(taskDefinition, task, "us-east-1"))
+}
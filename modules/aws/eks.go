@@ -0,0 +1,291 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// eksTokenPrefix is the prefix of the bearer tokens that EKS accepts, as generated by aws-iam-authenticator and
+	// aws eks get-token.
+	eksTokenPrefix = "k8s-aws-v1."
+
+	// eksClusterIdHeader is the header of the presigned STS request of a token that binds it to a cluster.
+	eksClusterIdHeader = "x-k8s-aws-id"
+
+	// eksNodeGroupLabel is the label EKS puts on the nodes of a managed node group, with the name of the group as value.
+	eksNodeGroupLabel = "eks.amazonaws.com/nodegroup"
+)
+
+// GetEksCluster returns the EKS cluster with the given name in the given region. This will fail the test if there is
+// an error.
+func GetEksCluster(t testing.TestingT, region string, clusterName string) *eks.Cluster {
+	cluster, err := GetEksClusterE(t, region, clusterName)
+	require.NoError(t, err)
+	return cluster
+}
+
+// GetEksClusterE returns the EKS cluster with the given name in the given region.
+func GetEksClusterE(t testing.TestingT, region string, clusterName string) (*eks.Cluster, error) {
+	client, err := NewEksClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeCluster(&eks.DescribeClusterInput{Name: aws.String(clusterName)})
+	if err != nil {
+		return nil, err
+	}
+	return output.Cluster, nil
+}
+
+// GetEksToken returns a bearer token to authenticate to the Kubernetes API of the EKS cluster with the given name as the
+// current AWS identity. This will fail the test if there is an error.
+func GetEksToken(t testing.TestingT, region string, clusterName string) string {
+	token, err := GetEksTokenE(t, region, clusterName)
+	require.NoError(t, err)
+	return token
+}
+
+// GetEksTokenE returns a bearer token to authenticate to the Kubernetes API of the EKS cluster with the given name as the
+// current AWS identity, the same way aws-iam-authenticator does: a presigned STS GetCallerIdentity request that is bound
+// to the cluster. EKS accepts the token for 15 minutes.
+func GetEksTokenE(t testing.TestingT, region string, clusterName string) (string, error) {
+	client, err := NewStsClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	request, _ := client.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	request.HTTPRequest.Header.Add(eksClusterIdHeader, clusterName)
+	presignedURL, err := request.Presign(60 * time.Second)
+	if err != nil {
+		return "", err
+	}
+	return eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presignedURL)), nil
+}
+
+// WriteKubeConfigForEksCluster adds the EKS cluster with the given name to the kubeconfig at the given path and makes it
+// the current context, like aws eks update-kubeconfig does. Returns the name of the context. This will fail the test if
+// there is an error.
+func WriteKubeConfigForEksCluster(t testing.TestingT, region string, clusterName string, kubeConfigPath string) string {
+	contextName, err := WriteKubeConfigForEksClusterE(t, region, clusterName, kubeConfigPath)
+	require.NoError(t, err)
+	return contextName
+}
+
+// WriteKubeConfigForEksClusterE adds the EKS cluster with the given name to the kubeconfig at the given path, creating
+// it if it does not exist, and makes it the current context, like aws eks update-kubeconfig does. Returns the name of
+// the context, which is the ARN of the cluster. The kubeconfig authenticates with a token from GetEksTokenE rather than
+// by running the AWS CLI, so the CLI does not need to be installed, but the token expires after 15 minutes; call this
+// again to refresh it in longer tests.
+func WriteKubeConfigForEksClusterE(t testing.TestingT, region string, clusterName string, kubeConfigPath string) (string, error) {
+	cluster, err := GetEksClusterE(t, region, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := GetEksTokenE(t, region, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	config := api.NewConfig()
+	if _, err := os.Stat(kubeConfigPath); err == nil {
+		config, err = clientcmd.LoadFromFile(kubeConfigPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	contextName, err := upsertEksClusterConfig(config, cluster, token)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Writing kubeconfig context %s for EKS cluster %s to %s", contextName, clusterName, kubeConfigPath)
+	return contextName, clientcmd.WriteToFile(*config, kubeConfigPath)
+}
+
+// upsertEksClusterConfig adds the given EKS cluster, a user with the given token and a context binding them, all named
+// after the ARN of the cluster, to the given kubeconfig, and makes the context the current one. Returns the name of the
+// context.
+func upsertEksClusterConfig(config *api.Config, cluster *eks.Cluster, token string) (string, error) {
+	caData, err := eksClusterCaData(cluster)
+	if err != nil {
+		return "", err
+	}
+
+	name := aws.StringValue(cluster.Arn)
+	config.Clusters[name] = &api.Cluster{Server: aws.StringValue(cluster.Endpoint), CertificateAuthorityData: caData}
+	config.AuthInfos[name] = &api.AuthInfo{Token: token}
+	config.Contexts[name] = &api.Context{Cluster: name, AuthInfo: name}
+	config.CurrentContext = name
+	return name, nil
+}
+
+// eksClusterCaData returns the decoded certificate authority data of the given EKS cluster.
+func eksClusterCaData(cluster *eks.Cluster) ([]byte, error) {
+	if cluster.CertificateAuthority == nil || aws.StringValue(cluster.CertificateAuthority.Data) == "" {
+		return nil, fmt.Errorf("EKS cluster %s has no certificate authority yet; is it still being created?", aws.StringValue(cluster.Name))
+	}
+	return base64.StdEncoding.DecodeString(aws.StringValue(cluster.CertificateAuthority.Data))
+}
+
+// WaitForEksNodeGroupReady waits until the managed node group with the given name of the given EKS cluster is ACTIVE
+// and as many of its nodes as it desires have joined the cluster and are Ready. This will fail the test if they don't
+// in time.
+func WaitForEksNodeGroupReady(t testing.TestingT, region string, clusterName string, nodeGroupName string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForEksNodeGroupReadyE(t, region, clusterName, nodeGroupName, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForEksNodeGroupReadyE waits until the managed node group with the given name of the given EKS cluster is ACTIVE
+// and as many of its nodes as it desires have joined the cluster and are Ready, which is when pods can be scheduled on
+// it. The nodes are looked up in the Kubernetes API of the cluster as the current AWS identity, which must be mapped
+// to a Kubernetes user that can list nodes. Returns an EksNodeGroupFailed error early if the node group fails to be
+// created or is being deleted, and a WaitTimedOut error if it isn't ready in time.
+func WaitForEksNodeGroupReadyE(t testing.TestingT, region string, clusterName string, nodeGroupName string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	client, err := NewEksClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("EKS node group %s of cluster %s", nodeGroupName, clusterName),
+		DesiredState: "ACTIVE with all desired nodes Ready",
+		Extract: func() (WaitState, error) {
+			output, err := client.DescribeNodegroup(&eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(nodeGroupName)})
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			nodeGroup := output.Nodegroup
+			if state, checkNodes, err := eksNodeGroupWaitState(clusterName, nodeGroup); !checkNodes || err != nil {
+				return state, err
+			}
+
+			// The token of the clientset expires, so create a new one for each check
+			clientset, err := newEksClientsetE(t, region, clusterName)
+			if err != nil {
+				return WaitState{}, err
+			}
+			nodes, err := clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", eksNodeGroupLabel, nodeGroupName)})
+			if err != nil {
+				return WaitState{}, err
+			}
+			return eksNodesWaitState(nodes.Items, eksNodeGroupDesiredSize(nodeGroup)), nil
+		},
+	}
+
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// eksNodeGroupWaitState returns the state of the given node group for WaitForEksNodeGroupReadyE, and whether it is far
+// enough along for its nodes to be checked. Returns a retry.FatalError if the node group won't become ACTIVE anymore.
+func eksNodeGroupWaitState(clusterName string, nodeGroup *eks.Nodegroup) (WaitState, bool, error) {
+	status := aws.StringValue(nodeGroup.Status)
+	switch status {
+	case eks.NodegroupStatusActive:
+		return WaitState{}, true, nil
+	case eks.NodegroupStatusCreateFailed, eks.NodegroupStatusDeleting, eks.NodegroupStatusDeleteFailed:
+		issues := []string{}
+		if nodeGroup.Health != nil {
+			for _, issue := range nodeGroup.Health.Issues {
+				issues = append(issues, fmt.Sprintf("%s: %s", aws.StringValue(issue.Code), aws.StringValue(issue.Message)))
+			}
+		}
+		return WaitState{}, false, retry.FatalError{Underlying: EksNodeGroupFailed{
+			ClusterName:   clusterName,
+			NodeGroupName: aws.StringValue(nodeGroup.NodegroupName),
+			Status:        status,
+			Issues:        issues,
+		}}
+	default:
+		return WaitState{Current: status}, false, nil
+	}
+}
+
+// eksNodeGroupDesiredSize returns the number of nodes the given node group desires.
+func eksNodeGroupDesiredSize(nodeGroup *eks.Nodegroup) int {
+	if nodeGroup.ScalingConfig == nil {
+		return 0
+	}
+	return int(aws.Int64Value(nodeGroup.ScalingConfig.DesiredSize))
+}
+
+// eksNodesWaitState returns the state of the given nodes for WaitForEksNodeGroupReadyE, which is done once at least the
+// given number of them are Ready.
+func eksNodesWaitState(nodes []corev1.Node, desiredSize int) WaitState {
+	ready := 0
+	for _, node := range nodes {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				ready++
+			}
+		}
+	}
+	return WaitState{
+		Current: fmt.Sprintf("%d/%d nodes Ready", ready, desiredSize),
+		Done:    ready >= desiredSize,
+	}
+}
+
+// newEksClientsetE returns a Kubernetes client for the EKS cluster with the given name that authenticates as the current
+// AWS identity.
+func newEksClientsetE(t testing.TestingT, region string, clusterName string) (*kubernetes.Clientset, error) {
+	cluster, err := GetEksClusterE(t, region, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	caData, err := eksClusterCaData(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := GetEksTokenE(t, region, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(&restclient.Config{
+		Host:            aws.StringValue(cluster.Endpoint),
+		BearerToken:     token,
+		TLSClientConfig: restclient.TLSClientConfig{CAData: caData},
+	})
+}
+
+// NewEksClient creates an EKS client.
+func NewEksClient(t testing.TestingT, region string) *eks.EKS {
+	client, err := NewEksClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewEksClientE creates an EKS client.
+func NewEksClientE(t testing.TestingT, region string) (*eks.EKS, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return eks.New(sess), nil
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestUpsertEksClusterConfig(t *testing.T) {
+	t.Parallel()
+
+	cluster := &eks.Cluster{
+		Name:                 aws.String("test"),
+		Arn:                  aws.String("arn:aws:eks:us-east-1:123456789012:cluster/test"),
+		Endpoint:             aws.String("https://ABCDEF.gr7.us-east-1.eks.amazonaws.com"),
+		CertificateAuthority: &eks.Certificate{Data: aws.String(base64.StdEncoding.EncodeToString([]byte("ca")))},
+	}
+
+	config := api.NewConfig()
+	config.Contexts["other"] = &api.Context{Cluster: "other", AuthInfo: "other"}
+	contextName, err := upsertEksClusterConfig(config, cluster, "k8s-aws-v1.token")
+	require.NoError(t, err)
+
+	assert.Equal(t, "arn:aws:eks:us-east-1:123456789012:cluster/test", contextName)
+	assert.Equal(t, contextName, config.CurrentContext)
+	assert.Contains(t, config.Contexts, "other")
+	assert.Equal(t, "https://ABCDEF.gr7.us-east-1.eks.amazonaws.com", config.Clusters[contextName].Server)
+	assert.Equal(t, []byte("ca"), config.Clusters[contextName].CertificateAuthorityData)
+	assert.Equal(t, "k8s-aws-v1.token", config.AuthInfos[contextName].Token)
+
+	_, err = upsertEksClusterConfig(api.NewConfig(), &eks.Cluster{Name: aws.String("creating")}, "k8s-aws-v1.token")
+	assert.EqualError(t, err, "EKS cluster creating has no certificate authority yet; is it still being created?")
+}
+
+func TestEksNodeGroupWaitState(t *testing.T) {
+	t.Parallel()
+
+	_, checkNodes, err := eksNodeGroupWaitState("test", &eks.Nodegroup{Status: aws.String(eks.NodegroupStatusActive)})
+	require.NoError(t, err)
+	assert.True(t, checkNodes)
+
+	state, checkNodes, err := eksNodeGroupWaitState("test", &eks.Nodegroup{Status: aws.String(eks.NodegroupStatusCreating)})
+	require.NoError(t, err)
+	assert.False(t, checkNodes)
+	assert.Equal(t, WaitState{Current: eks.NodegroupStatusCreating}, state)
+
+	_, _, err = eksNodeGroupWaitState("test", &eks.Nodegroup{
+		NodegroupName: aws.String("workers"),
+		Status:        aws.String(eks.NodegroupStatusCreateFailed),
+		Health: &eks.NodegroupHealth{Issues: []*eks.Issue{
+			{Code: aws.String("NodeCreationFailure"), Message: aws.String("Instances failed to join the kubernetes cluster")},
+		}},
+	})
+	fatalErr, ok := err.(retry.FatalError)
+	require.True(t, ok)
+	assert.EqualError(t, fatalErr.Underlying, "EKS node group workers of cluster test is CREATE_FAILED: NodeCreationFailure: Instances failed to join the kubernetes cluster")
+	assert.True(t, errors.Is(fatalErr.Underlying, ErrOperationFailed))
+}
+
+func TestEksNodesWaitState(t *testing.T) {
+	t.Parallel()
+
+	readyNode := corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}}}
+	notReadyNode := corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}}}}
+
+	assert.Equal(t, WaitState{Current: "1/2 nodes Ready"}, eksNodesWaitState([]corev1.Node{readyNode, notReadyNode}, 2))
+	assert.Equal(t, WaitState{Current: "2/2 nodes Ready", Done: true}, eksNodesWaitState([]corev1.Node{readyNode, readyNode}, 2))
+}
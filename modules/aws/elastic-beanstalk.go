@@ -0,0 +1,223 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elasticbeanstalk"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// The namespace of the option settings that hold the environment variables of an Elastic Beanstalk environment.
+const elasticBeanstalkEnvironmentVariablesNamespace = "aws:elasticbeanstalk:application:environment"
+
+// GetElasticBeanstalkEnvironment gets the given Elastic Beanstalk environment of the given application.
+func GetElasticBeanstalkEnvironment(t testing.TestingT, region string, applicationName string, environmentName string) *elasticbeanstalk.EnvironmentDescription {
+	environment, err := GetElasticBeanstalkEnvironmentE(t, region, applicationName, environmentName)
+	require.NoError(t, err)
+	return environment
+}
+
+// GetElasticBeanstalkEnvironmentE gets the given Elastic Beanstalk environment of the given application.
+func GetElasticBeanstalkEnvironmentE(t testing.TestingT, region string, applicationName string, environmentName string) (*elasticbeanstalk.EnvironmentDescription, error) {
+	client, err := NewElasticBeanstalkClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeEnvironments(&elasticbeanstalk.DescribeEnvironmentsInput{
+		ApplicationName:  aws.String(applicationName),
+		EnvironmentNames: aws.StringSlice([]string{environmentName}),
+		IncludeDeleted:   aws.Bool(false),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.Environments) == 0 {
+		return nil, NewNotFoundError("Elastic Beanstalk environment", environmentName, region)
+	}
+	return output.Environments[0], nil
+}
+
+// WaitForElasticBeanstalkEnvironmentHealthy waits until the given Elastic Beanstalk environment is Ready with Green
+// health. This will fail the test if that does not happen within the given number of retries.
+func WaitForElasticBeanstalkEnvironmentHealthy(t testing.TestingT, region string, applicationName string, environmentName string, maxRetries int, sleepBetweenRetries time.Duration) *elasticbeanstalk.EnvironmentDescription {
+	environment, err := WaitForElasticBeanstalkEnvironmentHealthyE(t, region, applicationName, environmentName, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return environment
+}
+
+// WaitForElasticBeanstalkEnvironmentHealthyE waits until the given Elastic Beanstalk environment is Ready with Green
+// health, returning an ElasticBeanstalkEnvironmentTerminated error early if it is terminating instead.
+func WaitForElasticBeanstalkEnvironmentHealthyE(t testing.TestingT, region string, applicationName string, environmentName string, maxRetries int, sleepBetweenRetries time.Duration) (*elasticbeanstalk.EnvironmentDescription, error) {
+	var environment *elasticbeanstalk.EnvironmentDescription
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("Elastic Beanstalk environment %s", environmentName),
+		DesiredState: fmt.Sprintf("%s with %s health", elasticbeanstalk.EnvironmentStatusReady, elasticbeanstalk.EnvironmentHealthGreen),
+		Extract: func() (WaitState, error) {
+			found, err := GetElasticBeanstalkEnvironmentE(t, region, applicationName, environmentName)
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			status := aws.StringValue(found.Status)
+			health := aws.StringValue(found.Health)
+			current := fmt.Sprintf("%s with %s health", status, health)
+			switch {
+			case status == elasticbeanstalk.EnvironmentStatusTerminating || status == elasticbeanstalk.EnvironmentStatusTerminated:
+				return WaitState{}, retry.FatalError{Underlying: ElasticBeanstalkEnvironmentTerminated{EnvironmentName: environmentName, Status: status}}
+			case status == elasticbeanstalk.EnvironmentStatusReady && health == elasticbeanstalk.EnvironmentHealthGreen:
+				environment = found
+				return WaitState{Current: current, Done: true}, nil
+			default:
+				return WaitState{Current: current}, nil
+			}
+		},
+	}
+
+	err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	return environment, err
+}
+
+// GetElasticBeanstalkEnvironmentVariables gets the environment variables configured on the given Elastic Beanstalk
+// environment.
+func GetElasticBeanstalkEnvironmentVariables(t testing.TestingT, region string, applicationName string, environmentName string) map[string]string {
+	variables, err := GetElasticBeanstalkEnvironmentVariablesE(t, region, applicationName, environmentName)
+	require.NoError(t, err)
+	return variables
+}
+
+// GetElasticBeanstalkEnvironmentVariablesE gets the environment variables configured on the given Elastic Beanstalk
+// environment.
+func GetElasticBeanstalkEnvironmentVariablesE(t testing.TestingT, region string, applicationName string, environmentName string) (map[string]string, error) {
+	client, err := NewElasticBeanstalkClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeConfigurationSettings(&elasticbeanstalk.DescribeConfigurationSettingsInput{
+		ApplicationName: aws.String(applicationName),
+		EnvironmentName: aws.String(environmentName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]string{}
+	for _, settings := range output.ConfigurationSettings {
+		for _, option := range settings.OptionSettings {
+			if aws.StringValue(option.Namespace) == elasticBeanstalkEnvironmentVariablesNamespace {
+				variables[aws.StringValue(option.OptionName)] = aws.StringValue(option.Value)
+			}
+		}
+	}
+	return variables, nil
+}
+
+// GetElasticBeanstalkEnvironmentEvents gets up to the given number of the most recent events of the given Elastic
+// Beanstalk environment, newest first, formatted as "<time> <severity> <message>".
+func GetElasticBeanstalkEnvironmentEvents(t testing.TestingT, region string, applicationName string, environmentName string, maxEvents int64) []string {
+	events, err := GetElasticBeanstalkEnvironmentEventsE(t, region, applicationName, environmentName, maxEvents)
+	require.NoError(t, err)
+	return events
+}
+
+// GetElasticBeanstalkEnvironmentEventsE gets up to the given number of the most recent events of the given Elastic
+// Beanstalk environment, newest first, formatted as "<time> <severity> <message>".
+func GetElasticBeanstalkEnvironmentEventsE(t testing.TestingT, region string, applicationName string, environmentName string, maxEvents int64) ([]string, error) {
+	client, err := NewElasticBeanstalkClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeEvents(&elasticbeanstalk.DescribeEventsInput{
+		ApplicationName: aws.String(applicationName),
+		EnvironmentName: aws.String(environmentName),
+		MaxRecords:      aws.Int64(maxEvents),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := []string{}
+	for _, event := range output.Events {
+		events = append(events, fmt.Sprintf("%s %s %s", aws.TimeValue(event.EventDate).Format(time.RFC3339), aws.StringValue(event.Severity), aws.StringValue(event.Message)))
+	}
+	return events, nil
+}
+
+// GetElasticBeanstalkEnvironmentUrl gets the URL of the given Elastic Beanstalk environment, e.g.
+// http://my-env.eba-abcd1234.us-east-1.elasticbeanstalk.com.
+func GetElasticBeanstalkEnvironmentUrl(t testing.TestingT, region string, applicationName string, environmentName string) string {
+	url, err := GetElasticBeanstalkEnvironmentUrlE(t, region, applicationName, environmentName)
+	require.NoError(t, err)
+	return url
+}
+
+// GetElasticBeanstalkEnvironmentUrlE gets the URL of the given Elastic Beanstalk environment, e.g.
+// http://my-env.eba-abcd1234.us-east-1.elasticbeanstalk.com.
+func GetElasticBeanstalkEnvironmentUrlE(t testing.TestingT, region string, applicationName string, environmentName string) (string, error) {
+	environment, err := GetElasticBeanstalkEnvironmentE(t, region, applicationName, environmentName)
+	if err != nil {
+		return "", err
+	}
+	return getElasticBeanstalkEnvironmentUrl(environment)
+}
+
+// ProbeElasticBeanstalkEnvironment sends GET requests for the given path to the URL of the given Elastic Beanstalk
+// environment until it responds with the expected status code, and returns the body of the response. This will fail
+// the test if that does not happen within the given number of retries.
+func ProbeElasticBeanstalkEnvironment(t testing.TestingT, region string, applicationName string, environmentName string, path string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := ProbeElasticBeanstalkEnvironmentE(t, region, applicationName, environmentName, path, expectedStatus, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return body
+}
+
+// ProbeElasticBeanstalkEnvironmentE sends GET requests for the given path to the URL of the given Elastic Beanstalk
+// environment until it responds with the expected status code, and returns the body of the response.
+func ProbeElasticBeanstalkEnvironmentE(t testing.TestingT, region string, applicationName string, environmentName string, path string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	url, err := GetElasticBeanstalkEnvironmentUrlE(t, region, applicationName, environmentName)
+	if err != nil {
+		return "", err
+	}
+	return http_helper.HTTPDoWithRetryE(t, "GET", url+"/"+strings.TrimPrefix(path, "/"), nil, nil, expectedStatus, retries, sleepBetweenRetries, nil)
+}
+
+// NewElasticBeanstalkClient creates an Elastic Beanstalk client.
+func NewElasticBeanstalkClient(t testing.TestingT, region string) *elasticbeanstalk.ElasticBeanstalk {
+	client, err := NewElasticBeanstalkClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewElasticBeanstalkClientE creates an Elastic Beanstalk client.
+func NewElasticBeanstalkClientE(t testing.TestingT, region string) (*elasticbeanstalk.ElasticBeanstalk, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return elasticbeanstalk.New(sess), nil
+}
+
+// getElasticBeanstalkEnvironmentUrl returns the URL of the given environment: its CNAME for load balanced environments,
+// or the endpoint URL (the IP address) of single instance environments.
+func getElasticBeanstalkEnvironmentUrl(environment *elasticbeanstalk.EnvironmentDescription) (string, error) {
+	host := aws.StringValue(environment.CNAME)
+	if host == "" {
+		host = aws.StringValue(environment.EndpointURL)
+	}
+	if host == "" {
+		return "", fmt.Errorf("Elastic Beanstalk environment %s has no URL", aws.StringValue(environment.EnvironmentName))
+	}
+	if strings.Contains(host, "://") {
+		return host, nil
+	}
+	return "http://" + host, nil
+}
@@ -0,0 +1,28 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elasticbeanstalk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetElasticBeanstalkEnvironmentUrl(t *testing.T) {
+	t.Parallel()
+
+	url, err := getElasticBeanstalkEnvironmentUrl(&elasticbeanstalk.EnvironmentDescription{
+		CNAME:       aws.String("my-env.eba-abcd1234.us-east-1.elasticbeanstalk.com"),
+		EndpointURL: aws.String("awseb-e-a-AWSEBLoa-123.us-east-1.elb.amazonaws.com"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://my-env.eba-abcd1234.us-east-1.elasticbeanstalk.com", url)
+
+	url, err = getElasticBeanstalkEnvironmentUrl(&elasticbeanstalk.EnvironmentDescription{EndpointURL: aws.String("203.0.113.7")})
+	require.NoError(t, err)
+	assert.Equal(t, "http://203.0.113.7", url)
+
+	_, err = getElasticBeanstalkEnvironmentUrl(&elasticbeanstalk.EnvironmentDescription{EnvironmentName: aws.String("my-env")})
+	assert.Error(t, err)
+}
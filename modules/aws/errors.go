@@ -1,9 +1,38 @@
 package aws
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/collections"
+)
+
+// The kinds of errors returned by the functions in this package. Every typed error of this package matches the kind it
+// belongs to with errors.Is, so tests and retry logic can branch on the kind of an error rather than on its message,
+// e.g. errors.Is(err, aws.ErrNotFound).
+var (
+	// ErrNotFound is the kind of the errors returned when an expected object does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrInstanceNotFound is the kind of the errors returned when an expected EC2 instance does not exist. These errors
+	// are also of kind ErrNotFound.
+	ErrInstanceNotFound = errors.New("EC2 instance not found")
+
+	// ErrOperationFailed is the kind of the errors returned when a long running operation, such as a CloudFormation
+	// stack update or an SSM command, ends in a failed state.
+	ErrOperationFailed = errors.New("operation failed")
+
+	// ErrTimedOut is the kind of the errors returned when an expected change does not happen before a timeout.
+	ErrTimedOut = errors.New("timed out")
 )
 
+// ec2InstanceObjectType is the object type of the NotFoundError returned for EC2 instances.
+const ec2InstanceObjectType = "EC2 Instance"
+
 // IpForEc2InstanceNotFound is an error that occurs when the IP for an EC2 instance is not found.
 type IpForEc2InstanceNotFound struct {
 	InstanceId string
@@ -15,6 +44,11 @@ func (err IpForEc2InstanceNotFound) Error() string {
 	return fmt.Sprintf("Could not find a %s IP address for EC2 Instance %s in %s", err.Type, err.InstanceId, err.AwsRegion)
 }
 
+// Is returns true if the target is ErrNotFound.
+func (err IpForEc2InstanceNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // HostnameForEc2InstanceNotFound is an error that occurs when the IP for an EC2 instance is not found.
 type HostnameForEc2InstanceNotFound struct {
 	InstanceId string
@@ -26,6 +60,11 @@ func (err HostnameForEc2InstanceNotFound) Error() string {
 	return fmt.Sprintf("Could not find a %s hostname for EC2 Instance %s in %s", err.Type, err.InstanceId, err.AwsRegion)
 }
 
+// Is returns true if the target is ErrNotFound.
+func (err HostnameForEc2InstanceNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // NotFoundError is returned when an expected object is not found
 type NotFoundError struct {
 	objectType string
@@ -37,6 +76,11 @@ func (err NotFoundError) Error() string {
 	return fmt.Sprintf("Object of type %s with id %s not found in region %s", err.objectType, err.objectID, err.region)
 }
 
+// Is returns true if the target is ErrNotFound, or ErrInstanceNotFound for an EC2 instance.
+func (err NotFoundError) Is(target error) bool {
+	return target == ErrNotFound || (target == ErrInstanceNotFound && err.objectType == ec2InstanceObjectType)
+}
+
 func NewNotFoundError(objectType string, objectID string, region string) NotFoundError {
 	return NotFoundError{objectType, objectID, region}
 }
@@ -129,3 +173,695 @@ func (err NoRdsInstanceTypeError) Error() string {
 		err.DatabaseEngineVersion,
 	)
 }
+
+// Ec2InstanceAttributeMismatch is returned when an attribute of an EC2 Instance does not have the expected value.
+type Ec2InstanceAttributeMismatch struct {
+	InstanceId string
+	Attribute  string
+	Expected   string
+	Actual     string
+}
+
+func (err Ec2InstanceAttributeMismatch) Error() string {
+	return fmt.Sprintf("Expected %s of EC2 Instance %s to be %s but got %s", err.Attribute, err.InstanceId, err.Expected, err.Actual)
+}
+
+// ElasticIpAssociationMismatch is returned when an Elastic IP is not associated with the expected EC2 Instance.
+type ElasticIpAssociationMismatch struct {
+	AllocationId       string
+	PublicIp           string
+	ExpectedInstanceId string
+	ActualInstanceId   string // Empty if the Elastic IP is not associated with an EC2 Instance
+}
+
+func (err ElasticIpAssociationMismatch) Error() string {
+	if err.ActualInstanceId == "" {
+		return fmt.Sprintf("Expected Elastic IP %s (%s) to be associated with EC2 Instance %s but it is not associated with any instance", err.AllocationId, err.PublicIp, err.ExpectedInstanceId)
+	}
+	return fmt.Sprintf("Expected Elastic IP %s (%s) to be associated with EC2 Instance %s but it is associated with %s", err.AllocationId, err.PublicIp, err.ExpectedInstanceId, err.ActualInstanceId)
+}
+
+// XraySegmentNotFound is returned when an X-Ray trace has no segment or subsegment with the expected name.
+type XraySegmentNotFound struct {
+	TraceId     string
+	SegmentName string
+	Found       []string // The names of the segments the trace has
+}
+
+func (err XraySegmentNotFound) Error() string {
+	return fmt.Sprintf("X-Ray trace %s has no segment named %s, only %v", err.TraceId, err.SegmentName, err.Found)
+}
+
+// XraySegmentTooSlow is returned when a segment of an X-Ray trace took longer than expected.
+type XraySegmentTooSlow struct {
+	TraceId     string
+	SegmentName string
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+func (err XraySegmentTooSlow) Error() string {
+	return fmt.Sprintf("Segment %s of X-Ray trace %s took %s, more than %s", err.SegmentName, err.TraceId, err.Duration, err.MaxDuration)
+}
+
+// XrayTraceHasErrors is returned when segments of an X-Ray trace are flagged as errors, faults or throttles.
+type XrayTraceHasErrors struct {
+	TraceId  string
+	Segments []string // The flagged segments, with their flags
+}
+
+func (err XrayTraceHasErrors) Error() string {
+	return fmt.Sprintf("X-Ray trace %s has flagged segments: %s", err.TraceId, strings.Join(err.Segments, ", "))
+}
+
+// CapacityReservationUtilizationMismatch is returned when a capacity reservation does not have the expected number of
+// instances running in it.
+type CapacityReservationUtilizationMismatch struct {
+	CapacityReservationId string
+	ExpectedUsed          int64
+	ActualUsed            int64
+	Total                 int64
+}
+
+func (err CapacityReservationUtilizationMismatch) Error() string {
+	return fmt.Sprintf(
+		"Expected %d instances to be running in capacity reservation %s but got %d (total capacity %d)",
+		err.ExpectedUsed,
+		err.CapacityReservationId,
+		err.ActualUsed,
+		err.Total,
+	)
+}
+
+// SsmCommandFailed is returned when an SSM command did not succeed on all the instances it was sent to.
+type SsmCommandFailed struct {
+	CommandId       string
+	DocumentName    string
+	FailedInstances map[string]string // Map of instance ID to the final status of the command on that instance
+}
+
+func (err SsmCommandFailed) Error() string {
+	return fmt.Sprintf("SSM command %s (document %s) did not succeed on all instances: %v", err.CommandId, err.DocumentName, err.FailedInstances)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err SsmCommandFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// MarketplaceSubscriptionRequired is returned when an AMI is a Marketplace product the account is not subscribed to,
+// which otherwise makes RunInstances fail with OptInRequired in the middle of an apply.
+type MarketplaceSubscriptionRequired struct {
+	AmiId        string
+	Region       string
+	ProductCodes []string
+}
+
+func (err MarketplaceSubscriptionRequired) Error() string {
+	urls := []string{}
+	for _, code := range err.ProductCodes {
+		urls = append(urls, fmt.Sprintf("https://aws.amazon.com/marketplace/pp?sku=%s", code))
+	}
+	return fmt.Sprintf(
+		"AMI %s in %s requires a Marketplace subscription the account does not have (product codes %v). Accept the terms of the product with the account running the tests, e.g. at %v",
+		err.AmiId,
+		err.Region,
+		err.ProductCodes,
+		urls,
+	)
+}
+
+// LicenseConfigurationNotAssociated is returned when a resource is not associated with an expected License Manager
+// license configuration.
+type LicenseConfigurationNotAssociated struct {
+	ResourceArn             string
+	LicenseConfigurationArn string
+	AssociatedArns          []string
+}
+
+func (err LicenseConfigurationNotAssociated) Error() string {
+	return fmt.Sprintf(
+		"Expected resource %s to be associated with license configuration %s but it is associated with %v",
+		err.ResourceArn,
+		err.LicenseConfigurationArn,
+		err.AssociatedArns,
+	)
+}
+
+// AthenaQueryFailed is returned when an Athena query does not succeed.
+type AthenaQueryFailed struct {
+	QueryExecutionId string
+	State            string
+	Reason           string
+}
+
+func (err AthenaQueryFailed) Error() string {
+	return fmt.Sprintf("Athena query %s finished with state %s: %s", err.QueryExecutionId, err.State, err.Reason)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err AthenaQueryFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// UnexpectedFlowsFound is returned when the VPC flow logs contain traffic that was expected not to happen.
+type UnexpectedFlowsFound struct {
+	Filter  FlowFilter
+	Records []FlowRecord
+}
+
+func (err UnexpectedFlowsFound) Error() string {
+	return fmt.Sprintf("Expected no flows matching %s but found %d, e.g. %s", err.Filter, len(err.Records), err.Records[0])
+}
+
+// CloudFormationStackFailed is returned when a CloudFormation stack ends up in a failed or rolled back state while
+// waiting for it to complete.
+type CloudFormationStackFailed struct {
+	StackName string
+	Status    string
+	Reason    string
+}
+
+func (err CloudFormationStackFailed) Error() string {
+	return fmt.Sprintf("CloudFormation stack %s is in state %s: %s", err.StackName, err.Status, err.Reason)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err CloudFormationStackFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// CloudFormationStackOutputNotFound is returned when a CloudFormation stack has no output with the given key.
+type CloudFormationStackOutputNotFound struct {
+	StackName string
+	OutputKey string
+}
+
+func (err CloudFormationStackOutputNotFound) Error() string {
+	return fmt.Sprintf("CloudFormation stack %s has no output named %s", err.StackName, err.OutputKey)
+}
+
+// Is returns true if the target is ErrNotFound.
+func (err CloudFormationStackOutputNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ServiceCatalogRecordFailed is returned when a Service Catalog provisioning, update or termination request fails.
+type ServiceCatalogRecordFailed struct {
+	RecordId string
+	Status   string
+	Errors   []string
+}
+
+func (err ServiceCatalogRecordFailed) Error() string {
+	return fmt.Sprintf("Service Catalog record %s finished with status %s: %s", err.RecordId, err.Status, strings.Join(err.Errors, "; "))
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err ServiceCatalogRecordFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// ProtonDeploymentFailed is returned when the deployment or deletion of a Proton environment fails.
+type ProtonDeploymentFailed struct {
+	EnvironmentName string
+	Status          string
+	Message         string
+}
+
+func (err ProtonDeploymentFailed) Error() string {
+	return fmt.Sprintf("Deployment of Proton environment %s finished with status %s: %s", err.EnvironmentName, err.Status, err.Message)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err ProtonDeploymentFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// MultiRegionFailures is returned when a check fails in some of the regions it ran in. It maps each failed region to
+// its error.
+type MultiRegionFailures map[string]error
+
+func (err MultiRegionFailures) Error() string {
+	regions := []string{}
+	for region := range err {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	messages := []string{}
+	for _, region := range regions {
+		messages = append(messages, fmt.Sprintf("%s: %s", region, err[region]))
+	}
+	return fmt.Sprintf("Failed in %d regions:\n%s", len(regions), strings.Join(messages, "\n"))
+}
+
+// Is returns true if the error of any of the failed regions matches the target.
+func (err MultiRegionFailures) Is(target error) bool {
+	for _, regionErr := range err {
+		if errors.Is(regionErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplicationTimedOut is returned when a write does not reach the replica region within the timeout.
+type ReplicationTimedOut struct {
+	Description string
+	Region      string
+	Timeout     time.Duration
+}
+
+func (err ReplicationTimedOut) Error() string {
+	return fmt.Sprintf("%s did not replicate to %s within %s", err.Description, err.Region, err.Timeout)
+}
+
+// Is returns true if the target is ErrTimedOut.
+func (err ReplicationTimedOut) Is(target error) bool {
+	return target == ErrTimedOut
+}
+
+// S3ReplicationFailed is returned when S3 reports that it failed to replicate an object.
+type S3ReplicationFailed struct {
+	Bucket string
+	Key    string
+}
+
+func (err S3ReplicationFailed) Error() string {
+	return fmt.Sprintf("S3 reports that replication of s3://%s/%s failed", err.Bucket, err.Key)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err S3ReplicationFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// ElasticBeanstalkEnvironmentTerminated is returned when an Elastic Beanstalk environment is terminating or terminated
+// while waiting for it to become healthy.
+type ElasticBeanstalkEnvironmentTerminated struct {
+	EnvironmentName string
+	Status          string
+}
+
+func (err ElasticBeanstalkEnvironmentTerminated) Error() string {
+	return fmt.Sprintf("Elastic Beanstalk environment %s is %s", err.EnvironmentName, err.Status)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err ElasticBeanstalkEnvironmentTerminated) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// AppRunnerServiceFailed is returned when an App Runner service reaches a state other than RUNNING once its operation
+// finishes.
+type AppRunnerServiceFailed struct {
+	ServiceArn string
+	Status     string
+}
+
+func (err AppRunnerServiceFailed) Error() string {
+	return fmt.Sprintf("App Runner service %s is in state %s", err.ServiceArn, err.Status)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err AppRunnerServiceFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// FisExperimentFailed is returned when an AWS Fault Injection Simulator experiment ends in any state other than
+// completed, e.g. because one of its stop conditions fired.
+type FisExperimentFailed struct {
+	ExperimentId string
+	Status       string
+	Reason       string
+}
+
+func (err FisExperimentFailed) Error() string {
+	return fmt.Sprintf("FIS experiment %s is in state %s: %s", err.ExperimentId, err.Status, err.Reason)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err FisExperimentFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// CanaryRunFailed is returned when a run of a CloudWatch Synthetics canary fails.
+type CanaryRunFailed struct {
+	CanaryName string
+	RunId      string
+	ReasonCode string // CANARY_FAILURE if the canary script failed, EXECUTION_FAILURE if it could not run
+	Reason     string
+}
+
+func (err CanaryRunFailed) Error() string {
+	return fmt.Sprintf("Run %s of CloudWatch Synthetics canary %s failed (%s): %s", err.RunId, err.CanaryName, err.ReasonCode, err.Reason)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err CanaryRunFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// InstanceNotRunning is returned when an EC2 Instance stops or terminates while waiting for it to pass its status
+// checks.
+type InstanceNotRunning struct {
+	InstanceID string
+	State      string
+}
+
+func (err InstanceNotRunning) Error() string {
+	return fmt.Sprintf("EC2 Instance %s is %s", err.InstanceID, err.State)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err InstanceNotRunning) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// FetchFilesFailed is returned by FetchFilesFromAsgsE for each ASG or instance that files could not be fetched from, so
+// the summary of all the failures says which ASG, instance and directory each one is about.
+type FetchFilesFailed struct {
+	AsgName    string
+	Tags       string // The tags of TagFilters, formatted as name=value pairs, for instances found by their tags
+	InstanceId string // Empty if the instances of the ASG, or with the tags, could not be looked up
+	RemoteDir  string
+	Underlying error
+}
+
+func (err FetchFilesFailed) Error() string {
+	return fmt.Sprintf("%s: %s", err.Item(), err.Underlying)
+}
+
+// Item returns a description of the ASG, instance and directory, if any, the files could not be fetched from.
+func (err FetchFilesFailed) Item() string {
+	if err.AsgName == "" && err.Tags != "" {
+		if err.InstanceId == "" {
+			return fmt.Sprintf("instances tagged %s", err.Tags)
+		}
+		return fmt.Sprintf("instance %s tagged %s, directory %s", err.InstanceId, err.Tags, err.RemoteDir)
+	}
+
+	if err.InstanceId == "" {
+		return fmt.Sprintf("ASG %s", err.AsgName)
+	}
+	if err.RemoteDir == "" {
+		return fmt.Sprintf("ASG %s, instance %s", err.AsgName, err.InstanceId)
+	}
+	return fmt.Sprintf("ASG %s, instance %s, directory %s", err.AsgName, err.InstanceId, err.RemoteDir)
+}
+
+// Unwrap returns the underlying error.
+func (err FetchFilesFailed) Unwrap() error {
+	return err.Underlying
+}
+
+// FetchFilesAbandoned is returned when fetching files from an EC2 Instance is given up on because its context is done,
+// e.g. because the InstanceTimeout of a RemoteFileSpecification expired while an SSH session hung.
+type FetchFilesAbandoned struct {
+	InstanceId string
+	Underlying error // context.DeadlineExceeded or context.Canceled
+}
+
+func (err FetchFilesAbandoned) Error() string {
+	return fmt.Sprintf("Gave up fetching files from EC2 Instance %s: %s", err.InstanceId, err.Underlying)
+}
+
+// Is returns true if the target is ErrTimedOut and the fetch was given up on because of a deadline.
+func (err FetchFilesAbandoned) Is(target error) bool {
+	return target == ErrTimedOut && errors.Is(err.Underlying, context.DeadlineExceeded)
+}
+
+// Unwrap returns the error of the context.
+func (err FetchFilesAbandoned) Unwrap() error {
+	return err.Underlying
+}
+
+// ConnectivityMethodFailed is returned for each connectivity method that failed to connect to an EC2 Instance to fetch
+// files from it.
+type ConnectivityMethodFailed struct {
+	Method     ConnectivityMethod
+	Underlying error
+}
+
+func (err ConnectivityMethodFailed) Error() string {
+	return fmt.Sprintf("via %s: %s", err.Method, err.Underlying)
+}
+
+// Unwrap returns the underlying error.
+func (err ConnectivityMethodFailed) Unwrap() error {
+	return err.Underlying
+}
+
+// BastionNotConfigured is returned when connecting to an EC2 Instance via a bastion host without one being configured.
+type BastionNotConfigured struct{}
+
+func (err BastionNotConfigured) Error() string {
+	return "Cannot connect via a bastion host, as no bastion host is configured"
+}
+
+// UnknownConnectivityMethod is returned when connecting to an EC2 Instance with a connectivity method this package does
+// not know.
+type UnknownConnectivityMethod struct {
+	Method ConnectivityMethod
+}
+
+func (err UnknownConnectivityMethod) Error() string {
+	return fmt.Sprintf("Unknown connectivity method %q", err.Method)
+}
+
+// UnknownAddressMode is returned when connecting to an EC2 Instance with an address mode this package does not know.
+type UnknownAddressMode struct {
+	Mode AddressMode
+}
+
+func (err UnknownAddressMode) Error() string {
+	return fmt.Sprintf("Unknown address mode %q", err.Mode)
+}
+
+// itemError is implemented by the errors that describe what item (e.g. instance or file) they failed for.
+type itemError interface {
+	error
+	Item() string
+	Unwrap() error
+}
+
+// formatMultiError is a multierror.ErrorFormatFunc that renders a summary of the given errors in which identical errors
+// are listed once. Errors that describe the item they failed for are grouped by their underlying error, listing every
+// item it occurred for, so that e.g. the same SSH failure on every instance of an ASG reads as one failure.
+func formatMultiError(errs []error) string {
+	messages := []string{}
+	itemsByMessage := map[string][]string{}
+	for _, err := range errs {
+		message := err.Error()
+		item := ""
+		if withItem, ok := err.(itemError); ok && withItem.Unwrap() != nil {
+			message = withItem.Unwrap().Error()
+			item = withItem.Item()
+		}
+
+		if _, seen := itemsByMessage[message]; !seen {
+			messages = append(messages, message)
+			itemsByMessage[message] = []string{}
+		}
+		if item != "" && !collections.ListContains(itemsByMessage[message], item) {
+			itemsByMessage[message] = append(itemsByMessage[message], item)
+		}
+	}
+
+	lines := []string{fmt.Sprintf("%d errors occurred (%d distinct):", len(errs), len(messages))}
+	for _, message := range messages {
+		lines = append(lines, fmt.Sprintf("  * %s", strings.Replace(message, "\n", "\n    ", -1)))
+		for _, item := range itemsByMessage[message] {
+			lines = append(lines, fmt.Sprintf("      - %s", item))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EksNodeGroupFailed is returned when an EKS managed node group fails to be created or is deleted while waiting for it
+// to be ready.
+type EksNodeGroupFailed struct {
+	ClusterName   string
+	NodeGroupName string
+	Status        string
+	Issues        []string // The health issues of the node group, e.g. "NodeCreationFailure: Instances failed to join"
+}
+
+func (err EksNodeGroupFailed) Error() string {
+	message := fmt.Sprintf("EKS node group %s of cluster %s is %s", err.NodeGroupName, err.ClusterName, err.Status)
+	if len(err.Issues) > 0 {
+		message += ": " + strings.Join(err.Issues, "; ")
+	}
+	return message
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err EksNodeGroupFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// EcsDeploymentFailed is returned when the deployment circuit breaker of an ECS service fails a rollout.
+type EcsDeploymentFailed struct {
+	ServiceName  string
+	DeploymentId string
+	Reason       string
+}
+
+func (err EcsDeploymentFailed) Error() string {
+	return fmt.Sprintf("Deployment %s of ECS service %s failed: %s", err.DeploymentId, err.ServiceName, err.Reason)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err EcsDeploymentFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// EcsTaskFailed is returned when containers of an ECS task did not run or exited with a non-zero status.
+type EcsTaskFailed struct {
+	TaskArn       string
+	StoppedReason string
+	Containers    []string // What went wrong with each failed container, e.g. "app exited with status 1"
+}
+
+func (err EcsTaskFailed) Error() string {
+	return fmt.Sprintf("ECS task %s failed (%s): %s", err.TaskArn, err.StoppedReason, strings.Join(err.Containers, "; "))
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err EcsTaskFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// LambdaFunctionFailed is returned when a Lambda function, or the last update of it, failed.
+type LambdaFunctionFailed struct {
+	FunctionName string
+	State        string
+	Reason       string
+}
+
+func (err LambdaFunctionFailed) Error() string {
+	return fmt.Sprintf("Lambda function %s is in state %s: %s", err.FunctionName, err.State, err.Reason)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err LambdaFunctionFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// DynamoDbItemNotFound is returned when a DynamoDB table has no item with a key.
+type DynamoDbItemNotFound struct {
+	TableName string
+	Key       string
+}
+
+func (err DynamoDbItemNotFound) Error() string {
+	return fmt.Sprintf("No item with key %s found in DynamoDB table %s", err.Key, err.TableName)
+}
+
+// Is returns true if the target is ErrNotFound.
+func (err DynamoDbItemNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// DynamoDbTableUnavailable is returned when a DynamoDB table, or a global secondary index of it, will not become
+// active, e.g. because it is being deleted.
+type DynamoDbTableUnavailable struct {
+	TableName string
+	IndexName string // Set if the index is unavailable rather than the table
+	Status    string
+}
+
+func (err DynamoDbTableUnavailable) Error() string {
+	if err.IndexName != "" {
+		return fmt.Sprintf("Global secondary index %s of DynamoDB table %s is %s", err.IndexName, err.TableName, err.Status)
+	}
+	return fmt.Sprintf("DynamoDB table %s is %s", err.TableName, err.Status)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err DynamoDbTableUnavailable) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// SesIdentityNotFound is returned when an SES identity does not exist.
+type SesIdentityNotFound struct {
+	Identity string
+	Region   string
+}
+
+func (err SesIdentityNotFound) Error() string {
+	return fmt.Sprintf("SES identity %s does not exist in %s", err.Identity, err.Region)
+}
+
+// Is returns true if the target is ErrNotFound.
+func (err SesIdentityNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// SesDkimVerificationFailed is returned when SES gives up on verifying the DKIM records of an identity.
+type SesDkimVerificationFailed struct {
+	Identity string
+	Status   string
+}
+
+func (err SesDkimVerificationFailed) Error() string {
+	return fmt.Sprintf("DKIM verification of SES identity %s is %s", err.Identity, err.Status)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err SesDkimVerificationFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// ClientVpnEndpointNotFound is returned when a Client VPN endpoint does not exist.
+type ClientVpnEndpointNotFound struct {
+	EndpointID string
+	Region     string
+}
+
+func (err ClientVpnEndpointNotFound) Error() string {
+	return fmt.Sprintf("Client VPN endpoint %s does not exist in %s", err.EndpointID, err.Region)
+}
+
+// Is returns true if the target is ErrNotFound.
+func (err ClientVpnEndpointNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ClientVpnEndpointFailed is returned when a Client VPN endpoint, or one of its authorization rules or routes, will not
+// become usable.
+type ClientVpnEndpointFailed struct {
+	EndpointID string
+	Resource   string // What failed, e.g. "endpoint" or "route to 10.0.0.0/16"
+	Status     string
+	Message    string
+}
+
+func (err ClientVpnEndpointFailed) Error() string {
+	message := fmt.Sprintf("The %s of Client VPN endpoint %s is %s", err.Resource, err.EndpointID, err.Status)
+	if err.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, err.Message)
+	}
+	return message
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err ClientVpnEndpointFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+// OpenVpnConnectionFailed is returned when OpenVPN does not connect to a Client VPN endpoint.
+type OpenVpnConnectionFailed struct {
+	Reason string
+	Output []string // The lines OpenVPN logged
+}
+
+func (err OpenVpnConnectionFailed) Error() string {
+	return fmt.Sprintf("OpenVPN did not connect (%s):\n%s", err.Reason, strings.Join(err.Output, "\n"))
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err OpenVpnConnectionFailed) Is(target error) bool {
+	return target == ErrOperationFailed
+}
@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	t.Parallel()
+
+	instanceNotFound := NewNotFoundError(ec2InstanceObjectType, "i-0123456789abcdef0", "us-east-1")
+	assert.True(t, errors.Is(instanceNotFound, ErrNotFound))
+	assert.True(t, errors.Is(instanceNotFound, ErrInstanceNotFound))
+
+	amiNotFound := NewNotFoundError("AMI", "ami-0123456789abcdef0", "us-east-1")
+	assert.True(t, errors.Is(amiNotFound, ErrNotFound))
+	assert.False(t, errors.Is(amiNotFound, ErrInstanceNotFound))
+
+	stackFailed := CloudFormationStackFailed{StackName: "stack", Status: "ROLLBACK_COMPLETE"}
+	assert.True(t, errors.Is(stackFailed, ErrOperationFailed))
+	assert.False(t, errors.Is(stackFailed, ErrNotFound))
+
+	// The kind is preserved through wrapping, e.g. in a FatalError or with %w
+	assert.True(t, errors.Is(retry.FatalError{Underlying: stackFailed}, ErrOperationFailed))
+	assert.True(t, errors.Is(fmt.Errorf("deploying: %w", instanceNotFound), ErrInstanceNotFound))
+
+	var actual CloudFormationStackFailed
+	assert.True(t, errors.As(retry.FatalError{Underlying: stackFailed}, &actual))
+	assert.Equal(t, "ROLLBACK_COMPLETE", actual.Status)
+}
+
+func TestMultiRegionFailuresErrorKinds(t *testing.T) {
+	t.Parallel()
+
+	failures := MultiRegionFailures{
+		"us-east-1": errors.New("boom"),
+		"eu-west-1": ReplicationTimedOut{Description: "item", Timeout: 0},
+	}
+	assert.True(t, errors.Is(failures, ErrTimedOut))
+	assert.False(t, errors.Is(failures, ErrNotFound))
+}
+
+func TestFormatMultiErrorGroupsIdenticalErrors(t *testing.T) {
+	t.Parallel()
+
+	sshErr := errors.New("ssh: unable to authenticate")
+	errorsOccurred := &multierror.Error{ErrorFormat: formatMultiError}
+	errorsOccurred = multierror.Append(errorsOccurred,
+		FetchFilesFailed{AsgName: "asg", InstanceId: "i-1", RemoteDir: "/var/log", Underlying: sshErr},
+		FetchFilesFailed{AsgName: "asg", InstanceId: "i-2", RemoteDir: "/var/log", Underlying: sshErr},
+		FetchFilesFailed{AsgName: "other-asg", Underlying: errors.New("ASG not found")},
+		errors.New("ASG not found"),
+	)
+
+	expected := `4 errors occurred (2 distinct):
+  * ssh: unable to authenticate
+      - ASG asg, instance i-1, directory /var/log
+      - ASG asg, instance i-2, directory /var/log
+  * ASG not found
+      - ASG other-asg`
+	assert.Equal(t, expected, errorsOccurred.Error())
+
+	// The individual errors are still reachable
+	var fetchErr FetchFilesFailed
+	assert.True(t, errors.As(errorsOccurred, &fetchErr))
+	assert.True(t, errors.Is(errorsOccurred, sshErr))
+}
@@ -0,0 +1,260 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/fis"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// FisSpotInterruptionActionId is the ID of the FIS action that sends a spot instance interruption notice to its target
+// instances and interrupts them once the notice period is over.
+const FisSpotInterruptionActionId = "aws:ec2:send-spot-instance-interruptions"
+
+// StartFisExperiment starts an experiment from the given AWS Fault Injection Simulator experiment template, e.g. one
+// that interrupts spot instances or simulates the power loss of an Availability Zone, and returns it without waiting for
+// it to finish. This will fail the test if there is an error.
+func StartFisExperiment(t testing.TestingT, region string, templateId string) *fis.Experiment {
+	experiment, err := StartFisExperimentE(t, region, templateId)
+	require.NoError(t, err)
+	return experiment
+}
+
+// StartFisExperimentE starts an experiment from the given AWS Fault Injection Simulator experiment template, e.g. one
+// that interrupts spot instances or simulates the power loss of an Availability Zone, and returns it without waiting for
+// it to finish. This invalidates the describe cache of the test, if enabled.
+func StartFisExperimentE(t testing.TestingT, region string, templateId string) (*fis.Experiment, error) {
+	client, err := NewFisClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Starting FIS experiment from template %s in %s", templateId, region)
+	output, err := client.StartExperiment(&fis.StartExperimentInput{
+		ClientToken:          aws.String(random.UniqueId()),
+		ExperimentTemplateId: aws.String(templateId),
+		Tags:                 aws.StringMap(map[string]string{"Name": fmt.Sprintf("terratest-%s", templateId)}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Started FIS experiment %s", aws.StringValue(output.Experiment.Id))
+	InvalidateDescribeCache(t)
+	return output.Experiment, nil
+}
+
+// GetFisExperiment gets the AWS Fault Injection Simulator experiment with the given ID. This will fail the test if
+// there is an error.
+func GetFisExperiment(t testing.TestingT, region string, experimentId string) *fis.Experiment {
+	experiment, err := GetFisExperimentE(t, region, experimentId)
+	require.NoError(t, err)
+	return experiment
+}
+
+// GetFisExperimentE gets the AWS Fault Injection Simulator experiment with the given ID.
+func GetFisExperimentE(t testing.TestingT, region string, experimentId string) (*fis.Experiment, error) {
+	client, err := NewFisClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.GetExperiment(&fis.GetExperimentInput{Id: aws.String(experimentId)})
+	if err != nil {
+		return nil, err
+	}
+	return output.Experiment, nil
+}
+
+// WaitForFisExperimentComplete waits until the given AWS Fault Injection Simulator experiment has completed, failing
+// early if it fails or is stopped instead. This will fail the test if there is an error.
+func WaitForFisExperimentComplete(t testing.TestingT, region string, experimentId string, maxRetries int, sleepBetweenRetries time.Duration) *fis.Experiment {
+	experiment, err := WaitForFisExperimentCompleteE(t, region, experimentId, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return experiment
+}
+
+// WaitForFisExperimentCompleteE waits until the given AWS Fault Injection Simulator experiment has completed, returning
+// a FisExperimentFailed error early if it fails or is stopped instead.
+func WaitForFisExperimentCompleteE(t testing.TestingT, region string, experimentId string, maxRetries int, sleepBetweenRetries time.Duration) (*fis.Experiment, error) {
+	var experiment *fis.Experiment
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("FIS experiment %s", experimentId),
+		DesiredState: fis.ExperimentStatusCompleted,
+		Extract: func() (WaitState, error) {
+			found, err := GetFisExperimentE(t, region, experimentId)
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			status := aws.StringValue(found.State.Status)
+			switch status {
+			case fis.ExperimentStatusCompleted:
+				experiment = found
+				return WaitState{Current: status, Done: true}, nil
+			case fis.ExperimentStatusFailed, fis.ExperimentStatusStopped:
+				return WaitState{}, retry.FatalError{Underlying: FisExperimentFailed{
+					ExperimentId: experimentId,
+					Status:       status,
+					Reason:       aws.StringValue(found.State.Reason),
+				}}
+			default:
+				return WaitState{Current: status}, nil
+			}
+		},
+	}
+
+	err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	return experiment, err
+}
+
+// StopFisExperiment stops the given AWS Fault Injection Simulator experiment, which rolls back the actions that support
+// it. This will fail the test if there is an error.
+func StopFisExperiment(t testing.TestingT, region string, experimentId string) {
+	require.NoError(t, StopFisExperimentE(t, region, experimentId))
+}
+
+// StopFisExperimentE stops the given AWS Fault Injection Simulator experiment, which rolls back the actions that support
+// it.
+func StopFisExperimentE(t testing.TestingT, region string, experimentId string) error {
+	if dryrun.Skip(t, "stop FIS experiment %s in %s", experimentId, region) {
+		return nil
+	}
+
+	client, err := NewFisClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Stopping FIS experiment %s", experimentId)
+	_, err = client.StopExperiment(&fis.StopExperimentInput{Id: aws.String(experimentId)})
+	return err
+}
+
+// CreateFisSpotInterruptionTemplate creates an AWS Fault Injection Simulator experiment template that interrupts the
+// given spot instances after sending them a notice the given duration in advance (at least 2 minutes), and returns its
+// ID. The experiment runs as the given IAM role, which needs the ec2:SendSpotInstanceInterruptions permission. This will
+// fail the test if there is an error.
+func CreateFisSpotInterruptionTemplate(t testing.TestingT, region string, roleArn string, instanceIds []string, durationBeforeInterruption time.Duration) string {
+	templateId, err := CreateFisSpotInterruptionTemplateE(t, region, roleArn, instanceIds, durationBeforeInterruption)
+	require.NoError(t, err)
+	return templateId
+}
+
+// CreateFisSpotInterruptionTemplateE creates an AWS Fault Injection Simulator experiment template that interrupts the
+// given spot instances after sending them a notice the given duration in advance (at least 2 minutes), and returns its
+// ID. The experiment runs as the given IAM role, which needs the ec2:SendSpotInstanceInterruptions permission.
+func CreateFisSpotInterruptionTemplateE(t testing.TestingT, region string, roleArn string, instanceIds []string, durationBeforeInterruption time.Duration) (string, error) {
+	client, err := NewFisClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	instanceArns, err := getEc2InstanceArnsE(t, region, instanceIds)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Creating FIS experiment template to interrupt spot instances %v in %s", instanceIds, region)
+	output, err := client.CreateExperimentTemplate(newFisSpotInterruptionTemplateInput(roleArn, instanceArns, durationBeforeInterruption))
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.ExperimentTemplate.Id), nil
+}
+
+// DeleteFisExperimentTemplate deletes the given AWS Fault Injection Simulator experiment template. This will fail the
+// test if there is an error.
+func DeleteFisExperimentTemplate(t testing.TestingT, region string, templateId string) {
+	require.NoError(t, DeleteFisExperimentTemplateE(t, region, templateId))
+}
+
+// DeleteFisExperimentTemplateE deletes the given AWS Fault Injection Simulator experiment template.
+func DeleteFisExperimentTemplateE(t testing.TestingT, region string, templateId string) error {
+	if dryrun.Skip(t, "delete FIS experiment template %s in %s", templateId, region) {
+		return nil
+	}
+
+	client, err := NewFisClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Deleting FIS experiment template %s", templateId)
+	_, err = client.DeleteExperimentTemplate(&fis.DeleteExperimentTemplateInput{Id: aws.String(templateId)})
+	return err
+}
+
+// NewFisClient creates an AWS Fault Injection Simulator client.
+func NewFisClient(t testing.TestingT, region string) *fis.FIS {
+	client, err := NewFisClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewFisClientE creates an AWS Fault Injection Simulator client.
+func NewFisClientE(t testing.TestingT, region string) (*fis.FIS, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return fis.New(sess), nil
+}
+
+// getEc2InstanceArnsE returns the ARNs of the given EC2 instances, which FIS needs to target them.
+func getEc2InstanceArnsE(t testing.TestingT, region string, instanceIds []string) ([]string, error) {
+	accountId, err := GetAccountIdE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	partition := "aws"
+	if found, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		partition = found.ID()
+	}
+
+	arns := []string{}
+	for _, instanceId := range instanceIds {
+		arns = append(arns, fmt.Sprintf("arn:%s:ec2:%s:%s:instance/%s", partition, region, accountId, instanceId))
+	}
+	return arns, nil
+}
+
+// newFisSpotInterruptionTemplateInput returns the input to create an experiment template that interrupts the given spot
+// instances. FIS expects the notice period as an ISO 8601 duration, in whole minutes.
+func newFisSpotInterruptionTemplateInput(roleArn string, instanceArns []string, durationBeforeInterruption time.Duration) *fis.CreateExperimentTemplateInput {
+	return &fis.CreateExperimentTemplateInput{
+		ClientToken: aws.String(random.UniqueId()),
+		Description: aws.String("Terratest spot instance interruption"),
+		RoleArn:     aws.String(roleArn),
+		Actions: map[string]*fis.CreateExperimentTemplateActionInput{
+			"interrupt": {
+				ActionId: aws.String(FisSpotInterruptionActionId),
+				Parameters: aws.StringMap(map[string]string{
+					"durationBeforeInterruption": fmt.Sprintf("PT%dM", int(durationBeforeInterruption.Minutes())),
+				}),
+				Targets: aws.StringMap(map[string]string{"SpotInstances": "instances"}),
+			},
+		},
+		Targets: map[string]*fis.CreateExperimentTemplateTargetInput{
+			"instances": {
+				ResourceType:  aws.String("aws:ec2:spot-instance"),
+				ResourceArns:  aws.StringSlice(instanceArns),
+				SelectionMode: aws.String("ALL"),
+			},
+		},
+		StopConditions: []*fis.CreateExperimentTemplateStopConditionInput{
+			{Source: aws.String("none")},
+		},
+		Tags: aws.StringMap(map[string]string{"Name": "terratest-spot-interruption"}),
+	}
+}
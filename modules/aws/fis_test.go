@@ -0,0 +1,27 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFisSpotInterruptionTemplateInput(t *testing.T) {
+	t.Parallel()
+
+	instanceArn := "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0"
+	input := newFisSpotInterruptionTemplateInput("arn:aws:iam::123456789012:role/fis", []string{instanceArn}, 3*time.Minute)
+	require.NoError(t, input.Validate())
+
+	action := input.Actions["interrupt"]
+	require.NotNil(t, action)
+	assert.Equal(t, FisSpotInterruptionActionId, aws.StringValue(action.ActionId))
+	assert.Equal(t, "PT3M", aws.StringValue(action.Parameters["durationBeforeInterruption"]))
+
+	target := input.Targets[aws.StringValue(action.Targets["SpotInstances"])]
+	require.NotNil(t, target)
+	assert.Equal(t, []string{instanceArn}, aws.StringValueSlice(target.ResourceArns))
+}
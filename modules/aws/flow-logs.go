@@ -0,0 +1,451 @@
+package aws
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// IANA protocol numbers of the protocols most often found in VPC flow logs, for use in FlowFilter.
+const (
+	FlowProtocolICMP = 1
+	FlowProtocolTCP  = 6
+	FlowProtocolUDP  = 17
+)
+
+// The actions recorded in VPC flow logs.
+const (
+	FlowActionAccept = "ACCEPT"
+	FlowActionReject = "REJECT"
+)
+
+// flowLogsQueryLimit is the maximum number of flow log records returned by QueryFlowLogsE.
+const flowLogsQueryLimit = 1000
+
+// flowLogsTableColumns are the columns of the default VPC flow log format (version 2).
+const flowLogsTableColumns = "version int, account_id string, interface_id string, srcaddr string, dstaddr string, srcport int, dstport int, protocol bigint, packets bigint, bytes bigint, start bigint, `end` bigint, action string, log_status string"
+
+var interfaceIDRegex = regexp.MustCompile(`^eni-[0-9a-f]+$`)
+
+// FlowLogsTable is an Athena table over the VPC flow logs delivered to an S3 bucket, as created by
+// CreateFlowLogsTable.
+type FlowLogsTable struct {
+	Region         string
+	Database       string
+	Table          string
+	OutputLocation string // The S3 location Athena writes query results to, e.g. "s3://my-bucket/athena-results/"
+}
+
+// FlowFilter selects the VPC flow log records to look for. Zero values match anything.
+type FlowFilter struct {
+	InterfaceId string
+	SrcAddr     string
+	DstAddr     string
+	SrcPort     int
+	DstPort     int
+	Protocol    int       // The IANA protocol number, e.g. FlowProtocolTCP
+	Action      string    // FlowActionAccept or FlowActionReject
+	Since       time.Time // Only match records of capture windows that started at or after this time
+}
+
+func (filter FlowFilter) String() string {
+	parts := []string{}
+	add := func(name string, value string) {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, value))
+	}
+
+	if filter.InterfaceId != "" {
+		add("interface", filter.InterfaceId)
+	}
+	if filter.SrcAddr != "" {
+		add("src", filter.SrcAddr)
+	}
+	if filter.SrcPort != 0 {
+		add("srcport", strconv.Itoa(filter.SrcPort))
+	}
+	if filter.DstAddr != "" {
+		add("dst", filter.DstAddr)
+	}
+	if filter.DstPort != 0 {
+		add("dstport", strconv.Itoa(filter.DstPort))
+	}
+	if filter.Protocol != 0 {
+		add("protocol", strconv.Itoa(filter.Protocol))
+	}
+	if filter.Action != "" {
+		add("action", filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		add("since", filter.Since.UTC().Format(time.RFC3339))
+	}
+
+	if len(parts) == 0 {
+		return "{any}"
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// FlowRecord is a single VPC flow log record.
+type FlowRecord struct {
+	InterfaceId string
+	SrcAddr     string
+	DstAddr     string
+	SrcPort     int
+	DstPort     int
+	Protocol    int
+	Packets     int64
+	Bytes       int64
+	Start       time.Time
+	End         time.Time
+	Action      string
+}
+
+func (record FlowRecord) String() string {
+	return fmt.Sprintf("%s %s:%d -> %s:%d protocol %d %s (%d packets at %s)", record.InterfaceId, record.SrcAddr, record.SrcPort, record.DstAddr, record.DstPort, record.Protocol, record.Action, record.Packets, record.Start.UTC().Format(time.RFC3339))
+}
+
+// EnableVpcFlowLogs starts capturing the traffic of the given VPC, subnet or network interface (the resource type is
+// taken from the ID prefix) into the given S3 bucket ARN (e.g. "arn:aws:s3:::my-bucket/flow-logs"), using the default
+// log format and a one minute aggregation interval. It returns the ID of the flow log, which should be removed with
+// DeleteVpcFlowLogs at the end of the test.
+func EnableVpcFlowLogs(t testing.TestingT, region string, resourceID string, s3BucketArn string) string {
+	flowLogID, err := EnableVpcFlowLogsE(t, region, resourceID, s3BucketArn)
+	require.NoError(t, err)
+	return flowLogID
+}
+
+// EnableVpcFlowLogsE starts capturing the traffic of the given VPC, subnet or network interface (the resource type is
+// taken from the ID prefix) into the given S3 bucket ARN (e.g. "arn:aws:s3:::my-bucket/flow-logs"), using the default
+// log format and a one minute aggregation interval. It returns the ID of the flow log, which should be removed with
+// DeleteVpcFlowLogsE at the end of the test.
+func EnableVpcFlowLogsE(t testing.TestingT, region string, resourceID string, s3BucketArn string) (string, error) {
+	resourceType, err := getFlowLogsResourceType(resourceID)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Enabling flow logs for %s into %s", resourceID, s3BucketArn)
+	output, err := client.CreateFlowLogs(&ec2.CreateFlowLogsInput{
+		ResourceIds:            aws.StringSlice([]string{resourceID}),
+		ResourceType:           aws.String(resourceType),
+		TrafficType:            aws.String(ec2.TrafficTypeAll),
+		LogDestinationType:     aws.String(ec2.LogDestinationTypeS3),
+		LogDestination:         aws.String(s3BucketArn),
+		MaxAggregationInterval: aws.Int64(60),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(output.Unsuccessful) > 0 {
+		return "", fmt.Errorf("Failed to enable flow logs for %s: %s", resourceID, aws.StringValue(output.Unsuccessful[0].Error.Message))
+	}
+	if len(output.FlowLogIds) != 1 {
+		return "", fmt.Errorf("Expected one flow log to be created for %s but got %d", resourceID, len(output.FlowLogIds))
+	}
+
+	return aws.StringValue(output.FlowLogIds[0]), nil
+}
+
+// DeleteVpcFlowLogs deletes the given flow log. The records already delivered to S3 are kept.
+func DeleteVpcFlowLogs(t testing.TestingT, region string, flowLogID string) {
+	err := DeleteVpcFlowLogsE(t, region, flowLogID)
+	require.NoError(t, err)
+}
+
+// DeleteVpcFlowLogsE deletes the given flow log. The records already delivered to S3 are kept.
+func DeleteVpcFlowLogsE(t testing.TestingT, region string, flowLogID string) error {
+	if dryrun.Skip(t, "delete VPC flow logs %s in %s", flowLogID, region) {
+		return nil
+	}
+
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Deleting flow log %s", flowLogID)
+	output, err := client.DeleteFlowLogs(&ec2.DeleteFlowLogsInput{FlowLogIds: aws.StringSlice([]string{flowLogID})})
+	if err != nil {
+		return err
+	}
+
+	if len(output.Unsuccessful) > 0 {
+		return fmt.Errorf("Failed to delete flow log %s: %s", flowLogID, aws.StringValue(output.Unsuccessful[0].Error.Message))
+	}
+	return nil
+}
+
+// CreateFlowLogsTable creates a uniquely named Athena database with a table over the default format VPC flow logs
+// under the given S3 location (e.g. "s3://my-bucket/flow-logs/AWSLogs/"), so they can be queried with QueryFlowLogs.
+// Query results are written to the given S3 output location. Use DeleteFlowLogsTable to remove the database again.
+func CreateFlowLogsTable(t testing.TestingT, region string, logsLocation string, outputLocation string) *FlowLogsTable {
+	table, err := CreateFlowLogsTableE(t, region, logsLocation, outputLocation)
+	require.NoError(t, err)
+	return table
+}
+
+// CreateFlowLogsTableE creates a uniquely named Athena database with a table over the default format VPC flow logs
+// under the given S3 location (e.g. "s3://my-bucket/flow-logs/AWSLogs/"), so they can be queried with QueryFlowLogsE.
+// Query results are written to the given S3 output location. Use DeleteFlowLogsTableE to remove the database again.
+func CreateFlowLogsTableE(t testing.TestingT, region string, logsLocation string, outputLocation string) (*FlowLogsTable, error) {
+	if !strings.HasPrefix(logsLocation, "s3://") || strings.ContainsAny(logsLocation, "'\\") {
+		return nil, fmt.Errorf("Invalid S3 location for flow logs %q", logsLocation)
+	}
+
+	table := &FlowLogsTable{
+		Region:         region,
+		Database:       "terratest_flow_logs_" + strings.ToLower(random.UniqueId()),
+		Table:          "flow_logs",
+		OutputLocation: outputLocation,
+	}
+
+	if _, err := RunAthenaQueryE(t, region, fmt.Sprintf("CREATE DATABASE %s", table.Database), "", outputLocation); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(
+		"CREATE EXTERNAL TABLE %s (%s) ROW FORMAT DELIMITED FIELDS TERMINATED BY ' ' LOCATION '%s' TBLPROPERTIES ('skip.header.line.count'='1')",
+		table.Table,
+		flowLogsTableColumns,
+		logsLocation,
+	)
+	if _, err := RunAthenaQueryE(t, region, query, table.Database, outputLocation); err != nil {
+		if cleanupErr := DeleteFlowLogsTableE(t, table); cleanupErr != nil {
+			logger.Logf(t, "Failed to delete Athena database %s: %s", table.Database, cleanupErr)
+		}
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// DeleteFlowLogsTable deletes the Athena database created by CreateFlowLogsTable. The flow logs in S3 are kept.
+func DeleteFlowLogsTable(t testing.TestingT, table *FlowLogsTable) {
+	err := DeleteFlowLogsTableE(t, table)
+	require.NoError(t, err)
+}
+
+// DeleteFlowLogsTableE deletes the Athena database created by CreateFlowLogsTableE. The flow logs in S3 are kept.
+func DeleteFlowLogsTableE(t testing.TestingT, table *FlowLogsTable) error {
+	if dryrun.Skip(t, "drop Athena database %s", table.Database) {
+		return nil
+	}
+
+	_, err := RunAthenaQueryE(t, table.Region, fmt.Sprintf("DROP DATABASE IF EXISTS %s CASCADE", table.Database), "", table.OutputLocation)
+	return err
+}
+
+// QueryFlowLogs returns up to 1000 flow log records in the given table that match the given filter, newest first.
+func QueryFlowLogs(t testing.TestingT, table *FlowLogsTable, filter FlowFilter) []FlowRecord {
+	records, err := QueryFlowLogsE(t, table, filter)
+	require.NoError(t, err)
+	return records
+}
+
+// QueryFlowLogsE returns up to 1000 flow log records in the given table that match the given filter, newest first.
+func QueryFlowLogsE(t testing.TestingT, table *FlowLogsTable, filter FlowFilter) ([]FlowRecord, error) {
+	query, err := buildFlowLogsQuery(table.Table, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := RunAthenaQueryE(t, table.Region, query, table.Database, table.OutputLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []FlowRecord{}
+	for _, row := range rows {
+		record, err := parseFlowRecord(row)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// WaitForFlow queries the flow logs in the given table until they contain traffic matching the given filter, which is
+// evidence the traffic actually happened, and returns the matching records. Flow logs are delivered to S3 in batches,
+// so allow for up to ten minutes of retries.
+func WaitForFlow(t testing.TestingT, table *FlowLogsTable, filter FlowFilter, maxRetries int, sleepBetweenRetries time.Duration) []FlowRecord {
+	records, err := WaitForFlowE(t, table, filter, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return records
+}
+
+// WaitForFlowE queries the flow logs in the given table until they contain traffic matching the given filter, which is
+// evidence the traffic actually happened, and returns the matching records. Flow logs are delivered to S3 in batches,
+// so allow for up to ten minutes of retries.
+func WaitForFlowE(t testing.TestingT, table *FlowLogsTable, filter FlowFilter, maxRetries int, sleepBetweenRetries time.Duration) ([]FlowRecord, error) {
+	var records []FlowRecord
+	description := fmt.Sprintf("Waiting for flow logs matching %s", filter)
+	_, err := retry.DoWithRetryE(t, description, maxRetries, sleepBetweenRetries, func() (string, error) {
+		found, err := QueryFlowLogsE(t, table, filter)
+		if err != nil {
+			if _, isQueryFailure := err.(AthenaQueryFailed); isQueryFailure {
+				return "", retry.FatalError{Underlying: err}
+			}
+			return "", err
+		}
+		if len(found) == 0 {
+			return "", fmt.Errorf("No flow logs matching %s yet", filter)
+		}
+
+		records = found
+		return fmt.Sprintf("Found %d records", len(found)), nil
+	})
+
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return nil, actualErr.Underlying
+	}
+	return records, err
+}
+
+// AssertNoFlow checks that the flow logs in the given table contain no traffic matching the given filter, e.g. to
+// verify that a forbidden flow was rejected or never attempted. Flow logs are delivered in batches, so only call this
+// once the traffic of interest had time to be delivered, e.g. after WaitForFlow found the allowed traffic sent at the
+// same time.
+func AssertNoFlow(t testing.TestingT, table *FlowLogsTable, filter FlowFilter) {
+	err := AssertNoFlowE(t, table, filter)
+	require.NoError(t, err)
+}
+
+// AssertNoFlowE checks that the flow logs in the given table contain no traffic matching the given filter, returning
+// an UnexpectedFlowsFound error otherwise. Flow logs are delivered in batches, so only call this once the traffic of
+// interest had time to be delivered, e.g. after WaitForFlowE found the allowed traffic sent at the same time.
+func AssertNoFlowE(t testing.TestingT, table *FlowLogsTable, filter FlowFilter) error {
+	records, err := QueryFlowLogsE(t, table, filter)
+	if err != nil {
+		return err
+	}
+
+	if len(records) > 0 {
+		return UnexpectedFlowsFound{Filter: filter, Records: records}
+	}
+	return nil
+}
+
+// getFlowLogsResourceType returns the CreateFlowLogs resource type for the given resource ID.
+func getFlowLogsResourceType(resourceID string) (string, error) {
+	switch {
+	case strings.HasPrefix(resourceID, "vpc-"):
+		return ec2.FlowLogsResourceTypeVpc, nil
+	case strings.HasPrefix(resourceID, "subnet-"):
+		return ec2.FlowLogsResourceTypeSubnet, nil
+	case strings.HasPrefix(resourceID, "eni-"):
+		return ec2.FlowLogsResourceTypeNetworkInterface, nil
+	default:
+		return "", fmt.Errorf("Cannot enable flow logs for %s: expected a VPC, subnet or network interface ID", resourceID)
+	}
+}
+
+// buildFlowLogsQuery returns the Athena query for the records in the given table that match the given filter. The
+// filter values are validated rather than escaped, as they can only be IP addresses, numbers and known keywords.
+func buildFlowLogsQuery(table string, filter FlowFilter) (string, error) {
+	conditions := []string{"log_status = 'OK'"}
+
+	if filter.InterfaceId != "" {
+		if !interfaceIDRegex.MatchString(filter.InterfaceId) {
+			return "", fmt.Errorf("Invalid network interface ID %q", filter.InterfaceId)
+		}
+		conditions = append(conditions, fmt.Sprintf("interface_id = '%s'", filter.InterfaceId))
+	}
+
+	for _, addr := range []struct{ column, value string }{{"srcaddr", filter.SrcAddr}, {"dstaddr", filter.DstAddr}} {
+		if addr.value == "" {
+			continue
+		}
+		if net.ParseIP(addr.value) == nil {
+			return "", fmt.Errorf("Invalid IP address %q", addr.value)
+		}
+		conditions = append(conditions, fmt.Sprintf("%s = '%s'", addr.column, addr.value))
+	}
+
+	if filter.SrcPort != 0 {
+		conditions = append(conditions, fmt.Sprintf("srcport = %d", filter.SrcPort))
+	}
+	if filter.DstPort != 0 {
+		conditions = append(conditions, fmt.Sprintf("dstport = %d", filter.DstPort))
+	}
+	if filter.Protocol != 0 {
+		conditions = append(conditions, fmt.Sprintf("protocol = %d", filter.Protocol))
+	}
+
+	if filter.Action != "" {
+		action := strings.ToUpper(filter.Action)
+		if action != FlowActionAccept && action != FlowActionReject {
+			return "", fmt.Errorf("Invalid flow log action %q: expected %s or %s", filter.Action, FlowActionAccept, FlowActionReject)
+		}
+		conditions = append(conditions, fmt.Sprintf("action = '%s'", action))
+	}
+
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("start >= %d", filter.Since.Unix()))
+	}
+
+	return fmt.Sprintf(
+		"SELECT interface_id, srcaddr, dstaddr, srcport, dstport, protocol, packets, bytes, start, \"end\", action FROM %s WHERE %s ORDER BY start DESC LIMIT %d",
+		table,
+		strings.Join(conditions, " AND "),
+		flowLogsQueryLimit,
+	), nil
+}
+
+// parseFlowRecord converts a row returned by the query of buildFlowLogsQuery into a FlowRecord.
+func parseFlowRecord(row map[string]string) (FlowRecord, error) {
+	record := FlowRecord{
+		InterfaceId: row["interface_id"],
+		SrcAddr:     row["srcaddr"],
+		DstAddr:     row["dstaddr"],
+		Action:      row["action"],
+	}
+
+	ints := map[string]*int{"srcport": &record.SrcPort, "dstport": &record.DstPort, "protocol": &record.Protocol}
+	for column, target := range ints {
+		value, err := strconv.Atoi(row[column])
+		if err != nil {
+			return record, fmt.Errorf("Invalid %s in flow log record: %s", column, err)
+		}
+		*target = value
+	}
+
+	int64s := map[string]*int64{"packets": &record.Packets, "bytes": &record.Bytes}
+	for column, target := range int64s {
+		value, err := strconv.ParseInt(row[column], 10, 64)
+		if err != nil {
+			return record, fmt.Errorf("Invalid %s in flow log record: %s", column, err)
+		}
+		*target = value
+	}
+
+	times := map[string]*time.Time{"start": &record.Start, "end": &record.End}
+	for column, target := range times {
+		value, err := strconv.ParseInt(row[column], 10, 64)
+		if err != nil {
+			return record, fmt.Errorf("Invalid %s in flow log record: %s", column, err)
+		}
+		*target = time.Unix(value, 0)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFlowLogsQuery(t *testing.T) {
+	t.Parallel()
+
+	since := time.Unix(1600000000, 0)
+	query, err := buildFlowLogsQuery("flow_logs", FlowFilter{
+		SrcAddr:  "10.0.1.5",
+		DstAddr:  "10.0.2.7",
+		DstPort:  5432,
+		Protocol: FlowProtocolTCP,
+		Action:   "reject",
+		Since:    since,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT interface_id, srcaddr, dstaddr, srcport, dstport, protocol, packets, bytes, start, \"end\", action FROM flow_logs WHERE log_status = 'OK' AND srcaddr = '10.0.1.5' AND dstaddr = '10.0.2.7' AND dstport = 5432 AND protocol = 6 AND action = 'REJECT' AND start >= 1600000000 ORDER BY start DESC LIMIT 1000", query)
+}
+
+func TestBuildFlowLogsQueryRejectsInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	invalidFilters := []FlowFilter{
+		{SrcAddr: "10.0.0.1' OR '1'='1"},
+		{DstAddr: "not-an-ip"},
+		{InterfaceId: "eni-123'"},
+		{Action: "DROP"},
+	}
+
+	for _, filter := range invalidFilters {
+		_, err := buildFlowLogsQuery("flow_logs", filter)
+		assert.Error(t, err, "%s", filter)
+	}
+}
+
+func TestParseFlowRecord(t *testing.T) {
+	t.Parallel()
+
+	record, err := parseFlowRecord(map[string]string{
+		"interface_id": "eni-0123abcd",
+		"srcaddr":      "10.0.1.5",
+		"dstaddr":      "10.0.2.7",
+		"srcport":      "49152",
+		"dstport":      "443",
+		"protocol":     "6",
+		"packets":      "10",
+		"bytes":        "840",
+		"start":        "1600000000",
+		"end":          "1600000060",
+		"action":       "ACCEPT",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, FlowRecord{
+		InterfaceId: "eni-0123abcd",
+		SrcAddr:     "10.0.1.5",
+		DstAddr:     "10.0.2.7",
+		SrcPort:     49152,
+		DstPort:     443,
+		Protocol:    FlowProtocolTCP,
+		Packets:     10,
+		Bytes:       840,
+		Start:       time.Unix(1600000000, 0),
+		End:         time.Unix(1600000060, 0),
+		Action:      FlowActionAccept,
+	}, record)
+}
+
+func TestDropAthenaHeaderRow(t *testing.T) {
+	t.Parallel()
+
+	columns := []string{"srcaddr", "action"}
+	rows := []map[string]string{
+		{"srcaddr": "srcaddr", "action": "action"},
+		{"srcaddr": "10.0.1.5", "action": "ACCEPT"},
+	}
+
+	assert.Equal(t, rows[1:], dropAthenaHeaderRow(columns, rows))
+	assert.Equal(t, rows[1:], dropAthenaHeaderRow(columns, rows[1:]))
+}
@@ -1,10 +1,13 @@
 package aws
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
@@ -133,6 +136,99 @@ func EnableMfaDeviceE(t testing.TestingT, iamClient *iam.IAM, mfaDevice *iam.Vir
 	return nil
 }
 
+// GetDeniedIamActions uses the IAM policy simulator to check which of the given actions the current caller is not
+// allowed to perform on the given resources (or on all resources, if none are given), and returns those actions.
+func GetDeniedIamActions(t testing.TestingT, region string, actions []string, resourceArns []string) []string {
+	denied, err := GetDeniedIamActionsE(t, region, actions, resourceArns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return denied
+}
+
+// GetDeniedIamActionsE uses the IAM policy simulator to check which of the given actions the current caller is not
+// allowed to perform on the given resources (or on all resources, if none are given), and returns those actions. The
+// caller itself needs the iam:SimulatePrincipalPolicy permission.
+func GetDeniedIamActionsE(t testing.TestingT, region string, actions []string, resourceArns []string) ([]string, error) {
+	stsClient, err := NewStsClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	principalArn, err := getIamPrincipalArn(aws.StringValue(identity.Arn))
+	if err != nil {
+		return nil, err
+	}
+
+	iamClient, err := NewIamClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalArn),
+		ActionNames:     aws.StringSlice(actions),
+	}
+	if len(resourceArns) > 0 {
+		input.ResourceArns = aws.StringSlice(resourceArns)
+	}
+
+	denied := []string{}
+	err = iamClient.SimulatePrincipalPolicyPages(input, func(page *iam.SimulatePolicyResponse, lastPage bool) bool {
+		for _, result := range page.EvaluationResults {
+			if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+				denied = append(denied, aws.StringValue(result.EvalActionName))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return uniqueStrings(denied), nil
+}
+
+// getIamPrincipalArn converts the ARN returned by STS GetCallerIdentity into the ARN of the IAM principal whose
+// policies apply to it. For assumed roles, STS returns arn:aws:sts::123456789012:assumed-role/my-role/my-session, but
+// the policy simulator needs the role ARN arn:aws:iam::123456789012:role/my-role. Note that the path of the role is not
+// included in the assumed role ARN, so roles with a path are not supported.
+func getIamPrincipalArn(callerArn string) (string, error) {
+	arnParts := strings.SplitN(callerArn, ":", 6)
+	if len(arnParts) < 6 {
+		return "", fmt.Errorf("Unrecognized format for caller ARN: %s", callerArn)
+	}
+
+	if arnParts[2] != "sts" {
+		return callerArn, nil
+	}
+
+	resourceParts := strings.Split(arnParts[5], "/")
+	if len(resourceParts) < 2 || resourceParts[0] != "assumed-role" {
+		return "", fmt.Errorf("Unrecognized format for caller ARN: %s", callerArn)
+	}
+
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", arnParts[1], arnParts[4], resourceParts[1]), nil
+}
+
+// uniqueStrings returns the given strings with duplicates removed, keeping the order of first occurrence.
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
 // NewIamClient creates a new IAM client.
 func NewIamClient(t testing.TestingT, region string) *iam.IAM {
 	client, err := NewIamClientE(t, region)
@@ -19,3 +19,33 @@ func TestGetIamCurrentUserArn(t *testing.T) {
 	username := GetIamCurrentUserArn(t)
 	assert.Regexp(t, "^arn:aws:iam::[0-9]{12}:user/.+$", username)
 }
+
+func TestGetIamPrincipalArn(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		callerArn string
+		expected  string
+	}{
+		{"arn:aws:iam::123456789012:user/test", "arn:aws:iam::123456789012:user/test"},
+		{"arn:aws:sts::123456789012:assumed-role/my-role/my-session", "arn:aws:iam::123456789012:role/my-role"},
+		{"arn:aws-us-gov:sts::123456789012:assumed-role/my-role/my-session", "arn:aws-us-gov:iam::123456789012:role/my-role"},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := getIamPrincipalArn(testCase.callerArn)
+		if assert.NoError(t, err) {
+			assert.Equal(t, testCase.expected, actual)
+		}
+	}
+}
+
+func TestGetIamPrincipalArnInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := getIamPrincipalArn("arn:aws:sts::123456789012:federated-user/test")
+	assert.Error(t, err)
+
+	_, err = getIamPrincipalArn("not-an-arn")
+	assert.Error(t, err)
+}
@@ -1,11 +1,17 @@
 package aws
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -173,6 +179,193 @@ func (err *FunctionError) Error() string {
 	return fmt.Sprintf("%s error invoking lambda function: %v", err.Message, err.Payload)
 }
 
+// LambdaInvocation is the parsed result of a synchronous invocation of a Lambda function.
+type LambdaInvocation struct {
+	StatusCode    int64
+	Payload       []byte               // The response of the function, or the error object if it failed
+	FunctionError *LambdaFunctionError // Set if the function failed, e.g. because its handler threw an exception
+	LogTail       string               // The last 4 KB of the logs of the invocation
+}
+
+// LambdaFunctionError is the error object a Lambda function responds with when it fails.
+type LambdaFunctionError struct {
+	Type         string   // Either "Handled" or "Unhandled", as reported by Lambda
+	ErrorType    string   `json:"errorType"`
+	ErrorMessage string   `json:"errorMessage"`
+	StackTrace   []string `json:"stackTrace"`
+}
+
+// InvokeLambdaFunction invokes the given Lambda function synchronously with the given payload, which is converted to
+// JSON, and returns the parsed result. This will fail the test if the invocation could not be made, but not if the
+// function itself fails: check FunctionError of the result for that.
+func InvokeLambdaFunction(t testing.TestingT, region string, functionName string, payload interface{}) *LambdaInvocation {
+	invocation, err := InvokeLambdaFunctionE(t, region, functionName, payload)
+	require.NoError(t, err)
+	return invocation
+}
+
+// InvokeLambdaFunctionE invokes the given Lambda function synchronously with the given payload, which is converted to
+// JSON, and returns the parsed result, including the tail of its logs. An error is only returned if the invocation
+// could not be made, e.g. because the function does not exist. If the function itself fails, its error object is
+// parsed into FunctionError of the result instead, so that tests can assert on it.
+func InvokeLambdaFunctionE(t testing.TestingT, region string, functionName string, payload interface{}) (*LambdaInvocation, error) {
+	lambdaClient, err := NewLambdaClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	invokeInput := &lambda.InvokeInput{
+		FunctionName:   aws.String(functionName),
+		InvocationType: aws.String(lambda.InvocationTypeRequestResponse),
+		LogType:        aws.String(lambda.LogTypeTail),
+	}
+	if payload != nil {
+		payloadJson, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		invokeInput.Payload = payloadJson
+	}
+
+	out, err := lambdaClient.Invoke(invokeInput)
+	if err != nil {
+		return nil, err
+	}
+	return parseLambdaInvocation(out)
+}
+
+// parseLambdaInvocation parses the output of a synchronous Lambda invocation.
+func parseLambdaInvocation(out *lambda.InvokeOutput) (*LambdaInvocation, error) {
+	invocation := &LambdaInvocation{
+		StatusCode: aws.Int64Value(out.StatusCode),
+		Payload:    out.Payload,
+	}
+
+	if out.LogResult != nil {
+		logTail, err := base64.StdEncoding.DecodeString(aws.StringValue(out.LogResult))
+		if err != nil {
+			return nil, err
+		}
+		invocation.LogTail = string(logTail)
+	}
+
+	if out.FunctionError != nil {
+		functionError := &LambdaFunctionError{}
+		// The error object of some runtimes, e.g. a timeout, is not JSON, in which case it is kept as the message
+		if err := json.Unmarshal(out.Payload, functionError); err != nil {
+			functionError.ErrorMessage = string(out.Payload)
+		}
+		functionError.Type = aws.StringValue(out.FunctionError)
+		invocation.FunctionError = functionError
+	}
+	return invocation, nil
+}
+
+// WaitForLambdaFunctionActive waits until the given Lambda function is active and its last update succeeded. This will
+// fail the test if it does not.
+func WaitForLambdaFunctionActive(t testing.TestingT, region string, functionName string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForLambdaFunctionActiveE(t, region, functionName, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForLambdaFunctionActiveE waits until the given Lambda function is active and its last update succeeded, so that
+// it can be invoked right after it is created or its code is updated. Returns a LambdaFunctionFailed error as soon as
+// the function or its last update fails, e.g. because its role can't be assumed.
+func WaitForLambdaFunctionActiveE(t testing.TestingT, region string, functionName string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	lambdaClient, err := NewLambdaClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("Lambda function %s", functionName),
+		DesiredState: "Active",
+		Extract: func() (WaitState, error) {
+			configuration, err := lambdaClient.GetFunctionConfiguration(&lambda.GetFunctionConfigurationInput{FunctionName: aws.String(functionName)})
+			if err != nil {
+				return WaitState{}, err
+			}
+			return lambdaFunctionWaitState(functionName, configuration)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// lambdaFunctionWaitState returns the state of the given Lambda function for WaitForLambdaFunctionActiveE. Returns a
+// retry.FatalError if the function or its last update failed.
+func lambdaFunctionWaitState(functionName string, configuration *lambda.FunctionConfiguration) (WaitState, error) {
+	state := aws.StringValue(configuration.State)
+	if state == lambda.StateFailed {
+		return WaitState{}, retry.FatalError{Underlying: LambdaFunctionFailed{
+			FunctionName: functionName,
+			State:        state,
+			Reason:       aws.StringValue(configuration.StateReason),
+		}}
+	}
+
+	updateStatus := aws.StringValue(configuration.LastUpdateStatus)
+	if updateStatus == lambda.LastUpdateStatusFailed {
+		return WaitState{}, retry.FatalError{Underlying: LambdaFunctionFailed{
+			FunctionName: functionName,
+			State:        "update " + updateStatus,
+			Reason:       aws.StringValue(configuration.LastUpdateStatusReason),
+		}}
+	}
+
+	// Functions created before Lambda reported states don't have one
+	active := configuration.State == nil || state == lambda.StateActive
+	updated := configuration.LastUpdateStatus == nil || updateStatus == lambda.LastUpdateStatusSuccessful
+	current := state
+	if updateStatus != "" {
+		current = fmt.Sprintf("%s (last update %s)", state, updateStatus)
+	}
+	return WaitState{Current: current, Done: active && updated}, nil
+}
+
+// GetLambdaFunctionLogs returns the log messages the given Lambda function wrote to CloudWatch since the given time.
+// This will fail the test if there is an error.
+func GetLambdaFunctionLogs(t testing.TestingT, region string, functionName string, since time.Time) []string {
+	logs, err := GetLambdaFunctionLogsE(t, region, functionName, since)
+	require.NoError(t, err)
+	return logs
+}
+
+// GetLambdaFunctionLogsE returns the log messages the given Lambda function wrote to its log group
+// /aws/lambda/<function name> in CloudWatch since the given time, across all its log streams and in the order they were
+// written. Note that logs take a few seconds to show up in CloudWatch after an invocation.
+func GetLambdaFunctionLogsE(t testing.TestingT, region string, functionName string, since time.Time) ([]string, error) {
+	client, err := NewCloudWatchLogsClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(lambdaFunctionLogGroup(functionName)),
+		StartTime:    aws.Int64(since.UnixNano() / int64(time.Millisecond)),
+	}
+	messages := []string{}
+	err = client.FilterLogEventsPages(input, func(page *cloudwatchlogs.FilterLogEventsOutput, lastPage bool) bool {
+		for _, event := range page.Events {
+			messages = append(messages, strings.TrimSuffix(aws.StringValue(event.Message), "\n"))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// lambdaFunctionLogGroup returns the CloudWatch log group Lambda creates for the given function.
+func lambdaFunctionLogGroup(functionName string) string {
+	// The function may be given by ARN, in which case the name is the part after "function:", before any qualifier
+	parts := strings.Split(functionName, ":")
+	if len(parts) >= 7 {
+		functionName = parts[6]
+	}
+	return "/aws/lambda/" + functionName
+}
+
 // NewLambdaClient creates a new Lambda client.
 func NewLambdaClient(t testing.TestingT, region string) *lambda.Lambda {
 	client, err := NewLambdaClientE(t, region)
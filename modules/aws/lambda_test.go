@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLambdaInvocation(t *testing.T) {
+	t.Parallel()
+
+	invocation, err := parseLambdaInvocation(&lambda.InvokeOutput{
+		StatusCode: aws.Int64(200),
+		Payload:    []byte(`{"ok":true}`),
+		LogResult:  aws.String(base64.StdEncoding.EncodeToString([]byte("START RequestId: 1\nEND RequestId: 1\n"))),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &LambdaInvocation{
+		StatusCode: 200,
+		Payload:    []byte(`{"ok":true}`),
+		LogTail:    "START RequestId: 1\nEND RequestId: 1\n",
+	}, invocation)
+}
+
+func TestParseLambdaInvocationFunctionError(t *testing.T) {
+	t.Parallel()
+
+	invocation, err := parseLambdaInvocation(&lambda.InvokeOutput{
+		StatusCode:    aws.Int64(200),
+		FunctionError: aws.String("Unhandled"),
+		Payload:       []byte(`{"errorType":"ValueError","errorMessage":"bad input","stackTrace":["line 1"]}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &LambdaFunctionError{
+		Type:         "Unhandled",
+		ErrorType:    "ValueError",
+		ErrorMessage: "bad input",
+		StackTrace:   []string{"line 1"},
+	}, invocation.FunctionError)
+
+	invocation, err = parseLambdaInvocation(&lambda.InvokeOutput{
+		StatusCode:    aws.Int64(200),
+		FunctionError: aws.String("Unhandled"),
+		Payload:       []byte(`Task timed out after 3.00 seconds`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &LambdaFunctionError{Type: "Unhandled", ErrorMessage: "Task timed out after 3.00 seconds"}, invocation.FunctionError)
+}
+
+func TestLambdaFunctionWaitState(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		configuration *lambda.FunctionConfiguration
+		expected      WaitState
+	}{
+		{"pending", &lambda.FunctionConfiguration{State: aws.String(lambda.StatePending)}, WaitState{Current: "Pending"}},
+		{"active", &lambda.FunctionConfiguration{State: aws.String(lambda.StateActive)}, WaitState{Current: "Active", Done: true}},
+		{
+			"updating",
+			&lambda.FunctionConfiguration{State: aws.String(lambda.StateActive), LastUpdateStatus: aws.String(lambda.LastUpdateStatusInProgress)},
+			WaitState{Current: "Active (last update InProgress)"},
+		},
+		{
+			"updated",
+			&lambda.FunctionConfiguration{State: aws.String(lambda.StateActive), LastUpdateStatus: aws.String(lambda.LastUpdateStatusSuccessful)},
+			WaitState{Current: "Active (last update Successful)", Done: true},
+		},
+		{"no state", &lambda.FunctionConfiguration{}, WaitState{Done: true}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			state, err := lambdaFunctionWaitState("my-function", testCase.configuration)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, state)
+		})
+	}
+}
+
+func TestLambdaFunctionWaitStateFailed(t *testing.T) {
+	t.Parallel()
+
+	_, err := lambdaFunctionWaitState("my-function", &lambda.FunctionConfiguration{
+		State:       aws.String(lambda.StateFailed),
+		StateReason: aws.String("The role can't be assumed"),
+	})
+	require.IsType(t, retry.FatalError{}, err)
+	assert.True(t, errors.Is(err.(retry.FatalError).Underlying, ErrOperationFailed))
+
+	_, err = lambdaFunctionWaitState("my-function", &lambda.FunctionConfiguration{
+		State:            aws.String(lambda.StateActive),
+		LastUpdateStatus: aws.String(lambda.LastUpdateStatusFailed),
+	})
+	require.IsType(t, retry.FatalError{}, err)
+}
+
+func TestLambdaFunctionLogGroup(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/aws/lambda/my-function", lambdaFunctionLogGroup("my-function"))
+	assert.Equal(t, "/aws/lambda/my-function", lambdaFunctionLogGroup("arn:aws:lambda:us-east-1:123456789012:function:my-function"))
+	assert.Equal(t, "/aws/lambda/my-function", lambdaFunctionLogGroup("arn:aws:lambda:us-east-1:123456789012:function:my-function:live"))
+}
@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/licensemanager"
+	"github.com/gruntwork-io/terratest/modules/collections"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// GetLicenseConfigurationArnsForResource gets the ARNs of the License Manager license configurations associated with
+// the given resource (e.g. an AMI or an EC2 instance ARN).
+func GetLicenseConfigurationArnsForResource(t testing.TestingT, region string, resourceArn string) []string {
+	arns, err := GetLicenseConfigurationArnsForResourceE(t, region, resourceArn)
+	require.NoError(t, err)
+	return arns
+}
+
+// GetLicenseConfigurationArnsForResourceE gets the ARNs of the License Manager license configurations associated with
+// the given resource (e.g. an AMI or an EC2 instance ARN).
+func GetLicenseConfigurationArnsForResourceE(t testing.TestingT, region string, resourceArn string) ([]string, error) {
+	client, err := NewLicenseManagerClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	arns := []string{}
+	input := &licensemanager.ListLicenseSpecificationsForResourceInput{ResourceArn: aws.String(resourceArn)}
+	for {
+		output, err := client.ListLicenseSpecificationsForResource(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, specification := range output.LicenseSpecifications {
+			arns = append(arns, aws.StringValue(specification.LicenseConfigurationArn))
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	return arns, nil
+}
+
+// GetResourcesAssociatedWithLicenseConfiguration gets the ARNs of the resources associated with the given License
+// Manager license configuration.
+func GetResourcesAssociatedWithLicenseConfiguration(t testing.TestingT, region string, licenseConfigurationArn string) []string {
+	arns, err := GetResourcesAssociatedWithLicenseConfigurationE(t, region, licenseConfigurationArn)
+	require.NoError(t, err)
+	return arns
+}
+
+// GetResourcesAssociatedWithLicenseConfigurationE gets the ARNs of the resources associated with the given License
+// Manager license configuration.
+func GetResourcesAssociatedWithLicenseConfigurationE(t testing.TestingT, region string, licenseConfigurationArn string) ([]string, error) {
+	client, err := NewLicenseManagerClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	arns := []string{}
+	input := &licensemanager.ListAssociationsForLicenseConfigurationInput{LicenseConfigurationArn: aws.String(licenseConfigurationArn)}
+	for {
+		output, err := client.ListAssociationsForLicenseConfiguration(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, association := range output.LicenseConfigurationAssociations {
+			arns = append(arns, aws.StringValue(association.ResourceArn))
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	return arns, nil
+}
+
+// AssertLicenseConfigurationAssociated checks that the given resource is associated with the given License Manager
+// license configuration, and fails the test if it is not.
+func AssertLicenseConfigurationAssociated(t testing.TestingT, region string, resourceArn string, licenseConfigurationArn string) {
+	require.NoError(t, AssertLicenseConfigurationAssociatedE(t, region, resourceArn, licenseConfigurationArn))
+}
+
+// AssertLicenseConfigurationAssociatedE checks that the given resource is associated with the given License Manager
+// license configuration, and returns a LicenseConfigurationNotAssociated error if it is not.
+func AssertLicenseConfigurationAssociatedE(t testing.TestingT, region string, resourceArn string, licenseConfigurationArn string) error {
+	arns, err := GetLicenseConfigurationArnsForResourceE(t, region, resourceArn)
+	if err != nil {
+		return err
+	}
+
+	if !collections.ListContains(arns, licenseConfigurationArn) {
+		return LicenseConfigurationNotAssociated{
+			ResourceArn:             resourceArn,
+			LicenseConfigurationArn: licenseConfigurationArn,
+			AssociatedArns:          arns,
+		}
+	}
+
+	return nil
+}
+
+// NewLicenseManagerClient creates a new License Manager client.
+func NewLicenseManagerClient(t testing.TestingT, region string) *licensemanager.LicenseManager {
+	client, err := NewLicenseManagerClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewLicenseManagerClientE creates a new License Manager client.
+func NewLicenseManagerClientE(t testing.TestingT, region string) (*licensemanager.LicenseManager, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+	return licensemanager.New(sess), nil
+}
@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// optInRequiredErrorCode is the error code EC2 returns when launching a Marketplace AMI without a subscription.
+	optInRequiredErrorCode = "OptInRequired"
+
+	// dryRunOperationErrorCode is the error code EC2 returns when a dry run request would have succeeded.
+	dryRunOperationErrorCode = "DryRunOperation"
+)
+
+// GetAmiMarketplaceProductCodes gets the Marketplace product codes of the given AMI. AMIs that are not Marketplace
+// products have none.
+func GetAmiMarketplaceProductCodes(t testing.TestingT, region string, amiID string) []string {
+	codes, err := GetAmiMarketplaceProductCodesE(t, region, amiID)
+	require.NoError(t, err)
+	return codes
+}
+
+// GetAmiMarketplaceProductCodesE gets the Marketplace product codes of the given AMI. AMIs that are not Marketplace
+// products have none.
+func GetAmiMarketplaceProductCodesE(t testing.TestingT, region string, amiID string) ([]string, error) {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.DescribeImages(&ec2.DescribeImagesInput{ImageIds: aws.StringSlice([]string{amiID})})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Images) == 0 {
+		return nil, NewNotFoundError("AMI", amiID, region)
+	}
+
+	codes := []string{}
+	for _, productCode := range output.Images[0].ProductCodes {
+		if aws.StringValue(productCode.ProductCodeType) == ec2.ProductCodeValuesMarketplace {
+			codes = append(codes, aws.StringValue(productCode.ProductCodeId))
+		}
+	}
+
+	return codes, nil
+}
+
+// AssertAmiSubscribed checks that the account can launch the given AMI, i.e. that it is either not a Marketplace product
+// or that the account is subscribed to it, and fails the test with a MarketplaceSubscriptionRequired error otherwise.
+// Run this before apply, so a missing subscription doesn't fail the test halfway through.
+func AssertAmiSubscribed(t testing.TestingT, region string, amiID string, instanceType string) {
+	require.NoError(t, AssertAmiSubscribedE(t, region, amiID, instanceType))
+}
+
+// AssertAmiSubscribedE checks that the account can launch the given AMI, i.e. that it is either not a Marketplace
+// product or that the account is subscribed to it, and returns a MarketplaceSubscriptionRequired error otherwise. The
+// subscription is verified with a dry run launch of the given instance type in the default VPC, so the caller needs the
+// ec2:RunInstances permission.
+func AssertAmiSubscribedE(t testing.TestingT, region string, amiID string, instanceType string) error {
+	codes, err := GetAmiMarketplaceProductCodesE(t, region, amiID)
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Checking the Marketplace subscription for AMI %s (product codes %v) with a dry run launch", amiID, codes)
+	_, err = client.RunInstances(&ec2.RunInstancesInput{
+		DryRun:       aws.Bool(true),
+		ImageId:      aws.String(amiID),
+		InstanceType: aws.String(instanceType),
+		MinCount:     aws.Int64(1),
+		MaxCount:     aws.Int64(1),
+	})
+
+	return interpretSubscriptionDryRunError(err, amiID, region, codes)
+}
+
+// interpretSubscriptionDryRunError converts the error of a dry run launch of a Marketplace AMI into the result of the
+// subscription check: DryRunOperation means the launch would have succeeded, OptInRequired means there is no
+// subscription. Any other error is returned as is.
+func interpretSubscriptionDryRunError(err error, amiID string, region string, codes []string) error {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+
+	switch awsErr.Code() {
+	case dryRunOperationErrorCode:
+		return nil
+	case optInRequiredErrorCode:
+		return MarketplaceSubscriptionRequired{AmiId: amiID, Region: region, ProductCodes: codes}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAmiMarketplaceProductCodesForNonMarketplaceAmi(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	amiID := GetAmazonLinuxAmi(t, region)
+
+	codes := GetAmiMarketplaceProductCodes(t, region, amiID)
+	assert.Empty(t, codes)
+
+	// AMIs that are not Marketplace products never need a subscription
+	AssertAmiSubscribed(t, region, amiID, "t3.micro")
+}
+
+func TestInterpretSubscriptionDryRunError(t *testing.T) {
+	t.Parallel()
+
+	codes := []string{"aw0evgkw8e5c1q413zgy5pjce"}
+
+	assert.NoError(t, interpretSubscriptionDryRunError(awserr.New("DryRunOperation", "Request would have succeeded", nil), "ami-123", "us-east-1", codes))
+
+	err := interpretSubscriptionDryRunError(awserr.New("OptInRequired", "In order to use this AWS Marketplace product you need to accept terms", nil), "ami-123", "us-east-1", codes)
+	assert.Equal(t, MarketplaceSubscriptionRequired{AmiId: "ami-123", Region: "us-east-1", ProductCodes: codes}, err)
+	assert.Contains(t, err.Error(), "https://aws.amazon.com/marketplace/pp?sku=aw0evgkw8e5c1q413zgy5pjce")
+
+	otherErr := errors.New("connection reset by peer")
+	assert.Equal(t, otherErr, interpretSubscriptionDryRunError(otherErr, "ami-123", "us-east-1", codes))
+}
@@ -0,0 +1,245 @@
+package aws
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultMaxRegionParallelism is the number of regions ForEachRegion checks at the same time, which keeps multi-region
+// tests from hitting API rate limits.
+const DefaultMaxRegionParallelism = 4
+
+// ForEachRegion runs the given function for each of the given regions, at most DefaultMaxRegionParallelism at a time,
+// and fails the test with the failures of all the regions once they are done. The function receives a TestingT scoped
+// to its region, so it can use assertions such as require.NoError: a failure stops only that region.
+func ForEachRegion(t testing.TestingT, regions []string, fn func(t testing.TestingT, region string)) {
+	ForEachRegionWithParallelism(t, regions, DefaultMaxRegionParallelism, fn)
+}
+
+// ForEachRegionWithParallelism runs the given function for each of the given regions, at most maxParallelism at a time,
+// and fails the test with the failures of all the regions once they are done. See ForEachRegion.
+func ForEachRegionWithParallelism(t testing.TestingT, regions []string, maxParallelism int, fn func(t testing.TestingT, region string)) {
+	err := ForEachRegionE(regions, maxParallelism, func(region string) error {
+		return runInRegionE(t, region, fn)
+	})
+	require.NoError(t, err)
+}
+
+// ForEachRegionE runs the given function for each of the given regions, at most maxParallelism at a time (with no
+// limit if it is not positive), and returns a MultiRegionFailures error with the error of every region that failed.
+func ForEachRegionE(regions []string, maxParallelism int, fn func(region string) error) error {
+	if maxParallelism <= 0 {
+		maxParallelism = len(regions)
+	}
+
+	failures := MultiRegionFailures{}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxParallelism)
+
+	for _, region := range regions {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func(region string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := fn(region); err != nil {
+				mutex.Lock()
+				failures[region] = err
+				mutex.Unlock()
+			}
+		}(region)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return failures
+	}
+	return nil
+}
+
+// GetDynamoDBTableInRegions gets the description of the DynamoDB table with the given name in each of the given
+// regions, e.g. for the replicas of a global table. This will fail the test if there is an error in any region.
+func GetDynamoDBTableInRegions(t testing.TestingT, regions []string, tableName string) map[string]*dynamodb.TableDescription {
+	tables, err := GetDynamoDBTableInRegionsE(t, regions, tableName)
+	require.NoError(t, err)
+	return tables
+}
+
+// GetDynamoDBTableInRegionsE gets the description of the DynamoDB table with the given name in each of the given
+// regions, e.g. for the replicas of a global table.
+func GetDynamoDBTableInRegionsE(t testing.TestingT, regions []string, tableName string) (map[string]*dynamodb.TableDescription, error) {
+	tables := map[string]*dynamodb.TableDescription{}
+	var mutex sync.Mutex
+
+	err := ForEachRegionE(regions, DefaultMaxRegionParallelism, func(region string) error {
+		table, err := GetDynamoDBTableE(t, region, tableName)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		tables[region] = table
+		return nil
+	})
+	return tables, err
+}
+
+// GetParameterInRegions gets the value of the SSM parameter with the given name in each of the given regions. This
+// will fail the test if there is an error in any region.
+func GetParameterInRegions(t testing.TestingT, regions []string, keyName string) map[string]string {
+	values, err := GetParameterInRegionsE(t, regions, keyName)
+	require.NoError(t, err)
+	return values
+}
+
+// GetParameterInRegionsE gets the value of the SSM parameter with the given name in each of the given regions.
+func GetParameterInRegionsE(t testing.TestingT, regions []string, keyName string) (map[string]string, error) {
+	return getStringInRegionsE(regions, func(region string) (string, error) {
+		return GetParameterE(t, region, keyName)
+	})
+}
+
+// GetCloudFormationStackOutputInRegions gets the value of the given output of the CloudFormation stack with the given
+// name in each of the given regions, e.g. for a StackSet. This will fail the test if there is an error in any region.
+func GetCloudFormationStackOutputInRegions(t testing.TestingT, regions []string, stackName string, outputKey string) map[string]string {
+	values, err := GetCloudFormationStackOutputInRegionsE(t, regions, stackName, outputKey)
+	require.NoError(t, err)
+	return values
+}
+
+// GetCloudFormationStackOutputInRegionsE gets the value of the given output of the CloudFormation stack with the given
+// name in each of the given regions, e.g. for a StackSet.
+func GetCloudFormationStackOutputInRegionsE(t testing.TestingT, regions []string, stackName string, outputKey string) (map[string]string, error) {
+	return getStringInRegionsE(regions, func(region string) (string, error) {
+		return GetCloudFormationStackOutputE(t, region, stackName, outputKey)
+	})
+}
+
+// GetEc2InstanceIdsByTagInRegions returns the IDs of the EC2 instances with the given tag in each of the given regions.
+// This will fail the test if there is an error in any region.
+func GetEc2InstanceIdsByTagInRegions(t testing.TestingT, regions []string, tagName string, tagValue string) map[string][]string {
+	ids, err := GetEc2InstanceIdsByTagInRegionsE(t, regions, tagName, tagValue)
+	require.NoError(t, err)
+	return ids
+}
+
+// GetEc2InstanceIdsByTagInRegionsE returns the IDs of the EC2 instances with the given tag in each of the given regions.
+func GetEc2InstanceIdsByTagInRegionsE(t testing.TestingT, regions []string, tagName string, tagValue string) (map[string][]string, error) {
+	ids := map[string][]string{}
+	var mutex sync.Mutex
+
+	err := ForEachRegionE(regions, DefaultMaxRegionParallelism, func(region string) error {
+		regionIds, err := GetEc2InstanceIdsByTagE(t, region, tagName, tagValue)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		ids[region] = regionIds
+		return nil
+	})
+	return ids, err
+}
+
+// getStringInRegionsE runs the given getter in each of the given regions and returns its results keyed by region.
+func getStringInRegionsE(regions []string, getter func(region string) (string, error)) (map[string]string, error) {
+	values := map[string]string{}
+	var mutex sync.Mutex
+
+	err := ForEachRegionE(regions, DefaultMaxRegionParallelism, func(region string) error {
+		value, err := getter(region)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		values[region] = value
+		return nil
+	})
+	return values, err
+}
+
+// runInRegionE runs the given function with a TestingT scoped to the given region, and returns the failures it reported
+// as an error.
+func runInRegionE(t testing.TestingT, region string, fn func(t testing.TestingT, region string)) error {
+	regionT := &regionTestingT{parent: t, region: region}
+
+	done := make(chan struct{})
+	go func() {
+		// FailNow exits this goroutine with runtime.Goexit, as it does for the goroutine of a test
+		defer close(done)
+		fn(regionT, region)
+	}()
+	<-done
+
+	if !regionT.failed {
+		logger.Logf(t, "Checks in region %s passed", region)
+		return nil
+	}
+	if len(regionT.messages) == 0 {
+		return fmt.Errorf("checks in region %s failed", region)
+	}
+	return fmt.Errorf("%s", strings.Join(regionT.messages, "\n"))
+}
+
+// regionTestingT is the TestingT that ForEachRegion passes to the function for each region. It records failures instead
+// of failing the test right away, so that the other regions keep running.
+type regionTestingT struct {
+	parent   testing.TestingT
+	region   string
+	mutex    sync.Mutex
+	failed   bool
+	messages []string
+}
+
+func (t *regionTestingT) Fail() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.failed = true
+}
+
+func (t *regionTestingT) FailNow() {
+	t.Fail()
+	runtime.Goexit()
+}
+
+func (t *regionTestingT) Fatal(args ...interface{}) {
+	t.log(fmt.Sprint(args...))
+	t.FailNow()
+}
+
+func (t *regionTestingT) Fatalf(format string, args ...interface{}) {
+	t.log(fmt.Sprintf(format, args...))
+	t.FailNow()
+}
+
+func (t *regionTestingT) Error(args ...interface{}) {
+	t.log(fmt.Sprint(args...))
+	t.Fail()
+}
+
+func (t *regionTestingT) Errorf(format string, args ...interface{}) {
+	t.log(fmt.Sprintf(format, args...))
+	t.Fail()
+}
+
+func (t *regionTestingT) Name() string {
+	return t.parent.Name() + "/" + t.region
+}
+
+// log records the given failure message.
+func (t *regionTestingT) log(message string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.messages = append(t.messages, message)
+}
@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	terratesting "github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachRegionELimitsParallelism(t *testing.T) {
+	t.Parallel()
+
+	var running, maxRunning int32
+	regions := []string{"us-east-1", "us-east-2", "us-west-1", "us-west-2", "eu-west-1", "eu-central-1"}
+
+	err := ForEachRegionE(regions, 2, func(region string) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			previous := atomic.LoadInt32(&maxRunning)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), maxRunning)
+}
+
+func TestForEachRegionEAggregatesFailures(t *testing.T) {
+	t.Parallel()
+
+	err := ForEachRegionE([]string{"us-east-1", "us-west-2", "eu-west-1"}, 0, func(region string) error {
+		if region == "us-east-1" {
+			return nil
+		}
+		return errors.New("table not found")
+	})
+
+	require.Error(t, err)
+	failures, ok := err.(MultiRegionFailures)
+	require.True(t, ok)
+	assert.Len(t, failures, 2)
+	assert.Equal(t, "Failed in 2 regions:\neu-west-1: table not found\nus-west-2: table not found", err.Error())
+}
+
+func TestRunInRegionEStopsOnlyTheFailedRegion(t *testing.T) {
+	t.Parallel()
+
+	reachedEnd := false
+	err := runInRegionE(t, "eu-west-1", func(t terratesting.TestingT, region string) {
+		require.Equal(t, "ap-south-1", region)
+		reachedEnd = true
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ap-south-1")
+	assert.False(t, reachedEnd)
+
+	assert.NoError(t, runInRegionE(t, "eu-west-1", func(t terratesting.TestingT, region string) {
+		assert.Equal(t, "eu-west-1", region)
+	}))
+}
@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// SubnetIsolation records the network ACL changes made by IsolateSubnets, so that RestoreIsolatedSubnets can undo them.
+type SubnetIsolation struct {
+	Region                string
+	VpcId                 string
+	NetworkAclId          string            // The ID of the deny-all network ACL the subnets were moved to
+	OriginalNetworkAclIds map[string]string // Map of subnet ID to the ID of the network ACL it was associated with before
+}
+
+// IsolateSubnets cuts all network traffic in and out of the given subnets of the given VPC by associating them with a
+// new network ACL that denies everything, which simulates the loss of the Availability Zone they are in. Use
+// RestoreIsolatedSubnets to put the original network ACLs back.
+func IsolateSubnets(t testing.TestingT, region string, vpcID string, subnetIDs []string) *SubnetIsolation {
+	isolation, err := IsolateSubnetsE(t, region, vpcID, subnetIDs)
+	require.NoError(t, err)
+	return isolation
+}
+
+// IsolateSubnetsE cuts all network traffic in and out of the given subnets of the given VPC by associating them with a
+// new network ACL that denies everything, which simulates the loss of the Availability Zone they are in. Use
+// RestoreIsolatedSubnetsE to put the original network ACLs back. If the isolation fails halfway, the subnets that were
+// already isolated are restored before returning the error. In dry-run mode, this returns a nil SubnetIsolation, which
+// RestoreIsolatedSubnetsE accepts.
+func IsolateSubnetsE(t testing.TestingT, region string, vpcID string, subnetIDs []string) (*SubnetIsolation, error) {
+	if dryrun.Skip(t, "isolate subnets %v of VPC %s in %s", subnetIDs, vpcID, region) {
+		return nil, nil
+	}
+
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	associations, err := getNetworkAclAssociationsE(client, subnetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("terratest-isolation-%s", random.UniqueId())
+	output, err := client.CreateNetworkAcl(&ec2.CreateNetworkAclInput{
+		VpcId: aws.String(vpcID),
+		TagSpecifications: []*ec2.TagSpecification{{
+			ResourceType: aws.String(ec2.ResourceTypeNetworkAcl),
+			Tags:         []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String(name)}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A new network ACL only has the default rules, which deny all inbound and outbound traffic
+	isolation := &SubnetIsolation{
+		Region:                region,
+		VpcId:                 vpcID,
+		NetworkAclId:          aws.StringValue(output.NetworkAcl.NetworkAclId),
+		OriginalNetworkAclIds: map[string]string{},
+	}
+
+	for _, subnetID := range subnetIDs {
+		association := associations[subnetID]
+
+		logger.Logf(t, "Isolating subnet %s by moving it from network ACL %s to %s", subnetID, aws.StringValue(association.NetworkAclId), isolation.NetworkAclId)
+		_, err := client.ReplaceNetworkAclAssociation(&ec2.ReplaceNetworkAclAssociationInput{
+			AssociationId: association.NetworkAclAssociationId,
+			NetworkAclId:  aws.String(isolation.NetworkAclId),
+		})
+		if err != nil {
+			if restoreErr := RestoreIsolatedSubnetsE(t, isolation); restoreErr != nil {
+				logger.Logf(t, "Failed to restore subnets after failed isolation: %s", restoreErr)
+			}
+			return nil, err
+		}
+
+		isolation.OriginalNetworkAclIds[subnetID] = aws.StringValue(association.NetworkAclId)
+	}
+
+	return isolation, nil
+}
+
+// RestoreIsolatedSubnets moves the subnets isolated by IsolateSubnets back to their original network ACLs and deletes
+// the deny-all network ACL.
+func RestoreIsolatedSubnets(t testing.TestingT, isolation *SubnetIsolation) {
+	require.NoError(t, RestoreIsolatedSubnetsE(t, isolation))
+}
+
+// RestoreIsolatedSubnetsE moves the subnets isolated by IsolateSubnetsE back to their original network ACLs and deletes
+// the deny-all network ACL. A nil isolation, as returned by IsolateSubnetsE in dry-run mode, has nothing to restore.
+func RestoreIsolatedSubnetsE(t testing.TestingT, isolation *SubnetIsolation) error {
+	if isolation == nil {
+		return nil
+	}
+
+	if dryrun.Skip(t, "restore subnets isolated by network ACL %s in %s", isolation.NetworkAclId, isolation.Region) {
+		return nil
+	}
+
+	client, err := NewEc2ClientE(t, isolation.Region)
+	if err != nil {
+		return err
+	}
+
+	output, err := client.DescribeNetworkAcls(&ec2.DescribeNetworkAclsInput{NetworkAclIds: aws.StringSlice([]string{isolation.NetworkAclId})})
+	if err != nil {
+		return err
+	}
+
+	for _, networkAcl := range output.NetworkAcls {
+		for _, association := range networkAcl.Associations {
+			subnetID := aws.StringValue(association.SubnetId)
+			originalNetworkAclID, ok := isolation.OriginalNetworkAclIds[subnetID]
+			if !ok {
+				continue
+			}
+
+			logger.Logf(t, "Restoring subnet %s to network ACL %s", subnetID, originalNetworkAclID)
+			_, err := client.ReplaceNetworkAclAssociation(&ec2.ReplaceNetworkAclAssociationInput{
+				AssociationId: association.NetworkAclAssociationId,
+				NetworkAclId:  aws.String(originalNetworkAclID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	logger.Logf(t, "Deleting network ACL %s", isolation.NetworkAclId)
+	_, err = client.DeleteNetworkAcl(&ec2.DeleteNetworkAclInput{NetworkAclId: aws.String(isolation.NetworkAclId)})
+	return err
+}
+
+// getNetworkAclAssociationsE returns the current network ACL association of each of the given subnets, keyed by subnet ID.
+func getNetworkAclAssociationsE(client *ec2.EC2, subnetIDs []string) (map[string]*ec2.NetworkAclAssociation, error) {
+	subnetIDFilter := ec2.Filter{Name: aws.String("association.subnet-id"), Values: aws.StringSlice(subnetIDs)}
+	output, err := client.DescribeNetworkAcls(&ec2.DescribeNetworkAclsInput{Filters: []*ec2.Filter{&subnetIDFilter}})
+	if err != nil {
+		return nil, err
+	}
+
+	associations := map[string]*ec2.NetworkAclAssociation{}
+	for _, networkAcl := range output.NetworkAcls {
+		for _, association := range networkAcl.Associations {
+			associations[aws.StringValue(association.SubnetId)] = association
+		}
+	}
+
+	for _, subnetID := range subnetIDs {
+		if _, ok := associations[subnetID]; !ok {
+			return nil, fmt.Errorf("Could not find the network ACL association of subnet %s", subnetID)
+		}
+	}
+
+	return associations, nil
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/proton"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ProtonEnvironmentOptions are the options to create a Proton environment from an environment template.
+type ProtonEnvironmentOptions struct {
+	Name                 string
+	TemplateName         string
+	TemplateMajorVersion string
+	TemplateMinorVersion string // Defaults to the recommended minor version of the major version
+	Spec                 string // The YAML spec with the input values of the template
+	ProtonServiceRoleArn string
+}
+
+// CreateProtonEnvironment creates a Proton environment from the given environment template and waits for its
+// deployment to succeed. This version of the Proton API does not expose the outputs of environments, so read them
+// from the CloudFormation stack Proton provisioned with GetCloudFormationStackOutputs.
+func CreateProtonEnvironment(t testing.TestingT, region string, options ProtonEnvironmentOptions, maxRetries int, sleepBetweenRetries time.Duration) *proton.Environment {
+	environment, err := CreateProtonEnvironmentE(t, region, options, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return environment
+}
+
+// CreateProtonEnvironmentE creates a Proton environment from the given environment template and waits for its
+// deployment to succeed. This version of the Proton API does not expose the outputs of environments, so read them
+// from the CloudFormation stack Proton provisioned with GetCloudFormationStackOutputsE.
+func CreateProtonEnvironmentE(t testing.TestingT, region string, options ProtonEnvironmentOptions, maxRetries int, sleepBetweenRetries time.Duration) (*proton.Environment, error) {
+	client, err := NewProtonClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &proton.CreateEnvironmentInput{
+		Name:                 aws.String(options.Name),
+		TemplateName:         aws.String(options.TemplateName),
+		TemplateMajorVersion: aws.String(options.TemplateMajorVersion),
+		Spec:                 aws.String(options.Spec),
+	}
+	if options.TemplateMinorVersion != "" {
+		input.TemplateMinorVersion = aws.String(options.TemplateMinorVersion)
+	}
+	if options.ProtonServiceRoleArn != "" {
+		input.ProtonServiceRoleArn = aws.String(options.ProtonServiceRoleArn)
+	}
+
+	logger.Logf(t, "Creating Proton environment %s from template %s version %s", options.Name, options.TemplateName, options.TemplateMajorVersion)
+	if _, err := client.CreateEnvironment(input); err != nil {
+		return nil, err
+	}
+
+	return WaitForProtonEnvironmentDeployedE(t, region, options.Name, maxRetries, sleepBetweenRetries)
+}
+
+// WaitForProtonEnvironmentDeployed waits for the latest deployment of the given Proton environment to succeed, and
+// returns the environment.
+func WaitForProtonEnvironmentDeployed(t testing.TestingT, region string, name string, maxRetries int, sleepBetweenRetries time.Duration) *proton.Environment {
+	environment, err := WaitForProtonEnvironmentDeployedE(t, region, name, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return environment
+}
+
+// WaitForProtonEnvironmentDeployedE waits for the latest deployment of the given Proton environment to succeed, and
+// returns the environment. A ProtonDeploymentFailed error is returned as soon as the deployment fails.
+func WaitForProtonEnvironmentDeployedE(t testing.TestingT, region string, name string, maxRetries int, sleepBetweenRetries time.Duration) (*proton.Environment, error) {
+	client, err := NewProtonClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var environment *proton.Environment
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("Proton environment %s", name),
+		DesiredState: proton.DeploymentStatusSucceeded,
+		Extract: func() (WaitState, error) {
+			output, err := client.GetEnvironment(&proton.GetEnvironmentInput{Name: aws.String(name)})
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			status := aws.StringValue(output.Environment.DeploymentStatus)
+			switch status {
+			case proton.DeploymentStatusSucceeded:
+				environment = output.Environment
+				return WaitState{Current: status, Done: true}, nil
+			case proton.DeploymentStatusInProgress:
+				return WaitState{Current: status}, nil
+			default:
+				return WaitState{}, retry.FatalError{Underlying: ProtonDeploymentFailed{
+					EnvironmentName: name,
+					Status:          status,
+					Message:         aws.StringValue(output.Environment.DeploymentStatusMessage),
+				}}
+			}
+		},
+	}
+
+	err = WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	return environment, err
+}
+
+// DeleteProtonEnvironment deletes the given Proton environment, which destroys the resources it provisioned, and
+// waits for the deletion to complete.
+func DeleteProtonEnvironment(t testing.TestingT, region string, name string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := DeleteProtonEnvironmentE(t, region, name, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// DeleteProtonEnvironmentE deletes the given Proton environment, which destroys the resources it provisioned, and
+// waits for the deletion to complete.
+func DeleteProtonEnvironmentE(t testing.TestingT, region string, name string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	if dryrun.Skip(t, "delete Proton environment %s in %s", name, region) {
+		return nil
+	}
+
+	client, err := NewProtonClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Deleting Proton environment %s", name)
+	if _, err := client.DeleteEnvironment(&proton.DeleteEnvironmentInput{Name: aws.String(name)}); err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("Waiting for Proton environment %s to be deleted", name)
+	_, err = retry.DoWithRetryE(t, description, maxRetries, sleepBetweenRetries, func() (string, error) {
+		output, err := client.GetEnvironment(&proton.GetEnvironmentInput{Name: aws.String(name)})
+		if err != nil {
+			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == proton.ErrCodeResourceNotFoundException {
+				return "Deleted", nil
+			}
+			return "", err
+		}
+
+		status := aws.StringValue(output.Environment.DeploymentStatus)
+		switch status {
+		case proton.DeploymentStatusDeleteComplete:
+			return status, nil
+		case proton.DeploymentStatusDeleteFailed:
+			return "", retry.FatalError{Underlying: ProtonDeploymentFailed{
+				EnvironmentName: name,
+				Status:          status,
+				Message:         aws.StringValue(output.Environment.DeploymentStatusMessage),
+			}}
+		default:
+			return "", fmt.Errorf("Proton environment %s has deployment status %s", name, status)
+		}
+	})
+
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return actualErr.Underlying
+	}
+	return err
+}
+
+// NewProtonClient creates a Proton client.
+func NewProtonClient(t testing.TestingT, region string) *proton.Proton {
+	client, err := NewProtonClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewProtonClientE creates a Proton client.
+func NewProtonClientE(t testing.TestingT, region string) (*proton.Proton, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return proton.New(sess), nil
+}
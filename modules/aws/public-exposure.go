@@ -0,0 +1,246 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+var publicCidrs = map[string]bool{
+	"0.0.0.0/0": true,
+	"::/0":      true,
+}
+
+// PublicExposure describes a security group rule that allows traffic from the whole internet to a network interface
+// with a public address.
+type PublicExposure struct {
+	NetworkInterfaceId string
+	PublicIp           string // The public IPv4 or IPv6 address of the network interface
+	SecurityGroupId    string
+	Protocol           string // "-1" means all protocols
+	FromPort           int64
+	ToPort             int64
+	Cidr               string
+}
+
+func (exposure PublicExposure) String() string {
+	return fmt.Sprintf(
+		"%s (%s) via %s: protocol %s ports %d-%d from %s",
+		exposure.NetworkInterfaceId,
+		exposure.PublicIp,
+		exposure.SecurityGroupId,
+		exposure.Protocol,
+		exposure.FromPort,
+		exposure.ToPort,
+		exposure.Cidr,
+	)
+}
+
+// FindPubliclyExposedResources scans the network interfaces and Elastic IPs tagged with the given tag, as well as
+// the network interfaces of EC2 Instances tagged with it, and returns every security group rule that allows traffic
+// from 0.0.0.0/0 or ::/0 to one of them while it has a public address. Rules that only open a single port in
+// allowedPorts (e.g. 443 for a public load balancer) are not reported.
+func FindPubliclyExposedResources(t testing.TestingT, region string, tagName string, tagValue string, allowedPorts []int64) []PublicExposure {
+	exposures, err := FindPubliclyExposedResourcesE(t, region, tagName, tagValue, allowedPorts)
+	require.NoError(t, err)
+	return exposures
+}
+
+// FindPubliclyExposedResourcesE scans the network interfaces and Elastic IPs tagged with the given tag, as well as
+// the network interfaces of EC2 Instances tagged with it, and returns every security group rule that allows traffic
+// from 0.0.0.0/0 or ::/0 to one of them while it has a public address. Rules that only open a single port in
+// allowedPorts (e.g. 443 for a public load balancer) are not reported.
+func FindPubliclyExposedResourcesE(t testing.TestingT, region string, tagName string, tagValue string, allowedPorts []int64) ([]PublicExposure, error) {
+	logger.Logf(t, "Looking for publicly exposed resources tagged with %s=%s in %s", tagName, tagValue, region)
+
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	tagFilter := &ec2.Filter{Name: aws.String(fmt.Sprintf("tag:%s", tagName)), Values: aws.StringSlice([]string{tagValue})}
+	eniIDs := map[string]bool{}
+
+	instanceIDs, err := GetEc2InstanceIdsByTagE(t, region, tagName, tagValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(instanceIDs) > 0 {
+		attachmentFilter := &ec2.Filter{Name: aws.String("attachment.instance-id"), Values: aws.StringSlice(instanceIDs)}
+		if err := collectNetworkInterfaceIds(client, attachmentFilter, eniIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := collectNetworkInterfaceIds(client, tagFilter, eniIDs); err != nil {
+		return nil, err
+	}
+
+	addresses, err := client.DescribeAddresses(&ec2.DescribeAddressesInput{Filters: []*ec2.Filter{tagFilter}})
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses.Addresses {
+		if address.NetworkInterfaceId != nil {
+			eniIDs[aws.StringValue(address.NetworkInterfaceId)] = true
+		}
+	}
+
+	if len(eniIDs) == 0 {
+		return []PublicExposure{}, nil
+	}
+
+	enis := []*ec2.NetworkInterface{}
+	input := &ec2.DescribeNetworkInterfacesInput{NetworkInterfaceIds: aws.StringSlice(mapKeys(eniIDs))}
+	err = client.DescribeNetworkInterfacesPages(input, func(page *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+		enis = append(enis, page.NetworkInterfaces...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := map[string]bool{}
+	for _, eni := range enis {
+		for _, group := range eni.Groups {
+			groupIDs[aws.StringValue(group.GroupId)] = true
+		}
+	}
+
+	securityGroups := map[string]*ec2.SecurityGroup{}
+	if len(groupIDs) > 0 {
+		groups, err := client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{GroupIds: aws.StringSlice(mapKeys(groupIDs))})
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups.SecurityGroups {
+			securityGroups[aws.StringValue(group.GroupId)] = group
+		}
+	}
+
+	return findPublicExposures(enis, securityGroups, allowedPorts), nil
+}
+
+// AssertNoPubliclyExposedResources checks that none of the resources tagged with the given tag are reachable from the
+// whole internet on ports other than allowedPorts. See FindPubliclyExposedResources for details.
+func AssertNoPubliclyExposedResources(t testing.TestingT, region string, tagName string, tagValue string, allowedPorts []int64) {
+	err := AssertNoPubliclyExposedResourcesE(t, region, tagName, tagValue, allowedPorts)
+	require.NoError(t, err)
+}
+
+// AssertNoPubliclyExposedResourcesE checks that none of the resources tagged with the given tag are reachable from the
+// whole internet on ports other than allowedPorts, and returns an error listing every exposure found otherwise. See
+// FindPubliclyExposedResourcesE for details.
+func AssertNoPubliclyExposedResourcesE(t testing.TestingT, region string, tagName string, tagValue string, allowedPorts []int64) error {
+	exposures, err := FindPubliclyExposedResourcesE(t, region, tagName, tagValue, allowedPorts)
+	if err != nil {
+		return err
+	}
+
+	if len(exposures) > 0 {
+		descriptions := []string{}
+		for _, exposure := range exposures {
+			descriptions = append(descriptions, exposure.String())
+		}
+		return fmt.Errorf("Found %d publicly exposed resources in %s:\n%s", len(exposures), region, strings.Join(descriptions, "\n"))
+	}
+
+	return nil
+}
+
+// findPublicExposures returns the rules of the given security groups that allow traffic from the whole internet to
+// those of the given network interfaces that have a public address.
+func findPublicExposures(enis []*ec2.NetworkInterface, securityGroups map[string]*ec2.SecurityGroup, allowedPorts []int64) []PublicExposure {
+	allowed := map[int64]bool{}
+	for _, port := range allowedPorts {
+		allowed[port] = true
+	}
+
+	exposures := []PublicExposure{}
+
+	for _, eni := range enis {
+		publicIP := getPublicAddressOfNetworkInterface(eni)
+		if publicIP == "" {
+			continue
+		}
+
+		for _, groupRef := range eni.Groups {
+			group, ok := securityGroups[aws.StringValue(groupRef.GroupId)]
+			if !ok {
+				continue
+			}
+
+			for _, permission := range group.IpPermissions {
+				fromPort := aws.Int64Value(permission.FromPort)
+				toPort := aws.Int64Value(permission.ToPort)
+				protocol := aws.StringValue(permission.IpProtocol)
+
+				if protocol != "-1" && fromPort == toPort && allowed[fromPort] {
+					continue
+				}
+
+				cidrs := []string{}
+				for _, ipRange := range permission.IpRanges {
+					cidrs = append(cidrs, aws.StringValue(ipRange.CidrIp))
+				}
+				for _, ipv6Range := range permission.Ipv6Ranges {
+					cidrs = append(cidrs, aws.StringValue(ipv6Range.CidrIpv6))
+				}
+
+				for _, cidr := range cidrs {
+					if publicCidrs[cidr] {
+						exposures = append(exposures, PublicExposure{
+							NetworkInterfaceId: aws.StringValue(eni.NetworkInterfaceId),
+							PublicIp:           publicIP,
+							SecurityGroupId:    aws.StringValue(group.GroupId),
+							Protocol:           protocol,
+							FromPort:           fromPort,
+							ToPort:             toPort,
+							Cidr:               cidr,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return exposures
+}
+
+// getPublicAddressOfNetworkInterface returns the public IPv4 address of the given network interface or, if it has
+// none, its first IPv6 address, since those are always publicly routable. An empty string is returned if the network
+// interface has no public address.
+func getPublicAddressOfNetworkInterface(eni *ec2.NetworkInterface) string {
+	if eni.Association != nil && aws.StringValue(eni.Association.PublicIp) != "" {
+		return aws.StringValue(eni.Association.PublicIp)
+	}
+	if len(eni.Ipv6Addresses) > 0 {
+		return aws.StringValue(eni.Ipv6Addresses[0].Ipv6Address)
+	}
+	return ""
+}
+
+// collectNetworkInterfaceIds adds the IDs of all the network interfaces matching the given filter to eniIDs.
+func collectNetworkInterfaceIds(client *ec2.EC2, filter *ec2.Filter, eniIDs map[string]bool) error {
+	input := &ec2.DescribeNetworkInterfacesInput{Filters: []*ec2.Filter{filter}}
+	return client.DescribeNetworkInterfacesPages(input, func(page *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+		for _, eni := range page.NetworkInterfaces {
+			eniIDs[aws.StringValue(eni.NetworkInterfaceId)] = true
+		}
+		return true
+	})
+}
+
+// mapKeys returns the keys of the given set.
+func mapKeys(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	return keys
+}
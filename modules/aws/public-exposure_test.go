@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindPublicExposures(t *testing.T) {
+	t.Parallel()
+
+	publicEni := &ec2.NetworkInterface{
+		NetworkInterfaceId: aws.String("eni-public"),
+		Association:        &ec2.NetworkInterfaceAssociation{PublicIp: aws.String("54.1.2.3")},
+		Groups:             []*ec2.GroupIdentifier{{GroupId: aws.String("sg-1")}},
+	}
+	privateEni := &ec2.NetworkInterface{
+		NetworkInterfaceId: aws.String("eni-private"),
+		Groups:             []*ec2.GroupIdentifier{{GroupId: aws.String("sg-1")}},
+	}
+
+	securityGroups := map[string]*ec2.SecurityGroup{
+		"sg-1": {
+			GroupId: aws.String("sg-1"),
+			IpPermissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(443),
+					ToPort:     aws.Int64(443),
+					IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+				},
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(22),
+					ToPort:     aws.Int64(22),
+					IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}, {CidrIp: aws.String("10.0.0.0/8")}},
+				},
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(5432),
+					ToPort:     aws.Int64(5432),
+					IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("10.0.0.0/8")}},
+				},
+			},
+		},
+	}
+
+	exposures := findPublicExposures([]*ec2.NetworkInterface{publicEni, privateEni}, securityGroups, []int64{443})
+
+	expected := []PublicExposure{
+		{
+			NetworkInterfaceId: "eni-public",
+			PublicIp:           "54.1.2.3",
+			SecurityGroupId:    "sg-1",
+			Protocol:           "tcp",
+			FromPort:           22,
+			ToPort:             22,
+			Cidr:               "0.0.0.0/0",
+		},
+	}
+	assert.Equal(t, expected, exposures)
+}
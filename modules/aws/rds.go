@@ -3,11 +3,17 @@ package aws
 import (
 	"database/sql"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rds"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -199,6 +205,200 @@ func GetRdsInstanceDetailsE(t testing.TestingT, dbInstanceID string, awsRegion s
 	return output.DBInstances[0], nil
 }
 
+// RdsEndpoint is the address and port to connect to an RDS instance on.
+type RdsEndpoint struct {
+	Address string
+	Port    int64
+}
+
+// GetRdsEndpoint gets the endpoint of the RDS instance with the given ID. This will fail the test if there is an error.
+func GetRdsEndpoint(t testing.TestingT, dbInstanceID string, awsRegion string) RdsEndpoint {
+	endpoint, err := GetRdsEndpointE(t, dbInstanceID, awsRegion)
+	require.NoError(t, err)
+	return endpoint
+}
+
+// GetRdsEndpointE gets the endpoint of the RDS instance with the given ID. Returns an RdsInstanceNotAvailable error if
+// the instance has no endpoint yet, e.g. because it is still being created.
+func GetRdsEndpointE(t testing.TestingT, dbInstanceID string, awsRegion string) (RdsEndpoint, error) {
+	dbInstance, err := GetRdsInstanceDetailsE(t, dbInstanceID, awsRegion)
+	if err != nil {
+		return RdsEndpoint{}, err
+	}
+	if dbInstance.Endpoint == nil {
+		return RdsEndpoint{}, RdsInstanceNotAvailable{DbInstanceID: dbInstanceID, Status: aws.StringValue(dbInstance.DBInstanceStatus)}
+	}
+	return RdsEndpoint{Address: aws.StringValue(dbInstance.Endpoint.Address), Port: aws.Int64Value(dbInstance.Endpoint.Port)}, nil
+}
+
+// The statuses of RDS instances that don't become available without someone stepping in.
+var rdsInstanceFailedStatuses = map[string]bool{
+	"deleting":                            true,
+	"failed":                              true,
+	"inaccessible-encryption-credentials": true,
+	"incompatible-network":                true,
+	"incompatible-option-group":           true,
+	"incompatible-parameters":             true,
+	"incompatible-restore":                true,
+	"storage-full":                        true,
+}
+
+// WaitForRdsInstanceAvailable waits until the RDS instance with the given ID is available. This will fail the test if
+// it does not.
+func WaitForRdsInstanceAvailable(t testing.TestingT, dbInstanceID string, awsRegion string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForRdsInstanceAvailableE(t, dbInstanceID, awsRegion, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForRdsInstanceAvailableE waits until the RDS instance with the given ID is available, e.g. after it is created or
+// modified with apply_immediately. Returns an RdsInstanceNotAvailable error as soon as the instance is in a state it
+// does not recover from by itself, such as incompatible-parameters or storage-full.
+func WaitForRdsInstanceAvailableE(t testing.TestingT, dbInstanceID string, awsRegion string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("RDS instance %s", dbInstanceID),
+		DesiredState: "available",
+		Extract: func() (WaitState, error) {
+			dbInstance, err := GetRdsInstanceDetailsE(t, dbInstanceID, awsRegion)
+			if err != nil {
+				return WaitState{}, err
+			}
+			return rdsInstanceWaitState(dbInstance)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// rdsInstanceWaitState returns the state of the given RDS instance for WaitForRdsInstanceAvailableE. Returns a
+// retry.FatalError if the instance will not become available.
+func rdsInstanceWaitState(dbInstance *rds.DBInstance) (WaitState, error) {
+	status := aws.StringValue(dbInstance.DBInstanceStatus)
+	if rdsInstanceFailedStatuses[status] {
+		return WaitState{}, retry.FatalError{Underlying: RdsInstanceNotAvailable{
+			DbInstanceID: aws.StringValue(dbInstance.DBInstanceIdentifier),
+			Status:       status,
+		}}
+	}
+	return WaitState{Current: status, Done: status == "available"}, nil
+}
+
+// RdsDatabaseDriver is a database/sql driver to connect to an RDS instance with. Use RdsMySql or RdsPostgres, or
+// implement it for another engine, e.g. SQL Server, whose driver the test then has to import.
+type RdsDatabaseDriver interface {
+	// DriverName returns the name the driver is registered with in database/sql, e.g. "mysql".
+	DriverName() string
+
+	// DataSourceName returns the data source name to connect to the given database with.
+	DataSourceName(connection RdsConnection) string
+}
+
+// RdsConnection is what is needed to connect to a database on an RDS instance.
+type RdsConnection struct {
+	Endpoint RdsEndpoint
+	Username string
+	Password string
+	Database string // The database to connect to. If empty, the default database of the user is used.
+}
+
+var (
+	// RdsMySql connects to MySQL and MariaDB instances, including Aurora MySQL, with github.com/go-sql-driver/mysql.
+	// Queries use ? placeholders.
+	RdsMySql RdsDatabaseDriver = rdsMySqlDriver{}
+
+	// RdsPostgres connects to PostgreSQL instances, including Aurora PostgreSQL, with github.com/lib/pq over TLS.
+	// Queries use $1, $2... placeholders.
+	RdsPostgres RdsDatabaseDriver = rdsPostgresDriver{}
+)
+
+type rdsMySqlDriver struct{}
+
+func (rdsMySqlDriver) DriverName() string {
+	return "mysql"
+}
+
+func (rdsMySqlDriver) DataSourceName(connection RdsConnection) string {
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s", connection.Username, connection.Password, rdsHostPort(connection.Endpoint), connection.Database)
+}
+
+type rdsPostgresDriver struct{}
+
+func (rdsPostgresDriver) DriverName() string {
+	return "postgres"
+}
+
+func (rdsPostgresDriver) DataSourceName(connection RdsConnection) string {
+	dataSourceName := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(connection.Username, connection.Password),
+		Host:     rdsHostPort(connection.Endpoint),
+		Path:     "/" + connection.Database,
+		RawQuery: "sslmode=require",
+	}
+	return dataSourceName.String()
+}
+
+func rdsHostPort(endpoint RdsEndpoint) string {
+	return net.JoinHostPort(endpoint.Address, strconv.FormatInt(endpoint.Port, 10))
+}
+
+// RdsQueryResult is the result of a query run by ConnectToRdsAndRunQueryE.
+type RdsQueryResult struct {
+	Columns []string
+	Rows    [][]string // The values of each row, in the order of Columns. NULL values are empty strings.
+}
+
+// ConnectToRdsAndRunQuery connects to a database on an RDS instance with the given driver, runs the given query with
+// the given arguments and returns its result. This will fail the test if there is an error.
+func ConnectToRdsAndRunQuery(t testing.TestingT, driver RdsDatabaseDriver, connection RdsConnection, query string, args ...interface{}) RdsQueryResult {
+	result, err := ConnectToRdsAndRunQueryE(t, driver, connection, query, args...)
+	require.NoError(t, err)
+	return result
+}
+
+// ConnectToRdsAndRunQueryE connects to a database on an RDS instance with the given driver, runs the given query with
+// the given arguments and returns its result with all values as strings, e.g. to assert that a migration created the
+// expected schema right after terraform apply:
+//
+//	result, err := aws.ConnectToRdsAndRunQueryE(t, aws.RdsPostgres, connection, "SELECT table_name FROM information_schema.tables WHERE table_schema = $1", "app")
+//
+// Statements that return no rows, such as inserts, return a result without columns.
+func ConnectToRdsAndRunQueryE(t testing.TestingT, driver RdsDatabaseDriver, connection RdsConnection, query string, args ...interface{}) (RdsQueryResult, error) {
+	db, err := sql.Open(driver.DriverName(), driver.DataSourceName(connection))
+	if err != nil {
+		return RdsQueryResult{}, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return RdsQueryResult{}, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return RdsQueryResult{}, err
+	}
+
+	result := RdsQueryResult{Columns: columns, Rows: [][]string{}}
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		scanArgs := make([]interface{}, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return RdsQueryResult{}, err
+		}
+
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = value.String
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
 // NewRdsClient creates an RDS client.
 func NewRdsClient(t testing.TestingT, region string) *rds.RDS {
 	client, err := NewRdsClientE(t, region)
@@ -299,3 +499,19 @@ type OptionGroupOptionSettingForDbInstanceNotFound struct {
 func (err OptionGroupOptionSettingForDbInstanceNotFound) Error() string {
 	return fmt.Sprintf("Could not find a option setting %s in option name %s of database %s in %s", err.OptionName, err.OptionSettingName, err.DbInstanceID, err.AwsRegion)
 }
+
+// RdsInstanceNotAvailable is an error that occurs when an RDS instance is not available, and either has no endpoint yet
+// or is in a state it does not recover from by itself
+type RdsInstanceNotAvailable struct {
+	DbInstanceID string
+	Status       string
+}
+
+func (err RdsInstanceNotAvailable) Error() string {
+	return fmt.Sprintf("RDS instance %s is not available: its status is %s", err.DbInstanceID, err.Status)
+}
+
+// Is returns true if the target is ErrOperationFailed.
+func (err RdsInstanceNotAvailable) Is(target error) bool {
+	return target == ErrOperationFailed
+}
@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ReplicationPollInterval is how often the replication helpers check whether a write has reached the replica region.
+const ReplicationPollInterval = 2 * time.Second
+
+// VerifyDynamoDBGlobalTableReplication writes the given item into the given global table in the source region, waits
+// up to the given timeout for it to appear in the replica region, and returns the replication latency. This will fail
+// the test if the item does not replicate in time or if there is an error.
+func VerifyDynamoDBGlobalTableReplication(t testing.TestingT, sourceRegion string, replicaRegion string, tableName string, item map[string]*dynamodb.AttributeValue, timeout time.Duration) time.Duration {
+	latency, err := VerifyDynamoDBGlobalTableReplicationE(t, sourceRegion, replicaRegion, tableName, item, timeout)
+	require.NoError(t, err)
+	return latency
+}
+
+// VerifyDynamoDBGlobalTableReplicationE writes the given item into the given global table in the source region, waits
+// up to the given timeout for it to appear in the replica region, and returns the replication latency. The item is
+// left in the table.
+func VerifyDynamoDBGlobalTableReplicationE(t testing.TestingT, sourceRegion string, replicaRegion string, tableName string, item map[string]*dynamodb.AttributeValue, timeout time.Duration) (time.Duration, error) {
+	table, err := GetDynamoDBTableE(t, sourceRegion, tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := getDynamoDBItemKey(table.KeySchema, item)
+	if err != nil {
+		return 0, err
+	}
+
+	sourceClient, err := NewDynamoDBClientE(t, sourceRegion)
+	if err != nil {
+		return 0, err
+	}
+
+	replicaClient, err := NewDynamoDBClientE(t, replicaRegion)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Logf(t, "Writing item into DynamoDB table %s in %s", tableName, sourceRegion)
+	if _, err := sourceClient.PutItem(&dynamodb.PutItemInput{TableName: aws.String(tableName), Item: item}); err != nil {
+		return 0, err
+	}
+
+	description := fmt.Sprintf("item of DynamoDB table %s", tableName)
+	return waitForReplicationE(t, description, replicaRegion, timeout, func() error {
+		output, err := replicaClient.GetItem(&dynamodb.GetItemInput{TableName: aws.String(tableName), Key: key})
+		if err != nil {
+			return err
+		}
+		if !dynamoDBItemContains(output.Item, item) {
+			return fmt.Errorf("item has not replicated to %s yet", replicaRegion)
+		}
+		return nil
+	})
+}
+
+// VerifyS3Replication uploads an object with the given key and contents into the source bucket, waits up to the given
+// timeout for it to appear in the replica bucket, and returns the replication latency. This will fail the test if the
+// object does not replicate in time, if S3 reports the replication as failed or if there is an error.
+func VerifyS3Replication(t testing.TestingT, sourceRegion string, sourceBucket string, replicaRegion string, replicaBucket string, key string, contents string, timeout time.Duration) time.Duration {
+	latency, err := VerifyS3ReplicationE(t, sourceRegion, sourceBucket, replicaRegion, replicaBucket, key, contents, timeout)
+	require.NoError(t, err)
+	return latency
+}
+
+// VerifyS3ReplicationE uploads an object with the given key and contents into the source bucket, waits up to the given
+// timeout for it to appear in the replica bucket, and returns the replication latency. The object is left in both
+// buckets.
+func VerifyS3ReplicationE(t testing.TestingT, sourceRegion string, sourceBucket string, replicaRegion string, replicaBucket string, key string, contents string, timeout time.Duration) (time.Duration, error) {
+	sourceClient, err := NewS3ClientE(t, sourceRegion)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Logf(t, "Uploading s3://%s/%s in %s", sourceBucket, key, sourceRegion)
+	_, err = sourceClient.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(sourceBucket),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(contents),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	description := fmt.Sprintf("s3://%s/%s", sourceBucket, key)
+	return waitForReplicationE(t, description, replicaRegion, timeout, func() error {
+		// S3 records the outcome of the replication on the source object, which tells a failure from a delay
+		head, err := sourceClient.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(sourceBucket), Key: aws.String(key)})
+		if err != nil {
+			return err
+		}
+		if aws.StringValue(head.ReplicationStatus) == s3.ReplicationStatusFailed {
+			return retry.FatalError{Underlying: S3ReplicationFailed{Bucket: sourceBucket, Key: key}}
+		}
+
+		replicaContents, err := GetS3ObjectContentsE(t, replicaRegion, replicaBucket, key)
+		if err != nil {
+			return err
+		}
+		if replicaContents != contents {
+			return fmt.Errorf("s3://%s/%s does not have the uploaded contents yet", replicaBucket, key)
+		}
+		return nil
+	})
+}
+
+// waitForReplicationE calls the given check every ReplicationPollInterval until it succeeds or the given timeout
+// expires, and returns how long it took to succeed.
+func waitForReplicationE(t testing.TestingT, description string, replicaRegion string, timeout time.Duration, check func() error) (time.Duration, error) {
+	start := time.Now()
+	retries := int(timeout / ReplicationPollInterval)
+
+	_, err := retry.DoWithRetryE(t, fmt.Sprintf("Waiting for %s to replicate to %s", description, replicaRegion), retries, ReplicationPollInterval, func() (string, error) {
+		return "", check()
+	})
+	if err != nil {
+		if actualErr, ok := err.(retry.FatalError); ok {
+			return 0, actualErr.Underlying
+		}
+		if _, ok := err.(retry.MaxRetriesExceeded); ok {
+			return 0, ReplicationTimedOut{Description: description, Region: replicaRegion, Timeout: timeout}
+		}
+		return 0, err
+	}
+
+	latency := time.Since(start)
+	logger.Logf(t, "Replicated %s to %s in %s", description, replicaRegion, latency)
+	return latency, nil
+}
+
+// getDynamoDBItemKey returns the attributes of the given item that make up its primary key in the given key schema.
+func getDynamoDBItemKey(keySchema []*dynamodb.KeySchemaElement, item map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	key := map[string]*dynamodb.AttributeValue{}
+	for _, element := range keySchema {
+		name := aws.StringValue(element.AttributeName)
+		value, ok := item[name]
+		if !ok {
+			return nil, fmt.Errorf("item is missing key attribute %s", name)
+		}
+		key[name] = value
+	}
+	return key, nil
+}
+
+// dynamoDBItemContains returns true if the given item has all the attributes of the expected item with the same values.
+// Global tables may add their own attributes, such as aws:rep:updateregion, to replicated items.
+func dynamoDBItemContains(item map[string]*dynamodb.AttributeValue, expected map[string]*dynamodb.AttributeValue) bool {
+	if len(item) == 0 {
+		return false
+	}
+	for name, value := range expected {
+		if !reflect.DeepEqual(item[name], value) {
+			return false
+		}
+	}
+	return true
+}
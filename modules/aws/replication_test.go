@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDynamoDBItemKey(t *testing.T) {
+	t.Parallel()
+
+	keySchema := []*dynamodb.KeySchemaElement{
+		{AttributeName: aws.String("pk"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+		{AttributeName: aws.String("sk"), KeyType: aws.String(dynamodb.KeyTypeRange)},
+	}
+	item := map[string]*dynamodb.AttributeValue{
+		"pk":    {S: aws.String("user#1")},
+		"sk":    {S: aws.String("profile")},
+		"email": {S: aws.String("test@example.com")},
+	}
+
+	key, err := getDynamoDBItemKey(keySchema, item)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{"pk": item["pk"], "sk": item["sk"]}, key)
+
+	_, err = getDynamoDBItemKey(keySchema, map[string]*dynamodb.AttributeValue{"pk": item["pk"]})
+	assert.Error(t, err)
+}
+
+func TestDynamoDBItemContainsIgnoresReplicationAttributes(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]*dynamodb.AttributeValue{"pk": {S: aws.String("user#1")}, "count": {N: aws.String("3")}}
+	replicated := map[string]*dynamodb.AttributeValue{
+		"pk":                   {S: aws.String("user#1")},
+		"count":                {N: aws.String("3")},
+		"aws:rep:updateregion": {S: aws.String("us-east-1")},
+	}
+
+	assert.True(t, dynamoDBItemContains(replicated, expected))
+	assert.False(t, dynamoDBItemContains(map[string]*dynamodb.AttributeValue{"pk": {S: aws.String("user#1")}}, expected))
+	assert.False(t, dynamoDBItemContains(nil, expected))
+}
+
+func TestWaitForReplicationE(t *testing.T) {
+	t.Parallel()
+
+	latency, err := waitForReplicationE(t, "test item", "us-west-2", time.Minute, func() error { return nil })
+	require.NoError(t, err)
+	assert.True(t, latency < ReplicationPollInterval)
+
+	_, err = waitForReplicationE(t, "test item", "us-west-2", 0, func() error { return errors.New("not yet") })
+	assert.Equal(t, ReplicationTimedOut{Description: "test item", Region: "us-west-2", Timeout: 0}, err)
+
+	failed := S3ReplicationFailed{Bucket: "source", Key: "test"}
+	_, err = waitForReplicationE(t, "test item", "us-west-2", time.Minute, func() error { return retry.FatalError{Underlying: failed} })
+	assert.Equal(t, failed, err)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
@@ -209,6 +210,10 @@ func DeleteS3Bucket(t testing.TestingT, region string, name string) {
 
 // DeleteS3BucketE destroys the S3 bucket in the given region with the given name.
 func DeleteS3BucketE(t testing.TestingT, region string, name string) error {
+	if dryrun.Skip(t, "delete bucket %s in %s", name, region) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting bucket %s in %s", region, name)
 
 	s3Client, err := NewS3ClientE(t, region)
@@ -231,6 +236,10 @@ func EmptyS3Bucket(t testing.TestingT, region string, name string) {
 
 // EmptyS3BucketE removes the contents of an S3 bucket in the given region with the given name.
 func EmptyS3BucketE(t testing.TestingT, region string, name string) error {
+	if dryrun.Skip(t, "empty bucket %s in %s", name, region) {
+		return nil
+	}
+
 	logger.Logf(t, "Emptying bucket %s in %s", name, region)
 
 	s3Client, err := NewS3ClientE(t, region)
@@ -3,6 +3,7 @@ package aws
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
@@ -65,6 +66,10 @@ func DeleteSecret(t testing.TestingT, awsRegion, id string, forceDelete bool) {
 
 // DeleteSecretE deletes a secret. If forceDelete is true, the secret will be deleted after a short delay. If forceDelete is false, the secret will be deleted after a 30 day recovery window.
 func DeleteSecretE(t testing.TestingT, awsRegion, id string, forceDelete bool) error {
+	if dryrun.Skip(t, "delete secret %s in %s", id, awsRegion) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting secret with ID %s", id)
 
 	client := NewSecretsManagerClient(t, awsRegion)
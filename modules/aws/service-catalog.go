@@ -0,0 +1,212 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ServiceCatalogProvisionOptions selects the Service Catalog product, version and launch path to provision, along
+// with its parameters. Either the ID or the name of the product and provisioning artifact must be set. If the product
+// has a single launch path, PathId can be left empty.
+type ServiceCatalogProvisionOptions struct {
+	ProductId                string
+	ProductName              string
+	ProvisioningArtifactId   string // The ID of the product version
+	ProvisioningArtifactName string // The name of the product version
+	PathId                   string
+	ProvisionedProductName   string
+	Parameters               map[string]string
+}
+
+// ProvisionServiceCatalogProduct launches the given Service Catalog product and waits for the provisioning to
+// succeed. It returns the record of the provisioning, whose ProvisionedProductId can be passed to
+// GetServiceCatalogProvisionedProductOutputs and TerminateServiceCatalogProvisionedProduct.
+func ProvisionServiceCatalogProduct(t testing.TestingT, region string, options ServiceCatalogProvisionOptions, maxRetries int, sleepBetweenRetries time.Duration) *servicecatalog.RecordDetail {
+	record, err := ProvisionServiceCatalogProductE(t, region, options, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return record
+}
+
+// ProvisionServiceCatalogProductE launches the given Service Catalog product and waits for the provisioning to
+// succeed. It returns the record of the provisioning, whose ProvisionedProductId can be passed to
+// GetServiceCatalogProvisionedProductOutputsE and TerminateServiceCatalogProvisionedProductE.
+func ProvisionServiceCatalogProductE(t testing.TestingT, region string, options ServiceCatalogProvisionOptions, maxRetries int, sleepBetweenRetries time.Duration) (*servicecatalog.RecordDetail, error) {
+	client, err := NewServiceCatalogClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &servicecatalog.ProvisionProductInput{ProvisionedProductName: aws.String(options.ProvisionedProductName)}
+	if options.ProductId != "" {
+		input.ProductId = aws.String(options.ProductId)
+	}
+	if options.ProductName != "" {
+		input.ProductName = aws.String(options.ProductName)
+	}
+	if options.ProvisioningArtifactId != "" {
+		input.ProvisioningArtifactId = aws.String(options.ProvisioningArtifactId)
+	}
+	if options.ProvisioningArtifactName != "" {
+		input.ProvisioningArtifactName = aws.String(options.ProvisioningArtifactName)
+	}
+	if options.PathId != "" {
+		input.PathId = aws.String(options.PathId)
+	}
+	for key, value := range options.Parameters {
+		input.ProvisioningParameters = append(input.ProvisioningParameters, &servicecatalog.ProvisioningParameter{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	logger.Logf(t, "Provisioning Service Catalog product %s%s as %s", options.ProductId, options.ProductName, options.ProvisionedProductName)
+	output, err := client.ProvisionProduct(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return WaitForServiceCatalogRecordE(t, region, aws.StringValue(output.RecordDetail.RecordId), maxRetries, sleepBetweenRetries)
+}
+
+// WaitForServiceCatalogRecord waits for the given Service Catalog provisioning, update or termination record to
+// succeed, and returns it.
+func WaitForServiceCatalogRecord(t testing.TestingT, region string, recordID string, maxRetries int, sleepBetweenRetries time.Duration) *servicecatalog.RecordDetail {
+	record, err := WaitForServiceCatalogRecordE(t, region, recordID, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return record
+}
+
+// WaitForServiceCatalogRecordE waits for the given Service Catalog provisioning, update or termination record to
+// succeed, and returns it. A ServiceCatalogRecordFailed error is returned as soon as the record fails.
+func WaitForServiceCatalogRecordE(t testing.TestingT, region string, recordID string, maxRetries int, sleepBetweenRetries time.Duration) (*servicecatalog.RecordDetail, error) {
+	client, err := NewServiceCatalogClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var record *servicecatalog.RecordDetail
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("Service Catalog record %s", recordID),
+		DesiredState: servicecatalog.RecordStatusSucceeded,
+		Extract: func() (WaitState, error) {
+			output, err := client.DescribeRecord(&servicecatalog.DescribeRecordInput{Id: aws.String(recordID)})
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			status := aws.StringValue(output.RecordDetail.Status)
+			switch status {
+			case servicecatalog.RecordStatusSucceeded:
+				record = output.RecordDetail
+				return WaitState{Current: status, Done: true}, nil
+			case servicecatalog.RecordStatusFailed, servicecatalog.RecordStatusInProgressInError:
+				return WaitState{}, retry.FatalError{Underlying: newServiceCatalogRecordFailed(output.RecordDetail)}
+			default:
+				return WaitState{Current: status}, nil
+			}
+		},
+	}
+
+	err = WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	return record, err
+}
+
+// GetServiceCatalogProvisionedProductOutputs gets the outputs of the given Service Catalog provisioned product as a
+// map of output key to value.
+func GetServiceCatalogProvisionedProductOutputs(t testing.TestingT, region string, provisionedProductID string) map[string]string {
+	outputs, err := GetServiceCatalogProvisionedProductOutputsE(t, region, provisionedProductID)
+	require.NoError(t, err)
+	return outputs
+}
+
+// GetServiceCatalogProvisionedProductOutputsE gets the outputs of the given Service Catalog provisioned product as a
+// map of output key to value.
+func GetServiceCatalogProvisionedProductOutputsE(t testing.TestingT, region string, provisionedProductID string) (map[string]string, error) {
+	client, err := NewServiceCatalogClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]string{}
+	input := &servicecatalog.GetProvisionedProductOutputsInput{ProvisionedProductId: aws.String(provisionedProductID)}
+	for {
+		page, err := client.GetProvisionedProductOutputs(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, output := range page.Outputs {
+			outputs[aws.StringValue(output.OutputKey)] = aws.StringValue(output.OutputValue)
+		}
+
+		if aws.StringValue(page.NextPageToken) == "" {
+			return outputs, nil
+		}
+		input.PageToken = page.NextPageToken
+	}
+}
+
+// TerminateServiceCatalogProvisionedProduct terminates the given Service Catalog provisioned product, which destroys
+// the resources it created, and waits for the termination to succeed.
+func TerminateServiceCatalogProvisionedProduct(t testing.TestingT, region string, provisionedProductID string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := TerminateServiceCatalogProvisionedProductE(t, region, provisionedProductID, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// TerminateServiceCatalogProvisionedProductE terminates the given Service Catalog provisioned product, which destroys
+// the resources it created, and waits for the termination to succeed.
+func TerminateServiceCatalogProvisionedProductE(t testing.TestingT, region string, provisionedProductID string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	if dryrun.Skip(t, "terminate Service Catalog provisioned product %s in %s", provisionedProductID, region) {
+		return nil
+	}
+
+	client, err := NewServiceCatalogClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Terminating Service Catalog provisioned product %s", provisionedProductID)
+	output, err := client.TerminateProvisionedProduct(&servicecatalog.TerminateProvisionedProductInput{ProvisionedProductId: aws.String(provisionedProductID)})
+	if err != nil {
+		return err
+	}
+
+	_, err = WaitForServiceCatalogRecordE(t, region, aws.StringValue(output.RecordDetail.RecordId), maxRetries, sleepBetweenRetries)
+	return err
+}
+
+// NewServiceCatalogClient creates a Service Catalog client.
+func NewServiceCatalogClient(t testing.TestingT, region string) *servicecatalog.ServiceCatalog {
+	client, err := NewServiceCatalogClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewServiceCatalogClientE creates a Service Catalog client.
+func NewServiceCatalogClientE(t testing.TestingT, region string) (*servicecatalog.ServiceCatalog, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return servicecatalog.New(sess), nil
+}
+
+// newServiceCatalogRecordFailed builds a ServiceCatalogRecordFailed error from the given failed record.
+func newServiceCatalogRecordFailed(record *servicecatalog.RecordDetail) ServiceCatalogRecordFailed {
+	errors := []string{}
+	for _, recordError := range record.RecordErrors {
+		errors = append(errors, fmt.Sprintf("%s: %s", aws.StringValue(recordError.Code), aws.StringValue(recordError.Description)))
+	}
+
+	return ServiceCatalogRecordFailed{
+		RecordId: aws.StringValue(record.RecordId),
+		Status:   aws.StringValue(record.Status),
+		Errors:   errors,
+	}
+}
@@ -0,0 +1,23 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServiceCatalogRecordFailed(t *testing.T) {
+	t.Parallel()
+
+	err := newServiceCatalogRecordFailed(&servicecatalog.RecordDetail{
+		RecordId: aws.String("rec-123"),
+		Status:   aws.String(servicecatalog.RecordStatusFailed),
+		RecordErrors: []*servicecatalog.RecordError{
+			{Code: aws.String("CloudFormationFailure"), Description: aws.String("Bucket already exists")},
+		},
+	})
+
+	assert.Equal(t, "Service Catalog record rec-123 finished with status FAILED: CloudFormationFailure: Bucket already exists", err.Error())
+}
@@ -0,0 +1,167 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// SesSimulatorSuccessAddress is the address of the SES mailbox simulator that accepts every message. Messages to it
+// can be sent from accounts in the SES sandbox without verifying it, and don't count towards the sending quota.
+const SesSimulatorSuccessAddress = "success@simulator.amazonses.com"
+
+// GetSesIdentityVerificationStatus gets the verification status of the given SES identity, which is either a domain or
+// an email address, e.g. "Success" or "Pending". This will fail the test if there is an error.
+func GetSesIdentityVerificationStatus(t testing.TestingT, region string, identity string) string {
+	status, err := GetSesIdentityVerificationStatusE(t, region, identity)
+	require.NoError(t, err)
+	return status
+}
+
+// GetSesIdentityVerificationStatusE gets the verification status of the given SES identity, which is either a domain
+// or an email address, e.g. "Success" or "Pending". Returns an SesIdentityNotFound error if the identity does not exist.
+func GetSesIdentityVerificationStatusE(t testing.TestingT, region string, identity string) (string, error) {
+	client, err := NewSesClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.GetIdentityVerificationAttributes(&ses.GetIdentityVerificationAttributesInput{
+		Identities: []*string{aws.String(identity)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	attributes, exists := output.VerificationAttributes[identity]
+	if !exists {
+		return "", SesIdentityNotFound{Identity: identity, Region: region}
+	}
+	return aws.StringValue(attributes.VerificationStatus), nil
+}
+
+// AssertSesIdentityVerified checks that the given SES identity exists and is verified. This will fail the test if it is
+// not.
+func AssertSesIdentityVerified(t testing.TestingT, region string, identity string) {
+	err := AssertSesIdentityVerifiedE(t, region, identity)
+	require.NoError(t, err)
+}
+
+// AssertSesIdentityVerifiedE checks that the given SES identity exists and is verified, and returns an error if it is
+// not.
+func AssertSesIdentityVerifiedE(t testing.TestingT, region string, identity string) error {
+	status, err := GetSesIdentityVerificationStatusE(t, region, identity)
+	if err != nil {
+		return err
+	}
+
+	if status != ses.VerificationStatusSuccess {
+		return fmt.Errorf("SES identity %s in %s is not verified: its verification status is %s", identity, region, status)
+	}
+
+	return nil
+}
+
+// WaitForSesDkimVerified waits until DKIM signing of the given SES identity is enabled and verified. This will fail the
+// test if it is not.
+func WaitForSesDkimVerified(t testing.TestingT, region string, identity string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForSesDkimVerifiedE(t, region, identity, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForSesDkimVerifiedE waits until DKIM signing of the given SES identity is enabled and verified, i.e. SES found
+// the DKIM CNAME records of the domain, e.g. those created by the module under test. DNS changes take a while to
+// propagate, so allow for several minutes. Returns an SesDkimVerificationFailed error as soon as SES gives up on
+// verifying them.
+func WaitForSesDkimVerifiedE(t testing.TestingT, region string, identity string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	client, err := NewSesClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("DKIM of SES identity %s", identity),
+		DesiredState: ses.VerificationStatusSuccess,
+		Extract: func() (WaitState, error) {
+			output, err := client.GetIdentityDkimAttributes(&ses.GetIdentityDkimAttributesInput{
+				Identities: []*string{aws.String(identity)},
+			})
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			attributes, exists := output.DkimAttributes[identity]
+			if !exists {
+				return WaitState{}, SesIdentityNotFound{Identity: identity, Region: region}
+			}
+			return sesDkimWaitState(identity, attributes)
+		},
+	}
+	return WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+}
+
+// sesDkimWaitState returns the DKIM state of the given SES identity for WaitForSesDkimVerifiedE. Returns a
+// retry.FatalError if verification failed for good.
+func sesDkimWaitState(identity string, attributes *ses.IdentityDkimAttributes) (WaitState, error) {
+	status := aws.StringValue(attributes.DkimVerificationStatus)
+	if status == ses.VerificationStatusFailed {
+		return WaitState{}, retry.FatalError{Underlying: SesDkimVerificationFailed{Identity: identity, Status: status}}
+	}
+	if !aws.BoolValue(attributes.DkimEnabled) {
+		return WaitState{Current: status + " (DKIM signing disabled)"}, nil
+	}
+	return WaitState{Current: status, Done: status == ses.VerificationStatusSuccess}, nil
+}
+
+// SendSesTestEmail sends an email with the given subject and body from the given address to the given addresses with
+// SES, and returns its message ID. This will fail the test if there is an error.
+func SendSesTestEmail(t testing.TestingT, region string, from string, to []string, subject string, body string) string {
+	messageID, err := SendSesTestEmailE(t, region, from, to, subject, body)
+	require.NoError(t, err)
+	return messageID
+}
+
+// SendSesTestEmailE sends an email with the given text subject and body from the given address to the given addresses
+// with SES, and returns its message ID. This checks that the identity of the sender can send, e.g. that it is verified
+// and the IAM permissions and configuration set of the module allow it. In the SES sandbox, the recipients must be
+// verified too, unless they are SES mailbox simulator addresses such as SesSimulatorSuccessAddress.
+func SendSesTestEmailE(t testing.TestingT, region string, from string, to []string, subject string, body string) (string, error) {
+	client, err := NewSesClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.SendEmail(&ses.SendEmailInput{
+		Source:      aws.String(from),
+		Destination: &ses.Destination{ToAddresses: aws.StringSlice(to)},
+		Message: &ses.Message{
+			Subject: &ses.Content{Data: aws.String(subject)},
+			Body:    &ses.Body{Text: &ses.Content{Data: aws.String(body)}},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.MessageId), nil
+}
+
+// NewSesClient creates an SES client.
+func NewSesClient(t testing.TestingT, region string) *ses.SES {
+	client, err := NewSesClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewSesClientE creates an SES client.
+func NewSesClientE(t testing.TestingT, region string) (*ses.SES, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+	return ses.New(sess), nil
+}
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSesDkimWaitState(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		attributes *ses.IdentityDkimAttributes
+		expected   WaitState
+	}{
+		{
+			"pending",
+			&ses.IdentityDkimAttributes{DkimEnabled: aws.Bool(true), DkimVerificationStatus: aws.String(ses.VerificationStatusPending)},
+			WaitState{Current: "Pending"},
+		},
+		{
+			"temporary failure",
+			&ses.IdentityDkimAttributes{DkimEnabled: aws.Bool(true), DkimVerificationStatus: aws.String(ses.VerificationStatusTemporaryFailure)},
+			WaitState{Current: "TemporaryFailure"},
+		},
+		{
+			"verified but disabled",
+			&ses.IdentityDkimAttributes{DkimEnabled: aws.Bool(false), DkimVerificationStatus: aws.String(ses.VerificationStatusSuccess)},
+			WaitState{Current: "Success (DKIM signing disabled)"},
+		},
+		{
+			"verified",
+			&ses.IdentityDkimAttributes{DkimEnabled: aws.Bool(true), DkimVerificationStatus: aws.String(ses.VerificationStatusSuccess)},
+			WaitState{Current: "Success", Done: true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			state, err := sesDkimWaitState("example.com", testCase.attributes)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, state)
+		})
+	}
+}
+
+func TestSesDkimWaitStateFailed(t *testing.T) {
+	t.Parallel()
+
+	_, err := sesDkimWaitState("example.com", &ses.IdentityDkimAttributes{
+		DkimEnabled:            aws.Bool(true),
+		DkimVerificationStatus: aws.String(ses.VerificationStatusFailed),
+	})
+	require.IsType(t, retry.FatalError{}, err)
+	assert.Equal(t, SesDkimVerificationFailed{Identity: "example.com", Status: "Failed"}, err.(retry.FatalError).Underlying)
+	assert.True(t, errors.Is(err, ErrOperationFailed))
+}
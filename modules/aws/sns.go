@@ -1,12 +1,24 @@
 package aws
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
+// snsDeliveryQueuePrefix is the name prefix of the temporary SQS queues AssertSnsMessageDeliveredE subscribes to topics.
+const snsDeliveryQueuePrefix = "terratest-sns-delivery"
+
+// snsFifoMessageGroupID is the message group ID PublishSnsMessageE publishes messages to FIFO topics with.
+const snsFifoMessageGroupID = "terratest"
+
 // CreateSnsTopic creates an SNS Topic and return the ARN.
 func CreateSnsTopic(t testing.TestingT, region string, snsTopicName string) string {
 	out, err := CreateSnsTopicE(t, region, snsTopicName)
@@ -47,6 +59,10 @@ func DeleteSNSTopic(t testing.TestingT, region string, snsTopicArn string) {
 
 // DeleteSNSTopicE deletes an SNS Topic.
 func DeleteSNSTopicE(t testing.TestingT, region string, snsTopicArn string) error {
+	if dryrun.Skip(t, "delete SNS topic %s in %s", snsTopicArn, region) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting SNS topic %s in %s", snsTopicArn, region)
 
 	snsClient, err := NewSnsClientE(t, region)
@@ -62,6 +78,187 @@ func DeleteSNSTopicE(t testing.TestingT, region string, snsTopicArn string) erro
 	return err
 }
 
+// PublishSnsMessage publishes the given message to the SNS topic with the given ARN and returns its message ID.
+func PublishSnsMessage(t testing.TestingT, region string, snsTopicArn string, message string) string {
+	messageID, err := PublishSnsMessageE(t, region, snsTopicArn, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messageID
+}
+
+// PublishSnsMessageE publishes the given message to the SNS topic with the given ARN and returns its message ID.
+// Messages to FIFO topics are published to a single message group, with a random deduplication ID.
+func PublishSnsMessageE(t testing.TestingT, region string, snsTopicArn string, message string) (string, error) {
+	logger.Logf(t, "Publishing message %s to SNS topic %s", message, snsTopicArn)
+
+	snsClient, err := NewSnsClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	input := &sns.PublishInput{
+		TopicArn: aws.String(snsTopicArn),
+		Message:  aws.String(message),
+	}
+	if isFifoSnsTopic(snsTopicArn) {
+		input.MessageGroupId = aws.String(snsFifoMessageGroupID)
+		input.MessageDeduplicationId = aws.String(random.UniqueId())
+	}
+
+	output, err := snsClient.Publish(input)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.MessageId), nil
+}
+
+// AssertSnsMessageDelivered checks that a message published to the SNS topic with the given ARN is delivered to its
+// subscribers within timeout seconds. This will fail the test if it is not.
+func AssertSnsMessageDelivered(t testing.TestingT, region string, snsTopicArn string, message string, timeout int) {
+	err := AssertSnsMessageDeliveredE(t, region, snsTopicArn, message, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertSnsMessageDeliveredE checks that a message published to the SNS topic with the given ARN is delivered to its
+// subscribers within timeout seconds, e.g. to test that the topic policy and encryption settings of a module allow
+// fan-out. It subscribes a temporary SQS queue to the topic, publishes the given message and waits for the queue to
+// receive it, then deletes the subscription and the queue again. The message is delivered to the other subscribers of
+// the topic too. Returns a ReceiveMessageTimeout error if the message is not delivered in time.
+func AssertSnsMessageDeliveredE(t testing.TestingT, region string, snsTopicArn string, message string, timeout int) error {
+	var queueURL string
+	var err error
+	// FIFO topics can only deliver to FIFO queues
+	if isFifoSnsTopic(snsTopicArn) {
+		queueURL, err = CreateRandomFifoQueueE(t, region, snsDeliveryQueuePrefix)
+	} else {
+		queueURL, err = CreateRandomQueueE(t, region, snsDeliveryQueuePrefix)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := DeleteQueueE(t, region, queueURL); err != nil {
+			logger.Logf(t, "WARN: Failed to delete SQS queue %s: %s", queueURL, err)
+		}
+	}()
+
+	subscriptionArn, err := subscribeQueueToSnsTopicE(t, region, snsTopicArn, queueURL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := unsubscribeFromSnsTopicE(t, region, subscriptionArn); err != nil {
+			logger.Logf(t, "WARN: Failed to delete SNS subscription %s: %s", subscriptionArn, err)
+		}
+	}()
+
+	if _, err := PublishSnsMessageE(t, region, snsTopicArn, message); err != nil {
+		return err
+	}
+
+	_, err = WaitForQueueMessageWithBodyE(t, region, queueURL, message, timeout)
+	return err
+}
+
+// subscribeQueueToSnsTopicE allows the SNS topic with the given ARN to send messages to the SQS queue with the given
+// URL, subscribes the queue to the topic with raw message delivery, so that message bodies are the published messages,
+// and returns the ARN of the subscription.
+func subscribeQueueToSnsTopicE(t testing.TestingT, region string, snsTopicArn string, queueURL string) (string, error) {
+	logger.Logf(t, "Subscribing SQS queue %s to SNS topic %s", queueURL, snsTopicArn)
+
+	sqsClient, err := NewSqsClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	attributes, err := sqsClient.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameQueueArn}),
+	})
+	if err != nil {
+		return "", err
+	}
+	queueArn := aws.StringValue(attributes.Attributes[sqs.QueueAttributeNameQueueArn])
+
+	policy, err := snsDeliveryQueuePolicy(queueArn, snsTopicArn)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = sqsClient.SetQueueAttributes(&sqs.SetQueueAttributesInput{
+		QueueUrl:   aws.String(queueURL),
+		Attributes: map[string]*string{sqs.QueueAttributeNamePolicy: aws.String(policy)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	snsClient, err := NewSnsClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := snsClient.Subscribe(&sns.SubscribeInput{
+		TopicArn:              aws.String(snsTopicArn),
+		Protocol:              aws.String("sqs"),
+		Endpoint:              aws.String(queueArn),
+		Attributes:            map[string]*string{"RawMessageDelivery": aws.String("true")},
+		ReturnSubscriptionArn: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.SubscriptionArn), nil
+}
+
+// unsubscribeFromSnsTopicE deletes the SNS subscription with the given ARN.
+func unsubscribeFromSnsTopicE(t testing.TestingT, region string, subscriptionArn string) error {
+	logger.Logf(t, "Deleting SNS subscription %s", subscriptionArn)
+
+	snsClient, err := NewSnsClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	_, err = snsClient.Unsubscribe(&sns.UnsubscribeInput{SubscriptionArn: aws.String(subscriptionArn)})
+	return err
+}
+
+// snsDeliveryQueuePolicy returns an SQS queue policy that allows the SNS topic with the given ARN to send messages to
+// the queue with the given ARN.
+func snsDeliveryQueuePolicy(queueArn string, snsTopicArn string) (string, error) {
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{
+			{
+				"Effect":    "Allow",
+				"Principal": map[string]string{"Service": "sns.amazonaws.com"},
+				"Action":    "sqs:SendMessage",
+				"Resource":  queueArn,
+				"Condition": map[string]interface{}{
+					"ArnEquals": map[string]string{"aws:SourceArn": snsTopicArn},
+				},
+			},
+		},
+	}
+
+	out, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// isFifoSnsTopic indicates whether the SNS topic with the given ARN is a FIFO topic, i.e. its name ends with ".fifo".
+func isFifoSnsTopic(snsTopicArn string) bool {
+	return strings.HasSuffix(snsTopicArn, ".fifo")
+}
+
 // NewSnsClient creates a new SNS client.
 func NewSnsClient(t testing.TestingT, region string) *sns.SNS {
 	client, err := NewSnsClientE(t, region)
@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateAndDeleteSnsTopic(t *testing.T) {
@@ -24,6 +25,47 @@ func TestCreateAndDeleteSnsTopic(t *testing.T) {
 	assert.True(t, snsTopicExists(t, region, arn))
 }
 
+func TestAssertSnsMessageDelivered(t *testing.T) {
+	t.Parallel()
+
+	region := GetRandomStableRegion(t, nil, nil)
+	uniqueID := random.UniqueId()
+	name := fmt.Sprintf("test-sns-topic-%s", uniqueID)
+
+	arn := CreateSnsTopic(t, region, name)
+	defer deleteTopic(t, region, arn)
+
+	AssertSnsMessageDelivered(t, region, arn, fmt.Sprintf("test-message-%s", uniqueID), 60)
+}
+
+func TestSnsDeliveryQueuePolicy(t *testing.T) {
+	t.Parallel()
+
+	queueArn := "arn:aws:sqs:us-east-1:123456789012:terratest-sns-delivery-abc"
+	topicArn := "arn:aws:sns:us-east-1:123456789012:my-topic"
+
+	policy, err := snsDeliveryQueuePolicy(queueArn, topicArn)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Allow",
+			"Principal": {"Service": "sns.amazonaws.com"},
+			"Action": "sqs:SendMessage",
+			"Resource": "arn:aws:sqs:us-east-1:123456789012:terratest-sns-delivery-abc",
+			"Condition": {"ArnEquals": {"aws:SourceArn": "arn:aws:sns:us-east-1:123456789012:my-topic"}}
+		}]
+	}`, policy)
+}
+
+func TestIsFifoSnsTopic(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isFifoSnsTopic("arn:aws:sns:us-east-1:123456789012:my-topic.fifo"))
+	assert.False(t, isFifoSnsTopic("arn:aws:sns:us-east-1:123456789012:my-topic"))
+}
+
 func snsTopicExists(t *testing.T, region string, arn string) bool {
 	snsClient := NewSnsClient(t, region)
 
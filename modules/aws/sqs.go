@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/google/uuid"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
@@ -98,6 +99,10 @@ func DeleteQueue(t testing.TestingT, awsRegion string, queueURL string) {
 
 // DeleteQueueE deletes the SQS queue with the given URL.
 func DeleteQueueE(t testing.TestingT, awsRegion string, queueURL string) error {
+	if dryrun.Skip(t, "delete SQS Queue %s", queueURL) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting SQS Queue %s", queueURL)
 
 	sqsClient, err := NewSqsClientE(t, awsRegion)
@@ -122,6 +127,10 @@ func DeleteMessageFromQueue(t testing.TestingT, awsRegion string, queueURL strin
 
 // DeleteMessageFromQueueE deletes the message with the given receipt from the SQS queue with the given URL.
 func DeleteMessageFromQueueE(t testing.TestingT, awsRegion string, queueURL string, receipt string) error {
+	if dryrun.Skip(t, "delete message from queue %s (%s)", queueURL, receipt) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting message from queue %s (%s)", queueURL, receipt)
 
 	sqsClient, err := NewSqsClientE(t, awsRegion)
@@ -147,29 +156,10 @@ func SendMessageToQueue(t testing.TestingT, awsRegion string, queueURL string, m
 
 // SendMessageToQueueE sends the given message to the SQS queue with the given URL.
 func SendMessageToQueueE(t testing.TestingT, awsRegion string, queueURL string, message string) error {
-	logger.Logf(t, "Sending message %s to queue %s", message, queueURL)
-
-	sqsClient, err := NewSqsClientE(t, awsRegion)
-	if err != nil {
-		return err
-	}
-
-	res, err := sqsClient.SendMessage(&sqs.SendMessageInput{
+	return sendMessageToQueueE(t, awsRegion, &sqs.SendMessageInput{
 		MessageBody: &message,
 		QueueUrl:    &queueURL,
 	})
-
-	if err != nil {
-		if strings.Contains(err.Error(), "AWS.SimpleQueueService.NonExistentQueue") {
-			logger.Logf(t, fmt.Sprintf("WARN: Client has stopped listening on queue %s", queueURL))
-			return nil
-		}
-		return err
-	}
-
-	logger.Logf(t, "Message id %s sent to queue %s", aws.StringValue(res.MessageId), queueURL)
-
-	return nil
 }
 
 // SendMessageToFifoQueue sends the given message to the FIFO SQS queue with the given URL.
@@ -180,20 +170,49 @@ func SendMessageFifoToQueue(t testing.TestingT, awsRegion string, queueURL strin
 	}
 }
 
-// SendMessageToFifoQueueE sends the given message to the FIFO SQS queue with the given URL.
+// SendMessageToFifoQueueE sends the given message to the FIFO SQS queue with the given URL. The queue must have content
+// based deduplication enabled, like the queues created by CreateRandomFifoQueue. Otherwise, use
+// SendMessageToFifoQueueWithDeduplicationIDE.
 func SendMessageToFifoQueueE(t testing.TestingT, awsRegion string, queueURL string, message string, messageGroupID string) error {
-	logger.Logf(t, "Sending message %s to queue %s", message, queueURL)
+	return sendMessageToQueueE(t, awsRegion, &sqs.SendMessageInput{
+		MessageBody:    &message,
+		QueueUrl:       &queueURL,
+		MessageGroupId: &messageGroupID,
+	})
+}
+
+// SendMessageToFifoQueueWithDeduplicationID sends the given message with the given deduplication ID to the FIFO SQS
+// queue with the given URL.
+func SendMessageToFifoQueueWithDeduplicationID(t testing.TestingT, awsRegion string, queueURL string, message string, messageGroupID string, deduplicationID string) {
+	err := SendMessageToFifoQueueWithDeduplicationIDE(t, awsRegion, queueURL, message, messageGroupID, deduplicationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// SendMessageToFifoQueueWithDeduplicationIDE sends the given message with the given deduplication ID to the FIFO SQS
+// queue with the given URL. SQS drops messages with the same deduplication ID as a message sent in the last 5 minutes,
+// which is useful to test that a consumer doesn't see duplicates.
+func SendMessageToFifoQueueWithDeduplicationIDE(t testing.TestingT, awsRegion string, queueURL string, message string, messageGroupID string, deduplicationID string) error {
+	return sendMessageToQueueE(t, awsRegion, &sqs.SendMessageInput{
+		MessageBody:            &message,
+		QueueUrl:               &queueURL,
+		MessageGroupId:         &messageGroupID,
+		MessageDeduplicationId: &deduplicationID,
+	})
+}
+
+// sendMessageToQueueE sends the message of the given input to its queue.
+func sendMessageToQueueE(t testing.TestingT, awsRegion string, input *sqs.SendMessageInput) error {
+	queueURL := aws.StringValue(input.QueueUrl)
+	logger.Logf(t, "Sending message %s to queue %s", aws.StringValue(input.MessageBody), queueURL)
 
 	sqsClient, err := NewSqsClientE(t, awsRegion)
 	if err != nil {
 		return err
 	}
 
-	res, err := sqsClient.SendMessage(&sqs.SendMessageInput{
-		MessageBody:    &message,
-		QueueUrl:       &queueURL,
-		MessageGroupId: &messageGroupID,
-	})
+	res, err := sqsClient.SendMessage(input)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "AWS.SimpleQueueService.NonExistentQueue") {
@@ -210,47 +229,172 @@ func SendMessageToFifoQueueE(t testing.TestingT, awsRegion string, queueURL stri
 
 // QueueMessageResponse contains a queue message.
 type QueueMessageResponse struct {
-	ReceiptHandle string
-	MessageBody   string
-	Error         error
+	ReceiptHandle   string
+	MessageBody     string
+	MessageID       string
+	MessageGroupID  string            // Only set for messages of FIFO queues
+	DeduplicationID string            // Only set for messages of FIFO queues
+	Attributes      map[string]string // The string and number message attributes
+	Error           error
 }
 
 // WaitForQueueMessage waits to receive a message from on the queueURL. Since the API only allows us to wait a max 20 seconds for a new
 // message to arrive, we must loop TIMEOUT/20 number of times to be able to wait for a total of TIMEOUT seconds
 func WaitForQueueMessage(t testing.TestingT, awsRegion string, queueURL string, timeout int) QueueMessageResponse {
-	sqsClient, err := NewSqsClientE(t, awsRegion)
+	response, err := WaitForQueueMessageE(t, awsRegion, queueURL, timeout)
 	if err != nil {
 		return QueueMessageResponse{Error: err}
 	}
+	return response
+}
 
-	cycles := timeout
-	cycleLength := 1
-	if timeout >= 20 {
-		cycleLength = 20
-		cycles = timeout / cycleLength
+// WaitForQueueMessageE waits up to timeout seconds to receive a message from the queue with the given URL, long polling
+// for it. Returns a ReceiveMessageTimeout error if no message arrives in time.
+func WaitForQueueMessageE(t testing.TestingT, awsRegion string, queueURL string, timeout int) (QueueMessageResponse, error) {
+	return waitForQueueMessageMatchingE(t, awsRegion, queueURL, timeout, 1, func(QueueMessageResponse) bool { return true })
+}
+
+// WaitForQueueMessageWithBody waits up to timeout seconds to receive a message with the given body from the queue with
+// the given URL. This will fail the test if no such message arrives in time.
+func WaitForQueueMessageWithBody(t testing.TestingT, awsRegion string, queueURL string, body string, timeout int) QueueMessageResponse {
+	response, err := WaitForQueueMessageWithBodyE(t, awsRegion, queueURL, body, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// WaitForQueueMessageWithBodyE waits up to timeout seconds to receive a message with the given body from the queue with
+// the given URL, e.g. the event a module under test publishes. Other messages received meanwhile are not deleted, so
+// they become visible again once the visibility timeout of the queue passes. Returns a ReceiveMessageTimeout error if
+// no such message arrives in time.
+func WaitForQueueMessageWithBodyE(t testing.TestingT, awsRegion string, queueURL string, body string, timeout int) (QueueMessageResponse, error) {
+	return waitForQueueMessageMatchingE(t, awsRegion, queueURL, timeout, 10, func(response QueueMessageResponse) bool {
+		return response.MessageBody == body
+	})
+}
+
+// waitForQueueMessageMatchingE receives up to maxMessages messages at a time from the given queue for up to timeout
+// seconds, and returns the first that matches.
+func waitForQueueMessageMatchingE(t testing.TestingT, awsRegion string, queueURL string, timeout int, maxMessages int64, matches func(QueueMessageResponse) bool) (QueueMessageResponse, error) {
+	sqsClient, err := NewSqsClientE(t, awsRegion)
+	if err != nil {
+		return QueueMessageResponse{}, err
 	}
 
+	cycles, cycleLength := queueMessageWaitCycles(timeout)
+
 	for i := 0; i < cycles; i++ {
 		logger.Logf(t, "Waiting for message on %s (%ss)", queueURL, strconv.Itoa(i*cycleLength))
 		result, err := sqsClient.ReceiveMessage(&sqs.ReceiveMessageInput{
 			QueueUrl:              aws.String(queueURL),
-			AttributeNames:        aws.StringSlice([]string{"SentTimestamp"}),
-			MaxNumberOfMessages:   aws.Int64(1),
+			AttributeNames:        aws.StringSlice([]string{sqs.QueueAttributeNameAll}),
+			MaxNumberOfMessages:   aws.Int64(maxMessages),
 			MessageAttributeNames: aws.StringSlice([]string{"All"}),
 			WaitTimeSeconds:       aws.Int64(int64(cycleLength)),
 		})
 
 		if err != nil {
-			return QueueMessageResponse{Error: err}
+			return QueueMessageResponse{}, err
 		}
 
-		if len(result.Messages) > 0 {
-			logger.Logf(t, "Message %s received on %s", *result.Messages[0].MessageId, queueURL)
-			return QueueMessageResponse{ReceiptHandle: *result.Messages[0].ReceiptHandle, MessageBody: *result.Messages[0].Body}
+		for _, message := range result.Messages {
+			response := queueMessageResponse(message)
+			if matches(response) {
+				logger.Logf(t, "Message %s received on %s", response.MessageID, queueURL)
+				return response, nil
+			}
 		}
 	}
 
-	return QueueMessageResponse{Error: ReceiveMessageTimeout{QueueUrl: queueURL, TimeoutSec: timeout}}
+	return QueueMessageResponse{}, ReceiveMessageTimeout{QueueUrl: queueURL, TimeoutSec: timeout}
+}
+
+// queueMessageWaitCycles returns how many times to long poll for how many seconds each to wait for a total of timeout
+// seconds. The API only allows to wait up to 20 seconds for a new message to arrive.
+func queueMessageWaitCycles(timeout int) (int, int) {
+	if timeout >= 20 {
+		return timeout / 20, 20
+	}
+	return timeout, 1
+}
+
+// queueMessageResponse converts the given received message to a QueueMessageResponse.
+func queueMessageResponse(message *sqs.Message) QueueMessageResponse {
+	attributes := map[string]string{}
+	for name, value := range message.MessageAttributes {
+		if value.StringValue != nil {
+			attributes[name] = aws.StringValue(value.StringValue)
+		}
+	}
+
+	return QueueMessageResponse{
+		ReceiptHandle:   aws.StringValue(message.ReceiptHandle),
+		MessageBody:     aws.StringValue(message.Body),
+		MessageID:       aws.StringValue(message.MessageId),
+		MessageGroupID:  aws.StringValue(message.Attributes[sqs.MessageSystemAttributeNameMessageGroupId]),
+		DeduplicationID: aws.StringValue(message.Attributes[sqs.MessageSystemAttributeNameMessageDeduplicationId]),
+		Attributes:      attributes,
+	}
+}
+
+// GetApproximateNumberOfMessages gets the approximate number of messages available to receive from the SQS queue with
+// the given URL.
+func GetApproximateNumberOfMessages(t testing.TestingT, awsRegion string, queueURL string) int {
+	count, err := GetApproximateNumberOfMessagesE(t, awsRegion, queueURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+// GetApproximateNumberOfMessagesE gets the approximate number of messages available to receive from the SQS queue with
+// the given URL. This doesn't count messages that are in flight, i.e. received but not deleted yet, or delayed. SQS is
+// eventually consistent, so the count may lag behind messages sent or deleted just now.
+func GetApproximateNumberOfMessagesE(t testing.TestingT, awsRegion string, queueURL string) (int, error) {
+	sqsClient, err := NewSqsClientE(t, awsRegion)
+	if err != nil {
+		return 0, err
+	}
+
+	output, err := sqsClient.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameApproximateNumberOfMessages}),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(aws.StringValue(output.Attributes[sqs.QueueAttributeNameApproximateNumberOfMessages]))
+}
+
+// PurgeQueue deletes all the messages in the SQS queue with the given URL.
+func PurgeQueue(t testing.TestingT, awsRegion string, queueURL string) {
+	err := PurgeQueueE(t, awsRegion, queueURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// PurgeQueueE deletes all the messages in the SQS queue with the given URL, e.g. to start a test from an empty queue.
+// Deleting the messages takes up to 60 seconds, and SQS only allows to purge a queue once every 60 seconds.
+func PurgeQueueE(t testing.TestingT, awsRegion string, queueURL string) error {
+	if dryrun.Skip(t, "purge SQS Queue %s", queueURL) {
+		return nil
+	}
+
+	logger.Logf(t, "Purging SQS Queue %s", queueURL)
+
+	sqsClient, err := NewSqsClientE(t, awsRegion)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqsClient.PurgeQueue(&sqs.PurgeQueueInput{
+		QueueUrl: aws.String(queueURL),
+	})
+
+	return err
 }
 
 // NewSqsClient creates a new SQS client.
@@ -2,6 +2,7 @@ package aws
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -66,6 +67,85 @@ func TestFifoSqsQueueMethods(t *testing.T) {
 	assert.Error(t, secondResponse.Error, ReceiveMessageTimeout{QueueUrl: url, TimeoutSec: timeoutSec})
 }
 
+func TestFifoSqsQueueDeduplication(t *testing.T) {
+	t.Parallel()
+
+	region := GetRandomStableRegion(t, nil, nil)
+	uniqueID := random.UniqueId()
+	namePrefix := fmt.Sprintf("sqs-queue-test-%s", uniqueID)
+
+	url := CreateRandomFifoQueue(t, region, namePrefix)
+	defer deleteQueue(t, region, url)
+
+	timeoutSec := 20
+
+	// The second message has the same deduplication ID as the first, so SQS drops it
+	SendMessageToFifoQueueWithDeduplicationID(t, region, url, "first", "g1", uniqueID)
+	SendMessageToFifoQueueWithDeduplicationID(t, region, url, "second", "g1", uniqueID)
+
+	response := WaitForQueueMessageWithBody(t, region, url, "first", timeoutSec)
+	assert.Equal(t, uniqueID, response.DeduplicationID)
+	assert.Equal(t, "g1", response.MessageGroupID)
+	DeleteMessageFromQueue(t, region, url, response.ReceiptHandle)
+
+	_, err := WaitForQueueMessageE(t, region, url, timeoutSec)
+	assert.Error(t, err, ReceiveMessageTimeout{QueueUrl: url, TimeoutSec: timeoutSec})
+}
+
+func TestQueueMessageWaitCycles(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		timeout        int
+		expectedCycles int
+		expectedLength int
+	}{
+		{0, 0, 1},
+		{5, 5, 1},
+		{20, 1, 20},
+		{60, 3, 20},
+		{70, 3, 20},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(strconv.Itoa(testCase.timeout), func(t *testing.T) {
+			t.Parallel()
+			cycles, length := queueMessageWaitCycles(testCase.timeout)
+			assert.Equal(t, testCase.expectedCycles, cycles)
+			assert.Equal(t, testCase.expectedLength, length)
+		})
+	}
+}
+
+func TestQueueMessageResponse(t *testing.T) {
+	t.Parallel()
+
+	response := queueMessageResponse(&sqs.Message{
+		MessageId:     aws.String("id"),
+		ReceiptHandle: aws.String("receipt"),
+		Body:          aws.String("body"),
+		Attributes: map[string]*string{
+			sqs.MessageSystemAttributeNameMessageGroupId:         aws.String("group"),
+			sqs.MessageSystemAttributeNameMessageDeduplicationId: aws.String("dedup"),
+		},
+		MessageAttributes: map[string]*sqs.MessageAttributeValue{
+			"type":   {DataType: aws.String("String"), StringValue: aws.String("created")},
+			"count":  {DataType: aws.String("Number"), StringValue: aws.String("3")},
+			"binary": {DataType: aws.String("Binary"), BinaryValue: []byte("data")},
+		},
+	})
+
+	assert.Equal(t, QueueMessageResponse{
+		ReceiptHandle:   "receipt",
+		MessageBody:     "body",
+		MessageID:       "id",
+		MessageGroupID:  "group",
+		DeduplicationID: "dedup",
+		Attributes:      map[string]string{"type": "created", "count": "3"},
+	}, response)
+}
+
 func queueExists(t *testing.T, region string, url string) bool {
 	sqsClient := NewSqsClient(t, region)
 
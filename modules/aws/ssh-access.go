@@ -0,0 +1,220 @@
+package aws
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// SshAuth is the temporary SSH access created by CreateTemporarySshAccess: an EC2 Key Pair and a Security Group that
+// allows SSH only from the test runner. Launch the instances under test with KeyPair.Name and SecurityGroupId. An
+// SshAuth created by NewEc2InstanceConnectSshAuth uses EC2 Instance Connect instead of an EC2 Key Pair.
+type SshAuth struct {
+	KeyPair         *Ec2Keypair
+	SecurityGroupId string // The ID of the Security Group that allows SSH from the test runner
+	Region          string // The AWS region where the Key Pair and Security Group live
+	VpcId           string // The ID of the VPC the Security Group lives in
+	AllowedCidr     string // The CIDR block, usually the test runner's egress IP as a /32 or /128, allowed to connect
+
+	// The methods FetchContentsOfFilesFromInstanceWithAuth tries, in order, to connect to instances. Defaults to
+	// ConnectViaBastion if Bastion is set and ConnectViaPublicIp otherwise.
+	ConnectivityOrder []ConnectivityMethod
+	Bastion           *ssh.Host // The bastion host to connect through with ConnectViaBastion
+
+	// The ID of the EC2 Instance of the Bastion, if it is one. With Ec2InstanceConnect, the public key of KeyPair is
+	// pushed to it too, for the SshUserName of the Bastion, so set the SshKeyPair of the Bastion to KeyPair.KeyPair.
+	BastionInstanceId string
+
+	// Which address of the instances to connect to via SSH, e.g. AddressIpv6 for IPv6-only subnets. Defaults to the
+	// private IP with ConnectViaBastion and the public IP otherwise.
+	AddressMode AddressMode
+
+	// If true, KeyPair is an ephemeral key that is not imported into EC2, and its public key is pushed to each instance
+	// with EC2 Instance Connect right before connecting to it.
+	Ec2InstanceConnect bool
+}
+
+// Host returns an ssh.Host that connects to the given IP address as the given user with the Key Pair of this SshAuth.
+// This does not push the key with EC2 Instance Connect: if Ec2InstanceConnect is set, call SendSshPublicKeyE with the
+// public key of KeyPair before each SSH connection made with the returned host, as pushed keys only last 60 seconds.
+func (auth *SshAuth) Host(ip string, sshUserName string) ssh.Host {
+	return ssh.Host{
+		Hostname:    ip,
+		SshUserName: sshUserName,
+		SshKeyPair:  auth.KeyPair.KeyPair,
+	}
+}
+
+// CreateTemporarySshAccess creates a throwaway EC2 Key Pair and a Security Group in the given VPC that allows port 22
+// only from the test runner's public egress IP. Both are deleted when the test finishes. This requires t to support
+// Cleanup, as testing.T does; otherwise a warning is logged, so defer DeleteTemporarySshAccess instead.
+func CreateTemporarySshAccess(t testing.TestingT, region string, vpcID string) *SshAuth {
+	auth, err := CreateTemporarySshAccessE(t, region, vpcID)
+	require.NoError(t, err)
+	return auth
+}
+
+// CreateTemporarySshAccessE creates a throwaway EC2 Key Pair and a Security Group in the given VPC that allows port 22
+// only from the test runner's public egress IP. Both are deleted when the test finishes. This requires t to support
+// Cleanup, as testing.T does; otherwise a warning is logged, so defer DeleteTemporarySshAccessE instead.
+func CreateTemporarySshAccessE(t testing.TestingT, region string, vpcID string) (*SshAuth, error) {
+	cidr, err := http_helper.GetEgressIPCidrE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("terratest-ssh-%s", random.UniqueId())
+	auth := &SshAuth{Region: region, VpcId: vpcID, AllowedCidr: cidr}
+
+	auth.KeyPair, err = CreateAndImportEC2KeyPairE(t, region, name)
+	if err != nil {
+		return nil, err
+	}
+
+	registerSshAccessCleanup(t, auth)
+
+	auth.SecurityGroupId, err = createSshSecurityGroupE(t, region, vpcID, name, auth.AllowedCidr)
+	if err != nil {
+		return auth, err
+	}
+
+	return auth, nil
+}
+
+// DeleteTemporarySshAccess deletes the Security Group and Key Pair of the given SshAuth. The Security Group can only
+// be deleted once no instances use it, so this retries for a few minutes while they terminate.
+func DeleteTemporarySshAccess(t testing.TestingT, auth *SshAuth) {
+	require.NoError(t, DeleteTemporarySshAccessE(t, auth))
+}
+
+// DeleteTemporarySshAccessE deletes the Security Group and Key Pair of the given SshAuth. The Security Group can only
+// be deleted once no instances use it, so this retries for a few minutes while they terminate.
+func DeleteTemporarySshAccessE(t testing.TestingT, auth *SshAuth) error {
+	if dryrun.Skip(t, "delete Security Group %s and Key Pair of the temporary SSH access in %s", auth.SecurityGroupId, auth.Region) {
+		return nil
+	}
+
+	if auth.SecurityGroupId != "" {
+		if err := deleteSecurityGroupWithRetryE(t, auth.Region, auth.SecurityGroupId); err != nil {
+			return err
+		}
+	}
+
+	if auth.KeyPair != nil && !auth.Ec2InstanceConnect {
+		return DeleteEC2KeyPairE(t, auth.KeyPair)
+	}
+
+	return nil
+}
+
+// registerSshAccessCleanup deletes the given SshAuth when the test finishes, if t supports Cleanup.
+func registerSshAccessCleanup(t testing.TestingT, auth *SshAuth) {
+	cleanupT, ok := t.(interface{ Cleanup(func()) })
+	if !ok {
+		logger.Logf(t, "WARNING: Cannot register Key Pair %s for cleanup because %T does not support Cleanup", auth.KeyPair.Name, t)
+		return
+	}
+
+	cleanupT.Cleanup(func() {
+		if err := DeleteTemporarySshAccessE(t, auth); err != nil {
+			t.Errorf("Failed to delete temporary SSH access %s in %s: %s", auth.KeyPair.Name, auth.Region, err)
+		}
+	})
+}
+
+// createSshSecurityGroupE creates a Security Group in the given VPC that allows SSH from the given CIDR block and
+// returns its ID.
+func createSshSecurityGroupE(t testing.TestingT, region string, vpcID string, name string, cidr string) (string, error) {
+	logger.Logf(t, "Creating Security Group %s in VPC %s allowing SSH from %s", name, vpcID, cidr)
+
+	permission, err := sshIpPermissionE(cidr)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{
+		GroupName:   aws.String(name),
+		Description: aws.String("Temporary SSH access for Terratest"),
+		VpcId:       aws.String(vpcID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	_, err = client.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId:       output.GroupId,
+		IpPermissions: []*ec2.IpPermission{permission},
+	})
+	if err != nil {
+		return aws.StringValue(output.GroupId), err
+	}
+
+	return aws.StringValue(output.GroupId), nil
+}
+
+// sshIpPermissionE returns the Security Group rule that allows SSH from the given CIDR block, which goes in the IPv6
+// ranges of the rule if it is an IPv6 CIDR block, e.g. the egress IP of a test runner on an IPv6 network.
+func sshIpPermissionE(cidr string) (*ec2.IpPermission, error) {
+	ip, _, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	permission := &ec2.IpPermission{
+		IpProtocol: aws.String("tcp"),
+		FromPort:   aws.Int64(22),
+		ToPort:     aws.Int64(22),
+	}
+	if ip.To4() == nil {
+		permission.Ipv6Ranges = []*ec2.Ipv6Range{{CidrIpv6: aws.String(cidr), Description: aws.String("Terratest runner")}}
+	} else {
+		permission.IpRanges = []*ec2.IpRange{{CidrIp: aws.String(cidr), Description: aws.String("Terratest runner")}}
+	}
+	return permission, nil
+}
+
+// deleteSecurityGroupWithRetryE deletes the given Security Group, retrying while it is still in use by network
+// interfaces of instances that are terminating.
+func deleteSecurityGroupWithRetryE(t testing.TestingT, region string, groupID string) error {
+	client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	_, err = retry.DoWithRetryE(t, fmt.Sprintf("Deleting Security Group %s", groupID), 30, 10*time.Second, func() (string, error) {
+		_, err := client.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: aws.String(groupID)})
+		if err == nil {
+			return "", nil
+		}
+		if awsErr, ok := err.(awserr.Error); ok {
+			switch awsErr.Code() {
+			case "DependencyViolation":
+				return "", err
+			case "InvalidGroup.NotFound":
+				return "", nil
+			}
+		}
+		return "", retry.FatalError{Underlying: err}
+	})
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return actualErr.Underlying
+	}
+	return err
+}
@@ -0,0 +1,29 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSshIpPermission(t *testing.T) {
+	t.Parallel()
+
+	permission, err := sshIpPermissionE("203.0.113.7/32")
+	require.NoError(t, err)
+	require.Len(t, permission.IpRanges, 1)
+	assert.Equal(t, "203.0.113.7/32", aws.StringValue(permission.IpRanges[0].CidrIp))
+	assert.Empty(t, permission.Ipv6Ranges)
+	assert.Equal(t, int64(22), aws.Int64Value(permission.FromPort))
+
+	permission, err = sshIpPermissionE("2001:db8::7/128")
+	require.NoError(t, err)
+	require.Len(t, permission.Ipv6Ranges, 1)
+	assert.Equal(t, "2001:db8::7/128", aws.StringValue(permission.Ipv6Ranges[0].CidrIpv6))
+	assert.Empty(t, permission.IpRanges)
+
+	_, err = sshIpPermissionE("203.0.113.7")
+	assert.Error(t, err)
+}
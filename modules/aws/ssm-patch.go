@@ -0,0 +1,323 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// GetPatchBaselineIdForPatchGroup returns the ID of the patch baseline registered for the given patch group.
+func GetPatchBaselineIdForPatchGroup(t testing.TestingT, awsRegion string, patchGroup string) string {
+	baselineID, err := GetPatchBaselineIdForPatchGroupE(t, awsRegion, patchGroup)
+	require.NoError(t, err)
+	return baselineID
+}
+
+// GetPatchBaselineIdForPatchGroupE returns the ID of the patch baseline registered for the given patch group.
+func GetPatchBaselineIdForPatchGroupE(t testing.TestingT, awsRegion string, patchGroup string) (string, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.GetPatchBaselineForPatchGroup(&ssm.GetPatchBaselineForPatchGroupInput{PatchGroup: aws.String(patchGroup)})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(resp.BaselineId), nil
+}
+
+// AssertPatchGroupUsesBaseline checks that the given patch group is registered with the patch baseline with the given
+// ID.
+func AssertPatchGroupUsesBaseline(t testing.TestingT, awsRegion string, patchGroup string, baselineID string) {
+	err := AssertPatchGroupUsesBaselineE(t, awsRegion, patchGroup, baselineID)
+	require.NoError(t, err)
+}
+
+// AssertPatchGroupUsesBaselineE checks that the given patch group is registered with the patch baseline with the given
+// ID and returns an error if it is not.
+func AssertPatchGroupUsesBaselineE(t testing.TestingT, awsRegion string, patchGroup string, baselineID string) error {
+	actualBaselineID, err := GetPatchBaselineIdForPatchGroupE(t, awsRegion, patchGroup)
+	if err != nil {
+		return err
+	}
+
+	if actualBaselineID != baselineID {
+		return fmt.Errorf("Expected patch group %s in %s to use patch baseline %s but it uses %s", patchGroup, awsRegion, baselineID, actualBaselineID)
+	}
+
+	return nil
+}
+
+// GetPatchBaseline returns the details of the patch baseline with the given ID.
+func GetPatchBaseline(t testing.TestingT, awsRegion string, baselineID string) *ssm.GetPatchBaselineOutput {
+	baseline, err := GetPatchBaselineE(t, awsRegion, baselineID)
+	require.NoError(t, err)
+	return baseline
+}
+
+// GetPatchBaselineE returns the details of the patch baseline with the given ID.
+func GetPatchBaselineE(t testing.TestingT, awsRegion string, baselineID string) (*ssm.GetPatchBaselineOutput, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetPatchBaseline(&ssm.GetPatchBaselineInput{BaselineId: aws.String(baselineID)})
+}
+
+// GetInstancePatchState returns the high-level patch compliance state of the managed instance with the given ID.
+func GetInstancePatchState(t testing.TestingT, awsRegion string, instanceID string) *ssm.InstancePatchState {
+	state, err := GetInstancePatchStateE(t, awsRegion, instanceID)
+	require.NoError(t, err)
+	return state
+}
+
+// GetInstancePatchStateE returns the high-level patch compliance state of the managed instance with the given ID.
+func GetInstancePatchStateE(t testing.TestingT, awsRegion string, instanceID string) (*ssm.InstancePatchState, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.DescribeInstancePatchStates(&ssm.DescribeInstancePatchStatesInput{InstanceIds: aws.StringSlice([]string{instanceID})})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.InstancePatchStates) == 0 {
+		return nil, NewNotFoundError("Instance Patch State", instanceID, awsRegion)
+	}
+
+	return resp.InstancePatchStates[0], nil
+}
+
+// AssertInstancePatchCompliant checks that the managed instance with the given ID has been scanned or patched and has
+// no missing or failed patches.
+func AssertInstancePatchCompliant(t testing.TestingT, awsRegion string, instanceID string) {
+	err := AssertInstancePatchCompliantE(t, awsRegion, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertInstancePatchCompliantE checks that the managed instance with the given ID has been scanned or patched and has
+// no missing or failed patches and returns an error if that is not the case.
+func AssertInstancePatchCompliantE(t testing.TestingT, awsRegion string, instanceID string) error {
+	state, err := GetInstancePatchStateE(t, awsRegion, instanceID)
+	if err != nil {
+		return err
+	}
+
+	missing := aws.Int64Value(state.MissingCount)
+	failed := aws.Int64Value(state.FailedCount)
+	if missing > 0 || failed > 0 {
+		return fmt.Errorf("Instance %s in %s is not patch compliant: %d missing and %d failed patches", instanceID, awsRegion, missing, failed)
+	}
+
+	return nil
+}
+
+// AssertSsmAssociationSucceeded checks that an association of the SSM document with the given name exists for the
+// given instance and that the last execution of that association succeeded.
+func AssertSsmAssociationSucceeded(t testing.TestingT, awsRegion string, documentName string, instanceID string) {
+	err := AssertSsmAssociationSucceededE(t, awsRegion, documentName, instanceID)
+	require.NoError(t, err)
+}
+
+// AssertSsmAssociationSucceededE checks that an association of the SSM document with the given name exists for the
+// given instance and that the last execution of that association succeeded, and returns an error if not.
+func AssertSsmAssociationSucceededE(t testing.TestingT, awsRegion string, documentName string, instanceID string) error {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.DescribeInstanceAssociationsStatus(&ssm.DescribeInstanceAssociationsStatusInput{InstanceId: aws.String(instanceID)})
+	if err != nil {
+		return err
+	}
+
+	for _, association := range resp.InstanceAssociationStatusInfos {
+		if aws.StringValue(association.Name) != documentName {
+			continue
+		}
+
+		if status := aws.StringValue(association.Status); status != ssm.AssociationStatusNameSuccess {
+			return fmt.Errorf("Association of %s with instance %s in %s has status %s: %s", documentName, instanceID, awsRegion, status, aws.StringValue(association.DetailedStatus))
+		}
+		return nil
+	}
+
+	return NewNotFoundError("SSM Association", fmt.Sprintf("%s/%s", documentName, instanceID), awsRegion)
+}
+
+// RunMaintenanceWindowTask runs the Run Command task with the given ID of the given maintenance window right away,
+// against the targets registered for that task, instead of waiting for the window's schedule. It then waits for the
+// command to finish and returns a map from instance ID to the output of the command on that instance.
+func RunMaintenanceWindowTask(t testing.TestingT, awsRegion string, windowID string, windowTaskID string, timeout time.Duration) map[string]*CommandOutput {
+	result, err := RunMaintenanceWindowTaskE(t, awsRegion, windowID, windowTaskID, timeout)
+	require.NoError(t, err)
+	return result
+}
+
+// RunMaintenanceWindowTaskE runs the Run Command task with the given ID of the given maintenance window right away,
+// against the targets registered for that task, instead of waiting for the window's schedule. It then waits for the
+// command to finish and returns a map from instance ID to the output of the command on that instance.
+func RunMaintenanceWindowTaskE(t testing.TestingT, awsRegion string, windowID string, windowTaskID string, timeout time.Duration) (map[string]*CommandOutput, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := client.GetMaintenanceWindowTask(&ssm.GetMaintenanceWindowTaskInput{
+		WindowId:     aws.String(windowID),
+		WindowTaskId: aws.String(windowTaskID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if taskType := aws.StringValue(task.TaskType); taskType != ssm.MaintenanceWindowTaskTypeRunCommand {
+		return nil, fmt.Errorf("Maintenance window task %s has type %s, only %s tasks can be run directly", windowTaskID, taskType, ssm.MaintenanceWindowTaskTypeRunCommand)
+	}
+
+	windowTargets := []*ssm.MaintenanceWindowTarget{}
+	err = client.DescribeMaintenanceWindowTargetsPages(&ssm.DescribeMaintenanceWindowTargetsInput{WindowId: aws.String(windowID)}, func(page *ssm.DescribeMaintenanceWindowTargetsOutput, lastPage bool) bool {
+		windowTargets = append(windowTargets, page.Targets...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := toSsmCommandTargets(task.Targets, windowTargets)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string][]string{}
+	if task.TaskInvocationParameters != nil && task.TaskInvocationParameters.RunCommand != nil {
+		for name, values := range task.TaskInvocationParameters.RunCommand.Parameters {
+			params[name] = aws.StringValueSlice(values)
+		}
+	}
+
+	logger.Logf(t, "Running task %s of maintenance window %s in %s", windowTaskID, windowID, awsRegion)
+	return RunSsmCommandOnInstancesWithClientE(t, client, aws.StringValue(task.TaskArn), params, targets, timeout)
+}
+
+// toSsmCommandTargets converts the targets of a maintenance window task into SsmCommandTargets, resolving any
+// references to targets registered with the maintenance window.
+func toSsmCommandTargets(taskTargets []*ssm.Target, windowTargets []*ssm.MaintenanceWindowTarget) (SsmCommandTargets, error) {
+	windowTargetsByID := map[string]*ssm.MaintenanceWindowTarget{}
+	for _, windowTarget := range windowTargets {
+		windowTargetsByID[aws.StringValue(windowTarget.WindowTargetId)] = windowTarget
+	}
+
+	result := SsmCommandTargets{Tags: map[string][]string{}}
+
+	var addTarget func(target *ssm.Target) error
+	addTarget = func(target *ssm.Target) error {
+		key := aws.StringValue(target.Key)
+		values := aws.StringValueSlice(target.Values)
+
+		switch {
+		case key == "InstanceIds":
+			result.InstanceIds = append(result.InstanceIds, values...)
+		case strings.HasPrefix(key, "tag:"):
+			tagKey := strings.TrimPrefix(key, "tag:")
+			result.Tags[tagKey] = append(result.Tags[tagKey], values...)
+		case key == "WindowTargetIds":
+			for _, windowTargetID := range values {
+				windowTarget, ok := windowTargetsByID[windowTargetID]
+				if !ok {
+					return fmt.Errorf("Maintenance window target %s not found", windowTargetID)
+				}
+				for _, nestedTarget := range windowTarget.Targets {
+					if err := addTarget(nestedTarget); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			return fmt.Errorf("Unsupported maintenance window target key %s", key)
+		}
+
+		return nil
+	}
+
+	for _, target := range taskTargets {
+		if err := addTarget(target); err != nil {
+			return SsmCommandTargets{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// WaitForMaintenanceWindowExecution waits for the next scheduled execution of the given maintenance window that starts
+// after the given time to finish, and returns it. This will fail the test if the execution does not succeed within the
+// given timeout.
+func WaitForMaintenanceWindowExecution(t testing.TestingT, awsRegion string, windowID string, after time.Time, timeout time.Duration) *ssm.MaintenanceWindowExecution {
+	execution, err := WaitForMaintenanceWindowExecutionE(t, awsRegion, windowID, after, timeout)
+	require.NoError(t, err)
+	return execution
+}
+
+// WaitForMaintenanceWindowExecutionE waits for the next scheduled execution of the given maintenance window that starts
+// after the given time to finish, and returns it. An error is returned if the execution does not succeed within the
+// given timeout.
+func WaitForMaintenanceWindowExecutionE(t testing.TestingT, awsRegion string, windowID string, after time.Time, timeout time.Duration) (*ssm.MaintenanceWindowExecution, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	timeBetweenRetries := 10 * time.Second
+	maxRetries := int(timeout.Seconds() / timeBetweenRetries.Seconds())
+	description := fmt.Sprintf("Waiting for an execution of maintenance window %s to complete", windowID)
+
+	input := &ssm.DescribeMaintenanceWindowExecutionsInput{
+		WindowId: aws.String(windowID),
+		Filters: []*ssm.MaintenanceWindowFilter{
+			{Key: aws.String("ExecutedAfter"), Values: aws.StringSlice([]string{after.UTC().Format(time.RFC3339)})},
+		},
+	}
+
+	execution, err := retry.DoWithRetryInterfaceE(t, description, maxRetries, timeBetweenRetries, func() (interface{}, error) {
+		resp, err := client.DescribeMaintenanceWindowExecutions(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resp.WindowExecutions) == 0 {
+			return nil, fmt.Errorf("Maintenance window %s has not run since %s", windowID, after)
+		}
+
+		// Executions are returned in descending order of start time, so the last one is the first to have started
+		execution := resp.WindowExecutions[len(resp.WindowExecutions)-1]
+		switch status := aws.StringValue(execution.Status); status {
+		case ssm.MaintenanceWindowExecutionStatusSuccess:
+			return execution, nil
+		case ssm.MaintenanceWindowExecutionStatusPending, ssm.MaintenanceWindowExecutionStatusInProgress, ssm.MaintenanceWindowExecutionStatusCancelling:
+			return nil, fmt.Errorf("Execution %s of maintenance window %s is %s", aws.StringValue(execution.WindowExecutionId), windowID, status)
+		default:
+			return nil, retry.FatalError{Underlying: fmt.Errorf("Execution %s of maintenance window %s finished with status %s: %s", aws.StringValue(execution.WindowExecutionId), windowID, status, aws.StringValue(execution.StatusDetails))}
+		}
+	})
+	if err != nil {
+		if actualErr, ok := err.(retry.FatalError); ok {
+			return nil, actualErr.Underlying
+		}
+		return nil, err
+	}
+
+	return execution.(*ssm.MaintenanceWindowExecution), nil
+}
@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSsmCommandTargets(t *testing.T) {
+	t.Parallel()
+
+	windowTargets := []*ssm.MaintenanceWindowTarget{
+		{
+			WindowTargetId: aws.String("target-1"),
+			Targets: []*ssm.Target{
+				{Key: aws.String("tag:PatchGroup"), Values: aws.StringSlice([]string{"web"})},
+			},
+		},
+		{
+			WindowTargetId: aws.String("target-2"),
+			Targets: []*ssm.Target{
+				{Key: aws.String("InstanceIds"), Values: aws.StringSlice([]string{"i-1", "i-2"})},
+			},
+		},
+	}
+
+	taskTargets := []*ssm.Target{
+		{Key: aws.String("WindowTargetIds"), Values: aws.StringSlice([]string{"target-1", "target-2"})},
+	}
+
+	targets, err := toSsmCommandTargets(taskTargets, windowTargets)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-1", "i-2"}, targets.InstanceIds)
+	assert.Equal(t, map[string][]string{"PatchGroup": {"web"}}, targets.Tags)
+
+	_, err = toSsmCommandTargets([]*ssm.Target{{Key: aws.String("WindowTargetIds"), Values: aws.StringSlice([]string{"missing"})}}, windowTargets)
+	assert.Error(t, err)
+
+	_, err = toSsmCommandTargets([]*ssm.Target{{Key: aws.String("resource-groups:Name"), Values: aws.StringSlice([]string{"group"})}}, windowTargets)
+	assert.Error(t, err)
+}
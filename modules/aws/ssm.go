@@ -6,6 +6,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -83,6 +84,10 @@ func DeleteParameterE(t testing.TestingT, awsRegion string, keyName string) erro
 
 // DeleteParameterE deletes all versions of SSM Parameter at keyName with the ability to provide the SSM client.
 func DeleteParameterWithClientE(t testing.TestingT, client *ssm.SSM, keyName string) error {
+	if dryrun.Skip(t, "delete SSM Parameter %s", keyName) {
+		return nil
+	}
+
 	_, err := client.DeleteParameter(&ssm.DeleteParameterInput{Name: aws.String(keyName)})
 	if err != nil {
 		return err
@@ -121,7 +126,7 @@ func WaitForSsmInstanceE(t testing.TestingT, awsRegion, instanceID string, timeo
 func WaitForSsmInstanceWithClientE(t testing.TestingT, client *ssm.SSM, instanceID string, timeout time.Duration) error {
 	timeBetweenRetries := 2 * time.Second
 	maxRetries := int(timeout.Seconds() / timeBetweenRetries.Seconds())
-	description := fmt.Sprintf("Waiting for %s to appear in the SSM inventory", instanceID)
+	description := fmt.Sprintf("EC2 Instance %s in the SSM inventory", instanceID)
 
 	input := &ssm.GetInventoryInput{
 		Filters: []*ssm.InventoryFilter{
@@ -132,21 +137,25 @@ func WaitForSsmInstanceWithClientE(t testing.TestingT, client *ssm.SSM, instance
 			},
 		},
 	}
-	_, err := retry.DoWithRetryE(t, description, maxRetries, timeBetweenRetries, func() (string, error) {
-		resp, err := client.GetInventory(input)
+	condition := WaitCondition{
+		Description:  description,
+		DesiredState: "registered",
+		Extract: func() (WaitState, error) {
+			resp, err := client.GetInventory(input)
 
-		if err != nil {
-			return "", err
-		}
+			if err != nil {
+				return WaitState{}, err
+			}
 
-		if len(resp.Entities) != 1 {
-			return "", fmt.Errorf("%s is not in the SSM inventory", instanceID)
-		}
+			if len(resp.Entities) != 1 {
+				return WaitState{Current: "not registered"}, nil
+			}
 
-		return "", nil
-	})
+			return WaitState{Current: "registered", Done: true}, nil
+		},
+	}
 
-	return err
+	return WaitForConditionE(t, condition, maxRetries, timeBetweenRetries)
 }
 
 // WaitForSsmInstance waits until the instance get registered to the SSM inventory.
@@ -260,3 +269,303 @@ func CheckSSMCommandWithClientWithDocumentE(t testing.TestingT, client *ssm.SSM,
 
 	return result, nil
 }
+
+// SsmCommandTargets describes which managed instances an SSM command should run on. Either InstanceIds or Tags (or
+// both) must be set.
+type SsmCommandTargets struct {
+	InstanceIds []string            // IDs of the instances to run the command on
+	Tags        map[string][]string // Run the command on all instances that have one of the given values for each tag key
+}
+
+// RunSsmCommandOnInstances runs the SSM document with the given name and parameters on the given targets, waits for the
+// command to complete on every instance and returns a map from instance ID to the output of the command on that
+// instance. This will fail the test if the command could not be sent or did not succeed on every instance.
+func RunSsmCommandOnInstances(t testing.TestingT, awsRegion string, documentName string, params map[string][]string, targets SsmCommandTargets, timeout time.Duration) map[string]*CommandOutput {
+	result, err := RunSsmCommandOnInstancesE(t, awsRegion, documentName, params, targets, timeout)
+	require.NoError(t, err)
+	return result
+}
+
+// RunSsmCommandOnInstancesE runs the SSM document with the given name and parameters on the given targets, waits for the
+// command to complete on every instance and returns a map from instance ID to the output of the command on that
+// instance. The outputs of all instances are returned even if the command failed on some of them, in which case an
+// SsmCommandFailed error is returned as well.
+func RunSsmCommandOnInstancesE(t testing.TestingT, awsRegion string, documentName string, params map[string][]string, targets SsmCommandTargets, timeout time.Duration) (map[string]*CommandOutput, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+	return RunSsmCommandOnInstancesWithClientE(t, client, documentName, params, targets, timeout)
+}
+
+// RunSsmCommandOnInstancesWithClientE runs the SSM document with the given name and parameters on the given targets
+// with the ability to provide the SSM client. See RunSsmCommandOnInstancesE for details.
+func RunSsmCommandOnInstancesWithClientE(t testing.TestingT, client *ssm.SSM, documentName string, params map[string][]string, targets SsmCommandTargets, timeout time.Duration) (map[string]*CommandOutput, error) {
+	input := &ssm.SendCommandInput{
+		Comment:      aws.String("Terratest SSM"),
+		DocumentName: aws.String(documentName),
+		Parameters:   map[string][]*string{},
+	}
+	for name, values := range params {
+		input.Parameters[name] = aws.StringSlice(values)
+	}
+	if len(targets.InstanceIds) > 0 {
+		input.InstanceIds = aws.StringSlice(targets.InstanceIds)
+	}
+	for key, values := range targets.Tags {
+		input.Targets = append(input.Targets, &ssm.Target{Key: aws.String("tag:" + key), Values: aws.StringSlice(values)})
+	}
+
+	logger.Logf(t, "Running SSM document %s", documentName)
+	resp, err := client.SendCommand(input)
+	if err != nil {
+		return nil, err
+	}
+	commandID := aws.StringValue(resp.Command.CommandId)
+
+	timeBetweenRetries := 2 * time.Second
+	maxRetries := int(timeout.Seconds() / timeBetweenRetries.Seconds())
+	description := fmt.Sprintf("Waiting for SSM command %s to complete", commandID)
+
+	// With Targets, SSM dispatches the command in batches of MaxConcurrency instances, so the invocations listed so far
+	// may all be complete while others have not been created yet. Only the status of the command itself, which
+	// aggregates all of its invocations, says when it is done on every target.
+	_, err = retry.DoWithRetryE(t, description, maxRetries, timeBetweenRetries, func() (string, error) {
+		output, err := client.ListCommands(&ssm.ListCommandsInput{CommandId: aws.String(commandID)})
+		if err != nil {
+			return "", err
+		}
+
+		if len(output.Commands) == 0 {
+			return "", fmt.Errorf("SSM command %s not found", commandID)
+		}
+
+		command := output.Commands[0]
+		if status := aws.StringValue(command.Status); !isTerminalSsmCommandStatus(status) {
+			return "", fmt.Errorf("SSM command %s is still %s, %d of %d targets completed", commandID, status, aws.Int64Value(command.CompletedCount), aws.Int64Value(command.TargetCount))
+		}
+
+		return "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invocations, err := listSsmCommandInvocationsE(client, commandID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]*CommandOutput{}
+	failedInstances := map[string]string{}
+
+	for _, invocation := range invocations {
+		instanceID := aws.StringValue(invocation.InstanceId)
+
+		output, err := client.GetCommandInvocation(&ssm.GetCommandInvocationInput{
+			CommandId:  aws.String(commandID),
+			InstanceId: aws.String(instanceID),
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result[instanceID] = &CommandOutput{
+			Stdout:   aws.StringValue(output.StandardOutputContent),
+			Stderr:   aws.StringValue(output.StandardErrorContent),
+			ExitCode: aws.Int64Value(output.ResponseCode),
+		}
+
+		if status := aws.StringValue(invocation.Status); status != ssm.CommandInvocationStatusSuccess {
+			failedInstances[instanceID] = status
+		}
+	}
+
+	if len(failedInstances) > 0 {
+		return result, SsmCommandFailed{CommandId: commandID, DocumentName: documentName, FailedInstances: failedInstances}
+	}
+
+	return result, nil
+}
+
+// listSsmCommandInvocationsE returns all the invocations of the SSM command with the given ID.
+func listSsmCommandInvocationsE(client *ssm.SSM, commandID string) ([]*ssm.CommandInvocation, error) {
+	invocations := []*ssm.CommandInvocation{}
+
+	input := &ssm.ListCommandInvocationsInput{CommandId: aws.String(commandID)}
+	err := client.ListCommandInvocationsPages(input, func(page *ssm.ListCommandInvocationsOutput, lastPage bool) bool {
+		invocations = append(invocations, page.CommandInvocations...)
+		return true
+	})
+
+	return invocations, err
+}
+
+// isTerminalSsmCommandStatus returns true if an SSM command, or an invocation of it, with the given status will not
+// change anymore. Commands and their invocations share these status names.
+func isTerminalSsmCommandStatus(status string) bool {
+	switch status {
+	case ssm.CommandInvocationStatusPending, ssm.CommandInvocationStatusInProgress, ssm.CommandInvocationStatusDelayed, ssm.CommandInvocationStatusCancelling:
+		return false
+	}
+	return true
+}
+
+// GetSsmDocument returns the description of the SSM document with the given name.
+func GetSsmDocument(t testing.TestingT, awsRegion string, documentName string) *ssm.DocumentDescription {
+	document, err := GetSsmDocumentE(t, awsRegion, documentName)
+	require.NoError(t, err)
+	return document
+}
+
+// GetSsmDocumentE returns the description of the SSM document with the given name.
+func GetSsmDocumentE(t testing.TestingT, awsRegion string, documentName string) (*ssm.DocumentDescription, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.DescribeDocument(&ssm.DescribeDocumentInput{Name: aws.String(documentName)})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Document, nil
+}
+
+// AssertSsmDocumentExists checks that the SSM document with the given name exists and is active.
+func AssertSsmDocumentExists(t testing.TestingT, awsRegion string, documentName string) {
+	err := AssertSsmDocumentExistsE(t, awsRegion, documentName)
+	require.NoError(t, err)
+}
+
+// AssertSsmDocumentExistsE checks that the SSM document with the given name exists and is active and returns an error
+// if it does not.
+func AssertSsmDocumentExistsE(t testing.TestingT, awsRegion string, documentName string) error {
+	document, err := GetSsmDocumentE(t, awsRegion, documentName)
+	if err != nil {
+		return err
+	}
+
+	if status := aws.StringValue(document.Status); status != ssm.DocumentStatusActive {
+		return fmt.Errorf("SSM document %s in %s has status %s instead of %s", documentName, awsRegion, status, ssm.DocumentStatusActive)
+	}
+
+	return nil
+}
+
+// GetSsmDocumentSharedAccountIds returns the IDs of the AWS accounts the SSM document with the given name is shared
+// with. The result contains "all" if the document is public.
+func GetSsmDocumentSharedAccountIds(t testing.TestingT, awsRegion string, documentName string) []string {
+	accountIDs, err := GetSsmDocumentSharedAccountIdsE(t, awsRegion, documentName)
+	require.NoError(t, err)
+	return accountIDs
+}
+
+// GetSsmDocumentSharedAccountIdsE returns the IDs of the AWS accounts the SSM document with the given name is shared
+// with. The result contains "all" if the document is public.
+func GetSsmDocumentSharedAccountIdsE(t testing.TestingT, awsRegion string, documentName string) ([]string, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.DescribeDocumentPermission(&ssm.DescribeDocumentPermissionInput{
+		Name:           aws.String(documentName),
+		PermissionType: aws.String(ssm.DocumentPermissionTypeShare),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return aws.StringValueSlice(resp.AccountIds), nil
+}
+
+// AssertSsmDocumentNotPublic checks that the SSM document with the given name is not shared publicly.
+func AssertSsmDocumentNotPublic(t testing.TestingT, awsRegion string, documentName string) {
+	err := AssertSsmDocumentNotPublicE(t, awsRegion, documentName)
+	require.NoError(t, err)
+}
+
+// AssertSsmDocumentNotPublicE checks that the SSM document with the given name is not shared publicly and returns an
+// error if it is.
+func AssertSsmDocumentNotPublicE(t testing.TestingT, awsRegion string, documentName string) error {
+	accountIDs, err := GetSsmDocumentSharedAccountIdsE(t, awsRegion, documentName)
+	if err != nil {
+		return err
+	}
+
+	for _, accountID := range accountIDs {
+		if accountID == "all" {
+			return fmt.Errorf("SSM document %s in %s is shared publicly", documentName, awsRegion)
+		}
+	}
+
+	return nil
+}
+
+// RunSsmAutomation starts an execution of the SSM automation document with the given name and parameters, waits for
+// it to finish and returns its outputs. This will fail the test if the execution does not succeed.
+func RunSsmAutomation(t testing.TestingT, awsRegion string, documentName string, params map[string][]string, timeout time.Duration) map[string][]string {
+	outputs, err := RunSsmAutomationE(t, awsRegion, documentName, params, timeout)
+	require.NoError(t, err)
+	return outputs
+}
+
+// RunSsmAutomationE starts an execution of the SSM automation document with the given name and parameters, waits for
+// it to finish and returns its outputs. An error is returned if the execution does not succeed.
+func RunSsmAutomationE(t testing.TestingT, awsRegion string, documentName string, params map[string][]string, timeout time.Duration) (map[string][]string, error) {
+	client, err := NewSsmClientE(t, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &ssm.StartAutomationExecutionInput{
+		DocumentName: aws.String(documentName),
+		Parameters:   map[string][]*string{},
+	}
+	for name, values := range params {
+		input.Parameters[name] = aws.StringSlice(values)
+	}
+
+	logger.Logf(t, "Starting SSM automation %s in %s", documentName, awsRegion)
+	resp, err := client.StartAutomationExecution(input)
+	if err != nil {
+		return nil, err
+	}
+	executionID := aws.StringValue(resp.AutomationExecutionId)
+
+	timeBetweenRetries := 5 * time.Second
+	maxRetries := int(timeout.Seconds() / timeBetweenRetries.Seconds())
+	description := fmt.Sprintf("Waiting for SSM automation execution %s to complete", executionID)
+
+	execution, err := retry.DoWithRetryInterfaceE(t, description, maxRetries, timeBetweenRetries, func() (interface{}, error) {
+		out, err := client.GetAutomationExecution(&ssm.GetAutomationExecutionInput{AutomationExecutionId: aws.String(executionID)})
+		if err != nil {
+			return nil, err
+		}
+
+		execution := out.AutomationExecution
+		switch status := aws.StringValue(execution.AutomationExecutionStatus); status {
+		case ssm.AutomationExecutionStatusSuccess:
+			return execution, nil
+		case ssm.AutomationExecutionStatusFailed, ssm.AutomationExecutionStatusCancelled, ssm.AutomationExecutionStatusTimedOut:
+			return nil, retry.FatalError{Underlying: fmt.Errorf("SSM automation execution %s finished with status %s: %s", executionID, status, aws.StringValue(execution.FailureMessage))}
+		default:
+			return nil, fmt.Errorf("SSM automation execution %s is %s", executionID, status)
+		}
+	})
+	if err != nil {
+		if actualErr, ok := err.(retry.FatalError); ok {
+			return nil, actualErr.Underlying
+		}
+		return nil, err
+	}
+
+	outputs := map[string][]string{}
+	for name, values := range execution.(*ssm.AutomationExecution).Outputs {
+		outputs[name] = aws.StringValueSlice(values)
+	}
+
+	return outputs, nil
+}
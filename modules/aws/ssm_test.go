@@ -38,3 +38,14 @@ func TestParameterIsDeleted(t *testing.T) {
 	assert.Equal(t, actualValue, "")
 	assert.Error(t, err)
 }
+
+func TestIsTerminalSsmCommandStatus(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, isTerminalSsmCommandStatus("Pending"))
+	assert.False(t, isTerminalSsmCommandStatus("InProgress"))
+	assert.False(t, isTerminalSsmCommandStatus("Delayed"))
+	assert.True(t, isTerminalSsmCommandStatus("Success"))
+	assert.True(t, isTerminalSsmCommandStatus("Failed"))
+	assert.True(t, isTerminalSsmCommandStatus("TimedOut"))
+}
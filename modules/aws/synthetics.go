@@ -0,0 +1,278 @@
+package aws
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/synthetics"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// StartedCanaryRun is a run of a CloudWatch Synthetics canary started by StartCanaryRunE. StartCanary does not return
+// the ID of the run, so the run is found by waiting for one that was not there before.
+type StartedCanaryRun struct {
+	Region         string
+	CanaryName     string
+	previousRunIds map[string]bool // The IDs of the runs of the canary before it was started
+}
+
+// StartCanaryRun starts the CloudWatch Synthetics canary with the given name, which must not be running already, and
+// returns its upcoming run to pass to WaitForCanaryRunToPass. This will fail the test if there is an error.
+func StartCanaryRun(t testing.TestingT, region string, canaryName string) StartedCanaryRun {
+	started, err := StartCanaryRunE(t, region, canaryName)
+	require.NoError(t, err)
+	return started
+}
+
+// StartCanaryRunE starts the CloudWatch Synthetics canary with the given name, which must not be running already, and
+// returns its upcoming run to pass to WaitForCanaryRunToPassE. A canary with a schedule expression of rate(0 minutes)
+// runs once and stops; other canaries keep running on their schedule until stopped, e.g. with StopCanaryE.
+func StartCanaryRunE(t testing.TestingT, region string, canaryName string) (StartedCanaryRun, error) {
+	client, err := NewSyntheticsClientE(t, region)
+	if err != nil {
+		return StartedCanaryRun{}, err
+	}
+
+	runs, err := GetCanaryRunsE(t, region, canaryName)
+	if err != nil {
+		return StartedCanaryRun{}, err
+	}
+
+	previousRunIds := map[string]bool{}
+	for _, run := range runs {
+		previousRunIds[aws.StringValue(run.Id)] = true
+	}
+
+	logger.Logf(t, "Starting CloudWatch Synthetics canary %s in %s", canaryName, region)
+	if _, err := client.StartCanary(&synthetics.StartCanaryInput{Name: aws.String(canaryName)}); err != nil {
+		return StartedCanaryRun{}, err
+	}
+
+	return StartedCanaryRun{Region: region, CanaryName: canaryName, previousRunIds: previousRunIds}, nil
+}
+
+// StopCanary stops the CloudWatch Synthetics canary with the given name. This will fail the test if there is an error.
+func StopCanary(t testing.TestingT, region string, canaryName string) {
+	require.NoError(t, StopCanaryE(t, region, canaryName))
+}
+
+// StopCanaryE stops the CloudWatch Synthetics canary with the given name.
+func StopCanaryE(t testing.TestingT, region string, canaryName string) error {
+	if dryrun.Skip(t, "stop canary %s in %s", canaryName, region) {
+		return nil
+	}
+
+	client, err := NewSyntheticsClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Stopping CloudWatch Synthetics canary %s in %s", canaryName, region)
+	_, err = client.StopCanary(&synthetics.StopCanaryInput{Name: aws.String(canaryName)})
+	return err
+}
+
+// GetCanaryRuns returns the most recent runs of the CloudWatch Synthetics canary with the given name, most recent first.
+// This will fail the test if there is an error.
+func GetCanaryRuns(t testing.TestingT, region string, canaryName string) []*synthetics.CanaryRun {
+	runs, err := GetCanaryRunsE(t, region, canaryName)
+	require.NoError(t, err)
+	return runs
+}
+
+// GetCanaryRunsE returns the most recent runs of the CloudWatch Synthetics canary with the given name, most recent
+// first.
+func GetCanaryRunsE(t testing.TestingT, region string, canaryName string) ([]*synthetics.CanaryRun, error) {
+	client, err := NewSyntheticsClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.GetCanaryRuns(&synthetics.GetCanaryRunsInput{Name: aws.String(canaryName), MaxResults: aws.Int64(100)})
+	if err != nil {
+		return nil, err
+	}
+	return output.CanaryRuns, nil
+}
+
+// WaitForCanaryRunToPass waits until the given run of a CloudWatch Synthetics canary has passed, failing early if it
+// fails instead, and returns the run. This will fail the test if there is an error.
+func WaitForCanaryRunToPass(t testing.TestingT, started StartedCanaryRun, maxRetries int, sleepBetweenRetries time.Duration) *synthetics.CanaryRun {
+	run, err := WaitForCanaryRunToPassE(t, started, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return run
+}
+
+// WaitForCanaryRunToPassE waits until the given run of a CloudWatch Synthetics canary has passed and returns the run,
+// or returns a CanaryRunFailed error early if it fails instead. The run is returned in that case too, so that its
+// artifacts can be fetched with FetchCanaryRunArtifactsE to see why it failed.
+func WaitForCanaryRunToPassE(t testing.TestingT, started StartedCanaryRun, maxRetries int, sleepBetweenRetries time.Duration) (*synthetics.CanaryRun, error) {
+	var run *synthetics.CanaryRun
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("run of CloudWatch Synthetics canary %s", started.CanaryName),
+		DesiredState: synthetics.CanaryRunStatePassed,
+		Extract: func() (WaitState, error) {
+			runs, err := GetCanaryRunsE(t, started.Region, started.CanaryName)
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			run = findStartedCanaryRun(runs, started.previousRunIds)
+			return canaryRunWaitState(started.CanaryName, run)
+		},
+	}
+
+	err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	return run, err
+}
+
+// findStartedCanaryRun returns the oldest of the given runs, which are sorted most recent first, that is not one of the
+// given previous runs, or nil if the started run has not shown up yet.
+func findStartedCanaryRun(runs []*synthetics.CanaryRun, previousRunIds map[string]bool) *synthetics.CanaryRun {
+	var started *synthetics.CanaryRun
+	for _, run := range runs {
+		if !previousRunIds[aws.StringValue(run.Id)] {
+			started = run
+		}
+	}
+	return started
+}
+
+// canaryRunWaitState returns the state of the given run of a canary for WaitForCanaryRunToPassE. Returns a
+// retry.FatalError if the run failed, as it won't pass anymore.
+func canaryRunWaitState(canaryName string, run *synthetics.CanaryRun) (WaitState, error) {
+	if run == nil || run.Status == nil {
+		return WaitState{Current: "not started"}, nil
+	}
+
+	state := aws.StringValue(run.Status.State)
+	if state == synthetics.CanaryRunStateFailed {
+		return WaitState{}, retry.FatalError{Underlying: CanaryRunFailed{
+			CanaryName: canaryName,
+			RunId:      aws.StringValue(run.Id),
+			ReasonCode: aws.StringValue(run.Status.StateReasonCode),
+			Reason:     aws.StringValue(run.Status.StateReason),
+		}}
+	}
+	return WaitState{Current: state, Done: state == synthetics.CanaryRunStatePassed}, nil
+}
+
+// FetchCanaryRunArtifacts downloads the artifacts of the given run of a CloudWatch Synthetics canary and returns their
+// local paths. This will fail the test if there is an error.
+func FetchCanaryRunArtifacts(t testing.TestingT, region string, run *synthetics.CanaryRun, localDirectory string) []string {
+	paths, err := FetchCanaryRunArtifactsE(t, region, run, localDirectory)
+	require.NoError(t, err)
+	return paths
+}
+
+// FetchCanaryRunArtifactsE downloads the artifacts that the given run of a CloudWatch Synthetics canary stored in S3,
+// such as its results JSON, logs, screenshots and HAR files, to localDirectory/<canaryname>/<runid>, keeping their paths
+// relative to the artifact location of the run, and returns their local paths. The artifact bucket is assumed to be in
+// the given region, as it is by default.
+func FetchCanaryRunArtifactsE(t testing.TestingT, region string, run *synthetics.CanaryRun, localDirectory string) ([]string, error) {
+	client, err := NewS3ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, prefix := parseCanaryArtifactLocation(aws.StringValue(run.ArtifactS3Location))
+	runDirectory := filepath.Join(localDirectory, aws.StringValue(run.Name), aws.StringValue(run.Id))
+
+	keys := []string{}
+	err = client.ListObjectsV2Pages(&s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String(prefix + "/")}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Downloading %d artifacts of run %s of canary %s from s3://%s/%s to %s", len(keys), aws.StringValue(run.Id), aws.StringValue(run.Name), bucket, prefix, runDirectory)
+
+	paths := []string{}
+	for _, key := range keys {
+		localPath, err := canaryArtifactLocalPath(runDirectory, prefix, key)
+		if err != nil {
+			return paths, err
+		}
+		if err := downloadS3ObjectE(client, bucket, key, localPath); err != nil {
+			return paths, err
+		}
+		paths = append(paths, localPath)
+	}
+	return paths, nil
+}
+
+// parseCanaryArtifactLocation returns the bucket and the key prefix of the given artifact location of a canary run,
+// which is given as bucket/prefix, optionally starting with s3://.
+func parseCanaryArtifactLocation(location string) (string, string) {
+	location = strings.Trim(strings.TrimPrefix(location, "s3://"), "/")
+	parts := strings.SplitN(location, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// canaryArtifactLocalPath returns the path in the given local directory to download the artifact with the given key to,
+// relative to the given prefix. Keys with .. segments are kept within the directory.
+func canaryArtifactLocalPath(localDirectory string, prefix string, key string) (string, error) {
+	relativePath := path.Clean("/" + strings.TrimPrefix(key, prefix))
+	if relativePath == "/" {
+		return "", fmt.Errorf("S3 key %s is not an artifact under %s", key, prefix)
+	}
+	return filepath.Join(localDirectory, filepath.FromSlash(relativePath)), nil
+}
+
+// downloadS3ObjectE downloads the S3 object with the given key to the given local path, creating its directory if
+// needed.
+func downloadS3ObjectE(client *s3.S3, bucket string, key string, localPath string) error {
+	output, err := client.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, output.Body)
+	return err
+}
+
+// NewSyntheticsClient creates a CloudWatch Synthetics client.
+func NewSyntheticsClient(t testing.TestingT, region string) *synthetics.Synthetics {
+	client, err := NewSyntheticsClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewSyntheticsClientE creates a CloudWatch Synthetics client.
+func NewSyntheticsClientE(t testing.TestingT, region string) (*synthetics.Synthetics, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return synthetics.New(sess), nil
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/synthetics"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCanaryRun(id string, state string) *synthetics.CanaryRun {
+	return &synthetics.CanaryRun{Id: aws.String(id), Status: &synthetics.CanaryRunStatus{State: aws.String(state)}}
+}
+
+func TestFindStartedCanaryRun(t *testing.T) {
+	t.Parallel()
+
+	previous := map[string]bool{"run-1": true, "run-2": true}
+
+	assert.Nil(t, findStartedCanaryRun([]*synthetics.CanaryRun{newCanaryRun("run-2", "PASSED"), newCanaryRun("run-1", "PASSED")}, previous))
+
+	// A canary that keeps running on its schedule may have run again since, so the oldest new run is the started one
+	runs := []*synthetics.CanaryRun{newCanaryRun("run-4", "RUNNING"), newCanaryRun("run-3", "PASSED"), newCanaryRun("run-2", "PASSED")}
+	assert.Equal(t, "run-3", aws.StringValue(findStartedCanaryRun(runs, previous).Id))
+}
+
+func TestCanaryRunWaitState(t *testing.T) {
+	t.Parallel()
+
+	state, err := canaryRunWaitState("my-canary", nil)
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "not started"}, state)
+
+	state, err = canaryRunWaitState("my-canary", newCanaryRun("run-3", "RUNNING"))
+	require.NoError(t, err)
+	assert.Equal(t, WaitState{Current: "RUNNING"}, state)
+
+	state, err = canaryRunWaitState("my-canary", newCanaryRun("run-3", "PASSED"))
+	require.NoError(t, err)
+	assert.True(t, state.Done)
+
+	failed := newCanaryRun("run-3", "FAILED")
+	failed.Status.StateReasonCode = aws.String("CANARY_FAILURE")
+	failed.Status.StateReason = aws.String("Timed out waiting for selector #login")
+	_, err = canaryRunWaitState("my-canary", failed)
+	fatalErr, isFatalErr := err.(retry.FatalError)
+	require.True(t, isFatalErr)
+	assert.EqualError(t, fatalErr.Underlying, "Run run-3 of CloudWatch Synthetics canary my-canary failed (CANARY_FAILURE): Timed out waiting for selector #login")
+	assert.True(t, errors.Is(fatalErr.Underlying, ErrOperationFailed))
+}
+
+func TestParseCanaryArtifactLocation(t *testing.T) {
+	t.Parallel()
+
+	bucket, prefix := parseCanaryArtifactLocation("cw-syn-results-123-us-east-1/canary/us-east-1/my-canary-abc/2021/10/14/12/34-56-789")
+	assert.Equal(t, "cw-syn-results-123-us-east-1", bucket)
+	assert.Equal(t, "canary/us-east-1/my-canary-abc/2021/10/14/12/34-56-789", prefix)
+
+	bucket, prefix = parseCanaryArtifactLocation("s3://my-bucket/runs/")
+	assert.Equal(t, "my-bucket", bucket)
+	assert.Equal(t, "runs", prefix)
+}
+
+func TestCanaryArtifactLocalPath(t *testing.T) {
+	t.Parallel()
+
+	localPath, err := canaryArtifactLocalPath("/tmp/artifacts", "runs/1", "runs/1/screenshots/login-succeeded.png")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/tmp/artifacts", "screenshots", "login-succeeded.png"), localPath)
+
+	localPath, err = canaryArtifactLocalPath("/tmp/artifacts", "runs/1", "runs/1/../../../etc/passwd")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/tmp/artifacts", "etc", "passwd"), localPath)
+
+	_, err = canaryArtifactLocalPath("/tmp/artifacts", "runs/1", "runs/1/")
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package aws
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// GetTargetsInAvailabilityZone gets the registered targets of the given load balancer target group that are in the
+// given Availability Zone. Only instance and IP targets are supported.
+func GetTargetsInAvailabilityZone(t testing.TestingT, region string, targetGroupArn string, availabilityZone string) []*elbv2.TargetDescription {
+	targets, err := GetTargetsInAvailabilityZoneE(t, region, targetGroupArn, availabilityZone)
+	require.NoError(t, err)
+	return targets
+}
+
+// GetTargetsInAvailabilityZoneE gets the registered targets of the given load balancer target group that are in the
+// given Availability Zone, or all of its targets if the zone is empty. Only instance and IP targets are supported.
+func GetTargetsInAvailabilityZoneE(t testing.TestingT, region string, targetGroupArn string, availabilityZone string) ([]*elbv2.TargetDescription, error) {
+	client, err := NewElbV2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := client.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{TargetGroupArns: aws.StringSlice([]string{targetGroupArn})})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups.TargetGroups) == 0 {
+		return nil, NewNotFoundError("Target group", targetGroupArn, region)
+	}
+	group := groups.TargetGroups[0]
+
+	health, err := client.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{TargetGroupArn: aws.String(targetGroupArn)})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []*elbv2.TargetDescription{}
+	for _, description := range health.TargetHealthDescriptions {
+		targets = append(targets, description.Target)
+	}
+	if len(targets) == 0 || availabilityZone == "" {
+		return targets, nil
+	}
+
+	ec2Client, err := NewEc2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	targetZones, err := getTargetAvailabilityZonesE(ec2Client, aws.StringValue(group.TargetType), aws.StringValue(group.VpcId), targets)
+	if err != nil {
+		return nil, err
+	}
+
+	targetsInZone := []*elbv2.TargetDescription{}
+	for _, target := range targets {
+		if targetZones[aws.StringValue(target.Id)] == availabilityZone {
+			targetsInZone = append(targetsInZone, target)
+		}
+	}
+
+	return targetsInZone, nil
+}
+
+// DeregisterTargetsInAvailabilityZone deregisters all the targets of the given load balancer target group that are in
+// the given Availability Zone, which simulates the loss of that zone for the load balancer, and returns the deregistered
+// targets so they can be registered again with RegisterTargets.
+func DeregisterTargetsInAvailabilityZone(t testing.TestingT, region string, targetGroupArn string, availabilityZone string) []*elbv2.TargetDescription {
+	targets, err := DeregisterTargetsInAvailabilityZoneE(t, region, targetGroupArn, availabilityZone)
+	require.NoError(t, err)
+	return targets
+}
+
+// DeregisterTargetsInAvailabilityZoneE deregisters all the targets of the given load balancer target group that are in
+// the given Availability Zone (or all of its targets, if the zone is empty), which simulates the loss of that zone for
+// the load balancer, and returns the deregistered targets so they can be registered again with RegisterTargetsE.
+func DeregisterTargetsInAvailabilityZoneE(t testing.TestingT, region string, targetGroupArn string, availabilityZone string) ([]*elbv2.TargetDescription, error) {
+	if dryrun.Skip(t, "deregister the targets of target group %s in Availability Zone %q", targetGroupArn, availabilityZone) {
+		return nil, nil
+	}
+
+	targets, err := GetTargetsInAvailabilityZoneE(t, region, targetGroupArn, availabilityZone)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return targets, nil
+	}
+
+	client, err := NewElbV2ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Deregistering %d targets in zone '%s' from target group %s", len(targets), availabilityZone, targetGroupArn)
+	_, err = client.DeregisterTargets(&elbv2.DeregisterTargetsInput{TargetGroupArn: aws.String(targetGroupArn), Targets: targets})
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// RegisterTargets registers the given targets with the given load balancer target group.
+func RegisterTargets(t testing.TestingT, region string, targetGroupArn string, targets []*elbv2.TargetDescription) {
+	require.NoError(t, RegisterTargetsE(t, region, targetGroupArn, targets))
+}
+
+// RegisterTargetsE registers the given targets with the given load balancer target group.
+func RegisterTargetsE(t testing.TestingT, region string, targetGroupArn string, targets []*elbv2.TargetDescription) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	client, err := NewElbV2ClientE(t, region)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Registering %d targets with target group %s", len(targets), targetGroupArn)
+	_, err = client.RegisterTargets(&elbv2.RegisterTargetsInput{TargetGroupArn: aws.String(targetGroupArn), Targets: targets})
+	return err
+}
+
+// getTargetAvailabilityZonesE returns the Availability Zone of each of the given targets, keyed by target ID. Instance
+// targets are looked up directly, IP targets by finding the subnet of the VPC that contains their IP address.
+func getTargetAvailabilityZonesE(client *ec2.EC2, targetType string, vpcID string, targets []*elbv2.TargetDescription) (map[string]string, error) {
+	zones := map[string]string{}
+
+	switch targetType {
+	case elbv2.TargetTypeEnumInstance:
+		ids := []string{}
+		for _, target := range targets {
+			ids = append(ids, aws.StringValue(target.Id))
+		}
+
+		err := client.DescribeInstancesPages(&ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice(ids)}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					zones[aws.StringValue(instance.InstanceId)] = aws.StringValue(instance.Placement.AvailabilityZone)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	case elbv2.TargetTypeEnumIp:
+		vpcIDFilter := ec2.Filter{Name: aws.String(vpcIDFilterName), Values: aws.StringSlice([]string{vpcID})}
+		output, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{Filters: []*ec2.Filter{&vpcIDFilter}})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range targets {
+			// Targets registered with an explicit zone keep it, e.g. "all" for IP addresses outside of the VPC
+			if target.AvailabilityZone != nil {
+				zones[aws.StringValue(target.Id)] = aws.StringValue(target.AvailabilityZone)
+				continue
+			}
+			zones[aws.StringValue(target.Id)] = findSubnetAvailabilityZoneForIp(output.Subnets, aws.StringValue(target.Id))
+		}
+
+	default:
+		return nil, fmt.Errorf("Target groups of type %s are not supported", targetType)
+	}
+
+	return zones, nil
+}
+
+// findSubnetAvailabilityZoneForIp returns the Availability Zone of the subnet whose CIDR block contains the given IP
+// address, or an empty string if there is none.
+func findSubnetAvailabilityZoneForIp(subnets []*ec2.Subnet, ip string) string {
+	parsedIP := net.ParseIP(ip)
+	for _, subnet := range subnets {
+		_, cidr, err := net.ParseCIDR(aws.StringValue(subnet.CidrBlock))
+		if err == nil && parsedIP != nil && cidr.Contains(parsedIP) {
+			return aws.StringValue(subnet.AvailabilityZone)
+		}
+	}
+	return ""
+}
+
+// NewElbV2Client creates a new ELBv2 client, which is used for Application and Network Load Balancers.
+func NewElbV2Client(t testing.TestingT, region string) *elbv2.ELBV2 {
+	client, err := NewElbV2ClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewElbV2ClientE creates a new ELBv2 client, which is used for Application and Network Load Balancers.
+func NewElbV2ClientE(t testing.TestingT, region string) (*elbv2.ELBV2, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+	return elbv2.New(sess), nil
+}
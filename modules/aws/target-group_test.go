@@ -0,0 +1,23 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSubnetAvailabilityZoneForIp(t *testing.T) {
+	t.Parallel()
+
+	subnets := []*ec2.Subnet{
+		{CidrBlock: aws.String("10.0.0.0/24"), AvailabilityZone: aws.String("us-east-1a")},
+		{CidrBlock: aws.String("10.0.1.0/24"), AvailabilityZone: aws.String("us-east-1b")},
+	}
+
+	assert.Equal(t, "us-east-1a", findSubnetAvailabilityZoneForIp(subnets, "10.0.0.12"))
+	assert.Equal(t, "us-east-1b", findSubnetAvailabilityZoneForIp(subnets, "10.0.1.200"))
+	assert.Equal(t, "", findSubnetAvailabilityZoneForIp(subnets, "10.0.2.1"))
+	assert.Equal(t, "", findSubnetAvailabilityZoneForIp(subnets, "not-an-ip"))
+}
@@ -0,0 +1,39 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/gruntwork-io/terratest/modules/ratelimit"
+)
+
+// ratelimitServicePrefix is the prefix of the ratelimit services of AWS APIs, which are named after the AWS service,
+// e.g. "aws/ec2" or "aws/autoscaling".
+const ratelimitServicePrefix = "aws/"
+
+// rateLimitHandler waits for the ratelimit limit of the service of a request.
+var rateLimitHandler = request.NamedHandler{
+	Name: "terratest.RateLimitHandler",
+	Fn: func(r *request.Request) {
+		if err := ratelimit.Wait(r.Context(), ratelimitServicePrefix+r.ClientInfo.ServiceName); err != nil {
+			r.Error = err
+		}
+	},
+}
+
+// configureThrottling makes every call of the clients created with the given session wait for the ratelimit limit of
+// its service, and retry calls that are throttled (e.g. with RequestLimitExceeded) up to ratelimit.MaxThrottleRetries
+// times, with longer backoffs than the SDK uses by default.
+func configureThrottling(sess *session.Session) {
+	// Sign handlers run for every attempt of a call, including retries. Waiting before the request is signed rather than
+	// when it is sent keeps the signature from going stale while waiting.
+	sess.Handlers.Sign.PushFrontNamed(rateLimitHandler)
+
+	request.WithRetryer(sess.Config, client.DefaultRetryer{
+		NumMaxRetries:    ratelimit.MaxThrottleRetries(),
+		MinThrottleDelay: 1 * time.Second,
+		MaxThrottleDelay: 30 * time.Second,
+	})
+}
@@ -0,0 +1,25 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gruntwork-io/terratest/modules/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureThrottling(t *testing.T) {
+	t.Parallel()
+
+	sess, err := newConfiguredSession(aws.NewConfig().WithRegion("us-east-1").WithCredentials(CreateAwsCredentials("id", "secret")))
+	require.NoError(t, err)
+
+	assert.True(t, sess.Handlers.Sign.SwapNamed(rateLimitHandler), "rate limit handler not added")
+
+	retryer, ok := ec2.New(sess).Retryer.(client.DefaultRetryer)
+	require.True(t, ok)
+	assert.Equal(t, ratelimit.MaxThrottleRetries(), retryer.NumMaxRetries)
+}
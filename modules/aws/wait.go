@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/clock"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// WaitState is the state of a resource being waited for, as looked up by a StateExtractor.
+type WaitState struct {
+	Current string // The current state, e.g. "CREATE_IN_PROGRESS" or "1/3 instances"
+	Done    bool   // True once the desired state is reached
+}
+
+// StateExtractor looks up the current state of the resource being waited for. If it returns an error, the error is
+// recorded and polling continues, unless the error is a retry.FatalError, e.g. because the resource reached a failed
+// state it can't recover from, in which case its underlying error is returned right away.
+type StateExtractor func() (WaitState, error)
+
+// WaitCondition describes what to wait for.
+type WaitCondition struct {
+	Description  string         // The resource waited for, e.g. "CloudFormation stack my-stack"
+	DesiredState string         // The state waited for, e.g. "complete", used in progress logs and errors
+	Extract      StateExtractor // Looks up the current state
+}
+
+// WaitObservation is a span of polls in the timeline of a wait that observed the same state or error in a row.
+type WaitObservation struct {
+	From  time.Duration // Time since the start of the wait of the first poll of the span
+	To    time.Duration // Time since the start of the wait of the last poll of the span
+	Polls int
+	State string // The observed state, if the polls succeeded
+	Error string // The error the polls returned, if they failed
+}
+
+func (observation WaitObservation) String() string {
+	span := observation.From.Round(time.Second).String()
+	if observation.Polls > 1 {
+		span = fmt.Sprintf("%s-%s (%d polls)", observation.From.Round(time.Second), observation.To.Round(time.Second), observation.Polls)
+	}
+	if observation.Error != "" {
+		return fmt.Sprintf("%s: error: %s", span, observation.Error)
+	}
+	return fmt.Sprintf("%s: %s", span, observation.State)
+}
+
+// WaitForCondition polls the state of the given condition up to maxRetries times, sleeping for sleepBetweenRetries in
+// between, until it reaches the desired state. This will fail the test if it does not.
+func WaitForCondition(t testing.TestingT, condition WaitCondition, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForConditionE polls the state of the given condition up to maxRetries times, sleeping for sleepBetweenRetries in
+// between, until it reaches the desired state. Each poll logs the current and desired state along with the elapsed and
+// remaining time. If the desired state is not reached, this returns a WaitTimedOut error with the timeline of the
+// observed states. If the state extractor returns a retry.FatalError, its underlying error is returned right away.
+func WaitForConditionE(t testing.TestingT, condition WaitCondition, maxRetries int, sleepBetweenRetries time.Duration) error {
+	waitClock := clock.Default()
+	start := waitClock.Now()
+	timeline := []WaitObservation{}
+
+	for i := 0; i <= maxRetries; i++ {
+		state, err := condition.Extract()
+		elapsed := waitClock.Now().Sub(start)
+
+		if fatalErr, isFatalErr := err.(retry.FatalError); isFatalErr {
+			logger.Logf(t, "Stopped waiting for %s after %s: %s", condition.Description, elapsed.Round(time.Second), fatalErr.Underlying)
+			return fatalErr.Underlying
+		}
+		if err == nil && state.Done {
+			logger.Logf(t, "%s is %s after %s", condition.Description, state.Current, elapsed.Round(time.Second))
+			return nil
+		}
+
+		timeline = addWaitObservation(timeline, elapsed, state, err)
+
+		remaining := time.Duration(maxRetries-i) * sleepBetweenRetries
+		current := state.Current
+		if err != nil {
+			current = fmt.Sprintf("unknown (%s)", err)
+		}
+		logger.Logf(t, "Waiting for %s: state is %s, want %s (elapsed %s, up to %s remaining)", condition.Description, current, condition.DesiredState, elapsed.Round(time.Second), remaining)
+
+		if i < maxRetries {
+			waitClock.Sleep(sleepBetweenRetries)
+		}
+	}
+
+	return WaitTimedOut{
+		Description:  condition.Description,
+		DesiredState: condition.DesiredState,
+		Elapsed:      waitClock.Now().Sub(start),
+		Timeline:     timeline,
+	}
+}
+
+// addWaitObservation adds the given poll to the given timeline, extending its last span if the poll observed the same
+// state or error.
+func addWaitObservation(timeline []WaitObservation, elapsed time.Duration, state WaitState, err error) []WaitObservation {
+	observation := WaitObservation{From: elapsed, To: elapsed, Polls: 1, State: state.Current}
+	if err != nil {
+		observation = WaitObservation{From: elapsed, To: elapsed, Polls: 1, Error: err.Error()}
+	}
+
+	if len(timeline) > 0 {
+		last := &timeline[len(timeline)-1]
+		if last.State == observation.State && last.Error == observation.Error {
+			last.To = elapsed
+			last.Polls++
+			return timeline
+		}
+	}
+	return append(timeline, observation)
+}
+
+// WaitTimedOut is returned by WaitForConditionE when the desired state is not reached in time. Its message includes the
+// timeline of the observed states.
+type WaitTimedOut struct {
+	Description  string
+	DesiredState string
+	Elapsed      time.Duration
+	Timeline     []WaitObservation
+}
+
+func (err WaitTimedOut) Error() string {
+	lines := []string{fmt.Sprintf("Timed out after %s waiting for %s to be %s. Observed:", err.Elapsed.Round(time.Second), err.Description, err.DesiredState)}
+	for _, observation := range err.Timeline {
+		lines = append(lines, "  "+observation.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Is returns true if the target is ErrTimedOut.
+func (err WaitTimedOut) Is(target error) bool {
+	return target == ErrTimedOut
+}
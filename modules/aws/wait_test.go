@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/clock"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForConditionReachesDesiredState(t *testing.T) {
+	t.Parallel()
+
+	states := []string{"CREATE_IN_PROGRESS", "CREATE_IN_PROGRESS", "CREATE_COMPLETE"}
+	polls := 0
+	condition := WaitCondition{
+		Description:  "CloudFormation stack test",
+		DesiredState: "complete",
+		Extract: func() (WaitState, error) {
+			state := states[polls]
+			polls++
+			return WaitState{Current: state, Done: state == "CREATE_COMPLETE"}, nil
+		},
+	}
+
+	require.NoError(t, WaitForConditionE(t, condition, 5, 0))
+	assert.Equal(t, 3, polls)
+}
+
+func TestWaitForConditionReturnsFatalErrorsRightAway(t *testing.T) {
+	t.Parallel()
+
+	failed := CloudFormationStackFailed{StackName: "test", Status: "ROLLBACK_COMPLETE"}
+	polls := 0
+	condition := WaitCondition{
+		Description:  "CloudFormation stack test",
+		DesiredState: "complete",
+		Extract: func() (WaitState, error) {
+			polls++
+			return WaitState{}, retry.FatalError{Underlying: failed}
+		},
+	}
+
+	assert.Equal(t, failed, WaitForConditionE(t, condition, 5, 0))
+	assert.Equal(t, 1, polls)
+}
+
+func TestWaitForConditionTimesOutWithTimeline(t *testing.T) {
+	t.Parallel()
+
+	results := []error{nil, nil, errors.New("throttled"), nil}
+	polls := 0
+	condition := WaitCondition{
+		Description:  "ASG test",
+		DesiredState: "at desired capacity",
+		Extract: func() (WaitState, error) {
+			err := results[polls]
+			polls++
+			return WaitState{Current: "1/3 instances"}, err
+		},
+	}
+
+	err := WaitForConditionE(t, condition, 3, 0)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTimedOut))
+
+	timedOut, ok := err.(WaitTimedOut)
+	require.True(t, ok)
+	require.Len(t, timedOut.Timeline, 3)
+	assert.Equal(t, 2, timedOut.Timeline[0].Polls)
+	assert.Equal(t, "1/3 instances", timedOut.Timeline[0].State)
+	assert.Equal(t, "throttled", timedOut.Timeline[1].Error)
+	assert.Equal(t, 1, timedOut.Timeline[2].Polls)
+	assert.Contains(t, err.Error(), "waiting for ASG test to be at desired capacity")
+	assert.Contains(t, err.Error(), "0s-0s (2 polls): 1/3 instances")
+	assert.Contains(t, err.Error(), "0s: error: throttled")
+}
+
+// This test replaces the global default clock, so it must not run in parallel.
+func TestWaitForConditionTimelineUsesDefaultClock(t *testing.T) {
+	fakeClock := clock.NewFake(time.Now())
+	restore := clock.SetDefault(fakeClock)
+	defer restore()
+
+	condition := WaitCondition{
+		Description:  "ASG test",
+		DesiredState: "at desired capacity",
+		Extract: func() (WaitState, error) {
+			return WaitState{Current: "1/3 instances"}, nil
+		},
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- WaitForConditionE(t, condition, 2, 10*time.Second)
+	}()
+	for i := 0; i < 2; i++ {
+		fakeClock.BlockUntil(1)
+		fakeClock.Advance(10 * time.Second)
+	}
+
+	err := <-result
+	require.IsType(t, WaitTimedOut{}, err)
+	assert.Equal(t, 20*time.Second, err.(WaitTimedOut).Elapsed)
+	assert.Contains(t, err.Error(), "0s-20s (3 polls): 1/3 instances")
+}
@@ -0,0 +1,307 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/xray"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// xrayBatchGetTracesMaxIds is the maximum number of trace IDs that BatchGetTraces accepts per call.
+const xrayBatchGetTracesMaxIds = 5
+
+// XrayTrace is an X-Ray trace with its segments and their subsegments flattened into one list.
+type XrayTrace struct {
+	Id       string
+	Duration time.Duration
+	Segments []XraySegment
+}
+
+// XraySegment is a segment or subsegment of an X-Ray trace.
+type XraySegment struct {
+	Id       string
+	Name     string
+	ParentId string // The ID of the segment this is a subsegment of, if any
+	Duration time.Duration
+	Error    bool // Set for client errors, e.g. 4xx responses
+	Fault    bool // Set for server errors, e.g. 5xx responses
+	Throttle bool // Set for throttled requests, i.e. 429 responses
+}
+
+// xraySegmentDocument is the JSON document of an X-Ray segment or subsegment.
+type xraySegmentDocument struct {
+	Id          string                `json:"id"`
+	Name        string                `json:"name"`
+	ParentId    string                `json:"parent_id"`
+	StartTime   float64               `json:"start_time"`
+	EndTime     float64               `json:"end_time"`
+	Error       bool                  `json:"error"`
+	Fault       bool                  `json:"fault"`
+	Throttle    bool                  `json:"throttle"`
+	Subsegments []xraySegmentDocument `json:"subsegments"`
+}
+
+// FindXrayTraces waits until there are at least minTraces X-Ray traces matching the given filter expression, e.g.
+// service("my-api") AND http.url CONTAINS "/health", since the given start time, and returns them. This will fail the
+// test if there is an error.
+func FindXrayTraces(t testing.TestingT, region string, filterExpression string, since time.Time, minTraces int, maxRetries int, sleepBetweenRetries time.Duration) []XrayTrace {
+	traces, err := FindXrayTracesE(t, region, filterExpression, since, minTraces, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return traces
+}
+
+// FindXrayTracesE waits until there are at least minTraces X-Ray traces matching the given filter expression, e.g.
+// service("my-api") AND http.url CONTAINS "/health", since the given start time, and returns them. Pass the time the
+// test started sending requests as the start time, so that traces of earlier test runs don't count. X-Ray takes up to
+// a minute to make traces available, so allow for a few retries.
+func FindXrayTracesE(t testing.TestingT, region string, filterExpression string, since time.Time, minTraces int, maxRetries int, sleepBetweenRetries time.Duration) ([]XrayTrace, error) {
+	var summaries []*xray.TraceSummary
+	condition := WaitCondition{
+		Description:  fmt.Sprintf("X-Ray traces matching %s", filterExpression),
+		DesiredState: fmt.Sprintf("at least %d traces", minTraces),
+		Extract: func() (WaitState, error) {
+			found, err := GetXrayTraceSummariesE(t, region, filterExpression, since, time.Now())
+			if err != nil {
+				return WaitState{}, err
+			}
+
+			summaries = found
+			return WaitState{Current: fmt.Sprintf("%d traces", len(found)), Done: len(found) >= minTraces}, nil
+		},
+	}
+
+	if err := WaitForConditionE(t, condition, maxRetries, sleepBetweenRetries); err != nil {
+		return nil, err
+	}
+
+	traceIds := []string{}
+	for _, summary := range summaries {
+		traceIds = append(traceIds, aws.StringValue(summary.Id))
+	}
+	return GetXrayTracesE(t, region, traceIds)
+}
+
+// GetXrayTraceSummaries returns the summaries of the X-Ray traces matching the given filter expression between the
+// given times. This will fail the test if there is an error.
+func GetXrayTraceSummaries(t testing.TestingT, region string, filterExpression string, start time.Time, end time.Time) []*xray.TraceSummary {
+	summaries, err := GetXrayTraceSummariesE(t, region, filterExpression, start, end)
+	require.NoError(t, err)
+	return summaries
+}
+
+// GetXrayTraceSummariesE returns the summaries of the X-Ray traces matching the given filter expression between the
+// given times, which X-Ray limits to a range of 6 hours at most.
+func GetXrayTraceSummariesE(t testing.TestingT, region string, filterExpression string, start time.Time, end time.Time) ([]*xray.TraceSummary, error) {
+	client, err := NewXrayClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &xray.GetTraceSummariesInput{StartTime: aws.Time(start), EndTime: aws.Time(end)}
+	if filterExpression != "" {
+		input.FilterExpression = aws.String(filterExpression)
+	}
+
+	summaries := []*xray.TraceSummary{}
+	err = client.GetTraceSummariesPages(input, func(page *xray.GetTraceSummariesOutput, lastPage bool) bool {
+		summaries = append(summaries, page.TraceSummaries...)
+		return true
+	})
+	return summaries, err
+}
+
+// GetXrayTraces returns the X-Ray traces with the given IDs. This will fail the test if there is an error.
+func GetXrayTraces(t testing.TestingT, region string, traceIds []string) []XrayTrace {
+	traces, err := GetXrayTracesE(t, region, traceIds)
+	require.NoError(t, err)
+	return traces
+}
+
+// GetXrayTracesE returns the X-Ray traces with the given IDs, with their segments parsed.
+func GetXrayTracesE(t testing.TestingT, region string, traceIds []string) ([]XrayTrace, error) {
+	client, err := NewXrayClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	traces := []XrayTrace{}
+	for start := 0; start < len(traceIds); start += xrayBatchGetTracesMaxIds {
+		end := start + xrayBatchGetTracesMaxIds
+		if end > len(traceIds) {
+			end = len(traceIds)
+		}
+
+		var parseErr error
+		err := client.BatchGetTracesPages(&xray.BatchGetTracesInput{TraceIds: aws.StringSlice(traceIds[start:end])}, func(page *xray.BatchGetTracesOutput, lastPage bool) bool {
+			for _, trace := range page.Traces {
+				parsed, err := newXrayTrace(trace)
+				if err != nil {
+					parseErr = err
+					return false
+				}
+				traces = append(traces, parsed)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if parseErr != nil {
+			return nil, parseErr
+		}
+	}
+	return traces, nil
+}
+
+// newXrayTrace parses the segment documents of the given X-Ray trace.
+func newXrayTrace(trace *xray.Trace) (XrayTrace, error) {
+	parsed := XrayTrace{
+		Id:       aws.StringValue(trace.Id),
+		Duration: secondsToDuration(aws.Float64Value(trace.Duration)),
+		Segments: []XraySegment{},
+	}
+
+	for _, segment := range trace.Segments {
+		var document xraySegmentDocument
+		if err := json.Unmarshal([]byte(aws.StringValue(segment.Document)), &document); err != nil {
+			return XrayTrace{}, fmt.Errorf("Failed to parse segment %s of X-Ray trace %s: %s", aws.StringValue(segment.Id), parsed.Id, err)
+		}
+		parsed.Segments = appendXraySegments(parsed.Segments, document, "")
+	}
+	return parsed, nil
+}
+
+// appendXraySegments appends the given segment document, and recursively its subsegments, to the given segments.
+func appendXraySegments(segments []XraySegment, document xraySegmentDocument, parentId string) []XraySegment {
+	if document.ParentId != "" {
+		parentId = document.ParentId
+	}
+
+	duration := time.Duration(0)
+	if document.EndTime > document.StartTime {
+		duration = secondsToDuration(document.EndTime - document.StartTime)
+	}
+
+	segments = append(segments, XraySegment{
+		Id:       document.Id,
+		Name:     document.Name,
+		ParentId: parentId,
+		Duration: duration,
+		Error:    document.Error,
+		Fault:    document.Fault,
+		Throttle: document.Throttle,
+	})
+	for _, subsegment := range document.Subsegments {
+		segments = appendXraySegments(segments, subsegment, document.Id)
+	}
+	return segments
+}
+
+// secondsToDuration converts the given fractional number of seconds to a duration.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// AssertXrayTraceHasSegment checks that the given X-Ray trace has a segment or subsegment with the given name, e.g. the
+// one recorded for a call to DynamoDB by an instrumented SDK.
+func AssertXrayTraceHasSegment(t testing.TestingT, trace XrayTrace, segmentName string) {
+	require.NoError(t, AssertXrayTraceHasSegmentE(trace, segmentName))
+}
+
+// AssertXrayTraceHasSegmentE checks that the given X-Ray trace has a segment or subsegment with the given name and
+// returns an XraySegmentNotFound error if it does not.
+func AssertXrayTraceHasSegmentE(trace XrayTrace, segmentName string) error {
+	_, err := findXraySegment(trace, segmentName)
+	return err
+}
+
+// AssertXraySegmentLatency checks that the segment or subsegment with the given name in the given X-Ray trace took at
+// most the given duration.
+func AssertXraySegmentLatency(t testing.TestingT, trace XrayTrace, segmentName string, maxDuration time.Duration) {
+	require.NoError(t, AssertXraySegmentLatencyE(trace, segmentName, maxDuration))
+}
+
+// AssertXraySegmentLatencyE checks that the segment or subsegment with the given name in the given X-Ray trace took at
+// most the given duration and returns an XraySegmentTooSlow error if it did not. If the trace has several segments
+// with that name, all of them are checked.
+func AssertXraySegmentLatencyE(trace XrayTrace, segmentName string, maxDuration time.Duration) error {
+	if _, err := findXraySegment(trace, segmentName); err != nil {
+		return err
+	}
+
+	for _, segment := range trace.Segments {
+		if segment.Name == segmentName && segment.Duration > maxDuration {
+			return XraySegmentTooSlow{TraceId: trace.Id, SegmentName: segmentName, Duration: segment.Duration, MaxDuration: maxDuration}
+		}
+	}
+	return nil
+}
+
+// AssertXrayTraceHasNoErrors checks that none of the segments of the given X-Ray trace are flagged as an error, fault
+// or throttle.
+func AssertXrayTraceHasNoErrors(t testing.TestingT, trace XrayTrace) {
+	require.NoError(t, AssertXrayTraceHasNoErrorsE(trace))
+}
+
+// AssertXrayTraceHasNoErrorsE checks that none of the segments of the given X-Ray trace are flagged as an error, fault
+// or throttle, and returns an XrayTraceHasErrors error listing the ones that are.
+func AssertXrayTraceHasNoErrorsE(trace XrayTrace) error {
+	flagged := []string{}
+	for _, segment := range trace.Segments {
+		flags := []string{}
+		if segment.Error {
+			flags = append(flags, "error")
+		}
+		if segment.Fault {
+			flags = append(flags, "fault")
+		}
+		if segment.Throttle {
+			flags = append(flags, "throttle")
+		}
+		if len(flags) > 0 {
+			flagged = append(flagged, fmt.Sprintf("%s %v", segment.Name, flags))
+		}
+	}
+
+	if len(flagged) > 0 {
+		return XrayTraceHasErrors{TraceId: trace.Id, Segments: flagged}
+	}
+	return nil
+}
+
+// findXraySegment returns the first segment or subsegment with the given name in the given X-Ray trace, or an
+// XraySegmentNotFound error if there is none.
+func findXraySegment(trace XrayTrace, segmentName string) (XraySegment, error) {
+	for _, segment := range trace.Segments {
+		if segment.Name == segmentName {
+			return segment, nil
+		}
+	}
+
+	names := []string{}
+	for _, segment := range trace.Segments {
+		names = append(names, segment.Name)
+	}
+	return XraySegment{}, XraySegmentNotFound{TraceId: trace.Id, SegmentName: segmentName, Found: names}
+}
+
+// NewXrayClient creates an X-Ray client.
+func NewXrayClient(t testing.TestingT, region string) *xray.XRay {
+	client, err := NewXrayClientE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewXrayClientE creates an X-Ray client.
+func NewXrayClientE(t testing.TestingT, region string) (*xray.XRay, error) {
+	sess, err := NewAuthenticatedSession(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return xray.New(sess), nil
+}
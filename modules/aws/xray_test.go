@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/xray"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testXraySegmentDocument = `{
+  "id": "seg-1",
+  "name": "my-api",
+  "start_time": 1634200000.0,
+  "end_time": 1634200000.25,
+  "fault": true,
+  "subsegments": [
+    {"id": "sub-1", "name": "DynamoDB", "start_time": 1634200000.05, "end_time": 1634200000.2, "throttle": true},
+    {"id": "sub-2", "name": "S3", "start_time": 1634200000.1, "in_progress": true}
+  ]
+}`
+
+func newTestXrayTrace(t *testing.T) XrayTrace {
+	trace, err := newXrayTrace(&xray.Trace{
+		Id:       aws.String("1-5f84c7a0-123"),
+		Duration: aws.Float64(0.25),
+		Segments: []*xray.Segment{{Id: aws.String("seg-1"), Document: aws.String(testXraySegmentDocument)}},
+	})
+	require.NoError(t, err)
+	return trace
+}
+
+func TestNewXrayTraceFlattensSubsegments(t *testing.T) {
+	t.Parallel()
+
+	trace := newTestXrayTrace(t)
+	assert.Equal(t, "1-5f84c7a0-123", trace.Id)
+	assert.Equal(t, 250*time.Millisecond, trace.Duration)
+
+	require.Len(t, trace.Segments, 3)
+	assert.Equal(t, "my-api", trace.Segments[0].Name)
+	assert.True(t, trace.Segments[0].Fault)
+	assert.Equal(t, "DynamoDB", trace.Segments[1].Name)
+	assert.Equal(t, "seg-1", trace.Segments[1].ParentId)
+	assert.InDelta(t, float64(150*time.Millisecond), float64(trace.Segments[1].Duration), float64(time.Millisecond))
+	assert.Equal(t, time.Duration(0), trace.Segments[2].Duration)
+
+	_, err := newXrayTrace(&xray.Trace{Id: aws.String("1-bad"), Segments: []*xray.Segment{{Id: aws.String("seg-1"), Document: aws.String("{")}}})
+	assert.Error(t, err)
+}
+
+func TestXrayTraceAssertions(t *testing.T) {
+	t.Parallel()
+
+	trace := newTestXrayTrace(t)
+
+	assert.NoError(t, AssertXrayTraceHasSegmentE(trace, "DynamoDB"))
+	assert.EqualError(t, AssertXrayTraceHasSegmentE(trace, "SQS"), "X-Ray trace 1-5f84c7a0-123 has no segment named SQS, only [my-api DynamoDB S3]")
+
+	assert.NoError(t, AssertXraySegmentLatencyE(trace, "DynamoDB", 200*time.Millisecond))
+	assert.IsType(t, XraySegmentTooSlow{}, AssertXraySegmentLatencyE(trace, "DynamoDB", 100*time.Millisecond))
+	assert.IsType(t, XraySegmentNotFound{}, AssertXraySegmentLatencyE(trace, "SQS", time.Second))
+
+	assert.EqualError(t, AssertXrayTraceHasNoErrorsE(trace), "X-Ray trace 1-5f84c7a0-123 has flagged segments: my-api [fault], DynamoDB [throttle]")
+	assert.NoError(t, AssertXrayTraceHasNoErrorsE(XrayTrace{Id: "1-ok", Segments: []XraySegment{{Name: "my-api"}}}))
+}
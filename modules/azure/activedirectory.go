@@ -0,0 +1,363 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ADApplicationExists indicates whether the Azure AD application (app registration) with the given application (client)
+// ID exists in the specified Azure AD tenant. This function would fail the test if there is an error.
+func ADApplicationExists(t testing.TestingT, appID string, tenantID string) bool {
+	exists, err := ADApplicationExistsE(appID, tenantID)
+	require.NoError(t, err)
+	return exists
+}
+
+// ADApplicationExistsE indicates whether the Azure AD application (app registration) with the given application
+// (client) ID exists in the specified Azure AD tenant.
+func ADApplicationExistsE(appID string, tenantID string) (bool, error) {
+	_, err := GetADApplicationE(appID, tenantID)
+	if err != nil {
+		if _, notFound := err.(NotFoundError); notFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetADApplication gets the Azure AD application (app registration) with the given application (client) ID in the
+// specified Azure AD tenant. This function would fail the test if there is an error.
+func GetADApplication(t testing.TestingT, appID string, tenantID string) *graphrbac.Application {
+	application, err := GetADApplicationE(appID, tenantID)
+	require.NoError(t, err)
+	return application
+}
+
+// GetADApplicationE gets the Azure AD application (app registration) with the given application (client) ID in the
+// specified Azure AD tenant. Returns a NotFoundError if there is no such application.
+func GetADApplicationE(appID string, tenantID string) (*graphrbac.Application, error) {
+	// Get the client reference
+	client, err := CreateADApplicationsClientE(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer for the Azure AD Graph API
+	authorizer, err := newGraphAuthorizerE()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	applications, err := client.ListComplete(context.Background(), appIDFilter(appID))
+	if err != nil {
+		return nil, err
+	}
+
+	if applications.NotDone() {
+		application := applications.Value()
+		return &application, nil
+	}
+
+	return nil, NewNotFoundError("Azure AD application", appID, client.TenantID)
+}
+
+// ServicePrincipalExists indicates whether a service principal of the Azure AD application with the given application
+// (client) ID exists in the specified Azure AD tenant. This function would fail the test if there is an error.
+func ServicePrincipalExists(t testing.TestingT, appID string, tenantID string) bool {
+	exists, err := ServicePrincipalExistsE(appID, tenantID)
+	require.NoError(t, err)
+	return exists
+}
+
+// ServicePrincipalExistsE indicates whether a service principal of the Azure AD application with the given application
+// (client) ID exists in the specified Azure AD tenant.
+func ServicePrincipalExistsE(appID string, tenantID string) (bool, error) {
+	_, err := GetServicePrincipalE(appID, tenantID)
+	if err != nil {
+		if _, notFound := err.(NotFoundError); notFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetServicePrincipal gets the service principal of the Azure AD application with the given application (client) ID in
+// the specified Azure AD tenant. This function would fail the test if there is an error.
+func GetServicePrincipal(t testing.TestingT, appID string, tenantID string) *graphrbac.ServicePrincipal {
+	servicePrincipal, err := GetServicePrincipalE(appID, tenantID)
+	require.NoError(t, err)
+	return servicePrincipal
+}
+
+// GetServicePrincipalE gets the service principal of the Azure AD application with the given application (client) ID
+// in the specified Azure AD tenant. Its ObjectID is the principal ID that role assignments refer to. Returns a
+// NotFoundError if there is no such service principal.
+func GetServicePrincipalE(appID string, tenantID string) (*graphrbac.ServicePrincipal, error) {
+	// Get the client reference
+	client, err := CreateADServicePrincipalsClientE(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer for the Azure AD Graph API
+	authorizer, err := newGraphAuthorizerE()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	servicePrincipals, err := client.ListComplete(context.Background(), appIDFilter(appID))
+	if err != nil {
+		return nil, err
+	}
+
+	if servicePrincipals.NotDone() {
+		servicePrincipal := servicePrincipals.Value()
+		return &servicePrincipal, nil
+	}
+
+	return nil, NewNotFoundError("Azure AD service principal", appID, client.TenantID)
+}
+
+// GetRoleAssignmentsForPrincipal gets the role assignments of the principal (user, group or service principal) with
+// the given object ID that apply at the given scope (e.g. "/subscriptions/<id>/resourceGroups/<name>"), including
+// those inherited from parent scopes. This function would fail the test if there is an error.
+func GetRoleAssignmentsForPrincipal(t testing.TestingT, principalID string, scope string, subscriptionID string) []authorization.RoleAssignment {
+	assignments, err := GetRoleAssignmentsForPrincipalE(principalID, scope, subscriptionID)
+	require.NoError(t, err)
+	return assignments
+}
+
+// GetRoleAssignmentsForPrincipalE gets the role assignments of the principal (user, group or service principal) with
+// the given object ID that apply at the given scope (e.g. "/subscriptions/<id>/resourceGroups/<name>"), including
+// those inherited from parent scopes.
+func GetRoleAssignmentsForPrincipalE(principalID string, scope string, subscriptionID string) ([]authorization.RoleAssignment, error) {
+	// Get the client reference
+	client, err := CreateRoleAssignmentsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	filter := fmt.Sprintf("principalId eq '%s'", principalID)
+	iterator, err := client.ListForScopeComplete(context.Background(), scope, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := []authorization.RoleAssignment{}
+	for iterator.NotDone() {
+		if assignment := iterator.Value(); roleAssignmentAppliesAtScope(assignment, scope) {
+			assignments = append(assignments, assignment)
+		}
+
+		if err := iterator.Next(); err != nil {
+			return nil, err
+		}
+	}
+
+	return assignments, nil
+}
+
+// RoleAssignmentExists indicates whether the principal with the given object ID has the role with the given name (e.g.
+// "Contributor") at the given scope, either assigned at the scope itself or inherited from a parent scope. This
+// function would fail the test if there is an error.
+func RoleAssignmentExists(t testing.TestingT, principalID string, roleName string, scope string, subscriptionID string) bool {
+	exists, err := RoleAssignmentExistsE(principalID, roleName, scope, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// RoleAssignmentExistsE indicates whether the principal with the given object ID has the role with the given name (e.g.
+// "Contributor") at the given scope, either assigned at the scope itself or inherited from a parent scope.
+func RoleAssignmentExistsE(principalID string, roleName string, scope string, subscriptionID string) (bool, error) {
+	roleDefinitionID, err := GetRoleDefinitionIDE(roleName, scope, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	assignments, err := GetRoleAssignmentsForPrincipalE(principalID, scope, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	return roleAssignmentsHaveRole(assignments, roleDefinitionID), nil
+}
+
+// GetRoleDefinitionID gets the ID of the role definition with the given name (e.g. "Contributor") that is assignable
+// at the given scope. This function would fail the test if there is an error.
+func GetRoleDefinitionID(t testing.TestingT, roleName string, scope string, subscriptionID string) string {
+	roleDefinitionID, err := GetRoleDefinitionIDE(roleName, scope, subscriptionID)
+	require.NoError(t, err)
+	return roleDefinitionID
+}
+
+// GetRoleDefinitionIDE gets the ID of the role definition with the given name (e.g. "Contributor") that is assignable
+// at the given scope. Returns a NotFoundError if there is no such role.
+func GetRoleDefinitionIDE(roleName string, scope string, subscriptionID string) (string, error) {
+	// Get the client reference
+	client, err := CreateRoleDefinitionsClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return "", err
+	}
+	client.Authorizer = *authorizer
+
+	filter := fmt.Sprintf("roleName eq '%s'", roleName)
+	roleDefinitions, err := client.ListComplete(context.Background(), scope, filter)
+	if err != nil {
+		return "", err
+	}
+
+	if roleDefinitions.NotDone() {
+		return safePtrToString(roleDefinitions.Value().ID), nil
+	}
+
+	return "", NewNotFoundError("Role definition", roleName, scope)
+}
+
+// WaitForADApplication waits until the Azure AD application with the given application (client) ID can be found in the
+// specified Azure AD tenant. This function would fail the test if that does not happen within the given number of
+// retries.
+func WaitForADApplication(t testing.TestingT, appID string, tenantID string, retries int, sleepBetweenRetries time.Duration) {
+	err := WaitForADApplicationE(t, appID, tenantID, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForADApplicationE waits until the Azure AD application with the given application (client) ID can be found in
+// the specified Azure AD tenant. Azure AD replicates new objects asynchronously, so they may not be found for a while
+// after the module under test created them.
+func WaitForADApplicationE(t testing.TestingT, appID string, tenantID string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for Azure AD application %s to be found", appID)
+
+	_, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		_, err := GetADApplicationE(appID, tenantID)
+		return "", err
+	})
+
+	return err
+}
+
+// WaitForServicePrincipal waits until a service principal of the Azure AD application with the given application
+// (client) ID can be found in the specified Azure AD tenant. This function would fail the test if that does not happen
+// within the given number of retries.
+func WaitForServicePrincipal(t testing.TestingT, appID string, tenantID string, retries int, sleepBetweenRetries time.Duration) {
+	err := WaitForServicePrincipalE(t, appID, tenantID, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForServicePrincipalE waits until a service principal of the Azure AD application with the given application
+// (client) ID can be found in the specified Azure AD tenant. Azure AD replicates new objects asynchronously, so they
+// may not be found for a while after the module under test created them.
+func WaitForServicePrincipalE(t testing.TestingT, appID string, tenantID string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for service principal of Azure AD application %s to be found", appID)
+
+	_, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		_, err := GetServicePrincipalE(appID, tenantID)
+		return "", err
+	})
+
+	return err
+}
+
+// WaitForRoleAssignment waits until the principal with the given object ID has the role with the given name at the
+// given scope. This function would fail the test if that does not happen within the given number of retries.
+func WaitForRoleAssignment(t testing.TestingT, principalID string, roleName string, scope string, subscriptionID string, retries int, sleepBetweenRetries time.Duration) {
+	err := WaitForRoleAssignmentE(t, principalID, roleName, scope, subscriptionID, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForRoleAssignmentE waits until the principal with the given object ID has the role with the given name at the
+// given scope. Role assignments can take several minutes to be listed and take effect after they are created.
+func WaitForRoleAssignmentE(t testing.TestingT, principalID string, roleName string, scope string, subscriptionID string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for principal %s to have role %s at scope %s", principalID, roleName, scope)
+
+	_, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		exists, err := RoleAssignmentExistsE(principalID, roleName, scope, subscriptionID)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return "", fmt.Errorf("Principal %s does not have role %s at scope %s yet", principalID, roleName, scope)
+		}
+
+		return "", nil
+	})
+
+	return err
+}
+
+// newGraphAuthorizerE creates an authorizer for the Azure AD Graph API of the Azure environment that is currently setup.
+func newGraphAuthorizerE() (*autorest.Authorizer, error) {
+	graphEndpoint, err := getEnvironmentEndpointE(GraphEndpointName)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuthorizerWithResource(graphEndpoint)
+}
+
+// appIDFilter returns an OData filter for Azure AD Graph objects of the application with the given application ID.
+func appIDFilter(appID string) string {
+	return fmt.Sprintf("appId eq '%s'", appID)
+}
+
+// roleAssignmentAppliesAtScope indicates whether the given role assignment applies at the given scope, i.e. it is
+// assigned at the scope itself or at one of its parent scopes. Scopes are case insensitive.
+func roleAssignmentAppliesAtScope(assignment authorization.RoleAssignment, scope string) bool {
+	if assignment.Properties == nil {
+		return false
+	}
+
+	assignmentScope := strings.ToLower(strings.TrimSuffix(safePtrToString(assignment.Properties.Scope), "/"))
+	scope = strings.ToLower(strings.TrimSuffix(scope, "/"))
+
+	// The root scope "/" is the parent of every scope
+	return assignmentScope == "" || scope == assignmentScope || strings.HasPrefix(scope, assignmentScope+"/")
+}
+
+// roleAssignmentsHaveRole indicates whether any of the given role assignments assigns the role definition with the
+// given ID. Role definition IDs are compared by their GUID, as the same role definition is referred to with the scope it
+// was looked up at as prefix.
+func roleAssignmentsHaveRole(assignments []authorization.RoleAssignment, roleDefinitionID string) bool {
+	for _, assignment := range assignments {
+		if assignment.Properties == nil {
+			continue
+		}
+
+		if strings.EqualFold(roleDefinitionGUID(safePtrToString(assignment.Properties.RoleDefinitionID)), roleDefinitionGUID(roleDefinitionID)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// roleDefinitionGUID returns the GUID of the role definition with the given ID, i.e. its last segment.
+func roleDefinitionGUID(roleDefinitionID string) string {
+	segments := strings.Split(strings.TrimSuffix(roleDefinitionID, "/"), "/")
+	return segments[len(segments)-1]
+}
@@ -0,0 +1,103 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods can be mocked or Create/Delete APIs are added, these tests can be extended.
+*/
+
+func TestGetADApplicationE(t *testing.T) {
+	t.Parallel()
+
+	appID := ""
+	tenantID := ""
+
+	_, err := GetADApplicationE(appID, tenantID)
+
+	require.Error(t, err)
+}
+
+func TestGetServicePrincipalE(t *testing.T) {
+	t.Parallel()
+
+	appID := ""
+	tenantID := ""
+
+	_, err := GetServicePrincipalE(appID, tenantID)
+
+	require.Error(t, err)
+}
+
+func TestGetRoleAssignmentsForPrincipalE(t *testing.T) {
+	t.Parallel()
+
+	principalID := ""
+	scope := ""
+	subscriptionID := ""
+
+	_, err := GetRoleAssignmentsForPrincipalE(principalID, scope, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestRoleAssignmentAppliesAtScope(t *testing.T) {
+	t.Parallel()
+
+	scope := "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/my-rg"
+
+	testCases := []struct {
+		name            string
+		assignmentScope string
+		expected        bool
+	}{
+		{"SameScope", scope, true},
+		{"DifferentCase", "/subscriptions/00000000-0000-0000-0000-000000000000/resourcegroups/MY-RG", true},
+		{"ParentScope", "/subscriptions/00000000-0000-0000-0000-000000000000", true},
+		{"RootScope", "/", true},
+		{"ChildScope", scope + "/providers/Microsoft.Storage/storageAccounts/mystorage", false},
+		{"SiblingWithSamePrefix", scope + "-other", false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			assignment := authorization.RoleAssignment{
+				Properties: &authorization.RoleAssignmentPropertiesWithScope{Scope: to.StringPtr(testCase.assignmentScope)},
+			}
+			assert.Equal(t, testCase.expected, roleAssignmentAppliesAtScope(assignment, scope))
+		})
+	}
+}
+
+func TestRoleAssignmentsHaveRole(t *testing.T) {
+	t.Parallel()
+
+	contributor := "b24988ac-6180-42a0-ab88-20f7382dd24c"
+	assignments := []authorization.RoleAssignment{
+		{},
+		{
+			Properties: &authorization.RoleAssignmentPropertiesWithScope{
+				RoleDefinitionID: to.StringPtr("/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleDefinitions/" + contributor),
+			},
+		},
+	}
+
+	assert.True(t, roleAssignmentsHaveRole(assignments, "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/my-rg/providers/Microsoft.Authorization/roleDefinitions/"+contributor))
+	assert.True(t, roleAssignmentsHaveRole(assignments, "/providers/Microsoft.Authorization/roleDefinitions/B24988AC-6180-42A0-AB88-20F7382DD24C"))
+	assert.False(t, roleAssignmentsHaveRole(assignments, "/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7"))
+	assert.False(t, roleAssignmentsHaveRole(nil, contributor))
+}
@@ -0,0 +1,400 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	autorestAzure "github.com/Azure/go-autorest/autorest/azure"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ApplicationGatewayExists indicates whether the specified Application Gateway exists.
+// This function would fail the test if there is an error.
+func ApplicationGatewayExists(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := ApplicationGatewayExistsE(appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// ApplicationGatewayExistsE indicates whether the specified Application Gateway exists.
+func ApplicationGatewayExistsE(appGatewayName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetApplicationGatewayE(appGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetApplicationGateway gets an Application Gateway network resource in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func GetApplicationGateway(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string) *network.ApplicationGateway {
+	appGateway, err := GetApplicationGatewayE(appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return appGateway
+}
+
+// GetApplicationGatewayE gets an Application Gateway network resource in the specified Azure Resource Group.
+func GetApplicationGatewayE(appGatewayName string, resourceGroupName string, subscriptionID string) (*network.ApplicationGateway, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := GetApplicationGatewayClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the Application Gateway
+	appGateway, err := client.Get(context.Background(), resourceGroupName, appGatewayName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appGateway, nil
+}
+
+// GetApplicationGatewayBackendHealth gets the health of every backend server of the specified Application Gateway as
+// reported by its health probes. This function would fail the test if there is an error.
+func GetApplicationGatewayBackendHealth(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string) *network.ApplicationGatewayBackendHealth {
+	health, err := GetApplicationGatewayBackendHealthE(appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return health
+}
+
+// GetApplicationGatewayBackendHealthE gets the health of every backend server of the specified Application Gateway as
+// reported by its health probes.
+func GetApplicationGatewayBackendHealthE(appGatewayName string, resourceGroupName string, subscriptionID string) (*network.ApplicationGatewayBackendHealth, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := GetApplicationGatewayClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Backend health is a long running operation, so wait for it to complete
+	ctx := context.Background()
+	future, err := client.BackendHealth(ctx, resourceGroupName, appGatewayName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := future.WaitForCompletionRef(ctx, client.Client); err != nil {
+		return nil, err
+	}
+
+	health, err := future.Result(*client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &health, nil
+}
+
+// GetApplicationGatewayUnhealthyBackends gets the addresses of the backend servers of the specified Application Gateway
+// that are not reported as healthy, in the form "<backend pool>/<address>: <health>".
+// This function would fail the test if there is an error.
+func GetApplicationGatewayUnhealthyBackends(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string) []string {
+	backends, err := GetApplicationGatewayUnhealthyBackendsE(appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return backends
+}
+
+// GetApplicationGatewayUnhealthyBackendsE gets the addresses of the backend servers of the specified Application Gateway
+// that are not reported as healthy, in the form "<backend pool>/<address>: <health>".
+func GetApplicationGatewayUnhealthyBackendsE(appGatewayName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	health, err := GetApplicationGatewayBackendHealthE(appGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getUnhealthyApplicationGatewayBackends(health), nil
+}
+
+// WaitForApplicationGatewayBackendsHealthy waits until every backend server of the specified Application Gateway is
+// reported as healthy. This function would fail the test if that does not happen within the given number of retries.
+func WaitForApplicationGatewayBackendsHealthy(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string, retries int, sleepBetweenRetries time.Duration) {
+	err := WaitForApplicationGatewayBackendsHealthyE(t, appGatewayName, resourceGroupName, subscriptionID, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForApplicationGatewayBackendsHealthyE waits until every backend server of the specified Application Gateway is
+// reported as healthy.
+func WaitForApplicationGatewayBackendsHealthyE(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for all backends of Application Gateway %s to be healthy", appGatewayName)
+
+	_, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		unhealthy, err := GetApplicationGatewayUnhealthyBackendsE(appGatewayName, resourceGroupName, subscriptionID)
+		if err != nil {
+			return "", err
+		}
+
+		if len(unhealthy) > 0 {
+			return "", fmt.Errorf("Unhealthy backends: %s", strings.Join(unhealthy, ", "))
+		}
+
+		return "", nil
+	})
+
+	return err
+}
+
+// GetApplicationGatewayRoutingRuleNames gets the names of the request routing rules of the specified Application Gateway.
+// This function would fail the test if there is an error.
+func GetApplicationGatewayRoutingRuleNames(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string) []string {
+	ruleNames, err := GetApplicationGatewayRoutingRuleNamesE(appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ruleNames
+}
+
+// GetApplicationGatewayRoutingRuleNamesE gets the names of the request routing rules of the specified Application Gateway.
+func GetApplicationGatewayRoutingRuleNamesE(appGatewayName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	appGateway, err := GetApplicationGatewayE(appGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleNames := []string{}
+	if appGateway.ApplicationGatewayPropertiesFormat == nil || appGateway.RequestRoutingRules == nil {
+		return ruleNames, nil
+	}
+
+	for _, rule := range *appGateway.RequestRoutingRules {
+		ruleNames = append(ruleNames, *rule.Name)
+	}
+
+	return ruleNames, nil
+}
+
+// GetApplicationGatewayRoutingRule gets the specified request routing rule of the specified Application Gateway.
+// This function would fail the test if there is an error.
+func GetApplicationGatewayRoutingRule(t testing.TestingT, ruleName string, appGatewayName string, resourceGroupName string, subscriptionID string) *network.ApplicationGatewayRequestRoutingRule {
+	rule, err := GetApplicationGatewayRoutingRuleE(ruleName, appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return rule
+}
+
+// GetApplicationGatewayRoutingRuleE gets the specified request routing rule of the specified Application Gateway.
+func GetApplicationGatewayRoutingRuleE(ruleName string, appGatewayName string, resourceGroupName string, subscriptionID string) (*network.ApplicationGatewayRequestRoutingRule, error) {
+	appGateway, err := GetApplicationGatewayE(appGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if appGateway.ApplicationGatewayPropertiesFormat != nil && appGateway.RequestRoutingRules != nil {
+		for _, rule := range *appGateway.RequestRoutingRules {
+			if rule.Name != nil && *rule.Name == ruleName {
+				return &rule, nil
+			}
+		}
+	}
+
+	return nil, NewNotFoundError("Application Gateway Request Routing Rule", ruleName, appGatewayName)
+}
+
+// GetApplicationGatewayWAFMode gets the mode ("Prevention" or "Detection") the web application firewall of the
+// specified Application Gateway runs in, taking an associated WAF policy into account. An empty string is returned if
+// the web application firewall is disabled. This function would fail the test if there is an error.
+func GetApplicationGatewayWAFMode(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string) string {
+	mode, err := GetApplicationGatewayWAFModeE(appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return mode
+}
+
+// GetApplicationGatewayWAFModeE gets the mode ("Prevention" or "Detection") the web application firewall of the
+// specified Application Gateway runs in, taking an associated WAF policy into account. An empty string is returned if
+// the web application firewall is disabled.
+func GetApplicationGatewayWAFModeE(appGatewayName string, resourceGroupName string, subscriptionID string) (string, error) {
+	appGateway, err := GetApplicationGatewayE(appGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	var policy *network.WebApplicationFirewallPolicy
+	if appGateway.ApplicationGatewayPropertiesFormat != nil && appGateway.FirewallPolicy != nil && appGateway.FirewallPolicy.ID != nil {
+		policy, err = getWebApplicationFirewallPolicyByIDE(*appGateway.FirewallPolicy.ID, subscriptionID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return getApplicationGatewayWAFMode(appGateway, policy), nil
+}
+
+// GetApplicationGatewayPublicIP gets the public IP address of the first frontend IP configuration of the specified
+// Application Gateway that has one. This function would fail the test if there is an error.
+func GetApplicationGatewayPublicIP(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string) string {
+	ipAddress, err := GetApplicationGatewayPublicIPE(appGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ipAddress
+}
+
+// GetApplicationGatewayPublicIPE gets the public IP address of the first frontend IP configuration of the specified
+// Application Gateway that has one.
+func GetApplicationGatewayPublicIPE(appGatewayName string, resourceGroupName string, subscriptionID string) (string, error) {
+	appGateway, err := GetApplicationGatewayE(appGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	if appGateway.ApplicationGatewayPropertiesFormat != nil && appGateway.FrontendIPConfigurations != nil {
+		for _, config := range *appGateway.FrontendIPConfigurations {
+			props := config.ApplicationGatewayFrontendIPConfigurationPropertiesFormat
+			if props == nil || props.PublicIPAddress == nil || props.PublicIPAddress.ID == nil {
+				continue
+			}
+
+			// Get PublicIPAddress resource name from the Application Gateway Frontend Configuration
+			pipName := GetNameFromResourceID(*props.PublicIPAddress.ID)
+			return GetIPOfPublicIPAddressByNameE(pipName, resourceGroupName, subscriptionID)
+		}
+	}
+
+	return "", NewNotFoundError("Application Gateway Public Frontend IP Configuration", "Any", appGatewayName)
+}
+
+// ProbeApplicationGateway sends HTTP GET requests for the given path to the public IP address of the specified
+// Application Gateway until it responds with the expected status code, and returns the body of the response. Pass a
+// "Host" header to hit a multi-site listener. This function would fail the test if that does not happen within the
+// given number of retries.
+func ProbeApplicationGateway(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string, path string, headers map[string]string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := ProbeApplicationGatewayE(t, appGatewayName, resourceGroupName, subscriptionID, path, headers, expectedStatus, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return body
+}
+
+// ProbeApplicationGatewayE sends HTTP GET requests for the given path to the public IP address of the specified
+// Application Gateway until it responds with the expected status code, and returns the body of the response. Pass a
+// "Host" header to hit a multi-site listener.
+func ProbeApplicationGatewayE(t testing.TestingT, appGatewayName string, resourceGroupName string, subscriptionID string, path string, headers map[string]string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	ipAddress, err := GetApplicationGatewayPublicIPE(appGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("http://%s/%s", ipAddress, strings.TrimPrefix(path, "/"))
+	return http_helper.HTTPDoWithRetryE(t, "GET", url, nil, headers, expectedStatus, retries, sleepBetweenRetries, nil)
+}
+
+// GetApplicationGatewayClientE gets a new Application Gateway client in the specified Azure Subscription.
+func GetApplicationGatewayClientE(subscriptionID string) (*network.ApplicationGatewaysClient, error) {
+	// Get the Application Gateway client
+	client, err := CreateApplicationGatewayClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	return client, nil
+}
+
+// getWebApplicationFirewallPolicyByIDE gets the Application Gateway WAF policy with the given resource ID.
+func getWebApplicationFirewallPolicyByIDE(policyID string, subscriptionID string) (*network.WebApplicationFirewallPolicy, error) {
+	resource, err := autorestAzure.ParseResourceID(policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateWebApplicationFirewallPoliciesClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	policy, err := client.Get(context.Background(), resource.ResourceGroup, resource.ResourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// getUnhealthyApplicationGatewayBackends returns the backend servers in the given backend health report that are not
+// up, in the form "<backend pool>/<address>: <health>".
+func getUnhealthyApplicationGatewayBackends(health *network.ApplicationGatewayBackendHealth) []string {
+	unhealthy := []string{}
+	if health == nil || health.BackendAddressPools == nil {
+		return unhealthy
+	}
+
+	for _, pool := range *health.BackendAddressPools {
+		poolName := ""
+		if pool.BackendAddressPool != nil && pool.BackendAddressPool.ID != nil {
+			poolName = GetNameFromResourceID(*pool.BackendAddressPool.ID)
+		}
+
+		if pool.BackendHTTPSettingsCollection == nil {
+			continue
+		}
+
+		for _, settings := range *pool.BackendHTTPSettingsCollection {
+			if settings.Servers == nil {
+				continue
+			}
+
+			for _, server := range *settings.Servers {
+				if server.Health != network.Up {
+					address := ""
+					if server.Address != nil {
+						address = *server.Address
+					}
+					unhealthy = append(unhealthy, fmt.Sprintf("%s/%s: %s", poolName, address, server.Health))
+				}
+			}
+		}
+	}
+
+	return unhealthy
+}
+
+// getApplicationGatewayWAFMode returns the mode the web application firewall of the given Application Gateway runs in.
+// An associated WAF policy takes precedence over the WAF configuration of the gateway itself.
+func getApplicationGatewayWAFMode(appGateway *network.ApplicationGateway, policy *network.WebApplicationFirewallPolicy) string {
+	if policy != nil {
+		if policy.WebApplicationFirewallPolicyPropertiesFormat == nil || policy.PolicySettings == nil {
+			return ""
+		}
+		if policy.PolicySettings.State != network.WebApplicationFirewallEnabledStateEnabled {
+			return ""
+		}
+		return string(policy.PolicySettings.Mode)
+	}
+
+	if appGateway.ApplicationGatewayPropertiesFormat == nil || appGateway.WebApplicationFirewallConfiguration == nil {
+		return ""
+	}
+
+	wafConfig := appGateway.WebApplicationFirewallConfiguration
+	if wafConfig.Enabled == nil || !*wafConfig.Enabled {
+		return ""
+	}
+
+	return string(wafConfig.FirewallMode)
+}
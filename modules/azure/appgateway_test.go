@@ -0,0 +1,100 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods can be mocked or Create/Delete APIs are added, these tests can be extended.
+*/
+
+func TestApplicationGatewayExistsE(t *testing.T) {
+	t.Parallel()
+
+	appGatewayName := ""
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := ApplicationGatewayExistsE(appGatewayName, resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestGetApplicationGatewayBackendHealthE(t *testing.T) {
+	t.Parallel()
+
+	appGatewayName := ""
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := GetApplicationGatewayBackendHealthE(appGatewayName, resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestGetUnhealthyApplicationGatewayBackends(t *testing.T) {
+	t.Parallel()
+
+	health := &network.ApplicationGatewayBackendHealth{
+		BackendAddressPools: &[]network.ApplicationGatewayBackendHealthPool{
+			{
+				BackendAddressPool: &network.ApplicationGatewayBackendAddressPool{
+					ID: stringPtr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/agw/backendAddressPools/web"),
+				},
+				BackendHTTPSettingsCollection: &[]network.ApplicationGatewayBackendHealthHTTPSettings{
+					{
+						Servers: &[]network.ApplicationGatewayBackendHealthServer{
+							{Address: stringPtr("10.0.1.4"), Health: network.Up},
+							{Address: stringPtr("10.0.1.5"), Health: network.Down},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"web/10.0.1.5: Down"}, getUnhealthyApplicationGatewayBackends(health))
+}
+
+func TestGetApplicationGatewayWAFMode(t *testing.T) {
+	t.Parallel()
+
+	enabled := true
+
+	appGateway := &network.ApplicationGateway{
+		ApplicationGatewayPropertiesFormat: &network.ApplicationGatewayPropertiesFormat{
+			WebApplicationFirewallConfiguration: &network.ApplicationGatewayWebApplicationFirewallConfiguration{
+				Enabled:      &enabled,
+				FirewallMode: network.Detection,
+			},
+		},
+	}
+	assert.Equal(t, "Detection", getApplicationGatewayWAFMode(appGateway, nil))
+
+	policy := &network.WebApplicationFirewallPolicy{
+		WebApplicationFirewallPolicyPropertiesFormat: &network.WebApplicationFirewallPolicyPropertiesFormat{
+			PolicySettings: &network.PolicySettings{
+				State: network.WebApplicationFirewallEnabledStateEnabled,
+				Mode:  network.WebApplicationFirewallModePrevention,
+			},
+		},
+	}
+	assert.Equal(t, "Prevention", getApplicationGatewayWAFMode(appGateway, policy))
+
+	policy.PolicySettings.State = network.WebApplicationFirewallEnabledStateDisabled
+	assert.Equal(t, "", getApplicationGatewayWAFMode(appGateway, policy))
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
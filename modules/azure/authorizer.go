@@ -30,12 +30,34 @@ func NewAuthorizer() (*autorest.Authorizer, error) {
 	// Execute logic to return an authorizer from the correct method
 	if clientIDExists && tenantIDExists {
 		authorizer, err := auth.NewAuthorizerFromEnvironment()
-		return &authorizer, err
+		return throttleAuthorizer(authorizer), err
 	} else if fileAuthSet {
 		authorizer, err := auth.NewAuthorizerFromFile(az.PublicCloud.ResourceManagerEndpoint)
-		return &authorizer, err
+		return throttleAuthorizer(authorizer), err
 	} else {
 		authorizer, err := auth.NewAuthorizerFromCLI()
-		return &authorizer, err
+		return throttleAuthorizer(authorizer), err
+	}
+}
+
+// NewAuthorizerWithResource creates an Azure authorizer for the given resource (e.g. "https://servicebus.azure.net" to
+// call the data plane of Service Bus or Event Hubs with Azure AD credentials), using the same auth mechanisms as
+// NewAuthorizer.
+func NewAuthorizerWithResource(resource string) (*autorest.Authorizer, error) {
+	// Carry out env var lookups
+	_, clientIDExists := os.LookupEnv(AuthFromEnvClient)
+	_, tenantIDExists := os.LookupEnv(AuthFromEnvTenant)
+	_, fileAuthSet := os.LookupEnv(AuthFromFile)
+
+	// Execute logic to return an authorizer from the correct method
+	if clientIDExists && tenantIDExists {
+		authorizer, err := auth.NewAuthorizerFromEnvironmentWithResource(resource)
+		return throttleAuthorizer(authorizer), err
+	} else if fileAuthSet {
+		authorizer, err := auth.NewAuthorizerFromFileWithResource(resource)
+		return throttleAuthorizer(authorizer), err
+	} else {
+		authorizer, err := auth.NewAuthorizerFromCLIWithResource(resource)
+		return throttleAuthorizer(authorizer), err
 	}
 }
@@ -19,12 +19,16 @@ import (
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/sql/mgmt/sql"
 	"github.com/Azure/azure-sdk-for-go/profiles/preview/cosmos-db/mgmt/documentdb"
 	"github.com/Azure/azure-sdk-for-go/profiles/preview/preview/monitor/mgmt/insights"
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/containerinstance/mgmt/2018-10-01/containerinstance"
 	"github.com/Azure/azure-sdk-for-go/services/containerregistry/mgmt/2019-05-01/containerregistry"
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2019-11-01/containerservice"
+	"github.com/Azure/azure-sdk-for-go/services/frontdoor/mgmt/2020-05-01/frontdoor"
+	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
 	kvmng "github.com/Azure/azure-sdk-for-go/services/keyvault/mgmt/2016-10-01/keyvault"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-06-01/subscriptions"
 	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
 	"github.com/Azure/azure-sdk-for-go/services/web/mgmt/2019-08-01/web"
@@ -45,6 +49,9 @@ const (
 
 	// ResourceManagerEndpointName is the name of the ResourceManagerEndpoint field in the Environment struct.
 	ResourceManagerEndpointName = "ResourceManagerEndpoint"
+
+	// GraphEndpointName is the name of the GraphEndpoint field in the Environment struct.
+	GraphEndpointName = "GraphEndpoint"
 )
 
 // ClientType describes the type of client a module can create.
@@ -605,6 +612,266 @@ func CreateLoadBalancerClientE(subscriptionID string) (*network.LoadBalancersCli
 	return &client, nil
 }
 
+// CreateApplicationGatewayClientE returns an Application Gateway client instance configured with the correct BaseURI
+// depending on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateApplicationGatewayClientE(subscriptionID string) (*network.ApplicationGatewaysClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := network.NewApplicationGatewaysClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreateWebApplicationFirewallPoliciesClientE returns an Application Gateway WAF policies client instance configured
+// with the correct BaseURI depending on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateWebApplicationFirewallPoliciesClientE(subscriptionID string) (*network.WebApplicationFirewallPoliciesClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := network.NewWebApplicationFirewallPoliciesClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreateFrontDoorClientE returns a Front Door client instance configured with the correct BaseURI depending on the
+// Azure environment that is currently setup (or "Public", if none is setup).
+func CreateFrontDoorClientE(subscriptionID string) (*frontdoor.FrontDoorsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := frontdoor.NewFrontDoorsClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreateFrontDoorWAFPoliciesClientE returns a Front Door WAF policies client instance configured with the correct
+// BaseURI depending on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateFrontDoorWAFPoliciesClientE(subscriptionID string) (*frontdoor.PoliciesClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := frontdoor.NewPoliciesClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreatePrivateEndpointsClientE returns a Private Endpoint client instance configured with the correct BaseURI depending on
+// the Azure environment that is currently setup (or "Public", if none is setup).
+func CreatePrivateEndpointsClientE(subscriptionID string) (*network.PrivateEndpointsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := network.NewPrivateEndpointsClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreatePermissionsClientE returns a Permissions client instance configured with the correct BaseURI depending on
+// the Azure environment that is currently setup (or "Public", if none is setup).
+func CreatePermissionsClientE(subscriptionID string) (*authorization.PermissionsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := authorization.NewPermissionsClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreateRoleAssignmentsClientE returns a Role Assignments client instance configured with the correct BaseURI depending
+// on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateRoleAssignmentsClientE(subscriptionID string) (*authorization.RoleAssignmentsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := authorization.NewRoleAssignmentsClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreateRoleDefinitionsClientE returns a Role Definitions client instance configured with the correct BaseURI depending
+// on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateRoleDefinitionsClientE(subscriptionID string) (*authorization.RoleDefinitionsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := authorization.NewRoleDefinitionsClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreateADApplicationsClientE returns an Azure AD Graph applications client instance configured with the correct
+// BaseURI depending on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateADApplicationsClientE(tenantID string) (*graphrbac.ApplicationsClient, error) {
+	// Validate Azure tenant ID
+	tenantID, err := getTargetAzureTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(GraphEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := graphrbac.NewApplicationsClientWithBaseURI(baseURI, tenantID)
+	return &client, nil
+}
+
+// CreateADServicePrincipalsClientE returns an Azure AD Graph service principals client instance configured with the
+// correct BaseURI depending on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateADServicePrincipalsClientE(tenantID string) (*graphrbac.ServicePrincipalsClient, error) {
+	// Validate Azure tenant ID
+	tenantID, err := getTargetAzureTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(GraphEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := graphrbac.NewServicePrincipalsClientWithBaseURI(baseURI, tenantID)
+	return &client, nil
+}
+
+// CreatePrivateDNSRecordSetsClientE returns a Private DNS zone record set client instance configured with the correct BaseURI depending on
+// the Azure environment that is currently setup (or "Public", if none is setup).
+func CreatePrivateDNSRecordSetsClientE(subscriptionID string) (*privatedns.RecordSetsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := privatedns.NewRecordSetsClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreatePrivateDNSVirtualNetworkLinksClientE returns a Private DNS zone virtual network link client instance configured with the correct BaseURI depending on
+// the Azure environment that is currently setup (or "Public", if none is setup).
+func CreatePrivateDNSVirtualNetworkLinksClientE(subscriptionID string) (*privatedns.VirtualNetworkLinksClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := privatedns.NewVirtualNetworkLinksClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreateResourcesClientE returns a generic resources client instance configured with the correct BaseURI depending on
+// the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateResourcesClientE(subscriptionID string) (*resources.Client, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create client
+	client := resources.NewClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
 // CreateNewSubnetClientE returns a Subnet client instance configured with the
 // correct BaseURI depending on the Azure environment that is currently setup (or "Public", if none is setup).
 func CreateNewSubnetClientE(subscriptionID string) (*network.SubnetsClient, error) {
@@ -49,6 +49,24 @@ func getTargetAzureResourceGroupName(resourceGroupName string) (string, error) {
 	return resourceGroupName, nil
 }
 
+// GetTargetAzureTenant is a helper function to find the correct target Azure AD tenant ID, with provided arguments
+// taking precedence over environment variables
+func GetTargetAzureTenant(tenantID string) (string, error) {
+	return getTargetAzureTenant(tenantID)
+}
+
+func getTargetAzureTenant(tenantID string) (string, error) {
+	if tenantID == "" {
+		if id, exists := os.LookupEnv(AuthFromEnvTenant); exists {
+			return id, nil
+		}
+
+		return "", TenantIDNotFound{}
+	}
+
+	return tenantID, nil
+}
+
 // safePtrToString converts a string pointer to a non-pointer string value, or to "" if the pointer is nil.
 func safePtrToString(raw *string) string {
 	if raw == nil {
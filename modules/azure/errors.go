@@ -21,6 +21,13 @@ func (err ResourceGroupNameNotFound) Error() string {
 	return fmt.Sprintf("Could not find an Azure Resource Group name in expected environment variable %s and one was not provided for this test.", AzureResGroupName)
 }
 
+// TenantIDNotFound is an error that occurs when the Azure AD tenant ID could not be found or was not provided
+type TenantIDNotFound struct{}
+
+func (err TenantIDNotFound) Error() string {
+	return fmt.Sprintf("Could not find an Azure AD tenant ID in expected environment variable %s and one was not provided for this test.", AuthFromEnvTenant)
+}
+
 // FailedToParseError is returned when an object cannot be parsed
 type FailedToParseError struct {
 	objectType string
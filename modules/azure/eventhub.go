@@ -0,0 +1,107 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/eventhub/mgmt/2017-04-01/eventhub"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+func eventHubsClientE(subscriptionID string) (*eventhub.EventHubsClient, error) {
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	ehClient := eventhub.NewEventHubsClient(subscriptionID)
+	ehClient.Authorizer = *authorizer
+	return &ehClient, nil
+}
+
+func eventHubConsumerGroupsClientE(subscriptionID string) (*eventhub.ConsumerGroupsClient, error) {
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	cgClient := eventhub.NewConsumerGroupsClient(subscriptionID)
+	cgClient.Authorizer = *authorizer
+	return &cgClient, nil
+}
+
+// GetEventHubE gets the given Event Hub in the given namespace.
+func GetEventHubE(subscriptionID string, namespace string, resourceGroup string, eventHubName string) (*eventhub.Model, error) {
+	ehClient, err := eventHubsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventHub, err := ehClient.Get(context.Background(), resourceGroup, namespace, eventHubName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventHub, nil
+}
+
+// GetEventHub gets the given Event Hub in the given namespace. This function would fail the test if there is an error.
+func GetEventHub(t testing.TestingT, subscriptionID string, namespace string, resourceGroup string, eventHubName string) *eventhub.Model {
+	eventHub, err := GetEventHubE(subscriptionID, namespace, resourceGroup, eventHubName)
+	require.NoError(t, err)
+
+	return eventHub
+}
+
+// GetEventHubPartitionIDsE gets the IDs of the partitions of the given Event Hub.
+func GetEventHubPartitionIDsE(subscriptionID string, namespace string, resourceGroup string, eventHubName string) ([]string, error) {
+	eventHub, err := GetEventHubE(subscriptionID, namespace, resourceGroup, eventHubName)
+	if err != nil {
+		return nil, err
+	}
+
+	if eventHub.Properties == nil || eventHub.PartitionIds == nil {
+		return []string{}, nil
+	}
+	return *eventHub.PartitionIds, nil
+}
+
+// GetEventHubPartitionIDs gets the IDs of the partitions of the given Event Hub. This function would fail the test if there is an error.
+func GetEventHubPartitionIDs(t testing.TestingT, subscriptionID string, namespace string, resourceGroup string, eventHubName string) []string {
+	partitionIDs, err := GetEventHubPartitionIDsE(subscriptionID, namespace, resourceGroup, eventHubName)
+	require.NoError(t, err)
+
+	return partitionIDs
+}
+
+// ListEventHubConsumerGroupNamesE gets the names of the consumer groups of the given Event Hub, automatically crossing page boundaries
+// as required.
+func ListEventHubConsumerGroupNamesE(subscriptionID string, namespace string, resourceGroup string, eventHubName string) ([]string, error) {
+	cgClient, err := eventHubConsumerGroupsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	iteratorConsumerGroups, err := cgClient.ListByEventHubComplete(context.Background(), resourceGroup, namespace, eventHubName, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []string{}
+	for iteratorConsumerGroups.NotDone() {
+		results = append(results, *(iteratorConsumerGroups.Value()).Name)
+		if err := iteratorConsumerGroups.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// ListEventHubConsumerGroupNames gets the names of the consumer groups of the given Event Hub, automatically crossing page boundaries
+// as required. This function would fail the test if there is an error.
+func ListEventHubConsumerGroupNames(t testing.TestingT, subscriptionID string, namespace string, resourceGroup string, eventHubName string) []string {
+	results, err := ListEventHubConsumerGroupNamesE(subscriptionID, namespace, resourceGroup, eventHubName)
+	require.NoError(t, err)
+
+	return results
+}
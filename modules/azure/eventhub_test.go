@@ -0,0 +1,27 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors. These tests can be extended.
+*/
+func TestGetEventHubPartitionIDsE(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := ""
+	namespace := ""
+	resourceGroup := ""
+	eventHubName := ""
+
+	_, err := GetEventHubPartitionIDsE(subscriptionID, namespace, resourceGroup, eventHubName)
+	require.Error(t, err)
+}
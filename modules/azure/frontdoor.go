@@ -0,0 +1,278 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/frontdoor/mgmt/2020-05-01/frontdoor"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// FrontDoorExists indicates whether the specified Front Door exists.
+// This function would fail the test if there is an error.
+func FrontDoorExists(t testing.TestingT, frontDoorName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := FrontDoorExistsE(frontDoorName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// FrontDoorExistsE indicates whether the specified Front Door exists.
+func FrontDoorExistsE(frontDoorName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetFrontDoorE(frontDoorName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetFrontDoor gets a Front Door resource in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func GetFrontDoor(t testing.TestingT, frontDoorName string, resourceGroupName string, subscriptionID string) *frontdoor.FrontDoor {
+	frontDoor, err := GetFrontDoorE(frontDoorName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return frontDoor
+}
+
+// GetFrontDoorE gets a Front Door resource in the specified Azure Resource Group.
+func GetFrontDoorE(frontDoorName string, resourceGroupName string, subscriptionID string) (*frontdoor.FrontDoor, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := GetFrontDoorClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the Front Door
+	frontDoor, err := client.Get(context.Background(), resourceGroupName, frontDoorName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &frontDoor, nil
+}
+
+// GetFrontDoorFrontendHostNames gets the host names of the frontend endpoints of the specified Front Door.
+// This function would fail the test if there is an error.
+func GetFrontDoorFrontendHostNames(t testing.TestingT, frontDoorName string, resourceGroupName string, subscriptionID string) []string {
+	hostNames, err := GetFrontDoorFrontendHostNamesE(frontDoorName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return hostNames
+}
+
+// GetFrontDoorFrontendHostNamesE gets the host names of the frontend endpoints of the specified Front Door.
+func GetFrontDoorFrontendHostNamesE(frontDoorName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	frontDoor, err := GetFrontDoorE(frontDoorName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	hostNames := []string{}
+	if frontDoor.Properties == nil || frontDoor.FrontendEndpoints == nil {
+		return hostNames, nil
+	}
+
+	for _, endpoint := range *frontDoor.FrontendEndpoints {
+		if endpoint.FrontendEndpointProperties != nil && endpoint.HostName != nil {
+			hostNames = append(hostNames, *endpoint.HostName)
+		}
+	}
+
+	return hostNames, nil
+}
+
+// GetFrontDoorRoutingRuleNames gets the names of the routing rules of the specified Front Door.
+// This function would fail the test if there is an error.
+func GetFrontDoorRoutingRuleNames(t testing.TestingT, frontDoorName string, resourceGroupName string, subscriptionID string) []string {
+	ruleNames, err := GetFrontDoorRoutingRuleNamesE(frontDoorName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ruleNames
+}
+
+// GetFrontDoorRoutingRuleNamesE gets the names of the routing rules of the specified Front Door.
+func GetFrontDoorRoutingRuleNamesE(frontDoorName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	frontDoor, err := GetFrontDoorE(frontDoorName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleNames := []string{}
+	if frontDoor.Properties == nil || frontDoor.RoutingRules == nil {
+		return ruleNames, nil
+	}
+
+	for _, rule := range *frontDoor.RoutingRules {
+		ruleNames = append(ruleNames, *rule.Name)
+	}
+
+	return ruleNames, nil
+}
+
+// GetFrontDoorBackendAddresses gets the addresses of the enabled backends in the specified backend pool of the
+// specified Front Door. This function would fail the test if there is an error.
+func GetFrontDoorBackendAddresses(t testing.TestingT, backendPoolName string, frontDoorName string, resourceGroupName string, subscriptionID string) []string {
+	addresses, err := GetFrontDoorBackendAddressesE(backendPoolName, frontDoorName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return addresses
+}
+
+// GetFrontDoorBackendAddressesE gets the addresses of the enabled backends in the specified backend pool of the
+// specified Front Door.
+func GetFrontDoorBackendAddressesE(backendPoolName string, frontDoorName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	frontDoor, err := GetFrontDoorE(frontDoorName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if frontDoor.Properties != nil && frontDoor.BackendPools != nil {
+		for _, pool := range *frontDoor.BackendPools {
+			if pool.Name != nil && *pool.Name == backendPoolName {
+				return getEnabledFrontDoorBackendAddresses(pool), nil
+			}
+		}
+	}
+
+	return nil, NewNotFoundError("Front Door Backend Pool", backendPoolName, frontDoorName)
+}
+
+// GetFrontDoorWAFPolicy gets a Front Door web application firewall policy in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func GetFrontDoorWAFPolicy(t testing.TestingT, policyName string, resourceGroupName string, subscriptionID string) *frontdoor.WebApplicationFirewallPolicy {
+	policy, err := GetFrontDoorWAFPolicyE(policyName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetFrontDoorWAFPolicyE gets a Front Door web application firewall policy in the specified Azure Resource Group.
+func GetFrontDoorWAFPolicyE(policyName string, resourceGroupName string, subscriptionID string) (*frontdoor.WebApplicationFirewallPolicy, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := CreateFrontDoorWAFPoliciesClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	// Get the WAF policy
+	policy, err := client.Get(context.Background(), resourceGroupName, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// GetFrontDoorWAFPolicyMode gets the mode ("Prevention" or "Detection") of the specified Front Door web application
+// firewall policy. An empty string is returned if the policy is disabled.
+// This function would fail the test if there is an error.
+func GetFrontDoorWAFPolicyMode(t testing.TestingT, policyName string, resourceGroupName string, subscriptionID string) string {
+	mode, err := GetFrontDoorWAFPolicyModeE(policyName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return mode
+}
+
+// GetFrontDoorWAFPolicyModeE gets the mode ("Prevention" or "Detection") of the specified Front Door web application
+// firewall policy. An empty string is returned if the policy is disabled.
+func GetFrontDoorWAFPolicyModeE(policyName string, resourceGroupName string, subscriptionID string) (string, error) {
+	policy, err := GetFrontDoorWAFPolicyE(policyName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	return getFrontDoorWAFPolicyMode(policy), nil
+}
+
+// ProbeFrontDoor sends HTTPS GET requests for the given path to the default frontend host name
+// (<name>.azurefd.net) of the specified Front Door until it responds with the expected status code, and returns the
+// body of the response. This function would fail the test if that does not happen within the given number of retries.
+func ProbeFrontDoor(t testing.TestingT, frontDoorName string, resourceGroupName string, subscriptionID string, path string, headers map[string]string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := ProbeFrontDoorE(t, frontDoorName, resourceGroupName, subscriptionID, path, headers, expectedStatus, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return body
+}
+
+// ProbeFrontDoorE sends HTTPS GET requests for the given path to the default frontend host name
+// (<name>.azurefd.net) of the specified Front Door until it responds with the expected status code, and returns the
+// body of the response.
+func ProbeFrontDoorE(t testing.TestingT, frontDoorName string, resourceGroupName string, subscriptionID string, path string, headers map[string]string, expectedStatus int, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	frontDoor, err := GetFrontDoorE(frontDoorName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	if frontDoor.Properties == nil || frontDoor.Cname == nil {
+		return "", NewNotFoundError("Front Door Host Name", "Any", frontDoorName)
+	}
+
+	url := fmt.Sprintf("https://%s/%s", *frontDoor.Cname, strings.TrimPrefix(path, "/"))
+	return http_helper.HTTPDoWithRetryE(t, "GET", url, nil, headers, expectedStatus, retries, sleepBetweenRetries, nil)
+}
+
+// GetFrontDoorClientE gets a new Front Door client in the specified Azure Subscription.
+func GetFrontDoorClientE(subscriptionID string) (*frontdoor.FrontDoorsClient, error) {
+	// Get the Front Door client
+	client, err := CreateFrontDoorClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	return client, nil
+}
+
+// getEnabledFrontDoorBackendAddresses returns the addresses of the enabled backends of the given backend pool.
+func getEnabledFrontDoorBackendAddresses(pool frontdoor.BackendPool) []string {
+	addresses := []string{}
+	if pool.BackendPoolProperties == nil || pool.Backends == nil {
+		return addresses
+	}
+
+	for _, backend := range *pool.Backends {
+		if backend.EnabledState == frontdoor.Enabled && backend.Address != nil {
+			addresses = append(addresses, *backend.Address)
+		}
+	}
+
+	return addresses
+}
+
+// getFrontDoorWAFPolicyMode returns the mode of the given Front Door WAF policy, or an empty string if it is disabled.
+func getFrontDoorWAFPolicyMode(policy *frontdoor.WebApplicationFirewallPolicy) string {
+	if policy.WebApplicationFirewallPolicyProperties == nil || policy.PolicySettings == nil {
+		return ""
+	}
+
+	if policy.PolicySettings.EnabledState != frontdoor.PolicyEnabledStateEnabled {
+		return ""
+	}
+
+	return string(policy.PolicySettings.Mode)
+}
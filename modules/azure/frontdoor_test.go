@@ -0,0 +1,75 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/frontdoor/mgmt/2020-05-01/frontdoor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods can be mocked or Create/Delete APIs are added, these tests can be extended.
+*/
+
+func TestFrontDoorExistsE(t *testing.T) {
+	t.Parallel()
+
+	frontDoorName := ""
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := FrontDoorExistsE(frontDoorName, resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestGetFrontDoorWAFPolicyE(t *testing.T) {
+	t.Parallel()
+
+	policyName := ""
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := GetFrontDoorWAFPolicyE(policyName, resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestGetEnabledFrontDoorBackendAddresses(t *testing.T) {
+	t.Parallel()
+
+	pool := frontdoor.BackendPool{
+		BackendPoolProperties: &frontdoor.BackendPoolProperties{
+			Backends: &[]frontdoor.Backend{
+				{Address: stringPtr("app1.azurewebsites.net"), EnabledState: frontdoor.Enabled},
+				{Address: stringPtr("app2.azurewebsites.net"), EnabledState: frontdoor.Disabled},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"app1.azurewebsites.net"}, getEnabledFrontDoorBackendAddresses(pool))
+}
+
+func TestGetFrontDoorWAFPolicyMode(t *testing.T) {
+	t.Parallel()
+
+	policy := &frontdoor.WebApplicationFirewallPolicy{
+		WebApplicationFirewallPolicyProperties: &frontdoor.WebApplicationFirewallPolicyProperties{
+			PolicySettings: &frontdoor.PolicySettings{
+				EnabledState: frontdoor.PolicyEnabledStateEnabled,
+				Mode:         frontdoor.Prevention,
+			},
+		},
+	}
+	assert.Equal(t, "Prevention", getFrontDoorWAFPolicyMode(policy))
+
+	policy.PolicySettings.EnabledState = frontdoor.PolicyEnabledStateDisabled
+	assert.Equal(t, "", getFrontDoorWAFPolicyMode(policy))
+}
@@ -133,13 +133,13 @@ func NewKeyVaultAuthorizerE() (*autorest.Authorizer, error) {
 	// Execute logic to return an authorizer from the correct method
 	if clientIDExists && tenantIDExists {
 		authorizer, err := kvauth.NewAuthorizerFromEnvironment()
-		return &authorizer, err
+		return throttleAuthorizer(authorizer), err
 	} else if fileAuthSet {
 		authorizer, err := kvauth.NewAuthorizerFromFile()
-		return &authorizer, err
+		return throttleAuthorizer(authorizer), err
 	} else {
 		authorizer, err := kvauth.NewAuthorizerFromCLI()
-		return &authorizer, err
+		return throttleAuthorizer(authorizer), err
 	}
 }
 
@@ -0,0 +1,265 @@
+package azure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// ServiceBusEndpointSuffixName is the name of the ServiceBusEndpointSuffix field in the Environment struct.
+	ServiceBusEndpointSuffixName = "ServiceBusEndpointSuffix"
+
+	// messagingAADResource is the Azure AD resource for the data plane of Service Bus and Event Hubs.
+	messagingAADResource = "https://servicebus.azure.net"
+
+	// messagingContentType is the content type the Service Bus and Event Hubs REST APIs expect for sent messages.
+	messagingContentType = "application/atom+xml;type=entry;charset=utf-8"
+
+	// sasTokenLifetime is how long the SAS tokens generated for each request are valid.
+	sasTokenLifetime = time.Hour
+)
+
+// MessagingAuth holds the credentials used to call the data plane of a Service Bus or Event Hubs namespace. If
+// SasKeyName is empty, an Azure AD token is requested with the same auth mechanisms as NewAuthorizer, in which case the
+// identity needs a data plane role such as "Azure Service Bus Data Owner".
+type MessagingAuth struct {
+	SasKeyName string // The name of a shared access policy, e.g. "RootManageSharedAccessKey"
+	SasKey     string // The primary or secondary key of that shared access policy
+}
+
+// ServiceBusMessage is a message received from a Service Bus queue or subscription.
+type ServiceBusMessage struct {
+	Body          string
+	MessageID     string
+	DeliveryCount int
+	// Properties holds the string application properties of the message, keyed by their canonical HTTP header form
+	// (e.g. "Deadletterreason"). Use Property to look them up by their original name.
+	Properties map[string]string
+}
+
+// Property returns the string application property with the given name, e.g. "DeadLetterReason" for dead-lettered
+// messages.
+func (message ServiceBusMessage) Property(name string) (string, bool) {
+	value, ok := message.Properties[http.CanonicalHeaderKey(name)]
+	return value, ok
+}
+
+// serviceBusBrokerProperties holds the subset of the BrokerProperties header we expose on ServiceBusMessage.
+type serviceBusBrokerProperties struct {
+	MessageId     string
+	DeliveryCount int
+}
+
+// SendServiceBusMessage sends a message with the given body and string application properties to the given Service Bus
+// queue or topic. This function would fail the test if there is an error.
+func SendServiceBusMessage(t testing.TestingT, namespace string, entityPath string, auth MessagingAuth, body string, properties map[string]string) {
+	err := SendServiceBusMessageE(namespace, entityPath, auth, body, properties)
+	require.NoError(t, err)
+}
+
+// SendServiceBusMessageE sends a message with the given body and string application properties to the given Service Bus
+// queue or topic.
+func SendServiceBusMessageE(namespace string, entityPath string, auth MessagingAuth, body string, properties map[string]string) error {
+	return sendMessagingMessageE(namespace, entityPath, auth, body, properties)
+}
+
+// ReceiveServiceBusMessage receives and deletes the next message from the given Service Bus queue or subscription
+// (use "<topic>/subscriptions/<subscription>" as the entity path), waiting up to the given timeout for one to arrive.
+// Returns nil if no message arrived. This function would fail the test if there is an error.
+func ReceiveServiceBusMessage(t testing.TestingT, namespace string, entityPath string, auth MessagingAuth, timeout time.Duration) *ServiceBusMessage {
+	message, err := ReceiveServiceBusMessageE(namespace, entityPath, auth, timeout)
+	require.NoError(t, err)
+	return message
+}
+
+// ReceiveServiceBusMessageE receives and deletes the next message from the given Service Bus queue or subscription
+// (use "<topic>/subscriptions/<subscription>" as the entity path), waiting up to the given timeout for one to arrive.
+// Returns nil if no message arrived.
+func ReceiveServiceBusMessageE(namespace string, entityPath string, auth MessagingAuth, timeout time.Duration) (*ServiceBusMessage, error) {
+	endpoint, err := getMessagingEndpointE(namespace, entityPath)
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL := fmt.Sprintf("%s/messages/head?timeout=%d", endpoint, int(timeout.Seconds()))
+	resp, body, err := doMessagingRequestE(http.MethodDelete, requestURL, endpoint, auth, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+		return newServiceBusMessage(resp.Header, string(body))
+	default:
+		return nil, fmt.Errorf("Receiving from %s failed with status %d: %s", entityPath, resp.StatusCode, string(body))
+	}
+}
+
+// ReceiveServiceBusDeadLetterMessage receives and deletes the next message from the dead-letter queue of the given
+// Service Bus queue or subscription, waiting up to the given timeout for one to arrive. Returns nil if there was none.
+// This function would fail the test if there is an error.
+func ReceiveServiceBusDeadLetterMessage(t testing.TestingT, namespace string, entityPath string, auth MessagingAuth, timeout time.Duration) *ServiceBusMessage {
+	message, err := ReceiveServiceBusDeadLetterMessageE(namespace, entityPath, auth, timeout)
+	require.NoError(t, err)
+	return message
+}
+
+// ReceiveServiceBusDeadLetterMessageE receives and deletes the next message from the dead-letter queue of the given
+// Service Bus queue or subscription, waiting up to the given timeout for one to arrive. Returns nil if there was none.
+func ReceiveServiceBusDeadLetterMessageE(namespace string, entityPath string, auth MessagingAuth, timeout time.Duration) (*ServiceBusMessage, error) {
+	return ReceiveServiceBusMessageE(namespace, entityPath+"/$DeadLetterQueue", auth, timeout)
+}
+
+// SendEventHubEvent sends an event with the given body and string application properties to the given Event Hub.
+// Events can only be read over AMQP, so verify delivery through the consumer of the module under test.
+// This function would fail the test if there is an error.
+func SendEventHubEvent(t testing.TestingT, namespace string, eventHubName string, auth MessagingAuth, body string, properties map[string]string) {
+	err := SendEventHubEventE(namespace, eventHubName, auth, body, properties)
+	require.NoError(t, err)
+}
+
+// SendEventHubEventE sends an event with the given body and string application properties to the given Event Hub.
+// Events can only be read over AMQP, so verify delivery through the consumer of the module under test.
+func SendEventHubEventE(namespace string, eventHubName string, auth MessagingAuth, body string, properties map[string]string) error {
+	return sendMessagingMessageE(namespace, eventHubName, auth, body, properties)
+}
+
+// sendMessagingMessageE sends a message to the given Service Bus queue or topic, or Event Hub, which share the same
+// REST API for sending.
+func sendMessagingMessageE(namespace string, entityPath string, auth MessagingAuth, body string, properties map[string]string) error {
+	endpoint, err := getMessagingEndpointE(namespace, entityPath)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": messagingContentType}
+	for key, value := range properties {
+		// Application properties are sent as headers with JSON encoded values
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		headers[key] = string(encoded)
+	}
+
+	resp, respBody, err := doMessagingRequestE(http.MethodPost, endpoint+"/messages", endpoint, auth, strings.NewReader(body), headers)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("Sending to %s failed with status %d: %s", entityPath, resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// doMessagingRequestE performs an authenticated request against the data plane of a Service Bus or Event Hubs
+// namespace and returns the response along with its body.
+func doMessagingRequestE(method string, requestURL string, resourceURI string, auth MessagingAuth, body io.Reader, headers map[string]string) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if auth.SasKeyName != "" {
+		req.Header.Set("Authorization", newSasToken(resourceURI, auth.SasKeyName, auth.SasKey, time.Now().Add(sasTokenLifetime)))
+	} else {
+		authorizer, err := NewAuthorizerWithResource(messagingAADResource)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		req, err = autorest.Prepare(req, (*authorizer).WithAuthorization())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, respBody, nil
+}
+
+// getMessagingEndpointE returns the data plane URL of the given entity in the given Service Bus or Event Hubs
+// namespace, for the Azure environment that is currently setup.
+func getMessagingEndpointE(namespace string, entityPath string) (string, error) {
+	suffix, err := getEnvironmentEndpointE(ServiceBusEndpointSuffixName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("https://%s.%s/%s", namespace, suffix, strings.Trim(entityPath, "/")), nil
+}
+
+// newSasToken returns a shared access signature for the given resource URI, signed with the given shared access policy
+// key and valid until the given expiry.
+func newSasToken(resourceURI string, keyName string, key string, expiry time.Time) string {
+	encodedURI := url.QueryEscape(resourceURI)
+	expiryString := strconv.FormatInt(expiry.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(encodedURI + "\n" + expiryString))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s&skn=%s", encodedURI, url.QueryEscape(signature), expiryString, keyName)
+}
+
+// newServiceBusMessage builds a ServiceBusMessage from the headers and body of a Service Bus receive response. String
+// application properties are returned as headers with JSON encoded (quoted) values, which distinguishes them from the
+// standard HTTP headers.
+func newServiceBusMessage(header http.Header, body string) (*ServiceBusMessage, error) {
+	message := &ServiceBusMessage{Body: body, Properties: map[string]string{}}
+
+	if brokerProperties := header.Get("BrokerProperties"); brokerProperties != "" {
+		var props serviceBusBrokerProperties
+		if err := json.Unmarshal([]byte(brokerProperties), &props); err != nil {
+			return nil, err
+		}
+		message.MessageID = props.MessageId
+		message.DeliveryCount = props.DeliveryCount
+	}
+
+	for key, values := range header {
+		if len(values) == 0 || !strings.HasPrefix(values[0], "\"") {
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal([]byte(values[0]), &value); err == nil {
+			message.Properties[key] = value
+		}
+	}
+
+	return message, nil
+}
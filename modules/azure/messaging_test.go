@@ -0,0 +1,51 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSasToken(t *testing.T) {
+	t.Parallel()
+
+	expiry := time.Unix(1600000000, 0)
+	token := newSasToken("https://ns.servicebus.windows.net/queue", "RootManageSharedAccessKey", "secret", expiry)
+
+	assert.True(t, strings.HasPrefix(token, "SharedAccessSignature sr=https%3A%2F%2Fns.servicebus.windows.net%2Fqueue&sig="))
+	assert.True(t, strings.HasSuffix(token, "&se=1600000000&skn=RootManageSharedAccessKey"))
+	assert.Equal(t, token, newSasToken("https://ns.servicebus.windows.net/queue", "RootManageSharedAccessKey", "secret", expiry))
+	assert.NotEqual(t, token, newSasToken("https://ns.servicebus.windows.net/queue", "RootManageSharedAccessKey", "other", expiry))
+}
+
+func TestNewServiceBusMessage(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("BrokerProperties", `{"DeliveryCount":2,"MessageId":"abc"}`)
+	header.Set("Content-Type", "application/atom+xml;type=entry;charset=utf-8")
+	header.Set("DeadLetterReason", `"MaxDeliveryCountExceeded"`)
+
+	message, err := newServiceBusMessage(header, "hello")
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello", message.Body)
+	assert.Equal(t, "abc", message.MessageID)
+	assert.Equal(t, 2, message.DeliveryCount)
+
+	reason, ok := message.Property("DeadLetterReason")
+	assert.True(t, ok)
+	assert.Equal(t, "MaxDeliveryCountExceeded", reason)
+
+	_, ok = message.Property("Content-Type")
+	assert.False(t, ok)
+}
@@ -0,0 +1,102 @@
+package azure
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// GetMissingResourceGroupPermissions checks which of the given management actions (e.g.
+// "Microsoft.Network/virtualNetworks/write") the current credentials are not allowed to perform in the specified
+// Azure Resource Group, and returns those actions. This function would fail the test if there is an error.
+func GetMissingResourceGroupPermissions(t testing.TestingT, actions []string, resourceGroupName string, subscriptionID string) []string {
+	missing, err := GetMissingResourceGroupPermissionsE(actions, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return missing
+}
+
+// GetMissingResourceGroupPermissionsE checks which of the given management actions (e.g.
+// "Microsoft.Network/virtualNetworks/write") the current credentials are not allowed to perform in the specified
+// Azure Resource Group, and returns those actions.
+func GetMissingResourceGroupPermissionsE(actions []string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := CreatePermissionsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	permissions, err := client.ListForResourceGroupComplete(context.Background(), resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := []authorization.Permission{}
+	for permissions.NotDone() {
+		granted = append(granted, permissions.Value())
+
+		if err := permissions.Next(); err != nil {
+			return nil, err
+		}
+	}
+
+	missing := []string{}
+	for _, action := range actions {
+		if !permissionsAllowAction(granted, action) {
+			missing = append(missing, action)
+		}
+	}
+
+	return missing, nil
+}
+
+// permissionsAllowAction indicates whether any of the given permissions allows the given action, i.e. one of its
+// Actions matches it and none of its NotActions does.
+func permissionsAllowAction(permissions []authorization.Permission, action string) bool {
+	for _, permission := range permissions {
+		if permission.Actions == nil || !anyActionPatternMatches(*permission.Actions, action) {
+			continue
+		}
+
+		if permission.NotActions != nil && anyActionPatternMatches(*permission.NotActions, action) {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// anyActionPatternMatches indicates whether the given action matches any of the given action patterns, which can
+// contain "*" wildcards (e.g. "Microsoft.Network/*/read"). Actions are case insensitive.
+func anyActionPatternMatches(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		quoted := strings.Split(pattern, "*")
+		for i, part := range quoted {
+			quoted[i] = regexp.QuoteMeta(part)
+		}
+
+		if regexp.MustCompile("(?i)^" + strings.Join(quoted, ".*") + "$").MatchString(action) {
+			return true
+		}
+	}
+
+	return false
+}
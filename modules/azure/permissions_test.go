@@ -0,0 +1,47 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods can be mocked or Create/Delete APIs are added, these tests can be extended.
+*/
+
+func TestGetMissingResourceGroupPermissionsE(t *testing.T) {
+	t.Parallel()
+
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := GetMissingResourceGroupPermissionsE([]string{"Microsoft.Network/virtualNetworks/write"}, resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestPermissionsAllowAction(t *testing.T) {
+	t.Parallel()
+
+	permissions := []authorization.Permission{
+		{
+			Actions:    &[]string{"Microsoft.Network/*", "Microsoft.Compute/*/read"},
+			NotActions: &[]string{"Microsoft.Network/publicIPAddresses/*"},
+		},
+	}
+
+	assert.True(t, permissionsAllowAction(permissions, "Microsoft.Network/virtualNetworks/write"))
+	assert.True(t, permissionsAllowAction(permissions, "microsoft.compute/virtualMachines/read"))
+	assert.False(t, permissionsAllowAction(permissions, "Microsoft.Compute/virtualMachines/write"))
+	assert.False(t, permissionsAllowAction(permissions, "Microsoft.Network/publicIPAddresses/write"))
+	assert.False(t, permissionsAllowAction(permissions, "Microsoft.Storage/storageAccounts/read"))
+}
@@ -0,0 +1,200 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// The ID of the built-in Run Command that executes a shell script on a Linux VM.
+const linuxRunShellScriptCommandID = "RunShellScript"
+
+// GetPrivateDNSARecordIPs gets the IPv4 addresses of the specified A record (e.g. "mydb" for
+// "mydb.privatelink.database.windows.net") in the specified Private DNS zone.
+// This function would fail the test if there is an error.
+func GetPrivateDNSARecordIPs(t testing.TestingT, recordName string, zoneName string, resourceGroupName string, subscriptionID string) []string {
+	ips, err := GetPrivateDNSARecordIPsE(recordName, zoneName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ips
+}
+
+// GetPrivateDNSARecordIPsE gets the IPv4 addresses of the specified A record (e.g. "mydb" for
+// "mydb.privatelink.database.windows.net") in the specified Private DNS zone.
+func GetPrivateDNSARecordIPsE(recordName string, zoneName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := CreatePrivateDNSRecordSetsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	// Get the A record set
+	recordSet, err := client.Get(context.Background(), resourceGroupName, zoneName, privatedns.A, recordName)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := []string{}
+	if recordSet.RecordSetProperties == nil || recordSet.ARecords == nil {
+		return ips, nil
+	}
+
+	for _, record := range *recordSet.ARecords {
+		if record.Ipv4Address != nil {
+			ips = append(ips, *record.Ipv4Address)
+		}
+	}
+
+	return ips, nil
+}
+
+// PrivateDNSZoneLinkedToVirtualNetwork indicates whether the specified Private DNS zone has a completed link to the
+// specified Virtual Network, so that VMs in it resolve names in the zone.
+// This function would fail the test if there is an error.
+func PrivateDNSZoneLinkedToVirtualNetwork(t testing.TestingT, zoneName string, vnetName string, resourceGroupName string, subscriptionID string) bool {
+	linked, err := PrivateDNSZoneLinkedToVirtualNetworkE(zoneName, vnetName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return linked
+}
+
+// PrivateDNSZoneLinkedToVirtualNetworkE indicates whether the specified Private DNS zone has a completed link to the
+// specified Virtual Network, so that VMs in it resolve names in the zone.
+func PrivateDNSZoneLinkedToVirtualNetworkE(zoneName string, vnetName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return false, err
+	}
+
+	// Get the client reference
+	client, err := CreatePrivateDNSVirtualNetworkLinksClientE(subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return false, err
+	}
+	client.Authorizer = *authorizer
+
+	links, err := client.ListComplete(context.Background(), resourceGroupName, zoneName, nil)
+	if err != nil {
+		return false, err
+	}
+
+	for links.NotDone() {
+		link := links.Value()
+		props := link.VirtualNetworkLinkProperties
+		if props != nil && props.VirtualNetwork != nil && props.VirtualNetwork.ID != nil &&
+			GetNameFromResourceID(*props.VirtualNetwork.ID) == vnetName &&
+			props.VirtualNetworkLinkState == privatedns.Completed {
+			return true, nil
+		}
+
+		if err := links.Next(); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// ResolveHostnameFromVirtualMachine resolves the given host name to its IPv4 addresses from inside the specified Linux
+// Virtual Machine using Run Command, which shows what a client in that VM's Virtual Network would connect to (e.g. the
+// private IP of a Private Endpoint). This function would fail the test if there is an error.
+func ResolveHostnameFromVirtualMachine(t testing.TestingT, hostname string, vmName string, resGroupName string, subscriptionID string) []string {
+	ips, err := ResolveHostnameFromVirtualMachineE(hostname, vmName, resGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ips
+}
+
+// ResolveHostnameFromVirtualMachineE resolves the given host name to its IPv4 addresses from inside the specified Linux
+// Virtual Machine using Run Command, which shows what a client in that VM's Virtual Network would connect to (e.g. the
+// private IP of a Private Endpoint).
+func ResolveHostnameFromVirtualMachineE(hostname string, vmName string, resGroupName string, subscriptionID string) ([]string, error) {
+	if strings.ContainsAny(hostname, "'\"` ;&|$\\") {
+		return nil, fmt.Errorf("Invalid host name %q", hostname)
+	}
+
+	// Validate Azure Resource Group Name
+	resGroupName, err := getTargetAzureResourceGroupName(resGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := GetVirtualMachineClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	commandID := linuxRunShellScriptCommandID
+	script := fmt.Sprintf("getent ahostsv4 '%s' | awk '{ print $1 }' | sort -u", hostname)
+	input := compute.RunCommandInput{
+		CommandID: &commandID,
+		Script:    &[]string{script},
+	}
+
+	// Run Command is a long running operation, so wait for it to complete
+	ctx := context.Background()
+	future, err := client.RunCommand(ctx, resGroupName, vmName, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := future.WaitForCompletionRef(ctx, client.Client); err != nil {
+		return nil, err
+	}
+
+	result, err := future.Result(*client)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Value == nil || len(*result.Value) == 0 || (*result.Value)[0].Message == nil {
+		return nil, fmt.Errorf("Run Command on VM %s returned no output", vmName)
+	}
+
+	return parseResolvedIPs(*(*result.Value)[0].Message), nil
+}
+
+// parseResolvedIPs extracts the IP addresses from the stdout section of a Linux Run Command output message, which looks
+// like "Enable succeeded: \n[stdout]\n10.0.0.4\n\n[stderr]\n".
+func parseResolvedIPs(message string) []string {
+	stdout := message
+	if start := strings.Index(stdout, "[stdout]"); start >= 0 {
+		stdout = stdout[start+len("[stdout]"):]
+	}
+	if end := strings.Index(stdout, "[stderr]"); end >= 0 {
+		stdout = stdout[:end]
+	}
+
+	ips := []string{}
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if net.ParseIP(line) != nil {
+			ips = append(ips, line)
+		}
+	}
+
+	return ips
+}
@@ -0,0 +1,47 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods can be mocked or Create/Delete APIs are added, these tests can be extended.
+*/
+
+func TestGetPrivateDNSARecordIPsE(t *testing.T) {
+	t.Parallel()
+
+	recordName := ""
+	zoneName := ""
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := GetPrivateDNSARecordIPsE(recordName, zoneName, resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestResolveHostnameFromVirtualMachineRejectsShellCharacters(t *testing.T) {
+	t.Parallel()
+
+	_, err := ResolveHostnameFromVirtualMachineE("example.com; rm -rf /", "vm", "rg", "sub")
+
+	require.Error(t, err)
+}
+
+func TestParseResolvedIPs(t *testing.T) {
+	t.Parallel()
+
+	message := "Enable succeeded: \n[stdout]\n10.0.0.4\n10.0.0.5\n\n[stderr]\nsome warning\n"
+
+	assert.Equal(t, []string{"10.0.0.4", "10.0.0.5"}, parseResolvedIPs(message))
+}
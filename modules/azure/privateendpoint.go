@@ -0,0 +1,240 @@
+package azure
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	autorestAzure "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// PrivateEndpointApprovedStatus is the status of a private endpoint connection that has been approved by the owner
+// of the target resource.
+const PrivateEndpointApprovedStatus = "Approved"
+
+// PrivateEndpointExists indicates whether the specified Private Endpoint exists.
+// This function would fail the test if there is an error.
+func PrivateEndpointExists(t testing.TestingT, privateEndpointName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := PrivateEndpointExistsE(privateEndpointName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// PrivateEndpointExistsE indicates whether the specified Private Endpoint exists.
+func PrivateEndpointExistsE(privateEndpointName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetPrivateEndpointE(privateEndpointName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetPrivateEndpoint gets a Private Endpoint network resource in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func GetPrivateEndpoint(t testing.TestingT, privateEndpointName string, resourceGroupName string, subscriptionID string) *network.PrivateEndpoint {
+	privateEndpoint, err := GetPrivateEndpointE(privateEndpointName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return privateEndpoint
+}
+
+// GetPrivateEndpointE gets a Private Endpoint network resource in the specified Azure Resource Group.
+func GetPrivateEndpointE(privateEndpointName string, resourceGroupName string, subscriptionID string) (*network.PrivateEndpoint, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := CreatePrivateEndpointsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	// Get the Private Endpoint
+	privateEndpoint, err := client.Get(context.Background(), resourceGroupName, privateEndpointName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &privateEndpoint, nil
+}
+
+// GetPrivateEndpointConnectionStatus gets the status ("Approved", "Pending", "Rejected" or "Disconnected") of the
+// connection between the specified Private Endpoint and its target resource.
+// This function would fail the test if there is an error.
+func GetPrivateEndpointConnectionStatus(t testing.TestingT, privateEndpointName string, resourceGroupName string, subscriptionID string) string {
+	status, err := GetPrivateEndpointConnectionStatusE(privateEndpointName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return status
+}
+
+// GetPrivateEndpointConnectionStatusE gets the status ("Approved", "Pending", "Rejected" or "Disconnected") of the
+// connection between the specified Private Endpoint and its target resource.
+func GetPrivateEndpointConnectionStatusE(privateEndpointName string, resourceGroupName string, subscriptionID string) (string, error) {
+	privateEndpoint, err := GetPrivateEndpointE(privateEndpointName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	connection := getPrivateLinkServiceConnection(privateEndpoint)
+	if connection == nil || connection.PrivateLinkServiceConnectionProperties == nil || connection.PrivateLinkServiceConnectionState == nil || connection.PrivateLinkServiceConnectionState.Status == nil {
+		return "", NewNotFoundError("Private Link Service Connection", "Any", privateEndpointName)
+	}
+
+	return *connection.PrivateLinkServiceConnectionState.Status, nil
+}
+
+// PrivateEndpointIsApproved indicates whether the connection between the specified Private Endpoint and its target resource
+// has been approved. This function would fail the test if there is an error.
+func PrivateEndpointIsApproved(t testing.TestingT, privateEndpointName string, resourceGroupName string, subscriptionID string) bool {
+	approved, err := PrivateEndpointIsApprovedE(privateEndpointName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return approved
+}
+
+// PrivateEndpointIsApprovedE indicates whether the connection between the specified Private Endpoint and its target resource
+// has been approved.
+func PrivateEndpointIsApprovedE(privateEndpointName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	status, err := GetPrivateEndpointConnectionStatusE(privateEndpointName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	return status == PrivateEndpointApprovedStatus, nil
+}
+
+// GetPrivateEndpointPrivateIPs gets the private IP addresses of the network interfaces of the specified Private Endpoint.
+// This function would fail the test if there is an error.
+func GetPrivateEndpointPrivateIPs(t testing.TestingT, privateEndpointName string, resourceGroupName string, subscriptionID string) []string {
+	ips, err := GetPrivateEndpointPrivateIPsE(privateEndpointName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ips
+}
+
+// GetPrivateEndpointPrivateIPsE gets the private IP addresses of the network interfaces of the specified Private Endpoint.
+func GetPrivateEndpointPrivateIPsE(privateEndpointName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	privateEndpoint, err := GetPrivateEndpointE(privateEndpointName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := []string{}
+	if privateEndpoint.PrivateEndpointProperties == nil || privateEndpoint.NetworkInterfaces == nil {
+		return ips, nil
+	}
+
+	for _, nic := range *privateEndpoint.NetworkInterfaces {
+		// Private Endpoint network interfaces are managed by Azure and may live in a different resource group
+		nicResource, err := autorestAzure.ParseResourceID(*nic.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		nicIPs, err := GetNetworkInterfacePrivateIPsE(nicResource.ResourceName, nicResource.ResourceGroup, subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, nicIPs...)
+	}
+
+	return ips, nil
+}
+
+// GetResourcePublicNetworkAccess gets the publicNetworkAccess property ("Enabled" or "Disabled") of the resource with
+// the given ID, using the given API version of its resource provider (e.g. "2021-02-01" for Microsoft.Sql/servers).
+// This function would fail the test if there is an error.
+func GetResourcePublicNetworkAccess(t testing.TestingT, resourceID string, apiVersion string, subscriptionID string) string {
+	access, err := GetResourcePublicNetworkAccessE(resourceID, apiVersion, subscriptionID)
+	require.NoError(t, err)
+	return access
+}
+
+// GetResourcePublicNetworkAccessE gets the publicNetworkAccess property ("Enabled" or "Disabled") of the resource with
+// the given ID, using the given API version of its resource provider (e.g. "2021-02-01" for Microsoft.Sql/servers).
+func GetResourcePublicNetworkAccessE(resourceID string, apiVersion string, subscriptionID string) (string, error) {
+	client, err := CreateResourcesClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return "", err
+	}
+	client.Authorizer = *authorizer
+
+	resource, err := client.GetByID(context.Background(), resourceID, apiVersion)
+	if err != nil {
+		return "", err
+	}
+
+	access, ok := getPublicNetworkAccess(resource.Properties)
+	if !ok {
+		return "", NewNotFoundError("publicNetworkAccess property", "Any", resourceID)
+	}
+
+	return access, nil
+}
+
+// PublicNetworkAccessDisabled indicates whether public network access is disabled on the resource with the given ID, so
+// that it can only be reached through its private endpoints. See GetResourcePublicNetworkAccess for details.
+// This function would fail the test if there is an error.
+func PublicNetworkAccessDisabled(t testing.TestingT, resourceID string, apiVersion string, subscriptionID string) bool {
+	disabled, err := PublicNetworkAccessDisabledE(resourceID, apiVersion, subscriptionID)
+	require.NoError(t, err)
+	return disabled
+}
+
+// PublicNetworkAccessDisabledE indicates whether public network access is disabled on the resource with the given ID, so
+// that it can only be reached through its private endpoints. See GetResourcePublicNetworkAccessE for details.
+func PublicNetworkAccessDisabledE(resourceID string, apiVersion string, subscriptionID string) (bool, error) {
+	access, err := GetResourcePublicNetworkAccessE(resourceID, apiVersion, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(access, "Disabled"), nil
+}
+
+// getPrivateLinkServiceConnection returns the automatically or manually approved connection of the given Private
+// Endpoint, or nil if it has none.
+func getPrivateLinkServiceConnection(privateEndpoint *network.PrivateEndpoint) *network.PrivateLinkServiceConnection {
+	props := privateEndpoint.PrivateEndpointProperties
+	if props == nil {
+		return nil
+	}
+
+	if props.PrivateLinkServiceConnections != nil && len(*props.PrivateLinkServiceConnections) > 0 {
+		return &(*props.PrivateLinkServiceConnections)[0]
+	}
+
+	if props.ManualPrivateLinkServiceConnections != nil && len(*props.ManualPrivateLinkServiceConnections) > 0 {
+		return &(*props.ManualPrivateLinkServiceConnections)[0]
+	}
+
+	return nil
+}
+
+// getPublicNetworkAccess returns the publicNetworkAccess value in the given generic resource properties.
+func getPublicNetworkAccess(properties interface{}) (string, bool) {
+	propertiesMap, ok := properties.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	access, ok := propertiesMap["publicNetworkAccess"].(string)
+	return access, ok
+}
@@ -0,0 +1,64 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods can be mocked or Create/Delete APIs are added, these tests can be extended.
+*/
+
+func TestPrivateEndpointExistsE(t *testing.T) {
+	t.Parallel()
+
+	privateEndpointName := ""
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := PrivateEndpointExistsE(privateEndpointName, resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestGetPrivateLinkServiceConnectionPrefersAutomaticConnection(t *testing.T) {
+	t.Parallel()
+
+	automatic := "automatic"
+	manual := "manual"
+	privateEndpoint := &network.PrivateEndpoint{
+		PrivateEndpointProperties: &network.PrivateEndpointProperties{
+			PrivateLinkServiceConnections:       &[]network.PrivateLinkServiceConnection{{Name: &automatic}},
+			ManualPrivateLinkServiceConnections: &[]network.PrivateLinkServiceConnection{{Name: &manual}},
+		},
+	}
+
+	connection := getPrivateLinkServiceConnection(privateEndpoint)
+	require.NotNil(t, connection)
+	assert.Equal(t, automatic, *connection.Name)
+
+	privateEndpoint.PrivateLinkServiceConnections = nil
+	connection = getPrivateLinkServiceConnection(privateEndpoint)
+	require.NotNil(t, connection)
+	assert.Equal(t, manual, *connection.Name)
+}
+
+func TestGetPublicNetworkAccess(t *testing.T) {
+	t.Parallel()
+
+	access, ok := getPublicNetworkAccess(map[string]interface{}{"publicNetworkAccess": "Disabled"})
+	assert.True(t, ok)
+	assert.Equal(t, "Disabled", access)
+
+	_, ok = getPublicNetworkAccess(map[string]interface{}{})
+	assert.False(t, ok)
+}
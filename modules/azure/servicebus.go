@@ -41,6 +41,28 @@ func serviceBusSubscriptionsClientE(subscriptionID string) (*servicebus.Subscrip
 	return &sClient, nil
 }
 
+func serviceBusQueuesClientE(subscriptionID string) (*servicebus.QueuesClient, error) {
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	qClient := servicebus.NewQueuesClient(subscriptionID)
+	qClient.Authorizer = *authorizer
+	return &qClient, nil
+}
+
+func serviceBusRulesClientE(subscriptionID string) (*servicebus.RulesClient, error) {
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	rClient := servicebus.NewRulesClient(subscriptionID)
+	rClient.Authorizer = *authorizer
+	return &rClient, nil
+}
+
 // ListServiceBusNamespaceE list all SB namespaces in all resource groups in the given subscription ID.
 func ListServiceBusNamespaceE(subscriptionID string) ([]servicebus.SBNamespace, error) {
 	nsClient, err := serviceBusNamespaceClientE(subscriptionID)
@@ -368,3 +390,126 @@ func ListTopicAuthRules(t *testing.T, subscriptionID string, namespace string, r
 
 	return results
 }
+
+// GetQueueDeadLetterMessageCountE - authenticate queue client and get the number of messages in the dead-letter queue of the given queue.
+func GetQueueDeadLetterMessageCountE(subscriptionID string, namespace string, resourceGroup string, queueName string) (int64, error) {
+	qClient, err := serviceBusQueuesClientE(subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+
+	queue, err := qClient.Get(context.Background(), resourceGroup, namespace, queueName)
+	if err != nil {
+		return 0, err
+	}
+
+	if queue.SBQueueProperties == nil || queue.CountDetails == nil || queue.CountDetails.DeadLetterMessageCount == nil {
+		return 0, nil
+	}
+	return *queue.CountDetails.DeadLetterMessageCount, nil
+}
+
+// GetQueueDeadLetterMessageCount - authenticate queue client and get the number of messages in the dead-letter queue of the given queue.
+// This function would fail the test if there is an error.
+func GetQueueDeadLetterMessageCount(t *testing.T, subscriptionID string, namespace string, resourceGroup string, queueName string) int64 {
+	count, err := GetQueueDeadLetterMessageCountE(subscriptionID, namespace, resourceGroup, queueName)
+	require.NoError(t, err)
+
+	return count
+}
+
+// GetTopicSubscriptionDeadLetterMessageCountE - authenticate subscriptions client and get the number of messages in the dead-letter queue
+// of the given topic subscription.
+func GetTopicSubscriptionDeadLetterMessageCountE(subscriptionID string, namespace string, resourceGroup string, topicName string, subscriptionName string) (int64, error) {
+	sClient, err := serviceBusSubscriptionsClientE(subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+
+	subscription, err := sClient.Get(context.Background(), resourceGroup, namespace, topicName, subscriptionName)
+	if err != nil {
+		return 0, err
+	}
+
+	if subscription.SBSubscriptionProperties == nil || subscription.CountDetails == nil || subscription.CountDetails.DeadLetterMessageCount == nil {
+		return 0, nil
+	}
+	return *subscription.CountDetails.DeadLetterMessageCount, nil
+}
+
+// GetTopicSubscriptionDeadLetterMessageCount - authenticate subscriptions client and get the number of messages in the dead-letter queue
+// of the given topic subscription. This function would fail the test if there is an error.
+func GetTopicSubscriptionDeadLetterMessageCount(t *testing.T, subscriptionID string, namespace string, resourceGroup string, topicName string, subscriptionName string) int64 {
+	count, err := GetTopicSubscriptionDeadLetterMessageCountE(subscriptionID, namespace, resourceGroup, topicName, subscriptionName)
+	require.NoError(t, err)
+
+	return count
+}
+
+// ListTopicSubscriptionRulesE - authenticate rules client and enumerates all rules of the given topic subscription, automatically crossing
+// page boundaries as required.
+func ListTopicSubscriptionRulesE(subscriptionID string, namespace string, resourceGroup string, topicName string, subscriptionName string) ([]servicebus.Rule, error) {
+	rClient, err := serviceBusRulesClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	iteratorRules, err := rClient.ListBySubscriptionsComplete(context.Background(), resourceGroup, namespace, topicName, subscriptionName, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]servicebus.Rule, 0)
+	for iteratorRules.NotDone() {
+		results = append(results, iteratorRules.Value())
+		if err := iteratorRules.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// ListTopicSubscriptionRules - authenticate rules client and enumerates all rules of the given topic subscription, automatically crossing
+// page boundaries as required. This function would fail the test if there is an error.
+func ListTopicSubscriptionRules(t *testing.T, subscriptionID string, namespace string, resourceGroup string, topicName string, subscriptionName string) []servicebus.Rule {
+	results, err := ListTopicSubscriptionRulesE(subscriptionID, namespace, resourceGroup, topicName, subscriptionName)
+	require.NoError(t, err)
+
+	return results
+}
+
+// GetTopicSubscriptionRuleSQLFilterE - get the SQL filter expression of the given rule of the given topic subscription.
+// Returns an error if the rule does not exist or is not a SQL filter rule.
+func GetTopicSubscriptionRuleSQLFilterE(subscriptionID string, namespace string, resourceGroup string, topicName string, subscriptionName string, ruleName string) (string, error) {
+	rules, err := ListTopicSubscriptionRulesE(subscriptionID, namespace, resourceGroup, topicName, subscriptionName)
+	if err != nil {
+		return "", err
+	}
+
+	return getRuleSQLFilter(rules, ruleName)
+}
+
+// GetTopicSubscriptionRuleSQLFilter - get the SQL filter expression of the given rule of the given topic subscription.
+// This function would fail the test if there is an error.
+func GetTopicSubscriptionRuleSQLFilter(t *testing.T, subscriptionID string, namespace string, resourceGroup string, topicName string, subscriptionName string, ruleName string) string {
+	filter, err := GetTopicSubscriptionRuleSQLFilterE(subscriptionID, namespace, resourceGroup, topicName, subscriptionName, ruleName)
+	require.NoError(t, err)
+
+	return filter
+}
+
+// getRuleSQLFilter returns the SQL filter expression of the rule with the given name.
+func getRuleSQLFilter(rules []servicebus.Rule, ruleName string) (string, error) {
+	for _, rule := range rules {
+		if rule.Name == nil || *rule.Name != ruleName {
+			continue
+		}
+
+		if rule.Ruleproperties == nil || rule.SQLFilter == nil || rule.SQLFilter.SQLExpression == nil {
+			return "", NewNotFoundError("SQL filter", ruleName, "Service Bus subscription rules")
+		}
+		return *rule.SQLFilter.SQLExpression, nil
+	}
+
+	return "", NewNotFoundError("Service Bus subscription rule", ruleName, "Service Bus subscription rules")
+}
@@ -7,6 +7,7 @@ package azure
 import (
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/servicebus/mgmt/2017-04-01/servicebus"
 	"github.com/stretchr/testify/require"
 )
 
@@ -77,3 +78,38 @@ func TestListTopicSubscriptionsNameE(t *testing.T) {
 	_, err := ListTopicSubscriptionsNameE(subscriptionID, namespace, resourceGroup, topicName)
 	require.Error(t, err)
 }
+
+func TestListTopicSubscriptionRulesE(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := ""
+	namespace := ""
+	resourceGroup := ""
+	topicName := ""
+	subscriptionName := ""
+
+	_, err := ListTopicSubscriptionRulesE(subscriptionID, namespace, resourceGroup, topicName, subscriptionName)
+	require.Error(t, err)
+}
+
+func TestGetRuleSQLFilter(t *testing.T) {
+	t.Parallel()
+
+	ruleName := "high-priority"
+	expression := "priority > 5"
+	rules := []servicebus.Rule{
+		{
+			Name: &ruleName,
+			Ruleproperties: &servicebus.Ruleproperties{
+				SQLFilter: &servicebus.SQLFilter{SQLExpression: &expression},
+			},
+		},
+	}
+
+	filter, err := getRuleSQLFilter(rules, ruleName)
+	require.NoError(t, err)
+	require.Equal(t, expression, filter)
+
+	_, err = getRuleSQLFilter(rules, "missing")
+	require.Error(t, err)
+}
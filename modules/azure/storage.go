@@ -245,3 +245,32 @@ func GetStorageDNSStringE(storageAccountName, resourceGroupName, subscriptionID
 
 	return "", NewNotFoundError("storage account", storageAccountName, "")
 }
+
+// GetStorageAccountKey gets the first access key of the specified storage account, which can be used to access its data
+// plane with shared key authentication. This function would fail the test if there is an error.
+func GetStorageAccountKey(t *testing.T, storageAccountName, resourceGroupName, subscriptionID string) string {
+	key, err := GetStorageAccountKeyE(storageAccountName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return key
+}
+
+// GetStorageAccountKeyE gets the first access key of the specified storage account, which can be used to access its
+// data plane with shared key authentication.
+func GetStorageAccountKeyE(storageAccountName, resourceGroupName, subscriptionID string) (string, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return "", err
+	}
+	client, err := CreateStorageAccountClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+	keys, err := client.ListKeys(context.Background(), resourceGroupName, storageAccountName, "")
+	if err != nil {
+		return "", err
+	}
+	if keys.Keys == nil || len(*keys.Keys) == 0 || (*keys.Keys)[0].Value == nil {
+		return "", NewNotFoundError("storage account key", storageAccountName, resourceGroupName)
+	}
+	return *(*keys.Keys)[0].Value, nil
+}
@@ -0,0 +1,64 @@
+package azure
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/gruntwork-io/terratest/modules/ratelimit"
+)
+
+// ratelimitServicePrefix is the prefix of the ratelimit services of Azure APIs, which are named after the resource
+// provider of ARM calls (e.g. "azure/Microsoft.Network") and after the host of data plane calls (e.g.
+// "azure/myvault.vault.azure.net").
+const ratelimitServicePrefix = "azure/"
+
+// throttledAuthorizer is an autorest.Authorizer that waits for the ratelimit limit of the service of each request
+// before authorizing it. Retrying throttled requests is left to autorest, which already retries requests that get a
+// 429 Too Many Requests response, honoring their Retry-After header.
+type throttledAuthorizer struct {
+	autorest.Authorizer
+}
+
+// throttleAuthorizer returns an authorizer that waits for the ratelimit limit of the service of each request before
+// authorizing it with the given authorizer.
+func throttleAuthorizer(authorizer autorest.Authorizer) *autorest.Authorizer {
+	if authorizer == nil {
+		return &authorizer
+	}
+
+	var throttled autorest.Authorizer = throttledAuthorizer{Authorizer: authorizer}
+	return &throttled
+}
+
+// WithAuthorization returns a PrepareDecorator that waits for the ratelimit limit of the service of the request, then
+// authorizes it.
+func (authorizer throttledAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	authorize := authorizer.Authorizer.WithAuthorization()
+
+	return func(p autorest.Preparer) autorest.Preparer {
+		return authorize(autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err != nil {
+				return r, err
+			}
+			return r, ratelimit.Wait(r.Context(), ratelimitServiceForURL(r.URL))
+		}))
+	}
+}
+
+// ratelimitServiceForURL returns the ratelimit service of a request to the given URL.
+func ratelimitServiceForURL(requestURL *url.URL) string {
+	segments := strings.Split(strings.Trim(requestURL.Path, "/"), "/")
+	for i := len(segments) - 2; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			return ratelimitServicePrefix + segments[i+1]
+		}
+	}
+
+	if strings.HasPrefix(strings.ToLower(requestURL.Path), "/subscriptions/") {
+		return ratelimitServicePrefix + "Microsoft.Resources"
+	}
+	return ratelimitServicePrefix + requestURL.Host
+}
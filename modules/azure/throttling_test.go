@@ -0,0 +1,34 @@
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatelimitServiceForURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://management.azure.com/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet?api-version=2019-09-01", "azure/Microsoft.Network"},
+		{"https://management.azure.com/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm/providers/Microsoft.Insights/diagnosticSettings", "azure/Microsoft.Insights"},
+		{"https://management.azure.com/subscriptions/sub/resourcegroups/rg", "azure/Microsoft.Resources"},
+		{"https://myvault.vault.azure.net/secrets/secret", "azure/myvault.vault.azure.net"},
+	}
+
+	for _, testCase := range testCases {
+		requestURL, err := url.Parse(testCase.url)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, ratelimitServiceForURL(requestURL))
+	}
+}
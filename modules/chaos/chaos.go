@@ -0,0 +1,22 @@
+// Package chaos contains helpers that inject failures into the system under test, so that its resilience and the
+// disaster recovery runbooks that go with it can be verified by automated tests. Every injected failure records what
+// it changed and can be restored, which is done automatically when the test ends. On AWS, failures can also be injected
+// natively with AWS Fault Injection Simulator experiments (see RunFisExperiment).
+package chaos
+
+import (
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// registerRestore registers the given restore function to run when the test ends, if the given TestingT supports
+// cleanup functions (e.g. *testing.T on Go 1.14+). Otherwise, it logs a warning, as the caller then has to defer the
+// restore itself.
+func registerRestore(t testing.TestingT, description string, restore func()) {
+	cleanupT, ok := t.(interface{ Cleanup(func()) })
+	if !ok {
+		logger.Logf(t, "WARNING: %T does not support Cleanup, make sure to defer the restore of %s", t, description)
+		return
+	}
+	cleanupT.Cleanup(restore)
+}
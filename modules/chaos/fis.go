@@ -0,0 +1,128 @@
+package chaos
+
+import (
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/fis"
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	defaultFisMaxRetries          = 90
+	defaultFisSleepBetweenRetries = 10 * time.Second
+)
+
+// FisExperimentOptions configures an AWS Fault Injection Simulator experiment run by RunFisExperiment.
+type FisExperimentOptions struct {
+	Region string
+	// The ID of the experiment template to run, e.g. one defined by the module under test for a spot interruption or the
+	// power loss of an Availability Zone.
+	TemplateId string
+	// How often to check whether the experiment has completed. Defaults to every 10 seconds, for up to 15 minutes.
+	MaxRetries          int
+	SleepBetweenRetries time.Duration
+}
+
+// SpotInterruptionOptions configures the spot instance interruption simulated by SimulateSpotInterruption.
+type SpotInterruptionOptions struct {
+	Region string
+	// The IAM role FIS runs the experiment as, which needs the ec2:SendSpotInstanceInterruptions permission.
+	RoleArn     string
+	InstanceIds []string
+	// How long before the interruption the instances get the interruption notice. FIS requires at least 2 minutes.
+	DurationBeforeInterruption time.Duration
+	// How often to check whether the experiment has completed. Defaults to every 10 seconds, for up to 15 minutes.
+	MaxRetries          int
+	SleepBetweenRetries time.Duration
+}
+
+// RunFisExperiment runs the configured AWS Fault Injection Simulator experiment and waits for it to complete, so the test
+// can then assert that the system under test recovered. If the test ends while the experiment is still running, the
+// experiment is stopped. This will fail the test if the experiment can't be run or doesn't complete.
+func RunFisExperiment(t testing.TestingT, options FisExperimentOptions) *fis.Experiment {
+	experiment, err := RunFisExperimentE(t, options)
+	require.NoError(t, err)
+	return experiment
+}
+
+// RunFisExperimentE runs the configured AWS Fault Injection Simulator experiment and waits for it to complete, so the
+// test can then assert that the system under test recovered. If the test ends while the experiment is still running,
+// the experiment is stopped. Returns an aws.FisExperimentFailed error if the experiment fails or is stopped.
+func RunFisExperimentE(t testing.TestingT, options FisExperimentOptions) (*fis.Experiment, error) {
+	experiment, err := aws.StartFisExperimentE(t, options.Region, options.TemplateId)
+	if err != nil {
+		return nil, err
+	}
+
+	experimentId := awssdk.StringValue(experiment.Id)
+	registerRestore(t, "FIS experiment "+experimentId, func() {
+		stopFisExperimentIfRunning(t, options.Region, experimentId)
+	})
+
+	maxRetries, sleepBetweenRetries := fisRetrySettings(options.MaxRetries, options.SleepBetweenRetries)
+	return aws.WaitForFisExperimentCompleteE(t, options.Region, experimentId, maxRetries, sleepBetweenRetries)
+}
+
+// SimulateSpotInterruption interrupts the configured spot instances with an AWS Fault Injection Simulator experiment,
+// sending them the interruption notice first as EC2 does, and waits for the interruption to complete. The experiment
+// template is created for the occasion and deleted afterwards. This will fail the test if there is an error.
+func SimulateSpotInterruption(t testing.TestingT, options SpotInterruptionOptions) *fis.Experiment {
+	experiment, err := SimulateSpotInterruptionE(t, options)
+	require.NoError(t, err)
+	return experiment
+}
+
+// SimulateSpotInterruptionE interrupts the configured spot instances with an AWS Fault Injection Simulator experiment,
+// sending them the interruption notice first as EC2 does, and waits for the interruption to complete. The experiment
+// template is created for the occasion and deleted afterwards.
+func SimulateSpotInterruptionE(t testing.TestingT, options SpotInterruptionOptions) (*fis.Experiment, error) {
+	templateId, err := aws.CreateFisSpotInterruptionTemplateE(t, options.Region, options.RoleArn, options.InstanceIds, options.DurationBeforeInterruption)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := aws.DeleteFisExperimentTemplateE(t, options.Region, templateId); err != nil {
+			logger.Logf(t, "Failed to delete FIS experiment template %s: %s", templateId, err)
+		}
+	}()
+
+	return RunFisExperimentE(t, FisExperimentOptions{
+		Region:              options.Region,
+		TemplateId:          templateId,
+		MaxRetries:          options.MaxRetries,
+		SleepBetweenRetries: options.SleepBetweenRetries,
+	})
+}
+
+// stopFisExperimentIfRunning stops the given experiment unless it has already finished.
+func stopFisExperimentIfRunning(t testing.TestingT, region string, experimentId string) {
+	experiment, err := aws.GetFisExperimentE(t, region, experimentId)
+	if err != nil {
+		logger.Logf(t, "Failed to get FIS experiment %s: %s", experimentId, err)
+		return
+	}
+
+	switch awssdk.StringValue(experiment.State.Status) {
+	case fis.ExperimentStatusCompleted, fis.ExperimentStatusStopped, fis.ExperimentStatusStopping, fis.ExperimentStatusFailed:
+		return
+	}
+
+	if err := aws.StopFisExperimentE(t, region, experimentId); err != nil {
+		logger.Logf(t, "Failed to stop FIS experiment %s: %s", experimentId, err)
+	}
+}
+
+// fisRetrySettings returns the given retry settings, or the defaults for those that are not set.
+func fisRetrySettings(maxRetries int, sleepBetweenRetries time.Duration) (int, time.Duration) {
+	if maxRetries <= 0 {
+		maxRetries = defaultFisMaxRetries
+	}
+	if sleepBetweenRetries <= 0 {
+		sleepBetweenRetries = defaultFisSleepBetweenRetries
+	}
+	return maxRetries, sleepBetweenRetries
+}
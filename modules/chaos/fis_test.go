@@ -0,0 +1,20 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFisRetrySettings(t *testing.T) {
+	t.Parallel()
+
+	maxRetries, sleepBetweenRetries := fisRetrySettings(0, 0)
+	assert.Equal(t, defaultFisMaxRetries, maxRetries)
+	assert.Equal(t, defaultFisSleepBetweenRetries, sleepBetweenRetries)
+
+	maxRetries, sleepBetweenRetries = fisRetrySettings(5, time.Second)
+	assert.Equal(t, 5, maxRetries)
+	assert.Equal(t, time.Second, sleepBetweenRetries)
+}
@@ -0,0 +1,185 @@
+package chaos
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+// ZoneFailureOptions configures which parts of the system under test lose an Availability Zone. Every part is optional.
+type ZoneFailureOptions struct {
+	Region string
+	// The Availability Zone to fail, e.g. "us-east-1a". Leave empty to simulate the loss of the whole region.
+	AvailabilityZone string
+	// If set, the subnets of this VPC in the zone are cut off from all network traffic with a deny-all network ACL.
+	VpcId string
+	// The load balancer target groups to deregister the targets in the zone from.
+	TargetGroupArns []string
+	// If set, all the nodes of this Kubernetes cluster in the zone are cordoned.
+	KubectlOptions *k8s.KubectlOptions
+}
+
+// ZoneFailure is a simulated loss of an Availability Zone (or region), recording everything that was changed so it can
+// be restored.
+type ZoneFailure struct {
+	Options             ZoneFailureOptions
+	SubnetIsolation     *aws.SubnetIsolation                  // The network ACL changes, if a VPC was given
+	DeregisteredTargets map[string][]*elbv2.TargetDescription // Map of target group ARN to the targets deregistered from it
+	CordonedNodes       []string                              // The names of the Kubernetes nodes that were cordoned
+
+	restoreOnce sync.Once
+	restoreErr  error
+}
+
+// SimulateZoneFailure simulates the loss of the configured Availability Zone for the system under test, and returns the
+// failure so it can be restored. The failure is restored automatically when the test ends, but it can also be restored
+// earlier with Restore to verify the recovery. This will fail the test if the failure can't be injected.
+func SimulateZoneFailure(t testing.TestingT, options ZoneFailureOptions) *ZoneFailure {
+	failure, err := SimulateZoneFailureE(t, options)
+	require.NoError(t, err)
+	return failure
+}
+
+// SimulateZoneFailureE simulates the loss of the configured Availability Zone for the system under test, and returns the
+// failure so it can be restored. The failure is restored automatically when the test ends, but it can also be restored
+// earlier with RestoreE to verify the recovery. If the failure can only be partly injected, what was injected is
+// restored before returning the error.
+func SimulateZoneFailureE(t testing.TestingT, options ZoneFailureOptions) (*ZoneFailure, error) {
+	failure := &ZoneFailure{
+		Options:             options,
+		DeregisteredTargets: map[string][]*elbv2.TargetDescription{},
+		CordonedNodes:       []string{},
+	}
+
+	logger.Logf(t, "Simulating the loss of %s", failure.description())
+
+	if err := failure.inject(t); err != nil {
+		if restoreErr := failure.RestoreE(t); restoreErr != nil {
+			logger.Logf(t, "Failed to restore %s after a failed injection: %s", failure.description(), restoreErr)
+		}
+		return nil, err
+	}
+
+	registerRestore(t, failure.description(), func() {
+		if err := failure.RestoreE(t); err != nil {
+			logger.Logf(t, "Failed to restore %s: %s", failure.description(), err)
+		}
+	})
+
+	return failure, nil
+}
+
+// Restore undoes all the changes made to simulate the zone failure. It is safe to call more than once. This will fail
+// the test if anything can't be restored.
+func (failure *ZoneFailure) Restore(t testing.TestingT) {
+	require.NoError(t, failure.RestoreE(t))
+}
+
+// RestoreE undoes all the changes made to simulate the zone failure, in the reverse order they were made. Every change
+// is attempted even if restoring an earlier one fails. It is safe to call more than once; only the first call restores.
+func (failure *ZoneFailure) RestoreE(t testing.TestingT) error {
+	failure.restoreOnce.Do(func() {
+		logger.Logf(t, "Restoring %s", failure.description())
+
+		var errorsOccurred = new(multierror.Error)
+
+		for _, nodeName := range failure.CordonedNodes {
+			if err := k8s.UncordonNodeE(t, failure.Options.KubectlOptions, nodeName); err != nil {
+				errorsOccurred = multierror.Append(errorsOccurred, err)
+			}
+		}
+
+		for targetGroupArn, targets := range failure.DeregisteredTargets {
+			if err := aws.RegisterTargetsE(t, failure.Options.Region, targetGroupArn, targets); err != nil {
+				errorsOccurred = multierror.Append(errorsOccurred, err)
+			}
+		}
+
+		if failure.SubnetIsolation != nil {
+			if err := aws.RestoreIsolatedSubnetsE(t, failure.SubnetIsolation); err != nil {
+				errorsOccurred = multierror.Append(errorsOccurred, err)
+			}
+		}
+
+		failure.restoreErr = errorsOccurred.ErrorOrNil()
+	})
+
+	return failure.restoreErr
+}
+
+// inject makes the changes that simulate the zone failure, recording each one as soon as it is made so that a partial
+// injection can be restored.
+func (failure *ZoneFailure) inject(t testing.TestingT) error {
+	options := failure.Options
+
+	if options.VpcId != "" {
+		subnets, err := aws.GetSubnetsForVpcE(t, options.VpcId, options.Region)
+		if err != nil {
+			return err
+		}
+
+		subnetIDs := []string{}
+		for _, subnet := range subnets {
+			if failure.inZone(subnet.AvailabilityZone) {
+				subnetIDs = append(subnetIDs, subnet.Id)
+			}
+		}
+
+		if len(subnetIDs) > 0 {
+			isolation, err := aws.IsolateSubnetsE(t, options.Region, options.VpcId, subnetIDs)
+			if err != nil {
+				return err
+			}
+			failure.SubnetIsolation = isolation
+		}
+	}
+
+	for _, targetGroupArn := range options.TargetGroupArns {
+		targets, err := aws.DeregisterTargetsInAvailabilityZoneE(t, options.Region, targetGroupArn, options.AvailabilityZone)
+		if err != nil {
+			return err
+		}
+		failure.DeregisteredTargets[targetGroupArn] = targets
+	}
+
+	if options.KubectlOptions != nil {
+		nodes, err := k8s.GetNodesE(t, options.KubectlOptions)
+		if err != nil {
+			return err
+		}
+
+		for _, node := range nodes {
+			// Leave nodes that were already cordoned alone, so restoring doesn't make them schedulable
+			if !failure.inZone(k8s.GetNodeZone(node)) || node.Spec.Unschedulable {
+				continue
+			}
+
+			if err := k8s.CordonNodeE(t, options.KubectlOptions, node.Name); err != nil {
+				return err
+			}
+			failure.CordonedNodes = append(failure.CordonedNodes, node.Name)
+		}
+	}
+
+	return nil
+}
+
+// inZone indicates whether the given zone is affected by the failure, which is always the case for a region failure.
+func (failure *ZoneFailure) inZone(zone string) bool {
+	return failure.Options.AvailabilityZone == "" || strings.EqualFold(zone, failure.Options.AvailabilityZone)
+}
+
+// description returns a human readable description of what the failure simulates.
+func (failure *ZoneFailure) description() string {
+	if failure.Options.AvailabilityZone == "" {
+		return "region " + failure.Options.Region
+	}
+	return "Availability Zone " + failure.Options.AvailabilityZone
+}
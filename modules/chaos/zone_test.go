@@ -0,0 +1,43 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoneFailureInZone(t *testing.T) {
+	t.Parallel()
+
+	zoneFailure := &ZoneFailure{Options: ZoneFailureOptions{Region: "us-east-1", AvailabilityZone: "us-east-1a"}}
+	assert.True(t, zoneFailure.inZone("us-east-1a"))
+	assert.False(t, zoneFailure.inZone("us-east-1b"))
+	assert.Equal(t, "Availability Zone us-east-1a", zoneFailure.description())
+
+	regionFailure := &ZoneFailure{Options: ZoneFailureOptions{Region: "us-east-1"}}
+	assert.True(t, regionFailure.inZone("us-east-1a"))
+	assert.True(t, regionFailure.inZone("us-east-1b"))
+	assert.Equal(t, "region us-east-1", regionFailure.description())
+}
+
+func TestZoneFailureRestoresOnlyOnce(t *testing.T) {
+	t.Parallel()
+
+	failure := &ZoneFailure{Options: ZoneFailureOptions{Region: "us-east-1", AvailabilityZone: "us-east-1a"}}
+	assert.NoError(t, failure.RestoreE(t))
+
+	// Later calls return the result of the first restore without redoing anything
+	failure.restoreErr = errors.New("should not be overwritten")
+	assert.EqualError(t, failure.RestoreE(t), "should not be overwritten")
+}
+
+func TestSimulateZoneFailureWithNothingToFail(t *testing.T) {
+	t.Parallel()
+
+	failure, err := SimulateZoneFailureE(t, ZoneFailureOptions{Region: "us-east-1", AvailabilityZone: "us-east-1a"})
+	assert.NoError(t, err)
+	assert.Nil(t, failure.SubnetIsolation)
+	assert.Empty(t, failure.DeregisteredTargets)
+	assert.Empty(t, failure.CordonedNodes)
+}
@@ -0,0 +1,103 @@
+// Package clock contains the clock that the retry, wait and logger packages, and the waits of the other modules built
+// on them, tell the time and sleep with. It can be replaced, so that the behavior of test helpers, such as their
+// backoff schedules and timeouts, can be unit tested without sleeping, or sped up in CI smoke tests:
+//
+//	restore := clock.SetDefault(clock.NewScaled(60))
+//	defer restore()
+//
+// The default clock is global, so only replace it in tests that don't run in parallel with tests that need the real
+// one.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time, and when a duration has passed.
+type Clock interface {
+	Now() time.Time
+	After(duration time.Duration) <-chan time.Time
+	Sleep(duration time.Duration)
+}
+
+// Real is the clock of the machine the tests run on.
+var Real Clock = realClock{}
+
+var (
+	mutex        sync.RWMutex
+	defaultClock = Real
+)
+
+// Default returns the clock to use when none is given. This is Real, unless it was replaced with SetDefault.
+func Default() Clock {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return defaultClock
+}
+
+// SetDefault replaces the clock returned by Default, and returns a function that restores the previous one.
+func SetDefault(clock Clock) func() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	previous := defaultClock
+	defaultClock = clock
+	return func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		defaultClock = previous
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+func (realClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// Scaled is a clock that runs faster than the real one by a factor, e.g. to run the waits of test helpers in CI smoke
+// tests in seconds rather than minutes, while keeping their schedules intact.
+type Scaled struct {
+	factor float64
+	start  time.Time
+}
+
+// NewScaled returns a clock that starts at the current time and runs the given factor faster than the real one. With a
+// factor of 60, a minute passes every second.
+func NewScaled(factor float64) *Scaled {
+	return &Scaled{factor: factor, start: time.Now()}
+}
+
+// Now returns the current time of the clock.
+func (clock *Scaled) Now() time.Time {
+	return clock.start.Add(clock.scale(time.Since(clock.start)))
+}
+
+// After returns a channel that receives the time of the clock once the given duration has passed on it.
+func (clock *Scaled) After(duration time.Duration) <-chan time.Time {
+	channel := make(chan time.Time, 1)
+	time.AfterFunc(clock.unscale(duration), func() { channel <- clock.Now() })
+	return channel
+}
+
+// Sleep blocks until the given duration has passed on the clock.
+func (clock *Scaled) Sleep(duration time.Duration) {
+	time.Sleep(clock.unscale(duration))
+}
+
+func (clock *Scaled) scale(duration time.Duration) time.Duration {
+	return time.Duration(float64(duration) * clock.factor)
+}
+
+func (clock *Scaled) unscale(duration time.Duration) time.Duration {
+	return time.Duration(float64(duration) / clock.factor)
+}
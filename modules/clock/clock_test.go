@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeFiresWhenAdvanced(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFake(start)
+	short := clock.After(time.Second)
+	long := clock.After(time.Minute)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(30*time.Second), <-short)
+	assert.Len(t, long, 0)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-long)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+}
+
+func TestFakeSleepBlocksUntilAdvanced(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFake(time.Now())
+	slept := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Hour)
+		close(slept)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-slept
+}
+
+func TestScaledRunsFaster(t *testing.T) {
+	t.Parallel()
+
+	clock := NewScaled(3600)
+	start := clock.Now()
+	realStart := time.Now()
+
+	clock.Sleep(time.Minute)
+	<-clock.After(time.Minute)
+
+	assert.True(t, clock.Now().Sub(start) >= 2*time.Minute, "the scaled clock did not advance by the durations waited for")
+	assert.True(t, time.Since(realStart) < time.Second, "the scaled clock waited for the real durations")
+}
+
+func TestSetDefaultRestores(t *testing.T) {
+	fake := NewFake(time.Now())
+	restore := SetDefault(fake)
+	assert.Equal(t, fake, Default())
+
+	restore()
+	assert.Equal(t, Real, Default())
+}
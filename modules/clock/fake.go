@@ -0,0 +1,84 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a clock that only moves when it is advanced, for unit testing code that waits without sleeping. Run the
+// code that waits in a goroutine, call BlockUntil to wait until it is waiting on the clock, and then call Advance.
+type Fake struct {
+	mutex   sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	until   time.Time
+	channel chan time.Time
+}
+
+// NewFake returns a fake clock that starts at the given time.
+func NewFake(now time.Time) *Fake {
+	clock := &Fake{now: now}
+	clock.changed = sync.NewCond(&clock.mutex)
+	return clock
+}
+
+// Now returns the current time of the clock.
+func (clock *Fake) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+// After returns a channel that receives the time of the clock once it has been advanced by the given duration.
+func (clock *Fake) After(duration time.Duration) <-chan time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	// Buffer the channel, so that the clock does not block on waiters that stopped waiting
+	channel := make(chan time.Time, 1)
+	if duration <= 0 {
+		channel <- clock.now
+		return channel
+	}
+
+	clock.waiters = append(clock.waiters, fakeWaiter{until: clock.now.Add(duration), channel: channel})
+	clock.changed.Broadcast()
+	return channel
+}
+
+// Advance moves the clock forward by the given duration, and fires the channels returned by After that are due.
+func (clock *Fake) Advance(duration time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.now = clock.now.Add(duration)
+	pending := []fakeWaiter{}
+	for _, waiter := range clock.waiters {
+		if waiter.until.After(clock.now) {
+			pending = append(pending, waiter)
+			continue
+		}
+		waiter.channel <- clock.now
+	}
+	clock.waiters = pending
+	clock.changed.Broadcast()
+}
+
+// BlockUntil blocks until the given number of calls to After or Sleep are waiting for the clock to be advanced.
+func (clock *Fake) BlockUntil(waiters int) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	for len(clock.waiters) < waiters {
+		clock.changed.Wait()
+	}
+}
+
+// Sleep blocks until the clock has been advanced by the given duration.
+func (clock *Fake) Sleep(duration time.Duration) {
+	<-clock.After(duration)
+}
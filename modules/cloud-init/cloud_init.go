@@ -0,0 +1,231 @@
+// Package cloud_init allows to validate cloud-init user data, e.g. rendered by Terraform, and its result on instances.
+package cloud_init
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// The header that identifies cloud-config user data.
+const cloudConfigHeader = "#cloud-config"
+
+// The MIME type of cloud-config parts in multipart user data.
+const cloudConfigMediaType = "text/cloud-config"
+
+// ValidateUserData validates the cloud-config in the given user data against the cloud-init schema, using the local
+// 'cloud-init schema' command. The user data can be plain cloud-config or multipart MIME, as rendered by the
+// cloudinit_config data source of Terraform, and may be base64 encoded and gzipped, so the value of a Terraform output
+// can be passed as-is. This will fail the test if the user data is invalid.
+func ValidateUserData(t testing.TestingT, userData string) {
+	require.NoError(t, ValidateUserDataE(t, userData))
+}
+
+// ValidateUserDataE validates the cloud-config in the given user data against the cloud-init schema, using the local
+// 'cloud-init schema' command. The user data can be plain cloud-config or multipart MIME, as rendered by the
+// cloudinit_config data source of Terraform, and may be base64 encoded and gzipped, so the value of a Terraform output
+// can be passed as-is.
+func ValidateUserDataE(t testing.TestingT, userData string) error {
+	configs, err := GetCloudConfigsE(userData)
+	if err != nil {
+		return err
+	}
+
+	if len(configs) == 0 {
+		return errors.New("The user data does not contain any cloud-config")
+	}
+
+	for _, config := range configs {
+		if err := validateCloudConfigE(t, config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetCloudConfigs returns the cloud-config documents in the given user data, decoding it first if it is base64
+// encoded or gzipped. For multipart MIME user data, the text/cloud-config parts are returned. This will fail the test
+// if the user data cannot be decoded.
+func GetCloudConfigs(t testing.TestingT, userData string) []string {
+	configs, err := GetCloudConfigsE(userData)
+	require.NoError(t, err)
+	return configs
+}
+
+// GetCloudConfigsE returns the cloud-config documents in the given user data, decoding it first if it is base64
+// encoded or gzipped. For multipart MIME user data, the text/cloud-config parts are returned.
+func GetCloudConfigsE(userData string) ([]string, error) {
+	decoded, err := decodeUserDataE(userData)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(decoded, cloudConfigHeader) {
+		return []string{decoded}, nil
+	}
+
+	if strings.HasPrefix(decoded, "Content-Type:") || strings.HasPrefix(decoded, "MIME-Version:") {
+		return getMultipartCloudConfigsE(decoded)
+	}
+
+	// Other formats, like shell scripts, are not cloud-config
+	return []string{}, nil
+}
+
+// ValidateUserDataOnHost runs 'cloud-init schema --system' over SSH on the given host, which validates the user data
+// the instance was actually launched with. This will fail the test if the user data is invalid.
+func ValidateUserDataOnHost(t testing.TestingT, host ssh.Host) string {
+	out, err := ValidateUserDataOnHostE(t, host)
+	require.NoError(t, err)
+	return out
+}
+
+// ValidateUserDataOnHostE runs 'cloud-init schema --system' over SSH on the given host, which validates the user data
+// the instance was actually launched with.
+func ValidateUserDataOnHostE(t testing.TestingT, host ssh.Host) (string, error) {
+	return ssh.CheckSshCommandE(t, host, "sudo cloud-init schema --system")
+}
+
+// WaitForCloudInitOnHost waits over SSH until cloud-init has finished on the given host, using
+// 'cloud-init status --wait'. This will fail the test if cloud-init finished with an error.
+func WaitForCloudInitOnHost(t testing.TestingT, host ssh.Host) string {
+	out, err := WaitForCloudInitOnHostE(t, host)
+	require.NoError(t, err)
+	return out
+}
+
+// WaitForCloudInitOnHostE waits over SSH until cloud-init has finished on the given host, using
+// 'cloud-init status --wait', and returns an error if cloud-init finished with an error.
+func WaitForCloudInitOnHostE(t testing.TestingT, host ssh.Host) (string, error) {
+	out, err := ssh.CheckSshCommandE(t, host, "cloud-init status --wait --long")
+	if err != nil {
+		return out, err
+	}
+
+	if !strings.Contains(out, "status: done") {
+		return out, fmt.Errorf("cloud-init did not finish successfully on %s:\n%s", host.Hostname, out)
+	}
+
+	return out, nil
+}
+
+// validateCloudConfigE writes the given cloud-config to a temporary file and validates it with 'cloud-init schema'.
+func validateCloudConfigE(t testing.TestingT, config string) error {
+	file, err := ioutil.TempFile("", "terratest-cloud-config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(config); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	cmd := shell.Command{
+		Command: "cloud-init",
+		Args:    []string{"schema", "--config-file", file.Name()},
+	}
+
+	_, err = shell.RunCommandAndGetOutputE(t, cmd)
+	return err
+}
+
+// decodeUserDataE decodes the given user data if it is base64 encoded and/or gzipped, as the user_data_base64 and
+// gzip options of Terraform resources produce.
+func decodeUserDataE(userData string) (string, error) {
+	content := []byte(userData)
+
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(userData)); err == nil {
+		content = decoded
+	}
+
+	// gzip streams start with the magic bytes 0x1f 0x8b
+	if len(content) > 2 && content[0] == 0x1f && content[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		content, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return string(content), nil
+}
+
+// getMultipartCloudConfigsE returns the text/cloud-config parts of the given multipart MIME user data.
+func getMultipartCloudConfigsE(userData string) ([]string, error) {
+	message, err := mail.ReadMessage(strings.NewReader(userData))
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		if mediaType != cloudConfigMediaType {
+			return []string{}, nil
+		}
+		body, err := ioutil.ReadAll(message.Body)
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(body)}, nil
+	}
+
+	configs := []string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				return configs, nil
+			}
+			return nil, err
+		}
+
+		partType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil || partType != cloudConfigMediaType {
+			continue
+		}
+
+		body, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+			body, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		configs = append(configs, string(body))
+	}
+}
@@ -0,0 +1,78 @@
+package cloud_init
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleCloudConfig = `#cloud-config
+packages:
+  - nginx
+`
+
+const exampleMultipartUserData = `Content-Type: multipart/mixed; boundary="MIMEBOUNDARY"
+MIME-Version: 1.0
+
+--MIMEBOUNDARY
+Content-Transfer-Encoding: 7bit
+Content-Type: text/cloud-config
+Mime-Version: 1.0
+
+#cloud-config
+packages:
+  - nginx
+
+--MIMEBOUNDARY
+Content-Transfer-Encoding: 7bit
+Content-Type: text/x-shellscript
+Mime-Version: 1.0
+
+#!/bin/bash
+echo hello
+
+--MIMEBOUNDARY--
+`
+
+func TestGetCloudConfigsPlain(t *testing.T) {
+	t.Parallel()
+
+	configs, err := GetCloudConfigsE(exampleCloudConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{exampleCloudConfig}, configs)
+}
+
+func TestGetCloudConfigsBase64Gzip(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(exampleCloudConfig))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	configs, err := GetCloudConfigsE(base64.StdEncoding.EncodeToString(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{exampleCloudConfig}, configs)
+}
+
+func TestGetCloudConfigsMultipart(t *testing.T) {
+	t.Parallel()
+
+	configs, err := GetCloudConfigsE(exampleMultipartUserData)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Contains(t, configs[0], "#cloud-config\npackages:\n  - nginx")
+}
+
+func TestGetCloudConfigsShellScript(t *testing.T) {
+	t.Parallel()
+
+	configs, err := GetCloudConfigsE("#!/bin/bash\necho hello\n")
+	require.NoError(t, err)
+	assert.Empty(t, configs)
+}
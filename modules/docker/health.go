@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// WaitForContainerHealthy waits until the HEALTHCHECK of the given container reports it as healthy and returns the
+// final inspect result. The container must have a health check, either from its image or from the --health-cmd
+// option of 'docker run'. This method fails the test if the container is not healthy within the timeout.
+func WaitForContainerHealthy(t testing.TestingT, containerID string, timeout time.Duration) *ContainerInspect {
+	container, err := WaitForContainerHealthyE(t, containerID, timeout)
+	require.NoError(t, err)
+	return container
+}
+
+// WaitForContainerHealthyE waits until the HEALTHCHECK of the given container reports it as healthy and returns the
+// final inspect result. An error is returned immediately if the container has no health check or exits. On timeout,
+// the error includes the output of the last health check run.
+func WaitForContainerHealthyE(t testing.TestingT, containerID string, timeout time.Duration) (*ContainerInspect, error) {
+	maxRetries := int(timeout.Seconds() / containerPollInterval.Seconds())
+	description := fmt.Sprintf("Waiting for container %s to be healthy", containerID)
+
+	var container *ContainerInspect
+	_, err := retry.DoWithRetryE(t, description, maxRetries, containerPollInterval, func() (string, error) {
+		var err error
+		container, err = InspectE(t, containerID)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case container.Health.Status == "":
+			return "", retry.FatalError{Underlying: fmt.Errorf("container %s does not have a health check", containerID)}
+		case !container.Running:
+			return "", retry.FatalError{Underlying: fmt.Errorf("container %s exited with code %d before becoming healthy", containerID, container.ExitCode)}
+		case container.Health.Status == "healthy":
+			return "", nil
+		default:
+			return "", fmt.Errorf("container %s is %s%s", containerID, container.Health.Status, lastHealthCheckOutput(container.Health))
+		}
+	})
+
+	if fatalErr, isFatalErr := err.(retry.FatalError); isFatalErr {
+		return nil, fatalErr.Underlying
+	}
+	if err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+// lastHealthCheckOutput describes the result of the most recent run of the given health check, if any.
+func lastHealthCheckOutput(health HealthCheck) string {
+	if len(health.Log) == 0 {
+		return ""
+	}
+
+	last := health.Log[len(health.Log)-1]
+	return fmt.Sprintf(" (last check exited with code %d: %s)", last.ExitCode, strings.TrimSpace(last.Output))
+}
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"testing"
 	"time"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,9 +22,24 @@ type ContainerInspect struct {
 	// Name of the inspected container
 	Name string
 
+	// Image the container was created from, as given to 'docker run'
+	Image string
+
+	// Labels set on the container, including those inherited from its image
+	Labels map[string]string
+
+	// Environment variables of the container, in the form "KEY=value"
+	Env []string
+
 	// time.Time that the container was created
 	Created time.Time
 
+	// time.Time that the container was last started, or the zero time if it never started
+	StartedAt time.Time
+
+	// time.Time that the container last exited, or the zero time if it has not exited
+	FinishedAt time.Time
+
 	// String representing the container's status
 	Status string
 
@@ -43,6 +58,9 @@ type ContainerInspect struct {
 	// Volume bindings made to the container
 	Binds []VolumeBind
 
+	// Networks the container is connected to, keyed by network name
+	Networks map[string]ContainerNetwork
+
 	// Health check
 	Health HealthCheck
 }
@@ -60,6 +78,15 @@ type VolumeBind struct {
 	Destination string
 }
 
+// ContainerNetwork represents the connection of the container to a single network
+type ContainerNetwork struct {
+	// IP address of the container on the network
+	IPAddress string
+
+	// Network-scoped aliases of the container
+	Aliases []string
+}
+
 // HealthCheck represents the current health history of the container
 type HealthCheck struct {
 	// Health check status
@@ -94,17 +121,25 @@ type inspectOutput struct {
 	Created string
 	Name    string
 	State   struct {
-		Health   HealthCheck
-		Status   string
-		Running  bool
-		ExitCode uint8
-		Error    string
+		Health     HealthCheck
+		Status     string
+		Running    bool
+		ExitCode   uint8
+		Error      string
+		StartedAt  string
+		FinishedAt string
+	}
+	Config struct {
+		Image  string
+		Labels map[string]string
+		Env    []string
 	}
 	NetworkSettings struct {
 		Ports map[string][]struct {
 			HostIp   string
 			HostPort string
 		}
+		Networks map[string]ContainerNetwork
 	}
 	HostConfig struct {
 		Binds []string
@@ -113,7 +148,7 @@ type inspectOutput struct {
 
 // Inspect runs the 'docker inspect {container id}' command and returns a ContainerInspect
 // struct, converted from the output JSON, along with any errors
-func Inspect(t *testing.T, id string) *ContainerInspect {
+func Inspect(t testing.TestingT, id string) *ContainerInspect {
 	out, err := InspectE(t, id)
 	require.NoError(t, err)
 
@@ -122,7 +157,7 @@ func Inspect(t *testing.T, id string) *ContainerInspect {
 
 // InspectE runs the 'docker inspect {container id}' command and returns a ContainerInspect
 // struct, converted from the output JSON, along with any errors
-func InspectE(t *testing.T, id string) (*ContainerInspect, error) {
+func InspectE(t testing.TestingT, id string) (*ContainerInspect, error) {
 	cmd := shell.Command{
 		Command: "docker",
 		Args:    []string{"container", "inspect", id},
@@ -151,7 +186,7 @@ func InspectE(t *testing.T, id string) (*ContainerInspect, error) {
 }
 
 // transformContainerPorts converts 'docker inspect' output JSON into a more friendly and testable format
-func transformContainer(t *testing.T, container inspectOutput) (*ContainerInspect, error) {
+func transformContainer(t testing.TestingT, container inspectOutput) (*ContainerInspect, error) {
 	name := strings.TrimLeft(container.Name, "/")
 
 	ports, err := transformContainerPorts(container)
@@ -166,16 +201,32 @@ func transformContainer(t *testing.T, container inspectOutput) (*ContainerInspec
 		return nil, err
 	}
 
+	startedAt, err := parseContainerStateTime(container.State.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	finishedAt, err := parseContainerStateTime(container.State.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+
 	inspect := ContainerInspect{
-		ID:       container.Id,
-		Name:     name,
-		Created:  created,
-		Status:   container.State.Status,
-		Running:  container.State.Running,
-		ExitCode: container.State.ExitCode,
-		Error:    container.State.Error,
-		Ports:    ports,
-		Binds:    volumes,
+		ID:         container.Id,
+		Name:       name,
+		Image:      container.Config.Image,
+		Labels:     container.Config.Labels,
+		Env:        container.Config.Env,
+		Created:    created,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Status:     container.State.Status,
+		Running:    container.State.Running,
+		ExitCode:   container.State.ExitCode,
+		Error:      container.State.Error,
+		Ports:      ports,
+		Binds:      volumes,
+		Networks:   container.NetworkSettings.Networks,
 		Health: HealthCheck{
 			Status:        container.State.Health.Status,
 			FailingStreak: container.State.Health.FailingStreak,
@@ -186,6 +237,21 @@ func transformContainer(t *testing.T, container inspectOutput) (*ContainerInspec
 	return &inspect, nil
 }
 
+// parseContainerStateTime parses the StartedAt and FinishedAt times of 'docker inspect', which Docker sets to
+// "0001-01-01T00:00:00Z" until the corresponding event happens.
+func parseContainerStateTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parsed.UTC(), nil
+}
+
 // transformContainerPorts converts Docker's ports from the following json into a more testable format
 // {
 //   "80/tcp": [
@@ -140,6 +140,39 @@ func TestInspectReturnsCorrectHealthCheckWhenUnhealthy(t *testing.T) {
 	require.Equal(t, "/bin/sh: service nginx status: not found\n", c.Health.Log[0].Output)
 }
 
+func TestWaitForContainerHealthy(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{
+		Detach: true,
+		OtherOptions: []string{
+			"--health-cmd=wget -q -O /dev/null http://localhost",
+			"--health-interval=1s",
+		},
+	}
+
+	id := RunAndGetID(t, dockerInspectTestImage, options)
+	defer removeContainer(t, id)
+
+	c := WaitForContainerHealthy(t, id, 60*time.Second)
+	require.Equal(t, "healthy", c.Health.Status)
+	require.Equal(t, dockerInspectTestImage, c.Image)
+	require.False(t, c.StartedAt.IsZero())
+	require.True(t, c.FinishedAt.IsZero())
+	require.Contains(t, c.Networks, "bridge")
+}
+
+func TestWaitForContainerHealthyFailsWithoutHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	id := RunAndGetID(t, dockerInspectTestImage, &RunOptions{Detach: true})
+	defer removeContainer(t, id)
+
+	_, err := WaitForContainerHealthyE(t, id, 10*time.Second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not have a health check")
+}
+
 func runWithHealthCheck(t *testing.T, check string, frequency time.Duration, delay time.Duration) *ContainerInspect {
 	// append timestamp to container name to allow running tests in parallel
 	name := "inspect-test-" + random.UniqueId()
@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// How long to wait between checks in WaitForContainerLogMessage and WaitForContainerHealthy.
+const containerPollInterval = time.Second
+
+// GetContainerLogs runs the 'docker logs' command for the given container and returns its stdout and stderr
+// interleaved. This method fails the test if there are any errors.
+func GetContainerLogs(t testing.TestingT, containerID string) string {
+	out, err := GetContainerLogsE(t, containerID)
+	require.NoError(t, err)
+	return out
+}
+
+// GetContainerLogsE runs the 'docker logs' command for the given container and returns its stdout and stderr
+// interleaved, or any error.
+func GetContainerLogsE(t testing.TestingT, containerID string) (string, error) {
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"logs", containerID},
+		// the logs are returned to the caller and may be checked many times, don't print them.
+		Logger: logger.Discard,
+	}
+
+	return shell.RunCommandAndGetOutputE(t, cmd)
+}
+
+// WaitForContainerLogMessage waits until the logs of the given container contain a line matching the given regular
+// expression (e.g. "ready to accept connections") and returns the first matching line. This is meant to replace fixed
+// sleeps while a containerized fixture starts up. This method fails the test if no line matches within the timeout.
+func WaitForContainerLogMessage(t testing.TestingT, containerID string, regex string, timeout time.Duration) string {
+	line, err := WaitForContainerLogMessageE(t, containerID, regex, timeout)
+	require.NoError(t, err)
+	return line
+}
+
+// WaitForContainerLogMessageE waits until the logs of the given container contain a line matching the given regular
+// expression (e.g. "ready to accept connections") and returns the first matching line. An error is returned
+// immediately if the container exits before the message appears.
+func WaitForContainerLogMessageE(t testing.TestingT, containerID string, regex string, timeout time.Duration) (string, error) {
+	re, err := regexp.Compile("(?m)" + regex)
+	if err != nil {
+		return "", err
+	}
+
+	maxRetries := int(timeout.Seconds() / containerPollInterval.Seconds())
+	description := fmt.Sprintf("Waiting for container %s to log a message matching %s", containerID, regex)
+
+	line, err := retry.DoWithRetryE(t, description, maxRetries, containerPollInterval, func() (string, error) {
+		logs, err := GetContainerLogsE(t, containerID)
+		if err != nil {
+			return "", err
+		}
+
+		if line := findLogLine(re, logs); line != "" {
+			return line, nil
+		}
+
+		container, err := InspectE(t, containerID)
+		if err != nil {
+			return "", err
+		}
+		if !container.Running {
+			return "", retry.FatalError{Underlying: fmt.Errorf("container %s exited without logging a message matching %s", containerID, regex)}
+		}
+
+		return "", fmt.Errorf("no message matching %s logged by container %s yet", regex, containerID)
+	})
+
+	if fatalErr, isFatalErr := err.(retry.FatalError); isFatalErr {
+		return "", fatalErr.Underlying
+	}
+	return line, err
+}
+
+// findLogLine returns the first line of the given logs that matches the given regular expression, or an empty string
+// if none does.
+func findLogLine(re *regexp.Regexp, logs string) string {
+	loc := re.FindStringIndex(logs)
+	if loc == nil {
+		return ""
+	}
+
+	start := loc[0]
+	for start > 0 && logs[start-1] != '\n' {
+		start--
+	}
+
+	end := loc[1]
+	for end < len(logs) && logs[end] != '\n' {
+		end++
+	}
+
+	return logs[start:end]
+}
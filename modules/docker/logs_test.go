@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForContainerLogMessage(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{
+		Command:    []string{"-c", "sleep 2; echo 'server listening on port 8080'; sleep 300"},
+		Detach:     true,
+		Entrypoint: "sh",
+	}
+
+	id := RunAndGetID(t, "alpine:3.7", options)
+	defer removeContainer(t, id)
+
+	line := WaitForContainerLogMessage(t, id, `listening on port \d+`, 30*time.Second)
+	require.Equal(t, "server listening on port 8080", line)
+}
+
+func TestWaitForContainerLogMessageFailsWhenContainerExits(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{
+		Command:    []string{"-c", "echo 'something else'"},
+		Detach:     true,
+		Entrypoint: "sh",
+	}
+
+	id := RunAndGetID(t, "alpine:3.7", options)
+	defer removeContainer(t, id)
+
+	_, err := WaitForContainerLogMessageE(t, id, "ready", 30*time.Second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exited")
+}
+
+func TestFindLogLine(t *testing.T) {
+	t.Parallel()
+
+	logs := "booting\ndatabase system is ready to accept connections\nother"
+	require.Equal(t, "database system is ready to accept connections", findLogLine(regexp.MustCompile("ready to accept"), logs))
+	require.Equal(t, "other", findLogLine(regexp.MustCompile("(?m)^oth"), logs))
+	require.Equal(t, "", findLogLine(regexp.MustCompile("shutdown"), logs))
+}
@@ -0,0 +1,312 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// NetworkOptions defines options that can be passed to the 'docker network' commands.
+type NetworkOptions struct {
+	// Driver to manage the network (default "bridge")
+	Driver string
+
+	// If set to true, pass the --internal flag to 'docker network create' to restrict external access to the network
+	Internal bool
+
+	// Set metadata on the network, in the form "key=value"
+	Labels []string
+
+	// Custom CLI options that will be passed as-is to the 'docker network create' command.
+	OtherOptions []string
+
+	// Set a logger that should be used. See the logger package for more info.
+	Logger *logger.Logger
+}
+
+// NetworkInspect defines the output of the InspectNetwork method, with the options returned by
+// 'docker network inspect' converted into a more friendly and testable interface
+type NetworkInspect struct {
+	// ID of the inspected network
+	ID string
+
+	// Name of the inspected network
+	Name string
+
+	// Driver managing the network
+	Driver string
+
+	// Whether external access to the network is restricted
+	Internal bool
+
+	// Containers connected to the network
+	Containers []NetworkContainer
+}
+
+// NetworkContainer represents a single container connected to a network
+type NetworkContainer struct {
+	ID   string
+	Name string
+
+	// IPv4 address of the container on the network, in CIDR notation (e.g. "172.18.0.2/16")
+	IPv4Address string
+}
+
+// networkInspectOutput defines options that will be returned by 'docker network inspect', in JSON format.
+type networkInspectOutput struct {
+	Id         string
+	Name       string
+	Driver     string
+	Internal   bool
+	Containers map[string]struct {
+		Name        string
+		IPv4Address string
+	}
+}
+
+// CreateNetwork runs the 'docker network create' command to create a user-defined network with the given name and
+// returns its ID. Containers on a user-defined network can reach each other by name. If t supports Cleanup (as
+// testing.T does), the network is removed automatically when the test finishes; otherwise, defer RemoveNetwork. This
+// method fails the test if there are any errors.
+func CreateNetwork(t testing.TestingT, name string, options *NetworkOptions) string {
+	id, err := CreateNetworkE(t, name, options)
+	require.NoError(t, err)
+	return id
+}
+
+// CreateNetworkE runs the 'docker network create' command to create a user-defined network with the given name and
+// returns its ID. Containers on a user-defined network can reach each other by name. If t supports Cleanup (as
+// testing.T does), the network is removed automatically when the test finishes; otherwise, defer RemoveNetwork.
+func CreateNetworkE(t testing.TestingT, name string, options *NetworkOptions) (string, error) {
+	options.Logger.Logf(t, "Running 'docker network create' for network '%s'", name)
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    formatDockerNetworkCreateArgs(name, options),
+		Logger:  options.Logger,
+	}
+
+	id, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	registerCleanup(t, func() {
+		if _, err := RemoveNetworkE(t, name, options); err != nil {
+			options.Logger.Logf(t, "Failed to remove network '%s': %s", name, err)
+		}
+	})
+
+	return id, nil
+}
+
+// RemoveNetwork runs the 'docker network rm' command for the given network. Any containers still connected to it are
+// disconnected first. This method fails the test if there are any errors.
+func RemoveNetwork(t testing.TestingT, name string, options *NetworkOptions) string {
+	out, err := RemoveNetworkE(t, name, options)
+	require.NoError(t, err)
+	return out
+}
+
+// RemoveNetworkE runs the 'docker network rm' command for the given network. Any containers still connected to it are
+// disconnected first. It is not an error if the network does not exist.
+func RemoveNetworkE(t testing.TestingT, name string, options *NetworkOptions) (string, error) {
+	if dryrun.Skip(t, "remove network %s", name) {
+		return "", nil
+	}
+
+	network, err := InspectNetworkE(t, name)
+	if err != nil {
+		// The network has already been removed, e.g. by a deferred RemoveNetwork before the automatic cleanup ran
+		options.Logger.Logf(t, "Network '%s' not found, skipping removal: %s", name, err)
+		return "", nil
+	}
+
+	for _, container := range network.Containers {
+		if _, err := DisconnectNetworkE(t, name, container.ID, options); err != nil {
+			return "", err
+		}
+	}
+
+	options.Logger.Logf(t, "Running 'docker network rm' for network '%s'", name)
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"network", "rm", name},
+		Logger:  options.Logger,
+	}
+
+	return shell.RunCommandAndGetOutputE(t, cmd)
+}
+
+// ConnectNetwork runs the 'docker network connect' command to attach a running container to the given network, under
+// the given network-scoped aliases. This method fails the test if there are any errors.
+func ConnectNetwork(t testing.TestingT, networkName string, container string, aliases []string, options *NetworkOptions) string {
+	out, err := ConnectNetworkE(t, networkName, container, aliases, options)
+	require.NoError(t, err)
+	return out
+}
+
+// ConnectNetworkE runs the 'docker network connect' command to attach a running container to the given network, under
+// the given network-scoped aliases.
+func ConnectNetworkE(t testing.TestingT, networkName string, container string, aliases []string, options *NetworkOptions) (string, error) {
+	options.Logger.Logf(t, "Running 'docker network connect' for container '%s' and network '%s'", container, networkName)
+
+	args := []string{"network", "connect"}
+	for _, alias := range aliases {
+		args = append(args, "--alias", alias)
+	}
+	args = append(args, networkName, container)
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    args,
+		Logger:  options.Logger,
+	}
+
+	return shell.RunCommandAndGetOutputE(t, cmd)
+}
+
+// DisconnectNetwork runs the 'docker network disconnect' command to detach a container from the given network. This
+// method fails the test if there are any errors.
+func DisconnectNetwork(t testing.TestingT, networkName string, container string, options *NetworkOptions) string {
+	out, err := DisconnectNetworkE(t, networkName, container, options)
+	require.NoError(t, err)
+	return out
+}
+
+// DisconnectNetworkE runs the 'docker network disconnect' command to detach a container from the given network.
+func DisconnectNetworkE(t testing.TestingT, networkName string, container string, options *NetworkOptions) (string, error) {
+	options.Logger.Logf(t, "Running 'docker network disconnect' for container '%s' and network '%s'", container, networkName)
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"network", "disconnect", "--force", networkName, container},
+		Logger:  options.Logger,
+	}
+
+	return shell.RunCommandAndGetOutputE(t, cmd)
+}
+
+// InspectNetwork runs the 'docker network inspect' command and returns a NetworkInspect struct, converted from the
+// output JSON. This method fails the test if there are any errors.
+func InspectNetwork(t testing.TestingT, name string) *NetworkInspect {
+	out, err := InspectNetworkE(t, name)
+	require.NoError(t, err)
+	return out
+}
+
+// InspectNetworkE runs the 'docker network inspect' command and returns a NetworkInspect struct, converted from the
+// output JSON, along with any errors.
+func InspectNetworkE(t testing.TestingT, name string) (*NetworkInspect, error) {
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"network", "inspect", name},
+		// inspect is a short-running command, don't print the output.
+		Logger: logger.Discard,
+	}
+
+	out, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var networks []networkInspectOutput
+	if err := json.Unmarshal([]byte(out), &networks); err != nil {
+		return nil, err
+	}
+
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("no network found with name %s", name)
+	}
+
+	return transformNetwork(networks[0]), nil
+}
+
+// ContainerCanConnect checks whether the given container can open a TCP connection to the given host (e.g. the name or
+// network alias of another container) and port, by running 'nc' inside it. The container image must provide 'nc', as
+// busybox and alpine based images do. This method fails the test if the check could not be run.
+func ContainerCanConnect(t testing.TestingT, container string, host string, port int) bool {
+	ok, err := ContainerCanConnectE(t, container, host, port)
+	require.NoError(t, err)
+	return ok
+}
+
+// ContainerCanConnectE checks whether the given container can open a TCP connection to the given host (e.g. the name
+// or network alias of another container) and port, by running 'nc' inside it. The container image must provide 'nc',
+// as busybox and alpine based images do.
+func ContainerCanConnectE(t testing.TestingT, container string, host string, port int) (bool, error) {
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"exec", container, "nc", "-z", "-w", "5", host, strconv.Itoa(port)},
+		Logger:  logger.Discard,
+	}
+
+	if err := shell.RunCommandE(t, cmd); err != nil {
+		exitCode, exitCodeErr := shell.GetExitCodeForRunCommandError(err)
+		if exitCodeErr != nil {
+			return false, err
+		}
+		// 'nc' exits with 1 when the connection fails, while 'docker exec' uses 125 to 127 for its own failures
+		if exitCode == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// formatDockerNetworkCreateArgs formats the arguments for the 'docker network create' command.
+func formatDockerNetworkCreateArgs(name string, options *NetworkOptions) []string {
+	args := []string{"network", "create"}
+
+	if options.Driver != "" {
+		args = append(args, "--driver", options.Driver)
+	}
+
+	if options.Internal {
+		args = append(args, "--internal")
+	}
+
+	for _, label := range options.Labels {
+		args = append(args, "--label", label)
+	}
+
+	args = append(args, options.OtherOptions...)
+
+	return append(args, name)
+}
+
+// transformNetwork converts 'docker network inspect' output JSON into a more friendly and testable format
+func transformNetwork(network networkInspectOutput) *NetworkInspect {
+	containers := make([]NetworkContainer, 0, len(network.Containers))
+	for id, container := range network.Containers {
+		containers = append(containers, NetworkContainer{
+			ID:          id,
+			Name:        container.Name,
+			IPv4Address: container.IPv4Address,
+		})
+	}
+
+	return &NetworkInspect{
+		ID:         network.Id,
+		Name:       network.Name,
+		Driver:     network.Driver,
+		Internal:   network.Internal,
+		Containers: containers,
+	}
+}
+
+// registerCleanup runs the given function when the test finishes if t supports Cleanup, as testing.T does.
+func registerCleanup(t testing.TestingT, cleanup func()) {
+	if cleanupT, ok := t.(interface{ Cleanup(func()) }); ok {
+		cleanupT.Cleanup(cleanup)
+	}
+}
@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkConnectivityBetweenContainers(t *testing.T) {
+	t.Parallel()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	networkName := "test-network" + suffix
+	appName := "test-app" + suffix
+	sidecarName := "test-sidecar" + suffix
+
+	CreateNetwork(t, networkName, &NetworkOptions{})
+
+	runOpts := &RunOptions{
+		Detach:         true,
+		Name:           appName,
+		Remove:         true,
+		Network:        networkName,
+		NetworkAliases: []string{"app"},
+	}
+	RunAndGetID(t, "nginx:1.17-alpine", runOpts)
+	defer Stop(t, []string{appName}, &StopOptions{})
+
+	sidecarOpts := &RunOptions{
+		Detach:  true,
+		Name:    sidecarName,
+		Remove:  true,
+		Command: []string{"sleep", "300"},
+	}
+	RunAndGetID(t, "alpine:3.7", sidecarOpts)
+	defer Stop(t, []string{sidecarName}, &StopOptions{Time: 1})
+
+	// The sidecar can only reach the app once it is attached to the same network
+	require.False(t, ContainerCanConnect(t, sidecarName, "app", 80))
+	ConnectNetwork(t, networkName, sidecarName, nil, &NetworkOptions{})
+	require.Eventually(t, func() bool { return ContainerCanConnect(t, sidecarName, "app", 80) }, 30*time.Second, time.Second)
+
+	network := InspectNetwork(t, networkName)
+	require.Equal(t, networkName, network.Name)
+	require.Len(t, network.Containers, 2)
+}
+
+func TestRemoveNetworkIgnoresMissingNetwork(t *testing.T) {
+	t.Parallel()
+
+	_, err := RemoveNetworkE(t, "test-network-does-not-exist", &NetworkOptions{})
+	require.NoError(t, err)
+}
@@ -28,6 +28,12 @@ type RunOptions struct {
 	// Assign a name to the container
 	Name string
 
+	// Connect the container to this network, e.g. one created with CreateNetwork
+	Network string
+
+	// Network-scoped aliases under which other containers on Network can reach this container
+	NetworkAliases []string
+
 	// If set to true, pass the --privileged flag to 'docker run' to give extended privileges to the container
 	Privileged bool
 
@@ -129,6 +135,14 @@ func formatDockerRunArgs(image string, options *RunOptions) ([]string, error) {
 		args = append(args, "--name", options.Name)
 	}
 
+	if options.Network != "" {
+		args = append(args, "--network", options.Network)
+	}
+
+	for _, alias := range options.NetworkAliases {
+		args = append(args, "--network-alias", alias)
+	}
+
 	if options.Privileged {
 		args = append(args, "--privileged")
 	}
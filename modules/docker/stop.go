@@ -3,6 +3,7 @@ package docker
 import (
 	"strconv"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -28,6 +29,10 @@ func Stop(t testing.TestingT, containers []string, options *StopOptions) string
 
 // StopE runs the 'docker stop' command for the given containers and returns any errors.
 func StopE(t testing.TestingT, containers []string, options *StopOptions) (string, error) {
+	if dryrun.Skip(t, "stop containers %s", containers) {
+		return "", nil
+	}
+
 	options.Logger.Logf(t, "Running 'docker stop' on containers '%s'", containers)
 
 	args, err := formatDockerStopArgs(containers, options)
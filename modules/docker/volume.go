@@ -0,0 +1,158 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// VolumeOptions defines options that can be passed to the 'docker volume' commands.
+type VolumeOptions struct {
+	// Driver to manage the volume (default "local")
+	Driver string
+
+	// Set metadata on the volume, in the form "key=value"
+	Labels []string
+
+	// Custom CLI options that will be passed as-is to the 'docker volume create' command.
+	OtherOptions []string
+
+	// Set a logger that should be used. See the logger package for more info.
+	Logger *logger.Logger
+}
+
+// VolumeInspect defines the output of the InspectVolume method, with the options returned by 'docker volume inspect'
+// converted into a more friendly and testable interface
+type VolumeInspect struct {
+	// Name of the inspected volume
+	Name string
+
+	// Driver managing the volume
+	Driver string
+
+	// Path of the volume on the Docker host
+	Mountpoint string
+
+	// Metadata set on the volume
+	Labels map[string]string
+}
+
+// CreateVolume runs the 'docker volume create' command to create a named volume, which can then be shared between
+// containers through RunOptions.Volumes (e.g. "<name>:/data"). If t supports Cleanup (as testing.T does), the volume is
+// removed automatically when the test finishes; otherwise, defer RemoveVolume. This method fails the test if there are
+// any errors.
+func CreateVolume(t testing.TestingT, name string, options *VolumeOptions) string {
+	out, err := CreateVolumeE(t, name, options)
+	require.NoError(t, err)
+	return out
+}
+
+// CreateVolumeE runs the 'docker volume create' command to create a named volume, which can then be shared between
+// containers through RunOptions.Volumes (e.g. "<name>:/data"). If t supports Cleanup (as testing.T does), the volume is
+// removed automatically when the test finishes; otherwise, defer RemoveVolume.
+func CreateVolumeE(t testing.TestingT, name string, options *VolumeOptions) (string, error) {
+	options.Logger.Logf(t, "Running 'docker volume create' for volume '%s'", name)
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    formatDockerVolumeCreateArgs(name, options),
+		Logger:  options.Logger,
+	}
+
+	out, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	registerCleanup(t, func() {
+		if _, err := RemoveVolumeE(t, name, options); err != nil {
+			options.Logger.Logf(t, "Failed to remove volume '%s': %s", name, err)
+		}
+	})
+
+	return out, nil
+}
+
+// RemoveVolume runs the 'docker volume rm' command for the given volume. The volume must no longer be used by any
+// container. This method fails the test if there are any errors.
+func RemoveVolume(t testing.TestingT, name string, options *VolumeOptions) string {
+	out, err := RemoveVolumeE(t, name, options)
+	require.NoError(t, err)
+	return out
+}
+
+// RemoveVolumeE runs the 'docker volume rm' command for the given volume. The volume must no longer be used by any
+// container. It is not an error if the volume does not exist.
+func RemoveVolumeE(t testing.TestingT, name string, options *VolumeOptions) (string, error) {
+	if dryrun.Skip(t, "remove volume %s", name) {
+		return "", nil
+	}
+
+	options.Logger.Logf(t, "Running 'docker volume rm' for volume '%s'", name)
+
+	cmd := shell.Command{
+		Command: "docker",
+		// --force only ignores volumes that do not exist, it does not remove volumes in use
+		Args:   []string{"volume", "rm", "--force", name},
+		Logger: options.Logger,
+	}
+
+	return shell.RunCommandAndGetOutputE(t, cmd)
+}
+
+// InspectVolume runs the 'docker volume inspect' command and returns a VolumeInspect struct, converted from the output
+// JSON. This method fails the test if there are any errors.
+func InspectVolume(t testing.TestingT, name string) *VolumeInspect {
+	out, err := InspectVolumeE(t, name)
+	require.NoError(t, err)
+	return out
+}
+
+// InspectVolumeE runs the 'docker volume inspect' command and returns a VolumeInspect struct, converted from the
+// output JSON, along with any errors.
+func InspectVolumeE(t testing.TestingT, name string) (*VolumeInspect, error) {
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"volume", "inspect", name},
+		// inspect is a short-running command, don't print the output.
+		Logger: logger.Discard,
+	}
+
+	out, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []VolumeInspect
+	if err := json.Unmarshal([]byte(out), &volumes); err != nil {
+		return nil, err
+	}
+
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("no volume found with name %s", name)
+	}
+
+	return &volumes[0], nil
+}
+
+// formatDockerVolumeCreateArgs formats the arguments for the 'docker volume create' command.
+func formatDockerVolumeCreateArgs(name string, options *VolumeOptions) []string {
+	args := []string{"volume", "create"}
+
+	if options.Driver != "" {
+		args = append(args, "--driver", options.Driver)
+	}
+
+	for _, label := range options.Labels {
+		args = append(args, "--label", label)
+	}
+
+	args = append(args, options.OtherOptions...)
+
+	return append(args, name)
+}
@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumeSharedBetweenContainers(t *testing.T) {
+	t.Parallel()
+
+	volumeName := "test-volume" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	CreateVolume(t, volumeName, &VolumeOptions{Labels: []string{"terratest=true"}})
+
+	volume := InspectVolume(t, volumeName)
+	require.Equal(t, "local", volume.Driver)
+	require.Equal(t, "true", volume.Labels["terratest"])
+
+	writeOpts := &RunOptions{
+		Command:    []string{"-c", "echo shared > /data/file"},
+		Entrypoint: "sh",
+		Remove:     true,
+		Volumes:    []string{volumeName + ":/data"},
+	}
+	Run(t, "alpine:3.7", writeOpts)
+
+	readOpts := &RunOptions{
+		Command: []string{"cat", "/data/file"},
+		Remove:  true,
+		Volumes: []string{volumeName + ":/data"},
+	}
+	out := Run(t, "alpine:3.7", readOpts)
+	require.Contains(t, out, "shared")
+}
@@ -0,0 +1,46 @@
+// Package dryrun allows to run tests without letting the destructive helpers of the modules, such as
+// aws.TerminateInstanceE, k8s.DeleteNamespaceE, aws.EmptyS3BucketE or terraform.DestroyE, change anything. With the
+// TERRATEST_DRY_RUN environment variable set to true, these helpers log what they would do and return success, which
+// allows to validate new test code paths before running them against shared accounts.
+//
+// Dry-run mode only suppresses teardown and in-place mutations: the helpers that destroy, delete, terminate, purge,
+// stop, drain, cordon, roll back, fail over or reassociate something. Helpers that create or apply something, such as
+// terraform.InitAndApply, k8s.KubectlApply or aws.CreateRandomQueue, still run, and since the matching teardown is
+// skipped, whatever they create is left behind and has to be cleaned up manually.
+package dryrun
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// EnvVar is the environment variable that enables dry-run mode, e.g. TERRATEST_DRY_RUN=true.
+const EnvVar = "TERRATEST_DRY_RUN"
+
+// IsEnabled returns true if dry-run mode is enabled by the TERRATEST_DRY_RUN environment variable. Values that are not
+// booleans, as accepted by strconv.ParseBool, enable it too, so that a typo never lets destructive calls through.
+func IsEnabled() bool {
+	value := os.Getenv(EnvVar)
+	if value == "" {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	return enabled || err != nil
+}
+
+// Skip returns true if dry-run mode is enabled, in which case it logs that the action described by the given format and
+// arguments, e.g. "terminate EC2 Instance %s", would be taken. Destructive helpers call this first and return success
+// without doing anything if it returns true.
+func Skip(t testing.TestingT, format string, args ...interface{}) bool {
+	if !IsEnabled() {
+		return false
+	}
+
+	logger.Logf(t, "[DRY RUN] Would %s", fmt.Sprintf(format, args...))
+	return true
+}
@@ -0,0 +1,41 @@
+package dryrun
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The tests in this file set the TERRATEST_DRY_RUN environment variable, so they must not run in parallel.
+
+func TestIsEnabled(t *testing.T) {
+	defer os.Unsetenv(EnvVar)
+
+	testCases := []struct {
+		value    string
+		expected bool
+	}{
+		{"", false},
+		{"false", false},
+		{"0", false},
+		{"true", true},
+		{"1", true},
+		{"yes-please", true},
+	}
+
+	for _, testCase := range testCases {
+		os.Setenv(EnvVar, testCase.value)
+		assert.Equal(t, testCase.expected, IsEnabled(), "value %q", testCase.value)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	defer os.Unsetenv(EnvVar)
+
+	os.Unsetenv(EnvVar)
+	assert.False(t, Skip(t, "terminate EC2 Instance %s", "i-123"))
+
+	os.Setenv(EnvVar, "true")
+	assert.True(t, Skip(t, "terminate EC2 Instance %s", "i-123"))
+}
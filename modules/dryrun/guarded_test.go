@@ -0,0 +1,153 @@
+package dryrun_test
+
+import (
+	"os"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/docker"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/objectstore"
+	"github.com/gruntwork-io/terratest/modules/oci"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGuardedHelpers lists the destructive helpers that dry-run mode suppresses. None of them have valid arguments
+// here, so any of them that does not return early with dry-run mode enabled fails trying to reach a cloud or cluster.
+// This test sets the TERRATEST_DRY_RUN environment variable, so it must not run in parallel.
+func TestGuardedHelpers(t *testing.T) {
+	os.Setenv(dryrun.EnvVar, "true")
+	defer os.Unsetenv(dryrun.EnvVar)
+
+	kubectlOptions := k8s.NewKubectlOptions("dry-run-context", "/nonexistent/kubeconfig", "dry-run")
+	terraformOptions := &terraform.Options{TerraformDir: "/nonexistent"}
+
+	helpers := map[string]func() error{
+		// aws
+		"aws.TerminateInstanceE":         func() error { return aws.TerminateInstanceE(t, "us-east-1", "i-0") },
+		"aws.DeleteAmiE":                 func() error { return aws.DeleteAmiE(t, "us-east-1", "ami-0") },
+		"aws.DeleteEbsSnapshotE":         func() error { return aws.DeleteEbsSnapshotE(t, "us-east-1", "snap-0") },
+		"aws.DeleteS3BucketE":            func() error { return aws.DeleteS3BucketE(t, "us-east-1", "bucket") },
+		"aws.EmptyS3BucketE":             func() error { return aws.EmptyS3BucketE(t, "us-east-1", "bucket") },
+		"aws.DeleteParameterWithClientE": func() error { return aws.DeleteParameterWithClientE(t, nil, "key") },
+		"aws.DeleteSecretE":              func() error { return aws.DeleteSecretE(t, "us-east-1", "id", true) },
+		"aws.DeleteQueueE":               func() error { return aws.DeleteQueueE(t, "us-east-1", "queue") },
+		"aws.DeleteMessageFromQueueE":    func() error { return aws.DeleteMessageFromQueueE(t, "us-east-1", "queue", "receipt") },
+		"aws.PurgeQueueE":                func() error { return aws.PurgeQueueE(t, "us-east-1", "queue") },
+		"aws.DeleteSNSTopicE":            func() error { return aws.DeleteSNSTopicE(t, "us-east-1", "topic") },
+		"aws.DeleteVpcFlowLogsE":         func() error { return aws.DeleteVpcFlowLogsE(t, "us-east-1", "fl-0") },
+		"aws.DeleteFlowLogsTableE":       func() error { return aws.DeleteFlowLogsTableE(t, &aws.FlowLogsTable{Database: "db"}) },
+		"aws.DeleteCloudFormationStackE": func() error {
+			return aws.DeleteCloudFormationStackE(t, "us-east-1", "stack")
+		},
+		"aws.TerminateServiceCatalogProvisionedProductE": func() error {
+			return aws.TerminateServiceCatalogProvisionedProductE(t, "us-east-1", "pp-0", 1, 0)
+		},
+		"aws.DeleteProtonEnvironmentE":     func() error { return aws.DeleteProtonEnvironmentE(t, "us-east-1", "env", 1, 0) },
+		"aws.StopFisExperimentE":           func() error { return aws.StopFisExperimentE(t, "us-east-1", "exp") },
+		"aws.DeleteFisExperimentTemplateE": func() error { return aws.DeleteFisExperimentTemplateE(t, "us-east-1", "tpl") },
+		"aws.StopCanaryE":                  func() error { return aws.StopCanaryE(t, "us-east-1", "canary") },
+		"aws.ReassociateElasticIpE": func() error {
+			_, err := aws.ReassociateElasticIpE(t, "us-east-1", "eipalloc-0", "i-0")
+			return err
+		},
+		"aws.TriggerAuroraFailoverAndWaitE": func() error {
+			_, err := aws.TriggerAuroraFailoverAndWaitE(t, "cluster", "us-east-1", "", 1, 0)
+			return err
+		},
+		"aws.DeregisterTargetsInAvailabilityZoneE": func() error {
+			_, err := aws.DeregisterTargetsInAvailabilityZoneE(t, "us-east-1", "arn", "us-east-1a")
+			return err
+		},
+		"aws.DeleteEcsClusterE": func() error {
+			return aws.DeleteEcsClusterE(t, "us-east-1", &ecs.Cluster{ClusterName: awssdk.String("cluster")})
+		},
+		"aws.DeleteECRRepoE": func() error {
+			return aws.DeleteECRRepoE(t, "us-east-1", &ecr.Repository{RepositoryName: awssdk.String("repo")})
+		},
+		"aws.IsolateSubnetsE": func() error {
+			_, err := aws.IsolateSubnetsE(t, "us-east-1", "vpc-0", []string{"subnet-0"})
+			return err
+		},
+		"aws.RestoreIsolatedSubnetsE": func() error {
+			return aws.RestoreIsolatedSubnetsE(t, &aws.SubnetIsolation{Region: "us-east-1", NetworkAclId: "acl-0"})
+		},
+		"aws.DeleteTemporarySshAccessE": func() error {
+			return aws.DeleteTemporarySshAccessE(t, &aws.SshAuth{Region: "us-east-1", SecurityGroupId: "sg-0"})
+		},
+
+		// k8s
+		"k8s.DeleteNamespaceE":             func() error { return k8s.DeleteNamespaceE(t, kubectlOptions, "namespace") },
+		"k8s.DeletePodE":                   func() error { return k8s.DeletePodE(t, kubectlOptions, "pod") },
+		"k8s.DeletePersistentVolumeClaimE": func() error { return k8s.DeletePersistentVolumeClaimE(t, kubectlOptions, "pvc") },
+		"k8s.DeleteVolumeSnapshotE":        func() error { return k8s.DeleteVolumeSnapshotE(t, kubectlOptions, "snapshot") },
+		"k8s.CordonNodeE":                  func() error { return k8s.CordonNodeE(t, kubectlOptions, "node") },
+		"k8s.DrainNodeE":                   func() error { return k8s.DrainNodeE(t, kubectlOptions, "node", 1, 0) },
+		"k8s.DeleteNodeE":                  func() error { return k8s.DeleteNodeE(t, kubectlOptions, "node") },
+		"k8s.KubectlDeleteE":               func() error { return k8s.KubectlDeleteE(t, kubectlOptions, "/nonexistent.yaml") },
+		"k8s.KubectlDeleteFromStringE":     func() error { return k8s.KubectlDeleteFromStringE(t, kubectlOptions, "kind: Pod") },
+
+		// terraform
+		"terraform.DestroyE": func() error {
+			_, err := terraform.DestroyE(t, terraformOptions)
+			return err
+		},
+		"terraform.TgDestroyAllE": func() error {
+			_, err := terraform.TgDestroyAllE(t, &terraform.Options{TerraformDir: "/nonexistent", TerraformBinary: "terragrunt"})
+			return err
+		},
+		"terraform.WorkspaceDeleteE": func() error {
+			_, err := terraform.WorkspaceDeleteE(t, terraformOptions, "workspace")
+			return err
+		},
+		"terraform.DestroyRemoteStateStackE": func() error {
+			return terraform.DestroyRemoteStateStackE(t, terraform.RemoteStateStack{Producer: terraformOptions, Consumer: terraformOptions})
+		},
+
+		// docker
+		"docker.RemoveNetworkE": func() error {
+			_, err := docker.RemoveNetworkE(t, "network", &docker.NetworkOptions{})
+			return err
+		},
+		"docker.RemoveVolumeE": func() error {
+			_, err := docker.RemoveVolumeE(t, "volume", &docker.VolumeOptions{})
+			return err
+		},
+		"docker.StopE": func() error {
+			_, err := docker.StopE(t, []string{"container"}, &docker.StopOptions{})
+			return err
+		},
+
+		// helm
+		"helm.DeleteE":   func() error { return helm.DeleteE(t, &helm.Options{}, "release", true) },
+		"helm.RollbackE": func() error { return helm.RollbackE(t, &helm.Options{}, "release", "1") },
+
+		// gcp
+		"gcp.DeleteStorageBucketE":      func() error { return gcp.DeleteStorageBucketE(t, "bucket") },
+		"gcp.EmptyStorageBucketE":       func() error { return gcp.EmptyStorageBucketE(t, "bucket") },
+		"gcp.DeletePubSubTopicE":        func() error { return gcp.DeletePubSubTopicE(t, "project", "topic") },
+		"gcp.DeletePubSubSubscriptionE": func() error { return gcp.DeletePubSubSubscriptionE(t, "project", "subscription") },
+		"gcp.DeleteSSHKeyE":             func() error { return gcp.DeleteSSHKeyE(t, "user", "key") },
+		"gcp.DeleteGCRRepoE":            func() error { return gcp.DeleteGCRRepoE(t, "gcr.io/project/repo") },
+		"gcp.DeleteGCRImageRefE":        func() error { return gcp.DeleteGCRImageRefE(t, "gcr.io/project/repo:tag") },
+
+		// oci
+		"oci.DeleteImageE": func() error { return oci.DeleteImageE(t, "ocid1.image.oc1..dry-run") },
+
+		// objectstore
+		"objectstore.DeletePrefixE":   func() error { return objectstore.DeletePrefixE(t, nil, "prefix/") },
+		"objectstore.S3Store.Delete":  func() error { return (&objectstore.S3Store{Bucket: "bucket"}).Delete(t, "key") },
+		"objectstore.GcsStore.Delete": func() error { return (&objectstore.GcsStore{Bucket: "bucket"}).Delete(t, "key") },
+	}
+
+	for name, helper := range helpers {
+		assert.NoError(t, helper(), name)
+	}
+}
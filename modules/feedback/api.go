@@ -0,0 +1,77 @@
+package feedback
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiTimeout is how long to wait for the GitHub or GitLab API to respond, so that an unreachable API doesn't hang the
+// test.
+const apiTimeout = 30 * time.Second
+
+// apiClient sends authenticated requests to the REST API at a base URL.
+type apiClient struct {
+	client  *http.Client
+	baseURL string
+	headers map[string]string // Authentication and other headers to send with every request
+}
+
+// doE sends a request with the given method to the given path of the API, with the given value as JSON body if not nil,
+// and decodes the JSON response into out if not nil. Returns an APIRequestFailed error if the API responds with an error
+// status.
+func (api apiClient) doE(method string, path string, value interface{}, out interface{}) error {
+	client := api.client
+	if client == nil {
+		client = &http.Client{Timeout: apiTimeout}
+	}
+
+	var body io.Reader
+	if value != nil {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(api.baseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if value != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, headerValue := range api.headers {
+		req.Header.Set(name, headerValue)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Only return the underlying error, as the URL may include the token for some setups, e.g. in a proxy URL
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return APIRequestFailed{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
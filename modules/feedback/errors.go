@@ -0,0 +1,38 @@
+package feedback
+
+import "fmt"
+
+// NoReporterConfigured is returned by ReporterFromEnvE when not running in a supported CI system with a token set.
+type NoReporterConfigured struct{}
+
+func (err NoReporterConfigured) Error() string {
+	return fmt.Sprintf("No pull request feedback reporter configured: set %s in GitHub Actions or %s in GitLab CI", GitHubTokenEnvVar, GitLabTokenEnvVar)
+}
+
+// InvalidPullRequest is returned when the number of the pull request under test is not a number.
+type InvalidPullRequest struct {
+	Value string
+}
+
+func (err InvalidPullRequest) Error() string {
+	return fmt.Sprintf("Invalid pull request number %q", err.Value)
+}
+
+// NoPullRequest is returned when posting a comment without a pull request to post it on, e.g. for a push build.
+type NoPullRequest struct{}
+
+func (err NoPullRequest) Error() string {
+	return fmt.Sprintf("Cannot post a comment without a pull request: set %s", PullRequestEnvVar)
+}
+
+// APIRequestFailed is returned when a request to the GitHub or GitLab API responds with an error status.
+type APIRequestFailed struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (err APIRequestFailed) Error() string {
+	return fmt.Sprintf("%s %s responded with status %d: %s", err.Method, err.Path, err.StatusCode, err.Body)
+}
@@ -0,0 +1,154 @@
+// Package feedback posts the results of a test run, such as Terraform plan summaries, the results of the stages of a
+// scenario and cost estimates, to the pull request being tested, as comments and commit statuses on GitHub or GitLab.
+// This gives Atlantis-style feedback on pull requests directly from Terratest suites:
+//
+//	reporter := feedback.ReporterFromEnv(t)
+//	feedback.SetStatus(t, reporter, feedback.Status{Context: "terratest/vpc", State: feedback.StateSuccess})
+//	feedback.UpsertComment(t, reporter, "vpc", feedback.FormatComment("VPC plan", feedback.PlanSummary(plan)))
+//
+// The reporter is configured from the environment variables of GitHub Actions or GitLab CI, with the token in
+// GITHUB_TOKEN or GITLAB_TOKEN.
+package feedback
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/gruntwork-io/terratest/modules/runinfo"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// The environment variables ReporterFromEnvE reads, in addition to the standard ones of GitHub Actions and GitLab CI.
+const (
+	GitHubTokenEnvVar = "GITHUB_TOKEN"
+	GitLabTokenEnvVar = "GITLAB_TOKEN"
+
+	// PullRequestEnvVar overrides the number of the pull request (or the IID of the merge request) to comment on, e.g.
+	// for workflows that are not triggered by pull request events.
+	PullRequestEnvVar = runinfo.PullRequestEnvVar
+)
+
+// State is the state of a commit status.
+type State string
+
+const (
+	StatePending State = "pending"
+	StateSuccess State = "success"
+	StateFailure State = "failure"
+	StateError   State = "error"
+)
+
+// Status is a commit status, shown next to the commit in the pull request.
+type Status struct {
+	Context     string // Identifies the status among the others of the commit, e.g. "terratest/vpc"
+	State       State
+	Description string
+	TargetURL   string // Where the status links to, e.g. the CI job
+}
+
+// Reporter posts feedback to the pull request or merge request under test.
+type Reporter interface {
+	// UpsertCommentE posts the given comment, replacing the one posted earlier with the same key, if any, so that re-runs
+	// update their comment instead of adding a new one.
+	UpsertCommentE(t testing.TestingT, key string, body string) error
+
+	// SetStatusE sets the given status on the commit under test.
+	SetStatusE(t testing.TestingT, status Status) error
+}
+
+// UpsertComment posts the given comment with the given reporter, replacing the one posted earlier with the same key.
+// This will fail the test if there is an error.
+func UpsertComment(t testing.TestingT, reporter Reporter, key string, body string) {
+	require.NoError(t, reporter.UpsertCommentE(t, key, body))
+}
+
+// SetStatus sets the given status on the commit under test with the given reporter. This will fail the test if there
+// is an error.
+func SetStatus(t testing.TestingT, reporter Reporter, status Status) {
+	require.NoError(t, reporter.SetStatusE(t, status))
+}
+
+// ReporterFromEnv returns a reporter for the pull request under test, as found in the environment variables of the CI
+// system. This will fail the test if there is none.
+func ReporterFromEnv(t testing.TestingT) Reporter {
+	reporter, err := ReporterFromEnvE(t)
+	require.NoError(t, err)
+	return reporter
+}
+
+// ReporterFromEnvE returns a reporter for the pull request under test, as found in the environment variables of the CI
+// system: a GitHubReporter when running in GitHub Actions with GITHUB_TOKEN set, or a GitLabReporter when running in
+// GitLab CI with GITLAB_TOKEN set. Returns a NoReporterConfigured error otherwise, e.g. when running locally. The pull
+// request can be set with TERRATEST_PULL_REQUEST when CI does not say.
+func ReporterFromEnvE(t testing.TestingT) (Reporter, error) {
+	return reporterFromEnvE(os.Getenv)
+}
+
+func reporterFromEnvE(getenv func(string) string) (Reporter, error) {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true" && getenv(GitHubTokenEnvVar) != "":
+		pullRequest, err := pullRequestFromEnvE(getenv, githubPullRequest(getenv("GITHUB_REF")))
+		if err != nil {
+			return nil, err
+		}
+		return &GitHubReporter{
+			Token:       getenv(GitHubTokenEnvVar),
+			APIURL:      getenv("GITHUB_API_URL"),
+			Repository:  getenv("GITHUB_REPOSITORY"),
+			PullRequest: pullRequest,
+			CommitSha:   getenv("GITHUB_SHA"),
+		}, nil
+	case getenv("GITLAB_CI") == "true" && getenv(GitLabTokenEnvVar) != "":
+		pullRequest, err := pullRequestFromEnvE(getenv, getenv("CI_MERGE_REQUEST_IID"))
+		if err != nil {
+			return nil, err
+		}
+		return &GitLabReporter{
+			Token:        getenv(GitLabTokenEnvVar),
+			APIURL:       getenv("CI_API_V4_URL"),
+			ProjectID:    getenv("CI_PROJECT_ID"),
+			MergeRequest: pullRequest,
+			CommitSha:    getenv("CI_COMMIT_SHA"),
+		}, nil
+	default:
+		return nil, NoReporterConfigured{}
+	}
+}
+
+// pullRequestFromEnvE returns the pull request set with TERRATEST_PULL_REQUEST, or else the given one found by CI, or 0 if
+// there is none, in which case only commit statuses can be set.
+func pullRequestFromEnvE(getenv func(string) string, fromCI string) (int, error) {
+	value := getenv(PullRequestEnvVar)
+	if value == "" {
+		value = fromCI
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	pullRequest, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, InvalidPullRequest{Value: value}
+	}
+	return pullRequest, nil
+}
+
+// githubPullRequestRefRegexp matches the refs GitHub Actions checks out for pull requests, e.g. refs/pull/42/merge.
+var githubPullRequestRefRegexp = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+// githubPullRequest returns the number of the pull request of the given GitHub ref, or "" if it is not a pull request.
+func githubPullRequest(ref string) string {
+	matches := githubPullRequestRefRegexp.FindStringSubmatch(ref)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
+// commentMarker returns the hidden marker included in comments with the given key, used to find them again.
+func commentMarker(key string) string {
+	return fmt.Sprintf("<!-- terratest-feedback:%s -->", key)
+}
@@ -0,0 +1,201 @@
+package feedback
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/scenario"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Body   map[string]string
+}
+
+// newRecordingServer returns a server that records the requests it receives and responds to GET requests with the given
+// comments.
+func newRecordingServer(t *testing.T, comments []map[string]interface{}) (*httptest.Server, *[]recordedRequest) {
+	requests := []recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := recordedRequest{Method: r.Method, Path: r.URL.EscapedPath()}
+		body, _ := ioutil.ReadAll(r.Body)
+		if len(body) > 0 {
+			require.NoError(t, json.Unmarshal(body, &request.Body))
+		}
+		requests = append(requests, request)
+
+		if r.Method == http.MethodGet {
+			require.NoError(t, json.NewEncoder(w).Encode(comments))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestGitHubReporterUpsertCommentCreatesAndUpdates(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newRecordingServer(t, []map[string]interface{}{
+		{"id": 1, "body": "unrelated"},
+		{"id": 2, "body": commentMarker("vpc") + "\nold"},
+	})
+	reporter := &GitHubReporter{Token: "secret", APIURL: server.URL, Repository: "acme/infra", PullRequest: 7}
+
+	UpsertComment(t, reporter, "vpc", "new")
+	UpsertComment(t, reporter, "eks", "first")
+
+	require.Len(t, *requests, 4)
+	assert.Equal(t, recordedRequest{Method: http.MethodPatch, Path: "/repos/acme/infra/issues/comments/2", Body: map[string]string{"body": commentMarker("vpc") + "\nnew"}}, (*requests)[1])
+	assert.Equal(t, recordedRequest{Method: http.MethodPost, Path: "/repos/acme/infra/issues/7/comments", Body: map[string]string{"body": commentMarker("eks") + "\nfirst"}}, (*requests)[3])
+}
+
+func TestGitHubReporterWithoutPullRequest(t *testing.T) {
+	t.Parallel()
+
+	reporter := &GitHubReporter{Token: "secret", Repository: "acme/infra"}
+	err := reporter.UpsertCommentE(t, "vpc", "body")
+	assert.True(t, errors.As(err, &NoPullRequest{}))
+}
+
+func TestGitLabReporterSetStatus(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newRecordingServer(t, nil)
+	reporter := &GitLabReporter{Token: "secret", APIURL: server.URL, ProjectID: "acme/infra", CommitSha: "abc123"}
+
+	SetStatus(t, reporter, Status{Context: "terratest/vpc", State: StateError, Description: strings.Repeat("x", 200)})
+
+	require.Len(t, *requests, 1)
+	request := (*requests)[0]
+	assert.Equal(t, "/projects/acme%2Finfra/statuses/abc123", request.Path)
+	assert.Equal(t, "terratest/vpc", request.Body["name"])
+	assert.Equal(t, "failed", request.Body["state"])
+	assert.Len(t, request.Body["description"], maxStatusDescriptionLength)
+}
+
+func TestAPIRequestFailed(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+	}))
+	defer server.Close()
+
+	reporter := &GitHubReporter{Token: "secret", APIURL: server.URL, Repository: "acme/infra", CommitSha: "abc123"}
+	err := reporter.SetStatusE(t, Status{Context: "terratest", State: StateSuccess})
+
+	failed := APIRequestFailed{}
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, http.StatusForbidden, failed.StatusCode)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestReporterFromEnv(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected Reporter
+		err      error
+	}{
+		{"none", map[string]string{}, nil, NoReporterConfigured{}},
+		{"github without token", map[string]string{"GITHUB_ACTIONS": "true"}, nil, NoReporterConfigured{}},
+		{
+			"github pull request",
+			map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_TOKEN": "secret", "GITHUB_REPOSITORY": "acme/infra", "GITHUB_REF": "refs/pull/42/merge", "GITHUB_SHA": "abc123"},
+			&GitHubReporter{Token: "secret", Repository: "acme/infra", PullRequest: 42, CommitSha: "abc123"},
+			nil,
+		},
+		{
+			"github push with override",
+			map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_TOKEN": "secret", "GITHUB_REF": "refs/heads/main", PullRequestEnvVar: "9"},
+			&GitHubReporter{Token: "secret", PullRequest: 9},
+			nil,
+		},
+		{
+			"gitlab merge request",
+			map[string]string{"GITLAB_CI": "true", "GITLAB_TOKEN": "secret", "CI_API_V4_URL": "https://git.acme.com/api/v4", "CI_PROJECT_ID": "5", "CI_MERGE_REQUEST_IID": "3", "CI_COMMIT_SHA": "abc123"},
+			&GitLabReporter{Token: "secret", APIURL: "https://git.acme.com/api/v4", ProjectID: "5", MergeRequest: 3, CommitSha: "abc123"},
+			nil,
+		},
+		{"invalid pull request", map[string]string{"GITLAB_CI": "true", "GITLAB_TOKEN": "secret", PullRequestEnvVar: "abc"}, nil, InvalidPullRequest{Value: "abc"}},
+	}
+
+	for _, testCase := range testCases {
+		// capture range variable so that it doesn't change as the loop advances
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			reporter, err := reporterFromEnvE(func(name string) string { return testCase.env[name] })
+			assert.Equal(t, testCase.err, err)
+			assert.Equal(t, testCase.expected, reporter)
+		})
+	}
+}
+
+func TestPlanSummary(t *testing.T) {
+	t.Parallel()
+
+	managedChange := func(actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{Mode: tfjson.ManagedResourceMode, Change: &tfjson.Change{Actions: actions}}
+	}
+	plan := &terraform.PlanStruct{ResourceChangesMap: map[string]*tfjson.ResourceChange{
+		"aws_vpc.main":        managedChange(tfjson.ActionCreate),
+		"aws_instance.web":    managedChange(tfjson.ActionDelete, tfjson.ActionCreate),
+		"aws_subnet.private":  managedChange(tfjson.ActionUpdate),
+		"aws_s3_bucket.logs":  managedChange(tfjson.ActionNoop),
+		"data.aws_ami.ubuntu": {Mode: tfjson.DataResourceMode, Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionRead}}},
+	}}
+
+	expected := strings.Join([]string{
+		"**Plan:** 2 to add, 1 to change, 1 to destroy.",
+		"",
+		"| Resource | Action |",
+		"| --- | --- |",
+		"| `aws_instance.web` | replace |",
+		"| `aws_subnet.private` | update |",
+		"| `aws_vpc.main` | create |",
+	}, "\n")
+	assert.Equal(t, expected, PlanSummary(plan))
+	assert.Equal(t, "**Plan:** no changes.", PlanSummary(&terraform.PlanStruct{}))
+}
+
+func TestStageResults(t *testing.T) {
+	t.Parallel()
+
+	report := &scenario.Report{
+		Scenario: "upgrade",
+		Duration: 90 * time.Second,
+		Steps: []scenario.StepResult{
+			{Name: "deploy", Status: scenario.Passed, Attempts: 1, Duration: 60 * time.Second},
+			{Name: "verify", Status: scenario.Failed, Attempts: 3, Duration: 30 * time.Second, Err: errors.New("HTTP 502 | bad gateway")},
+		},
+	}
+
+	expected := strings.Join([]string{
+		"**Scenario upgrade** failed in 1m30s.",
+		"",
+		"| Stage | Status | Attempts | Duration |",
+		"| --- | --- | --- | --- |",
+		"| deploy | PASSED | 1 | 1m0s |",
+		"| verify | FAILED: HTTP 502 \\| bad gateway | 3 | 30s |",
+	}, "\n")
+	assert.Equal(t, expected, StageResults(report))
+}
@@ -0,0 +1,129 @@
+package feedback
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/scenario"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// maxStatusDescriptionLength is the longest description GitHub accepts for commit statuses.
+const maxStatusDescriptionLength = 140
+
+// FormatComment returns a markdown comment with the given title, followed by the given sections, such as the ones
+// returned by PlanSummary, StageResults and CostEstimate.
+func FormatComment(title string, sections ...string) string {
+	parts := []string{"### " + title}
+	for _, section := range sections {
+		if section != "" {
+			parts = append(parts, section)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// PlanSummary returns a markdown summary of the given plan: the number of resources to add, change and destroy, and a
+// table of the managed resources that change.
+func PlanSummary(plan *terraform.PlanStruct) string {
+	addresses := []string{}
+	for address, change := range plan.ResourceChangesMap {
+		if change.Mode == tfjson.ManagedResourceMode && change.Change != nil && !change.Change.Actions.NoOp() && !change.Change.Actions.Read() {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	if len(addresses) == 0 {
+		return "**Plan:** no changes."
+	}
+
+	toAdd, toChange, toDestroy := 0, 0, 0
+	rows := []string{"| Resource | Action |", "| --- | --- |"}
+	for _, address := range addresses {
+		actions := plan.ResourceChangesMap[address].Change.Actions
+		action := ""
+		switch {
+		case actions.Replace():
+			toAdd++
+			toDestroy++
+			action = "replace"
+		case actions.Create():
+			toAdd++
+			action = "create"
+		case actions.Update():
+			toChange++
+			action = "update"
+		case actions.Delete():
+			toDestroy++
+			action = "delete"
+		}
+		rows = append(rows, fmt.Sprintf("| `%s` | %s |", address, action))
+	}
+
+	summary := fmt.Sprintf("**Plan:** %d to add, %d to change, %d to destroy.", toAdd, toChange, toDestroy)
+	return summary + "\n\n" + strings.Join(rows, "\n")
+}
+
+// StageResults returns a markdown table of the results of the steps of the given scenario report.
+func StageResults(report *scenario.Report) string {
+	outcome := "passed"
+	if report.Failed() {
+		outcome = "failed"
+	}
+
+	rows := []string{"| Stage | Status | Attempts | Duration |", "| --- | --- | --- | --- |"}
+	for _, step := range report.Steps {
+		status := string(step.Status)
+		if step.Err != nil {
+			status = fmt.Sprintf("%s: %s", status, escapeTableCell(step.Err.Error()))
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s | %d | %s |", step.Name, status, step.Attempts, step.Duration.Round(time.Second)))
+	}
+
+	summary := fmt.Sprintf("**Scenario %s** %s in %s.", report.Scenario, outcome, report.Duration.Round(time.Second))
+	return summary + "\n\n" + strings.Join(rows, "\n")
+}
+
+// CostEstimate returns a markdown table of the changes of the monthly cost of the resources in the given estimate.
+func CostEstimate(estimate *terraform.PlanCostEstimate) string {
+	summary := fmt.Sprintf("**Monthly cost:** %+.2f", estimate.MonthlyDelta)
+
+	rows := []string{"| Resource | Action | Before | After | Change |", "| --- | --- | --- | --- | --- |"}
+	for _, change := range estimate.Changes {
+		if change.Delta() == 0 {
+			continue
+		}
+		actions := []string{}
+		for _, action := range change.Actions {
+			actions = append(actions, string(action))
+		}
+		rows = append(rows, fmt.Sprintf("| `%s` | %s | %.2f | %.2f | %+.2f |", change.Address, strings.Join(actions, ", "), change.CostBefore, change.CostAfter, change.Delta()))
+	}
+
+	sections := []string{summary}
+	if len(rows) > 2 {
+		sections = append(sections, strings.Join(rows, "\n"))
+	}
+	if len(estimate.Unpriced) > 0 {
+		sections = append(sections, "Not priced: `"+strings.Join(estimate.Unpriced, "`, `")+"`")
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// escapeTableCell makes the given text fit in a cell of a markdown table.
+func escapeTableCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ").Replace(text)
+}
+
+// truncate shortens the given text to at most the given number of characters.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,87 @@
+package feedback
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// GitHubAPIURL is the URL of the GitHub REST API.
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHubReporter posts comments on a GitHub pull request and statuses on a commit.
+type GitHubReporter struct {
+	Token       string
+	APIURL      string       // Defaults to GitHubAPIURL; set it for GitHub Enterprise Server
+	Repository  string       // The owner and name of the repository, e.g. "gruntwork-io/terratest"
+	PullRequest int          // The number of the pull request to comment on
+	CommitSha   string       // The commit to set statuses on
+	Client      *http.Client // Defaults to a client with a 30 second timeout
+}
+
+// githubComment is a comment on a GitHub issue or pull request, as returned by the API.
+type githubComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// UpsertCommentE posts the given comment on the pull request, or updates the one posted earlier with the same key.
+func (reporter *GitHubReporter) UpsertCommentE(t testing.TestingT, key string, body string) error {
+	if reporter.PullRequest == 0 {
+		return NoPullRequest{}
+	}
+
+	api := reporter.api()
+	marker := commentMarker(key)
+	body = marker + "\n" + body
+
+	// Pull requests are issues as far as comments are concerned
+	comments := []githubComment{}
+	commentsPath := fmt.Sprintf("/repos/%s/issues/%d/comments", reporter.Repository, reporter.PullRequest)
+	if err := api.doE(http.MethodGet, commentsPath+"?per_page=100", nil, &comments); err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, marker) {
+			logger.Logf(t, "Updating comment %s on pull request %d of %s", key, reporter.PullRequest, reporter.Repository)
+			return api.doE(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", reporter.Repository, comment.ID), map[string]string{"body": body}, nil)
+		}
+	}
+
+	logger.Logf(t, "Posting comment %s on pull request %d of %s", key, reporter.PullRequest, reporter.Repository)
+	return api.doE(http.MethodPost, commentsPath, map[string]string{"body": body}, nil)
+}
+
+// SetStatusE sets the given status on the commit.
+func (reporter *GitHubReporter) SetStatusE(t testing.TestingT, status Status) error {
+	logger.Logf(t, "Setting status %s of commit %s of %s to %s", status.Context, reporter.CommitSha, reporter.Repository, status.State)
+
+	payload := map[string]string{
+		"context":     status.Context,
+		"state":       string(status.State),
+		"description": truncate(status.Description, maxStatusDescriptionLength),
+	}
+	if status.TargetURL != "" {
+		payload["target_url"] = status.TargetURL
+	}
+	return reporter.api().doE(http.MethodPost, fmt.Sprintf("/repos/%s/statuses/%s", reporter.Repository, reporter.CommitSha), payload, nil)
+}
+
+func (reporter *GitHubReporter) api() apiClient {
+	baseURL := reporter.APIURL
+	if baseURL == "" {
+		baseURL = GitHubAPIURL
+	}
+	return apiClient{
+		client:  reporter.Client,
+		baseURL: baseURL,
+		headers: map[string]string{
+			"Authorization": "Bearer " + reporter.Token,
+			"Accept":        "application/vnd.github+json",
+		},
+	}
+}
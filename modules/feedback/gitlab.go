@@ -0,0 +1,103 @@
+package feedback
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// GitLabAPIURL is the URL of the GitLab REST API.
+const GitLabAPIURL = "https://gitlab.com/api/v4"
+
+// GitLabReporter posts comments on a GitLab merge request and statuses on a commit.
+type GitLabReporter struct {
+	Token        string
+	APIURL       string       // Defaults to GitLabAPIURL; set it for self-managed GitLab
+	ProjectID    string       // The ID or the URL-encoded path of the project, e.g. "42" or "group%2Fproject"
+	MergeRequest int          // The IID of the merge request to comment on
+	CommitSha    string       // The commit to set statuses on
+	Client       *http.Client // Defaults to a client with a 30 second timeout
+}
+
+// gitlabNote is a comment on a GitLab merge request, as returned by the API.
+type gitlabNote struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// UpsertCommentE posts the given comment on the merge request, or updates the one posted earlier with the same key.
+func (reporter *GitLabReporter) UpsertCommentE(t testing.TestingT, key string, body string) error {
+	if reporter.MergeRequest == 0 {
+		return NoPullRequest{}
+	}
+
+	api := reporter.api()
+	marker := commentMarker(key)
+	body = marker + "\n" + body
+
+	notes := []gitlabNote{}
+	notesPath := fmt.Sprintf("/projects/%s/merge_requests/%d/notes", reporter.projectPath(), reporter.MergeRequest)
+	if err := api.doE(http.MethodGet, notesPath+"?per_page=100", nil, &notes); err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		if strings.Contains(note.Body, marker) {
+			logger.Logf(t, "Updating comment %s on merge request %d of project %s", key, reporter.MergeRequest, reporter.ProjectID)
+			return api.doE(http.MethodPut, fmt.Sprintf("%s/%d", notesPath, note.ID), map[string]string{"body": body}, nil)
+		}
+	}
+
+	logger.Logf(t, "Posting comment %s on merge request %d of project %s", key, reporter.MergeRequest, reporter.ProjectID)
+	return api.doE(http.MethodPost, notesPath, map[string]string{"body": body}, nil)
+}
+
+// SetStatusE sets the given status on the commit, as an external commit status of the pipeline.
+func (reporter *GitLabReporter) SetStatusE(t testing.TestingT, status Status) error {
+	logger.Logf(t, "Setting status %s of commit %s of project %s to %s", status.Context, reporter.CommitSha, reporter.ProjectID, status.State)
+
+	payload := map[string]string{
+		"name":        status.Context,
+		"state":       gitlabState(status.State),
+		"description": truncate(status.Description, maxStatusDescriptionLength),
+	}
+	if status.TargetURL != "" {
+		payload["target_url"] = status.TargetURL
+	}
+	return reporter.api().doE(http.MethodPost, fmt.Sprintf("/projects/%s/statuses/%s", reporter.projectPath(), reporter.CommitSha), payload, nil)
+}
+
+// gitlabState returns the GitLab commit status state for the given state. GitLab has no error state, so errors are
+// reported as failures.
+func gitlabState(state State) string {
+	switch state {
+	case StateFailure, StateError:
+		return "failed"
+	default:
+		return string(state)
+	}
+}
+
+// projectPath returns the project ID to use in API paths, encoding project paths such as "group/project".
+func (reporter *GitLabReporter) projectPath() string {
+	if strings.Contains(reporter.ProjectID, "/") {
+		return url.PathEscape(reporter.ProjectID)
+	}
+	return reporter.ProjectID
+}
+
+func (reporter *GitLabReporter) api() apiClient {
+	baseURL := reporter.APIURL
+	if baseURL == "" {
+		baseURL = GitLabAPIURL
+	}
+	return apiClient{
+		client:  reporter.Client,
+		baseURL: baseURL,
+		headers: map[string]string{"PRIVATE-TOKEN": reporter.Token},
+	}
+}
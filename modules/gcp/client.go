@@ -0,0 +1,79 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gruntwork-io/terratest/modules/ratelimit"
+	"github.com/gruntwork-io/terratest/modules/replay"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// ratelimitServicePrefix is the prefix of the ratelimit services of GCP APIs, e.g. "gcp/compute" or "gcp/storage".
+const ratelimitServicePrefix = "gcp/"
+
+// cloudPlatformScope is the OAuth2 scope that gives access to all the GCP APIs the credentials have access to.
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// newHTTPClientOptionE returns a client option for a GCP API client of the given service, which authenticates with the
+// default credentials and is configured as done by configureHTTPClient.
+func newHTTPClientOptionE(ctx context.Context, service string) (option.ClientOption, error) {
+	client, err := newDefaultHTTPClientE(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	return option.WithHTTPClient(client), nil
+}
+
+// newImpersonatedHTTPClientOptionE returns a client option for a GCP API client of the given service, which
+// authenticates as the given service account by impersonating it with the default credentials. The default credentials
+// need the roles/iam.serviceAccountTokenCreator role on the service account.
+func newImpersonatedHTTPClientOptionE(ctx context.Context, service string, serviceAccountEmail string) (option.ClientOption, error) {
+	client, err := newServiceHTTPClientE(service, func() (*http.Client, error) {
+		tokenSource, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+			TargetPrincipal: serviceAccountEmail,
+			Scopes:          []string{cloudPlatformScope},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return oauth2.NewClient(ctx, tokenSource), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return option.WithHTTPClient(client), nil
+}
+
+// newDefaultHTTPClientE returns an HTTP client for a GCP API client of the given service, which authenticates with the
+// default credentials and is configured as done by configureHTTPClient.
+func newDefaultHTTPClientE(ctx context.Context, service string) (*http.Client, error) {
+	return newServiceHTTPClientE(service, func() (*http.Client, error) {
+		return google.DefaultClient(ctx, cloudPlatformScope)
+	})
+}
+
+// newServiceHTTPClientE returns the HTTP client created by newClient for a GCP API client of the given service,
+// configured as done by configureHTTPClient. Replayed calls don't need credentials, which may not even be available
+// when replaying, so newClient is not called while replaying, and an unauthenticated client is returned instead.
+func newServiceHTTPClientE(service string, newClient func() (*http.Client, error)) (*http.Client, error) {
+	if replay.IsReplaying() {
+		return configureHTTPClient(&http.Client{}, service), nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	return configureHTTPClient(client, service), nil
+}
+
+// configureHTTPClient makes the given HTTP client wait for the ratelimit limit of the given service before each call,
+// retry calls that are throttled, and record or replay its calls if a replay cassette is active.
+func configureHTTPClient(client *http.Client, service string) *http.Client {
+	client.Transport = replay.NewTransport(ratelimit.NewTransport(ratelimitServicePrefix+service, client.Transport))
+	return client
+}
@@ -0,0 +1,126 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	cloudfunctions "google.golang.org/api/cloudfunctions/v1"
+)
+
+// GetCloudFunction returns the Cloud Function with the given name in the given project and region.
+func GetCloudFunction(t testing.TestingT, projectID string, region string, functionName string) *cloudfunctions.CloudFunction {
+	function, err := GetCloudFunctionE(t, projectID, region, functionName)
+	require.NoError(t, err)
+	return function
+}
+
+// GetCloudFunctionE returns the Cloud Function with the given name in the given project and region.
+func GetCloudFunctionE(t testing.TestingT, projectID string, region string, functionName string) (*cloudfunctions.CloudFunction, error) {
+	service, err := NewCloudFunctionsServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("projects/%s/locations/%s/functions/%s", projectID, region, functionName)
+	return service.Projects.Locations.Functions.Get(name).Context(context.Background()).Do()
+}
+
+// WaitForCloudFunctionActive waits until the latest version of the given Cloud Function is deployed and active. This
+// will fail the test if that does not happen within the given number of retries.
+func WaitForCloudFunctionActive(t testing.TestingT, projectID string, region string, functionName string, retries int, sleepBetweenRetries time.Duration) {
+	err := WaitForCloudFunctionActiveE(t, projectID, region, functionName, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// WaitForCloudFunctionActiveE waits until the latest version of the given Cloud Function is deployed and active.
+func WaitForCloudFunctionActiveE(t testing.TestingT, projectID string, region string, functionName string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for Cloud Function %s to become active", functionName)
+
+	_, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		function, err := GetCloudFunctionE(t, projectID, region, functionName)
+		if err != nil {
+			return "", err
+		}
+
+		switch function.Status {
+		case "ACTIVE":
+			return "", nil
+		case "OFFLINE":
+			return "", retry.FatalError{Underlying: fmt.Errorf("Cloud Function %s failed to deploy", functionName)}
+		default:
+			return "", fmt.Errorf("Cloud Function %s is %s", functionName, function.Status)
+		}
+	})
+
+	return err
+}
+
+// InvokeCloudFunction calls the HTTP trigger of the given Cloud Function with an ID token minted for it and returns
+// the status code and body of the response.
+func InvokeCloudFunction(t testing.TestingT, projectID string, region string, functionName string, method string, body io.Reader, headers map[string]string) (int, string) {
+	statusCode, respBody, err := InvokeCloudFunctionE(t, projectID, region, functionName, method, body, headers)
+	require.NoError(t, err)
+	return statusCode, respBody
+}
+
+// InvokeCloudFunctionE calls the HTTP trigger of the given Cloud Function with an ID token minted for it and returns
+// the status code and body of the response.
+func InvokeCloudFunctionE(t testing.TestingT, projectID string, region string, functionName string, method string, body io.Reader, headers map[string]string) (int, string, error) {
+	function, err := GetCloudFunctionE(t, projectID, region, functionName)
+	if err != nil {
+		return -1, "", err
+	}
+
+	if function.HttpsTrigger == nil || function.HttpsTrigger.Url == "" {
+		return -1, "", fmt.Errorf("Cloud Function %s does not have an HTTP trigger", functionName)
+	}
+
+	return InvokeWithIdentityTokenE(t, method, function.HttpsTrigger.Url, body, headers)
+}
+
+// AssertCloudFunctionInvoker checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com" or
+// "allUsers") is allowed to invoke the given Cloud Function.
+func AssertCloudFunctionInvoker(t testing.TestingT, projectID string, region string, functionName string, member string) {
+	err := AssertCloudFunctionInvokerE(t, projectID, region, functionName, member)
+	require.NoError(t, err)
+}
+
+// AssertCloudFunctionInvokerE checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com" or
+// "allUsers") is allowed to invoke the given Cloud Function and returns an error if it is not.
+func AssertCloudFunctionInvokerE(t testing.TestingT, projectID string, region string, functionName string, member string) error {
+	service, err := NewCloudFunctionsServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	resource := fmt.Sprintf("projects/%s/locations/%s/functions/%s", projectID, region, functionName)
+	policy, err := service.Projects.Locations.Functions.GetIamPolicy(resource).Context(context.Background()).Do()
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range policy.Bindings {
+		if binding.Role == cloudFunctionsInvokerRole && containsString(binding.Members, member) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not bound to %s on Cloud Function %s", member, cloudFunctionsInvokerRole, functionName)
+}
+
+// NewCloudFunctionsServiceE creates a new Cloud Functions service, which is used to make Cloud Functions API calls.
+func NewCloudFunctionsServiceE(t testing.TestingT) (*cloudfunctions.Service, error) {
+	ctx := context.Background()
+
+	clientOption, err := newHTTPClientOptionE(ctx, "cloudfunctions")
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudfunctions.NewService(ctx, clientOption)
+}
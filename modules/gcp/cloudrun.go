@@ -0,0 +1,233 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/api/option"
+	run "google.golang.org/api/run/v1"
+)
+
+// The role that allows calling a Cloud Run service or an HTTP-triggered Cloud Function.
+const (
+	cloudRunInvokerRole       = "roles/run.invoker"
+	cloudFunctionsInvokerRole = "roles/cloudfunctions.invoker"
+)
+
+// GetIdentityToken mints a Google-signed OpenID Connect ID token for the given audience (usually the URL of a Cloud
+// Run service or Cloud Function). Minting ID tokens requires the default credentials to be a service account, either
+// through GOOGLE_APPLICATION_CREDENTIALS or the metadata server.
+func GetIdentityToken(t testing.TestingT, audience string) string {
+	token, err := GetIdentityTokenE(t, audience)
+	require.NoError(t, err)
+	return token
+}
+
+// GetIdentityTokenE mints a Google-signed OpenID Connect ID token for the given audience (usually the URL of a Cloud
+// Run service or Cloud Function). Minting ID tokens requires the default credentials to be a service account, either
+// through GOOGLE_APPLICATION_CREDENTIALS or the metadata server.
+func GetIdentityTokenE(t testing.TestingT, audience string) (string, error) {
+	logger.Logf(t, "Minting an ID token for audience %s", audience)
+
+	tokenSource, err := idtoken.NewTokenSource(context.Background(), audience)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := tokenSource.Token()
+	if err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
+
+// InvokeWithIdentityToken performs the given HTTP method on the given URL of a Cloud Run service or Cloud Function,
+// authenticating with an ID token minted for that URL, and returns the status code and body of the response.
+func InvokeWithIdentityToken(t testing.TestingT, method string, url string, body io.Reader, headers map[string]string) (int, string) {
+	statusCode, respBody, err := InvokeWithIdentityTokenE(t, method, url, body, headers)
+	require.NoError(t, err)
+	return statusCode, respBody
+}
+
+// InvokeWithIdentityTokenE performs the given HTTP method on the given URL of a Cloud Run service or Cloud Function,
+// authenticating with an ID token minted for that URL, and returns the status code and body of the response.
+func InvokeWithIdentityTokenE(t testing.TestingT, method string, url string, body io.Reader, headers map[string]string) (int, string, error) {
+	token, err := GetIdentityTokenE(t, url)
+	if err != nil {
+		return -1, "", err
+	}
+
+	allHeaders := map[string]string{"Authorization": fmt.Sprintf("Bearer %s", token)}
+	for key, value := range headers {
+		allHeaders[key] = value
+	}
+
+	return http_helper.HTTPDoE(t, method, url, body, allHeaders, nil)
+}
+
+// GetCloudRunService returns the Cloud Run service with the given name in the given project and region.
+func GetCloudRunService(t testing.TestingT, projectID string, region string, serviceName string) *run.Service {
+	service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+	require.NoError(t, err)
+	return service
+}
+
+// GetCloudRunServiceE returns the Cloud Run service with the given name in the given project and region.
+func GetCloudRunServiceE(t testing.TestingT, projectID string, region string, serviceName string) (*run.Service, error) {
+	client, err := NewCloudRunServiceE(t, region)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("projects/%s/locations/%s/services/%s", projectID, region, serviceName)
+	return client.Projects.Locations.Services.Get(name).Context(context.Background()).Do()
+}
+
+// GetCloudRunServiceUrl returns the URL the Cloud Run service with the given name serves traffic on.
+func GetCloudRunServiceUrl(t testing.TestingT, projectID string, region string, serviceName string) string {
+	url, err := GetCloudRunServiceUrlE(t, projectID, region, serviceName)
+	require.NoError(t, err)
+	return url
+}
+
+// GetCloudRunServiceUrlE returns the URL the Cloud Run service with the given name serves traffic on.
+func GetCloudRunServiceUrlE(t testing.TestingT, projectID string, region string, serviceName string) (string, error) {
+	service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+	if err != nil {
+		return "", err
+	}
+
+	if service.Status == nil || service.Status.Url == "" {
+		return "", fmt.Errorf("Cloud Run service %s in %s does not have a URL yet", serviceName, region)
+	}
+
+	return service.Status.Url, nil
+}
+
+// WaitForCloudRunRevisionServing waits until the latest created revision of the given Cloud Run service is ready and
+// receives 100% of the traffic, and returns the name of that revision. This will fail the test if that does not happen
+// within the given number of retries.
+func WaitForCloudRunRevisionServing(t testing.TestingT, projectID string, region string, serviceName string, retries int, sleepBetweenRetries time.Duration) string {
+	revision, err := WaitForCloudRunRevisionServingE(t, projectID, region, serviceName, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return revision
+}
+
+// WaitForCloudRunRevisionServingE waits until the latest created revision of the given Cloud Run service is ready and
+// receives 100% of the traffic, and returns the name of that revision.
+func WaitForCloudRunRevisionServingE(t testing.TestingT, projectID string, region string, serviceName string, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	description := fmt.Sprintf("Waiting for the latest revision of Cloud Run service %s to serve all traffic", serviceName)
+
+	return retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+		if err != nil {
+			return "", err
+		}
+
+		return checkLatestRevisionServing(service)
+	})
+}
+
+// checkLatestRevisionServing returns the name of the latest created revision of the given Cloud Run service if that
+// revision is ready and receives 100% of the traffic, or an error otherwise.
+func checkLatestRevisionServing(service *run.Service) (string, error) {
+	status := service.Status
+	if status == nil {
+		return "", fmt.Errorf("Cloud Run service has no status yet")
+	}
+
+	latest := status.LatestCreatedRevisionName
+	if latest == "" || status.LatestReadyRevisionName != latest {
+		return "", fmt.Errorf("Latest revision %s is not ready yet (latest ready revision is %s)", latest, status.LatestReadyRevisionName)
+	}
+
+	for _, condition := range status.Conditions {
+		if condition.Type == "Ready" && condition.Status != "True" {
+			return "", fmt.Errorf("Service is not ready: %s", condition.Message)
+		}
+	}
+
+	var percent int64
+	for _, target := range status.Traffic {
+		if target.RevisionName == latest {
+			percent += target.Percent
+		}
+	}
+
+	if percent != 100 {
+		return "", fmt.Errorf("Latest revision %s only receives %d%% of the traffic", latest, percent)
+	}
+
+	return latest, nil
+}
+
+// AssertCloudRunInvoker checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com" or
+// "allUsers") is allowed to invoke the given Cloud Run service.
+func AssertCloudRunInvoker(t testing.TestingT, projectID string, region string, serviceName string, member string) {
+	err := AssertCloudRunInvokerE(t, projectID, region, serviceName, member)
+	require.NoError(t, err)
+}
+
+// AssertCloudRunInvokerE checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com" or
+// "allUsers") is allowed to invoke the given Cloud Run service and returns an error if it is not.
+func AssertCloudRunInvokerE(t testing.TestingT, projectID string, region string, serviceName string, member string) error {
+	client, err := NewCloudRunServiceE(t, region)
+	if err != nil {
+		return err
+	}
+
+	resource := fmt.Sprintf("projects/%s/locations/%s/services/%s", projectID, region, serviceName)
+	policy, err := client.Projects.Locations.Services.GetIamPolicy(resource).Context(context.Background()).Do()
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range policy.Bindings {
+		if binding.Role == cloudRunInvokerRole && containsString(binding.Members, member) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not bound to %s on Cloud Run service %s", member, cloudRunInvokerRole, serviceName)
+}
+
+// NewCloudRunService creates a new Cloud Run service, which is used to make Cloud Run API calls against the regional
+// endpoint of the given region.
+func NewCloudRunService(t testing.TestingT, region string) *run.APIService {
+	client, err := NewCloudRunServiceE(t, region)
+	require.NoError(t, err)
+	return client
+}
+
+// NewCloudRunServiceE creates a new Cloud Run service, which is used to make Cloud Run API calls against the regional
+// endpoint of the given region.
+func NewCloudRunServiceE(t testing.TestingT, region string) (*run.APIService, error) {
+	ctx := context.Background()
+
+	clientOption, err := newHTTPClientOptionE(ctx, "run")
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("https://%s-run.googleapis.com/", region)
+	return run.NewService(ctx, clientOption, option.WithEndpoint(endpoint))
+}
+
+// containsString returns true if the given slice contains the given string.
+func containsString(haystack []string, needle string) bool {
+	for _, item := range haystack {
+		if item == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	run "google.golang.org/api/run/v1"
+)
+
+func TestCheckLatestRevisionServing(t *testing.T) {
+	t.Parallel()
+
+	service := &run.Service{
+		Status: &run.ServiceStatus{
+			LatestCreatedRevisionName: "hello-00002",
+			LatestReadyRevisionName:   "hello-00002",
+			Conditions:                []*run.GoogleCloudRunV1Condition{{Type: "Ready", Status: "True"}},
+			Traffic: []*run.TrafficTarget{
+				{RevisionName: "hello-00002", Percent: 100},
+			},
+		},
+	}
+
+	revision, err := checkLatestRevisionServing(service)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-00002", revision)
+
+	service.Status.Traffic = []*run.TrafficTarget{
+		{RevisionName: "hello-00001", Percent: 50},
+		{RevisionName: "hello-00002", Percent: 50},
+	}
+	_, err = checkLatestRevisionServing(service)
+	assert.Error(t, err)
+
+	service.Status.LatestReadyRevisionName = "hello-00001"
+	_, err = checkLatestRevisionServing(service)
+	assert.Error(t, err)
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/testing"
-	"golang.org/x/oauth2/google"
 )
 
 // Corresponds to a GCP Compute Instance (https://cloud.google.com/compute/docs/instances/)
@@ -596,7 +595,7 @@ func NewComputeServiceE(t testing.TestingT) (*compute.Service, error) {
 	var client *http.Client
 
 	msg, retryErr := retry.DoWithRetryE(t, description, maxRetries, timeBetweenRetries, func() (string, error) {
-		rawClient, err := google.DefaultClient(ctx, compute.CloudPlatformScope)
+		rawClient, err := newDefaultHTTPClientE(ctx, "compute")
 		if err != nil {
 			return "Error retrieving default GCP client", err
 		}
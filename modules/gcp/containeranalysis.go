@@ -0,0 +1,112 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/gruntwork-io/terratest/modules/imagescan"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	containeranalysis "google.golang.org/api/containeranalysis/v1beta1"
+)
+
+// GetImageVulnerabilities returns the vulnerabilities that Container Analysis found in the given GCR or Artifact
+// Registry image, which must be pinned to a digest (e.g. "us-docker.pkg.dev/project/repo/app@sha256:..."). This will
+// fail the test if there is an error.
+func GetImageVulnerabilities(t testing.TestingT, projectID string, image string) []imagescan.Vulnerability {
+	vulnerabilities, err := GetImageVulnerabilitiesE(t, projectID, image)
+	require.NoError(t, err)
+	return vulnerabilities
+}
+
+// GetImageVulnerabilitiesE returns the vulnerabilities that Container Analysis found in the given GCR or Artifact
+// Registry image, which must be pinned to a digest (e.g. "us-docker.pkg.dev/project/repo/app@sha256:...").
+func GetImageVulnerabilitiesE(t testing.TestingT, projectID string, image string) ([]imagescan.Vulnerability, error) {
+	service, err := NewContainerAnalysisServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("kind=\"VULNERABILITY\" AND resourceUrl=\"https://%s\"", image)
+	vulnerabilities := []imagescan.Vulnerability{}
+	err = service.Projects.Occurrences.List(fmt.Sprintf("projects/%s", projectID)).Filter(filter).Pages(context.Background(), func(page *containeranalysis.ListOccurrencesResponse) error {
+		for _, occurrence := range page.Occurrences {
+			vulnerabilities = append(vulnerabilities, newVulnerabilitiesFromOccurrence(occurrence)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vulnerabilities, nil
+}
+
+// AssertNoImageVulnerabilities fails the test if Container Analysis found any vulnerability that violates the given
+// policy in the given GCR or Artifact Registry image, which must be pinned to a digest.
+func AssertNoImageVulnerabilities(t testing.TestingT, projectID string, image string, policy imagescan.Policy) {
+	vulnerabilities := GetImageVulnerabilities(t, projectID, image)
+	imagescan.AssertNoVulnerabilities(t, image, vulnerabilities, policy)
+}
+
+// NewContainerAnalysisServiceE creates a new Container Analysis service, which is used to make Container Analysis API
+// calls.
+func NewContainerAnalysisServiceE(t testing.TestingT) (*containeranalysis.Service, error) {
+	ctx := context.Background()
+
+	clientOption, err := newHTTPClientOptionE(ctx, "containeranalysis")
+	if err != nil {
+		return nil, err
+	}
+
+	return containeranalysis.NewService(ctx, clientOption)
+}
+
+// newVulnerabilitiesFromOccurrence converts a vulnerability occurrence into one vulnerability for each package it
+// affects. The ID is the last segment of the note name, e.g. CVE-2021-3711 for "projects/goog-vulnz/notes/CVE-2021-3711".
+func newVulnerabilitiesFromOccurrence(occurrence *containeranalysis.Occurrence) []imagescan.Vulnerability {
+	details := occurrence.Vulnerability
+	if details == nil {
+		return nil
+	}
+
+	severityName := details.EffectiveSeverity
+	if severityName == "" || severityName == "SEVERITY_UNSPECIFIED" {
+		severityName = details.Severity
+	}
+	base := imagescan.Vulnerability{
+		ID:       path.Base(occurrence.NoteName),
+		Severity: imagescan.ParseSeverity(severityName),
+	}
+
+	if len(details.PackageIssue) == 0 {
+		return []imagescan.Vulnerability{base}
+	}
+
+	vulnerabilities := []imagescan.Vulnerability{}
+	for _, issue := range details.PackageIssue {
+		vulnerability := base
+		if issue.AffectedLocation != nil {
+			vulnerability.Package = issue.AffectedLocation.Package
+			vulnerability.InstalledVersion = containerAnalysisVersionString(issue.AffectedLocation.Version)
+		}
+		if issue.FixedLocation != nil {
+			vulnerability.FixedVersion = containerAnalysisVersionString(issue.FixedLocation.Version)
+		}
+		vulnerabilities = append(vulnerabilities, vulnerability)
+	}
+	return vulnerabilities
+}
+
+// containerAnalysisVersionString returns the given package version as a string, or an empty string if it is missing or
+// is the MAXIMUM version, which Container Analysis uses as the fixed version of a vulnerability that has no fix.
+func containerAnalysisVersionString(version *containeranalysis.Version) string {
+	if version == nil || version.Kind == "MAXIMUM" || version.Name == "" {
+		return ""
+	}
+	if version.Revision != "" {
+		return version.Name + "-" + version.Revision
+	}
+	return version.Name
+}
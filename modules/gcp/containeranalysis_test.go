@@ -0,0 +1,40 @@
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/imagescan"
+	"github.com/stretchr/testify/assert"
+	containeranalysis "google.golang.org/api/containeranalysis/v1beta1"
+)
+
+func TestNewVulnerabilitiesFromOccurrence(t *testing.T) {
+	t.Parallel()
+
+	occurrence := &containeranalysis.Occurrence{
+		NoteName: "projects/goog-vulnz/notes/CVE-2021-3711",
+		Vulnerability: &containeranalysis.GrafeasV1beta1VulnerabilityDetails{
+			EffectiveSeverity: "CRITICAL",
+			Severity:          "HIGH",
+			PackageIssue: []*containeranalysis.PackageIssue{
+				{
+					AffectedLocation: &containeranalysis.VulnerabilityLocation{Package: "openssl", Version: &containeranalysis.Version{Name: "1.1.1d", Revision: "0+deb10u6", Kind: "NORMAL"}},
+					FixedLocation:    &containeranalysis.VulnerabilityLocation{Package: "openssl", Version: &containeranalysis.Version{Name: "1.1.1d", Revision: "0+deb10u7", Kind: "NORMAL"}},
+				},
+				{
+					AffectedLocation: &containeranalysis.VulnerabilityLocation{Package: "libssl1.1", Version: &containeranalysis.Version{Name: "1.1.1d", Kind: "NORMAL"}},
+					FixedLocation:    &containeranalysis.VulnerabilityLocation{Package: "libssl1.1", Version: &containeranalysis.Version{Kind: "MAXIMUM"}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []imagescan.Vulnerability{
+		{ID: "CVE-2021-3711", Severity: imagescan.SeverityCritical, Package: "openssl", InstalledVersion: "1.1.1d-0+deb10u6", FixedVersion: "1.1.1d-0+deb10u7"},
+		{ID: "CVE-2021-3711", Severity: imagescan.SeverityCritical, Package: "libssl1.1", InstalledVersion: "1.1.1d"},
+	}, newVulnerabilitiesFromOccurrence(occurrence))
+}
@@ -6,6 +6,7 @@ import (
 	gcrname "github.com/google/go-containerregistry/pkg/name"
 	gcrgoogle "github.com/google/go-containerregistry/pkg/v1/google"
 	gcrremote "github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
@@ -19,6 +20,10 @@ func DeleteGCRRepo(t testing.TestingT, repo string) {
 
 // DeleteGCRRepoE deletes a GCR repository including all tagged images
 func DeleteGCRRepoE(t testing.TestingT, repo string) error {
+	if dryrun.Skip(t, "delete GCR repo %s", repo) {
+		return nil
+	}
+
 	// create a new auther for the API calls
 	auther, err := gcrgoogle.NewEnvAuthenticator()
 	if err != nil {
@@ -64,6 +69,10 @@ func DeleteGCRImageRef(t testing.TestingT, ref string) {
 
 // DeleteGCRImageRefE deletes a single repo image ref/digest
 func DeleteGCRImageRefE(t testing.TestingT, ref string) error {
+	if dryrun.Skip(t, "delete GCR image %s", ref) {
+		return nil
+	}
+
 	name, err := gcrname.ParseReference(ref)
 	if err != nil {
 		return fmt.Errorf("Failed to parse reference %s. Got error: %v", ref, err)
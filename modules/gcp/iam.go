@@ -0,0 +1,445 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
+	cloudresourcemanagerv2 "google.golang.org/api/cloudresourcemanager/v2"
+	iam "google.golang.org/api/iam/v1"
+)
+
+// testIamPermissionsBatchSize is the maximum number of permissions a single testIamPermissions call accepts.
+const testIamPermissionsBatchSize = 100
+
+// iamPolicyVersion is the IAM policy version to request, which is the only one that includes conditional role bindings.
+const iamPolicyVersion = 3
+
+// workloadIdentityUserRole is the role that allows a Kubernetes service account to act as a GCP service account with
+// workload identity.
+const workloadIdentityUserRole = "roles/iam.workloadIdentityUser"
+
+// IamBinding is a role binding of an IAM policy, independent of the API the policy was fetched with.
+type IamBinding struct {
+	Resource  string   // The resource the policy of the binding is set on, e.g. "projects/my-project" or "folders/123"
+	Role      string   // The role, e.g. "roles/storage.objectViewer"
+	Members   []string // The members, e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com" or "group:admins@example.com"
+	Condition string   // The CEL expression of the condition of the binding, or "" if it is unconditional
+}
+
+// GetMissingProjectPermissions checks which of the given IAM permissions (e.g. "compute.instances.create") the current
+// credentials do not have on the given project, and returns those permissions.
+func GetMissingProjectPermissions(t testing.TestingT, projectID string, permissions []string) []string {
+	missing, err := GetMissingProjectPermissionsE(t, projectID, permissions)
+	require.NoError(t, err)
+	return missing
+}
+
+// GetMissingProjectPermissionsE checks which of the given IAM permissions (e.g. "compute.instances.create") the current
+// credentials do not have on the given project, and returns those permissions.
+func GetMissingProjectPermissionsE(t testing.TestingT, projectID string, permissions []string) ([]string, error) {
+	service, err := NewCloudResourceManagerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return getMissingProjectPermissionsE(service, projectID, permissions)
+}
+
+// GetMissingProjectPermissionsForServiceAccount checks which of the given IAM permissions the given service account does
+// not have on the given project, and returns those permissions.
+func GetMissingProjectPermissionsForServiceAccount(t testing.TestingT, projectID string, serviceAccountEmail string, permissions []string) []string {
+	missing, err := GetMissingProjectPermissionsForServiceAccountE(t, projectID, serviceAccountEmail, permissions)
+	require.NoError(t, err)
+	return missing
+}
+
+// GetMissingProjectPermissionsForServiceAccountE checks which of the given IAM permissions the given service account
+// does not have on the given project, and returns those permissions. This calls testIamPermissions as the service
+// account by impersonating it, so the current credentials need the roles/iam.serviceAccountTokenCreator role on it.
+// Together with asserting that permissions the service account should not have are missing, this tests that a module
+// grants least privilege.
+func GetMissingProjectPermissionsForServiceAccountE(t testing.TestingT, projectID string, serviceAccountEmail string, permissions []string) ([]string, error) {
+	ctx := context.Background()
+
+	clientOption, err := newImpersonatedHTTPClientOptionE(ctx, "cloudresourcemanager", serviceAccountEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := cloudresourcemanager.NewService(ctx, clientOption)
+	if err != nil {
+		return nil, err
+	}
+
+	return getMissingProjectPermissionsE(service, projectID, permissions)
+}
+
+// getMissingProjectPermissionsE checks which of the given IAM permissions the credentials of the given service do not
+// have on the given project, and returns those permissions.
+func getMissingProjectPermissionsE(service *cloudresourcemanager.Service, projectID string, permissions []string) ([]string, error) {
+	granted := map[string]bool{}
+	for start := 0; start < len(permissions); start += testIamPermissionsBatchSize {
+		end := start + testIamPermissionsBatchSize
+		if end > len(permissions) {
+			end = len(permissions)
+		}
+
+		request := &cloudresourcemanager.TestIamPermissionsRequest{Permissions: permissions[start:end]}
+		response, err := service.Projects.TestIamPermissions(projectID, request).Context(context.Background()).Do()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, permission := range response.Permissions {
+			granted[permission] = true
+		}
+	}
+
+	missing := []string{}
+	for _, permission := range permissions {
+		if !granted[permission] {
+			missing = append(missing, permission)
+		}
+	}
+
+	return missing, nil
+}
+
+// GetProjectIamBindings returns the role bindings of the IAM policy of the given project.
+func GetProjectIamBindings(t testing.TestingT, projectID string) []IamBinding {
+	bindings, err := GetProjectIamBindingsE(t, projectID)
+	require.NoError(t, err)
+	return bindings
+}
+
+// GetProjectIamBindingsE returns the role bindings of the IAM policy of the given project.
+func GetProjectIamBindingsE(t testing.TestingT, projectID string) ([]IamBinding, error) {
+	service, err := NewCloudResourceManagerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cloudresourcemanager.GetIamPolicyRequest{
+		Options: &cloudresourcemanager.GetPolicyOptions{RequestedPolicyVersion: iamPolicyVersion},
+	}
+	policy, err := service.Projects.GetIamPolicy(projectID, request).Context(context.Background()).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudResourceManagerIamBindings("projects/"+projectID, policy.Bindings), nil
+}
+
+// GetFolderIamBindings returns the role bindings of the IAM policy of the folder with the given numeric ID.
+func GetFolderIamBindings(t testing.TestingT, folderID string) []IamBinding {
+	bindings, err := GetFolderIamBindingsE(t, folderID)
+	require.NoError(t, err)
+	return bindings
+}
+
+// GetFolderIamBindingsE returns the role bindings of the IAM policy of the folder with the given numeric ID.
+func GetFolderIamBindingsE(t testing.TestingT, folderID string) ([]IamBinding, error) {
+	ctx := context.Background()
+
+	clientOption, err := newHTTPClientOptionE(ctx, "cloudresourcemanager")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := cloudresourcemanagerv2.NewService(ctx, clientOption)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := "folders/" + folderID
+	request := &cloudresourcemanagerv2.GetIamPolicyRequest{
+		Options: &cloudresourcemanagerv2.GetPolicyOptions{RequestedPolicyVersion: iamPolicyVersion},
+	}
+	policy, err := service.Folders.GetIamPolicy(resource, request).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := []IamBinding{}
+	for _, binding := range policy.Bindings {
+		bindings = append(bindings, IamBinding{Resource: resource, Role: binding.Role, Members: binding.Members, Condition: folderConditionExpression(binding.Condition)})
+	}
+	return bindings, nil
+}
+
+// GetOrganizationIamBindings returns the role bindings of the IAM policy of the organization with the given numeric ID.
+func GetOrganizationIamBindings(t testing.TestingT, organizationID string) []IamBinding {
+	bindings, err := GetOrganizationIamBindingsE(t, organizationID)
+	require.NoError(t, err)
+	return bindings
+}
+
+// GetOrganizationIamBindingsE returns the role bindings of the IAM policy of the organization with the given numeric
+// ID.
+func GetOrganizationIamBindingsE(t testing.TestingT, organizationID string) ([]IamBinding, error) {
+	service, err := NewCloudResourceManagerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := "organizations/" + organizationID
+	request := &cloudresourcemanager.GetIamPolicyRequest{
+		Options: &cloudresourcemanager.GetPolicyOptions{RequestedPolicyVersion: iamPolicyVersion},
+	}
+	policy, err := service.Organizations.GetIamPolicy(resource, request).Context(context.Background()).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudResourceManagerIamBindings(resource, policy.Bindings), nil
+}
+
+// GetEffectiveProjectIamBindings returns the role bindings that apply to the given project, i.e. those of its own IAM
+// policy and those inherited from the folders and organization it is in.
+func GetEffectiveProjectIamBindings(t testing.TestingT, projectID string) []IamBinding {
+	bindings, err := GetEffectiveProjectIamBindingsE(t, projectID)
+	require.NoError(t, err)
+	return bindings
+}
+
+// GetEffectiveProjectIamBindingsE returns the role bindings that apply to the given project, i.e. those of its own IAM
+// policy and those inherited from the folders and organization it is in, starting with the project's own. The Resource
+// of each binding says where it is set. Reading the policies of the ancestors needs permissions on them too.
+func GetEffectiveProjectIamBindingsE(t testing.TestingT, projectID string) ([]IamBinding, error) {
+	service, err := NewCloudResourceManagerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestry, err := service.Projects.GetAncestry(projectID, &cloudresourcemanager.GetAncestryRequest{}).Context(context.Background()).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	// The ancestry starts with the project itself and ends with its organization, if any
+	bindings := []IamBinding{}
+	for _, ancestor := range ancestry.Ancestor {
+		var ancestorBindings []IamBinding
+		switch ancestor.ResourceId.Type {
+		case "project":
+			ancestorBindings, err = GetProjectIamBindingsE(t, ancestor.ResourceId.Id)
+		case "folder":
+			ancestorBindings, err = GetFolderIamBindingsE(t, ancestor.ResourceId.Id)
+		case "organization":
+			ancestorBindings, err = GetOrganizationIamBindingsE(t, ancestor.ResourceId.Id)
+		default:
+			err = fmt.Errorf("unknown type %s of ancestor %s of project %s", ancestor.ResourceId.Type, ancestor.ResourceId.Id, projectID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, ancestorBindings...)
+	}
+
+	return bindings, nil
+}
+
+// AssertProjectIamMember checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com") is bound
+// to the given role on the given project, unconditionally or not, and fails the test if it is not.
+func AssertProjectIamMember(t testing.TestingT, projectID string, role string, member string) {
+	err := AssertProjectIamMemberE(t, projectID, role, member)
+	require.NoError(t, err)
+}
+
+// AssertProjectIamMemberE checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com") is bound
+// to the given role on the given project, unconditionally or not, and returns an error if it is not. Bindings inherited
+// from folders and the organization are not considered, use GetEffectiveProjectIamBindingsE for those.
+func AssertProjectIamMemberE(t testing.TestingT, projectID string, role string, member string) error {
+	bindings, err := GetProjectIamBindingsE(t, projectID)
+	if err != nil {
+		return err
+	}
+
+	if !iamBindingsHaveMember(bindings, role, member) {
+		return fmt.Errorf("%s is not bound to %s on project %s", member, role, projectID)
+	}
+
+	return nil
+}
+
+// GetServiceAccountIamBindings returns the role bindings of the IAM policy of the given service account, i.e. who can
+// act as or manage it.
+func GetServiceAccountIamBindings(t testing.TestingT, serviceAccountEmail string) []IamBinding {
+	bindings, err := GetServiceAccountIamBindingsE(t, serviceAccountEmail)
+	require.NoError(t, err)
+	return bindings
+}
+
+// GetServiceAccountIamBindingsE returns the role bindings of the IAM policy of the given service account, i.e. who can
+// act as or manage it.
+func GetServiceAccountIamBindingsE(t testing.TestingT, serviceAccountEmail string) ([]IamBinding, error) {
+	service, err := NewIamServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := serviceAccountResourceName(serviceAccountEmail)
+	policy, err := service.Projects.ServiceAccounts.GetIamPolicy(resource).OptionsRequestedPolicyVersion(iamPolicyVersion).Context(context.Background()).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := []IamBinding{}
+	for _, binding := range policy.Bindings {
+		condition := ""
+		if binding.Condition != nil {
+			condition = binding.Condition.Expression
+		}
+		bindings = append(bindings, IamBinding{Resource: resource, Role: binding.Role, Members: binding.Members, Condition: condition})
+	}
+	return bindings, nil
+}
+
+// AssertWorkloadIdentityBinding checks that the given Kubernetes service account of a GKE cluster in the given project
+// can act as the given GCP service account with workload identity, and fails the test if it can not.
+func AssertWorkloadIdentityBinding(t testing.TestingT, projectID string, namespace string, kubernetesServiceAccount string, serviceAccountEmail string) {
+	err := AssertWorkloadIdentityBindingE(t, projectID, namespace, kubernetesServiceAccount, serviceAccountEmail)
+	require.NoError(t, err)
+}
+
+// AssertWorkloadIdentityBindingE checks that the given Kubernetes service account of a GKE cluster in the given project
+// can act as the given GCP service account with workload identity, i.e. the service account binds the
+// roles/iam.workloadIdentityUser role to the workload identity of the Kubernetes service account, and returns an error
+// if it can not. The project is the one of the cluster, which names its workload identity pool, and can differ from
+// the project of the service account.
+func AssertWorkloadIdentityBindingE(t testing.TestingT, projectID string, namespace string, kubernetesServiceAccount string, serviceAccountEmail string) error {
+	bindings, err := GetServiceAccountIamBindingsE(t, serviceAccountEmail)
+	if err != nil {
+		return err
+	}
+
+	member := workloadIdentityMember(projectID, namespace, kubernetesServiceAccount)
+	if !iamBindingsHaveMember(bindings, workloadIdentityUserRole, member) {
+		return fmt.Errorf("%s is not bound to %s on service account %s", member, workloadIdentityUserRole, serviceAccountEmail)
+	}
+
+	return nil
+}
+
+// GetServiceAccountUserManagedKeys returns the user managed keys of the given service account, i.e. those that were
+// created to use the service account outside of GCP, as opposed to the keys GCP manages and rotates itself.
+func GetServiceAccountUserManagedKeys(t testing.TestingT, serviceAccountEmail string) []*iam.ServiceAccountKey {
+	keys, err := GetServiceAccountUserManagedKeysE(t, serviceAccountEmail)
+	require.NoError(t, err)
+	return keys
+}
+
+// GetServiceAccountUserManagedKeysE returns the user managed keys of the given service account, i.e. those that were
+// created to use the service account outside of GCP, as opposed to the keys GCP manages and rotates itself.
+func GetServiceAccountUserManagedKeysE(t testing.TestingT, serviceAccountEmail string) ([]*iam.ServiceAccountKey, error) {
+	service, err := NewIamServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := service.Projects.ServiceAccounts.Keys.List(serviceAccountResourceName(serviceAccountEmail)).KeyTypes("USER_MANAGED").Context(context.Background()).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Keys, nil
+}
+
+// AssertServiceAccountHasNoUserManagedKeys checks that the given service account has no user managed keys, e.g.
+// because it is only meant to be used with workload identity or impersonation, and fails the test if it has any.
+func AssertServiceAccountHasNoUserManagedKeys(t testing.TestingT, serviceAccountEmail string) {
+	err := AssertServiceAccountHasNoUserManagedKeysE(t, serviceAccountEmail)
+	require.NoError(t, err)
+}
+
+// AssertServiceAccountHasNoUserManagedKeysE checks that the given service account has no user managed keys, e.g.
+// because it is only meant to be used with workload identity or impersonation, and returns an error if it has any.
+func AssertServiceAccountHasNoUserManagedKeysE(t testing.TestingT, serviceAccountEmail string) error {
+	keys, err := GetServiceAccountUserManagedKeysE(t, serviceAccountEmail)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		return fmt.Errorf("service account %s has %d user managed keys", serviceAccountEmail, len(keys))
+	}
+
+	return nil
+}
+
+// NewIamServiceE creates a new IAM service, which is used to make service account API calls.
+func NewIamServiceE(t testing.TestingT) (*iam.Service, error) {
+	ctx := context.Background()
+
+	clientOption, err := newHTTPClientOptionE(ctx, "iam")
+	if err != nil {
+		return nil, err
+	}
+
+	return iam.NewService(ctx, clientOption)
+}
+
+// NewCloudResourceManagerServiceE creates a new Cloud Resource Manager service, which is used to make project API calls.
+func NewCloudResourceManagerServiceE(t testing.TestingT) (*cloudresourcemanager.Service, error) {
+	ctx := context.Background()
+
+	clientOption, err := newHTTPClientOptionE(ctx, "cloudresourcemanager")
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudresourcemanager.NewService(ctx, clientOption)
+}
+
+// cloudResourceManagerIamBindings converts the given role bindings of the IAM policy of the given resource to
+// IamBindings.
+func cloudResourceManagerIamBindings(resource string, bindings []*cloudresourcemanager.Binding) []IamBinding {
+	converted := []IamBinding{}
+	for _, binding := range bindings {
+		condition := ""
+		if binding.Condition != nil {
+			condition = binding.Condition.Expression
+		}
+		converted = append(converted, IamBinding{Resource: resource, Role: binding.Role, Members: binding.Members, Condition: condition})
+	}
+	return converted
+}
+
+// folderConditionExpression returns the CEL expression of the given condition of a folder role binding, or "" if there
+// is none.
+func folderConditionExpression(condition *cloudresourcemanagerv2.Expr) string {
+	if condition == nil {
+		return ""
+	}
+	return condition.Expression
+}
+
+// iamBindingsHaveMember indicates whether any of the given role bindings binds the given role to the given member.
+func iamBindingsHaveMember(bindings []IamBinding, role string, member string) bool {
+	for _, binding := range bindings {
+		if binding.Role != role {
+			continue
+		}
+		for _, bound := range binding.Members {
+			if bound == member {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// workloadIdentityMember returns the IAM member of the workload identity of the given Kubernetes service account of a
+// GKE cluster in the given project.
+func workloadIdentityMember(projectID string, namespace string, kubernetesServiceAccount string) string {
+	return fmt.Sprintf("serviceAccount:%s.svc.id.goog[%s/%s]", projectID, namespace, kubernetesServiceAccount)
+}
+
+// serviceAccountResourceName returns the resource name of the service account with the given email, which is unique
+// across projects.
+func serviceAccountResourceName(serviceAccountEmail string) string {
+	return "projects/-/serviceAccounts/" + serviceAccountEmail
+}
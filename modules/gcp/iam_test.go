@@ -0,0 +1,54 @@
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMissingProjectPermissions(t *testing.T) {
+	t.Parallel()
+
+	projectID := GetGoogleProjectIDFromEnvVar(t)
+
+	// The credentials used to run the tests can always read the project they run in
+	missing := GetMissingProjectPermissions(t, projectID, []string{"resourcemanager.projects.get"})
+	assert.Empty(t, missing)
+}
+
+func TestGetEffectiveProjectIamBindings(t *testing.T) {
+	t.Parallel()
+
+	projectID := GetGoogleProjectIDFromEnvVar(t)
+
+	// The effective bindings start with the project's own
+	projectBindings := GetProjectIamBindings(t, projectID)
+	effectiveBindings := GetEffectiveProjectIamBindings(t, projectID)
+	require.True(t, len(effectiveBindings) >= len(projectBindings))
+	assert.Equal(t, projectBindings, effectiveBindings[:len(projectBindings)])
+}
+
+func TestIamBindingsHaveMember(t *testing.T) {
+	t.Parallel()
+
+	bindings := []IamBinding{
+		{Resource: "projects/my-project", Role: "roles/viewer", Members: []string{"group:admins@example.com"}},
+		{Resource: "projects/my-project", Role: "roles/editor", Members: []string{"serviceAccount:foo@my-project.iam.gserviceaccount.com"}, Condition: "request.time < timestamp('2030-01-01T00:00:00Z')"},
+	}
+
+	assert.True(t, iamBindingsHaveMember(bindings, "roles/viewer", "group:admins@example.com"))
+	assert.True(t, iamBindingsHaveMember(bindings, "roles/editor", "serviceAccount:foo@my-project.iam.gserviceaccount.com"))
+	assert.False(t, iamBindingsHaveMember(bindings, "roles/editor", "group:admins@example.com"))
+	assert.False(t, iamBindingsHaveMember(bindings, "roles/owner", "group:admins@example.com"))
+}
+
+func TestWorkloadIdentityMember(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "serviceAccount:my-project.svc.id.goog[my-namespace/my-ksa]", workloadIdentityMember("my-project", "my-namespace", "my-ksa"))
+}
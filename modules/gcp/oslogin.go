@@ -4,11 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/oauth2/google"
-	"google.golang.org/api/compute/v1"
 	"google.golang.org/api/oslogin/v1"
 )
 
@@ -58,6 +57,10 @@ func DeleteSSHKey(t testing.TestingT, user, key string) {
 // The `user` parameter should be the email address of the user.
 // The `key` parameter should be the public key of the SSH key that was uploaded.
 func DeleteSSHKeyE(t testing.TestingT, user, key string) error {
+	if dryrun.Skip(t, "delete SSH key for user %s", user) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting SSH key for user %s", user)
 
 	ctx := context.Background()
@@ -120,7 +123,7 @@ func GetLoginProfileE(t testing.TestingT, user string) (*oslogin.LoginProfile, e
 func NewOSLoginServiceE(t testing.TestingT) (*oslogin.Service, error) {
 	ctx := context.Background()
 
-	client, err := google.DefaultClient(ctx, compute.CloudPlatformScope)
+	client, err := newDefaultHTTPClientE(ctx, "oslogin")
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get default client: %v", err)
 	}
@@ -0,0 +1,290 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// PubSubMessage is a message received from a Pub/Sub subscription.
+type PubSubMessage struct {
+	ID          string
+	Data        []byte
+	Attributes  map[string]string
+	PublishTime string
+	AckID       string // The ID to use to acknowledge the message or modify its ack deadline
+}
+
+// CreatePubSubTopic creates a Pub/Sub topic with the given name in the given project.
+func CreatePubSubTopic(t testing.TestingT, projectID string, topicName string) {
+	err := CreatePubSubTopicE(t, projectID, topicName)
+	require.NoError(t, err)
+}
+
+// CreatePubSubTopicE creates a Pub/Sub topic with the given name in the given project.
+func CreatePubSubTopicE(t testing.TestingT, projectID string, topicName string) error {
+	logger.Logf(t, "Creating Pub/Sub topic %s in project %s", topicName, projectID)
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.Projects.Topics.Create(pubSubTopicPath(projectID, topicName), &pubsub.Topic{}).Context(context.Background()).Do()
+	return err
+}
+
+// DeletePubSubTopic deletes the Pub/Sub topic with the given name in the given project.
+func DeletePubSubTopic(t testing.TestingT, projectID string, topicName string) {
+	err := DeletePubSubTopicE(t, projectID, topicName)
+	require.NoError(t, err)
+}
+
+// DeletePubSubTopicE deletes the Pub/Sub topic with the given name in the given project.
+func DeletePubSubTopicE(t testing.TestingT, projectID string, topicName string) error {
+	if dryrun.Skip(t, "delete Pub/Sub topic %s in project %s", topicName, projectID) {
+		return nil
+	}
+
+	logger.Logf(t, "Deleting Pub/Sub topic %s in project %s", topicName, projectID)
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.Projects.Topics.Delete(pubSubTopicPath(projectID, topicName)).Context(context.Background()).Do()
+	return err
+}
+
+// CreatePubSubSubscription creates a pull subscription with the given name to the given topic. Messages that are not
+// acknowledged within ackDeadline (between 10 seconds and 10 minutes) are redelivered.
+func CreatePubSubSubscription(t testing.TestingT, projectID string, topicName string, subscriptionName string, ackDeadline time.Duration) {
+	err := CreatePubSubSubscriptionE(t, projectID, topicName, subscriptionName, ackDeadline)
+	require.NoError(t, err)
+}
+
+// CreatePubSubSubscriptionE creates a pull subscription with the given name to the given topic. Messages that are not
+// acknowledged within ackDeadline (between 10 seconds and 10 minutes) are redelivered.
+func CreatePubSubSubscriptionE(t testing.TestingT, projectID string, topicName string, subscriptionName string, ackDeadline time.Duration) error {
+	logger.Logf(t, "Creating Pub/Sub subscription %s to topic %s in project %s", subscriptionName, topicName, projectID)
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	subscription := &pubsub.Subscription{
+		Topic:              pubSubTopicPath(projectID, topicName),
+		AckDeadlineSeconds: int64(ackDeadline.Seconds()),
+	}
+
+	_, err = service.Projects.Subscriptions.Create(pubSubSubscriptionPath(projectID, subscriptionName), subscription).Context(context.Background()).Do()
+	return err
+}
+
+// DeletePubSubSubscription deletes the Pub/Sub subscription with the given name in the given project.
+func DeletePubSubSubscription(t testing.TestingT, projectID string, subscriptionName string) {
+	err := DeletePubSubSubscriptionE(t, projectID, subscriptionName)
+	require.NoError(t, err)
+}
+
+// DeletePubSubSubscriptionE deletes the Pub/Sub subscription with the given name in the given project.
+func DeletePubSubSubscriptionE(t testing.TestingT, projectID string, subscriptionName string) error {
+	if dryrun.Skip(t, "delete Pub/Sub subscription %s in project %s", subscriptionName, projectID) {
+		return nil
+	}
+
+	logger.Logf(t, "Deleting Pub/Sub subscription %s in project %s", subscriptionName, projectID)
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.Projects.Subscriptions.Delete(pubSubSubscriptionPath(projectID, subscriptionName)).Context(context.Background()).Do()
+	return err
+}
+
+// PublishPubSubMessage publishes a message with the given data and attributes to the given topic and returns the ID
+// of the message.
+func PublishPubSubMessage(t testing.TestingT, projectID string, topicName string, data []byte, attributes map[string]string) string {
+	messageID, err := PublishPubSubMessageE(t, projectID, topicName, data, attributes)
+	require.NoError(t, err)
+	return messageID
+}
+
+// PublishPubSubMessageE publishes a message with the given data and attributes to the given topic and returns the ID
+// of the message.
+func PublishPubSubMessageE(t testing.TestingT, projectID string, topicName string, data []byte, attributes map[string]string) (string, error) {
+	logger.Logf(t, "Publishing message to Pub/Sub topic %s in project %s", topicName, projectID)
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return "", err
+	}
+
+	request := &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{
+			{Data: base64.StdEncoding.EncodeToString(data), Attributes: attributes},
+		},
+	}
+
+	resp, err := service.Projects.Topics.Publish(pubSubTopicPath(projectID, topicName), request).Context(context.Background()).Do()
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.MessageIds) != 1 {
+		return "", fmt.Errorf("Expected 1 message ID from Pub/Sub but got %d", len(resp.MessageIds))
+	}
+
+	return resp.MessageIds[0], nil
+}
+
+// PullPubSubMessages pulls up to maxMessages messages from the given subscription without waiting for new messages to
+// arrive. If ack is true, the messages are acknowledged so they are not redelivered.
+func PullPubSubMessages(t testing.TestingT, projectID string, subscriptionName string, maxMessages int64, ack bool) []PubSubMessage {
+	messages, err := PullPubSubMessagesE(t, projectID, subscriptionName, maxMessages, ack)
+	require.NoError(t, err)
+	return messages
+}
+
+// PullPubSubMessagesE pulls up to maxMessages messages from the given subscription without waiting for new messages to
+// arrive. If ack is true, the messages are acknowledged so they are not redelivered.
+func PullPubSubMessagesE(t testing.TestingT, projectID string, subscriptionName string, maxMessages int64, ack bool) ([]PubSubMessage, error) {
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := pubSubSubscriptionPath(projectID, subscriptionName)
+	resp, err := service.Projects.Subscriptions.Pull(subscription, &pubsub.PullRequest{MaxMessages: maxMessages}).Context(context.Background()).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []PubSubMessage{}
+	ackIDs := []string{}
+
+	for _, received := range resp.ReceivedMessages {
+		message, err := newPubSubMessage(received)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+		ackIDs = append(ackIDs, received.AckId)
+	}
+
+	if ack && len(ackIDs) > 0 {
+		if _, err := service.Projects.Subscriptions.Acknowledge(subscription, &pubsub.AcknowledgeRequest{AckIds: ackIDs}).Context(context.Background()).Do(); err != nil {
+			return nil, err
+		}
+	}
+
+	return messages, nil
+}
+
+// WaitForPubSubMessage pulls messages from the given subscription until one of them satisfies the given matcher,
+// acknowledges all pulled messages and returns the matching one. This will fail the test if no matching message
+// arrives within the given number of retries.
+func WaitForPubSubMessage(t testing.TestingT, projectID string, subscriptionName string, retries int, sleepBetweenRetries time.Duration, matcher func(PubSubMessage) bool) PubSubMessage {
+	message, err := WaitForPubSubMessageE(t, projectID, subscriptionName, retries, sleepBetweenRetries, matcher)
+	require.NoError(t, err)
+	return message
+}
+
+// WaitForPubSubMessageE pulls messages from the given subscription until one of them satisfies the given matcher,
+// acknowledges all pulled messages and returns the matching one.
+func WaitForPubSubMessageE(t testing.TestingT, projectID string, subscriptionName string, retries int, sleepBetweenRetries time.Duration, matcher func(PubSubMessage) bool) (PubSubMessage, error) {
+	description := fmt.Sprintf("Waiting for a matching message on Pub/Sub subscription %s", subscriptionName)
+
+	message, err := retry.DoWithRetryInterfaceE(t, description, retries, sleepBetweenRetries, func() (interface{}, error) {
+		messages, err := PullPubSubMessagesE(t, projectID, subscriptionName, 100, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, message := range messages {
+			if matcher(message) {
+				return message, nil
+			}
+		}
+
+		return nil, fmt.Errorf("No matching message received on subscription %s yet", subscriptionName)
+	})
+	if err != nil {
+		return PubSubMessage{}, err
+	}
+
+	return message.(PubSubMessage), nil
+}
+
+// ModifyPubSubAckDeadline changes the ack deadline of the messages with the given ack IDs. Setting it to 0 makes the
+// messages available for redelivery immediately.
+func ModifyPubSubAckDeadline(t testing.TestingT, projectID string, subscriptionName string, ackIDs []string, ackDeadline time.Duration) {
+	err := ModifyPubSubAckDeadlineE(t, projectID, subscriptionName, ackIDs, ackDeadline)
+	require.NoError(t, err)
+}
+
+// ModifyPubSubAckDeadlineE changes the ack deadline of the messages with the given ack IDs. Setting it to 0 makes the
+// messages available for redelivery immediately.
+func ModifyPubSubAckDeadlineE(t testing.TestingT, projectID string, subscriptionName string, ackIDs []string, ackDeadline time.Duration) error {
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	request := &pubsub.ModifyAckDeadlineRequest{
+		AckIds:             ackIDs,
+		AckDeadlineSeconds: int64(ackDeadline.Seconds()),
+		ForceSendFields:    []string{"AckDeadlineSeconds"},
+	}
+
+	_, err = service.Projects.Subscriptions.ModifyAckDeadline(pubSubSubscriptionPath(projectID, subscriptionName), request).Context(context.Background()).Do()
+	return err
+}
+
+// NewPubSubServiceE creates a new Pub/Sub service, which is used to make Pub/Sub API calls.
+func NewPubSubServiceE(t testing.TestingT) (*pubsub.Service, error) {
+	ctx := context.Background()
+
+	clientOption, err := newHTTPClientOptionE(ctx, "pubsub")
+	if err != nil {
+		return nil, err
+	}
+
+	return pubsub.NewService(ctx, clientOption)
+}
+
+// newPubSubMessage converts a message received from the Pub/Sub API into a PubSubMessage, decoding its data.
+func newPubSubMessage(received *pubsub.ReceivedMessage) (PubSubMessage, error) {
+	data, err := base64.StdEncoding.DecodeString(received.Message.Data)
+	if err != nil {
+		return PubSubMessage{}, err
+	}
+
+	return PubSubMessage{
+		ID:          received.Message.MessageId,
+		Data:        data,
+		Attributes:  received.Message.Attributes,
+		PublishTime: received.Message.PublishTime,
+		AckID:       received.AckId,
+	}, nil
+}
+
+func pubSubTopicPath(projectID string, topicName string) string {
+	return fmt.Sprintf("projects/%s/topics/%s", projectID, topicName)
+}
+
+func pubSubSubscriptionPath(projectID string, subscriptionName string) string {
+	return fmt.Sprintf("projects/%s/subscriptions/%s", projectID, subscriptionName)
+}
@@ -0,0 +1,40 @@
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+func TestNewPubSubMessageDecodesData(t *testing.T) {
+	t.Parallel()
+
+	received := &pubsub.ReceivedMessage{
+		AckId: "ack-1",
+		Message: &pubsub.PubsubMessage{
+			MessageId:  "123",
+			Data:       "aGVsbG8=",
+			Attributes: map[string]string{"origin": "terratest"},
+		},
+	}
+
+	message, err := newPubSubMessage(received)
+	require.NoError(t, err)
+	assert.Equal(t, "123", message.ID)
+	assert.Equal(t, "hello", string(message.Data))
+	assert.Equal(t, "ack-1", message.AckID)
+	assert.Equal(t, "terratest", message.Attributes["origin"])
+}
+
+func TestNewPubSubMessageInvalidData(t *testing.T) {
+	t.Parallel()
+
+	_, err := newPubSubMessage(&pubsub.ReceivedMessage{Message: &pubsub.PubsubMessage{Data: "not base64!"}})
+	assert.Error(t, err)
+}
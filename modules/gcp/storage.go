@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
+	"cloud.google.com/go/iam"
 	"cloud.google.com/go/storage"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	"golang.org/x/oauth2/google"
 	"google.golang.org/api/iterator"
 )
 
@@ -26,7 +30,7 @@ func CreateStorageBucketE(t testing.TestingT, projectID string, name string, att
 	ctx := context.Background()
 
 	// Creates a client.
-	client, err := storage.NewClient(ctx)
+	client, err := newStorageClientE(ctx)
 	if err != nil {
 		return err
 	}
@@ -48,11 +52,15 @@ func DeleteStorageBucket(t testing.TestingT, name string) {
 
 // DeleteStorageBucketE destroys the S3 bucket in the given region with the given name.
 func DeleteStorageBucketE(t testing.TestingT, name string) error {
+	if dryrun.Skip(t, "delete storage bucket %s", name) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting bucket %s", name)
 
 	ctx := context.Background()
 
-	client, err := storage.NewClient(ctx)
+	client, err := newStorageClientE(ctx)
 	if err != nil {
 		return err
 	}
@@ -75,7 +83,7 @@ func ReadBucketObjectE(t testing.TestingT, bucketName string, filePath string) (
 
 	ctx := context.Background()
 
-	client, err := storage.NewClient(ctx)
+	client, err := newStorageClientE(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +117,7 @@ func WriteBucketObjectE(t testing.TestingT, bucketName string, filePath string,
 
 	ctx := context.Background()
 
-	client, err := storage.NewClient(ctx)
+	client, err := newStorageClientE(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -143,11 +151,15 @@ func EmptyStorageBucket(t testing.TestingT, name string) {
 
 // EmptyStorageBucketE removes the contents of a storage bucket with the given name.
 func EmptyStorageBucketE(t testing.TestingT, name string) error {
+	if dryrun.Skip(t, "empty storage bucket %s", name) {
+		return nil
+	}
+
 	logger.Logf(t, "Emptying storage bucket %s", name)
 
 	ctx := context.Background()
 
-	client, err := storage.NewClient(ctx)
+	client, err := newStorageClientE(ctx)
 	if err != nil {
 		return err
 	}
@@ -192,7 +204,7 @@ func AssertStorageBucketExistsE(t testing.TestingT, name string) error {
 	ctx := context.Background()
 
 	// Creates a client.
-	client, err := storage.NewClient(ctx)
+	client, err := newStorageClientE(ctx)
 	if err != nil {
 		return err
 	}
@@ -217,3 +229,142 @@ func AssertStorageBucketExistsE(t testing.TestingT, name string) error {
 
 	return nil
 }
+
+// GenerateSignedUrl returns a V4 signed URL that allows anyone who has it to perform the given HTTP method on the given
+// object until the given expiry. Signing requires the default credentials to be a service account key.
+func GenerateSignedUrl(t testing.TestingT, bucketName string, filePath string, method string, expiry time.Duration) string {
+	url, err := GenerateSignedUrlE(t, bucketName, filePath, method, expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return url
+}
+
+// GenerateSignedUrlE returns a V4 signed URL that allows anyone who has it to perform the given HTTP method on the given
+// object until the given expiry. Signing requires the default credentials to be a service account key.
+func GenerateSignedUrlE(t testing.TestingT, bucketName string, filePath string, method string, expiry time.Duration) (string, error) {
+	logger.Logf(t, "Generating signed URL for %s on object %s in bucket %s", method, filePath, bucketName)
+
+	creds, err := google.FindDefaultCredentials(context.Background(), storage.ScopeReadOnly)
+	if err != nil {
+		return "", err
+	}
+
+	if len(creds.JSON) == 0 {
+		return "", fmt.Errorf("Signed URLs can only be generated with service account key credentials")
+	}
+
+	jwtConfig, err := google.JWTConfigFromJSON(creds.JSON)
+	if err != nil {
+		return "", err
+	}
+
+	return storage.SignedURL(bucketName, filePath, &storage.SignedURLOptions{
+		GoogleAccessID: jwtConfig.Email,
+		PrivateKey:     jwtConfig.PrivateKey,
+		Method:         method,
+		Expires:        time.Now().Add(expiry),
+		Scheme:         storage.SigningSchemeV4,
+	})
+}
+
+// AssertStorageBucketIamMember checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com") is
+// bound to the given role on the given bucket and fails the test if it is not.
+func AssertStorageBucketIamMember(t testing.TestingT, bucketName string, role string, member string) {
+	err := AssertStorageBucketIamMemberE(t, bucketName, role, member)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertStorageBucketIamMemberE checks that the given member (e.g. "serviceAccount:foo@bar.iam.gserviceaccount.com") is
+// bound to the given role on the given bucket and returns an error if it is not.
+func AssertStorageBucketIamMemberE(t testing.TestingT, bucketName string, role string, member string) error {
+	ctx := context.Background()
+
+	client, err := newStorageClientE(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy, err := client.Bucket(bucketName).IAM().Policy(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !policy.HasRole(member, iam.RoleName(role)) {
+		return fmt.Errorf("%s is not bound to %s on bucket %s", member, role, bucketName)
+	}
+
+	return nil
+}
+
+// GetStorageBucketLifecycle returns the lifecycle configuration of the given bucket.
+func GetStorageBucketLifecycle(t testing.TestingT, bucketName string) storage.Lifecycle {
+	lifecycle, err := GetStorageBucketLifecycleE(t, bucketName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lifecycle
+}
+
+// GetStorageBucketLifecycleE returns the lifecycle configuration of the given bucket.
+func GetStorageBucketLifecycleE(t testing.TestingT, bucketName string) (storage.Lifecycle, error) {
+	ctx := context.Background()
+
+	client, err := newStorageClientE(ctx)
+	if err != nil {
+		return storage.Lifecycle{}, err
+	}
+
+	attrs, err := client.Bucket(bucketName).Attrs(ctx)
+	if err != nil {
+		return storage.Lifecycle{}, err
+	}
+
+	return attrs.Lifecycle, nil
+}
+
+// AssertStorageBucketDeletesObjectsAfter checks that the given bucket has a lifecycle rule that deletes objects once
+// they are the given number of days old and fails the test if it does not.
+func AssertStorageBucketDeletesObjectsAfter(t testing.TestingT, bucketName string, ageInDays int64) {
+	err := AssertStorageBucketDeletesObjectsAfterE(t, bucketName, ageInDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertStorageBucketDeletesObjectsAfterE checks that the given bucket has a lifecycle rule that deletes objects once
+// they are the given number of days old and returns an error if it does not.
+func AssertStorageBucketDeletesObjectsAfterE(t testing.TestingT, bucketName string, ageInDays int64) error {
+	lifecycle, err := GetStorageBucketLifecycleE(t, bucketName)
+	if err != nil {
+		return err
+	}
+
+	if !hasLifecycleDeleteRule(lifecycle, ageInDays) {
+		return fmt.Errorf("Bucket %s has no lifecycle rule deleting objects after %d days", bucketName, ageInDays)
+	}
+
+	return nil
+}
+
+// hasLifecycleDeleteRule returns true if the given lifecycle configuration deletes objects of the given age.
+func hasLifecycleDeleteRule(lifecycle storage.Lifecycle, ageInDays int64) bool {
+	for _, rule := range lifecycle.Rules {
+		if rule.Action.Type == storage.DeleteAction && rule.Condition.AgeInDays == ageInDays {
+			return true
+		}
+	}
+	return false
+}
+
+// newStorageClientE creates a new Cloud Storage client, which is used to make Cloud Storage API calls.
+func newStorageClientE(ctx context.Context) (*storage.Client, error) {
+	clientOption, err := newHTTPClientOptionE(ctx, "storage")
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewClient(ctx, clientOption)
+}
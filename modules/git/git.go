@@ -95,3 +95,22 @@ func GetTagE(t testing.TestingT) (string, error) {
 	}
 	return strings.TrimSpace(string(bytes)), nil
 }
+
+// GetCurrentCommitSha retrieves the full SHA of the commit that is currently checked out.
+func GetCurrentCommitSha(t testing.TestingT) string {
+	out, err := GetCurrentCommitShaE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// GetCurrentCommitShaE retrieves the full SHA of the commit that is currently checked out.
+func GetCurrentCommitShaE(t testing.TestingT) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	bytes, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(bytes)), nil
+}
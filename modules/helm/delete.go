@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -14,6 +15,10 @@ func Delete(t testing.TestingT, options *Options, releaseName string, purge bool
 // DeleteE will delete the provided release from Tiller. If you set purge to true, Tiller will delete the release object
 // as well so that the release name can be reused.
 func DeleteE(t testing.TestingT, options *Options, releaseName string, purge bool) error {
+	if dryrun.Skip(t, "delete helm release %s", releaseName) {
+		return nil
+	}
+
 	args := []string{}
 	if !purge {
 		args = append(args, "--keep-history")
@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -13,6 +14,10 @@ func Rollback(t testing.TestingT, options *Options, releaseName string, revision
 
 // RollbackE will downgrade the release to the specified version
 func RollbackE(t testing.TestingT, options *Options, releaseName string, revision string) error {
+	if dryrun.Skip(t, "roll back helm release %s to revision %s", releaseName, revision) {
+		return nil
+	}
+
 	var err error
 	args := []string{}
 	if options.ExtraArgs != nil {
@@ -0,0 +1,105 @@
+package http_helper
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// EgressIPServices are the services GetEgressIP asks, in order, for the public IP address of the test runner. Each must
+// respond to a GET request with just the IP address in the body.
+var EgressIPServices = []string{
+	"https://checkip.amazonaws.com",
+	"https://api.ipify.org",
+	"https://ifconfig.me/ip",
+	"https://icanhazip.com",
+}
+
+// egressIPCache holds the egress IP once it has been looked up, as it does not change during a test run and the
+// services are rate limited.
+var egressIPCache struct {
+	sync.Mutex
+	ip string
+}
+
+// GetEgressIP returns the public IP address the test runner connects to the internet from, trying each of
+// EgressIPServices in turn. The result is cached for the lifetime of the test binary. This will fail the test if no
+// service returns a valid IP address.
+func GetEgressIP(t testing.TestingT) string {
+	ip, err := GetEgressIPE(t)
+	require.NoError(t, err)
+	return ip
+}
+
+// GetEgressIPE returns the public IP address the test runner connects to the internet from, trying each of
+// EgressIPServices in turn. The result is cached for the lifetime of the test binary.
+func GetEgressIPE(t testing.TestingT) (string, error) {
+	egressIPCache.Lock()
+	defer egressIPCache.Unlock()
+
+	if egressIPCache.ip != "" {
+		return egressIPCache.ip, nil
+	}
+
+	ip, err := lookupEgressIPE(t, EgressIPServices)
+	if err != nil {
+		return "", err
+	}
+
+	egressIPCache.ip = ip
+	return ip, nil
+}
+
+// GetEgressIPCidr returns the public IP address of the test runner as a single host CIDR block (e.g. 203.0.113.7/32),
+// ready to use in security group and firewall rules. See GetEgressIP. This will fail the test if there is an error.
+func GetEgressIPCidr(t testing.TestingT) string {
+	cidr, err := GetEgressIPCidrE(t)
+	require.NoError(t, err)
+	return cidr
+}
+
+// GetEgressIPCidrE returns the public IP address of the test runner as a single host CIDR block (e.g. 203.0.113.7/32),
+// ready to use in security group and firewall rules. See GetEgressIPE.
+func GetEgressIPCidrE(t testing.TestingT) (string, error) {
+	ip, err := GetEgressIPE(t)
+	if err != nil {
+		return "", err
+	}
+	return hostCidr(ip), nil
+}
+
+// lookupEgressIPE asks each of the given services for the public IP address of the test runner and returns the first
+// valid answer.
+func lookupEgressIPE(t testing.TestingT, services []string) (string, error) {
+	failures := map[string]string{}
+
+	for _, service := range services {
+		statusCode, body, err := HttpGetE(t, service, nil)
+		switch {
+		case err != nil:
+			failures[service] = err.Error()
+		case statusCode != http.StatusOK:
+			failures[service] = fmt.Sprintf("status %d", statusCode)
+		case net.ParseIP(body) == nil:
+			failures[service] = fmt.Sprintf("invalid IP address %q", body)
+		default:
+			logger.Logf(t, "Egress IP of the test runner is %s", body)
+			return body, nil
+		}
+	}
+
+	return "", EgressIPLookupFailed{Failures: failures}
+}
+
+// hostCidr returns the CIDR block that contains only the given IP address.
+func hostCidr(ip string) string {
+	if net.ParseIP(ip).To4() != nil {
+		return ip + "/32"
+	}
+	return ip + "/128"
+}
@@ -0,0 +1,52 @@
+package http_helper
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupEgressIPFallsBackToNextService(t *testing.T) {
+	t.Parallel()
+
+	failing := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer failing.Close()
+	invalid := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>blocked</html>")
+	})
+	defer invalid.Close()
+	working := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "203.0.113.7\n")
+	})
+	defer working.Close()
+
+	ip, err := lookupEgressIPE(t, []string{failing.URL, invalid.URL, working.URL})
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip)
+}
+
+func TestLookupEgressIPFailsWhenAllServicesFail(t *testing.T) {
+	t.Parallel()
+
+	invalid := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "not an ip")
+	})
+	defer invalid.Close()
+
+	_, err := lookupEgressIPE(t, []string{invalid.URL})
+	require.Error(t, err)
+	assert.IsType(t, EgressIPLookupFailed{}, err)
+	assert.Contains(t, err.(EgressIPLookupFailed).Failures[invalid.URL], "not an ip")
+}
+
+func TestHostCidr(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "203.0.113.7/32", hostCidr("203.0.113.7"))
+	assert.Equal(t, "2001:db8::1/128", hostCidr("2001:db8::1"))
+}
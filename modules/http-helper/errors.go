@@ -1,6 +1,9 @@
 package http_helper
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // ValidationFunctionFailed is an error that occurs if a validation function fails.
 type ValidationFunctionFailed struct {
@@ -12,3 +15,24 @@ type ValidationFunctionFailed struct {
 func (err ValidationFunctionFailed) Error() string {
 	return fmt.Sprintf("Validation failed for URL %s. Response status: %d. Response body:\n%s", err.Url, err.Status, err.Body)
 }
+
+// EgressIPLookupFailed is an error that occurs if none of the egress IP services returned a valid IP address.
+type EgressIPLookupFailed struct {
+	Failures map[string]string // The reason each service failed, keyed by its URL
+}
+
+func (err EgressIPLookupFailed) Error() string {
+	return fmt.Sprintf("Unable to determine the egress IP of the test runner: %v", err.Failures)
+}
+
+// ResponseTimeThresholdExceeded is an error that occurs if the 95th percentile response time of a URL is above the
+// threshold.
+type ResponseTimeThresholdExceeded struct {
+	Url       string
+	Threshold time.Duration
+	Stats     ResponseTimeStats
+}
+
+func (err ResponseTimeThresholdExceeded) Error() string {
+	return fmt.Sprintf("p95 response time of %s is %s, above the threshold of %s (%s)", err.Url, err.Stats.P95, err.Threshold, err.Stats)
+}
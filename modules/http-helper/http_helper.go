@@ -300,6 +300,12 @@ func newRequest(method string, url string, body io.Reader, headers map[string]st
 		return nil
 	}
 	for k, v := range headers {
+		// net/http ignores the Host header and uses req.Host instead, so set that to allow routing by host name (e.g. when
+		// calling a load balancer or reverse proxy by IP).
+		if http.CanonicalHeaderKey(k) == "Host" {
+			req.Host = v
+			continue
+		}
 		req.Header.Add(k, v)
 	}
 	return req
@@ -80,6 +80,23 @@ func TestOkHeaders(t *testing.T) {
 	}
 }
 
+func TestHostHeader(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(hostCopyHandler)
+	defer ts.Close()
+	url := ts.URL
+	headers := map[string]string{"Host": "www.example.com"}
+	statusCode, respBody := HTTPDo(t, "GET", url, nil, headers, nil)
+
+	expectedCode := 200
+	if statusCode != expectedCode {
+		t.Errorf("handler returned wrong status code: got %v want %v", statusCode, expectedCode)
+	}
+	if respBody != "www.example.com" {
+		t.Errorf("handler returned wrong body: got %v want %v", respBody, "www.example.com")
+	}
+}
+
 func TestWrongStatus(t *testing.T) {
 	t.Parallel()
 	ts := getTestServerForFunction(wrongStatusHandler)
@@ -154,6 +171,10 @@ func headersCopyHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(buffer.Bytes())
 }
 
+func hostCopyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Host))
+}
+
 func wrongStatusHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusInternalServerError)
 }
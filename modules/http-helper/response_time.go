@@ -0,0 +1,135 @@
+package http_helper
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ResponseTimeStats summarizes the response times measured by MeasureResponseTimes.
+type ResponseTimeStats struct {
+	Samples int
+	Min     time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	P95     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+func (stats ResponseTimeStats) String() string {
+	return fmt.Sprintf("%d samples: min %s, p50 %s, p90 %s, p95 %s, p99 %s, max %s", stats.Samples, stats.Min, stats.P50, stats.P90, stats.P95, stats.P99, stats.Max)
+}
+
+// MeasureResponseTimes sends the given number of warmup GET requests to the given URL, which are discarded so that
+// cold starts and empty caches do not skew the results, followed by the given number of sequential GET requests whose
+// response times it returns as percentiles. This will fail the test if any request fails or responds with an error
+// status.
+func MeasureResponseTimes(t testing.TestingT, url string, samples int, warmup int) ResponseTimeStats {
+	stats, err := MeasureResponseTimesE(t, url, samples, warmup)
+	require.NoError(t, err)
+	return stats
+}
+
+// MeasureResponseTimesE sends the given number of warmup GET requests to the given URL, which are discarded so that
+// cold starts and empty caches do not skew the results, followed by the given number of sequential GET requests whose
+// response times it returns as percentiles. Returns an error if any request fails or responds with an error status.
+func MeasureResponseTimesE(t testing.TestingT, url string, samples int, warmup int) (ResponseTimeStats, error) {
+	if samples <= 0 {
+		return ResponseTimeStats{}, fmt.Errorf("At least one sample is required to measure the response time of %s", url)
+	}
+
+	logger.Logf(t, "Measuring the response time of %s over %d requests after %d warmup requests", url, samples, warmup)
+
+	client := http.Client{
+		// By default, Go does not impose a timeout, so an HTTP connection attempt can hang for a LONG time.
+		Timeout: 10 * time.Second,
+	}
+
+	durations := []time.Duration{}
+	for i := 0; i < warmup+samples; i++ {
+		duration, err := timeGetRequestE(client, url)
+		if err != nil {
+			return ResponseTimeStats{}, err
+		}
+		if i >= warmup {
+			durations = append(durations, duration)
+		}
+	}
+
+	stats := newResponseTimeStats(durations)
+	logger.Logf(t, "Response time of %s: %s", url, stats)
+	return stats, nil
+}
+
+// AssertResponseTimeUnder measures the response times of the given URL as MeasureResponseTimes does, and fails the test
+// if the 95th percentile is above the given threshold. The failure message includes all the percentiles.
+func AssertResponseTimeUnder(t testing.TestingT, url string, p95Threshold time.Duration, samples int, warmup int) {
+	require.NoError(t, AssertResponseTimeUnderE(t, url, p95Threshold, samples, warmup))
+}
+
+// AssertResponseTimeUnderE measures the response times of the given URL as MeasureResponseTimesE does, and returns a
+// ResponseTimeThresholdExceeded error if the 95th percentile is above the given threshold.
+func AssertResponseTimeUnderE(t testing.TestingT, url string, p95Threshold time.Duration, samples int, warmup int) error {
+	stats, err := MeasureResponseTimesE(t, url, samples, warmup)
+	if err != nil {
+		return err
+	}
+
+	if stats.P95 > p95Threshold {
+		return ResponseTimeThresholdExceeded{Url: url, Threshold: p95Threshold, Stats: stats}
+	}
+	return nil
+}
+
+// timeGetRequestE sends a GET request to the given URL and returns how long it took to receive the whole response.
+func timeGetRequestE(client http.Client, url string) (time.Duration, error) {
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		return 0, err
+	}
+	duration := time.Since(start)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, fmt.Errorf("GET %s responded with status %d, so its response time is not meaningful", url, resp.StatusCode)
+	}
+	return duration, nil
+}
+
+// newResponseTimeStats computes the percentiles of the given durations with the nearest-rank method.
+func newResponseTimeStats(durations []time.Duration) ResponseTimeStats {
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+
+	return ResponseTimeStats{
+		Samples: len(sorted),
+		Min:     sorted[0],
+		P50:     percentile(50),
+		P90:     percentile(90),
+		P95:     percentile(95),
+		P99:     percentile(99),
+		Max:     sorted[len(sorted)-1],
+	}
+}
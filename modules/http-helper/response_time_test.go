@@ -0,0 +1,75 @@
+package http_helper
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResponseTimeStats(t *testing.T) {
+	t.Parallel()
+
+	durations := []time.Duration{}
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := newResponseTimeStats(durations)
+	assert.Equal(t, ResponseTimeStats{
+		Samples: 100,
+		Min:     1 * time.Millisecond,
+		P50:     50 * time.Millisecond,
+		P90:     90 * time.Millisecond,
+		P95:     95 * time.Millisecond,
+		P99:     99 * time.Millisecond,
+		Max:     100 * time.Millisecond,
+	}, stats)
+}
+
+func TestAssertResponseTimeUnderDiscardsWarmup(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		// Only the first (warmup) request is slow, like a cold start
+		if atomic.AddInt32(&requests, 1) == 1 {
+			time.Sleep(500 * time.Millisecond)
+		}
+		w.Write([]byte("ok"))
+	})
+	defer ts.Close()
+
+	require.NoError(t, AssertResponseTimeUnderE(t, ts.URL, 250*time.Millisecond, 5, 1))
+	assert.Equal(t, int32(6), atomic.LoadInt32(&requests))
+}
+
+func TestAssertResponseTimeUnderReportsPercentiles(t *testing.T) {
+	t.Parallel()
+
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+	defer ts.Close()
+
+	err := AssertResponseTimeUnderE(t, ts.URL, time.Millisecond, 3, 0)
+	require.Error(t, err)
+	assert.IsType(t, ResponseTimeThresholdExceeded{}, err)
+	assert.Contains(t, err.Error(), "p99")
+}
+
+func TestMeasureResponseTimesFailsOnErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer ts.Close()
+
+	_, err := MeasureResponseTimesE(t, ts.URL, 3, 0)
+	assert.Error(t, err)
+}
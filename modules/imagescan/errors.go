@@ -0,0 +1,36 @@
+package imagescan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VulnerabilitiesFound is an error that occurs when an image has vulnerabilities that violate its policy.
+type VulnerabilitiesFound struct {
+	Image           string
+	Threshold       Severity
+	Vulnerabilities []Vulnerability
+}
+
+func (err VulnerabilitiesFound) Error() string {
+	lines := []string{}
+	for _, vulnerability := range err.Vulnerabilities {
+		fix := "no fix"
+		if vulnerability.FixedVersion != "" {
+			fix = "fixed in " + vulnerability.FixedVersion
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s in %s %s (%s)", vulnerability.Severity, vulnerability.ID, vulnerability.Package, vulnerability.InstalledVersion, fix))
+	}
+	return fmt.Sprintf("Image %s has %d vulnerabilities of severity %s or above:\n%s", err.Image, len(err.Vulnerabilities), err.Threshold, strings.Join(lines, "\n"))
+}
+
+// ScanNotComplete is an error that occurs when the scan of an image by a registry failed or is not supported.
+type ScanNotComplete struct {
+	Image  string
+	Status string
+	Reason string
+}
+
+func (err ScanNotComplete) Error() string {
+	return fmt.Sprintf("Scan of image %s did not complete (status %s): %s", err.Image, err.Status, err.Reason)
+}
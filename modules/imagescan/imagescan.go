@@ -0,0 +1,124 @@
+// Package imagescan gates tests on the vulnerabilities found in the container images deployed by the module under
+// test, whether they come from the scan of a registry such as ECR or Artifact Registry, or from running trivy locally.
+package imagescan
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Severity is the severity of a vulnerability. Severities are ordered, so they can be compared with < and >.
+type Severity int
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityUnknown:  "UNKNOWN",
+	SeverityLow:      "LOW",
+	SeverityMedium:   "MEDIUM",
+	SeverityHigh:     "HIGH",
+	SeverityCritical: "CRITICAL",
+}
+
+func (severity Severity) String() string {
+	return severityNames[severity]
+}
+
+// ParseSeverity converts the severity name reported by a scanner into a Severity. Besides the names of the Severity
+// constants, it understands the aliases used by the various vulnerability databases (e.g. "IMPORTANT" or "MODERATE").
+// Names it does not know, including "INFORMATIONAL", "NEGLIGIBLE" and "UNDEFINED", are SeverityUnknown.
+func ParseSeverity(name string) Severity {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "LOW":
+		return SeverityLow
+	case "MEDIUM", "MODERATE":
+		return SeverityMedium
+	case "HIGH", "IMPORTANT":
+		return SeverityHigh
+	case "CRITICAL":
+		return SeverityCritical
+	default:
+		return SeverityUnknown
+	}
+}
+
+// Vulnerability is a vulnerability found in a package of a container image.
+type Vulnerability struct {
+	ID               string // The CVE or advisory ID, e.g. CVE-2021-3711
+	Severity         Severity
+	Package          string
+	InstalledVersion string
+	FixedVersion     string // Empty if there is no fix
+}
+
+// Policy is the vulnerability policy of an image.
+type Policy struct {
+	// Threshold is the lowest severity that violates the policy, e.g. SeverityHigh to allow LOW and MEDIUM
+	// vulnerabilities. Vulnerabilities of unknown severity always pass.
+	Threshold Severity
+
+	// Allowlist holds the IDs of vulnerabilities that are accepted regardless of their severity, e.g. because they do
+	// not affect the module or have no fix yet.
+	Allowlist []string
+
+	// IgnoreUnfixed accepts vulnerabilities that have no fixed version yet.
+	IgnoreUnfixed bool
+}
+
+// Violations returns the given vulnerabilities that violate the policy, sorted from most to least severe.
+func (policy Policy) Violations(vulnerabilities []Vulnerability) []Vulnerability {
+	allowed := map[string]bool{}
+	for _, id := range policy.Allowlist {
+		allowed[strings.ToUpper(id)] = true
+	}
+
+	violations := []Vulnerability{}
+	for _, vulnerability := range vulnerabilities {
+		if vulnerability.Severity == SeverityUnknown || vulnerability.Severity < policy.Threshold {
+			continue
+		}
+		if allowed[strings.ToUpper(vulnerability.ID)] {
+			continue
+		}
+		if policy.IgnoreUnfixed && vulnerability.FixedVersion == "" {
+			continue
+		}
+		violations = append(violations, vulnerability)
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Severity != violations[j].Severity {
+			return violations[i].Severity > violations[j].Severity
+		}
+		return violations[i].ID < violations[j].ID
+	})
+	return violations
+}
+
+// AssertNoVulnerabilities fails the test if any of the given vulnerabilities, found in the given image, violates the
+// given policy. The failure message lists every violation.
+func AssertNoVulnerabilities(t testing.TestingT, image string, vulnerabilities []Vulnerability, policy Policy) {
+	require.NoError(t, AssertNoVulnerabilitiesE(t, image, vulnerabilities, policy))
+}
+
+// AssertNoVulnerabilitiesE returns a VulnerabilitiesFound error if any of the given vulnerabilities, found in the given
+// image, violates the given policy.
+func AssertNoVulnerabilitiesE(t testing.TestingT, image string, vulnerabilities []Vulnerability, policy Policy) error {
+	violations := policy.Violations(vulnerabilities)
+	logger.Logf(t, "Found %d vulnerabilities in image %s, %d of which are %s or above and not allowed", len(vulnerabilities), image, len(violations), policy.Threshold)
+
+	if len(violations) > 0 {
+		return VulnerabilitiesFound{Image: image, Threshold: policy.Threshold, Vulnerabilities: violations}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package imagescan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, SeverityCritical, ParseSeverity("CRITICAL"))
+	assert.Equal(t, SeverityHigh, ParseSeverity("Important"))
+	assert.Equal(t, SeverityMedium, ParseSeverity("moderate"))
+	assert.Equal(t, SeverityLow, ParseSeverity("LOW"))
+	assert.Equal(t, SeverityUnknown, ParseSeverity("INFORMATIONAL"))
+}
+
+func TestPolicyViolations(t *testing.T) {
+	t.Parallel()
+
+	vulnerabilities := []Vulnerability{
+		{ID: "CVE-2021-0001", Severity: SeverityLow, FixedVersion: "1.0.1"},
+		{ID: "CVE-2021-0002", Severity: SeverityHigh, FixedVersion: "1.0.1"},
+		{ID: "CVE-2021-0003", Severity: SeverityCritical, FixedVersion: "1.0.1"},
+		{ID: "CVE-2021-0004", Severity: SeverityCritical},
+		{ID: "CVE-2021-0005", Severity: SeverityUnknown, FixedVersion: "1.0.1"},
+	}
+
+	policy := Policy{Threshold: SeverityHigh}
+	assert.Equal(t, []Vulnerability{vulnerabilities[2], vulnerabilities[3], vulnerabilities[1]}, policy.Violations(vulnerabilities))
+
+	policy = Policy{Threshold: SeverityHigh, Allowlist: []string{"cve-2021-0003"}, IgnoreUnfixed: true}
+	assert.Equal(t, []Vulnerability{vulnerabilities[1]}, policy.Violations(vulnerabilities))
+}
+
+func TestAssertNoVulnerabilitiesE(t *testing.T) {
+	t.Parallel()
+
+	vulnerabilities := []Vulnerability{
+		{ID: "CVE-2021-3711", Severity: SeverityCritical, Package: "openssl", InstalledVersion: "1.1.1k", FixedVersion: "1.1.1l"},
+	}
+
+	assert.NoError(t, AssertNoVulnerabilitiesE(t, "app:1.0", vulnerabilities, Policy{Threshold: SeverityCritical, Allowlist: []string{"CVE-2021-3711"}}))
+
+	err := AssertNoVulnerabilitiesE(t, "app:1.0", vulnerabilities, Policy{Threshold: SeverityHigh})
+	require.Error(t, err)
+	assert.IsType(t, VulnerabilitiesFound{}, err)
+	assert.Contains(t, err.Error(), "CRITICAL CVE-2021-3711 in openssl 1.1.1k (fixed in 1.1.1l)")
+}
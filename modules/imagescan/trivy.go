@@ -0,0 +1,87 @@
+package imagescan
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// trivyResult is a result of a trivy JSON report, one for each OS or language specific package set in the image.
+type trivyResult struct {
+	Target          string
+	Vulnerabilities []struct {
+		VulnerabilityID  string
+		PkgName          string
+		InstalledVersion string
+		FixedVersion     string
+		Severity         string
+	}
+}
+
+// ScanImageWithTrivy scans the given image with a locally installed trivy and returns the vulnerabilities it found. The
+// image is pulled from its registry with the local credentials unless it is already available locally. This will fail
+// the test if there is an error.
+func ScanImageWithTrivy(t testing.TestingT, image string) []Vulnerability {
+	vulnerabilities, err := ScanImageWithTrivyE(t, image)
+	require.NoError(t, err)
+	return vulnerabilities
+}
+
+// ScanImageWithTrivyE scans the given image with a locally installed trivy and returns the vulnerabilities it found. The
+// image is pulled from its registry with the local credentials unless it is already available locally.
+func ScanImageWithTrivyE(t testing.TestingT, image string) ([]Vulnerability, error) {
+	cmd := shell.Command{
+		Command: "trivy",
+		Args:    []string{"image", "--quiet", "--format", "json", image},
+	}
+
+	output, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTrivyReportE(output)
+}
+
+// AssertNoTrivyVulnerabilities scans the given image with a locally installed trivy and fails the test if it has any
+// vulnerability that violates the given policy.
+func AssertNoTrivyVulnerabilities(t testing.TestingT, image string, policy Policy) {
+	vulnerabilities := ScanImageWithTrivy(t, image)
+	AssertNoVulnerabilities(t, image, vulnerabilities, policy)
+}
+
+// ParseTrivyReportE parses the vulnerabilities out of a trivy JSON report. It supports both the current report format,
+// in which the results are under a "Results" key, and the older one that is just the list of results.
+func ParseTrivyReportE(report string) ([]Vulnerability, error) {
+	var results []trivyResult
+	if strings.HasPrefix(strings.TrimSpace(report), "[") {
+		if err := json.Unmarshal([]byte(report), &results); err != nil {
+			return nil, err
+		}
+	} else {
+		var parsed struct {
+			Results []trivyResult
+		}
+		if err := json.Unmarshal([]byte(report), &parsed); err != nil {
+			return nil, err
+		}
+		results = parsed.Results
+	}
+
+	vulnerabilities := []Vulnerability{}
+	for _, result := range results {
+		for _, vulnerability := range result.Vulnerabilities {
+			vulnerabilities = append(vulnerabilities, Vulnerability{
+				ID:               vulnerability.VulnerabilityID,
+				Severity:         ParseSeverity(vulnerability.Severity),
+				Package:          vulnerability.PkgName,
+				InstalledVersion: vulnerability.InstalledVersion,
+				FixedVersion:     vulnerability.FixedVersion,
+			})
+		}
+	}
+	return vulnerabilities, nil
+}
@@ -0,0 +1,50 @@
+package imagescan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrivyReportE(t *testing.T) {
+	t.Parallel()
+
+	expected := []Vulnerability{
+		{ID: "CVE-2021-3711", Severity: SeverityCritical, Package: "libssl1.1", InstalledVersion: "1.1.1k-r0", FixedVersion: "1.1.1l-r0"},
+		{ID: "CVE-2021-36159", Severity: SeverityHigh, Package: "apk-tools", InstalledVersion: "2.12.5-r0"},
+	}
+
+	report := `{
+  "SchemaVersion": 2,
+  "ArtifactName": "app:1.0",
+  "Results": [
+    {
+      "Target": "app:1.0 (alpine 3.13.5)",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2021-3711", "PkgName": "libssl1.1", "InstalledVersion": "1.1.1k-r0", "FixedVersion": "1.1.1l-r0", "Severity": "CRITICAL"}
+      ]
+    },
+    {"Target": "app/go.sum"},
+    {
+      "Target": "etc",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2021-36159", "PkgName": "apk-tools", "InstalledVersion": "2.12.5-r0", "Severity": "HIGH"}
+      ]
+    }
+  ]
+}`
+	vulnerabilities, err := ParseTrivyReportE(report)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vulnerabilities)
+
+	legacyReport := `[
+  {"Target": "app:1.0 (alpine 3.13.5)", "Vulnerabilities": [
+    {"VulnerabilityID": "CVE-2021-3711", "PkgName": "libssl1.1", "InstalledVersion": "1.1.1k-r0", "FixedVersion": "1.1.1l-r0", "Severity": "CRITICAL"},
+    {"VulnerabilityID": "CVE-2021-36159", "PkgName": "apk-tools", "InstalledVersion": "2.12.5-r0", "Severity": "HIGH"}
+  ]}
+]`
+	vulnerabilities, err = ParseTrivyReportE(legacyReport)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vulnerabilities)
+}
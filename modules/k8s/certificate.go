@@ -0,0 +1,455 @@
+package k8s
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// certManagerAPIPath is the API path of the cert-manager Certificate resources.
+const certManagerAPIPath = "/apis/cert-manager.io/v1"
+
+// CertManagerCertificate is the subset of a cert-manager Certificate resource that the helpers in this file use.
+type CertManagerCertificate struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              CertManagerCertificateSpec   `json:"spec"`
+	Status            CertManagerCertificateStatus `json:"status"`
+}
+
+// CertManagerCertificateSpec is the subset of the spec of a cert-manager Certificate that the helpers in this file use.
+type CertManagerCertificateSpec struct {
+	SecretName string   `json:"secretName"`
+	DNSNames   []string `json:"dnsNames"`
+	Duration   string   `json:"duration"`
+	IssuerRef  struct {
+		Name string `json:"name"`
+		Kind string `json:"kind"`
+	} `json:"issuerRef"`
+}
+
+// CertManagerCertificateStatus is the subset of the status of a cert-manager Certificate that the helpers in this
+// file use.
+type CertManagerCertificateStatus struct {
+	Conditions  []CertManagerCondition `json:"conditions"`
+	NotAfter    *metav1.Time           `json:"notAfter"`
+	RenewalTime *metav1.Time           `json:"renewalTime"`
+	Revision    int                    `json:"revision"`
+}
+
+// CertManagerCondition is a condition of a cert-manager Certificate, e.g. Ready or Issuing.
+type CertManagerCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// Condition returns the condition of the given type, or nil if the Certificate does not have it.
+func (certificate *CertManagerCertificate) Condition(conditionType string) *CertManagerCondition {
+	for i := range certificate.Status.Conditions {
+		if certificate.Status.Conditions[i].Type == conditionType {
+			return &certificate.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsCertificateReady returns true if the given cert-manager Certificate has a Ready condition with status True.
+func IsCertificateReady(certificate *CertManagerCertificate) bool {
+	ready := certificate.Condition("Ready")
+	return ready != nil && ready.Status == "True"
+}
+
+// CertificateExpectations are the properties an issued certificate is checked against by AssertIssuedCertificate.
+// Zero values are not checked.
+type CertificateExpectations struct {
+	DNSNames         []string      // DNS names that must all be in the SANs of the certificate
+	IssuerCommonName string        // The common name of the issuer of the certificate
+	MinDuration      time.Duration // The minimum validity of the certificate
+	MaxDuration      time.Duration // The maximum validity of the certificate
+}
+
+// GetCertManagerCertificate returns the cert-manager Certificate resource with the given name. This will fail the
+// test if there is an error.
+func GetCertManagerCertificate(t testing.TestingT, options *KubectlOptions, certificateName string) *CertManagerCertificate {
+	certificate, err := GetCertManagerCertificateE(t, options, certificateName)
+	require.NoError(t, err)
+	return certificate
+}
+
+// GetCertManagerCertificateE returns the cert-manager Certificate resource with the given name.
+func GetCertManagerCertificateE(t testing.TestingT, options *KubectlOptions, certificateName string) (*CertManagerCertificate, error) {
+	raw, err := getCertManagerCertificateRawE(t, options, certificateName)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := &CertManagerCertificate{}
+	if err := json.Unmarshal(raw, certificate); err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+// WaitUntilCertificateReady waits until the given cert-manager Certificate is Ready, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try. This will fail the test if there
+// is an error or if the check times out.
+func WaitUntilCertificateReady(t testing.TestingT, options *KubectlOptions, certificateName string, retries int, sleepBetweenRetries time.Duration) *CertManagerCertificate {
+	certificate, err := WaitUntilCertificateReadyE(t, options, certificateName, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return certificate
+}
+
+// WaitUntilCertificateReadyE waits until the given cert-manager Certificate is Ready, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try.
+func WaitUntilCertificateReadyE(t testing.TestingT, options *KubectlOptions, certificateName string, retries int, sleepBetweenRetries time.Duration) (*CertManagerCertificate, error) {
+	return waitUntilCertificateRevisionReadyE(t, options, certificateName, 0, retries, sleepBetweenRetries)
+}
+
+// GetIssuedCertificate returns the leaf certificate in the secret of the given cert-manager Certificate. This will fail
+// the test if there is an error.
+func GetIssuedCertificate(t testing.TestingT, options *KubectlOptions, certificateName string) *x509.Certificate {
+	cert, err := GetIssuedCertificateE(t, options, certificateName)
+	require.NoError(t, err)
+	return cert
+}
+
+// GetIssuedCertificateE returns the leaf certificate in the secret of the given cert-manager Certificate.
+func GetIssuedCertificateE(t testing.TestingT, options *KubectlOptions, certificateName string) (*x509.Certificate, error) {
+	certificate, err := GetCertManagerCertificateE(t, options, certificateName)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := GetSecretE(t, options, certificate.Spec.SecretName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLeafCertificate(secret.Data["tls.crt"])
+}
+
+// AssertIssuedCertificate checks that the certificate in the secret of the given cert-manager Certificate matches the
+// given expectations. This will fail the test if there is an error.
+func AssertIssuedCertificate(t testing.TestingT, options *KubectlOptions, certificateName string, expected CertificateExpectations) {
+	require.NoError(t, AssertIssuedCertificateE(t, options, certificateName, expected))
+}
+
+// AssertIssuedCertificateE checks that the certificate in the secret of the given cert-manager Certificate matches the
+// given expectations, returning a CertificateMismatch error listing all the differences otherwise.
+func AssertIssuedCertificateE(t testing.TestingT, options *KubectlOptions, certificateName string, expected CertificateExpectations) error {
+	cert, err := GetIssuedCertificateE(t, options, certificateName)
+	if err != nil {
+		return err
+	}
+
+	if differences := diffCertificate(cert, expected); len(differences) > 0 {
+		return NewCertificateMismatchError(certificateName, differences)
+	}
+	return nil
+}
+
+// RenewCertificate triggers the re-issuance of the given cert-manager Certificate the same way `cmctl renew` does, and
+// returns the revision of the Certificate before the renewal, to pass to WaitUntilCertificateRenewed. This will fail
+// the test if there is an error.
+func RenewCertificate(t testing.TestingT, options *KubectlOptions, certificateName string) int {
+	revision, err := RenewCertificateE(t, options, certificateName)
+	require.NoError(t, err)
+	return revision
+}
+
+// RenewCertificateE triggers the re-issuance of the given cert-manager Certificate the same way `cmctl renew` does, by
+// setting its Issuing condition, and returns the revision of the Certificate before the renewal, to pass to
+// WaitUntilCertificateRenewedE.
+func RenewCertificateE(t testing.TestingT, options *KubectlOptions, certificateName string) (int, error) {
+	raw, err := getCertManagerCertificateRawE(t, options, certificateName)
+	if err != nil {
+		return 0, err
+	}
+
+	certificate := &CertManagerCertificate{}
+	if err := json.Unmarshal(raw, certificate); err != nil {
+		return 0, err
+	}
+	if issuing := certificate.Condition("Issuing"); issuing != nil && issuing.Status == "True" {
+		return 0, fmt.Errorf("Certificate %s is already being issued", certificateName)
+	}
+
+	// Update the raw object rather than the subset we parse, so that no fields are lost
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return 0, err
+	}
+	updated, err := json.Marshal(withManualIssuingCondition(object))
+	if err != nil {
+		return 0, err
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Logf(t, "Triggering the renewal of certificate %s at revision %d", certificateName, certificate.Status.Revision)
+	err = clientset.Discovery().RESTClient().Put().
+		AbsPath(certManagerCertificatePath(options.Namespace, certificateName), "status").
+		SetHeader("Content-Type", "application/json").
+		Body(updated).
+		Do(context.Background()).
+		Error()
+	if err != nil {
+		return 0, err
+	}
+
+	return certificate.Status.Revision, nil
+}
+
+// WaitUntilCertificateRenewed waits until the given cert-manager Certificate is Ready at a revision later than the
+// given one, as returned by RenewCertificate. This will fail the test if there is an error or if the check times out.
+func WaitUntilCertificateRenewed(t testing.TestingT, options *KubectlOptions, certificateName string, previousRevision int, retries int, sleepBetweenRetries time.Duration) *CertManagerCertificate {
+	certificate, err := WaitUntilCertificateRenewedE(t, options, certificateName, previousRevision, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return certificate
+}
+
+// WaitUntilCertificateRenewedE waits until the given cert-manager Certificate is Ready at a revision later than the
+// given one, as returned by RenewCertificateE.
+func WaitUntilCertificateRenewedE(t testing.TestingT, options *KubectlOptions, certificateName string, previousRevision int, retries int, sleepBetweenRetries time.Duration) (*CertManagerCertificate, error) {
+	return waitUntilCertificateRevisionReadyE(t, options, certificateName, previousRevision+1, retries, sleepBetweenRetries)
+}
+
+// WaitUntilCertificateServed waits until the server at the given address (host:port) presents the certificate that is
+// in the secret of the given cert-manager Certificate, e.g. to verify that an Ingress picked up a renewed certificate.
+// The given server name is sent with SNI. This will fail the test if there is an error or if the check times out.
+func WaitUntilCertificateServed(t testing.TestingT, options *KubectlOptions, certificateName string, address string, serverName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilCertificateServedE(t, options, certificateName, address, serverName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilCertificateServedE waits until the server at the given address (host:port) presents the certificate that
+// is in the secret of the given cert-manager Certificate, e.g. to verify that an Ingress picked up a renewed
+// certificate. The given server name is sent with SNI.
+func WaitUntilCertificateServedE(t testing.TestingT, options *KubectlOptions, certificateName string, address string, serverName string, retries int, sleepBetweenRetries time.Duration) error {
+	expected, err := GetIssuedCertificateE(t, options, certificateName)
+	if err != nil {
+		return err
+	}
+
+	statusMsg := fmt.Sprintf("Wait for %s to serve certificate %s with serial %s", address, certificateName, expected.SerialNumber)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		served, err := getServedCertificate(address, serverName)
+		if err != nil {
+			return "", err
+		}
+		if err := compareCertificates(certificateName, expected, served); err != nil {
+			return "", err
+		}
+		return "Certificate is now served", nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// WaitUntilPodHasCertificate waits until the file at the given path in the given container of the given pod holds
+// the certificate that is in the secret of the given cert-manager Certificate, e.g. to verify that a renewed
+// certificate was propagated to the pods that mount its secret. This will fail the test if there is an error or if the
+// check times out.
+func WaitUntilPodHasCertificate(t testing.TestingT, options *KubectlOptions, certificateName string, podName string, containerName string, path string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilPodHasCertificateE(t, options, certificateName, podName, containerName, path, retries, sleepBetweenRetries))
+}
+
+// WaitUntilPodHasCertificateE waits until the file at the given path in the given container of the given pod holds
+// the certificate that is in the secret of the given cert-manager Certificate, e.g. to verify that a renewed
+// certificate was propagated to the pods that mount its secret.
+func WaitUntilPodHasCertificateE(t testing.TestingT, options *KubectlOptions, certificateName string, podName string, containerName string, path string, retries int, sleepBetweenRetries time.Duration) error {
+	expected, err := GetIssuedCertificateE(t, options, certificateName)
+	if err != nil {
+		return err
+	}
+
+	statusMsg := fmt.Sprintf("Wait for pod %s to have certificate %s with serial %s at %s", podName, certificateName, expected.SerialNumber, path)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		output, err := RunKubectlAndGetOutputE(t, options, "exec", podName, "-c", containerName, "--", "cat", path)
+		if err != nil {
+			return "", err
+		}
+		mounted, err := parseLeafCertificate([]byte(output))
+		if err != nil {
+			return "", err
+		}
+		if err := compareCertificates(certificateName, expected, mounted); err != nil {
+			return "", err
+		}
+		return "Certificate is now mounted", nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// waitUntilCertificateRevisionReadyE waits until the given cert-manager Certificate is Ready at the given revision or
+// later.
+func waitUntilCertificateRevisionReadyE(t testing.TestingT, options *KubectlOptions, certificateName string, minRevision int, retries int, sleepBetweenRetries time.Duration) (*CertManagerCertificate, error) {
+	var certificate *CertManagerCertificate
+	statusMsg := fmt.Sprintf("Wait for certificate %s to be ready at revision %d or later.", certificateName, minRevision)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		found, err := GetCertManagerCertificateE(t, options, certificateName)
+		if err != nil {
+			return "", err
+		}
+
+		if !IsCertificateReady(found) {
+			notReady := CertificateNotReady{Name: certificateName}
+			if ready := found.Condition("Ready"); ready != nil {
+				notReady.Reason, notReady.Message = ready.Reason, ready.Message
+			}
+			return "", notReady
+		}
+		if found.Status.Revision < minRevision {
+			return "", fmt.Errorf("Certificate %s is at revision %d", certificateName, found.Status.Revision)
+		}
+
+		certificate = found
+		return "Certificate is now ready", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Logf(t, message)
+	return certificate, nil
+}
+
+// getCertManagerCertificateRawE returns the JSON of the given cert-manager Certificate resource.
+func getCertManagerCertificateRawE(t testing.TestingT, options *KubectlOptions, certificateName string) ([]byte, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// There is no typed client for cert-manager resources in client-go, so use the raw REST client
+	return clientset.Discovery().RESTClient().Get().
+		AbsPath(certManagerCertificatePath(options.Namespace, certificateName)).
+		DoRaw(context.Background())
+}
+
+// certManagerCertificatePath returns the API path of the given cert-manager Certificate resource.
+func certManagerCertificatePath(namespace string, certificateName string) string {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return fmt.Sprintf("%s/namespaces/%s/certificates/%s", certManagerAPIPath, namespace, certificateName)
+}
+
+// withManualIssuingCondition sets the Issuing condition of the given raw Certificate object to True, which makes
+// cert-manager re-issue the certificate.
+func withManualIssuingCondition(object map[string]interface{}) map[string]interface{} {
+	status, _ := object["status"].(map[string]interface{})
+	if status == nil {
+		status = map[string]interface{}{}
+	}
+
+	issuing := map[string]interface{}{
+		"type":               "Issuing",
+		"status":             "True",
+		"reason":             "ManuallyTriggered",
+		"message":            "Certificate re-issuance manually triggered",
+		"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+	}
+
+	conditions := []interface{}{issuing}
+	existing, _ := status["conditions"].([]interface{})
+	for _, condition := range existing {
+		if conditionMap, ok := condition.(map[string]interface{}); ok && conditionMap["type"] == "Issuing" {
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+
+	status["conditions"] = conditions
+	object["status"] = status
+	return object
+}
+
+// parseLeafCertificate parses the first certificate in the given PEM data, which is the leaf certificate in the
+// tls.crt of a cert-manager secret.
+func parseLeafCertificate(pemData []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			return nil, fmt.Errorf("No PEM encoded certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// getServedCertificate returns the leaf certificate presented by the TLS server at the given address. The certificate
+// is not verified, as it is compared with the expected certificate instead.
+func getServedCertificate(address string, serverName string) (*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("Server at %s presented no certificate", address)
+	}
+	return certs[0], nil
+}
+
+// compareCertificates returns a CertificateMismatch error if the given actual certificate is not the expected one.
+func compareCertificates(name string, expected *x509.Certificate, actual *x509.Certificate) error {
+	if expected.SerialNumber.Cmp(actual.SerialNumber) != 0 {
+		return NewCertificateMismatchError(name, []string{fmt.Sprintf("expected serial %s but got %s", expected.SerialNumber, actual.SerialNumber)})
+	}
+	return nil
+}
+
+// diffCertificate returns the differences between the given certificate and the expectations.
+func diffCertificate(cert *x509.Certificate, expected CertificateExpectations) []string {
+	differences := []string{}
+
+	sans := map[string]bool{}
+	for _, name := range cert.DNSNames {
+		sans[name] = true
+	}
+	for _, name := range expected.DNSNames {
+		if !sans[name] {
+			differences = append(differences, fmt.Sprintf("DNS name %s is not in the SANs %v", name, cert.DNSNames))
+		}
+	}
+
+	if expected.IssuerCommonName != "" && cert.Issuer.CommonName != expected.IssuerCommonName {
+		differences = append(differences, fmt.Sprintf("expected issuer %s but got %s", expected.IssuerCommonName, cert.Issuer.CommonName))
+	}
+
+	duration := cert.NotAfter.Sub(cert.NotBefore)
+	if expected.MinDuration != 0 && duration < expected.MinDuration {
+		differences = append(differences, fmt.Sprintf("validity of %s is shorter than %s", duration, expected.MinDuration))
+	}
+	if expected.MaxDuration != 0 && duration > expected.MaxDuration {
+		differences = append(differences, fmt.Sprintf("validity of %s is longer than %s", duration, expected.MaxDuration))
+	}
+
+	return differences
+}
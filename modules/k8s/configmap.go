@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetConfigMap returns a Kubernetes ConfigMap resource in the provided namespace with the given name. The namespace
+// used is the one provided in the KubectlOptions. This will fail the test if there is an error.
+func GetConfigMap(t testing.TestingT, options *KubectlOptions, configMapName string) *corev1.ConfigMap {
+	configMap, err := GetConfigMapE(t, options, configMapName)
+	require.NoError(t, err)
+	return configMap
+}
+
+// GetConfigMapE returns a Kubernetes ConfigMap resource in the provided namespace with the given name. The namespace
+// used is the one provided in the KubectlOptions.
+func GetConfigMapE(t testing.TestingT, options *KubectlOptions, configMapName string) (*corev1.ConfigMap, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().ConfigMaps(options.Namespace).Get(context.Background(), configMapName, metav1.GetOptions{})
+}
+
+// GetConfigMapData returns the data of the Kubernetes ConfigMap with the given name, including the decoded values of
+// binaryData. This will fail the test if there is an error.
+func GetConfigMapData(t testing.TestingT, options *KubectlOptions, configMapName string) map[string]string {
+	data, err := GetConfigMapDataE(t, options, configMapName)
+	require.NoError(t, err)
+	return data
+}
+
+// GetConfigMapDataE returns the data of the Kubernetes ConfigMap with the given name, including the decoded values of
+// binaryData.
+func GetConfigMapDataE(t testing.TestingT, options *KubectlOptions, configMapName string) (map[string]string, error) {
+	configMap, err := GetConfigMapE(t, options, configMapName)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	for key, value := range configMap.Data {
+		data[key] = value
+	}
+	for key, value := range configMap.BinaryData {
+		data[key] = string(value)
+	}
+	return data, nil
+}
+
+// AssertConfigMapData verifies that the Kubernetes ConfigMap with the given name contains the expected keys with the
+// expected values. Keys in the ConfigMap that are not in expected are ignored. This will fail the test if the data does
+// not match or there is an error.
+func AssertConfigMapData(t testing.TestingT, options *KubectlOptions, configMapName string, expected map[string]string) {
+	require.NoError(t, AssertConfigMapDataE(t, options, configMapName, expected))
+}
+
+// AssertConfigMapDataE verifies that the Kubernetes ConfigMap with the given name contains the expected keys with the
+// expected values. Keys in the ConfigMap that are not in expected are ignored.
+func AssertConfigMapDataE(t testing.TestingT, options *KubectlOptions, configMapName string, expected map[string]string) error {
+	data, err := GetConfigMapDataE(t, options, configMapName)
+	if err != nil {
+		return err
+	}
+	differences := diffResourceData(expected, data, false)
+	if len(differences) > 0 {
+		return NewResourceDataMismatchError("ConfigMap", configMapName, differences)
+	}
+	return nil
+}
+
+// WaitUntilConfigMapHasKeys waits until the ConfigMap with the given name exists and contains all the given keys. This
+// will fail the test if the keys do not appear within the given number of retries.
+func WaitUntilConfigMapHasKeys(t testing.TestingT, options *KubectlOptions, configMapName string, keys []string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilConfigMapHasKeysE(t, options, configMapName, keys, retries, sleepBetweenRetries))
+}
+
+// WaitUntilConfigMapHasKeysE waits until the ConfigMap with the given name exists and contains all the given keys.
+func WaitUntilConfigMapHasKeysE(t testing.TestingT, options *KubectlOptions, configMapName string, keys []string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for ConfigMap %s to have keys %v.", configMapName, keys)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			data, err := GetConfigMapDataE(t, options, configMapName)
+			if err != nil {
+				return "", err
+			}
+			if missing := missingResourceKeys(keys, data); len(missing) > 0 {
+				return "", NewResourceKeysMissingError("ConfigMap", configMapName, missing)
+			}
+			return "ConfigMap now has all keys", nil
+		},
+	)
+	if err != nil {
+		logger.Logf(t, "Timed out waiting for ConfigMap %s to have keys: %s", configMapName, err)
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
@@ -1,7 +1,9 @@
 package k8s
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -10,6 +12,23 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// The kinds of errors returned by the functions in this package. Every typed error of this package matches the kind it
+// belongs to with errors.Is, so tests and retry logic can branch on the kind of an error rather than on its message,
+// e.g. errors.Is(err, k8s.ErrNotReady).
+var (
+	// ErrNotReady is the kind of the errors returned when a resource exists but is not available or ready yet.
+	ErrNotReady = errors.New("not ready")
+
+	// ErrNotFound is the kind of the errors returned when an expected resource does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrMismatch is the kind of the errors returned when a resource does not match what the test expects.
+	ErrMismatch = errors.New("mismatch")
+
+	// ErrFailed is the kind of the errors returned when an operation, such as a Velero backup, ends in a failed state.
+	ErrFailed = errors.New("failed")
+)
+
 // IngressNotAvailable is returned when a Kubernetes service is not yet available to accept traffic.
 type IngressNotAvailable struct {
 	ingress *networkingv1.Ingress
@@ -20,6 +39,11 @@ func (err IngressNotAvailable) Error() string {
 	return fmt.Sprintf("Ingress %s is not available", err.ingress.Name)
 }
 
+// Is returns true if the target is ErrNotReady.
+func (err IngressNotAvailable) Is(target error) bool {
+	return target == ErrNotReady
+}
+
 // IngressNotAvailableV1Beta1 is returned when a Kubernetes service is not yet available to accept traffic.
 type IngressNotAvailableV1Beta1 struct {
 	ingress *networkingv1beta1.Ingress
@@ -30,6 +54,11 @@ func (err IngressNotAvailableV1Beta1) Error() string {
 	return fmt.Sprintf("Ingress %s is not available", err.ingress.Name)
 }
 
+// Is returns true if the target is ErrNotReady.
+func (err IngressNotAvailableV1Beta1) Is(target error) bool {
+	return target == ErrNotReady
+}
+
 // UnknownKubeResourceType is returned if the given resource type does not match the list of known resource types.
 type UnknownKubeResourceType struct {
 	ResourceType KubeResourceType
@@ -51,6 +80,11 @@ func (err DesiredNumberOfPodsNotCreated) Error() string {
 	return fmt.Sprintf("Desired number of pods (%d) matching filter %v not yet created", err.DesiredCount, err.Filter)
 }
 
+// Is returns true if the target is ErrNotReady.
+func (err DesiredNumberOfPodsNotCreated) Is(target error) bool {
+	return target == ErrNotReady
+}
+
 // ServiceAccountTokenNotAvailable is returned when a Kubernetes ServiceAccount does not have a token provisioned yet.
 type ServiceAccountTokenNotAvailable struct {
 	Name string
@@ -61,6 +95,11 @@ func (err ServiceAccountTokenNotAvailable) Error() string {
 	return fmt.Sprintf("ServiceAccount %s does not have a token yet.", err.Name)
 }
 
+// Is returns true if the target is ErrNotReady.
+func (err ServiceAccountTokenNotAvailable) Is(target error) bool {
+	return target == ErrNotReady
+}
+
 // PodNotAvailable is returned when a Kubernetes service is not yet available to accept traffic.
 type PodNotAvailable struct {
 	pod *corev1.Pod
@@ -71,6 +110,11 @@ func (err PodNotAvailable) Error() string {
 	return fmt.Sprintf("Pod %s is not available", err.pod.Name)
 }
 
+// Is returns true if the target is ErrNotReady.
+func (err PodNotAvailable) Is(target error) bool {
+	return target == ErrNotReady
+}
+
 // NewPodNotAvailableError returnes a PodNotAvailable struct when Kubernetes deems a pod is not available
 func NewPodNotAvailableError(pod *corev1.Pod) PodNotAvailable {
 	return PodNotAvailable{pod}
@@ -86,6 +130,11 @@ func (err JobNotSucceeded) Error() string {
 	return fmt.Sprintf("Job %s is not Succeeded", err.job.Name)
 }
 
+// Is returns true if the target is ErrNotReady.
+func (err JobNotSucceeded) Is(target error) bool {
+	return target == ErrNotReady
+}
+
 // NewJobNotSucceeded returnes a JobNotSucceeded when the status of the job is not Succeeded
 func NewJobNotSucceeded(job *batchv1.Job) JobNotSucceeded {
 	return JobNotSucceeded{job}
@@ -101,6 +150,11 @@ func (err ServiceNotAvailable) Error() string {
 	return fmt.Sprintf("Service %s is not available", err.service.Name)
 }
 
+// Is returns true if the target is ErrNotReady.
+func (err ServiceNotAvailable) Is(target error) bool {
+	return target == ErrNotReady
+}
+
 // NewServiceNotAvailableError returnes a ServiceNotAvailable struct when Kubernetes deems a service is not available
 func NewServiceNotAvailableError(service *corev1.Service) ServiceNotAvailable {
 	return ServiceNotAvailable{service}
@@ -145,6 +199,11 @@ func (err NoNodesInKubernetes) Error() string {
 	return "There are no nodes in the Kubernetes cluster"
 }
 
+// Is returns true if the target is ErrNotFound.
+func (err NoNodesInKubernetes) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // NewNoNodesInKubernetesError returns a NoNodesInKubernetes struct when it is deemed that there are no Kubernetes nodes registered
 func NewNoNodesInKubernetesError() NoNodesInKubernetes {
 	return NoNodesInKubernetes{}
@@ -189,6 +248,11 @@ func (err JSONPathMalformedJSONErr) Error() string {
 	return fmt.Sprintf("Error unmarshaling original json blob: %s", err.underlyingErr)
 }
 
+// Unwrap returns the underlying error.
+func (err JSONPathMalformedJSONErr) Unwrap() error {
+	return err.underlyingErr
+}
+
 // JSONPathMalformedJSONPathErr is returned when the jsonpath unmarshal routine fails to parse the given JSON path
 // string.
 type JSONPathMalformedJSONPathErr struct {
@@ -199,6 +263,11 @@ func (err JSONPathMalformedJSONPathErr) Error() string {
 	return fmt.Sprintf("Error parsing json path: %s", err.underlyingErr)
 }
 
+// Unwrap returns the underlying error.
+func (err JSONPathMalformedJSONPathErr) Unwrap() error {
+	return err.underlyingErr
+}
+
 // JSONPathExtractJSONPathErr is returned when the jsonpath unmarshal routine fails to extract the given JSON path from
 // the JSON blob.
 type JSONPathExtractJSONPathErr struct {
@@ -209,6 +278,11 @@ func (err JSONPathExtractJSONPathErr) Error() string {
 	return fmt.Sprintf("Error extracting json path from blob: %s", err.underlyingErr)
 }
 
+// Unwrap returns the underlying error.
+func (err JSONPathExtractJSONPathErr) Unwrap() error {
+	return err.underlyingErr
+}
+
 // JSONPathMalformedJSONPathResultErr is returned when the jsonpath unmarshal routine fails to unmarshal the resulting
 // data from extraction.
 type JSONPathMalformedJSONPathResultErr struct {
@@ -218,3 +292,305 @@ type JSONPathMalformedJSONPathResultErr struct {
 func (err JSONPathMalformedJSONPathResultErr) Error() string {
 	return fmt.Sprintf("Error unmarshaling json path output: %s", err.underlyingErr)
 }
+
+// Unwrap returns the underlying error.
+func (err JSONPathMalformedJSONPathResultErr) Unwrap() error {
+	return err.underlyingErr
+}
+
+// ResourceDataMismatch is returned when the data of a Kubernetes Secret or ConfigMap does not match the expected data.
+type ResourceDataMismatch struct {
+	Kind        string
+	Name        string
+	Differences []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err ResourceDataMismatch) Error() string {
+	return fmt.Sprintf("%s %s does not have the expected data:\n  %s", err.Kind, err.Name, strings.Join(err.Differences, "\n  "))
+}
+
+// Is returns true if the target is ErrMismatch.
+func (err ResourceDataMismatch) Is(target error) bool {
+	return target == ErrMismatch
+}
+
+// NewResourceDataMismatchError returns a ResourceDataMismatch struct when the data of a Secret or ConfigMap differs
+// from the expected data
+func NewResourceDataMismatchError(kind string, name string, differences []string) ResourceDataMismatch {
+	return ResourceDataMismatch{kind, name, differences}
+}
+
+// ResourceKeysMissing is returned when a Kubernetes Secret or ConfigMap does not (yet) contain the expected keys.
+type ResourceKeysMissing struct {
+	Kind string
+	Name string
+	Keys []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err ResourceKeysMissing) Error() string {
+	return fmt.Sprintf("%s %s is missing keys %s", err.Kind, err.Name, strings.Join(err.Keys, ", "))
+}
+
+// Is returns true if the target is ErrMismatch.
+func (err ResourceKeysMissing) Is(target error) bool {
+	return target == ErrMismatch
+}
+
+// NewResourceKeysMissingError returns a ResourceKeysMissing struct when a Secret or ConfigMap lacks expected keys
+func NewResourceKeysMissingError(kind string, name string, keys []string) ResourceKeysMissing {
+	return ResourceKeysMissing{kind, name, keys}
+}
+
+// EventNotFound is returned when an expected Kubernetes event was not recorded for an object.
+type EventNotFound struct {
+	Kind   string
+	Name   string
+	Reason string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err EventNotFound) Error() string {
+	return fmt.Sprintf("No event with reason %s found for %s %s", err.Reason, err.Kind, err.Name)
+}
+
+// Is returns true if the target is ErrNotFound.
+func (err EventNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// NewEventNotFoundError returns an EventNotFound struct when an event with the given reason is not found for the object
+func NewEventNotFoundError(involvedObject corev1.ObjectReference, reason string) EventNotFound {
+	return EventNotFound{involvedObject.Kind, involvedObject.Name, reason}
+}
+
+// ResourceUsageExceeded is returned when the containers of a pod use more CPU or memory than allowed.
+type ResourceUsageExceeded struct {
+	PodName    string
+	Violations []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err ResourceUsageExceeded) Error() string {
+	return fmt.Sprintf("Pod %s exceeds its resource bounds:\n  %s", err.PodName, strings.Join(err.Violations, "\n  "))
+}
+
+// NewResourceUsageExceededError returns a ResourceUsageExceeded struct when the usage of a pod exceeds its requests or
+// limits
+func NewResourceUsageExceededError(podName string, violations []string) ResourceUsageExceeded {
+	return ResourceUsageExceeded{podName, violations}
+}
+
+// MeshSidecarMissing is returned when pods that should be part of a service mesh do not have its sidecar proxy.
+type MeshSidecarMissing struct {
+	Mesh     string
+	PodNames []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err MeshSidecarMissing) Error() string {
+	return fmt.Sprintf("The following pods do not have the %s sidecar proxy: %s", err.Mesh, strings.Join(err.PodNames, ", "))
+}
+
+// Is returns true if the target is ErrMismatch.
+func (err MeshSidecarMissing) Is(target error) bool {
+	return target == ErrMismatch
+}
+
+// NewMeshSidecarMissingError returns a MeshSidecarMissing struct when pods are missing the sidecar of the given mesh
+func NewMeshSidecarMissingError(mesh string, podNames []string) MeshSidecarMissing {
+	return MeshSidecarMissing{mesh, podNames}
+}
+
+// PlaintextConnectionAccepted is returned when a plaintext request from outside the mesh got a response, while the
+// mesh should enforce mTLS.
+type PlaintextConnectionAccepted struct {
+	URL        string
+	StatusCode int
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err PlaintextConnectionAccepted) Error() string {
+	return fmt.Sprintf("Plaintext request to %s was accepted with status %d, so mTLS is not enforced", err.URL, err.StatusCode)
+}
+
+// NewPlaintextConnectionAcceptedError returns a PlaintextConnectionAccepted struct when a plaintext request got a
+// response
+func NewPlaintextConnectionAcceptedError(url string, statusCode int) PlaintextConnectionAccepted {
+	return PlaintextConnectionAccepted{url, statusCode}
+}
+
+// CertificateNotReady is returned when a cert-manager Certificate does not have a Ready condition with status True.
+type CertificateNotReady struct {
+	Name    string
+	Reason  string
+	Message string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err CertificateNotReady) Error() string {
+	return fmt.Sprintf("Certificate %s is not ready: %s: %s", err.Name, err.Reason, err.Message)
+}
+
+// Is returns true if the target is ErrNotReady.
+func (err CertificateNotReady) Is(target error) bool {
+	return target == ErrNotReady
+}
+
+// CertificateMismatch is returned when an issued certificate does not match the expectations of a test, or the
+// certificate seen by a consumer is not the one in the secret of a cert-manager Certificate.
+type CertificateMismatch struct {
+	Name        string
+	Differences []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err CertificateMismatch) Error() string {
+	return fmt.Sprintf("Certificate %s does not match:\n  %s", err.Name, strings.Join(err.Differences, "\n  "))
+}
+
+// Is returns true if the target is ErrMismatch.
+func (err CertificateMismatch) Is(target error) bool {
+	return target == ErrMismatch
+}
+
+// NewCertificateMismatchError returns a CertificateMismatch struct when a certificate does not match
+func NewCertificateMismatchError(name string, differences []string) CertificateMismatch {
+	return CertificateMismatch{name, differences}
+}
+
+// NodeDrainBlocked is returned when a node could not be drained because evicting some of its pods would violate their
+// PodDisruptionBudgets.
+type NodeDrainBlocked struct {
+	NodeName string
+	PodNames []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err NodeDrainBlocked) Error() string {
+	return fmt.Sprintf("Draining node %s is blocked by the PodDisruptionBudgets of pods: %s", err.NodeName, strings.Join(err.PodNames, ", "))
+}
+
+// NewNodeDrainBlockedError returns a NodeDrainBlocked struct when the eviction of pods from a node is refused
+func NewNodeDrainBlockedError(nodeName string, podNames []string) NodeDrainBlocked {
+	return NodeDrainBlocked{nodeName, podNames}
+}
+
+// VeleroOperationFailed is returned when a Velero Backup or Restore fails or partially fails.
+type VeleroOperationFailed struct {
+	Kind   string // Backup or Restore
+	Name   string
+	Status VeleroStatus
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err VeleroOperationFailed) Error() string {
+	message := fmt.Sprintf("Velero %s %s is %s with %d errors", err.Kind, err.Name, err.Status.Phase, err.Status.Errors)
+	if err.Status.FailureReason != "" {
+		message += ": " + err.Status.FailureReason
+	}
+	if len(err.Status.ValidationErrors) > 0 {
+		message += ": " + strings.Join(err.Status.ValidationErrors, "; ")
+	}
+	return message
+}
+
+// Is returns true if the target is ErrFailed.
+func (err VeleroOperationFailed) Is(target error) bool {
+	return target == ErrFailed
+}
+
+// NewVeleroOperationFailedError returns a VeleroOperationFailed struct when a Velero Backup or Restore fails
+func NewVeleroOperationFailedError(kind string, name string, status *VeleroStatus) VeleroOperationFailed {
+	return VeleroOperationFailed{kind, name, *status}
+}
+
+// ImageNotPulled is returned when the container of an image pull probe pod has not pulled its image yet.
+type ImageNotPulled struct {
+	pod *corev1.Pod
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err ImageNotPulled) Error() string {
+	return fmt.Sprintf("Pod %s has not pulled its image yet", err.pod.Name)
+}
+
+// Is returns true if the target is ErrNotReady.
+func (err ImageNotPulled) Is(target error) bool {
+	return target == ErrNotReady
+}
+
+// NewImageNotPulledError returns an ImageNotPulled struct when the image of a probe pod is still being pulled
+func NewImageNotPulledError(pod *corev1.Pod) ImageNotPulled {
+	return ImageNotPulled{pod}
+}
+
+// ImagePullFailed is returned when a pod can't pull its image, e.g. because the registry denies access to it.
+type ImagePullFailed struct {
+	PodName string
+	Image   string
+	Reason  string
+	Message string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err ImagePullFailed) Error() string {
+	return fmt.Sprintf("Pod %s failed to pull image %s: %s: %s", err.PodName, err.Image, err.Reason, err.Message)
+}
+
+// Is returns true if the target is ErrFailed.
+func (err ImagePullFailed) Is(target error) bool {
+	return target == ErrFailed
+}
+
+// NewImagePullFailedError returns an ImagePullFailed struct when a container waits because its image can't be pulled
+func NewImagePullFailedError(podName string, image string, waiting *corev1.ContainerStateWaiting) ImagePullFailed {
+	return ImagePullFailed{podName, image, waiting.Reason, waiting.Message}
+}
+
+// WorkloadIdentityMismatch is returned when a pod does not get the expected cloud identity from its service account.
+type WorkloadIdentityMismatch struct {
+	PodName            string
+	ServiceAccountName string
+	Expected           string
+	Actual             string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err WorkloadIdentityMismatch) Error() string {
+	return fmt.Sprintf("Pod %s with service account %s has identity %s, expected %s", err.PodName, err.ServiceAccountName, err.Actual, err.Expected)
+}
+
+// Is returns true if the target is ErrMismatch.
+func (err WorkloadIdentityMismatch) Is(target error) bool {
+	return target == ErrMismatch
+}
+
+// NewWorkloadIdentityMismatchError returns a WorkloadIdentityMismatch struct when a pod has an unexpected identity
+func NewWorkloadIdentityMismatchError(podName string, serviceAccountName string, expected string, actual string) WorkloadIdentityMismatch {
+	return WorkloadIdentityMismatch{podName, serviceAccountName, expected, actual}
+}
+
+// WorkloadIdentityNotIsolated is returned when a control pod without the service account under test gets its cloud
+// identity too, e.g. because it is the identity of the nodes rather than one bound to the service account.
+type WorkloadIdentityNotIsolated struct {
+	PodName  string
+	Identity string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err WorkloadIdentityNotIsolated) Error() string {
+	return fmt.Sprintf("Control pod %s with the default service account also has identity %s", err.PodName, err.Identity)
+}
+
+// Is returns true if the target is ErrMismatch.
+func (err WorkloadIdentityNotIsolated) Is(target error) bool {
+	return target == ErrMismatch
+}
+
+// NewWorkloadIdentityNotIsolatedError returns a WorkloadIdentityNotIsolated struct when a control pod has the identity
+// under test
+func NewWorkloadIdentityNotIsolatedError(podName string, identity string) WorkloadIdentityNotIsolated {
+	return WorkloadIdentityNotIsolated{podName, identity}
+}
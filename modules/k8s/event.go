@@ -0,0 +1,205 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// How long to wait between checks for a new event in WaitForEvent.
+const eventPollInterval = 2 * time.Second
+
+// ListEvents will look for events in the given namespace that match the given filters and return them, oldest first.
+// This will fail the test if there is an error.
+func ListEvents(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []corev1.Event {
+	events, err := ListEventsE(t, options, filters)
+	require.NoError(t, err)
+	return events
+}
+
+// ListEventsE will look for events in the given namespace that match the given filters and return them, oldest first.
+func ListEventsE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]corev1.Event, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clientset.CoreV1().Events(options.Namespace).List(context.Background(), filters)
+	if err != nil {
+		return nil, err
+	}
+	events := resp.Items
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventLastSeen(events[i]).Before(eventLastSeen(events[j]))
+	})
+	return events, nil
+}
+
+// ListEventsForObject returns the events recorded for the given object, oldest first. The Kind and Name of the
+// involvedObject are required; the UID is also matched if set, to ignore events of a previous object with the same name.
+// The namespace used is the one provided in the KubectlOptions. This will fail the test if there is an error.
+func ListEventsForObject(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference) []corev1.Event {
+	events, err := ListEventsForObjectE(t, options, involvedObject)
+	require.NoError(t, err)
+	return events
+}
+
+// ListEventsForObjectE returns the events recorded for the given object, oldest first. The Kind and Name of the
+// involvedObject are required; the UID is also matched if set, to ignore events of a previous object with the same name.
+// The namespace used is the one provided in the KubectlOptions.
+func ListEventsForObjectE(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference) ([]corev1.Event, error) {
+	return ListEventsE(t, options, metav1.ListOptions{FieldSelector: involvedObjectFieldSelector(involvedObject)})
+}
+
+// WaitForEvent waits until an event with the given reason (e.g. "Scheduled", "Pulled" or "BackOff") is recorded for the
+// given object and returns it, checking every few seconds until the timeout expires. If no such event is recorded, the
+// events of the object are logged to help debug the failure, and the test is failed.
+func WaitForEvent(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference, reason string, timeout time.Duration) *corev1.Event {
+	event, err := WaitForEventE(t, options, involvedObject, reason, timeout)
+	require.NoError(t, err)
+	return event
+}
+
+// WaitForEventE waits until an event with the given reason (e.g. "Scheduled", "Pulled" or "BackOff") is recorded for
+// the given object and returns it, checking every few seconds until the timeout expires. If no such event is recorded,
+// the events of the object are logged to help debug the failure.
+func WaitForEventE(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference, reason string, timeout time.Duration) (*corev1.Event, error) {
+	var found *corev1.Event
+	maxRetries := int(timeout.Seconds() / eventPollInterval.Seconds())
+	statusMsg := fmt.Sprintf("Wait for event %s on %s %s.", reason, involvedObject.Kind, involvedObject.Name)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		maxRetries,
+		eventPollInterval,
+		func() (string, error) {
+			events, err := ListEventsForObjectE(t, options, involvedObject)
+			if err != nil {
+				return "", err
+			}
+			found = findEventWithReason(events, reason)
+			if found == nil {
+				return "", NewEventNotFoundError(involvedObject, reason)
+			}
+			return fmt.Sprintf("Found event %s: %s", reason, found.Message), nil
+		},
+	)
+	if err != nil {
+		logger.Logf(t, "Timed out waiting for event %s on %s %s: %s", reason, involvedObject.Kind, involvedObject.Name, err)
+		logEventsForFailure(t, options, metav1.ListOptions{FieldSelector: involvedObjectFieldSelector(involvedObject)})
+		return nil, err
+	}
+	logger.Logf(t, message)
+	return found, nil
+}
+
+// LogEvents logs all the events in the namespace provided in the KubectlOptions in the same form as
+// `kubectl get events`. This will fail the test if there is an error.
+func LogEvents(t testing.TestingT, options *KubectlOptions) {
+	require.NoError(t, LogEventsE(t, options))
+}
+
+// LogEventsE logs all the events in the namespace provided in the KubectlOptions in the same form as
+// `kubectl get events`.
+func LogEventsE(t testing.TestingT, options *KubectlOptions) error {
+	events, err := ListEventsE(t, options, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, "Events in namespace %s:\n%s", options.Namespace, formatEvents(events))
+	return nil
+}
+
+// LogEventsOnFailure logs all the events in the namespace provided in the KubectlOptions if the test has failed. It is
+// meant to be deferred at the start of a test, right after the namespace is created, e.g.:
+//
+//	defer k8s.LogEventsOnFailure(t, options)
+//
+// Since it usually runs while the test is already failing, errors listing the events are logged instead of failing the
+// test again. Nothing is logged if t does not implement Failed() bool, as testing.T does.
+func LogEventsOnFailure(t testing.TestingT, options *KubectlOptions) {
+	failed, ok := t.(interface{ Failed() bool })
+	if !ok || !failed.Failed() {
+		return
+	}
+	logEventsForFailure(t, options, metav1.ListOptions{})
+}
+
+// logEventsForFailure logs the events matching the given filters, logging instead of returning any error since it is
+// only used to add context to a failure that is already being reported.
+func logEventsForFailure(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) {
+	events, err := ListEventsE(t, options, filters)
+	if err != nil {
+		logger.Logf(t, "Failed to list events in namespace %s: %s", options.Namespace, err)
+		return
+	}
+	logger.Logf(t, "Events in namespace %s:\n%s", options.Namespace, formatEvents(events))
+}
+
+// involvedObjectFieldSelector returns the field selector that matches the events of the given object.
+func involvedObjectFieldSelector(involvedObject corev1.ObjectReference) string {
+	selectors := []fields.Selector{
+		fields.OneTermEqualSelector("involvedObject.kind", involvedObject.Kind),
+		fields.OneTermEqualSelector("involvedObject.name", involvedObject.Name),
+	}
+	if involvedObject.UID != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.uid", string(involvedObject.UID)))
+	}
+	return fields.AndSelectors(selectors...).String()
+}
+
+// findEventWithReason returns the most recent of the given events (sorted oldest first) with the given reason, or nil
+// if there is none.
+func findEventWithReason(events []corev1.Event, reason string) *corev1.Event {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Reason == reason {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+// eventLastSeen returns when the given event was last observed, falling back to the fields set by newer event
+// recorders that do not populate LastTimestamp.
+func eventLastSeen(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// formatEvents renders the given events as a table similar to the output of `kubectl get events`.
+func formatEvents(events []corev1.Event) string {
+	if len(events) == 0 {
+		return "No events found."
+	}
+
+	lines := []string{"LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE"}
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf(
+			"%s\t%s\t%s\t%s/%s\t%s",
+			eventLastSeen(event).UTC().Format(time.RFC3339),
+			event.Type,
+			event.Reason,
+			strings.ToLower(event.InvolvedObject.Kind),
+			event.InvolvedObject.Name,
+			strings.TrimSpace(event.Message),
+		))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,45 @@
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+func TestWaitForEventFindsScheduledEvent(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(EXAMPLE_POD_YAML_TEMPLATE, uniqueID, uniqueID)
+	defer KubectlDeleteFromString(t, options, configData)
+	defer LogEventsOnFailure(t, options)
+	KubectlApplyFromString(t, options, configData)
+
+	pod := corev1.ObjectReference{Kind: "Pod", Name: "nginx-pod"}
+	event := WaitForEvent(t, options, pod, "Scheduled", 1*time.Minute)
+	require.Equal(t, "nginx-pod", event.InvolvedObject.Name)
+	require.NotEmpty(t, ListEventsForObject(t, options, pod))
+}
+
+func TestWaitForEventEReturnsErrorForMissingEvent(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "default")
+	_, err := WaitForEventE(t, options, corev1.ObjectReference{Kind: "Pod", Name: "does-not-exist"}, "Scheduled", 4*time.Second)
+	require.Error(t, err)
+}
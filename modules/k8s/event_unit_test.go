@@ -0,0 +1,45 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInvolvedObjectFieldSelector(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "involvedObject.kind=Pod,involvedObject.name=nginx", involvedObjectFieldSelector(corev1.ObjectReference{Kind: "Pod", Name: "nginx"}))
+	require.Equal(t, "involvedObject.kind=Pod,involvedObject.name=nginx,involvedObject.uid=1234", involvedObjectFieldSelector(corev1.ObjectReference{Kind: "Pod", Name: "nginx", UID: "1234"}))
+}
+
+func TestFindEventWithReasonReturnsMostRecent(t *testing.T) {
+	t.Parallel()
+
+	events := []corev1.Event{
+		{Reason: "BackOff", Message: "first"},
+		{Reason: "Pulled"},
+		{Reason: "BackOff", Message: "second"},
+	}
+	require.Equal(t, "second", findEventWithReason(events, "BackOff").Message)
+	require.Nil(t, findEventWithReason(events, "Scheduled"))
+}
+
+func TestFormatEvents(t *testing.T) {
+	t.Parallel()
+
+	lastSeen := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	events := []corev1.Event{{
+		LastTimestamp:  lastSeen,
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "nginx"},
+		Message:        "Back-off restarting failed container\n",
+	}}
+
+	require.Equal(t, "LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n2020-01-02T03:04:05Z\tWarning\tBackOff\tpod/nginx\tBack-off restarting failed container", formatEvents(events))
+	require.Equal(t, "No events found.", formatEvents(nil))
+}
@@ -0,0 +1,141 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const imagePullProbeContainerName = "probe"
+
+// The reasons a container waits with when pulling its image failed in a way that retrying won't fix.
+var imagePullFailureReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// ImagePullProbe describes a probe pod that checks an image from a private registry can be pulled.
+type ImagePullProbe struct {
+	Image              string   // The image to pull, e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com/app:1.0
+	ImagePullSecrets   []string // The names of the secrets with the registry credentials, if the pod should use any
+	ServiceAccountName string   // The service account to run the pod as, e.g. one whose image pull secrets are set up by the module
+}
+
+// CreateImagePullProbePod creates a pod with the given name that runs the image of the given probe, and waits until the
+// image is pulled. Delete it with DeletePod. This will fail the test if there is an error.
+func CreateImagePullProbePod(t testing.TestingT, options *KubectlOptions, podName string, probe ImagePullProbe, retries int, sleepBetweenRetries time.Duration) *corev1.Pod {
+	pod, err := CreateImagePullProbePodE(t, options, podName, probe, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return pod
+}
+
+// CreateImagePullProbePodE creates a pod with the given name that runs the image of the given probe, and waits until the
+// image is pulled, which verifies that the registry credentials of the pod, its service account or its nodes (e.g. IRSA
+// or workload identity) are wired up. The pod is not expected to become available, as the image may not run without
+// the configuration the module normally gives it. Returns an ImagePullFailed error as soon as the pull backs off, or the
+// image name is invalid. Delete it with DeletePodE.
+func CreateImagePullProbePodE(t testing.TestingT, options *KubectlOptions, podName string, probe ImagePullProbe, retries int, sleepBetweenRetries time.Duration) (*corev1.Pod, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Creating probe pod %s to pull image %s", podName, probe.Image)
+	pod := newImagePullProbePod(podName, options.Namespace, probe)
+	if _, err := clientset.CoreV1().Pods(options.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+
+	var pulled *corev1.Pod
+	statusMsg := fmt.Sprintf("Wait for pod %s to pull image %s.", podName, probe.Image)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		pod, err := GetPodE(t, options, podName)
+		if err != nil {
+			return "", err
+		}
+		if err := checkImagePulled(pod); err != nil {
+			return "", err
+		}
+		pulled = pod
+		return fmt.Sprintf("Pod %s pulled image %s", podName, probe.Image), nil
+	})
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return nil, actualErr.Underlying
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.Logf(t, message)
+	return pulled, nil
+}
+
+// AssertImageCanBePulled creates a probe pod that runs the image of the given probe, waits until the image is pulled and
+// deletes the pod again. This will fail the test if the image can't be pulled.
+func AssertImageCanBePulled(t testing.TestingT, options *KubectlOptions, probe ImagePullProbe, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, AssertImageCanBePulledE(t, options, probe, retries, sleepBetweenRetries))
+}
+
+// AssertImageCanBePulledE creates a probe pod that runs the image of the given probe, waits until the image is pulled
+// and deletes the pod again. Returns an ImagePullFailed error if the pull backs off, e.g. because the registry denies
+// access.
+func AssertImageCanBePulledE(t testing.TestingT, options *KubectlOptions, probe ImagePullProbe, retries int, sleepBetweenRetries time.Duration) error {
+	podName := fmt.Sprintf("image-pull-probe-%s", strings.ToLower(random.UniqueId()))
+	_, err := CreateImagePullProbePodE(t, options, podName, probe, retries, sleepBetweenRetries)
+
+	if deleteErr := DeletePodE(t, options, podName); deleteErr != nil && err == nil {
+		return deleteErr
+	}
+	return err
+}
+
+// checkImagePulled returns nil if the container of the given probe pod has pulled its image, a retry.FatalError with an
+// ImagePullFailed error if pulling the image failed for good, and an ImageNotPulled error otherwise.
+func checkImagePulled(pod *corev1.Pod) error {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != imagePullProbeContainerName {
+			continue
+		}
+		// A container only starts, and gets an image ID, once its image is pulled
+		if containerStatus.ImageID != "" || containerStatus.State.Running != nil || containerStatus.State.Terminated != nil {
+			return nil
+		}
+		if waiting := containerStatus.State.Waiting; waiting != nil && imagePullFailureReasons[waiting.Reason] {
+			return retry.FatalError{Underlying: NewImagePullFailedError(pod.Name, containerStatus.Image, waiting)}
+		}
+	}
+	return NewImageNotPulledError(pod)
+}
+
+// newImagePullProbePod returns the spec of a probe pod that runs the image of the given probe. The pod is not
+// restarted, so that an image that exits right away does not go into a crash loop.
+func newImagePullProbePod(podName string, namespace string, probe ImagePullProbe) *corev1.Pod {
+	pullSecrets := []corev1.LocalObjectReference{}
+	for _, secretName := range probe.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secretName})
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  imagePullProbeContainerName,
+				Image: probe.Image,
+				// Always pull, so that an image cached on the node does not hide missing credentials
+				ImagePullPolicy: corev1.PullAlways,
+			}},
+			ImagePullSecrets:   pullSecrets,
+			ServiceAccountName: probe.ServiceAccountName,
+			RestartPolicy:      corev1.RestartPolicyNever,
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewImagePullProbePod(t *testing.T) {
+	t.Parallel()
+
+	probe := ImagePullProbe{
+		Image:              "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:1.0",
+		ImagePullSecrets:   []string{"registry-credentials"},
+		ServiceAccountName: "app",
+	}
+	pod := newImagePullProbePod("probe", "registry-test", probe)
+
+	require.Len(t, pod.Spec.Containers, 1)
+	assert.Equal(t, probe.Image, pod.Spec.Containers[0].Image)
+	assert.Equal(t, corev1.PullAlways, pod.Spec.Containers[0].ImagePullPolicy)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry-credentials"}}, pod.Spec.ImagePullSecrets)
+	assert.Equal(t, "app", pod.Spec.ServiceAccountName)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+}
+
+func TestCheckImagePulled(t *testing.T) {
+	t.Parallel()
+
+	podWithState := func(state corev1.ContainerState, imageID string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "probe"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: imagePullProbeContainerName, Image: "registry.example.com/app:1.0", ImageID: imageID, State: state},
+			}},
+		}
+	}
+
+	assert.True(t, errors.Is(checkImagePulled(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "probe"}}), ErrNotReady))
+
+	pulling := podWithState(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}, "")
+	assert.True(t, errors.Is(checkImagePulled(pulling), ErrNotReady))
+
+	retrying := podWithState(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}}, "")
+	assert.True(t, errors.Is(checkImagePulled(retrying), ErrNotReady))
+
+	crashed := podWithState(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}, "registry.example.com/app@sha256:abc")
+	assert.NoError(t, checkImagePulled(crashed))
+
+	backOff := podWithState(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "401 Unauthorized"}}, "")
+	err := checkImagePulled(backOff)
+	fatalErr, ok := err.(retry.FatalError)
+	require.True(t, ok)
+	assert.EqualError(t, fatalErr.Underlying, "Pod probe failed to pull image registry.example.com/app:1.0: ImagePullBackOff: 401 Unauthorized")
+	assert.True(t, errors.Is(fatalErr.Underlying, ErrFailed))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
@@ -52,6 +53,10 @@ func KubectlDelete(t testing.TestingT, options *KubectlOptions, configPath strin
 
 // KubectlDeleteE will take in a file path and delete it from the cluster targeted by KubectlOptions.
 func KubectlDeleteE(t testing.TestingT, options *KubectlOptions, configPath string) error {
+	if dryrun.Skip(t, "run kubectl delete -f %s", configPath) {
+		return nil
+	}
+
 	return RunKubectlE(t, options, "delete", "-f", configPath)
 }
 
@@ -64,6 +69,10 @@ func KubectlDeleteFromString(t testing.TestingT, options *KubectlOptions, config
 // KubectlDeleteFromStringE will take in a kubernetes resource config as a string and delete it on the cluster specified
 // by the provided kubectl options. If it fails, this will return the error.
 func KubectlDeleteFromStringE(t testing.TestingT, options *KubectlOptions, configData string) error {
+	if dryrun.Skip(t, "run kubectl delete on the given resource config") {
+		return nil
+	}
+
 	tmpfile, err := StoreConfigToTempFileE(t, configData)
 	if err != nil {
 		return err
@@ -0,0 +1,278 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MeshProbeImage is the image of the probe pods created by CreateMeshProbePod, which must provide curl and sleep.
+	MeshProbeImage = "curlimages/curl:7.79.1"
+
+	meshProbeContainerName = "probe"
+	meshProbeRetries       = 60
+	meshProbeSleep         = 2 * time.Second
+	meshProbeRequestTime   = "10"
+)
+
+// ServiceMesh describes how a service mesh injects its sidecar proxy into pods.
+type ServiceMesh struct {
+	Name                 string
+	SidecarContainerName string
+	InjectAnnotation     string // The annotation (and label) that controls the injection of the sidecar into a pod
+	InjectEnabledValue   string
+	InjectDisabledValue  string
+}
+
+var (
+	// Istio is the Istio service mesh.
+	Istio = ServiceMesh{
+		Name:                 "Istio",
+		SidecarContainerName: "istio-proxy",
+		InjectAnnotation:     "sidecar.istio.io/inject",
+		InjectEnabledValue:   "true",
+		InjectDisabledValue:  "false",
+	}
+
+	// Linkerd is the Linkerd service mesh.
+	Linkerd = ServiceMesh{
+		Name:                 "Linkerd",
+		SidecarContainerName: "linkerd-proxy",
+		InjectAnnotation:     "linkerd.io/inject",
+		InjectEnabledValue:   "enabled",
+		InjectDisabledValue:  "disabled",
+	}
+)
+
+// PodHasMeshSidecar returns true if the given pod has the sidecar proxy of the given mesh, either as a regular
+// container or as a native sidecar (init) container.
+func PodHasMeshSidecar(pod *corev1.Pod, mesh ServiceMesh) bool {
+	for _, container := range append(pod.Spec.Containers, pod.Spec.InitContainers...) {
+		if container.Name == mesh.SidecarContainerName {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertPodsHaveMeshSidecar checks that there is at least one pod matching the given filters, and that all of them
+// have the sidecar proxy of the given mesh injected. This will fail the test if there is an error.
+func AssertPodsHaveMeshSidecar(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions, mesh ServiceMesh) {
+	require.NoError(t, AssertPodsHaveMeshSidecarE(t, options, filters, mesh))
+}
+
+// AssertPodsHaveMeshSidecarE checks that there is at least one pod matching the given filters, and that all of them
+// have the sidecar proxy of the given mesh injected, returning a MeshSidecarMissing error otherwise.
+func AssertPodsHaveMeshSidecarE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions, mesh ServiceMesh) error {
+	pods, err := ListPodsE(t, options, filters)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("No pods found matching %v", filters)
+	}
+
+	missing := []string{}
+	for i := range pods {
+		if !PodHasMeshSidecar(&pods[i], mesh) {
+			missing = append(missing, pods[i].Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return NewMeshSidecarMissingError(mesh.Name, missing)
+	}
+	return nil
+}
+
+// CreateMeshProbePod creates a long running pod with curl that requests can be sent from with HTTPRequestFromPod,
+// and waits for it to be available. If meshed is true, the sidecar of the given mesh is injected into it, otherwise
+// injection is disabled, so that it sends plaintext requests from outside the mesh. Delete it with
+// DeleteMeshProbePod. This will fail the test if there is an error.
+func CreateMeshProbePod(t testing.TestingT, options *KubectlOptions, podName string, mesh ServiceMesh, meshed bool) *corev1.Pod {
+	pod, err := CreateMeshProbePodE(t, options, podName, mesh, meshed)
+	require.NoError(t, err)
+	return pod
+}
+
+// CreateMeshProbePodE creates a long running pod with curl that requests can be sent from with HTTPRequestFromPodE,
+// and waits for it to be available. If meshed is true, the sidecar of the given mesh is injected into it, otherwise
+// injection is disabled, so that it sends plaintext requests from outside the mesh. Delete it with
+// DeleteMeshProbePodE.
+func CreateMeshProbePodE(t testing.TestingT, options *KubectlOptions, podName string, mesh ServiceMesh, meshed bool) (*corev1.Pod, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	pod := newMeshProbePod(podName, options.Namespace, mesh, meshed)
+	logger.Logf(t, "Creating probe pod %s (%s sidecar injected: %t)", podName, mesh.Name, meshed)
+	if _, err := clientset.CoreV1().Pods(options.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+
+	if err := WaitUntilPodAvailableE(t, options, podName, meshProbeRetries, meshProbeSleep); err != nil {
+		return nil, err
+	}
+
+	created, err := GetPodE(t, options, podName)
+	if err != nil {
+		return nil, err
+	}
+	if meshed && !PodHasMeshSidecar(created, mesh) {
+		return nil, NewMeshSidecarMissingError(mesh.Name, []string{podName})
+	}
+	return created, nil
+}
+
+// DeleteMeshProbePod deletes the given probe pod. This will fail the test if there is an error.
+func DeleteMeshProbePod(t testing.TestingT, options *KubectlOptions, podName string) {
+	require.NoError(t, DeleteMeshProbePodE(t, options, podName))
+}
+
+// DeleteMeshProbePodE deletes the given probe pod.
+func DeleteMeshProbePodE(t testing.TestingT, options *KubectlOptions, podName string) error {
+	return DeletePodE(t, options, podName)
+}
+
+// HTTPRequestFromPod sends an HTTP GET request with the given headers to the given URL with curl from inside the
+// given probe pod, and returns the status code and body of the response. This is how the routing rules (e.g. Istio
+// VirtualServices) of a mesh are seen by its clients. This will fail the test if there is an error.
+func HTTPRequestFromPod(t testing.TestingT, options *KubectlOptions, podName string, url string, headers map[string]string) (int, string) {
+	statusCode, body, err := HTTPRequestFromPodE(t, options, podName, url, headers)
+	require.NoError(t, err)
+	return statusCode, body
+}
+
+// HTTPRequestFromPodE sends an HTTP GET request with the given headers to the given URL with curl from inside the
+// given probe pod, and returns the status code and body of the response. This is how the routing rules (e.g. Istio
+// VirtualServices) of a mesh are seen by its clients. An error is returned if no response was received.
+func HTTPRequestFromPodE(t testing.TestingT, options *KubectlOptions, podName string, url string, headers map[string]string) (int, string, error) {
+	args := []string{"exec", podName, "-c", meshProbeContainerName, "--", "curl", "-sS", "-m", meshProbeRequestTime, "-w", "\n%{http_code}"}
+	for key, value := range headers {
+		args = append(args, "-H", fmt.Sprintf("%s: %s", key, value))
+	}
+	args = append(args, url)
+
+	output, err := RunKubectlAndGetOutputE(t, options, args...)
+	if err != nil {
+		return 0, "", fmt.Errorf("Request to %s from pod %s failed: %s: %s", url, podName, err, output)
+	}
+
+	return parseCurlStatusOutput(output)
+}
+
+// GetResponseCountsFromPod sends the given number of requests to the given URL from inside the given probe pod, and
+// returns how many times each distinct (trimmed) response body was received. Failed requests are counted under the
+// empty string. This is useful to verify weighted routing, e.g. a 90/10 canary split between two service versions
+// that respond with their version. This will fail the test if there is an error.
+func GetResponseCountsFromPod(t testing.TestingT, options *KubectlOptions, podName string, url string, headers map[string]string, numRequests int) map[string]int {
+	counts, err := GetResponseCountsFromPodE(t, options, podName, url, headers, numRequests)
+	require.NoError(t, err)
+	return counts
+}
+
+// GetResponseCountsFromPodE sends the given number of requests to the given URL from inside the given probe pod, and
+// returns how many times each distinct (trimmed) response body was received. Failed requests are counted under the
+// empty string. This is useful to verify weighted routing, e.g. a 90/10 canary split between two service versions
+// that respond with their version.
+func GetResponseCountsFromPodE(t testing.TestingT, options *KubectlOptions, podName string, url string, headers map[string]string, numRequests int) (map[string]int, error) {
+	if numRequests <= 0 {
+		return nil, fmt.Errorf("The number of requests must be positive, got %d", numRequests)
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < numRequests; i++ {
+		statusCode, body, err := HTTPRequestFromPodE(t, options, podName, url, headers)
+		if err != nil || statusCode >= 500 {
+			counts[""]++
+			continue
+		}
+		counts[strings.TrimSpace(body)]++
+	}
+	return counts, nil
+}
+
+// AssertPlaintextRejected checks that a plaintext request to the given URL from the given probe pod, which should be
+// created outside the mesh with CreateMeshProbePod, is rejected. With mTLS enforced, Istio resets such connections
+// and Linkerd answers them with a 403 once an authorization policy denies unauthenticated clients.
+// This will fail the test if there is an error.
+func AssertPlaintextRejected(t testing.TestingT, options *KubectlOptions, podName string, url string) {
+	require.NoError(t, AssertPlaintextRejectedE(t, options, podName, url))
+}
+
+// AssertPlaintextRejectedE checks that a plaintext request to the given URL from the given probe pod, which should be
+// created outside the mesh with CreateMeshProbePodE, is rejected, returning a PlaintextConnectionAccepted error
+// otherwise. With mTLS enforced, Istio resets such connections and Linkerd answers them with a 403 once an
+// authorization policy denies unauthenticated clients.
+func AssertPlaintextRejectedE(t testing.TestingT, options *KubectlOptions, podName string, url string) error {
+	pod, err := GetPodE(t, options, podName)
+	if err != nil {
+		return err
+	}
+	for _, mesh := range []ServiceMesh{Istio, Linkerd} {
+		if PodHasMeshSidecar(pod, mesh) {
+			return fmt.Errorf("Probe pod %s has the %s sidecar, so its requests are not plaintext", podName, mesh.Name)
+		}
+	}
+
+	statusCode, _, err := HTTPRequestFromPodE(t, options, podName, url, nil)
+	if err != nil {
+		logger.Logf(t, "Plaintext request to %s was rejected as expected: %s", url, err)
+		return nil
+	}
+	if statusCode == 403 {
+		logger.Logf(t, "Plaintext request to %s was denied as expected with status %d", url, statusCode)
+		return nil
+	}
+
+	return NewPlaintextConnectionAcceptedError(url, statusCode)
+}
+
+// newMeshProbePod returns the spec of a probe pod, with the sidecar of the given mesh injected if meshed is true.
+func newMeshProbePod(podName string, namespace string, mesh ServiceMesh, meshed bool) *corev1.Pod {
+	injectValue := mesh.InjectDisabledValue
+	if meshed {
+		injectValue = mesh.InjectEnabledValue
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: namespace,
+			// Istio reads the injection setting from a label in recent versions and from an annotation in older ones
+			Labels:      map[string]string{"app": podName, mesh.InjectAnnotation: injectValue},
+			Annotations: map[string]string{mesh.InjectAnnotation: injectValue},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    meshProbeContainerName,
+				Image:   MeshProbeImage,
+				Command: []string{"sleep", "3600"},
+			}},
+		},
+	}
+}
+
+// parseCurlStatusOutput splits the output of curl with `-w "\n%{http_code}"` into the status code and body.
+func parseCurlStatusOutput(output string) (int, string, error) {
+	output = strings.TrimRight(output, "\n")
+	separator := strings.LastIndex(output, "\n")
+	statusCode, err := strconv.Atoi(strings.TrimSpace(output[separator+1:]))
+	if err != nil {
+		return 0, "", fmt.Errorf("Failed to parse the status code from curl output %q", output)
+	}
+	if separator < 0 {
+		return statusCode, "", nil
+	}
+	return statusCode, output[:separator], nil
+}
@@ -0,0 +1,52 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodHasMeshSidecar(t *testing.T) {
+	t.Parallel()
+
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "istio-proxy"}}}}
+	assert.True(t, PodHasMeshSidecar(pod, Istio))
+	assert.False(t, PodHasMeshSidecar(pod, Linkerd))
+
+	nativeSidecarPod := &corev1.Pod{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "linkerd-proxy"}},
+		Containers:     []corev1.Container{{Name: "app"}},
+	}}
+	assert.True(t, PodHasMeshSidecar(nativeSidecarPod, Linkerd))
+}
+
+func TestNewMeshProbePod(t *testing.T) {
+	t.Parallel()
+
+	meshed := newMeshProbePod("probe", "default", Linkerd, true)
+	assert.Equal(t, "enabled", meshed.Annotations["linkerd.io/inject"])
+
+	plaintext := newMeshProbePod("probe", "default", Istio, false)
+	assert.Equal(t, "false", plaintext.Annotations["sidecar.istio.io/inject"])
+	assert.Equal(t, "false", plaintext.Labels["sidecar.istio.io/inject"])
+	assert.Equal(t, MeshProbeImage, plaintext.Spec.Containers[0].Image)
+}
+
+func TestParseCurlStatusOutput(t *testing.T) {
+	t.Parallel()
+
+	statusCode, body, err := parseCurlStatusOutput("hello from v2\nsecond line\n200")
+	require.NoError(t, err)
+	assert.Equal(t, 200, statusCode)
+	assert.Equal(t, "hello from v2\nsecond line", body)
+
+	statusCode, body, err = parseCurlStatusOutput("204\n")
+	require.NoError(t, err)
+	assert.Equal(t, 204, statusCode)
+	assert.Equal(t, "", body)
+
+	_, _, err = parseCurlStatusOutput("curl: (56) Recv failure: Connection reset by peer")
+	assert.Error(t, err)
+}
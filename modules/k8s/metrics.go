@@ -0,0 +1,262 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// The path of the metrics.k8s.io API served by metrics-server.
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// PodMetrics is a snapshot of the CPU and memory usage of the containers of a pod, as reported by metrics-server.
+type PodMetrics struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Timestamp         metav1.Time        `json:"timestamp"`
+	Window            metav1.Duration    `json:"window"`
+	Containers        []ContainerMetrics `json:"containers"`
+}
+
+// ContainerMetrics is the CPU and memory usage of a single container.
+type ContainerMetrics struct {
+	Name  string              `json:"name"`
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+// NodeMetrics is a snapshot of the CPU and memory usage of a node, as reported by metrics-server.
+type NodeMetrics struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Timestamp         metav1.Time         `json:"timestamp"`
+	Window            metav1.Duration     `json:"window"`
+	Usage             corev1.ResourceList `json:"usage"`
+}
+
+type podMetricsList struct {
+	Items []PodMetrics `json:"items"`
+}
+
+type nodeMetricsList struct {
+	Items []NodeMetrics `json:"items"`
+}
+
+// GetPodMetrics returns the current CPU and memory usage of the pod with the given name in the namespace provided in the
+// KubectlOptions. This requires metrics-server to be installed in the cluster. This will fail the test if there is an
+// error.
+func GetPodMetrics(t testing.TestingT, options *KubectlOptions, podName string) *PodMetrics {
+	metrics, err := GetPodMetricsE(t, options, podName)
+	require.NoError(t, err)
+	return metrics
+}
+
+// GetPodMetricsE returns the current CPU and memory usage of the pod with the given name in the namespace provided in
+// the KubectlOptions. This requires metrics-server to be installed in the cluster.
+func GetPodMetricsE(t testing.TestingT, options *KubectlOptions, podName string) (*PodMetrics, error) {
+	var metrics PodMetrics
+	path := fmt.Sprintf("%s/namespaces/%s/pods/%s", metricsAPIPath, options.Namespace, podName)
+	if err := getMetricsE(t, options, path, metav1.ListOptions{}, &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// ListPodMetrics returns the current CPU and memory usage of the pods that match the given filters in the namespace
+// provided in the KubectlOptions. Only the LabelSelector and FieldSelector of the filters are used. This will fail the
+// test if there is an error.
+func ListPodMetrics(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []PodMetrics {
+	metrics, err := ListPodMetricsE(t, options, filters)
+	require.NoError(t, err)
+	return metrics
+}
+
+// ListPodMetricsE returns the current CPU and memory usage of the pods that match the given filters in the namespace
+// provided in the KubectlOptions. Only the LabelSelector and FieldSelector of the filters are used.
+func ListPodMetricsE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]PodMetrics, error) {
+	var list podMetricsList
+	path := fmt.Sprintf("%s/namespaces/%s/pods", metricsAPIPath, options.Namespace)
+	if err := getMetricsE(t, options, path, filters, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetNodeMetrics returns the current CPU and memory usage of the node with the given name. This requires metrics-server
+// to be installed in the cluster. This will fail the test if there is an error.
+func GetNodeMetrics(t testing.TestingT, options *KubectlOptions, nodeName string) *NodeMetrics {
+	metrics, err := GetNodeMetricsE(t, options, nodeName)
+	require.NoError(t, err)
+	return metrics
+}
+
+// GetNodeMetricsE returns the current CPU and memory usage of the node with the given name. This requires
+// metrics-server to be installed in the cluster.
+func GetNodeMetricsE(t testing.TestingT, options *KubectlOptions, nodeName string) (*NodeMetrics, error) {
+	var metrics NodeMetrics
+	if err := getMetricsE(t, options, fmt.Sprintf("%s/nodes/%s", metricsAPIPath, nodeName), metav1.ListOptions{}, &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// ListNodeMetrics returns the current CPU and memory usage of all the nodes in the cluster. This will fail the test if
+// there is an error.
+func ListNodeMetrics(t testing.TestingT, options *KubectlOptions) []NodeMetrics {
+	metrics, err := ListNodeMetricsE(t, options)
+	require.NoError(t, err)
+	return metrics
+}
+
+// ListNodeMetricsE returns the current CPU and memory usage of all the nodes in the cluster.
+func ListNodeMetricsE(t testing.TestingT, options *KubectlOptions) ([]NodeMetrics, error) {
+	var list nodeMetricsList
+	if err := getMetricsE(t, options, metricsAPIPath+"/nodes", metav1.ListOptions{}, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// WaitUntilPodMetricsAvailable waits until metrics-server reports the usage of the pod with the given name, which
+// usually takes up to a minute after the pod starts. This will retry the check for the specified amount of times,
+// sleeping for the provided duration between each try. This will fail the test if the retry times out.
+func WaitUntilPodMetricsAvailable(t testing.TestingT, options *KubectlOptions, podName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilPodMetricsAvailableE(t, options, podName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilPodMetricsAvailableE waits until metrics-server reports the usage of the pod with the given name, which
+// usually takes up to a minute after the pod starts. This will retry the check for the specified amount of times,
+// sleeping for the provided duration between each try.
+func WaitUntilPodMetricsAvailableE(t testing.TestingT, options *KubectlOptions, podName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for metrics of pod %s to be available.", podName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			metrics, err := GetPodMetricsE(t, options, podName)
+			if err != nil {
+				return "", err
+			}
+			if len(metrics.Containers) == 0 {
+				return "", fmt.Errorf("No container metrics reported for pod %s yet", podName)
+			}
+			return "Pod metrics are now available", nil
+		},
+	)
+	if err != nil {
+		logger.Logf(t, "Timed out waiting for metrics of pod %s: %s", podName, err)
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// AssertPodUsageWithinLimits verifies that the current CPU and memory usage of every container of the pod with the
+// given name is within the limits of that container. Resources without a limit are not checked. This will fail the test
+// if any usage exceeds its limit or there is an error.
+func AssertPodUsageWithinLimits(t testing.TestingT, options *KubectlOptions, podName string) {
+	require.NoError(t, AssertPodUsageWithinLimitsE(t, options, podName))
+}
+
+// AssertPodUsageWithinLimitsE verifies that the current CPU and memory usage of every container of the pod with the
+// given name is within the limits of that container. Resources without a limit are not checked.
+func AssertPodUsageWithinLimitsE(t testing.TestingT, options *KubectlOptions, podName string) error {
+	return assertPodUsageE(t, options, podName, "limit", 1, func(container corev1.Container) corev1.ResourceList {
+		return container.Resources.Limits
+	})
+}
+
+// AssertPodUsageWithinRequests verifies that the current CPU and memory usage of every container of the pod with the
+// given name is at most maxRatio times the requests of that container, e.g. a maxRatio of 1.2 allows usage to exceed
+// the requests by 20%. Resources without a request are not checked. This is useful to verify the right-sizing of
+// requests while the pod is under a representative load. This will fail the test if any usage exceeds its bound or
+// there is an error.
+func AssertPodUsageWithinRequests(t testing.TestingT, options *KubectlOptions, podName string, maxRatio float64) {
+	require.NoError(t, AssertPodUsageWithinRequestsE(t, options, podName, maxRatio))
+}
+
+// AssertPodUsageWithinRequestsE verifies that the current CPU and memory usage of every container of the pod with the
+// given name is at most maxRatio times the requests of that container, e.g. a maxRatio of 1.2 allows usage to exceed
+// the requests by 20%. Resources without a request are not checked. This is useful to verify the right-sizing of
+// requests while the pod is under a representative load.
+func AssertPodUsageWithinRequestsE(t testing.TestingT, options *KubectlOptions, podName string, maxRatio float64) error {
+	return assertPodUsageE(t, options, podName, "request", maxRatio, func(container corev1.Container) corev1.ResourceList {
+		return container.Resources.Requests
+	})
+}
+
+// assertPodUsageE compares the usage of each container of the given pod with the bounds returned by getBounds, scaled
+// by maxRatio.
+func assertPodUsageE(t testing.TestingT, options *KubectlOptions, podName string, boundName string, maxRatio float64, getBounds func(corev1.Container) corev1.ResourceList) error {
+	pod, err := GetPodE(t, options, podName)
+	if err != nil {
+		return err
+	}
+	metrics, err := GetPodMetricsE(t, options, podName)
+	if err != nil {
+		return err
+	}
+
+	bounds := map[string]corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		bounds[container.Name] = getBounds(container)
+	}
+
+	violations := findResourceUsageViolations(metrics.Containers, bounds, boundName, maxRatio)
+	if len(violations) > 0 {
+		return NewResourceUsageExceededError(podName, violations)
+	}
+	return nil
+}
+
+// findResourceUsageViolations returns a description of each CPU or memory usage in the given container metrics that is
+// more than maxRatio times the bound of that container.
+func findResourceUsageViolations(containers []ContainerMetrics, bounds map[string]corev1.ResourceList, boundName string, maxRatio float64) []string {
+	violations := []string{}
+	for _, container := range containers {
+		for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			bound, hasBound := bounds[container.Name][resourceName]
+			usage, hasUsage := container.Usage[resourceName]
+			if !hasBound || !hasUsage {
+				continue
+			}
+			if float64(usage.MilliValue()) > float64(bound.MilliValue())*maxRatio {
+				violations = append(violations, fmt.Sprintf(
+					"container %s uses %s %s, more than %.2f times its %s of %s",
+					container.Name, usage.String(), resourceName, maxRatio, boundName, bound.String(),
+				))
+			}
+		}
+	}
+	return violations
+}
+
+// getMetricsE fetches the given path of the metrics.k8s.io API and decodes the response into result.
+func getMetricsE(t testing.TestingT, options *KubectlOptions, path string, filters metav1.ListOptions, result interface{}) error {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	request := clientset.Discovery().RESTClient().Get().AbsPath(path)
+	if filters.LabelSelector != "" {
+		request = request.Param("labelSelector", filters.LabelSelector)
+	}
+	if filters.FieldSelector != "" {
+		request = request.Param("fieldSelector", filters.FieldSelector)
+	}
+
+	body, err := request.DoRaw(context.Background())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
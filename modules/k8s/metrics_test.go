@@ -0,0 +1,29 @@
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestListNodeMetricsReturnsUsage(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "default")
+	metrics, err := ListNodeMetricsE(t, options)
+	if err != nil {
+		t.Skipf("metrics-server does not seem to be installed: %s", err)
+	}
+	require.NotEmpty(t, metrics)
+	_, hasCPU := metrics[0].Usage[corev1.ResourceCPU]
+	require.True(t, hasCPU)
+}
@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestFindResourceUsageViolations(t *testing.T) {
+	t.Parallel()
+
+	containers := []ContainerMetrics{
+		{
+			Name: "app",
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("150m"),
+				corev1.ResourceMemory: resource.MustParse("100Mi"),
+			},
+		},
+		{
+			Name: "sidecar",
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("5m"),
+			},
+		},
+	}
+	bounds := map[string]corev1.ResourceList{
+		"app": {
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		"sidecar": {},
+	}
+
+	require.Equal(t, []string{
+		"container app uses 150m cpu, more than 1.00 times its request of 100m",
+	}, findResourceUsageViolations(containers, bounds, "request", 1))
+	require.Empty(t, findResourceUsageViolations(containers, bounds, "request", 1.5))
+}
@@ -2,10 +2,16 @@ package k8s
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -72,6 +78,10 @@ func DeleteNamespace(t testing.TestingT, options *KubectlOptions, namespaceName
 
 // DeleteNamespaceE will delete the requested namespace from the Kubernetes cluster targeted by the provided options.
 func DeleteNamespaceE(t testing.TestingT, options *KubectlOptions, namespaceName string) error {
+	if dryrun.Skip(t, "delete namespace %s", namespaceName) {
+		return nil
+	}
+
 	clientset, err := GetKubernetesClientFromOptionsE(t, options)
 	if err != nil {
 		return err
@@ -79,3 +89,32 @@ func DeleteNamespaceE(t testing.TestingT, options *KubectlOptions, namespaceName
 
 	return clientset.CoreV1().Namespaces().Delete(context.Background(), namespaceName, metav1.DeleteOptions{})
 }
+
+// WaitUntilNamespaceDeleted waits until the given namespace is gone, which takes a while after deleting it as its
+// resources are deleted first, retrying the check for the specified amount of times, sleeping for the provided duration
+// between each try. This will fail the test if there is an error or if the check times out.
+func WaitUntilNamespaceDeleted(t testing.TestingT, options *KubectlOptions, namespaceName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilNamespaceDeletedE(t, options, namespaceName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilNamespaceDeletedE waits until the given namespace is gone, which takes a while after deleting it as its
+// resources are deleted first, retrying the check for the specified amount of times, sleeping for the provided duration
+// between each try.
+func WaitUntilNamespaceDeletedE(t testing.TestingT, options *KubectlOptions, namespaceName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for namespace %s to be deleted.", namespaceName)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		_, err := GetNamespaceE(t, options, namespaceName)
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("Namespace %s is deleted", namespaceName), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("Namespace %s still exists", namespaceName)
+	})
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
@@ -3,12 +3,16 @@ package k8s
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -129,3 +133,231 @@ func AreAllNodesReadyE(t testing.TestingT, options *KubectlOptions) (bool, error
 	}
 	return true, nil
 }
+
+const (
+	// NodeZoneLabel is the well-known label holding the zone a node runs in.
+	NodeZoneLabel = "topology.kubernetes.io/zone"
+
+	// deprecatedNodeZoneLabel is the label holding the zone a node runs in on clusters older than Kubernetes 1.17.
+	deprecatedNodeZoneLabel = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// GetNodeZone returns the zone (e.g. the AWS Availability Zone) the given node runs in, based on its well-known zone
+// labels, or an empty string if it has none.
+func GetNodeZone(node corev1.Node) string {
+	if zone, ok := node.Labels[NodeZoneLabel]; ok {
+		return zone
+	}
+	return node.Labels[deprecatedNodeZoneLabel]
+}
+
+// GetNodesInZone queries Kubernetes for the nodes that run in the given zone. If anything goes wrong, the function will
+// automatically fail the test.
+func GetNodesInZone(t testing.TestingT, options *KubectlOptions, zone string) []corev1.Node {
+	nodes, err := GetNodesInZoneE(t, options, zone)
+	require.NoError(t, err)
+	return nodes
+}
+
+// GetNodesInZoneE queries Kubernetes for the nodes that run in the given zone.
+func GetNodesInZoneE(t testing.TestingT, options *KubectlOptions, zone string) ([]corev1.Node, error) {
+	nodes, err := GetNodesE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	nodesInZone := []corev1.Node{}
+	for _, node := range nodes {
+		if GetNodeZone(node) == zone {
+			nodesInZone = append(nodesInZone, node)
+		}
+	}
+	return nodesInZone, nil
+}
+
+// CordonNode marks the given node as unschedulable, so that no new pods are scheduled on it. This will fail the test if
+// there is an error.
+func CordonNode(t testing.TestingT, options *KubectlOptions, nodeName string) {
+	require.NoError(t, CordonNodeE(t, options, nodeName))
+}
+
+// CordonNodeE marks the given node as unschedulable, so that no new pods are scheduled on it.
+func CordonNodeE(t testing.TestingT, options *KubectlOptions, nodeName string) error {
+	if dryrun.Skip(t, "cordon node %s", nodeName) {
+		return nil
+	}
+
+	return RunKubectlE(t, options, "cordon", nodeName)
+}
+
+// UncordonNode marks the given node as schedulable again. This will fail the test if there is an error.
+func UncordonNode(t testing.TestingT, options *KubectlOptions, nodeName string) {
+	require.NoError(t, UncordonNodeE(t, options, nodeName))
+}
+
+// UncordonNodeE marks the given node as schedulable again.
+func UncordonNodeE(t testing.TestingT, options *KubectlOptions, nodeName string) error {
+	return RunKubectlE(t, options, "uncordon", nodeName)
+}
+
+// mirrorPodAnnotation is the annotation of the mirror pods the kubelet creates for its static pods, which can't be
+// evicted through the API server.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// DrainNode cordons the given node and evicts all its pods except those managed by a DaemonSet, as kubectl drain does.
+// Evictions respect PodDisruptionBudgets: an eviction that would violate one is retried until the budget allows it or
+// the retries run out. This will fail the test if there is an error or if the node can't be drained in time.
+func DrainNode(t testing.TestingT, options *KubectlOptions, nodeName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, DrainNodeE(t, options, nodeName, retries, sleepBetweenRetries))
+}
+
+// DrainNodeE cordons the given node and evicts all its pods except those managed by a DaemonSet, as kubectl drain does.
+// Evictions respect PodDisruptionBudgets: an eviction that would violate one is retried until the budget allows it or
+// the retries run out, in which case a NodeDrainBlocked error lists the pods that could not be evicted.
+func DrainNodeE(t testing.TestingT, options *KubectlOptions, nodeName string, retries int, sleepBetweenRetries time.Duration) error {
+	if dryrun.Skip(t, "drain node %s", nodeName) {
+		return nil
+	}
+
+	if err := CordonNodeE(t, options, nodeName); err != nil {
+		return err
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	blockedPodNames := []string{}
+	description := fmt.Sprintf("Draining node %s", nodeName)
+	_, err = retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		pods, err := getPodsOnNodeE(t, options, nodeName)
+		if err != nil {
+			return "", err
+		}
+
+		remaining := 0
+		blockedPodNames = []string{}
+		for _, pod := range pods {
+			if !isDrainablePod(pod) {
+				continue
+			}
+			remaining++
+			if pod.DeletionTimestamp != nil {
+				// Already evicted, waiting for it to terminate
+				continue
+			}
+
+			eviction := &policyv1beta1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+			err := clientset.PolicyV1beta1().Evictions(pod.Namespace).Evict(context.Background(), eviction)
+			switch {
+			case err == nil, apierrors.IsNotFound(err):
+				logger.Logf(t, "Evicted pod %s/%s from node %s", pod.Namespace, pod.Name, nodeName)
+			case apierrors.IsTooManyRequests(err):
+				blockedPodNames = append(blockedPodNames, pod.Namespace+"/"+pod.Name)
+			default:
+				return "", retry.FatalError{Underlying: err}
+			}
+		}
+
+		if remaining > 0 {
+			return "", fmt.Errorf("%d pods are still running on node %s", remaining, nodeName)
+		}
+		return fmt.Sprintf("Node %s is drained", nodeName), nil
+	})
+
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return actualErr.Underlying
+	}
+	if _, ok := err.(retry.MaxRetriesExceeded); ok && len(blockedPodNames) > 0 {
+		return NewNodeDrainBlockedError(nodeName, blockedPodNames)
+	}
+	return err
+}
+
+// DeleteNode deletes the given node from the cluster, as happens when its instance is terminated. This will fail the
+// test if there is an error.
+func DeleteNode(t testing.TestingT, options *KubectlOptions, nodeName string) {
+	require.NoError(t, DeleteNodeE(t, options, nodeName))
+}
+
+// DeleteNodeE deletes the given node from the cluster, as happens when its instance is terminated.
+func DeleteNodeE(t testing.TestingT, options *KubectlOptions, nodeName string) error {
+	if dryrun.Skip(t, "delete node %s", nodeName) {
+		return nil
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Deleting node %s", nodeName)
+	return clientset.CoreV1().Nodes().Delete(context.Background(), nodeName, metav1.DeleteOptions{})
+}
+
+// WaitUntilPodsRescheduled waits until at least the desired number of pods that match the provided filter are available
+// on nodes other than the given one, e.g. after the node was drained or deleted. This will retry the check for the
+// specified amount of times, sleeping for the provided duration between each try. This will fail the test if the retry
+// times out.
+func WaitUntilPodsRescheduled(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions, nodeName string, desiredCount int, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilPodsRescheduledE(t, options, filters, nodeName, desiredCount, retries, sleepBetweenRetries))
+}
+
+// WaitUntilPodsRescheduledE waits until at least the desired number of pods that match the provided filter are
+// available on nodes other than the given one, e.g. after the node was drained or deleted. This will retry the check
+// for the specified amount of times, sleeping for the provided duration between each try.
+func WaitUntilPodsRescheduledE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions, nodeName string, desiredCount int, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Wait for %d pods to be rescheduled away from node %s", desiredCount, nodeName)
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		pods, err := ListPodsE(t, options, filters)
+		if err != nil {
+			return "", err
+		}
+
+		available := 0
+		for _, pod := range pods {
+			pod := pod
+			if pod.Spec.NodeName != nodeName && pod.DeletionTimestamp == nil && IsPodAvailable(&pod) {
+				available++
+			}
+		}
+
+		if available < desiredCount {
+			return "", fmt.Errorf("%d of %d pods are available on other nodes", available, desiredCount)
+		}
+		return fmt.Sprintf("%d pods are available on other nodes", available), nil
+	})
+	logger.Logf(t, message)
+	return err
+}
+
+// getPodsOnNodeE returns the pods in all namespaces that are scheduled on the given node.
+func getPodsOnNodeE(t testing.TestingT, options *KubectlOptions, nodeName string) ([]corev1.Pod, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// isDrainablePod returns true if draining a node should evict the given pod, which excludes the pods managed by a
+// DaemonSet (they would be recreated on the same node), mirror pods and pods that have already terminated.
+func isDrainablePod(pod corev1.Pod) bool {
+	if _, isMirror := pod.Annotations[mirrorPodAnnotation]; isMirror {
+		return false
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Tests that:
@@ -72,3 +74,38 @@ func TestWaitUntilAllNodesReady(t *testing.T) {
 
 	assert.Equal(t, nodeNames, readyNodeNames)
 }
+
+func TestGetNodeZone(t *testing.T) {
+	t.Parallel()
+
+	node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{NodeZoneLabel: "us-east-1a"}}}
+	assert.Equal(t, "us-east-1a", GetNodeZone(node))
+
+	legacyNode := corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"failure-domain.beta.kubernetes.io/zone": "us-east-1b"}}}
+	assert.Equal(t, "us-east-1b", GetNodeZone(legacyNode))
+
+	assert.Equal(t, "", GetNodeZone(corev1.Node{}))
+}
+
+func TestIsDrainablePod(t *testing.T) {
+	t.Parallel()
+
+	running := corev1.PodStatus{Phase: corev1.PodRunning}
+	cases := []struct {
+		title    string
+		pod      corev1.Pod
+		expected bool
+	}{
+		{"ReplicaSetPod", corev1.Pod{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet"}}}, Status: running}, true},
+		{"DaemonSetPod", corev1.Pod{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet"}}}, Status: running}, false},
+		{"MirrorPod", corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{mirrorPodAnnotation: "abc"}}, Status: running}, false},
+		{"SucceededPod", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}, false},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, isDrainablePod(tc.pod))
+		})
+	}
+}
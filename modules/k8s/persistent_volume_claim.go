@@ -0,0 +1,302 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// VolumeProbeImage is the image of the probe pods created by CreateVolumeProbePod, which must provide sh and sleep.
+	VolumeProbeImage = "busybox:1.34"
+
+	// VolumeProbeMountPath is where CreateVolumeProbePod mounts the volume under test.
+	VolumeProbeMountPath = "/data"
+
+	volumeProbeContainerName = "probe"
+)
+
+// GetPersistentVolumeClaim returns the PersistentVolumeClaim with the given name in the namespace of the given
+// KubectlOptions. This will fail the test if there is an error.
+func GetPersistentVolumeClaim(t testing.TestingT, options *KubectlOptions, pvcName string) *corev1.PersistentVolumeClaim {
+	pvc, err := GetPersistentVolumeClaimE(t, options, pvcName)
+	require.NoError(t, err)
+	return pvc
+}
+
+// GetPersistentVolumeClaimE returns the PersistentVolumeClaim with the given name in the namespace of the given
+// KubectlOptions.
+func GetPersistentVolumeClaimE(t testing.TestingT, options *KubectlOptions, pvcName string) (*corev1.PersistentVolumeClaim, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().PersistentVolumeClaims(options.Namespace).Get(context.Background(), pvcName, metav1.GetOptions{})
+}
+
+// CreatePersistentVolumeClaim creates a ReadWriteOnce PersistentVolumeClaim of the given size (e.g. "1Gi") from the
+// given StorageClass. This will fail the test if there is an error.
+func CreatePersistentVolumeClaim(t testing.TestingT, options *KubectlOptions, pvcName string, storageClassName string, size string) *corev1.PersistentVolumeClaim {
+	pvc, err := CreatePersistentVolumeClaimE(t, options, pvcName, storageClassName, size)
+	require.NoError(t, err)
+	return pvc
+}
+
+// CreatePersistentVolumeClaimE creates a ReadWriteOnce PersistentVolumeClaim of the given size (e.g. "1Gi") from the
+// given StorageClass.
+func CreatePersistentVolumeClaimE(t testing.TestingT, options *KubectlOptions, pvcName string, storageClassName string, size string) (*corev1.PersistentVolumeClaim, error) {
+	return createPersistentVolumeClaimE(t, options, pvcName, storageClassName, size, nil)
+}
+
+// CreatePersistentVolumeClaimFromSnapshot creates a ReadWriteOnce PersistentVolumeClaim of the given size from the
+// given StorageClass, restoring the data of the given VolumeSnapshot. This will fail the test if there is an error.
+func CreatePersistentVolumeClaimFromSnapshot(t testing.TestingT, options *KubectlOptions, pvcName string, storageClassName string, size string, snapshotName string) *corev1.PersistentVolumeClaim {
+	pvc, err := CreatePersistentVolumeClaimFromSnapshotE(t, options, pvcName, storageClassName, size, snapshotName)
+	require.NoError(t, err)
+	return pvc
+}
+
+// CreatePersistentVolumeClaimFromSnapshotE creates a ReadWriteOnce PersistentVolumeClaim of the given size from the
+// given StorageClass, restoring the data of the given VolumeSnapshot.
+func CreatePersistentVolumeClaimFromSnapshotE(t testing.TestingT, options *KubectlOptions, pvcName string, storageClassName string, size string, snapshotName string) (*corev1.PersistentVolumeClaim, error) {
+	apiGroup := volumeSnapshotAPIGroup
+	dataSource := &corev1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "VolumeSnapshot", Name: snapshotName}
+	return createPersistentVolumeClaimE(t, options, pvcName, storageClassName, size, dataSource)
+}
+
+// WaitUntilPersistentVolumeClaimBound waits until the given PersistentVolumeClaim is bound to a volume, retrying the
+// check for the specified amount of times, sleeping for the provided duration between each try. Note that claims of a
+// StorageClass with the WaitForFirstConsumer binding mode are only bound once a pod uses them. This will fail the test
+// if there is an error or if the check times out.
+func WaitUntilPersistentVolumeClaimBound(t testing.TestingT, options *KubectlOptions, pvcName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilPersistentVolumeClaimBoundE(t, options, pvcName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilPersistentVolumeClaimBoundE waits until the given PersistentVolumeClaim is bound to a volume, retrying the
+// check for the specified amount of times, sleeping for the provided duration between each try. Note that claims of a
+// StorageClass with the WaitForFirstConsumer binding mode are only bound once a pod uses them.
+func WaitUntilPersistentVolumeClaimBoundE(t testing.TestingT, options *KubectlOptions, pvcName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for PersistentVolumeClaim %s to be bound.", pvcName)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		pvc, err := GetPersistentVolumeClaimE(t, options, pvcName)
+		if err != nil {
+			return "", err
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			return "", fmt.Errorf("PersistentVolumeClaim %s is %s", pvcName, pvc.Status.Phase)
+		}
+		return "PersistentVolumeClaim is now bound", nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// ExpandPersistentVolumeClaim requests the given PersistentVolumeClaim to be resized to the given size, which requires
+// a StorageClass with allowVolumeExpansion. Use WaitUntilPersistentVolumeClaimCapacity to wait for the resize to
+// happen. This will fail the test if there is an error.
+func ExpandPersistentVolumeClaim(t testing.TestingT, options *KubectlOptions, pvcName string, size string) {
+	require.NoError(t, ExpandPersistentVolumeClaimE(t, options, pvcName, size))
+}
+
+// ExpandPersistentVolumeClaimE requests the given PersistentVolumeClaim to be resized to the given size, which
+// requires a StorageClass with allowVolumeExpansion. Use WaitUntilPersistentVolumeClaimCapacityE to wait for the
+// resize to happen.
+func ExpandPersistentVolumeClaimE(t testing.TestingT, options *KubectlOptions, pvcName string, size string) error {
+	if _, err := resource.ParseQuantity(size); err != nil {
+		return err
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Expanding PersistentVolumeClaim %s to %s", pvcName, size)
+	patch := fmt.Sprintf(`{"spec":{"resources":{"requests":{"storage":%q}}}}`, size)
+	_, err = clientset.CoreV1().PersistentVolumeClaims(options.Namespace).Patch(context.Background(), pvcName, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// WaitUntilPersistentVolumeClaimCapacity waits until the capacity of the volume bound to the given
+// PersistentVolumeClaim is at least the given size, e.g. after ExpandPersistentVolumeClaim. This will fail the test if
+// there is an error or if the check times out.
+func WaitUntilPersistentVolumeClaimCapacity(t testing.TestingT, options *KubectlOptions, pvcName string, size string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilPersistentVolumeClaimCapacityE(t, options, pvcName, size, retries, sleepBetweenRetries))
+}
+
+// WaitUntilPersistentVolumeClaimCapacityE waits until the capacity of the volume bound to the given
+// PersistentVolumeClaim is at least the given size, e.g. after ExpandPersistentVolumeClaimE.
+func WaitUntilPersistentVolumeClaimCapacityE(t testing.TestingT, options *KubectlOptions, pvcName string, size string, retries int, sleepBetweenRetries time.Duration) error {
+	expected, err := resource.ParseQuantity(size)
+	if err != nil {
+		return err
+	}
+
+	statusMsg := fmt.Sprintf("Wait for PersistentVolumeClaim %s to have a capacity of %s.", pvcName, size)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		pvc, err := GetPersistentVolumeClaimE(t, options, pvcName)
+		if err != nil {
+			return "", err
+		}
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(expected) < 0 {
+			return "", fmt.Errorf("PersistentVolumeClaim %s has a capacity of %s", pvcName, capacity.String())
+		}
+		return "PersistentVolumeClaim now has the expected capacity", nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// DeletePersistentVolumeClaim deletes the given PersistentVolumeClaim. Depending on the reclaim policy of its
+// StorageClass, this deletes the underlying volume too. This will fail the test if there is an error.
+func DeletePersistentVolumeClaim(t testing.TestingT, options *KubectlOptions, pvcName string) {
+	require.NoError(t, DeletePersistentVolumeClaimE(t, options, pvcName))
+}
+
+// DeletePersistentVolumeClaimE deletes the given PersistentVolumeClaim. Depending on the reclaim policy of its
+// StorageClass, this deletes the underlying volume too.
+func DeletePersistentVolumeClaimE(t testing.TestingT, options *KubectlOptions, pvcName string) error {
+	if dryrun.Skip(t, "delete PersistentVolumeClaim %s", pvcName) {
+		return nil
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().PersistentVolumeClaims(options.Namespace).Delete(context.Background(), pvcName, metav1.DeleteOptions{})
+}
+
+// CreateVolumeProbePod creates a long running pod that mounts the given PersistentVolumeClaim at /data, and waits for
+// it to be available, which also waits for the volume to be provisioned and attached. Use WriteVolumeProbeFile and
+// ReadVolumeProbeFile to check the volume works, and DeletePod to clean up. This will fail the test if there is an
+// error.
+func CreateVolumeProbePod(t testing.TestingT, options *KubectlOptions, podName string, pvcName string, retries int, sleepBetweenRetries time.Duration) *corev1.Pod {
+	pod, err := CreateVolumeProbePodE(t, options, podName, pvcName, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return pod
+}
+
+// CreateVolumeProbePodE creates a long running pod that mounts the given PersistentVolumeClaim at /data, and waits for
+// it to be available, which also waits for the volume to be provisioned and attached. Use WriteVolumeProbeFileE and
+// ReadVolumeProbeFileE to check the volume works, and DeletePodE to clean up.
+func CreateVolumeProbePodE(t testing.TestingT, options *KubectlOptions, podName string, pvcName string, retries int, sleepBetweenRetries time.Duration) (*corev1.Pod, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Creating probe pod %s for PersistentVolumeClaim %s", podName, pvcName)
+	pod := newVolumeProbePod(podName, options.Namespace, pvcName)
+	if _, err := clientset.CoreV1().Pods(options.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+
+	if err := WaitUntilPodAvailableE(t, options, podName, retries, sleepBetweenRetries); err != nil {
+		return nil, err
+	}
+	return GetPodE(t, options, podName)
+}
+
+// WriteVolumeProbeFile writes the given contents to the file with the given name on the volume mounted in the given
+// probe pod, and syncs it to the volume. This will fail the test if there is an error.
+func WriteVolumeProbeFile(t testing.TestingT, options *KubectlOptions, podName string, fileName string, contents string) {
+	require.NoError(t, WriteVolumeProbeFileE(t, options, podName, fileName, contents))
+}
+
+// WriteVolumeProbeFileE writes the given contents to the file with the given name on the volume mounted in the given
+// probe pod, and syncs it to the volume.
+func WriteVolumeProbeFileE(t testing.TestingT, options *KubectlOptions, podName string, fileName string, contents string) error {
+	// The contents and file name are passed as positional parameters, so they are never interpreted by the shell
+	script := fmt.Sprintf(`printf '%%s' "$1" > "%s/$2" && sync`, VolumeProbeMountPath)
+	_, err := RunKubectlAndGetOutputE(t, options, "exec", podName, "-c", volumeProbeContainerName, "--", "sh", "-c", script, "sh", contents, fileName)
+	return err
+}
+
+// ReadVolumeProbeFile reads the file with the given name from the volume mounted in the given probe pod. This will
+// fail the test if there is an error.
+func ReadVolumeProbeFile(t testing.TestingT, options *KubectlOptions, podName string, fileName string) string {
+	contents, err := ReadVolumeProbeFileE(t, options, podName, fileName)
+	require.NoError(t, err)
+	return contents
+}
+
+// ReadVolumeProbeFileE reads the file with the given name from the volume mounted in the given probe pod.
+func ReadVolumeProbeFileE(t testing.TestingT, options *KubectlOptions, podName string, fileName string) (string, error) {
+	return RunKubectlAndGetOutputE(t, options, "exec", podName, "-c", volumeProbeContainerName, "--", "cat", VolumeProbeMountPath+"/"+fileName)
+}
+
+// createPersistentVolumeClaimE creates a ReadWriteOnce PersistentVolumeClaim, optionally populated from the given data
+// source.
+func createPersistentVolumeClaimE(t testing.TestingT, options *KubectlOptions, pvcName string, storageClassName string, size string, dataSource *corev1.TypedLocalObjectReference) (*corev1.PersistentVolumeClaim, error) {
+	pvc, err := newPersistentVolumeClaim(pvcName, options.Namespace, storageClassName, size, dataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Creating PersistentVolumeClaim %s of %s from StorageClass %s", pvcName, size, storageClassName)
+	return clientset.CoreV1().PersistentVolumeClaims(options.Namespace).Create(context.Background(), pvc, metav1.CreateOptions{})
+}
+
+// newPersistentVolumeClaim returns the spec of a ReadWriteOnce PersistentVolumeClaim.
+func newPersistentVolumeClaim(pvcName string, namespace string, storageClassName string, size string, dataSource *corev1.TypedLocalObjectReference) (*corev1.PersistentVolumeClaim, error) {
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClassName,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+			},
+			DataSource: dataSource,
+		},
+	}, nil
+}
+
+// newVolumeProbePod returns the spec of a probe pod that mounts the given PersistentVolumeClaim.
+func newVolumeProbePod(podName string, namespace string, pvcName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:         volumeProbeContainerName,
+				Image:        VolumeProbeImage,
+				Command:      []string{"sleep", "3600"},
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: VolumeProbeMountPath}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName},
+				},
+			}},
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewPersistentVolumeClaimFromSnapshot(t *testing.T) {
+	t.Parallel()
+
+	apiGroup := volumeSnapshotAPIGroup
+	pvc, err := newPersistentVolumeClaim("restored", "storage-test", "gp3", "2Gi", &corev1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "VolumeSnapshot", Name: "data-snap"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gp3", *pvc.Spec.StorageClassName)
+	assert.Equal(t, "2Gi", pvc.Spec.Resources.Requests.Storage().String())
+	assert.Equal(t, "data-snap", pvc.Spec.DataSource.Name)
+
+	_, err = newPersistentVolumeClaim("invalid", "storage-test", "gp3", "two gigs", nil)
+	assert.Error(t, err)
+}
+
+func TestNewVolumeProbePodMountsClaim(t *testing.T) {
+	t.Parallel()
+
+	pod := newVolumeProbePod("probe", "storage-test", "data")
+
+	require.Len(t, pod.Spec.Containers, 1)
+	assert.Equal(t, VolumeProbeMountPath, pod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	require.Len(t, pod.Spec.Volumes, 1)
+	assert.Equal(t, "data", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+}
@@ -3,12 +3,14 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -148,3 +150,57 @@ func IsPodAvailable(pod *corev1.Pod) bool {
 	}
 	return pod.Status.Phase == corev1.PodRunning
 }
+
+// GetPodImages returns the images of the containers (including init containers) of the given pod. Where the pod status
+// reports the digest the image resolved to, the image is returned pinned to that digest (e.g.
+// "nginx@sha256:..."), so that it identifies exactly what was deployed, e.g. for a vulnerability scan.
+func GetPodImages(pod *corev1.Pod) []string {
+	imageIDs := map[string]string{}
+	for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		imageIDs[containerStatus.Name] = containerStatus.ImageID
+	}
+
+	images := []string{}
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		images = append(images, pinImageToDigest(container.Image, imageIDs[container.Name]))
+	}
+	return images
+}
+
+// pinImageToDigest returns the given image pinned to the digest of the given image ID as reported in a container status
+// (e.g. "docker-pullable://nginx@sha256:..."), or the image itself if the image ID does not contain a digest.
+func pinImageToDigest(image string, imageID string) string {
+	digestIndex := strings.Index(imageID, "@sha256:")
+	if digestIndex < 0 {
+		return image
+	}
+
+	repository := image
+	if atIndex := strings.Index(repository, "@"); atIndex >= 0 {
+		repository = repository[:atIndex]
+	}
+	// Strip the tag, taking care not to mistake the port of a registry host for one
+	if colonIndex := strings.LastIndex(repository, ":"); colonIndex > strings.LastIndex(repository, "/") {
+		repository = repository[:colonIndex]
+	}
+	return repository + imageID[digestIndex:]
+}
+
+// DeletePod deletes the pod with the given name in the namespace of the given KubectlOptions. This will fail the test
+// if there is an error.
+func DeletePod(t testing.TestingT, options *KubectlOptions, podName string) {
+	require.NoError(t, DeletePodE(t, options, podName))
+}
+
+// DeletePodE deletes the pod with the given name in the namespace of the given KubectlOptions.
+func DeletePodE(t testing.TestingT, options *KubectlOptions, podName string) error {
+	if dryrun.Skip(t, "delete pod %s", podName) {
+		return nil
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().Pods(options.Namespace).Delete(context.Background(), podName, metav1.DeleteOptions{})
+}
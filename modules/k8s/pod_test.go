@@ -211,3 +211,30 @@ func TestIsPodAvailable(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPodImagesPinsToDigest(t *testing.T) {
+	t.Parallel()
+
+	digest := "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox"}},
+			Containers: []corev1.Container{
+				{Name: "app", Image: "registry.example.com:5000/team/app:1.2.3"},
+				{Name: "sidecar", Image: "nginx:1.21"},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", ImageID: "docker-pullable://registry.example.com:5000/team/app@" + digest},
+				{Name: "sidecar", ImageID: ""},
+			},
+		},
+	}
+
+	require.Equal(t, []string{
+		"busybox",
+		"registry.example.com:5000/team/app@" + digest,
+		"nginx:1.21",
+	}, GetPodImages(pod))
+}
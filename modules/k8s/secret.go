@@ -3,6 +3,7 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
@@ -51,3 +52,119 @@ func WaitUntilSecretAvailable(t testing.TestingT, options *KubectlOptions, secre
 	)
 	logger.Logf(t, message)
 }
+
+// GetSecretData returns the data of the Kubernetes secret with the given name as a map of strings. The values are
+// already base64 decoded. The namespace used is the one provided in the KubectlOptions. This will fail the test if
+// there is an error.
+func GetSecretData(t testing.TestingT, options *KubectlOptions, secretName string) map[string]string {
+	data, err := GetSecretDataE(t, options, secretName)
+	require.NoError(t, err)
+	return data
+}
+
+// GetSecretDataE returns the data of the Kubernetes secret with the given name as a map of strings. The values are
+// already base64 decoded. The namespace used is the one provided in the KubectlOptions.
+func GetSecretDataE(t testing.TestingT, options *KubectlOptions, secretName string) (map[string]string, error) {
+	secret, err := GetSecretE(t, options, secretName)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	return data, nil
+}
+
+// AssertSecretData verifies that the Kubernetes secret with the given name contains the expected keys with the expected
+// (decoded) values. Keys in the secret that are not in expected are ignored. On a mismatch, the reported differences
+// only include the length of the values so that secrets are not written to the test logs. This will fail the test if
+// the data does not match or there is an error.
+func AssertSecretData(t testing.TestingT, options *KubectlOptions, secretName string, expected map[string]string) {
+	require.NoError(t, AssertSecretDataE(t, options, secretName, expected))
+}
+
+// AssertSecretDataE verifies that the Kubernetes secret with the given name contains the expected keys with the
+// expected (decoded) values. Keys in the secret that are not in expected are ignored. On a mismatch, the reported
+// differences only include the length of the values so that secrets are not written to the test logs.
+func AssertSecretDataE(t testing.TestingT, options *KubectlOptions, secretName string, expected map[string]string) error {
+	data, err := GetSecretDataE(t, options, secretName)
+	if err != nil {
+		return err
+	}
+	differences := diffResourceData(expected, data, true)
+	if len(differences) > 0 {
+		return NewResourceDataMismatchError("Secret", secretName, differences)
+	}
+	return nil
+}
+
+// WaitUntilSecretHasKeys waits until the secret with the given name exists and contains all the given keys. This is
+// useful for secrets that are synced from an external store, e.g. by external-secrets or the Secrets Store CSI driver,
+// and populated some time after the workload is deployed. This will fail the test if the keys do not appear within the
+// given number of retries.
+func WaitUntilSecretHasKeys(t testing.TestingT, options *KubectlOptions, secretName string, keys []string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilSecretHasKeysE(t, options, secretName, keys, retries, sleepBetweenRetries))
+}
+
+// WaitUntilSecretHasKeysE waits until the secret with the given name exists and contains all the given keys. This is
+// useful for secrets that are synced from an external store, e.g. by external-secrets or the Secrets Store CSI driver,
+// and populated some time after the workload is deployed.
+func WaitUntilSecretHasKeysE(t testing.TestingT, options *KubectlOptions, secretName string, keys []string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for secret %s to have keys %v.", secretName, keys)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			data, err := GetSecretDataE(t, options, secretName)
+			if err != nil {
+				return "", err
+			}
+			if missing := missingResourceKeys(keys, data); len(missing) > 0 {
+				return "", NewResourceKeysMissingError("Secret", secretName, missing)
+			}
+			return "Secret now has all keys", nil
+		},
+	)
+	if err != nil {
+		logger.Logf(t, "Timed out waiting for Secret %s to have keys: %s", secretName, err)
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// diffResourceData returns a sorted description of each key in expected that is missing from actual or has a different
+// value. When redact is true, values are replaced by their length.
+func diffResourceData(expected map[string]string, actual map[string]string, redact bool) []string {
+	differences := []string{}
+	for key, expectedValue := range expected {
+		actualValue, ok := actual[key]
+		switch {
+		case !ok:
+			differences = append(differences, fmt.Sprintf("%s: missing", key))
+		case actualValue == expectedValue:
+			continue
+		case redact:
+			differences = append(differences, fmt.Sprintf("%s: expected <redacted, %d bytes> but got <redacted, %d bytes>", key, len(expectedValue), len(actualValue)))
+		default:
+			differences = append(differences, fmt.Sprintf("%s: expected %q but got %q", key, expectedValue, actualValue))
+		}
+	}
+	sort.Strings(differences)
+	return differences
+}
+
+// missingResourceKeys returns the sorted keys that are not present in data.
+func missingResourceKeys(keys []string, data map[string]string) []string {
+	missing := []string{}
+	for _, key := range keys {
+		if _, ok := data[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -53,6 +53,47 @@ func TestWaitUntilSecretAvailableReturnsSuccessfully(t *testing.T) {
 	WaitUntilSecretAvailable(t, options, "master-password", 10, 1*time.Second)
 }
 
+func TestAssertSecretDataMatchesDecodedValues(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(EXAMPLE_SECRET_WITH_DATA_YAML_TEMPLATE, uniqueID, uniqueID)
+	defer KubectlDeleteFromString(t, options, configData)
+	KubectlApplyFromString(t, options, configData)
+
+	WaitUntilSecretHasKeys(t, options, "db-credentials", []string{"username", "password"}, 10, 1*time.Second)
+	AssertSecretData(t, options, "db-credentials", map[string]string{"username": "admin", "password": "hunter2"})
+
+	err := AssertSecretDataE(t, options, "db-credentials", map[string]string{"password": "wrong"})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestDiffResourceDataRedactsValues(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]string{"same": "a", "changed": "secret", "missing": "b"}
+	actual := map[string]string{"same": "a", "changed": "other-value", "extra": "c"}
+
+	require.Equal(t, []string{
+		"changed: expected <redacted, 6 bytes> but got <redacted, 11 bytes>",
+		"missing: missing",
+	}, diffResourceData(expected, actual, true))
+	require.Equal(t, []string{
+		"changed: expected \"secret\" but got \"other-value\"",
+		"missing: missing",
+	}, diffResourceData(expected, actual, false))
+}
+
+func TestMissingResourceKeys(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]string{"a": "1", "b": "2"}
+	require.Equal(t, []string{}, missingResourceKeys([]string{"a", "b"}, data))
+	require.Equal(t, []string{"c", "d"}, missingResourceKeys([]string{"d", "a", "c"}, data))
+}
+
 const EXAMPLE_SECRET_YAML_TEMPLATE = `---
 apiVersion: v1
 kind: Namespace
@@ -65,3 +106,19 @@ metadata:
   name: master-password
   namespace: %s
 `
+
+const EXAMPLE_SECRET_WITH_DATA_YAML_TEMPLATE = `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-credentials
+  namespace: %s
+data:
+  username: YWRtaW4=
+  password: aHVudGVyMg==
+`
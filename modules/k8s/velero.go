@@ -0,0 +1,313 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// veleroAPIGroup is the API group of the Velero resources.
+	veleroAPIGroup = "velero.io"
+
+	// veleroAPIPath is the API path of the Velero resources.
+	veleroAPIPath = "/apis/" + veleroAPIGroup + "/v1"
+
+	// DefaultVeleroNamespace is the namespace Velero is installed in by default, which the helpers in this file use when
+	// the namespace of the given KubectlOptions is not set.
+	DefaultVeleroNamespace = "velero"
+)
+
+// The phases of Velero backups and restores.
+const (
+	VeleroPhaseCompleted        = "Completed"
+	VeleroPhasePartiallyFailed  = "PartiallyFailed"
+	VeleroPhaseFailed           = "Failed"
+	VeleroPhaseFailedValidation = "FailedValidation"
+)
+
+// VeleroBackup is the subset of a Velero Backup resource that the helpers in this file use.
+type VeleroBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VeleroBackupSpec `json:"spec"`
+	Status            *VeleroStatus    `json:"status,omitempty"`
+}
+
+// VeleroBackupSpec is the spec of a Velero Backup.
+type VeleroBackupSpec struct {
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+	TTL                string   `json:"ttl,omitempty"`
+}
+
+// VeleroRestore is the subset of a Velero Restore resource that the helpers in this file use.
+type VeleroRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VeleroRestoreSpec `json:"spec"`
+	Status            *VeleroStatus     `json:"status,omitempty"`
+}
+
+// VeleroRestoreSpec is the spec of a Velero Restore.
+type VeleroRestoreSpec struct {
+	BackupName         string   `json:"backupName"`
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+}
+
+// VeleroStatus is the subset of the status of a Velero Backup or Restore that the helpers in this file use.
+type VeleroStatus struct {
+	Phase            string   `json:"phase,omitempty"`
+	Errors           int      `json:"errors,omitempty"`
+	Warnings         int      `json:"warnings,omitempty"`
+	FailureReason    string   `json:"failureReason,omitempty"`
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+}
+
+// CreateVeleroBackup creates a Velero Backup of the given namespaces in the namespace of the given options, which is
+// where Velero is installed (velero if not set). This will fail the test if there is an error.
+func CreateVeleroBackup(t testing.TestingT, options *KubectlOptions, backupName string, namespaces ...string) {
+	require.NoError(t, CreateVeleroBackupE(t, options, backupName, namespaces...))
+}
+
+// CreateVeleroBackupE creates a Velero Backup of the given namespaces in the namespace of the given options, which is
+// where Velero is installed (velero if not set). Use WaitUntilVeleroBackupCompletedE to wait for it.
+func CreateVeleroBackupE(t testing.TestingT, options *KubectlOptions, backupName string, namespaces ...string) error {
+	backup := &VeleroBackup{
+		TypeMeta:   metav1.TypeMeta{APIVersion: veleroAPIGroup + "/v1", Kind: "Backup"},
+		ObjectMeta: metav1.ObjectMeta{Name: backupName, Namespace: veleroNamespace(options)},
+		Spec:       VeleroBackupSpec{IncludedNamespaces: namespaces},
+	}
+
+	logger.Logf(t, "Creating Velero Backup %s of namespaces %v", backupName, namespaces)
+	return createVeleroResourceE(t, options, "backups", backup)
+}
+
+// GetVeleroBackup returns the Velero Backup with the given name. This will fail the test if there is an error.
+func GetVeleroBackup(t testing.TestingT, options *KubectlOptions, backupName string) *VeleroBackup {
+	backup, err := GetVeleroBackupE(t, options, backupName)
+	require.NoError(t, err)
+	return backup
+}
+
+// GetVeleroBackupE returns the Velero Backup with the given name.
+func GetVeleroBackupE(t testing.TestingT, options *KubectlOptions, backupName string) (*VeleroBackup, error) {
+	backup := &VeleroBackup{}
+	if err := getVeleroResourceE(t, options, "backups", backupName, backup); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// WaitUntilVeleroBackupCompleted waits until the given Velero Backup has completed, retrying the check for the specified
+// amount of times, sleeping for the provided duration between each try. This will fail the test if there is an error,
+// if the backup fails or if the check times out.
+func WaitUntilVeleroBackupCompleted(t testing.TestingT, options *KubectlOptions, backupName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilVeleroBackupCompletedE(t, options, backupName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilVeleroBackupCompletedE waits until the given Velero Backup has completed, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try. Returns a VeleroOperationFailed error
+// right away if the backup fails or partially fails.
+func WaitUntilVeleroBackupCompletedE(t testing.TestingT, options *KubectlOptions, backupName string, retries int, sleepBetweenRetries time.Duration) error {
+	return waitUntilVeleroOperationCompletedE(t, "Backup", backupName, retries, sleepBetweenRetries, func() (*VeleroStatus, error) {
+		backup, err := GetVeleroBackupE(t, options, backupName)
+		if err != nil {
+			return nil, err
+		}
+		return backup.Status, nil
+	})
+}
+
+// CreateVeleroRestore creates a Velero Restore from the given Velero Backup in the namespace of the given options, which
+// is where Velero is installed (velero if not set). This will fail the test if there is an error.
+func CreateVeleroRestore(t testing.TestingT, options *KubectlOptions, restoreName string, backupName string) {
+	require.NoError(t, CreateVeleroRestoreE(t, options, restoreName, backupName))
+}
+
+// CreateVeleroRestoreE creates a Velero Restore from the given Velero Backup in the namespace of the given options,
+// which is where Velero is installed (velero if not set). Velero does not overwrite resources that already exist, so
+// delete the backed up namespaces first, e.g. with DeleteNamespaceE and WaitUntilNamespaceDeletedE. Use
+// WaitUntilVeleroRestoreCompletedE to wait for the restore.
+func CreateVeleroRestoreE(t testing.TestingT, options *KubectlOptions, restoreName string, backupName string) error {
+	restore := &VeleroRestore{
+		TypeMeta:   metav1.TypeMeta{APIVersion: veleroAPIGroup + "/v1", Kind: "Restore"},
+		ObjectMeta: metav1.ObjectMeta{Name: restoreName, Namespace: veleroNamespace(options)},
+		Spec:       VeleroRestoreSpec{BackupName: backupName},
+	}
+
+	logger.Logf(t, "Creating Velero Restore %s from Backup %s", restoreName, backupName)
+	return createVeleroResourceE(t, options, "restores", restore)
+}
+
+// GetVeleroRestore returns the Velero Restore with the given name. This will fail the test if there is an error.
+func GetVeleroRestore(t testing.TestingT, options *KubectlOptions, restoreName string) *VeleroRestore {
+	restore, err := GetVeleroRestoreE(t, options, restoreName)
+	require.NoError(t, err)
+	return restore
+}
+
+// GetVeleroRestoreE returns the Velero Restore with the given name.
+func GetVeleroRestoreE(t testing.TestingT, options *KubectlOptions, restoreName string) (*VeleroRestore, error) {
+	restore := &VeleroRestore{}
+	if err := getVeleroResourceE(t, options, "restores", restoreName, restore); err != nil {
+		return nil, err
+	}
+	return restore, nil
+}
+
+// WaitUntilVeleroRestoreCompleted waits until the given Velero Restore has completed, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try. This will fail the test if there is
+// an error, if the restore fails or if the check times out.
+func WaitUntilVeleroRestoreCompleted(t testing.TestingT, options *KubectlOptions, restoreName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilVeleroRestoreCompletedE(t, options, restoreName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilVeleroRestoreCompletedE waits until the given Velero Restore has completed, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try. Returns a VeleroOperationFailed error
+// right away if the restore fails or partially fails.
+func WaitUntilVeleroRestoreCompletedE(t testing.TestingT, options *KubectlOptions, restoreName string, retries int, sleepBetweenRetries time.Duration) error {
+	return waitUntilVeleroOperationCompletedE(t, "Restore", restoreName, retries, sleepBetweenRetries, func() (*VeleroStatus, error) {
+		restore, err := GetVeleroRestoreE(t, options, restoreName)
+		if err != nil {
+			return nil, err
+		}
+		return restore.Status, nil
+	})
+}
+
+// BackupAndRestoreNamespaceWithVelero backs up the given namespace with Velero, deletes it, and restores it from the
+// backup, so that the test can then check that its resources and data came back. This will fail the test if there is
+// an error.
+func BackupAndRestoreNamespaceWithVelero(t testing.TestingT, veleroOptions *KubectlOptions, namespace string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, BackupAndRestoreNamespaceWithVeleroE(t, veleroOptions, namespace, retries, sleepBetweenRetries))
+}
+
+// BackupAndRestoreNamespaceWithVeleroE backs up the given namespace with Velero, deletes it, waits until it is gone,
+// and restores it from the backup, so that the test can then check that its resources and data came back. The given
+// options point at the namespace Velero is installed in (velero if not set). Each step is waited for with the given
+// retries, and the backup and restore are named after the namespace with a unique suffix.
+func BackupAndRestoreNamespaceWithVeleroE(t testing.TestingT, veleroOptions *KubectlOptions, namespace string, retries int, sleepBetweenRetries time.Duration) error {
+	name := veleroOperationName(namespace)
+
+	if err := CreateVeleroBackupE(t, veleroOptions, name, namespace); err != nil {
+		return err
+	}
+	if err := WaitUntilVeleroBackupCompletedE(t, veleroOptions, name, retries, sleepBetweenRetries); err != nil {
+		return err
+	}
+
+	if err := DeleteNamespaceE(t, veleroOptions, namespace); err != nil {
+		return err
+	}
+	if err := WaitUntilNamespaceDeletedE(t, veleroOptions, namespace, retries, sleepBetweenRetries); err != nil {
+		return err
+	}
+
+	if err := CreateVeleroRestoreE(t, veleroOptions, name, name); err != nil {
+		return err
+	}
+	return WaitUntilVeleroRestoreCompletedE(t, veleroOptions, name, retries, sleepBetweenRetries)
+}
+
+// waitUntilVeleroOperationCompletedE waits until the status returned by getStatus is Completed, returning a
+// VeleroOperationFailed error right away if it is a failed phase.
+func waitUntilVeleroOperationCompletedE(t testing.TestingT, kind string, name string, retries int, sleepBetweenRetries time.Duration, getStatus func() (*VeleroStatus, error)) error {
+	statusMsg := fmt.Sprintf("Wait for Velero %s %s to complete.", kind, name)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		status, err := getStatus()
+		if err != nil {
+			return "", err
+		}
+		return checkVeleroStatus(kind, name, status)
+	})
+	if actualErr, ok := err.(retry.FatalError); ok {
+		return actualErr.Underlying
+	}
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// checkVeleroStatus returns an error if the given status of a Velero Backup or Restore is not Completed, which is a
+// retry.FatalError if the operation failed.
+func checkVeleroStatus(kind string, name string, status *VeleroStatus) (string, error) {
+	if status == nil || status.Phase == "" {
+		return "", fmt.Errorf("Velero %s %s has not started", kind, name)
+	}
+
+	switch status.Phase {
+	case VeleroPhaseCompleted:
+		return fmt.Sprintf("Velero %s %s completed with %d warnings", kind, name, status.Warnings), nil
+	case VeleroPhaseFailed, VeleroPhasePartiallyFailed, VeleroPhaseFailedValidation:
+		return "", retry.FatalError{Underlying: NewVeleroOperationFailedError(kind, name, status)}
+	default:
+		return "", fmt.Errorf("Velero %s %s is %s", kind, name, status.Phase)
+	}
+}
+
+// veleroOperationName returns a unique name for a backup or restore of the given namespace.
+func veleroOperationName(namespace string) string {
+	return fmt.Sprintf("%s-%s", namespace, strings.ToLower(random.UniqueId()))
+}
+
+// veleroNamespace returns the namespace Velero is installed in according to the given options.
+func veleroNamespace(options *KubectlOptions) string {
+	if options.Namespace == "" {
+		return DefaultVeleroNamespace
+	}
+	return options.Namespace
+}
+
+// createVeleroResourceE creates the given Velero resource of the given kind, e.g. backups.
+func createVeleroResourceE(t testing.TestingT, options *KubectlOptions, resource string, object interface{}) error {
+	body, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	// There is no typed client for the Velero resources in client-go, so use the raw REST client
+	return clientset.Discovery().RESTClient().Post().
+		AbsPath(veleroResourcesPath(veleroNamespace(options), resource)).
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do(context.Background()).
+		Error()
+}
+
+// getVeleroResourceE gets the Velero resource of the given kind, e.g. backups, with the given name into the given
+// object.
+func getVeleroResourceE(t testing.TestingT, options *KubectlOptions, resource string, name string, object interface{}) error {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	raw, err := clientset.Discovery().RESTClient().Get().
+		AbsPath(veleroResourcesPath(veleroNamespace(options), resource), name).
+		DoRaw(context.Background())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, object)
+}
+
+// veleroResourcesPath returns the API path of the Velero resources of the given kind in the given namespace.
+func veleroResourcesPath(namespace string, resource string) string {
+	return fmt.Sprintf("%s/namespaces/%s/%s", veleroAPIPath, namespace, resource)
+}
@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckVeleroStatus(t *testing.T) {
+	t.Parallel()
+
+	_, err := checkVeleroStatus("Backup", "app-backup", nil)
+	assert.EqualError(t, err, "Velero Backup app-backup has not started")
+
+	_, err = checkVeleroStatus("Backup", "app-backup", &VeleroStatus{Phase: "InProgress"})
+	assert.EqualError(t, err, "Velero Backup app-backup is InProgress")
+
+	message, err := checkVeleroStatus("Restore", "app-restore", &VeleroStatus{Phase: VeleroPhaseCompleted, Warnings: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "Velero Restore app-restore completed with 2 warnings", message)
+
+	status := &VeleroStatus{}
+	require.NoError(t, json.Unmarshal([]byte(`{"phase": "PartiallyFailed", "errors": 3, "failureReason": "volume snapshot failed"}`), status))
+	_, err = checkVeleroStatus("Backup", "app-backup", status)
+	fatalErr, ok := err.(retry.FatalError)
+	require.True(t, ok)
+	assert.EqualError(t, fatalErr.Underlying, "Velero Backup app-backup is PartiallyFailed with 3 errors: volume snapshot failed")
+	assert.True(t, errors.Is(fatalErr.Underlying, ErrFailed))
+}
+
+func TestVeleroResourcesPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/apis/velero.io/v1/namespaces/velero/backups", veleroResourcesPath(veleroNamespace(&KubectlOptions{}), "backups"))
+	assert.Equal(t, "/apis/velero.io/v1/namespaces/backup-system/restores", veleroResourcesPath(veleroNamespace(&KubectlOptions{Namespace: "backup-system"}), "restores"))
+	assert.Regexp(t, "^app-[a-z0-9]{6}$", veleroOperationName("app"))
+}
@@ -0,0 +1,185 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// volumeSnapshotAPIGroup is the API group of the CSI VolumeSnapshot resources.
+	volumeSnapshotAPIGroup = "snapshot.storage.k8s.io"
+
+	// volumeSnapshotAPIPath is the API path of the CSI VolumeSnapshot resources.
+	volumeSnapshotAPIPath = "/apis/" + volumeSnapshotAPIGroup + "/v1"
+)
+
+// VolumeSnapshot is the subset of a CSI VolumeSnapshot resource that the helpers in this file use.
+type VolumeSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VolumeSnapshotSpec    `json:"spec"`
+	Status            *VolumeSnapshotStatus `json:"status,omitempty"`
+}
+
+// VolumeSnapshotSpec is the spec of a CSI VolumeSnapshot.
+type VolumeSnapshotSpec struct {
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	Source                  struct {
+		PersistentVolumeClaimName *string `json:"persistentVolumeClaimName,omitempty"`
+	} `json:"source"`
+}
+
+// VolumeSnapshotStatus is the subset of the status of a CSI VolumeSnapshot that the helpers in this file use.
+type VolumeSnapshotStatus struct {
+	ReadyToUse  *bool  `json:"readyToUse,omitempty"`
+	RestoreSize string `json:"restoreSize,omitempty"`
+	Error       *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// IsVolumeSnapshotReady returns true if the given VolumeSnapshot can be used to restore a volume.
+func IsVolumeSnapshotReady(snapshot *VolumeSnapshot) bool {
+	return snapshot.Status != nil && snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse
+}
+
+// CreateVolumeSnapshot creates a CSI VolumeSnapshot of the given PersistentVolumeClaim with the given
+// VolumeSnapshotClass. Use CreatePersistentVolumeClaimFromSnapshot to restore it. This will fail the test if there is
+// an error.
+func CreateVolumeSnapshot(t testing.TestingT, options *KubectlOptions, snapshotName string, pvcName string, snapshotClassName string) {
+	require.NoError(t, CreateVolumeSnapshotE(t, options, snapshotName, pvcName, snapshotClassName))
+}
+
+// CreateVolumeSnapshotE creates a CSI VolumeSnapshot of the given PersistentVolumeClaim with the given
+// VolumeSnapshotClass. Use CreatePersistentVolumeClaimFromSnapshotE to restore it.
+func CreateVolumeSnapshotE(t testing.TestingT, options *KubectlOptions, snapshotName string, pvcName string, snapshotClassName string) error {
+	body, err := json.Marshal(newVolumeSnapshot(snapshotName, options.Namespace, pvcName, snapshotClassName))
+	if err != nil {
+		return err
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	// There is no typed client for VolumeSnapshots in client-go, so use the raw REST client
+	logger.Logf(t, "Creating VolumeSnapshot %s of PersistentVolumeClaim %s", snapshotName, pvcName)
+	return clientset.Discovery().RESTClient().Post().
+		AbsPath(volumeSnapshotsPath(options.Namespace)).
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do(context.Background()).
+		Error()
+}
+
+// GetVolumeSnapshot returns the CSI VolumeSnapshot with the given name. This will fail the test if there is an error.
+func GetVolumeSnapshot(t testing.TestingT, options *KubectlOptions, snapshotName string) *VolumeSnapshot {
+	snapshot, err := GetVolumeSnapshotE(t, options, snapshotName)
+	require.NoError(t, err)
+	return snapshot
+}
+
+// GetVolumeSnapshotE returns the CSI VolumeSnapshot with the given name.
+func GetVolumeSnapshotE(t testing.TestingT, options *KubectlOptions, snapshotName string) (*VolumeSnapshot, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := clientset.Discovery().RESTClient().Get().
+		AbsPath(volumeSnapshotsPath(options.Namespace), snapshotName).
+		DoRaw(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &VolumeSnapshot{}
+	if err := json.Unmarshal(raw, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// WaitUntilVolumeSnapshotReady waits until the given CSI VolumeSnapshot is ready to use, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try. This will fail the test if there is
+// an error or if the check times out.
+func WaitUntilVolumeSnapshotReady(t testing.TestingT, options *KubectlOptions, snapshotName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilVolumeSnapshotReadyE(t, options, snapshotName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilVolumeSnapshotReadyE waits until the given CSI VolumeSnapshot is ready to use, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try.
+func WaitUntilVolumeSnapshotReadyE(t testing.TestingT, options *KubectlOptions, snapshotName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for VolumeSnapshot %s to be ready to use.", snapshotName)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		snapshot, err := GetVolumeSnapshotE(t, options, snapshotName)
+		if err != nil {
+			return "", err
+		}
+		if !IsVolumeSnapshotReady(snapshot) {
+			if snapshot.Status != nil && snapshot.Status.Error != nil {
+				return "", fmt.Errorf("VolumeSnapshot %s is not ready: %s", snapshotName, snapshot.Status.Error.Message)
+			}
+			return "", fmt.Errorf("VolumeSnapshot %s is not ready", snapshotName)
+		}
+		return "VolumeSnapshot is now ready to use", nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// DeleteVolumeSnapshot deletes the given CSI VolumeSnapshot. This will fail the test if there is an error.
+func DeleteVolumeSnapshot(t testing.TestingT, options *KubectlOptions, snapshotName string) {
+	require.NoError(t, DeleteVolumeSnapshotE(t, options, snapshotName))
+}
+
+// DeleteVolumeSnapshotE deletes the given CSI VolumeSnapshot.
+func DeleteVolumeSnapshotE(t testing.TestingT, options *KubectlOptions, snapshotName string) error {
+	if dryrun.Skip(t, "delete VolumeSnapshot %s", snapshotName) {
+		return nil
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	return clientset.Discovery().RESTClient().Delete().
+		AbsPath(volumeSnapshotsPath(options.Namespace), snapshotName).
+		Do(context.Background()).
+		Error()
+}
+
+// volumeSnapshotsPath returns the API path of the VolumeSnapshots in the given namespace.
+func volumeSnapshotsPath(namespace string) string {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return fmt.Sprintf("%s/namespaces/%s/volumesnapshots", volumeSnapshotAPIPath, namespace)
+}
+
+// newVolumeSnapshot returns a VolumeSnapshot of the given PersistentVolumeClaim.
+func newVolumeSnapshot(snapshotName string, namespace string, pvcName string, snapshotClassName string) *VolumeSnapshot {
+	snapshot := &VolumeSnapshot{
+		TypeMeta:   metav1.TypeMeta{APIVersion: volumeSnapshotAPIGroup + "/v1", Kind: "VolumeSnapshot"},
+		ObjectMeta: metav1.ObjectMeta{Name: snapshotName, Namespace: namespace},
+	}
+	snapshot.Spec.Source.PersistentVolumeClaimName = &pvcName
+	if snapshotClassName != "" {
+		snapshot.Spec.VolumeSnapshotClassName = &snapshotClassName
+	}
+	return snapshot
+}
@@ -0,0 +1,35 @@
+package k8s
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVolumeSnapshot(t *testing.T) {
+	t.Parallel()
+
+	body, err := json.Marshal(newVolumeSnapshot("data-snap", "storage-test", "data", "csi-aws-vsc"))
+	require.NoError(t, err)
+
+	var object map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &object))
+	assert.Equal(t, "snapshot.storage.k8s.io/v1", object["apiVersion"])
+	assert.Equal(t, "VolumeSnapshot", object["kind"])
+	assert.Equal(t, map[string]interface{}{
+		"volumeSnapshotClassName": "csi-aws-vsc",
+		"source":                  map[string]interface{}{"persistentVolumeClaimName": "data"},
+	}, object["spec"])
+	assert.NotContains(t, object, "status")
+}
+
+func TestIsVolumeSnapshotReady(t *testing.T) {
+	t.Parallel()
+
+	snapshot := &VolumeSnapshot{}
+	require.NoError(t, json.Unmarshal([]byte(`{"metadata": {"name": "data-snap"}, "status": {"readyToUse": true, "restoreSize": "1Gi"}}`), snapshot))
+	assert.True(t, IsVolumeSnapshotReady(snapshot))
+	assert.False(t, IsVolumeSnapshotReady(&VolumeSnapshot{}))
+}
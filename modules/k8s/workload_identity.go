@@ -0,0 +1,220 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	workloadIdentityProbeContainerName = "probe"
+	workloadIdentityProbeRetries       = 60
+	workloadIdentityProbeSleep         = 2 * time.Second
+)
+
+// WorkloadIdentityProvider describes how to look up the cloud identity that a pod gets from its service account, e.g.
+// with IAM Roles for Service Accounts (IRSA) on EKS or Workload Identity on GKE.
+type WorkloadIdentityProvider struct {
+	Name string
+
+	// The image of the probe pods, which must provide the CLI of the cloud and sleep.
+	Image string
+
+	// The command that makes an API call to the cloud and prints the identity it was made as.
+	Command []string
+
+	// Extracts the identity to compare with the expected one from the output of the command.
+	ParseIdentity func(output string) string
+}
+
+var (
+	// AwsIrsa looks up the IAM role that a pod assumes with IAM Roles for Service Accounts, by calling STS
+	// GetCallerIdentity. The identity is the name of the role, e.g. "my-app" for
+	// arn:aws:sts::123456789012:assumed-role/my-app/botocore-session-1634567890.
+	AwsIrsa = WorkloadIdentityProvider{
+		Name:          "IRSA",
+		Image:         "amazon/aws-cli:2.2.46",
+		Command:       []string{"aws", "sts", "get-caller-identity", "--query", "Arn", "--output", "text"},
+		ParseIdentity: parseAssumedRoleName,
+	}
+
+	// GcpWorkloadIdentity looks up the Google service account that a pod acts as with GKE Workload Identity, by
+	// fetching an access token for it. The identity is the email of the service account, e.g.
+	// my-app@my-project.iam.gserviceaccount.com.
+	GcpWorkloadIdentity = WorkloadIdentityProvider{
+		Name:          "Workload Identity",
+		Image:         "google/cloud-sdk:360.0.0-slim",
+		Command:       []string{"sh", "-c", "gcloud auth print-access-token > /dev/null && gcloud config get-value account"},
+		ParseIdentity: strings.TrimSpace,
+	}
+)
+
+// CreateWorkloadIdentityProbePod creates a long running pod with the CLI of the given provider that runs as the given
+// service account, and waits for it to be available. Use GetWorkloadIdentityFromPod to look up the identity it gets,
+// and DeletePod to clean up. This will fail the test if there is an error.
+func CreateWorkloadIdentityProbePod(t testing.TestingT, options *KubectlOptions, podName string, provider WorkloadIdentityProvider, serviceAccountName string) *corev1.Pod {
+	pod, err := CreateWorkloadIdentityProbePodE(t, options, podName, provider, serviceAccountName)
+	require.NoError(t, err)
+	return pod
+}
+
+// CreateWorkloadIdentityProbePodE creates a long running pod with the CLI of the given provider that runs as the given
+// service account, or the default one of the namespace if it is empty, and waits for it to be available. Use
+// GetWorkloadIdentityFromPodE to look up the identity it gets, and DeletePodE to clean up.
+func CreateWorkloadIdentityProbePodE(t testing.TestingT, options *KubectlOptions, podName string, provider WorkloadIdentityProvider, serviceAccountName string) (*corev1.Pod, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Creating %s probe pod %s with service account %q", provider.Name, podName, serviceAccountName)
+	pod := newWorkloadIdentityProbePod(podName, options.Namespace, provider, serviceAccountName)
+	if _, err := clientset.CoreV1().Pods(options.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+
+	if err := WaitUntilPodAvailableE(t, options, podName, workloadIdentityProbeRetries, workloadIdentityProbeSleep); err != nil {
+		return nil, err
+	}
+	return GetPodE(t, options, podName)
+}
+
+// GetWorkloadIdentityFromPod makes an API call to the cloud of the given provider from inside the given probe pod and
+// returns the identity it was made as. This will fail the test if there is an error.
+func GetWorkloadIdentityFromPod(t testing.TestingT, options *KubectlOptions, podName string, provider WorkloadIdentityProvider) string {
+	identity, err := GetWorkloadIdentityFromPodE(t, options, podName, provider)
+	require.NoError(t, err)
+	return identity
+}
+
+// GetWorkloadIdentityFromPodE makes an API call to the cloud of the given provider from inside the given probe pod and
+// returns the identity it was made as. An error is returned if the call fails, e.g. because the pod has no credentials.
+func GetWorkloadIdentityFromPodE(t testing.TestingT, options *KubectlOptions, podName string, provider WorkloadIdentityProvider) (string, error) {
+	args := append([]string{"exec", podName, "-c", workloadIdentityProbeContainerName, "--"}, provider.Command...)
+	output, err := RunKubectlAndGetOutputE(t, options, args...)
+	if err != nil {
+		return "", fmt.Errorf("%s API call from pod %s failed: %s: %s", provider.Name, podName, err, output)
+	}
+	return provider.ParseIdentity(output), nil
+}
+
+// AssertWorkloadIdentity checks that a pod running as the given service account gets the expected cloud identity from
+// the given provider, while a control pod running as the default service account of the namespace does not. This will
+// fail the test if there is an error.
+func AssertWorkloadIdentity(t testing.TestingT, options *KubectlOptions, provider WorkloadIdentityProvider, serviceAccountName string, expectedIdentity string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, AssertWorkloadIdentityE(t, options, provider, serviceAccountName, expectedIdentity, retries, sleepBetweenRetries))
+}
+
+// AssertWorkloadIdentityE checks that a pod running as the given service account gets the expected cloud identity from
+// the given provider, e.g. the name of the IAM role in the eks.amazonaws.com/role-arn annotation of the service account
+// for AwsIrsa, and that a control pod running as the default service account of the namespace, which must not have the
+// annotation, does not. The call from the probe pod is retried, as new IAM bindings take a while to propagate. Returns
+// a WorkloadIdentityMismatch error if the probe pod does not get the expected identity, and a
+// WorkloadIdentityNotIsolated error if the control pod gets it too, e.g. because it is the identity of the nodes. The
+// pods are deleted afterwards.
+func AssertWorkloadIdentityE(t testing.TestingT, options *KubectlOptions, provider WorkloadIdentityProvider, serviceAccountName string, expectedIdentity string, retries int, sleepBetweenRetries time.Duration) error {
+	uniqueID := strings.ToLower(random.UniqueId())
+	probePodName := fmt.Sprintf("workload-identity-probe-%s", uniqueID)
+	controlPodName := fmt.Sprintf("workload-identity-control-%s", uniqueID)
+
+	err := assertWorkloadIdentityOfProbePodE(t, options, probePodName, provider, serviceAccountName, expectedIdentity, retries, sleepBetweenRetries)
+	if deleteErr := DeletePodE(t, options, probePodName); deleteErr != nil && err == nil {
+		err = deleteErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = assertWorkloadIdentityOfControlPodE(t, options, controlPodName, provider, expectedIdentity)
+	if deleteErr := DeletePodE(t, options, controlPodName); deleteErr != nil && err == nil {
+		err = deleteErr
+	}
+	return err
+}
+
+// assertWorkloadIdentityOfProbePodE creates a probe pod running as the given service account and waits until it gets
+// the expected identity.
+func assertWorkloadIdentityOfProbePodE(t testing.TestingT, options *KubectlOptions, podName string, provider WorkloadIdentityProvider, serviceAccountName string, expectedIdentity string, retries int, sleepBetweenRetries time.Duration) error {
+	if _, err := CreateWorkloadIdentityProbePodE(t, options, podName, provider, serviceAccountName); err != nil {
+		return err
+	}
+
+	var lastErr error
+	statusMsg := fmt.Sprintf("Wait for pod %s to get %s identity %s.", podName, provider.Name, expectedIdentity)
+	message, err := retry.DoWithRetryE(t, statusMsg, retries, sleepBetweenRetries, func() (string, error) {
+		identity, err := GetWorkloadIdentityFromPodE(t, options, podName, provider)
+		if err == nil && identity != expectedIdentity {
+			err = NewWorkloadIdentityMismatchError(podName, serviceAccountName, expectedIdentity, identity)
+		}
+		if err != nil {
+			lastErr = err
+			return "", err
+		}
+		return fmt.Sprintf("Pod %s got %s identity %s", podName, provider.Name, identity), nil
+	})
+	// Return why the last attempt failed rather than just that the retries ran out
+	if _, timedOut := err.(retry.MaxRetriesExceeded); timedOut && lastErr != nil {
+		return lastErr
+	}
+	if err != nil {
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// assertWorkloadIdentityOfControlPodE creates a control pod running as the default service account and checks that it
+// does not get the given identity.
+func assertWorkloadIdentityOfControlPodE(t testing.TestingT, options *KubectlOptions, podName string, provider WorkloadIdentityProvider, identity string) error {
+	if _, err := CreateWorkloadIdentityProbePodE(t, options, podName, provider, ""); err != nil {
+		return err
+	}
+
+	controlIdentity, err := GetWorkloadIdentityFromPodE(t, options, podName, provider)
+	if err != nil {
+		logger.Logf(t, "%s API call from control pod %s failed as expected: %s", provider.Name, podName, err)
+		return nil
+	}
+	if controlIdentity == identity {
+		return NewWorkloadIdentityNotIsolatedError(podName, identity)
+	}
+	logger.Logf(t, "Control pod %s got a different %s identity %s as expected", podName, provider.Name, controlIdentity)
+	return nil
+}
+
+// newWorkloadIdentityProbePod returns the spec of a probe pod with the CLI of the given provider that runs as the given
+// service account.
+func newWorkloadIdentityProbePod(podName string, namespace string, provider WorkloadIdentityProvider, serviceAccountName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    workloadIdentityProbeContainerName,
+				Image:   provider.Image,
+				Command: []string{"sleep", "3600"},
+			}},
+			ServiceAccountName: serviceAccountName,
+		},
+	}
+}
+
+// parseAssumedRoleName returns the name of the role of the given assumed role ARN as printed by aws sts
+// get-caller-identity, e.g. "my-app" for arn:aws:sts::123456789012:assumed-role/my-app/botocore-session-1634567890, or
+// the trimmed output itself if it is not an assumed role ARN, e.g. the ARN of an IAM user.
+func parseAssumedRoleName(output string) string {
+	arn := strings.TrimSpace(output)
+	parts := strings.Split(arn, "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[0], ":assumed-role") {
+		return arn
+	}
+	return parts[1]
+}
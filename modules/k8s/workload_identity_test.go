@@ -0,0 +1,35 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAssumedRoleName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "my-app", parseAssumedRoleName("arn:aws:sts::123456789012:assumed-role/my-app/botocore-session-1634567890\n"))
+	assert.Equal(t, "arn:aws:iam::123456789012:user/ci", parseAssumedRoleName("arn:aws:iam::123456789012:user/ci"))
+	assert.Equal(t, "my-app@my-project.iam.gserviceaccount.com", GcpWorkloadIdentity.ParseIdentity("my-app@my-project.iam.gserviceaccount.com\n"))
+}
+
+func TestNewWorkloadIdentityProbePod(t *testing.T) {
+	t.Parallel()
+
+	pod := newWorkloadIdentityProbePod("probe", "irsa-test", AwsIrsa, "my-app")
+
+	assert.Equal(t, "my-app", pod.Spec.ServiceAccountName)
+	assert.Equal(t, AwsIrsa.Image, pod.Spec.Containers[0].Image)
+	assert.Equal(t, []string{"sleep", "3600"}, pod.Spec.Containers[0].Command)
+}
+
+func TestWorkloadIdentityErrors(t *testing.T) {
+	t.Parallel()
+
+	err := NewWorkloadIdentityMismatchError("probe", "my-app", "my-app", "eks-node-role")
+	assert.EqualError(t, err, "Pod probe with service account my-app has identity eks-node-role, expected my-app")
+	assert.True(t, errors.Is(err, ErrMismatch))
+	assert.True(t, errors.Is(NewWorkloadIdentityNotIsolatedError("control", "my-app"), ErrMismatch))
+}
@@ -7,9 +7,11 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	gotesting "testing"
 	"time"
 
+	"github.com/gruntwork-io/terratest/modules/clock"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
@@ -41,6 +43,15 @@ var (
 	TestingT = New(testingT{})
 )
 
+// runID holds the ID of the current test run, which is included in every line logged by DoLog once set.
+var runID atomic.Value
+
+// SetRunID sets the ID of the current test run, which is then included in every line logged by DoLog, so that log
+// output can be tied back to the run that produced it. See the runinfo package, which sets it automatically.
+func SetRunID(id string) {
+	runID.Store(id)
+}
+
 type TestLogger interface {
 	Logf(t testing.TestingT, format string, args ...interface{})
 }
@@ -141,8 +152,12 @@ func Log(t testing.TestingT, args ...interface{}) {
 // DoLog logs the given arguments to the given writer, along with a timestamp and information about what test and file is
 // doing the logging.
 func DoLog(t testing.TestingT, callDepth int, writer io.Writer, args ...interface{}) {
-	date := time.Now()
-	prefix := fmt.Sprintf("%s %s %s:", t.Name(), date.Format(time.RFC3339), CallerPrefix(callDepth+1))
+	date := clock.Default().Now()
+	run := ""
+	if id, ok := runID.Load().(string); ok && id != "" {
+		run = " run=" + id
+	}
+	prefix := fmt.Sprintf("%s %s%s %s:", t.Name(), date.Format(time.RFC3339), run, CallerPrefix(callDepth+1))
 	allArgs := append([]interface{}{prefix}, args...)
 	fmt.Fprintln(writer, allArgs...)
 }
@@ -52,3 +52,15 @@ func TestCustomLogger(t *testing.T) {
 	assert.Equal(t, "log output 2", c.logs[1])
 	assert.Equal(t, "subtest log", c.logs[2])
 }
+
+func TestDoLogWithRunID(t *testing.T) {
+	SetRunID("abc123")
+	defer SetRunID("")
+
+	text := "test-do-log-with-run-id"
+	var buffer bytes.Buffer
+
+	DoLog(t, 1, &buffer, text)
+
+	assert.Regexp(t, fmt.Sprintf("^%s .+? run=abc123 [[:word:]]+.go:[0-9]+: %s$", t.Name(), text), strings.TrimSpace(buffer.String()))
+}
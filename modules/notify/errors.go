@@ -0,0 +1,13 @@
+package notify
+
+import "fmt"
+
+// WebhookFailed is returned when a webhook responds to a notification with an error status.
+type WebhookFailed struct {
+	StatusCode int
+	Body       string
+}
+
+func (err WebhookFailed) Error() string {
+	return fmt.Sprintf("Webhook responded with status %d: %s", err.StatusCode, err.Body)
+}
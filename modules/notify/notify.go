@@ -0,0 +1,233 @@
+// Package notify posts summaries of the events of long running test suites, such as nightly infrastructure suites, to
+// Slack or Microsoft Teams webhooks and PagerDuty, so that they don't fail silently. A suite notifies when it starts,
+// when it finishes or fails (with its duration, failed stages and artifact links), and when it detects leaked
+// resources:
+//
+//	func TestMain(m *testing.M) {
+//	    suite := notify.NewSuite("nightly-infra", notify.NotifiersFromEnv()...)
+//	    suite.StartE()
+//	    code := m.Run()
+//	    suite.FinishE(notify.Result{Failed: code != 0})
+//	    os.Exit(code)
+//	}
+//
+// Failing to notify never fails the suite: the methods that take a testing.TestingT only log a warning.
+package notify
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/runinfo"
+	"github.com/gruntwork-io/terratest/modules/scenario"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+)
+
+// The environment variables NotifiersFromEnv reads the notifiers to use from.
+const (
+	SlackWebhookURLEnvVar     = "TERRATEST_SLACK_WEBHOOK_URL"
+	TeamsWebhookURLEnvVar     = "TERRATEST_TEAMS_WEBHOOK_URL"
+	PagerDutyRoutingKeyEnvVar = "TERRATEST_PAGERDUTY_ROUTING_KEY"
+)
+
+// EventKind is the kind of a suite event.
+type EventKind string
+
+const (
+	SuiteStarted    EventKind = "suite-started"
+	SuiteFinished   EventKind = "suite-finished" // The suite passed
+	SuiteFailed     EventKind = "suite-failed"
+	ResourcesLeaked EventKind = "resources-leaked"
+)
+
+// Link is a named link to an artifact of a suite, such as a log bundle or a report.
+type Link struct {
+	Name string
+	URL  string
+}
+
+// Event is an event of a test suite, as posted to the notifiers.
+type Event struct {
+	Kind            EventKind
+	Suite           string
+	Duration        time.Duration // How long the suite ran, for SuiteFinished and SuiteFailed
+	FailedStages    []string      // The stages (e.g. scenario steps or tests) that failed, for SuiteFailed
+	LeakedResources []string      // The resources that were left behind, for ResourcesLeaked
+	Artifacts       []Link
+	Message         string           // An optional free form message
+	Run             *runinfo.RunInfo // The metadata of the test run, e.g. to link to the CI job
+}
+
+// Title returns a one line summary of the event, e.g. "Suite nightly-infra failed after 1h2m3s".
+func (event Event) Title() string {
+	switch event.Kind {
+	case SuiteStarted:
+		return fmt.Sprintf("Suite %s started", event.Suite)
+	case SuiteFinished:
+		return fmt.Sprintf("Suite %s passed after %s", event.Suite, event.Duration.Round(time.Second))
+	case SuiteFailed:
+		return fmt.Sprintf("Suite %s failed after %s", event.Suite, event.Duration.Round(time.Second))
+	case ResourcesLeaked:
+		return fmt.Sprintf("Suite %s leaked %d resources", event.Suite, len(event.LeakedResources))
+	default:
+		return fmt.Sprintf("Suite %s: %s", event.Suite, event.Kind)
+	}
+}
+
+// Details returns the lines detailing the event: its message, failed stages, leaked resources, artifacts and CI job.
+func (event Event) Details() []string {
+	details := []string{}
+	if event.Message != "" {
+		details = append(details, event.Message)
+	}
+	if len(event.FailedStages) > 0 {
+		details = append(details, fmt.Sprintf("Failed stages: %s", strings.Join(event.FailedStages, ", ")))
+	}
+	if len(event.LeakedResources) > 0 {
+		details = append(details, fmt.Sprintf("Leaked resources: %s", strings.Join(event.LeakedResources, ", ")))
+	}
+	for _, artifact := range event.Artifacts {
+		details = append(details, fmt.Sprintf("%s: %s", artifact.Name, artifact.URL))
+	}
+	if event.Run != nil {
+		details = append(details, fmt.Sprintf("Run: %s", event.Run.RunID))
+		if event.Run.CIJobURL != "" {
+			details = append(details, fmt.Sprintf("CI job: %s", event.Run.CIJobURL))
+		}
+	}
+	return details
+}
+
+// IsFailure returns true if the event is about something going wrong, which is what PagerDuty is notified of.
+func (event Event) IsFailure() bool {
+	return event.Kind == SuiteFailed || event.Kind == ResourcesLeaked
+}
+
+// Notifier posts suite events somewhere.
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// NotifiersFromEnv returns the notifiers configured with the TERRATEST_SLACK_WEBHOOK_URL, TERRATEST_TEAMS_WEBHOOK_URL
+// and TERRATEST_PAGERDUTY_ROUTING_KEY environment variables, so that CI can configure where to notify without code
+// changes. Returns no notifiers if none of them are set.
+func NotifiersFromEnv() []Notifier {
+	notifiers := []Notifier{}
+	if url := os.Getenv(SlackWebhookURLEnvVar); url != "" {
+		notifiers = append(notifiers, &SlackNotifier{WebhookURL: url})
+	}
+	if url := os.Getenv(TeamsWebhookURLEnvVar); url != "" {
+		notifiers = append(notifiers, &TeamsNotifier{WebhookURL: url})
+	}
+	if routingKey := os.Getenv(PagerDutyRoutingKeyEnvVar); routingKey != "" {
+		notifiers = append(notifiers, &PagerDutyNotifier{RoutingKey: routingKey})
+	}
+	return notifiers
+}
+
+// Result is the outcome of a suite, as passed to Suite.Finish.
+type Result struct {
+	Failed       bool     // Whether the suite failed. Set automatically if there are FailedStages.
+	FailedStages []string // The stages that failed
+	Artifacts    []Link
+	Message      string
+}
+
+// ResultFromScenarioReport returns the result of a suite made of the given scenario, whose failed steps are the failed
+// stages.
+func ResultFromScenarioReport(report *scenario.Report) Result {
+	result := Result{}
+	for _, step := range report.Steps {
+		if step.Status == scenario.Failed {
+			result.FailedStages = append(result.FailedStages, step.Name)
+		}
+	}
+	result.Failed = len(result.FailedStages) > 0
+	return result
+}
+
+// Suite notifies its notifiers of the events of a test suite.
+type Suite struct {
+	Name      string
+	Notifiers []Notifier
+	startedAt time.Time
+}
+
+// NewSuite returns a suite with the given name that notifies the given notifiers.
+func NewSuite(name string, notifiers ...Notifier) *Suite {
+	return &Suite{Name: name, Notifiers: notifiers, startedAt: time.Now()}
+}
+
+// Start notifies that the suite started, logging a warning if that fails.
+func (suite *Suite) Start(t testing.TestingT) {
+	warnIfFailed(t, suite.StartE())
+}
+
+// StartE notifies that the suite started and resets the start time its duration is measured from.
+func (suite *Suite) StartE() error {
+	suite.startedAt = time.Now()
+	return suite.NotifyE(Event{Kind: SuiteStarted})
+}
+
+// Finish notifies that the suite passed or failed, with the given result, logging a warning if that fails.
+func (suite *Suite) Finish(t testing.TestingT, result Result) {
+	warnIfFailed(t, suite.FinishE(result))
+}
+
+// FinishE notifies that the suite passed or failed, with the given result and how long the suite ran.
+func (suite *Suite) FinishE(result Result) error {
+	kind := SuiteFinished
+	if result.Failed || len(result.FailedStages) > 0 {
+		kind = SuiteFailed
+	}
+
+	return suite.NotifyE(Event{
+		Kind:         kind,
+		Duration:     time.Since(suite.startedAt),
+		FailedStages: result.FailedStages,
+		Artifacts:    result.Artifacts,
+		Message:      result.Message,
+	})
+}
+
+// ReportLeakedResources notifies that the given resources were left behind by the suite, e.g. as found by a sweep of
+// the resources tagged with the run ID, logging a warning if that fails. This is a no-op if there are no resources.
+func (suite *Suite) ReportLeakedResources(t testing.TestingT, resources []string) {
+	warnIfFailed(t, suite.ReportLeakedResourcesE(resources))
+}
+
+// ReportLeakedResourcesE notifies that the given resources were left behind by the suite. This is a no-op if there are
+// no resources.
+func (suite *Suite) ReportLeakedResourcesE(resources []string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	return suite.NotifyE(Event{Kind: ResourcesLeaked, LeakedResources: resources})
+}
+
+// NotifyE posts the given event, filled in with the name of the suite and the metadata of the test run, to all the
+// notifiers of the suite. All the notifiers are tried, even if some fail.
+func (suite *Suite) NotifyE(event Event) error {
+	event.Suite = suite.Name
+	if event.Run == nil {
+		event.Run = runinfo.Get()
+	}
+
+	var errorsOccurred *multierror.Error
+	for _, notifier := range suite.Notifiers {
+		if err := notifier.Notify(event); err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, err)
+		}
+	}
+	return errorsOccurred.ErrorOrNil()
+}
+
+func warnIfFailed(t testing.TestingT, err error) {
+	if err != nil {
+		logger.Logf(t, "WARNING: Failed to send suite notification: %s", err)
+	}
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/runinfo"
+	"github.com/gruntwork-io/terratest/modules/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer is a webhook that records the JSON bodies posted to it.
+type recordingServer struct {
+	*httptest.Server
+	mutex  sync.Mutex
+	bodies []map[string]interface{}
+}
+
+func newRecordingServer(t *testing.T, status int) *recordingServer {
+	server := &recordingServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &decoded))
+
+		server.mutex.Lock()
+		server.bodies = append(server.bodies, decoded)
+		server.mutex.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	return server
+}
+
+var testRun = &runinfo.RunInfo{RunID: "abc123", CIJobURL: "https://ci.example.com/jobs/1"}
+
+func TestEventSummary(t *testing.T) {
+	t.Parallel()
+
+	event := Event{
+		Kind:         SuiteFailed,
+		Suite:        "nightly",
+		Duration:     90 * time.Minute,
+		FailedStages: []string{"deploy", "verify"},
+		Artifacts:    []Link{{Name: "Logs", URL: "https://example.com/logs.tar.gz"}},
+		Run:          testRun,
+	}
+
+	assert.Equal(t, "Suite nightly failed after 1h30m0s", event.Title())
+	assert.Equal(t, []string{
+		"Failed stages: deploy, verify",
+		"Logs: https://example.com/logs.tar.gz",
+		"Run: abc123",
+		"CI job: https://ci.example.com/jobs/1",
+	}, event.Details())
+	assert.True(t, event.IsFailure())
+}
+
+func TestSlackAndTeamsNotifiers(t *testing.T) {
+	t.Parallel()
+
+	slack := newRecordingServer(t, http.StatusOK)
+	defer slack.Close()
+	teams := newRecordingServer(t, http.StatusOK)
+	defer teams.Close()
+
+	suite := NewSuite("nightly", &SlackNotifier{WebhookURL: slack.URL}, &TeamsNotifier{WebhookURL: teams.URL})
+	require.NoError(t, suite.NotifyE(Event{Kind: ResourcesLeaked, LeakedResources: []string{"i-123"}, Run: testRun}))
+
+	require.Len(t, slack.bodies, 1)
+	assert.Equal(t, "*Suite nightly leaked 1 resources*\nLeaked resources: i-123\nRun: abc123\nCI job: https://ci.example.com/jobs/1", slack.bodies[0]["text"])
+
+	require.Len(t, teams.bodies, 1)
+	assert.Equal(t, "MessageCard", teams.bodies[0]["@type"])
+	assert.Equal(t, "Suite nightly leaked 1 resources", teams.bodies[0]["title"])
+	assert.Equal(t, "E01E5A", teams.bodies[0]["themeColor"])
+}
+
+func TestPagerDutyNotifierTriggersOnFailureAndResolvesOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	pagerDuty := newRecordingServer(t, http.StatusAccepted)
+	defer pagerDuty.Close()
+
+	suite := NewSuite("nightly", &PagerDutyNotifier{RoutingKey: "key", EventsURL: pagerDuty.URL})
+	require.NoError(t, suite.NotifyE(Event{Kind: SuiteStarted, Run: testRun}))
+	require.NoError(t, suite.NotifyE(Event{Kind: SuiteFailed, FailedStages: []string{"deploy"}, Run: testRun}))
+	require.NoError(t, suite.NotifyE(Event{Kind: SuiteFinished, Run: testRun}))
+
+	// The start of the suite is not sent to PagerDuty
+	require.Len(t, pagerDuty.bodies, 2)
+	assert.Equal(t, "trigger", pagerDuty.bodies[0]["event_action"])
+	assert.Equal(t, "error", pagerDuty.bodies[0]["payload"].(map[string]interface{})["severity"])
+	assert.Equal(t, "resolve", pagerDuty.bodies[1]["event_action"])
+	assert.Equal(t, pagerDuty.bodies[0]["dedup_key"], pagerDuty.bodies[1]["dedup_key"])
+}
+
+func TestNotifyTriesAllNotifiersAndReturnsFailures(t *testing.T) {
+	t.Parallel()
+
+	failing := newRecordingServer(t, http.StatusForbidden)
+	defer failing.Close()
+	working := newRecordingServer(t, http.StatusOK)
+	defer working.Close()
+
+	suite := NewSuite("nightly", &SlackNotifier{WebhookURL: failing.URL}, &SlackNotifier{WebhookURL: working.URL})
+	err := suite.FinishE(Result{Failed: true})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 403")
+	assert.Len(t, working.bodies, 1)
+	assert.True(t, strings.HasPrefix(working.bodies[0]["text"].(string), "*Suite nightly failed after"))
+}
+
+func TestResultFromScenarioReport(t *testing.T) {
+	t.Parallel()
+
+	report := &scenario.Report{Steps: []scenario.StepResult{
+		{Name: "deploy", Status: scenario.Passed},
+		{Name: "verify", Status: scenario.Failed},
+		{Name: "destroy", Status: scenario.NotRun},
+	}}
+
+	assert.Equal(t, Result{Failed: true, FailedStages: []string{"verify"}}, ResultFromScenarioReport(report))
+}
+
+// This test sets environment variables, so it must not run in parallel.
+func TestNotifiersFromEnv(t *testing.T) {
+	os.Setenv(SlackWebhookURLEnvVar, "https://hooks.slack.com/services/x")
+	defer os.Unsetenv(SlackWebhookURLEnvVar)
+	os.Setenv(PagerDutyRoutingKeyEnvVar, "key")
+	defer os.Unsetenv(PagerDutyRoutingKeyEnvVar)
+
+	notifiers := NotifiersFromEnv()
+	require.Len(t, notifiers, 2)
+	assert.Equal(t, &SlackNotifier{WebhookURL: "https://hooks.slack.com/services/x"}, notifiers[0])
+	assert.Equal(t, &PagerDutyNotifier{RoutingKey: "key"}, notifiers[1])
+}
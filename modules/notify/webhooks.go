@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PagerDutyEventsURL is the URL of the PagerDuty Events API v2.
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// webhookTimeout is how long to wait for a webhook to respond, so that an unreachable webhook doesn't hang the suite.
+const webhookTimeout = 30 * time.Second
+
+// SlackNotifier posts suite events to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Defaults to a client with a 30 second timeout
+}
+
+// Notify posts the given event to the Slack webhook.
+func (notifier *SlackNotifier) Notify(event Event) error {
+	text := "*" + event.Title() + "*"
+	if details := event.Details(); len(details) > 0 {
+		text += "\n" + strings.Join(details, "\n")
+	}
+	return postJSON(notifier.Client, notifier.WebhookURL, map[string]string{"text": text})
+}
+
+// TeamsNotifier posts suite events to a Microsoft Teams incoming webhook, as message cards.
+type TeamsNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Defaults to a client with a 30 second timeout
+}
+
+// Notify posts the given event to the Teams webhook.
+func (notifier *TeamsNotifier) Notify(event Event) error {
+	color := "2EB67D"
+	if event.IsFailure() {
+		color = "E01E5A"
+	}
+
+	card := map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    event.Title(),
+		"title":      event.Title(),
+		"themeColor": color,
+		// Teams renders message card text as markdown, where lines need two trailing spaces to break
+		"text": strings.Join(event.Details(), "  \n"),
+	}
+	return postJSON(notifier.Client, notifier.WebhookURL, card)
+}
+
+// PagerDutyNotifier triggers a PagerDuty incident when a suite fails or leaks resources, and resolves it when the suite
+// passes again. Other events are not sent to PagerDuty.
+type PagerDutyNotifier struct {
+	RoutingKey string       // The integration key of the PagerDuty service
+	Severity   string       // The severity of the incidents: critical, error, warning or info. Defaults to error.
+	EventsURL  string       // Defaults to PagerDutyEventsURL
+	Client     *http.Client // Defaults to a client with a 30 second timeout
+}
+
+// pagerDutyEvent is an event of the PagerDuty Events API v2.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// Notify triggers a PagerDuty incident for the given event if it is a failure, and resolves the incident of the failure
+// of the suite if it passed. Incidents for leaked resources must be resolved in PagerDuty.
+func (notifier *PagerDutyNotifier) Notify(event Event) error {
+	// Deduplicating by suite and kind means a new failure of the same suite updates its open incident
+	dedupKey := "terratest-" + event.Suite + "-" + string(event.Kind)
+
+	var pdEvent pagerDutyEvent
+	switch {
+	case event.IsFailure():
+		severity := notifier.Severity
+		if severity == "" {
+			severity = "error"
+		}
+		pdEvent = pagerDutyEvent{
+			RoutingKey:  notifier.RoutingKey,
+			EventAction: "trigger",
+			DedupKey:    dedupKey,
+			Payload: &pagerDutyPayload{
+				Summary:       event.Title(),
+				Source:        "terratest/" + event.Suite,
+				Severity:      severity,
+				CustomDetails: map[string]interface{}{"details": event.Details()},
+			},
+		}
+		for _, artifact := range event.Artifacts {
+			pdEvent.Links = append(pdEvent.Links, pagerDutyLink{Href: artifact.URL, Text: artifact.Name})
+		}
+	case event.Kind == SuiteFinished:
+		pdEvent = pagerDutyEvent{
+			RoutingKey:  notifier.RoutingKey,
+			EventAction: "resolve",
+			DedupKey:    "terratest-" + event.Suite + "-" + string(SuiteFailed),
+		}
+	default:
+		return nil
+	}
+
+	eventsURL := notifier.EventsURL
+	if eventsURL == "" {
+		eventsURL = PagerDutyEventsURL
+	}
+	return postJSON(notifier.Client, eventsURL, pdEvent)
+}
+
+// postJSON posts the given value as JSON to the given URL with the given client, or a client with a 30 second timeout
+// if nil, and returns a WebhookFailed error if the response is not a success.
+func postJSON(client *http.Client, webhookURL string, value interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The error includes the URL, which is a secret for webhooks, so only return the underlying error
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return WebhookFailed{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	return nil
+}
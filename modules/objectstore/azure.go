@@ -0,0 +1,136 @@
+package objectstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/gruntwork-io/terratest/modules/azure"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// AzureBlobStore is an ObjectStore backed by an Azure Blob Storage container.
+type AzureBlobStore struct {
+	StorageAccountName string
+	ContainerName      string
+	container          *storage.Container
+}
+
+// NewAzureBlobStore creates an ObjectStore for the given container of the given storage account. It authenticates with
+// the first access key of the storage account, which is looked up with the current Azure credentials. This will fail
+// the test if there is an error.
+func NewAzureBlobStore(t testing.TestingT, containerName string, storageAccountName string, resourceGroupName string, subscriptionID string) *AzureBlobStore {
+	store, err := NewAzureBlobStoreE(t, containerName, storageAccountName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return store
+}
+
+// NewAzureBlobStoreE creates an ObjectStore for the given container of the given storage account. It authenticates with
+// the first access key of the storage account, which is looked up with the current Azure credentials.
+func NewAzureBlobStoreE(t testing.TestingT, containerName string, storageAccountName string, resourceGroupName string, subscriptionID string) (*AzureBlobStore, error) {
+	key, err := azure.GetStorageAccountKeyE(storageAccountName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, err := azure.GetStorageURISuffixE()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := storage.NewClient(storageAccountName, key, suffix, storage.DefaultAPIVersion, true)
+	if err != nil {
+		return nil, err
+	}
+
+	blobService := client.GetBlobService()
+	container := blobService.GetContainerReference(containerName)
+	return &AzureBlobStore{StorageAccountName: storageAccountName, ContainerName: containerName, container: container}, nil
+}
+
+// Put writes the given data to the blob with the given key.
+func (store *AzureBlobStore) Put(t testing.TestingT, key string, data []byte) error {
+	logger.Logf(t, "Writing %d bytes to %s", len(data), store.URL(key))
+	return store.container.GetBlobReference(key).CreateBlockBlobFromReader(bytes.NewReader(data), nil)
+}
+
+// Get reads the data of the blob with the given key.
+func (store *AzureBlobStore) Get(t testing.TestingT, key string) ([]byte, error) {
+	logger.Logf(t, "Reading %s", store.URL(key))
+
+	reader, err := store.container.GetBlobReference(key).Get(nil)
+	if err != nil {
+		if isAzureStorageNotFound(err) {
+			return nil, ObjectNotFound{URL: store.URL(key)}
+		}
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// List returns the keys of all the blobs whose key starts with the given prefix, in lexical order.
+func (store *AzureBlobStore) List(t testing.TestingT, prefix string) ([]string, error) {
+	keys := []string{}
+	params := storage.ListBlobsParameters{Prefix: prefix}
+	for {
+		response, err := store.container.ListBlobs(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, blob := range response.Blobs {
+			keys = append(keys, blob.Name)
+		}
+
+		if response.NextMarker == "" {
+			break
+		}
+		params.Marker = response.NextMarker
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete deletes the blob with the given key.
+func (store *AzureBlobStore) Delete(t testing.TestingT, key string) error {
+	if dryrun.Skip(t, "delete %s", store.URL(key)) {
+		return nil
+	}
+
+	logger.Logf(t, "Deleting %s", store.URL(key))
+
+	_, err := store.container.GetBlobReference(key).DeleteIfExists(nil)
+	return err
+}
+
+// Presign returns a SAS URL that allows anyone who has it to download the blob with the given key until the given
+// expiry.
+func (store *AzureBlobStore) Presign(t testing.TestingT, key string, expiry time.Duration) (string, error) {
+	return store.container.GetBlobReference(key).GetSASURI(storage.BlobSASOptions{
+		BlobServiceSASPermissions: storage.BlobServiceSASPermissions{Read: true},
+		SASOptions: storage.SASOptions{
+			Expiry:   time.Now().Add(expiry),
+			UseHTTPS: true,
+		},
+	})
+}
+
+// URL returns the HTTPS URL of the blob with the given key.
+func (store *AzureBlobStore) URL(key string) string {
+	return store.container.GetBlobReference(key).GetURL()
+}
+
+// isAzureStorageNotFound indicates whether the given error is a 404 response from Azure Storage.
+func isAzureStorageNotFound(err error) bool {
+	serviceErr, ok := err.(storage.AzureStorageServiceError)
+	return ok && serviceErr.StatusCode == http.StatusNotFound
+}
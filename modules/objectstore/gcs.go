@@ -0,0 +1,115 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/iterator"
+)
+
+// GcsStore is an ObjectStore backed by a Google Cloud Storage bucket.
+type GcsStore struct {
+	Bucket string
+	client *storage.Client
+}
+
+// NewGcsStore creates an ObjectStore for the given Google Cloud Storage bucket. This will fail the test if there is an
+// error.
+func NewGcsStore(t testing.TestingT, bucket string) *GcsStore {
+	store, err := NewGcsStoreE(t, bucket)
+	require.NoError(t, err)
+	return store
+}
+
+// NewGcsStoreE creates an ObjectStore for the given Google Cloud Storage bucket.
+func NewGcsStoreE(t testing.TestingT, bucket string) (*GcsStore, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &GcsStore{Bucket: bucket, client: client}, nil
+}
+
+// Put writes the given data to the object with the given key.
+func (store *GcsStore) Put(t testing.TestingT, key string, data []byte) error {
+	logger.Logf(t, "Writing %d bytes to %s", len(data), store.URL(key))
+
+	writer := store.client.Bucket(store.Bucket).Object(key).NewWriter(context.Background())
+	if _, err := io.Copy(writer, bytes.NewReader(data)); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// Get reads the data of the object with the given key.
+func (store *GcsStore) Get(t testing.TestingT, key string) ([]byte, error) {
+	logger.Logf(t, "Reading %s", store.URL(key))
+
+	reader, err := store.client.Bucket(store.Bucket).Object(key).NewReader(context.Background())
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return nil, ObjectNotFound{URL: store.URL(key)}
+		}
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// List returns the keys of all the objects whose key starts with the given prefix, in lexical order.
+func (store *GcsStore) List(t testing.TestingT, prefix string) ([]string, error) {
+	keys := []string{}
+	objects := store.client.Bucket(store.Bucket).Objects(context.Background(), &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := objects.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attrs.Name)
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete deletes the object with the given key.
+func (store *GcsStore) Delete(t testing.TestingT, key string) error {
+	if dryrun.Skip(t, "delete %s", store.URL(key)) {
+		return nil
+	}
+
+	logger.Logf(t, "Deleting %s", store.URL(key))
+
+	err := store.client.Bucket(store.Bucket).Object(key).Delete(context.Background())
+	if err == storage.ErrObjectNotExist {
+		return nil
+	}
+	return err
+}
+
+// Presign returns a URL that allows anyone who has it to download the object with the given key until the given expiry.
+// Signing requires the default credentials to be a service account key.
+func (store *GcsStore) Presign(t testing.TestingT, key string, expiry time.Duration) (string, error) {
+	return gcp.GenerateSignedUrlE(t, store.Bucket, key, "GET", expiry)
+}
+
+// URL returns the gs:// URL of the object with the given key.
+func (store *GcsStore) URL(key string) string {
+	return fmt.Sprintf("gs://%s/%s", store.Bucket, key)
+}
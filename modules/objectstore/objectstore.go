@@ -0,0 +1,107 @@
+// Package objectstore provides a provider-agnostic interface to object storage (AWS S3, Google Cloud Storage and Azure
+// Blob Storage), so that test utilities which store or fetch objects work the same regardless of the cloud.
+package objectstore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ObjectStore is a bucket (or container) of objects in a cloud object storage service. Keys are the full paths of
+// objects within the bucket, e.g. "fixtures/users.json".
+type ObjectStore interface {
+	// Put writes the given data to the object with the given key, replacing it if it already exists.
+	Put(t testing.TestingT, key string, data []byte) error
+	// Get reads the data of the object with the given key. If there is no such object, it returns an ObjectNotFound
+	// error.
+	Get(t testing.TestingT, key string) ([]byte, error)
+	// List returns the keys of all the objects whose key starts with the given prefix, in lexical order.
+	List(t testing.TestingT, prefix string) ([]string, error)
+	// Delete deletes the object with the given key. Deleting an object that does not exist is not an error.
+	Delete(t testing.TestingT, key string) error
+	// Presign returns a URL that allows anyone who has it to download the object with the given key until the given
+	// expiry.
+	Presign(t testing.TestingT, key string, expiry time.Duration) (string, error)
+	// URL returns the provider specific URL of the object with the given key, e.g. "s3://bucket/key".
+	URL(key string) string
+}
+
+// ObjectNotFound is returned by Get when there is no object with the given key.
+type ObjectNotFound struct {
+	URL string
+}
+
+func (err ObjectNotFound) Error() string {
+	return fmt.Sprintf("Object %s not found", err.URL)
+}
+
+// IsObjectNotFound indicates whether the given error is an ObjectNotFound error.
+func IsObjectNotFound(err error) bool {
+	_, ok := err.(ObjectNotFound)
+	return ok
+}
+
+// Put writes the given data to the object with the given key in the given store. This will fail the test if there is
+// an error.
+func Put(t testing.TestingT, store ObjectStore, key string, data []byte) {
+	require.NoError(t, store.Put(t, key, data))
+}
+
+// Get reads the data of the object with the given key in the given store. This will fail the test if there is an
+// error.
+func Get(t testing.TestingT, store ObjectStore, key string) []byte {
+	data, err := store.Get(t, key)
+	require.NoError(t, err)
+	return data
+}
+
+// List returns the keys of all the objects in the given store whose key starts with the given prefix. This will fail
+// the test if there is an error.
+func List(t testing.TestingT, store ObjectStore, prefix string) []string {
+	keys, err := store.List(t, prefix)
+	require.NoError(t, err)
+	return keys
+}
+
+// Delete deletes the object with the given key in the given store. This will fail the test if there is an error.
+func Delete(t testing.TestingT, store ObjectStore, key string) {
+	require.NoError(t, store.Delete(t, key))
+}
+
+// Presign returns a URL that allows anyone who has it to download the object with the given key in the given store
+// until the given expiry. This will fail the test if there is an error.
+func Presign(t testing.TestingT, store ObjectStore, key string, expiry time.Duration) string {
+	url, err := store.Presign(t, key, expiry)
+	require.NoError(t, err)
+	return url
+}
+
+// DeletePrefix deletes all the objects in the given store whose key starts with the given prefix. This will fail the
+// test if there is an error.
+func DeletePrefix(t testing.TestingT, store ObjectStore, prefix string) {
+	require.NoError(t, DeletePrefixE(t, store, prefix))
+}
+
+// DeletePrefixE deletes all the objects in the given store whose key starts with the given prefix.
+func DeletePrefixE(t testing.TestingT, store ObjectStore, prefix string) error {
+	if dryrun.Skip(t, "delete all the objects with prefix %s", prefix) {
+		return nil
+	}
+
+	keys, err := store.List(t, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := store.Delete(t, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package objectstore
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	terratesting "github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ ObjectStore = &S3Store{}
+	_ ObjectStore = &GcsStore{}
+	_ ObjectStore = &AzureBlobStore{}
+)
+
+// memoryStore is an in memory ObjectStore used to test the provider-agnostic helpers.
+type memoryStore map[string][]byte
+
+func (store memoryStore) Put(t terratesting.TestingT, key string, data []byte) error {
+	store[key] = data
+	return nil
+}
+
+func (store memoryStore) Get(t terratesting.TestingT, key string) ([]byte, error) {
+	data, ok := store[key]
+	if !ok {
+		return nil, ObjectNotFound{URL: store.URL(key)}
+	}
+	return data, nil
+}
+
+func (store memoryStore) List(t terratesting.TestingT, prefix string) ([]string, error) {
+	keys := []string{}
+	for key := range store {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (store memoryStore) Delete(t terratesting.TestingT, key string) error {
+	delete(store, key)
+	return nil
+}
+
+func (store memoryStore) Presign(t terratesting.TestingT, key string, expiry time.Duration) (string, error) {
+	return store.URL(key) + "?signed", nil
+}
+
+func (store memoryStore) URL(key string) string {
+	return "memory://" + key
+}
+
+func TestObjectStoreHelpers(t *testing.T) {
+	t.Parallel()
+
+	store := memoryStore{}
+	Put(t, store, "fixtures/a.json", []byte("a"))
+	Put(t, store, "fixtures/b.json", []byte("b"))
+	Put(t, store, "golden/c.txt", []byte("c"))
+
+	assert.Equal(t, []byte("a"), Get(t, store, "fixtures/a.json"))
+	assert.Equal(t, []string{"fixtures/a.json", "fixtures/b.json"}, List(t, store, "fixtures/"))
+	assert.Equal(t, "memory://golden/c.txt?signed", Presign(t, store, "golden/c.txt", time.Minute))
+
+	DeletePrefix(t, store, "fixtures/")
+	assert.Equal(t, []string{"golden/c.txt"}, List(t, store, ""))
+
+	_, err := store.Get(t, "fixtures/a.json")
+	assert.True(t, IsObjectNotFound(err))
+}
+
+func TestObjectURLs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "s3://my-bucket/path/to/key", (&S3Store{Bucket: "my-bucket"}).URL("path/to/key"))
+	assert.Equal(t, "gs://my-bucket/path/to/key", (&GcsStore{Bucket: "my-bucket"}).URL("path/to/key"))
+}
@@ -0,0 +1,116 @@
+package objectstore
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	terratestAws "github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/dryrun"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// S3Store is an ObjectStore backed by an AWS S3 bucket.
+type S3Store struct {
+	Region string
+	Bucket string
+	client *s3.S3
+}
+
+// NewS3Store creates an ObjectStore for the given S3 bucket. This will fail the test if there is an error.
+func NewS3Store(t testing.TestingT, region string, bucket string) *S3Store {
+	store, err := NewS3StoreE(t, region, bucket)
+	require.NoError(t, err)
+	return store
+}
+
+// NewS3StoreE creates an ObjectStore for the given S3 bucket.
+func NewS3StoreE(t testing.TestingT, region string, bucket string) (*S3Store, error) {
+	client, err := terratestAws.NewS3ClientE(t, region)
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{Region: region, Bucket: bucket, client: client}, nil
+}
+
+// Put writes the given data to the object with the given key.
+func (store *S3Store) Put(t testing.TestingT, key string, data []byte) error {
+	logger.Logf(t, "Writing %d bytes to %s", len(data), store.URL(key))
+	_, err := store.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(store.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+// Get reads the data of the object with the given key.
+func (store *S3Store) Get(t testing.TestingT, key string) ([]byte, error) {
+	logger.Logf(t, "Reading %s", store.URL(key))
+	output, err := store.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(store.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ObjectNotFound{URL: store.URL(key)}
+		}
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return ioutil.ReadAll(output.Body)
+}
+
+// List returns the keys of all the objects whose key starts with the given prefix, in lexical order.
+func (store *S3Store) List(t testing.TestingT, prefix string) ([]string, error) {
+	keys := []string{}
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(store.Bucket), Prefix: aws.String(prefix)}
+	err := store.client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete deletes the object with the given key.
+func (store *S3Store) Delete(t testing.TestingT, key string) error {
+	if dryrun.Skip(t, "delete %s", store.URL(key)) {
+		return nil
+	}
+
+	logger.Logf(t, "Deleting %s", store.URL(key))
+	_, err := store.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(store.Bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// Presign returns a URL that allows anyone who has it to download the object with the given key until the given expiry.
+func (store *S3Store) Presign(t testing.TestingT, key string, expiry time.Duration) (string, error) {
+	request, _ := store.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(store.Bucket),
+		Key:    aws.String(key),
+	})
+	return request.Presign(expiry)
+}
+
+// URL returns the s3:// URL of the object with the given key.
+func (store *S3Store) URL(key string) string {
+	return fmt.Sprintf("s3://%s/%s", store.Bucket, key)
+}
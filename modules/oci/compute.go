@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/oracle/oci-go-sdk/common"
@@ -21,6 +22,10 @@ func DeleteImage(t testing.TestingT, ocid string) {
 
 // DeleteImageE deletes a custom image with given OCID.
 func DeleteImageE(t testing.TestingT, ocid string) error {
+	if dryrun.Skip(t, "delete image with OCID %s", ocid) {
+		return nil
+	}
+
 	logger.Logf(t, "Deleting image with OCID %s", ocid)
 
 	configProvider := common.DefaultConfigProvider()
@@ -0,0 +1,298 @@
+package packer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// The builder IDs Packer reports for the artifacts of common builders.
+const (
+	AmazonEbsBuilderID = "mitchellh.amazonebs"
+	DockerBuilderID    = "packer.docker"
+)
+
+// Packer escapes commas within fields of its machine-readable output with this placeholder.
+const machineReadableCommaPlaceholder = "%!(PACKER_COMMA)"
+
+// Artifact is an artifact reported by a builder or post-processor in the Packer machine-readable output.
+type Artifact struct {
+	BuildName string   // The name of the build that produced the artifact, e.g. "amazon-ebs.ubuntu"
+	Index     int      // The index of the artifact within the build
+	BuilderID string   // The ID of the builder or post-processor, e.g. "mitchellh.amazonebs"
+	ID        string   // The artifact ID, e.g. "us-east-1:ami-123,us-west-2:ami-456"
+	String    string   // The human readable description of the artifact
+	Files     []string // The files produced by the artifact, if any
+}
+
+// AmiIDs returns a map of region to AMI ID for an artifact produced by the amazon builders, which use IDs of the form
+// "us-east-1:ami-123,us-west-2:ami-456" when copying the AMI to several regions.
+func (artifact Artifact) AmiIDs() map[string]string {
+	amis := map[string]string{}
+	for _, regionAndAmi := range strings.Split(artifact.ID, ",") {
+		parts := strings.SplitN(regionAndAmi, ":", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[1], "ami-") {
+			amis[parts[0]] = parts[1]
+		}
+	}
+	return amis
+}
+
+// Manifest is the content of the file written by the Packer manifest post-processor.
+type Manifest struct {
+	Builds      []ManifestBuild `json:"builds"`
+	LastRunUUID string          `json:"last_run_uuid"`
+}
+
+// ManifestBuild is a single build recorded in a Packer manifest.
+type ManifestBuild struct {
+	Name          string            `json:"name"`
+	BuilderType   string            `json:"builder_type"`
+	BuildTime     int64             `json:"build_time"`
+	Files         []ManifestFile    `json:"files"`
+	ArtifactID    string            `json:"artifact_id"`
+	PackerRunUUID string            `json:"packer_run_uuid"`
+	CustomData    map[string]string `json:"custom_data"`
+}
+
+// ManifestFile is a single file of a build recorded in a Packer manifest.
+type ManifestFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// LastRunBuilds returns the builds of the most recent Packer run, since the manifest post-processor appends to the
+// manifest file on each run.
+func (manifest Manifest) LastRunBuilds() []ManifestBuild {
+	builds := []ManifestBuild{}
+	for _, build := range manifest.Builds {
+		if build.PackerRunUUID == manifest.LastRunUUID {
+			builds = append(builds, build)
+		}
+	}
+	return builds
+}
+
+// BuildAndGetArtifacts builds the given Packer template and returns all the artifacts reported in the machine-readable
+// output, including those of post-processors. This will fail the test if there is an error.
+func BuildAndGetArtifacts(t testing.TestingT, options *Options) []Artifact {
+	artifacts, err := BuildAndGetArtifactsE(t, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return artifacts
+}
+
+// BuildAndGetArtifactsE builds the given Packer template and returns all the artifacts reported in the
+// machine-readable output, including those of post-processors.
+func BuildAndGetArtifactsE(t testing.TestingT, options *Options) ([]Artifact, error) {
+	output, err := buildE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return ParseArtifacts(output)
+}
+
+// ParseArtifacts parses the artifacts from the Packer machine-readable log output, which contains entries of the
+// format:
+//
+// <timestamp>,<build name>,artifact,<index>,<key>,<value>...
+//
+// For example:
+//
+// 1456332887,amazon-ebs,artifact,0,builder-id,mitchellh.amazonebs
+// 1456332887,amazon-ebs,artifact,0,id,us-east-1:ami-b481b3de%!(PACKER_COMMA)us-west-2:ami-8ef3b2e6
+// 1456332887,amazon-ebs,artifact,0,end
+//
+func ParseArtifacts(packerLogOutput string) ([]Artifact, error) {
+	artifacts := []Artifact{}
+	artifactIndexes := map[string]int{}
+
+	for _, line := range strings.Split(packerLogOutput, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 5 || fields[2] != "artifact" {
+			continue
+		}
+		for i := range fields {
+			fields[i] = unescapeMachineReadable(fields[i])
+		}
+
+		buildName, key, values := fields[1], fields[4], fields[5:]
+		index, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid artifact index in Packer output line %q: %s", line, err)
+		}
+
+		artifactKey := fmt.Sprintf("%s/%d", buildName, index)
+		position, exists := artifactIndexes[artifactKey]
+		if !exists {
+			artifacts = append(artifacts, Artifact{BuildName: buildName, Index: index, Files: []string{}})
+			position = len(artifacts) - 1
+			artifactIndexes[artifactKey] = position
+		}
+		artifact := &artifacts[position]
+
+		switch {
+		case key == "builder-id" && len(values) > 0:
+			artifact.BuilderID = values[0]
+		case key == "id" && len(values) > 0:
+			artifact.ID = values[0]
+		case key == "string" && len(values) > 0:
+			artifact.String = values[0]
+		case key == "file" && len(values) > 1:
+			artifact.Files = append(artifact.Files, values[1])
+		}
+	}
+
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("Could not find any artifacts in Packer output")
+	}
+	return artifacts, nil
+}
+
+// FilterArtifactsByBuilderID returns the artifacts produced by the builder or post-processor with the given ID, e.g.
+// AmazonEbsBuilderID.
+func FilterArtifactsByBuilderID(artifacts []Artifact, builderID string) []Artifact {
+	filtered := []Artifact{}
+	for _, artifact := range artifacts {
+		if artifact.BuilderID == builderID {
+			filtered = append(filtered, artifact)
+		}
+	}
+	return filtered
+}
+
+// GetAmiIDs returns a map of region to AMI ID for all the AMIs in the given artifacts.
+func GetAmiIDs(artifacts []Artifact) map[string]string {
+	amis := map[string]string{}
+	for _, artifact := range artifacts {
+		for region, ami := range artifact.AmiIDs() {
+			amis[region] = ami
+		}
+	}
+	return amis
+}
+
+// GetDockerImageIDs returns the IDs of the images built by the docker builder in the given artifacts.
+func GetDockerImageIDs(artifacts []Artifact) []string {
+	ids := []string{}
+	for _, artifact := range FilterArtifactsByBuilderID(artifacts, DockerBuilderID) {
+		ids = append(ids, artifact.ID)
+	}
+	return ids
+}
+
+// AssertAmisInRegions checks that the given artifacts contain an AMI in each of the given regions.
+func AssertAmisInRegions(t testing.TestingT, artifacts []Artifact, regions []string) {
+	require.NoError(t, AssertAmisInRegionsE(artifacts, regions))
+}
+
+// AssertAmisInRegionsE checks that the given artifacts contain an AMI in each of the given regions.
+func AssertAmisInRegionsE(artifacts []Artifact, regions []string) error {
+	amis := GetAmiIDs(artifacts)
+	missing := []string{}
+	for _, region := range regions {
+		if _, ok := amis[region]; !ok {
+			missing = append(missing, region)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("No AMI was built in regions %v, only in %v", missing, amiRegions(amis))
+	}
+	return nil
+}
+
+// ParseManifest parses the file written by the Packer manifest post-processor at the given path.
+func ParseManifest(t testing.TestingT, path string) *Manifest {
+	manifest, err := ParseManifestE(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+// ParseManifestE parses the file written by the Packer manifest post-processor at the given path.
+func ParseManifestE(path string) (*Manifest, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("Failed to parse Packer manifest %s: %s", path, err)
+	}
+	return &manifest, nil
+}
+
+// AssertManifestMatchesArtifacts checks that the last run recorded in the given Packer manifest has a build for each
+// of the given builder artifacts, with the same artifact ID.
+func AssertManifestMatchesArtifacts(t testing.TestingT, manifest *Manifest, artifacts []Artifact) {
+	require.NoError(t, AssertManifestMatchesArtifactsE(manifest, artifacts))
+}
+
+// AssertManifestMatchesArtifactsE checks that the last run recorded in the given Packer manifest has a build for
+// each of the given builder artifacts, with the same artifact ID.
+func AssertManifestMatchesArtifactsE(manifest *Manifest, artifacts []Artifact) error {
+	manifestIDs := map[string]bool{}
+	for _, build := range manifest.LastRunBuilds() {
+		manifestIDs[build.ArtifactID] = true
+	}
+
+	for _, artifact := range artifacts {
+		if artifact.ID != "" && !manifestIDs[artifact.ID] {
+			return fmt.Errorf("Artifact %s of build %s is not in the last run of the Packer manifest", artifact.ID, artifact.BuildName)
+		}
+	}
+	return nil
+}
+
+// RegisterAmisForCleanup deletes all the AMIs in the given artifacts, along with the EBS snapshots backing them, when
+// the test finishes. This requires t to support Cleanup, as testing.T does; otherwise nothing is registered and a
+// warning is logged, so defer aws.DeleteAmiAndAllSnapshots instead.
+func RegisterAmisForCleanup(t testing.TestingT, artifacts []Artifact) {
+	cleanupT, ok := t.(interface{ Cleanup(func()) })
+	amis := GetAmiIDs(artifacts)
+	if !ok {
+		if len(amis) > 0 {
+			logger.Logf(t, "WARNING: Cannot register AMIs %v for cleanup because %T does not support Cleanup", amis, t)
+		}
+		return
+	}
+
+	for region, ami := range amis {
+		region := region
+		ami := ami
+		cleanupT.Cleanup(func() {
+			if err := aws.DeleteAmiAndAllSnapshotsE(t, region, ami); err != nil {
+				t.Errorf("Failed to delete AMI %s in %s and its snapshots: %s", ami, region, err)
+			}
+		})
+	}
+}
+
+// unescapeMachineReadable restores the characters Packer escapes in the fields of its machine-readable output.
+func unescapeMachineReadable(field string) string {
+	field = strings.ReplaceAll(field, machineReadableCommaPlaceholder, ",")
+	field = strings.ReplaceAll(field, `\n`, "\n")
+	return strings.ReplaceAll(field, `\r`, "\r")
+}
+
+// amiRegions returns the sorted regions of the given map of region to AMI ID.
+func amiRegions(amis map[string]string) []string {
+	regions := []string{}
+	for region := range amis {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
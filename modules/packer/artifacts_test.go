@@ -0,0 +1,98 @@
+package packer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multiBuildPackerOutput = `
+1456332887,,ui,say,==> Builds finished. The artifacts of successful builds are:
+1456332887,amazon-ebs.ubuntu,artifact-count,1
+1456332887,amazon-ebs.ubuntu,artifact,0,builder-id,mitchellh.amazonebs
+1456332887,amazon-ebs.ubuntu,artifact,0,id,us-east-1:ami-b481b3de%!(PACKER_COMMA)us-west-2:ami-8ef3b2e6
+1456332887,amazon-ebs.ubuntu,artifact,0,string,AMIs were created:\nus-east-1: ami-b481b3de\nus-west-2: ami-8ef3b2e6
+1456332887,amazon-ebs.ubuntu,artifact,0,files-count,0
+1456332887,amazon-ebs.ubuntu,artifact,0,end
+1456332888,docker.app,artifact-count,2
+1456332888,docker.app,artifact,0,builder-id,packer.docker
+1456332888,docker.app,artifact,0,id,sha256:3f57d9401f8d42f986df300f0c69192fc41da28ccc8d797829467780db3dd741
+1456332888,docker.app,artifact,0,end
+1456332888,docker.app,artifact,1,builder-id,packer.post-processor.manifest
+1456332888,docker.app,artifact,1,files-count,1
+1456332888,docker.app,artifact,1,file,0,packer-manifest.json
+1456332888,docker.app,artifact,1,end
+`
+
+func TestParseArtifacts(t *testing.T) {
+	t.Parallel()
+
+	artifacts, err := ParseArtifacts(multiBuildPackerOutput)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 3)
+
+	ami := artifacts[0]
+	assert.Equal(t, "amazon-ebs.ubuntu", ami.BuildName)
+	assert.Equal(t, AmazonEbsBuilderID, ami.BuilderID)
+	assert.Equal(t, "us-east-1:ami-b481b3de,us-west-2:ami-8ef3b2e6", ami.ID)
+	assert.Equal(t, "AMIs were created:\nus-east-1: ami-b481b3de\nus-west-2: ami-8ef3b2e6", ami.String)
+	assert.Equal(t, map[string]string{"us-east-1": "ami-b481b3de", "us-west-2": "ami-8ef3b2e6"}, ami.AmiIDs())
+
+	manifest := artifacts[2]
+	assert.Equal(t, 1, manifest.Index)
+	assert.Equal(t, []string{"packer-manifest.json"}, manifest.Files)
+
+	assert.Equal(t, []string{"sha256:3f57d9401f8d42f986df300f0c69192fc41da28ccc8d797829467780db3dd741"}, GetDockerImageIDs(artifacts))
+	assert.Len(t, FilterArtifactsByBuilderID(artifacts, AmazonEbsBuilderID), 1)
+}
+
+func TestParseArtifactsNoArtifactPresent(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseArtifacts("foo\nbar\n")
+	assert.Error(t, err)
+}
+
+func TestAssertAmisInRegions(t *testing.T) {
+	t.Parallel()
+
+	artifacts, err := ParseArtifacts(multiBuildPackerOutput)
+	require.NoError(t, err)
+
+	assert.NoError(t, AssertAmisInRegionsE(artifacts, []string{"us-east-1", "us-west-2"}))
+	assert.Error(t, AssertAmisInRegionsE(artifacts, []string{"us-east-1", "eu-west-1"}))
+}
+
+func TestParseManifestLastRunBuilds(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "terratest-packer-manifest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	manifestPath := filepath.Join(dir, "packer-manifest.json")
+	content := `{
+  "builds": [
+    {"name": "ubuntu", "builder_type": "amazon-ebs", "artifact_id": "us-east-1:ami-old", "packer_run_uuid": "run-1"},
+    {"name": "ubuntu", "builder_type": "amazon-ebs", "artifact_id": "us-east-1:ami-b481b3de,us-west-2:ami-8ef3b2e6", "packer_run_uuid": "run-2", "custom_data": {"version": "1.2.3"}}
+  ],
+  "last_run_uuid": "run-2"
+}`
+	require.NoError(t, ioutil.WriteFile(manifestPath, []byte(content), 0644))
+
+	manifest, err := ParseManifestE(manifestPath)
+	require.NoError(t, err)
+
+	builds := manifest.LastRunBuilds()
+	require.Len(t, builds, 1)
+	assert.Equal(t, "1.2.3", builds[0].CustomData["version"])
+
+	artifacts, err := ParseArtifacts(multiBuildPackerOutput)
+	require.NoError(t, err)
+	assert.NoError(t, AssertManifestMatchesArtifactsE(manifest, FilterArtifactsByBuilderID(artifacts, AmazonEbsBuilderID)))
+	assert.Error(t, AssertManifestMatchesArtifactsE(manifest, artifacts))
+}
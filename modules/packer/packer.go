@@ -35,6 +35,7 @@ type Options struct {
 	WorkingDir                 string            // The directory to run packer in
 	Logger                     *logger.Logger    // If set, use a non-default logger
 	DisableTemporaryPluginPath bool              // If set, do not use a temporary directory for Packer plugins.
+	CleanupAmis                bool              // If set, delete the AMIs built and their snapshots when the test finishes. See RegisterAmisForCleanup.
 }
 
 // BuildArtifacts can take a map of identifierName <-> Options and then parallelize
@@ -96,6 +97,16 @@ func BuildArtifact(t testing.TestingT, options *Options) string {
 
 // BuildArtifactE builds the given Packer template and return the generated Artifact ID.
 func BuildArtifactE(t testing.TestingT, options *Options) (string, error) {
+	output, err := buildE(t, options)
+	if err != nil {
+		return "", err
+	}
+
+	return extractArtifactID(output)
+}
+
+// buildE runs 'packer build' for the given options and returns the machine-readable output.
+func buildE(t testing.TestingT, options *Options) (string, error) {
 	options.Logger.Logf(t, "Running Packer to generate a custom artifact for template %s", options.Template)
 
 	// By default, we download packer plugins to a temporary directory rather than use the global plugin path.
@@ -136,7 +147,13 @@ func BuildArtifactE(t testing.TestingT, options *Options) (string, error) {
 		return "", err
 	}
 
-	return extractArtifactID(output)
+	if options.CleanupAmis {
+		if artifacts, err := ParseArtifacts(output); err == nil {
+			RegisterAmisForCleanup(t, artifacts)
+		}
+	}
+
+	return output, nil
 }
 
 // BuildAmi builds the given Packer template and return the generated AMI ID.
@@ -0,0 +1,46 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingEnvVarsError is returned when required environment variables are not set.
+type MissingEnvVarsError struct {
+	Names []string
+}
+
+func (err MissingEnvVarsError) Error() string {
+	return fmt.Sprintf("Required environment variables are not set: %s", strings.Join(err.Names, ", "))
+}
+
+// ToolNotFoundError is returned when a required tool is not in the PATH.
+type ToolNotFoundError struct {
+	Name string
+}
+
+func (err ToolNotFoundError) Error() string {
+	return fmt.Sprintf("Required tool %s was not found in the PATH", err.Name)
+}
+
+// ToolVersionMismatchError is returned when the version of a required tool does not satisfy its version constraint.
+type ToolVersionMismatchError struct {
+	Name       string
+	Version    string
+	Constraint string
+}
+
+func (err ToolVersionMismatchError) Error() string {
+	return fmt.Sprintf("Required tool %s is at version %s, which does not satisfy %q", err.Name, err.Version, err.Constraint)
+}
+
+// MissingPermissionsError is returned when the current credentials of a cloud lack required permissions.
+type MissingPermissionsError struct {
+	Cloud       string
+	Scope       string
+	Permissions []string
+}
+
+func (err MissingPermissionsError) Error() string {
+	return fmt.Sprintf("The %s credentials are missing permissions on %s: %s", err.Cloud, err.Scope, strings.Join(err.Permissions, ", "))
+}
@@ -0,0 +1,171 @@
+// Package preflight checks, before any infrastructure is provisioned, that a test has everything it needs to run:
+// cloud credentials with the required permissions, tools in the PATH at the right versions and environment variables.
+// This way a missing permission fails the test in seconds instead of halfway through a long apply.
+package preflight
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/azure"
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2/google"
+)
+
+// Requirements are the things a test needs before it can provision anything. Leave any field empty to skip that check.
+type Requirements struct {
+	EnvVars []string           // Environment variables that must be set to a non-empty value
+	Tools   []Tool             // Tools that must be in the PATH
+	Aws     *AwsRequirements   // Permissions the AWS credentials must have
+	Gcp     *GcpRequirements   // Permissions the GCP credentials must have
+	Azure   *AzureRequirements // Permissions the Azure credentials must have
+}
+
+// AwsRequirements are the IAM actions the current AWS credentials must be allowed to perform, checked with the IAM
+// policy simulator. If no actions are given, only the presence of valid credentials is checked.
+type AwsRequirements struct {
+	Region       string
+	Actions      []string // e.g. "ec2:RunInstances"
+	ResourceArns []string // The resources to simulate the actions on. Defaults to all resources.
+}
+
+// GcpRequirements are the IAM permissions the current GCP credentials must have on a project, checked with
+// testIamPermissions. If no permissions are given, only the presence of default credentials is checked.
+type GcpRequirements struct {
+	ProjectID   string
+	Permissions []string // e.g. "compute.instances.create"
+}
+
+// AzureRequirements are the management actions the current Azure credentials must be allowed to perform in a resource
+// group. If no actions are given, only the presence of credentials is checked.
+type AzureRequirements struct {
+	SubscriptionID    string
+	ResourceGroupName string
+	Actions           []string // e.g. "Microsoft.Network/virtualNetworks/write"
+}
+
+// Check verifies that all the given requirements are met, and fails the test listing every unmet requirement if not.
+func Check(t testing.TestingT, requirements Requirements) {
+	require.NoError(t, CheckE(t, requirements))
+}
+
+// CheckE verifies that all the given requirements are met. Every check is run, and the returned error lists all the
+// unmet requirements rather than just the first one.
+func CheckE(t testing.TestingT, requirements Requirements) error {
+	var errorsOccurred = new(multierror.Error)
+
+	if err := checkEnvVars(requirements.EnvVars); err != nil {
+		errorsOccurred = multierror.Append(errorsOccurred, err)
+	}
+
+	for _, tool := range requirements.Tools {
+		if err := CheckToolE(t, tool); err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, err)
+		}
+	}
+
+	if requirements.Aws != nil {
+		if err := checkAwsE(t, *requirements.Aws); err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, err)
+		}
+	}
+
+	if requirements.Gcp != nil {
+		if err := checkGcpE(t, *requirements.Gcp); err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, err)
+		}
+	}
+
+	if requirements.Azure != nil {
+		if err := checkAzureE(*requirements.Azure); err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, err)
+		}
+	}
+
+	if err := errorsOccurred.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	logger.Logf(t, "All preflight checks passed")
+	return nil
+}
+
+// checkEnvVars returns a MissingEnvVarsError listing the given environment variables that are not set or empty.
+func checkEnvVars(names []string) error {
+	missing := []string{}
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return MissingEnvVarsError{Names: missing}
+	}
+	return nil
+}
+
+// checkAwsE checks that the AWS credentials exist and are allowed to perform the required actions.
+func checkAwsE(t testing.TestingT, requirements AwsRequirements) error {
+	if len(requirements.Actions) == 0 {
+		_, err := aws.GetAccountIdE(t)
+		return err
+	}
+
+	denied, err := aws.GetDeniedIamActionsE(t, requirements.Region, requirements.Actions, requirements.ResourceArns)
+	if err != nil {
+		return err
+	}
+
+	if len(denied) > 0 {
+		scope := "all resources"
+		if len(requirements.ResourceArns) > 0 {
+			scope = strings.Join(requirements.ResourceArns, ", ")
+		}
+		return MissingPermissionsError{Cloud: "AWS", Scope: scope, Permissions: denied}
+	}
+	return nil
+}
+
+// checkGcpE checks that the GCP credentials exist and have the required permissions on the project.
+func checkGcpE(t testing.TestingT, requirements GcpRequirements) error {
+	if len(requirements.Permissions) == 0 {
+		_, err := google.FindDefaultCredentials(context.Background())
+		return err
+	}
+
+	missing, err := gcp.GetMissingProjectPermissionsE(t, requirements.ProjectID, requirements.Permissions)
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		return MissingPermissionsError{Cloud: "GCP", Scope: "project " + requirements.ProjectID, Permissions: missing}
+	}
+	return nil
+}
+
+// checkAzureE checks that the Azure credentials exist and are allowed to perform the required actions in the resource
+// group.
+func checkAzureE(requirements AzureRequirements) error {
+	if len(requirements.Actions) == 0 {
+		_, err := azure.NewAuthorizer()
+		return err
+	}
+
+	missing, err := azure.GetMissingResourceGroupPermissionsE(requirements.Actions, requirements.ResourceGroupName, requirements.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		return MissingPermissionsError{Cloud: "Azure", Scope: "resource group " + requirements.ResourceGroupName, Permissions: missing}
+	}
+	return nil
+}
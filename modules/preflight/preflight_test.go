@@ -0,0 +1,77 @@
+package preflight
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/releases"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckEnvVars(t *testing.T) {
+	os.Setenv("TERRATEST_PREFLIGHT_SET", "value")
+	defer os.Unsetenv("TERRATEST_PREFLIGHT_SET")
+	os.Unsetenv("TERRATEST_PREFLIGHT_UNSET")
+
+	assert.NoError(t, checkEnvVars([]string{"TERRATEST_PREFLIGHT_SET"}))
+
+	err := checkEnvVars([]string{"TERRATEST_PREFLIGHT_SET", "TERRATEST_PREFLIGHT_UNSET"})
+	require.Error(t, err)
+	assert.Equal(t, MissingEnvVarsError{Names: []string{"TERRATEST_PREFLIGHT_UNSET"}}, err)
+}
+
+func TestCheckToolE(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, CheckToolE(t, Tool{Name: "go", VersionArgs: []string{"version"}, VersionConstraint: ">= 1.0"}))
+
+	err := CheckToolE(t, Tool{Name: "go", VersionArgs: []string{"version"}, VersionConstraint: "< 1.0"})
+	assert.IsType(t, ToolVersionMismatchError{}, err)
+
+	err = CheckToolE(t, Tool{Name: "terratest-preflight-missing-tool"})
+	assert.Equal(t, ToolNotFoundError{Name: "terratest-preflight-missing-tool"}, err)
+
+	err = CheckToolE(t, Tool{Name: "go", Sha256: "0000000000000000000000000000000000000000000000000000000000000000"})
+	assert.IsType(t, releases.ChecksumMismatch{}, err)
+}
+
+func TestCheckEReportsAllFailures(t *testing.T) {
+	os.Unsetenv("TERRATEST_PREFLIGHT_UNSET")
+
+	err := CheckE(t, Requirements{
+		EnvVars: []string{"TERRATEST_PREFLIGHT_UNSET"},
+		Tools:   []Tool{{Name: "go"}, {Name: "terratest-preflight-missing-tool"}},
+	})
+	require.Error(t, err)
+
+	merr, ok := err.(*multierror.Error)
+	require.True(t, ok)
+	assert.Len(t, merr.Errors, 2)
+}
+
+func TestParseToolVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		output   string
+		expected string
+	}{
+		{"Terraform v1.0.2\non linux_amd64\n", "1.0.2"},
+		{"go version go1.16.5 linux/amd64", "1.16.5"},
+		{"Packer v1.7.0", "1.7.0"},
+		{"Client Version: v1.21.0-rc.1", "1.21.0-rc.1"},
+		{"ansible-playbook 2.9.6", "2.9.6"},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := parseToolVersion("tool", testCase.output)
+		if assert.NoError(t, err) {
+			assert.Equal(t, testCase.expected, actual)
+		}
+	}
+
+	_, err := parseToolVersion("tool", "no version here")
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package preflight
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+
+	"github.com/gruntwork-io/terratest/modules/asserts"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/releases"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// toolVersionRegexp matches the first version number in the output of a version command, e.g. "1.0.2" in
+// "Terraform v1.0.2" or "1.16.5" in "go version go1.16.5 linux/amd64".
+var toolVersionRegexp = regexp.MustCompile(`\d+\.\d+(\.\d+)?(-[0-9A-Za-z]+(\.[0-9A-Za-z]+)*)?`)
+
+// Tool is a command line tool that must be in the PATH.
+type Tool struct {
+	Name string // The name of the binary, e.g. "terraform"
+	// The arguments that make the tool print its version. Defaults to "--version".
+	VersionArgs []string
+	// The constraint the version must satisfy, e.g. ">= 0.14, < 2.0". Leave empty to only check the tool is in the PATH.
+	VersionConstraint string
+	// The hex encoded SHA256 checksum the binary in the PATH must have, e.g. from the SHA256SUMS of its release. Leave
+	// empty to skip the check.
+	Sha256 string
+}
+
+// CheckTool verifies that the given tool is in the PATH and, if it has a version constraint or checksum, that its
+// version satisfies it and its binary matches it. This function would fail the test if that is not the case.
+func CheckTool(t testing.TestingT, tool Tool) {
+	require.NoError(t, CheckToolE(t, tool))
+}
+
+// CheckToolE verifies that the given tool is in the PATH and, if it has a version constraint or checksum, that its
+// version satisfies it and its binary matches it.
+func CheckToolE(t testing.TestingT, tool Tool) error {
+	path, err := exec.LookPath(tool.Name)
+	if err != nil {
+		return ToolNotFoundError{Name: tool.Name}
+	}
+
+	if tool.Sha256 != "" {
+		if err := releases.VerifySha256E(path, tool.Sha256); err != nil {
+			return err
+		}
+	}
+
+	if tool.VersionConstraint == "" {
+		return nil
+	}
+
+	version, err := GetToolVersionE(t, tool)
+	if err != nil {
+		return err
+	}
+
+	if err := asserts.AssertSemverConstraintE(version, tool.VersionConstraint); err != nil {
+		return ToolVersionMismatchError{Name: tool.Name, Version: version, Constraint: tool.VersionConstraint}
+	}
+	return nil
+}
+
+// GetToolVersion runs the version command of the given tool and returns the version number it prints.
+func GetToolVersion(t testing.TestingT, tool Tool) string {
+	version, err := GetToolVersionE(t, tool)
+	require.NoError(t, err)
+	return version
+}
+
+// GetToolVersionE runs the version command of the given tool and returns the version number it prints.
+func GetToolVersionE(t testing.TestingT, tool Tool) (string, error) {
+	args := tool.VersionArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+
+	output, err := shell.RunCommandAndGetOutputE(t, shell.Command{
+		Command: tool.Name,
+		Args:    args,
+		Logger:  logger.Discard,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return parseToolVersion(tool.Name, output)
+}
+
+// parseToolVersion extracts the first version number from the given output of a version command.
+func parseToolVersion(name string, output string) (string, error) {
+	version := toolVersionRegexp.FindString(output)
+	if version == "" {
+		return "", fmt.Errorf("Could not find a version number in the output of %s: %s", name, output)
+	}
+	return version, nil
+}
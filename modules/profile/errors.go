@@ -0,0 +1,14 @@
+package profile
+
+import "fmt"
+
+// ProfileNotFound is returned when the requested profile does not exist in the profiles directory.
+type ProfileNotFound struct {
+	Name      string
+	Dir       string
+	Available []string
+}
+
+func (err ProfileNotFound) Error() string {
+	return fmt.Sprintf("Profile '%s' not found in %s. Available profiles: %v", err.Name, err.Dir, err.Available)
+}
@@ -0,0 +1,223 @@
+// Package profile loads named environment profiles (e.g. dev, stage, prod-like) that supply the Terraform vars,
+// regions, resource sizes and feature flags of a test, so that the same test can run with a small, cheap configuration
+// locally and a production-like configuration in a nightly build. The profile is selected with the TERRATEST_PROFILE
+// environment variable.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ProfileEnvVar is the environment variable that selects the profile to load, e.g. TERRATEST_PROFILE=nightly.
+const ProfileEnvVar = "TERRATEST_PROFILE"
+
+// profileFileExtensions are the supported profile file extensions, in the order they are looked up.
+var profileFileExtensions = []string{".yaml", ".yml", ".json", ".hcl"}
+
+// Profile is a named set of parameters for a test. In a YAML profile it looks like:
+//
+//	regions: [us-east-1, us-west-2]
+//	vars:
+//	  cluster_name_prefix: nightly
+//	sizes:
+//	  instance_type: m5.large
+//	  node_count: "3"
+//	features:
+//	  multi_az: true
+//
+// The same fields can be set as attributes of an HCL profile, e.g. `regions = ["us-east-1"]`.
+type Profile struct {
+	Name     string                 `json:"-"`
+	Regions  []string               `json:"regions"`  // The regions the test may run in
+	Vars     map[string]interface{} `json:"vars"`     // Terraform vars
+	Sizes    map[string]string      `json:"sizes"`    // Resource sizes, which are passed to Terraform as vars as well
+	Features map[string]bool        `json:"features"` // Feature flags the test can check with FeatureEnabled
+	EnvVars  map[string]string      `json:"env_vars"` // Environment variables to set when running Terraform
+}
+
+// Load loads the profile selected by the TERRATEST_PROFILE environment variable, or the given default profile if it
+// is not set, from the given directory. A profile named "nightly" is read from nightly.yaml, nightly.yml,
+// nightly.json or nightly.hcl in that directory.
+func Load(t testing.TestingT, dir string, defaultName string) *Profile {
+	profile, err := LoadE(t, dir, defaultName)
+	require.NoError(t, err)
+	return profile
+}
+
+// LoadE loads the profile selected by the TERRATEST_PROFILE environment variable, or the given default profile if it
+// is not set, from the given directory. A profile named "nightly" is read from nightly.yaml, nightly.yml,
+// nightly.json or nightly.hcl in that directory.
+func LoadE(t testing.TestingT, dir string, defaultName string) (*Profile, error) {
+	name := os.Getenv(ProfileEnvVar)
+	if name == "" {
+		name = defaultName
+	}
+	return LoadByNameE(t, dir, name)
+}
+
+// LoadByName loads the profile with the given name from the given directory, ignoring TERRATEST_PROFILE.
+func LoadByName(t testing.TestingT, dir string, name string) *Profile {
+	profile, err := LoadByNameE(t, dir, name)
+	require.NoError(t, err)
+	return profile
+}
+
+// LoadByNameE loads the profile with the given name from the given directory, ignoring TERRATEST_PROFILE.
+func LoadByNameE(t testing.TestingT, dir string, name string) (*Profile, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("Invalid profile name %q", name)
+	}
+
+	for _, extension := range profileFileExtensions {
+		path := filepath.Join(dir, name+extension)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		logger.Logf(t, "Loading profile '%s' from %s", name, path)
+		profile, err := parseProfileFileE(t, path)
+		if err != nil {
+			return nil, err
+		}
+		profile.Name = name
+		return profile, nil
+	}
+
+	return nil, ProfileNotFound{Name: name, Dir: dir, Available: listProfiles(dir)}
+}
+
+// FeatureEnabled returns true if the given feature flag is set to true in the profile.
+func (profile *Profile) FeatureEnabled(name string) bool {
+	return profile.Features[name]
+}
+
+// Size returns the given resource size from the profile, or the given default if the profile does not set it.
+func (profile *Profile) Size(name string, defaultValue string) string {
+	if size, ok := profile.Sizes[name]; ok {
+		return size
+	}
+	return defaultValue
+}
+
+// Region returns a random region from the regions of the profile. This function fails the test if the profile has
+// no regions.
+func (profile *Profile) Region(t testing.TestingT) string {
+	region, err := profile.RegionE()
+	require.NoError(t, err)
+	return region
+}
+
+// RegionE returns a random region from the regions of the profile.
+func (profile *Profile) RegionE() (string, error) {
+	if len(profile.Regions) == 0 {
+		return "", fmt.Errorf("Profile '%s' has no regions", profile.Name)
+	}
+	return random.RandomString(profile.Regions), nil
+}
+
+// TerraformOptions makes a copy of the given Terraform options with the vars, sizes and environment variables of the
+// profile added. Values already set in the given options take precedence over those of the profile, so a test can
+// still pin specific values. This will fail the test if there are any errors in the cloning process.
+func (profile *Profile) TerraformOptions(t testing.TestingT, originalOptions *terraform.Options) *terraform.Options {
+	newOptions, err := originalOptions.Clone()
+	require.NoError(t, err)
+
+	// Clone does not copy maps, so build new ones to leave the original options untouched
+	vars := map[string]interface{}{}
+	for key, value := range profile.Vars {
+		vars[key] = value
+	}
+	for key, value := range profile.Sizes {
+		vars[key] = value
+	}
+	for key, value := range originalOptions.Vars {
+		vars[key] = value
+	}
+	newOptions.Vars = vars
+
+	envVars := map[string]string{}
+	for key, value := range profile.EnvVars {
+		envVars[key] = value
+	}
+	for key, value := range originalOptions.EnvVars {
+		envVars[key] = value
+	}
+	newOptions.EnvVars = envVars
+
+	return newOptions
+}
+
+// parseProfileFileE parses the given YAML, JSON or HCL profile file.
+func parseProfileFileE(t testing.TestingT, path string) (*Profile, error) {
+	var jsonBytes []byte
+
+	if filepath.Ext(path) == ".hcl" {
+		// HCL profiles have the same syntax as Terraform var files
+		var values map[string]interface{}
+		if err := terraform.GetAllVariablesFromVarFileE(t, path, &values); err != nil {
+			return nil, err
+		}
+
+		var err error
+		jsonBytes, err = json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// YAML is a superset of JSON, so this handles JSON profiles as well
+		jsonBytes, err = yaml.YAMLToJSON(contents)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse profile %s: %s", path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	decoder.DisallowUnknownFields()
+
+	profile := &Profile{}
+	if err := decoder.Decode(profile); err != nil {
+		return nil, fmt.Errorf("Failed to parse profile %s: %s", path, err)
+	}
+
+	return profile, nil
+}
+
+// listProfiles returns the names of the profiles in the given directory, for use in error messages.
+func listProfiles(dir string) []string {
+	names := []string{}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return names
+	}
+
+	for _, file := range files {
+		extension := filepath.Ext(file.Name())
+		for _, supported := range profileFileExtensions {
+			if !file.IsDir() && extension == supported {
+				names = append(names, strings.TrimSuffix(file.Name(), extension))
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,137 @@
+package profile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlProfile = `
+regions: [us-east-1, us-west-2]
+vars:
+  name_prefix: nightly
+  tags:
+    team: platform
+sizes:
+  instance_type: m5.large
+features:
+  multi_az: true
+env_vars:
+  TF_LOG: INFO
+`
+
+const hclProfile = `
+regions = ["eu-west-1"]
+sizes = {
+  instance_type = "t3.micro"
+}
+features = {
+  multi_az = false
+}
+`
+
+func writeProfiles(t *testing.T, profiles map[string]string) string {
+	dir, err := ioutil.TempDir("", "terratest-profiles")
+	require.NoError(t, err)
+
+	for fileName, contents := range profiles {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fileName), []byte(contents), 0644))
+	}
+	return dir
+}
+
+func TestLoadYamlProfile(t *testing.T) {
+	t.Parallel()
+
+	dir := writeProfiles(t, map[string]string{"nightly.yaml": yamlProfile})
+	defer os.RemoveAll(dir)
+
+	profile := LoadByName(t, dir, "nightly")
+
+	assert.Equal(t, "nightly", profile.Name)
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, profile.Regions)
+	assert.Contains(t, profile.Regions, profile.Region(t))
+	assert.Equal(t, "m5.large", profile.Size("instance_type", "t3.micro"))
+	assert.Equal(t, "10", profile.Size("disk_size", "10"))
+	assert.True(t, profile.FeatureEnabled("multi_az"))
+	assert.False(t, profile.FeatureEnabled("unknown"))
+}
+
+func TestLoadHclProfile(t *testing.T) {
+	t.Parallel()
+
+	dir := writeProfiles(t, map[string]string{"dev.hcl": hclProfile})
+	defer os.RemoveAll(dir)
+
+	profile := LoadByName(t, dir, "dev")
+
+	assert.Equal(t, []string{"eu-west-1"}, profile.Regions)
+	assert.Equal(t, "t3.micro", profile.Size("instance_type", ""))
+	assert.False(t, profile.FeatureEnabled("multi_az"))
+}
+
+func TestLoadProfileRejectsUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	dir := writeProfiles(t, map[string]string{"typo.yaml": "region: us-east-1\n"})
+	defer os.RemoveAll(dir)
+
+	_, err := LoadByNameE(t, dir, "typo")
+	assert.Error(t, err)
+}
+
+func TestLoadMissingProfileListsAvailableProfiles(t *testing.T) {
+	t.Parallel()
+
+	dir := writeProfiles(t, map[string]string{"dev.hcl": hclProfile, "nightly.yaml": yamlProfile, "README.md": "profiles"})
+	defer os.RemoveAll(dir)
+
+	_, err := LoadByNameE(t, dir, "prod")
+	require.Error(t, err)
+
+	notFound, ok := err.(ProfileNotFound)
+	require.True(t, ok)
+	assert.Equal(t, []string{"dev", "nightly"}, notFound.Available)
+}
+
+// Not parallel, as it sets an environment variable
+func TestLoadProfileSelectedByEnvVar(t *testing.T) {
+	dir := writeProfiles(t, map[string]string{"dev.hcl": hclProfile, "nightly.yaml": yamlProfile})
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, "dev", Load(t, dir, "dev").Name)
+
+	os.Setenv(ProfileEnvVar, "nightly")
+	defer os.Unsetenv(ProfileEnvVar)
+
+	assert.Equal(t, "nightly", Load(t, dir, "dev").Name)
+}
+
+func TestProfileTerraformOptions(t *testing.T) {
+	t.Parallel()
+
+	dir := writeProfiles(t, map[string]string{"nightly.yaml": yamlProfile})
+	defer os.RemoveAll(dir)
+
+	profile := LoadByName(t, dir, "nightly")
+	original := &terraform.Options{
+		TerraformDir: "/tmp/module",
+		Vars:         map[string]interface{}{"name_prefix": "pinned"},
+	}
+
+	options := profile.TerraformOptions(t, original)
+
+	assert.Equal(t, "/tmp/module", options.TerraformDir)
+	assert.Equal(t, "pinned", options.Vars["name_prefix"])
+	assert.Equal(t, "m5.large", options.Vars["instance_type"])
+	assert.Equal(t, map[string]interface{}{"team": "platform"}, options.Vars["tags"])
+	assert.Equal(t, "INFO", options.EnvVars["TF_LOG"])
+
+	// The original options are left untouched
+	assert.Equal(t, map[string]interface{}{"name_prefix": "pinned"}, original.Vars)
+}
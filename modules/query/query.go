@@ -0,0 +1,94 @@
+// Package query allows to select values from API responses, Terraform plan JSON and Kubernetes objects with JMESPath
+// expressions, instead of walking nested structs by hand.
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/jmespath/go-jmespath"
+	"github.com/stretchr/testify/require"
+)
+
+// Jmes evaluates the given JMESPath expression (e.g. "Reservations[].Instances[].InstanceId") against the given data
+// and returns the result. See JmesE for the supported data. This will fail the test if there is an error.
+func Jmes(t testing.TestingT, data interface{}, expression string) interface{} {
+	result, err := JmesE(data, expression)
+	require.NoError(t, err)
+	return result
+}
+
+// JmesE evaluates the given JMESPath expression (e.g. "Reservations[].Instances[].InstanceId") against the given data
+// and returns the result. The data can be raw JSON as a []byte or string (e.g. the output of terraform show -json), or
+// any value that can be marshaled to JSON, like AWS SDK responses (whose fields are queried by their Go names) or
+// Kubernetes unstructured objects. JSON objects are returned as map[string]interface{}, arrays as []interface{} and
+// numbers as float64.
+func JmesE(data interface{}, expression string) (interface{}, error) {
+	compiled, err := jmespath.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid JMESPath expression %q: %s", expression, err)
+	}
+
+	normalized, err := normalize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := compiled.Search(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to evaluate JMESPath expression %q: %s", expression, err)
+	}
+	return result, nil
+}
+
+// JmesInto evaluates the given JMESPath expression against the given data, like Jmes, and unmarshals the result into
+// output, e.g. a *[]string. This will fail the test if there is an error.
+func JmesInto(t testing.TestingT, data interface{}, expression string, output interface{}) {
+	require.NoError(t, JmesIntoE(data, expression, output))
+}
+
+// JmesIntoE evaluates the given JMESPath expression against the given data, like JmesE, and unmarshals the result into
+// output, e.g. a *[]string.
+func JmesIntoE(data interface{}, expression string, output interface{}) error {
+	result, err := JmesE(data, expression)
+	if err != nil {
+		return err
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(resultJSON, output); err != nil {
+		return fmt.Errorf("Cannot unmarshal the result of JMESPath expression %q into %T: %s", expression, output, err)
+	}
+	return nil
+}
+
+// normalize converts the given data into the generic JSON representation that JMESPath operates on. Going through
+// JSON dereferences the pointers used throughout SDK structs and applies their custom JSON marshaling.
+func normalize(data interface{}) (interface{}, error) {
+	var raw []byte
+	switch typed := data.(type) {
+	case []byte:
+		raw = typed
+	case string:
+		raw = []byte(typed)
+	case json.RawMessage:
+		raw = typed
+	default:
+		marshaled, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot query %T as JSON: %s", data, err)
+		}
+		raw = marshaled
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, fmt.Errorf("Cannot query invalid JSON: %s", err)
+	}
+	return normalized, nil
+}
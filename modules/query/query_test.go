@@ -0,0 +1,62 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestJmesOverSdkResponse(t *testing.T) {
+	t.Parallel()
+
+	response := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{Instances: []*ec2.Instance{{InstanceId: aws.String("i-1")}, {InstanceId: aws.String("i-2")}}},
+			{Instances: []*ec2.Instance{{InstanceId: aws.String("i-3")}}},
+		},
+	}
+
+	result, err := JmesE(response, "Reservations[].Instances[].InstanceId")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"i-1", "i-2", "i-3"}, result)
+
+	var ids []string
+	require.NoError(t, JmesIntoE(response, "Reservations[].Instances[].InstanceId", &ids))
+	assert.Equal(t, []string{"i-1", "i-2", "i-3"}, ids)
+}
+
+func TestJmesOverPlanJson(t *testing.T) {
+	t.Parallel()
+
+	plan := []byte(`{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"actions": ["create"]}},
+		{"address": "aws_s3_bucket.logs", "change": {"actions": ["no-op"]}}
+	]}`)
+
+	var created []string
+	JmesInto(t, plan, "resource_changes[?contains(change.actions, 'create')].address", &created)
+	assert.Equal(t, []string{"aws_instance.web"}, created)
+}
+
+func TestJmesOverUnstructured(t *testing.T) {
+	t.Parallel()
+
+	object := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec":     map[string]interface{}{"replicas": int64(3)},
+	}}
+
+	assert.Equal(t, float64(3), Jmes(t, object, "spec.replicas"))
+}
+
+func TestJmesEInvalidExpression(t *testing.T) {
+	t.Parallel()
+
+	_, err := JmesE(`{}`, "Reservations[")
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+// Package ratelimit contains a rate limiter shared by all the calls the modules make to cloud APIs, and a backoff for
+// retrying calls that were throttled anyway, so that large suites of parallel tests don't fail because of throttling.
+//
+// Calls are limited per service, where a service is e.g. "aws/ec2", "gcp/compute" or "azure/Microsoft.Network". By
+// default, calls are not limited at all, but throttled calls are always retried. Set the limits for the services your
+// tests call heavily, e.g. in TestMain:
+//
+//	ratelimit.SetLimit("aws/ec2", ratelimit.Limit{RequestsPerSecond: 10, Burst: 20})
+package ratelimit
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// minThrottleBackoff is how long to wait before retrying a call that was throttled for the first time.
+	minThrottleBackoff = 1 * time.Second
+
+	// maxThrottleBackoff is the longest to wait before retrying a throttled call.
+	maxThrottleBackoff = 30 * time.Second
+
+	// defaultMaxThrottleRetries is how many times a throttled call is retried by default.
+	defaultMaxThrottleRetries = 8
+)
+
+// Limit is the rate at which calls to a service may be made.
+type Limit struct {
+	RequestsPerSecond float64 // The number of calls per second to allow on average. Zero means no limit.
+	Burst             int     // The number of calls to allow at once, above the average rate. Defaults to 1.
+}
+
+func (limit Limit) newLimiter() *rate.Limiter {
+	if limit.RequestsPerSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), burst)
+}
+
+var (
+	mutex              sync.Mutex
+	defaultLimit       = Limit{}
+	limits             = map[string]Limit{}
+	limiters           = map[string]*rate.Limiter{}
+	maxThrottleRetries = defaultMaxThrottleRetries
+)
+
+// SetLimit sets the rate at which calls to the given service may be made.
+func SetLimit(service string, limit Limit) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	limits[service] = limit
+	limiters[service] = limit.newLimiter()
+}
+
+// SetDefaultLimit sets the rate at which calls to each of the services without a limit of their own may be made.
+func SetDefaultLimit(limit Limit) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	defaultLimit = limit
+	for service := range limiters {
+		if _, hasLimit := limits[service]; !hasLimit {
+			delete(limiters, service)
+		}
+	}
+}
+
+// SetMaxThrottleRetries sets how many times a throttled call is retried before giving up. Defaults to 8.
+func SetMaxThrottleRetries(retries int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	maxThrottleRetries = retries
+}
+
+// MaxThrottleRetries returns how many times a throttled call is retried before giving up.
+func MaxThrottleRetries() int {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return maxThrottleRetries
+}
+
+// Wait blocks until a call to the given service is allowed by its limit, or the given context is done.
+func Wait(ctx context.Context, service string) error {
+	return getLimiter(service).Wait(ctx)
+}
+
+func getLimiter(service string) *rate.Limiter {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	limiter, ok := limiters[service]
+	if !ok {
+		limiter = defaultLimit.newLimiter()
+		limiters[service] = limiter
+	}
+	return limiter
+}
+
+// ThrottleBackoff returns how long to wait before the given retry (starting at 0) of a throttled call: an exponential
+// backoff with jitter, so that parallel tests that were throttled at the same time don't all retry at the same time.
+func ThrottleBackoff(retry int) time.Duration {
+	backoff := maxThrottleBackoff
+	if retry < 5 {
+		backoff = minThrottleBackoff << uint(retry)
+	}
+	if backoff > maxThrottleBackoff {
+		backoff = maxThrottleBackoff
+	}
+	// Wait somewhere between half and all of the backoff
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// transport is an http.RoundTripper that applies the limit of a service to the requests it sends and retries the ones
+// that are throttled.
+type transport struct {
+	service string
+	base    http.RoundTripper
+}
+
+// NewTransport returns an http.RoundTripper that sends requests with the given base RoundTripper (or
+// http.DefaultTransport if nil), waiting for the limit of the given service before each one, and retrying requests that
+// are throttled with a 429 Too Many Requests response, honoring the Retry-After header if there is one (up to
+// 30 seconds).
+func NewTransport(service string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{service: service, base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := MaxThrottleRetries()
+
+	for retry := 0; ; retry++ {
+		if err := Wait(req.Context(), t.service); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || retry >= retries {
+			return resp, err
+		}
+
+		// The body of the request has been consumed, so it can only be retried if it can be read again
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		backoff := retryAfter(resp)
+		if backoff <= 0 {
+			backoff = ThrottleBackoff(retry)
+		} else if backoff > maxThrottleBackoff {
+			backoff = maxThrottleBackoff
+		}
+		resp.Body.Close()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter returns how long the Retry-After header of the given response says to wait, or 0 if it does not say.
+func retryAfter(resp *http.Response) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitAppliesLimitPerService(t *testing.T) {
+	t.Parallel()
+
+	service := fmt.Sprintf("test/%s", t.Name())
+	SetLimit(service, Limit{RequestsPerSecond: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, Wait(context.Background(), service))
+	}
+	// The first call is allowed at once, the other 4 at 20 per second
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "calls were not limited")
+
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, Wait(context.Background(), service+"/unlimited"))
+	}
+	assert.True(t, time.Since(start) < 50*time.Millisecond, "calls to a service without a limit were limited")
+}
+
+func TestWaitReturnsWhenContextDone(t *testing.T) {
+	t.Parallel()
+
+	service := fmt.Sprintf("test/%s", t.Name())
+	SetLimit(service, Limit{RequestsPerSecond: 0.001, Burst: 1})
+	require.NoError(t, Wait(context.Background(), service))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, Wait(ctx, service))
+}
+
+func TestThrottleBackoff(t *testing.T) {
+	t.Parallel()
+
+	for retry := 0; retry < 10; retry++ {
+		backoff := ThrottleBackoff(retry)
+		expected := maxThrottleBackoff
+		if retry < 5 {
+			expected = minThrottleBackoff << uint(retry)
+		}
+		assert.True(t, backoff >= expected/2 && backoff <= expected, "backoff %s of retry %d not in [%s, %s]", backoff, retry, expected/2, expected)
+	}
+}
+
+func TestTransportRetriesThrottledRequests(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(fmt.Sprintf("test/%s", t.Name()), nil)}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, time.Duration(0), retryAfter(&http.Response{Header: http.Header{}}))
+	assert.Equal(t, 5*time.Second, retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{"5"}}}))
+	assert.Equal(t, time.Duration(0), retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{"soon"}}}))
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	backoff := retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{date}}})
+	assert.True(t, backoff > 50*time.Second && backoff <= time.Minute)
+}
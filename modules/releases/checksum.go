@@ -0,0 +1,111 @@
+// Package releases downloads the binaries of tools such as terraform, packer and helm and verifies them against their
+// published SHA256 checksums and signatures, so a compromised mirror or a tampered download fails the test instead of
+// running.
+package releases
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// GetSha256 returns the hex encoded SHA256 checksum of the file at the given path. This will fail the test if there is
+// an error.
+func GetSha256(t testing.TestingT, path string) string {
+	checksum, err := GetSha256E(path)
+	require.NoError(t, err)
+	return checksum
+}
+
+// GetSha256E returns the hex encoded SHA256 checksum of the file at the given path.
+func GetSha256E(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifySha256 verifies that the file at the given path has the given hex encoded SHA256 checksum. This will fail the
+// test if it does not or if there is an error.
+func VerifySha256(t testing.TestingT, path string, expected string) {
+	require.NoError(t, VerifySha256E(path, expected))
+}
+
+// VerifySha256E verifies that the file at the given path has the given hex encoded SHA256 checksum, and returns a
+// ChecksumMismatch error if it does not.
+func VerifySha256E(path string, expected string) error {
+	actual, err := GetSha256E(path)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return ChecksumMismatch{Path: path, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// VerifySha256Sums verifies the file at the given path against its entry in the given checksums file, which uses the
+// "<checksum>  <file name>" format of sha256sum, as in the SHA256SUMS files of HashiCorp releases and the .sha256sum
+// files of Helm releases. This will fail the test if the checksum does not match or if there is an error.
+func VerifySha256Sums(t testing.TestingT, path string, sumsPath string) {
+	require.NoError(t, VerifySha256SumsE(path, sumsPath))
+}
+
+// VerifySha256SumsE verifies the file at the given path against its entry in the given checksums file, which uses the
+// "<checksum>  <file name>" format of sha256sum, as in the SHA256SUMS files of HashiCorp releases and the .sha256sum
+// files of Helm releases.
+func VerifySha256SumsE(path string, sumsPath string) error {
+	contents, err := ioutil.ReadFile(sumsPath)
+	if err != nil {
+		return err
+	}
+
+	expected, err := findSha256Sum(string(contents), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	return VerifySha256E(path, expected)
+}
+
+// findSha256Sum returns the checksum of the given file name in the given contents of a checksums file. A file with a
+// single entry matches any file name, as the .sha256sum files for a single artifact do not always name it.
+func findSha256Sum(sums string, fileName string) (string, error) {
+	entries := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch len(fields) {
+		case 1:
+			entries[""] = fields[0]
+		case 2:
+			// sha256sum marks files read in binary mode with a leading *
+			entries[strings.TrimPrefix(fields[1], "*")] = fields[0]
+		}
+	}
+
+	if checksum, ok := entries[fileName]; ok {
+		return checksum, nil
+	}
+	if len(entries) == 1 {
+		for _, checksum := range entries {
+			return checksum, nil
+		}
+	}
+	return "", ChecksumNotFound{FileName: fileName}
+}
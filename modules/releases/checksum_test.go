@@ -0,0 +1,59 @@
+package releases
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The SHA256 checksum of "hello terratest\n"
+const testFileSha256 = "a2210490e8ef71fb66e114a4e35d655c890937a4c464c66d5fe9ac3209637282"
+
+func TestVerifySha256Sums(t *testing.T) {
+	t.Parallel()
+
+	dir := createTempDir(t)
+	path := writeTestFile(t, dir, "terraform_1.0.2_linux_amd64.zip", "hello terratest\n")
+	checksum := GetSha256(t, path)
+	assert.Equal(t, testFileSha256, checksum)
+
+	sumsPath := writeTestFile(t, dir, "SHA256SUMS", "0000000000000000000000000000000000000000000000000000000000000000  terraform_1.0.2_darwin_amd64.zip\n"+checksum+"  terraform_1.0.2_linux_amd64.zip\n")
+	assert.NoError(t, VerifySha256SumsE(path, sumsPath))
+
+	tamperedPath := writeTestFile(t, createTempDir(t), "terraform_1.0.2_linux_amd64.zip", "tampered\n")
+	err := VerifySha256SumsE(tamperedPath, sumsPath)
+	require.Error(t, err)
+	assert.IsType(t, ChecksumMismatch{}, err)
+
+	missingPath := writeTestFile(t, dir, "terraform_1.0.2_windows_amd64.zip", "hello terratest\n")
+	assert.Equal(t, ChecksumNotFound{FileName: "terraform_1.0.2_windows_amd64.zip"}, VerifySha256SumsE(missingPath, sumsPath))
+}
+
+func TestFindSha256SumSingleEntry(t *testing.T) {
+	t.Parallel()
+
+	checksum, err := findSha256Sum("abc123\n", "helm-v3.6.0-linux-amd64.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", checksum)
+
+	checksum, err = findSha256Sum("def456 *helm-v3.6.0-linux-amd64.tar.gz\n", "helm-v3.6.0-linux-amd64.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "def456", checksum)
+}
+
+func createTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "terratest-releases")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func writeTestFile(t *testing.T, dir string, name string, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	return path
+}
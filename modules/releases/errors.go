@@ -0,0 +1,43 @@
+package releases
+
+import "fmt"
+
+// ChecksumMismatch is an error that occurs when a file does not have the expected checksum.
+type ChecksumMismatch struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (err ChecksumMismatch) Error() string {
+	return fmt.Sprintf("SHA256 checksum of %s is %s, but expected %s", err.Path, err.Actual, err.Expected)
+}
+
+// ChecksumNotFound is an error that occurs when a checksums file does not list a file.
+type ChecksumNotFound struct {
+	FileName string
+}
+
+func (err ChecksumNotFound) Error() string {
+	return fmt.Sprintf("No checksum found for %s", err.FileName)
+}
+
+// InvalidSignature is an error that occurs when the signature of a file is not valid for the trusted keys.
+type InvalidSignature struct {
+	Path          string
+	SignaturePath string
+	Reason        string
+}
+
+func (err InvalidSignature) Error() string {
+	return fmt.Sprintf("Signature %s of %s is not valid: %s", err.SignaturePath, err.Path, err.Reason)
+}
+
+// MissingPublicKey is an error that occurs when a release is downloaded without a public key to verify it with.
+type MissingPublicKey struct {
+	Product string
+}
+
+func (err MissingPublicKey) Error() string {
+	return fmt.Sprintf("A public key is required to verify the signature of %s. Refusing to download it unverified.", err.Product)
+}
@@ -0,0 +1,149 @@
+package releases
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultHashiCorpReleasesURL is the URL HashiCorp publishes the releases of terraform, packer and its other tools at.
+const DefaultHashiCorpReleasesURL = "https://releases.hashicorp.com"
+
+// HashiCorpRelease identifies a release of a HashiCorp tool to download and verify.
+type HashiCorpRelease struct {
+	Product string // The name of the tool, e.g. "terraform" or "packer"
+	Version string // The version to download, e.g. "1.0.2"
+	OS      string // The operating system of the binary. Defaults to runtime.GOOS.
+	Arch    string // The architecture of the binary. Defaults to runtime.GOARCH.
+	// The ASCII armored public key that signs the SHA256SUMS file, as published at https://www.hashicorp.com/security.
+	// Required, as a release is never installed without verifying its signature.
+	PublicKey string
+	BaseURL   string // The URL to download from. Defaults to DefaultHashiCorpReleasesURL.
+}
+
+// DownloadHashiCorpRelease downloads the given release of a HashiCorp tool, verifies the signature of its SHA256SUMS
+// file and the checksum of its zip archive, and extracts the binary into the given directory. Returns the path of the
+// binary, e.g. to use as TerraformBinary or PackerBinary. This will fail the test if the verification fails or if
+// there is an error.
+func DownloadHashiCorpRelease(t testing.TestingT, release HashiCorpRelease, destDir string) string {
+	path, err := DownloadHashiCorpReleaseE(t, release, destDir)
+	require.NoError(t, err)
+	return path
+}
+
+// DownloadHashiCorpReleaseE downloads the given release of a HashiCorp tool, verifies the signature of its SHA256SUMS
+// file and the checksum of its zip archive, and extracts the binary into the given directory. Returns the path of the
+// binary, e.g. to use as TerraformBinary or PackerBinary.
+func DownloadHashiCorpReleaseE(t testing.TestingT, release HashiCorpRelease, destDir string) (string, error) {
+	if release.PublicKey == "" {
+		return "", MissingPublicKey{Product: release.Product}
+	}
+
+	downloadDir, err := ioutil.TempDir("", "terratest-"+release.Product)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(downloadDir)
+
+	archiveName := fmt.Sprintf("%s_%s_%s_%s.zip", release.Product, release.Version, valueOrDefault(release.OS, runtime.GOOS), valueOrDefault(release.Arch, runtime.GOARCH))
+	sumsName := fmt.Sprintf("%s_%s_SHA256SUMS", release.Product, release.Version)
+	releaseURL := fmt.Sprintf("%s/%s/%s", valueOrDefault(release.BaseURL, DefaultHashiCorpReleasesURL), release.Product, release.Version)
+
+	logger.Logf(t, "Downloading %s from %s", archiveName, releaseURL)
+	for _, name := range []string{archiveName, sumsName, sumsName + ".sig"} {
+		if err := downloadFileE(releaseURL+"/"+name, filepath.Join(downloadDir, name)); err != nil {
+			return "", err
+		}
+	}
+
+	// The signature covers the checksums, which in turn cover the archive
+	sumsPath := filepath.Join(downloadDir, sumsName)
+	if err := VerifyGpgSignatureE(sumsPath, sumsPath+".sig", release.PublicKey); err != nil {
+		return "", err
+	}
+
+	archivePath := filepath.Join(downloadDir, archiveName)
+	if err := VerifySha256SumsE(archivePath, sumsPath); err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Verified the signature and checksum of %s", archiveName)
+	return extractBinaryE(archivePath, release.Product, destDir)
+}
+
+// downloadFileE downloads the given URL to the given path.
+func downloadFileE(url string, path string) error {
+	client := http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Downloading %s failed with status %d", url, resp.StatusCode)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, resp.Body)
+	return err
+}
+
+// extractBinaryE extracts the binary with the given name (ignoring a .exe suffix) from the given zip archive into the
+// given directory, and returns its path.
+func extractBinaryE(archivePath string, name string, destDir string) (string, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if entry.Name != name && entry.Name != name+".exe" {
+			continue
+		}
+
+		reader, err := entry.Open()
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		path := filepath.Join(destDir, entry.Name)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		if _, err := io.Copy(file, reader); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	return "", fmt.Errorf("Archive %s does not contain %s", archivePath, name)
+}
+
+// valueOrDefault returns the given value, or the given default if it is empty.
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,118 @@
+package releases
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func TestDownloadHashiCorpRelease(t *testing.T) {
+	t.Parallel()
+
+	signer, publicKey := createTestGpgKey(t)
+	archive := createTestZip(t, "terraform", "#!/bin/sh\necho terraform\n")
+	sums := fmt.Sprintf("%s  terraform_1.0.2_linux_amd64.zip\n", sha256Hex(archive))
+
+	files := map[string][]byte{
+		"/terraform/1.0.2/terraform_1.0.2_linux_amd64.zip": archive,
+		"/terraform/1.0.2/terraform_1.0.2_SHA256SUMS":      []byte(sums),
+		"/terraform/1.0.2/terraform_1.0.2_SHA256SUMS.sig":  detachSign(t, signer, []byte(sums)),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contents, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(contents)
+	}))
+	defer server.Close()
+
+	release := HashiCorpRelease{Product: "terraform", Version: "1.0.2", OS: "linux", Arch: "amd64", PublicKey: publicKey, BaseURL: server.URL}
+
+	destDir := createTempDir(t)
+	path := DownloadHashiCorpRelease(t, release, destDir)
+	assert.Equal(t, filepath.Join(destDir, "terraform"), path)
+	contents, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho terraform\n", string(contents))
+
+	// A tampered archive no longer matches the signed checksums
+	files["/terraform/1.0.2/terraform_1.0.2_linux_amd64.zip"] = createTestZip(t, "terraform", "malicious")
+	_, err = DownloadHashiCorpReleaseE(t, release, createTempDir(t))
+	assert.IsType(t, ChecksumMismatch{}, err)
+
+	// Checksums signed by another key are rejected
+	otherSigner, _ := createTestGpgKey(t)
+	files["/terraform/1.0.2/terraform_1.0.2_SHA256SUMS.sig"] = detachSign(t, otherSigner, []byte(sums))
+	_, err = DownloadHashiCorpReleaseE(t, release, createTempDir(t))
+	assert.IsType(t, InvalidSignature{}, err)
+
+	release.PublicKey = ""
+	_, err = DownloadHashiCorpReleaseE(t, release, createTempDir(t))
+	assert.Equal(t, MissingPublicKey{Product: "terraform"}, err)
+}
+
+func TestVerifyGpgSignatureArmored(t *testing.T) {
+	t.Parallel()
+
+	signer, publicKey := createTestGpgKey(t)
+	dir := createTempDir(t)
+	path := writeTestFile(t, dir, "SHA256SUMS", "abc123  helm.tar.gz\n")
+
+	var signature bytes.Buffer
+	require.NoError(t, openpgp.ArmoredDetachSign(&signature, signer, bytes.NewReader([]byte("abc123  helm.tar.gz\n")), nil))
+	signaturePath := writeTestFile(t, dir, "SHA256SUMS.asc", signature.String())
+
+	assert.NoError(t, VerifyGpgSignatureE(path, signaturePath, publicKey))
+
+	tamperedPath := writeTestFile(t, createTempDir(t), "SHA256SUMS", "def456  helm.tar.gz\n")
+	assert.IsType(t, InvalidSignature{}, VerifyGpgSignatureE(tamperedPath, signaturePath, publicKey))
+}
+
+func createTestGpgKey(t *testing.T) (*openpgp.Entity, string) {
+	entity, err := openpgp.NewEntity("Terratest", "test", "terratest@example.com", nil)
+	require.NoError(t, err)
+
+	var publicKey bytes.Buffer
+	writer, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(writer))
+	require.NoError(t, writer.Close())
+
+	return entity, publicKey.String()
+}
+
+func detachSign(t *testing.T, signer *openpgp.Entity, contents []byte) []byte {
+	var signature bytes.Buffer
+	require.NoError(t, openpgp.DetachSign(&signature, signer, bytes.NewReader(contents), nil))
+	return signature.Bytes()
+}
+
+func createTestZip(t *testing.T, name string, contents string) []byte {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	file, err := writer.Create(name)
+	require.NoError(t, err)
+	_, err = file.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return archive.Bytes()
+}
+
+func sha256Hex(contents []byte) string {
+	checksum := sha256.Sum256(contents)
+	return hex.EncodeToString(checksum[:])
+}
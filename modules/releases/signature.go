@@ -0,0 +1,78 @@
+package releases
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+)
+
+// VerifyGpgSignature verifies that the given detached GPG signature of the file at the given path, either binary (as
+// the .sig files of HashiCorp releases) or ASCII armored, was made by one of the keys in the given ASCII armored
+// public key block. This will fail the test if it was not or if there is an error.
+func VerifyGpgSignature(t testing.TestingT, path string, signaturePath string, armoredPublicKey string) {
+	require.NoError(t, VerifyGpgSignatureE(path, signaturePath, armoredPublicKey))
+}
+
+// VerifyGpgSignatureE verifies that the given detached GPG signature of the file at the given path, either binary (as
+// the .sig files of HashiCorp releases) or ASCII armored, was made by one of the keys in the given ASCII armored
+// public key block, and returns an InvalidSignature error if it was not.
+func VerifyGpgSignatureE(path string, signaturePath string, armoredPublicKey string) error {
+	keyRing, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredPublicKey))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	signature, err := ioutil.ReadFile(signaturePath)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN PGP SIGNATURE-----")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyRing, file, bytes.NewReader(signature))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyRing, file, bytes.NewReader(signature))
+	}
+	if err != nil {
+		return InvalidSignature{Path: path, SignaturePath: signaturePath, Reason: err.Error()}
+	}
+	return nil
+}
+
+// VerifyCosignSignature verifies the given cosign signature of the file at the given path with the given public key, by
+// running cosign verify-blob. The cosign binary must be in the PATH. This will fail the test if the signature is not
+// valid or if there is an error.
+func VerifyCosignSignature(t testing.TestingT, path string, signaturePath string, publicKeyPath string) {
+	require.NoError(t, VerifyCosignSignatureE(t, path, signaturePath, publicKeyPath))
+}
+
+// VerifyCosignSignatureE verifies the given cosign signature of the file at the given path with the given public key,
+// by running cosign verify-blob. The cosign binary must be in the PATH. Returns an InvalidSignature error if the
+// signature is not valid.
+func VerifyCosignSignatureE(t testing.TestingT, path string, signaturePath string, publicKeyPath string) error {
+	output, err := shell.RunCommandAndGetOutputE(t, shell.Command{
+		Command: "cosign",
+		Args:    []string{"verify-blob", "--key", publicKeyPath, "--signature", signaturePath, path},
+		Logger:  logger.Discard,
+	})
+	if err != nil {
+		if _, exitCodeErr := shell.GetExitCodeForRunCommandError(err); exitCodeErr == nil {
+			// cosign ran and rejected the signature
+			return InvalidSignature{Path: path, SignaturePath: signaturePath, Reason: output}
+		}
+		return err
+	}
+	return nil
+}
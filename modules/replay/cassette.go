@@ -0,0 +1,147 @@
+package replay
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+// Cassette is a file of recorded calls to cloud APIs.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+
+	path  string
+	mode  Mode
+	mutex sync.Mutex
+	used  []bool
+}
+
+// Interaction is a recorded call: a request and the response to it.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a sanitized request of a recorded call.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Url    string `json:"url"`
+	Body   Body   `json:"body"`
+}
+
+// RecordedResponse is a sanitized response of a recorded call.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       Body        `json:"body"`
+}
+
+// Body is the body of a recorded request or response. It is stored as text if it is valid UTF-8, so that cassettes
+// can be reviewed, and base64 encoded otherwise.
+type Body []byte
+
+type encodedBody struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+// MarshalJSON encodes the body as text if it is valid UTF-8 and as base64 otherwise.
+func (body Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(body) {
+		return json.Marshal(encodedBody{Text: string(body)})
+	}
+	return json.Marshal(encodedBody{Base64: base64.StdEncoding.EncodeToString(body)})
+}
+
+// UnmarshalJSON decodes a body encoded by MarshalJSON.
+func (body *Body) UnmarshalJSON(data []byte) error {
+	var encoded encodedBody
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	if encoded.Base64 == "" {
+		*body = Body(encoded.Text)
+		return nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*body = decoded
+	return nil
+}
+
+// LoadCassetteE loads the cassette at the given path.
+func LoadCassetteE(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{path: path}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, err
+	}
+	cassette.used = make([]bool, len(cassette.Interactions))
+	return cassette, nil
+}
+
+// SaveE saves the cassette to its path, creating its directory if needed.
+func (cassette *Cassette) SaveE() error {
+	cassette.mutex.Lock()
+	defer cassette.mutex.Unlock()
+
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cassette.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cassette.path, data, 0644)
+}
+
+// record adds the given interaction to the cassette.
+func (cassette *Cassette) record(interaction Interaction) {
+	cassette.mutex.Lock()
+	defer cassette.mutex.Unlock()
+
+	cassette.Interactions = append(cassette.Interactions, interaction)
+}
+
+// find returns the first interaction that has not been replayed yet with the given request, and marks it as replayed,
+// so that repeated calls, e.g. while polling, get their responses in the order they were recorded. Once all of them
+// have been replayed, the last one is returned again.
+func (cassette *Cassette) find(request RecordedRequest) (*Interaction, bool) {
+	cassette.mutex.Lock()
+	defer cassette.mutex.Unlock()
+
+	last := -1
+	for i, interaction := range cassette.Interactions {
+		if !matches(interaction.Request, request) {
+			continue
+		}
+		if !cassette.used[i] {
+			cassette.used[i] = true
+			return &cassette.Interactions[i], true
+		}
+		last = i
+	}
+
+	if last < 0 {
+		return nil, false
+	}
+	return &cassette.Interactions[last], true
+}
+
+func matches(recorded RecordedRequest, request RecordedRequest) bool {
+	return recorded.Method == request.Method && recorded.Url == request.Url && string(recorded.Body) == string(request.Body)
+}
@@ -0,0 +1,168 @@
+// Package replay allows to record the calls the modules make to cloud APIs and to replay them later, so that tests that
+// mostly read infrastructure can be re-run against the recorded responses while iterating on their assertions, without
+// calling the real APIs every run.
+//
+// A test records or replays its calls in a cassette file:
+//
+//	func TestVpc(t *testing.T) {
+//	    replay.Start(t, "testdata/cassettes/TestVpc.json")
+//	    ...
+//	}
+//
+// What Start does depends on the TERRATEST_REPLAY_MODE environment variable: with "record", the calls are made for real
+// and saved to the cassette when the test finishes; with "replay", the responses are served from the cassette and no
+// call reaches the real APIs; otherwise, Start does nothing. Recorded interactions are sanitized (see AddSanitizer) so
+// that the cassettes can be committed. Calls that create resources usually include unique values, such as random names
+// or client tokens, in which case they can't be replayed.
+//
+// The AWS clients and the GCP REST clients of the modules support replay. Only one cassette can be active at a time, so
+// tests that use replay must not run in parallel.
+package replay
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// ModeEnvVar is the environment variable that sets the mode of replay.
+const ModeEnvVar = "TERRATEST_REPLAY_MODE"
+
+// Mode is what replay does with the calls to cloud APIs.
+type Mode string
+
+const (
+	// ModeOff lets the calls through untouched.
+	ModeOff Mode = ""
+
+	// ModeRecord lets the calls through and records them in the active cassette.
+	ModeRecord Mode = "record"
+
+	// ModeReplay serves the calls from the active cassette.
+	ModeReplay Mode = "replay"
+)
+
+var (
+	mutex  sync.Mutex
+	active *Cassette
+)
+
+// GetMode returns the mode of replay set by the TERRATEST_REPLAY_MODE environment variable.
+func GetMode() Mode {
+	return Mode(os.Getenv(ModeEnvVar))
+}
+
+// IsReplaying returns true if calls are currently served from a cassette. Clients use this to skip looking up
+// credentials, which replayed calls don't need.
+func IsReplaying() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return active != nil && active.mode == ModeReplay
+}
+
+// Start starts recording or replaying calls to cloud APIs in the cassette at the given path, depending on the
+// TERRATEST_REPLAY_MODE environment variable, until the test finishes. This will fail the test if there is an error.
+func Start(t testing.TestingT, path string) {
+	if err := StartE(t, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// StartE starts recording or replaying calls to cloud APIs in the cassette at the given path, depending on the
+// TERRATEST_REPLAY_MODE environment variable, until the test finishes, if t supports Cleanup, as testing.T does.
+// Otherwise, call StopE when done.
+func StartE(t testing.TestingT, path string) error {
+	mode := GetMode()
+
+	var cassette *Cassette
+	switch mode {
+	case ModeOff:
+		return nil
+	case ModeRecord:
+		cassette = &Cassette{path: path, mode: mode}
+	case ModeReplay:
+		loaded, err := LoadCassetteE(path)
+		if err != nil {
+			return err
+		}
+		cassette = loaded
+		cassette.mode = mode
+	default:
+		return UnknownMode{Mode: mode}
+	}
+
+	mutex.Lock()
+	if active != nil {
+		mutex.Unlock()
+		return CassetteAlreadyActive{Path: active.path}
+	}
+	active = cassette
+	mutex.Unlock()
+
+	logger.Logf(t, "Replay %s mode: using cassette %s", mode, path)
+
+	cleanupT, ok := t.(interface{ Cleanup(func()) })
+	if !ok {
+		logger.Logf(t, "WARNING: Cannot register cassette %s for cleanup because %T does not support Cleanup", path, t)
+		return nil
+	}
+	cleanupT.Cleanup(func() {
+		if err := StopE(); err != nil {
+			t.Errorf("Failed to save cassette %s: %s", path, err)
+		}
+	})
+	return nil
+}
+
+// StopE stops recording or replaying calls and, when recording, saves the recorded calls to the cassette.
+func StopE() error {
+	mutex.Lock()
+	cassette := active
+	active = nil
+	mutex.Unlock()
+
+	if cassette == nil || cassette.mode != ModeRecord {
+		return nil
+	}
+	return cassette.SaveE()
+}
+
+func getActiveCassette() *Cassette {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return active
+}
+
+// UnknownMode is returned when TERRATEST_REPLAY_MODE is set to an unknown mode.
+type UnknownMode struct {
+	Mode Mode
+}
+
+func (err UnknownMode) Error() string {
+	return fmt.Sprintf("Unknown replay mode %q in %s: expected %q or %q", err.Mode, ModeEnvVar, ModeRecord, ModeReplay)
+}
+
+// CassetteAlreadyActive is returned when starting a cassette while another one is active.
+type CassetteAlreadyActive struct {
+	Path string
+}
+
+func (err CassetteAlreadyActive) Error() string {
+	return fmt.Sprintf("Cassette %s is already active: tests that use replay must not run in parallel", err.Path)
+}
+
+// InteractionNotFound is returned when replaying a call that is not in the cassette.
+type InteractionNotFound struct {
+	Path   string
+	Method string
+	Url    string
+}
+
+func (err InteractionNotFound) Error() string {
+	return fmt.Sprintf("No recorded response for %s %s in cassette %s: record it again with %s=%s", err.Method, err.Url, err.Path, ModeEnvVar, ModeRecord)
+}
@@ -0,0 +1,65 @@
+package replay
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+)
+
+// sanitizer replaces the matches of a pattern in recorded interactions.
+type sanitizer struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// sensitiveHeaders are the headers that are never recorded, as they carry credentials or change on every call.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Amz-Security-Token",
+	"X-Goog-Api-Key",
+}
+
+var (
+	sanitizersMutex sync.Mutex
+	sanitizers      = []sanitizer{
+		// AWS access key IDs and the credentials returned by STS
+		{regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`), "${1}XXXXXXXXXXXXXXXX"},
+		{regexp.MustCompile(`<SecretAccessKey>[^<]*</SecretAccessKey>`), "<SecretAccessKey>REDACTED</SecretAccessKey>"},
+		{regexp.MustCompile(`<SessionToken>[^<]*</SessionToken>`), "<SessionToken>REDACTED</SessionToken>"},
+		// OAuth2 tokens, e.g. returned by the GCP token endpoints
+		{regexp.MustCompile(`"(access_token|refresh_token|id_token)"\s*:\s*"[^"]*"`), `"$1":"REDACTED"`},
+	}
+)
+
+// AddSanitizer makes the matches of the given pattern be replaced with the given replacement, which can refer to the
+// groups of the pattern as in regexp.Regexp.ReplaceAllString, in the URLs and bodies of the requests and responses
+// recorded from now on, e.g. to keep account IDs out of the cassettes. Requests are sanitized the same way before
+// being matched with the recorded ones when replaying.
+func AddSanitizer(pattern *regexp.Regexp, replacement string) {
+	sanitizersMutex.Lock()
+	defer sanitizersMutex.Unlock()
+
+	sanitizers = append(sanitizers, sanitizer{pattern: pattern, replacement: replacement})
+}
+
+// sanitize applies all the sanitizers to the given text.
+func sanitize(text string) string {
+	sanitizersMutex.Lock()
+	defer sanitizersMutex.Unlock()
+
+	for _, sanitizer := range sanitizers {
+		text = sanitizer.pattern.ReplaceAllString(text, sanitizer.replacement)
+	}
+	return text
+}
+
+// sanitizeHeader returns a copy of the given header without the sensitive headers.
+func sanitizeHeader(header http.Header) http.Header {
+	sanitized := header.Clone()
+	for _, name := range sensitiveHeaders {
+		sanitized.Del(name)
+	}
+	return sanitized
+}
@@ -0,0 +1,97 @@
+package replay
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// transport is an http.RoundTripper that records or replays requests in the active cassette.
+type transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport returns an http.RoundTripper that records the requests it sends with the given base RoundTripper (or
+// http.DefaultTransport if nil) in the active cassette when recording, serves them from the active cassette when
+// replaying, and just sends them otherwise.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cassette := getActiveCassette()
+	if cassette == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	requestBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	recordedRequest := RecordedRequest{
+		Method: req.Method,
+		Url:    sanitize(req.URL.String()),
+		Body:   Body(sanitize(string(requestBody))),
+	}
+
+	if cassette.mode == ModeReplay {
+		interaction, found := cassette.find(recordedRequest)
+		if !found {
+			return nil, InteractionNotFound{Path: cassette.path, Method: recordedRequest.Method, Url: recordedRequest.Url}
+		}
+		return newResponse(req, interaction.Response), nil
+	}
+
+	if req.Body != nil {
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	cassette.record(Interaction{
+		Request: recordedRequest,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     sanitizeHeader(resp.Header),
+			Body:       Body(sanitize(string(responseBody))),
+		},
+	})
+	return resp, nil
+}
+
+// readBody reads and closes the given request or response body, which may be nil.
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// newResponse returns a response to the given request with the given recorded response.
+func newResponse(req *http.Request, recorded RecordedResponse) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(recorded.StatusCode),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+}
@@ -8,9 +8,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/gruntwork-io/terratest/modules/clock"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
-	"golang.org/x/net/context"
 )
 
 // Either contains a result and potentially an error.
@@ -32,8 +32,8 @@ func DoWithTimeout(t testing.TestingT, actionDescription string, timeout time.Du
 // DoWithTimeoutE runs the specified action and waits up to the specified timeout for it to complete. Return the output of the action if
 // it completes on time or an error otherwise.
 func DoWithTimeoutE(t testing.TestingT, actionDescription string, timeout time.Duration, action func() (string, error)) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	// Measure the timeout with the default clock, so that it can be faked in tests
+	deadline := clock.Default().After(timeout)
 
 	resultChannel := make(chan Either, 1)
 
@@ -45,7 +45,7 @@ func DoWithTimeoutE(t testing.TestingT, actionDescription string, timeout time.D
 	select {
 	case either := <-resultChannel:
 		return either.Result, either.Error
-	case <-ctx.Done():
+	case <-deadline:
 		return "", TimeoutExceeded{Description: actionDescription, Timeout: timeout}
 	}
 }
@@ -101,7 +101,7 @@ func DoWithRetryInterfaceE(t testing.TestingT, actionDescription string, maxRetr
 		}
 
 		logger.Logf(t, "%s returned an error: %s. Sleeping for %s and will try again.", actionDescription, err.Error(), sleepBetweenRetries)
-		time.Sleep(sleepBetweenRetries)
+		clock.Default().Sleep(sleepBetweenRetries)
 	}
 
 	return output, MaxRetriesExceeded{Description: actionDescription, MaxRetries: maxRetries}
@@ -174,7 +174,7 @@ func DoInBackgroundUntilStopped(t testing.TestingT, actionDescription string, sl
 			logger.Logf(t, "Sleeping for %s before repeating action '%s'", sleepBetweenRepeats, actionDescription)
 
 			select {
-			case <-time.After(sleepBetweenRepeats):
+			case <-clock.Default().After(sleepBetweenRepeats):
 				// Nothing to do, just allow the loop to continue
 			case <-stop:
 				logger.Logf(t, "Received stop signal for action '%s'.", actionDescription)
@@ -216,3 +216,8 @@ type FatalError struct {
 func (err FatalError) Error() string {
 	return fmt.Sprintf("FatalError{Underlying: %v}", err.Underlying)
 }
+
+// Unwrap returns the underlying error, so that errors.Is and errors.As see through the FatalError.
+func (err FatalError) Unwrap() error {
+	return err.Underlying
+}
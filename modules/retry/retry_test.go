@@ -1,10 +1,12 @@
 package retry
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/gruntwork-io/terratest/modules/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -261,3 +263,43 @@ type ErrorCounter int
 func (count ErrorCounter) Error() string {
 	return fmt.Sprintf("%d", int(count))
 }
+
+func TestFatalErrorUnwraps(t *testing.T) {
+	t.Parallel()
+
+	underlying := fmt.Errorf("underlying error")
+	_, err := DoWithRetryE(t, "Return a fatal error", 3, 0, func() (string, error) {
+		return "", FatalError{Underlying: underlying}
+	})
+	assert.True(t, errors.Is(err, underlying))
+}
+
+// This test replaces the global default clock, so it must not run in parallel.
+func TestDoWithRetrySleepsWithDefaultClock(t *testing.T) {
+	start := time.Now()
+	fakeClock := clock.NewFake(start)
+	restore := clock.SetDefault(fakeClock)
+	defer restore()
+
+	attempts := 0
+	result := make(chan error, 1)
+	go func() {
+		_, err := DoWithRetryE(t, t.Name(), 5, time.Hour, func() (string, error) {
+			attempts++
+			if attempts < 3 {
+				return "", errors.New("not yet")
+			}
+			return "done", nil
+		})
+		result <- err
+	}()
+
+	for i := 0; i < 2; i++ {
+		fakeClock.BlockUntil(1)
+		fakeClock.Advance(time.Hour)
+	}
+
+	assert.NoError(t, <-result)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 2*time.Hour, fakeClock.Now().Sub(start))
+}
@@ -0,0 +1,101 @@
+package runinfo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ciInfo is the metadata about the CI job running the tests, as found in the environment variables of the CI system.
+type ciInfo struct {
+	provider  string
+	jobID     string
+	jobURL    string
+	gitSha    string
+	gitBranch string
+
+	pullRequest string
+}
+
+// detectCI detects the CI system the tests are running in from the given environment lookup and returns the metadata
+// it exposes. Returns an empty ciInfo when not running in a known CI system.
+func detectCI(getenv func(string) string) ciInfo {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		branch := getenv("GITHUB_HEAD_REF")
+		if branch == "" {
+			branch = getenv("GITHUB_REF_NAME")
+		}
+		return ciInfo{
+			provider:  "github-actions",
+			jobID:     getenv("GITHUB_RUN_ID"),
+			jobURL:    fmt.Sprintf("%s/%s/actions/runs/%s", getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"), getenv("GITHUB_RUN_ID")),
+			gitSha:    getenv("GITHUB_SHA"),
+			gitBranch: branch,
+
+			pullRequest: githubPullRequest(getenv("GITHUB_REF")),
+		}
+	case getenv("CIRCLECI") == "true":
+		return ciInfo{
+			provider:  "circleci",
+			jobID:     getenv("CIRCLE_BUILD_NUM"),
+			jobURL:    getenv("CIRCLE_BUILD_URL"),
+			gitSha:    getenv("CIRCLE_SHA1"),
+			gitBranch: getenv("CIRCLE_BRANCH"),
+
+			// CircleCI only exposes the URL of the pull request, which ends with its number
+			pullRequest: lastPathSegment(getenv("CIRCLE_PULL_REQUEST")),
+		}
+	case getenv("GITLAB_CI") == "true":
+		return ciInfo{
+			provider:  "gitlab",
+			jobID:     getenv("CI_JOB_ID"),
+			jobURL:    getenv("CI_JOB_URL"),
+			gitSha:    getenv("CI_COMMIT_SHA"),
+			gitBranch: getenv("CI_COMMIT_REF_NAME"),
+
+			pullRequest: getenv("CI_MERGE_REQUEST_IID"),
+		}
+	case getenv("BUILDKITE") == "true":
+		return ciInfo{
+			provider:  "buildkite",
+			jobID:     getenv("BUILDKITE_JOB_ID"),
+			jobURL:    getenv("BUILDKITE_BUILD_URL"),
+			gitSha:    getenv("BUILDKITE_COMMIT"),
+			gitBranch: getenv("BUILDKITE_BRANCH"),
+
+			// Buildkite sets this to "false" for builds that are not for a pull request
+			pullRequest: strings.TrimPrefix(getenv("BUILDKITE_PULL_REQUEST"), "false"),
+		}
+	case getenv("JENKINS_URL") != "":
+		return ciInfo{
+			provider:  "jenkins",
+			jobID:     getenv("BUILD_ID"),
+			jobURL:    getenv("BUILD_URL"),
+			gitSha:    getenv("GIT_COMMIT"),
+			gitBranch: getenv("GIT_BRANCH"),
+
+			// Set by multibranch pipelines for pull request builds
+			pullRequest: getenv("CHANGE_ID"),
+		}
+	default:
+		return ciInfo{}
+	}
+}
+
+// githubPullRequestRefRegexp matches the refs GitHub Actions checks out for pull requests, e.g. refs/pull/42/merge.
+var githubPullRequestRefRegexp = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+// githubPullRequest returns the number of the pull request of the given GitHub ref, or "" if it is not a pull request.
+func githubPullRequest(ref string) string {
+	matches := githubPullRequestRefRegexp.FindStringSubmatch(ref)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
+// lastPathSegment returns the part of the given URL after its last slash.
+func lastPathSegment(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
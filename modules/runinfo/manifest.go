@@ -0,0 +1,71 @@
+package runinfo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Artifact is a resource or file created by the test run, e.g. an AMI, a bucket or a log file.
+type Artifact struct {
+	Name     string `json:"name"`
+	Location string `json:"location"` // An ID, ARN, URL or path that identifies the artifact
+}
+
+// Manifest is the content of the manifest file written by WriteManifest.
+type Manifest struct {
+	RunInfo
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+var (
+	artifacts      = []Artifact{}
+	artifactsMutex sync.Mutex
+)
+
+// RecordArtifact records that the current test run created the given artifact, so that it is listed in the manifest.
+func RecordArtifact(t testing.TestingT, name string, location string) {
+	artifactsMutex.Lock()
+	defer artifactsMutex.Unlock()
+
+	logger.Logf(t, "Recording artifact %s at %s", name, location)
+	artifacts = append(artifacts, Artifact{Name: name, Location: location})
+}
+
+// GetManifest returns the metadata of the current test run along with all the artifacts recorded so far.
+func GetManifest() Manifest {
+	artifactsMutex.Lock()
+	defer artifactsMutex.Unlock()
+
+	recorded := make([]Artifact, len(artifacts))
+	copy(recorded, artifacts)
+	return Manifest{RunInfo: *Get(), Artifacts: recorded}
+}
+
+// WriteManifest writes the metadata of the current test run and the artifacts recorded so far as JSON to the given
+// path. This will fail the test if there is an error.
+func WriteManifest(t testing.TestingT, path string) {
+	require.NoError(t, WriteManifestE(t, path))
+}
+
+// WriteManifestE writes the metadata of the current test run and the artifacts recorded so far as JSON to the given
+// path. Call it again after recording more artifacts to update the file.
+func WriteManifestE(t testing.TestingT, path string) error {
+	data, err := json.MarshalIndent(GetManifest(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Writing run manifest to %s", path)
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,165 @@
+// Package runinfo collects metadata about the current test run (a run ID, the git commit being tested and the CI job
+// running the tests) so that every cloud resource, log line and artifact can be tied back to the exact CI job that
+// created it. The metadata is collected once per test binary, stamped on all the log output of the logger package and
+// passed to every Terraform command as TF_VAR_terratest_* environment variables, which modules can declare as
+// variables to tag their resources.
+package runinfo
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/git"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+// RunIDEnvVar is the environment variable that can be set to use a specific run ID, e.g. to share one run ID across the
+// test binaries of several packages. If it is not set, a random run ID is generated.
+const RunIDEnvVar = "TERRATEST_RUN_ID"
+
+// PullRequestEnvVar is the environment variable that can be set to the number of the pull request (or merge request)
+// under test, for CI systems or workflows that don't expose it.
+const PullRequestEnvVar = "TERRATEST_PULL_REQUEST"
+
+// RunInfo is the metadata of the current test run.
+type RunInfo struct {
+	RunID      string    `json:"run_id"`
+	StartedAt  time.Time `json:"started_at"`
+	GitSha     string    `json:"git_sha,omitempty"`
+	GitBranch  string    `json:"git_branch,omitempty"`
+	CIProvider string    `json:"ci_provider,omitempty"` // e.g. "github-actions" or "circleci"; empty when not running in CI
+	CIJobID    string    `json:"ci_job_id,omitempty"`
+	CIJobURL   string    `json:"ci_job_url,omitempty"`
+
+	PullRequest string `json:"pull_request,omitempty"` // The number of the pull request under test; empty when not testing one
+}
+
+var (
+	current     *RunInfo
+	currentOnce sync.Once
+)
+
+// Get returns the metadata of the current test run, collecting it on the first call. From then on, the run ID is also
+// included in every line logged by the logger package.
+func Get() *RunInfo {
+	currentOnce.Do(func() {
+		current = collect(os.Getenv, getGitInfo)
+		logger.SetRunID(current.RunID)
+	})
+	return current
+}
+
+// Tags returns the run metadata as tags (e.g. "terratest-run-id"), which can be put on AWS and Azure resources. Empty
+// values are left out.
+func (info *RunInfo) Tags() map[string]string {
+	tags := map[string]string{}
+	addIfSet(tags, "terratest-run-id", info.RunID)
+	addIfSet(tags, "terratest-git-sha", info.GitSha)
+	addIfSet(tags, "terratest-git-branch", info.GitBranch)
+	addIfSet(tags, "terratest-ci-provider", info.CIProvider)
+	addIfSet(tags, "terratest-ci-job-id", info.CIJobID)
+	addIfSet(tags, "terratest-ci-job-url", info.CIJobURL)
+	addIfSet(tags, "terratest-pull-request", info.PullRequest)
+	return tags
+}
+
+// Labels returns the run metadata as GCP labels, whose keys and values may only contain lowercase letters, digits,
+// underscores and dashes, and are at most 63 characters long. The CI job URL can't be represented and is left out.
+func (info *RunInfo) Labels() map[string]string {
+	labels := map[string]string{}
+	for key, value := range info.Tags() {
+		if key == "terratest-ci-job-url" {
+			continue
+		}
+		labels[key] = toLabelValue(value)
+	}
+	return labels
+}
+
+// TerraformEnvVars returns the run metadata as TF_VAR_ environment variables: terratest_run_id, terratest_git_sha,
+// terratest_git_branch, terratest_ci_job_url and terratest_tags (a map of all the Tags). Terraform ignores environment
+// variables for undeclared variables, so modules opt in by declaring the ones they want.
+func (info *RunInfo) TerraformEnvVars() map[string]string {
+	envVars := map[string]string{
+		"TF_VAR_terratest_run_id":     info.RunID,
+		"TF_VAR_terratest_git_sha":    info.GitSha,
+		"TF_VAR_terratest_git_branch": info.GitBranch,
+		"TF_VAR_terratest_ci_job_url": info.CIJobURL,
+	}
+
+	// JSON objects are valid HCL, which Terraform uses to parse complex variable values
+	if tags, err := json.Marshal(info.Tags()); err == nil {
+		envVars["TF_VAR_terratest_tags"] = string(tags)
+	}
+
+	return envVars
+}
+
+// collect builds the run metadata from the given environment lookup and git information, preferring the commit and
+// branch reported by the CI system, as CI checkouts are often in a detached state.
+func collect(getenv func(string) string, gitInfo func() (string, string)) *RunInfo {
+	info := &RunInfo{
+		RunID:     getenv(RunIDEnvVar),
+		StartedAt: time.Now().UTC(),
+	}
+	if info.RunID == "" {
+		info.RunID = strings.ToLower(random.UniqueId())
+	}
+
+	ci := detectCI(getenv)
+	info.CIProvider = ci.provider
+	info.CIJobID = ci.jobID
+	info.CIJobURL = ci.jobURL
+	info.GitSha = ci.gitSha
+	info.GitBranch = ci.gitBranch
+
+	info.PullRequest = getenv(PullRequestEnvVar)
+	if info.PullRequest == "" {
+		info.PullRequest = ci.pullRequest
+	}
+
+	if info.GitSha == "" || info.GitBranch == "" {
+		sha, branch := gitInfo()
+		if info.GitSha == "" {
+			info.GitSha = sha
+		}
+		if info.GitBranch == "" {
+			info.GitBranch = branch
+		}
+	}
+
+	return info
+}
+
+// getGitInfo returns the SHA and branch of the git checkout in the working directory, or empty strings if they can't
+// be determined, e.g. because the tests don't run in a git checkout.
+func getGitInfo() (string, string) {
+	// The metadata is collected outside of any particular test, and the git helpers don't use their TestingT
+	sha, _ := git.GetCurrentCommitShaE(nil)
+	branch, _ := git.GetCurrentBranchNameE(nil)
+	return sha, branch
+}
+
+// addIfSet adds the given key and value to the given map if the value is not empty.
+func addIfSet(values map[string]string, key string, value string) {
+	if value != "" {
+		values[key] = value
+	}
+}
+
+// invalidLabelCharacters matches the characters that are not allowed in GCP label values.
+var invalidLabelCharacters = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// toLabelValue converts the given value into a valid GCP label value.
+func toLabelValue(value string) string {
+	value = invalidLabelCharacters.ReplaceAllString(strings.ToLower(value), "_")
+	if len(value) > 63 {
+		value = value[:63]
+	}
+	return value
+}
@@ -0,0 +1,151 @@
+package runinfo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeEnv(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func fakeGitInfo() (string, string) {
+	return "0123456789abcdef", "local-branch"
+}
+
+func TestCollectOutsideCI(t *testing.T) {
+	t.Parallel()
+
+	info := collect(fakeEnv(map[string]string{}), fakeGitInfo)
+
+	assert.Regexp(t, "^[a-z0-9]{6}$", info.RunID)
+	assert.Equal(t, "0123456789abcdef", info.GitSha)
+	assert.Equal(t, "local-branch", info.GitBranch)
+	assert.Empty(t, info.CIProvider)
+	assert.Empty(t, info.CIJobURL)
+}
+
+func TestCollectInGitHubActions(t *testing.T) {
+	t.Parallel()
+
+	info := collect(fakeEnv(map[string]string{
+		RunIDEnvVar:         "shared-run",
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_RUN_ID":     "42",
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_REPOSITORY": "gruntwork-io/terratest",
+		"GITHUB_SHA":        "fedcba9876543210",
+		"GITHUB_REF_NAME":   "main",
+	}), fakeGitInfo)
+
+	assert.Equal(t, "shared-run", info.RunID)
+	assert.Equal(t, "github-actions", info.CIProvider)
+	assert.Equal(t, "42", info.CIJobID)
+	assert.Equal(t, "https://github.com/gruntwork-io/terratest/actions/runs/42", info.CIJobURL)
+	assert.Equal(t, "fedcba9876543210", info.GitSha)
+	assert.Equal(t, "main", info.GitBranch)
+}
+
+func TestCollectFallsBackToGitForMissingCIValues(t *testing.T) {
+	t.Parallel()
+
+	info := collect(fakeEnv(map[string]string{
+		"CIRCLECI":         "true",
+		"CIRCLE_BUILD_NUM": "7",
+		"CIRCLE_BUILD_URL": "https://circleci.com/gh/gruntwork-io/terratest/7",
+		"CIRCLE_SHA1":      "fedcba9876543210",
+	}), fakeGitInfo)
+
+	assert.Equal(t, "circleci", info.CIProvider)
+	assert.Equal(t, "fedcba9876543210", info.GitSha)
+	assert.Equal(t, "local-branch", info.GitBranch)
+}
+
+func TestCollectPullRequest(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"github pull request", map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/pull/42/merge"}, "42"},
+		{"github push", map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/main"}, ""},
+		{"circleci", map[string]string{"CIRCLECI": "true", "CIRCLE_PULL_REQUEST": "https://github.com/gruntwork-io/terratest/pull/7"}, "7"},
+		{"gitlab", map[string]string{"GITLAB_CI": "true", "CI_MERGE_REQUEST_IID": "3"}, "3"},
+		{"buildkite push", map[string]string{"BUILDKITE": "true", "BUILDKITE_PULL_REQUEST": "false"}, ""},
+		{"override", map[string]string{PullRequestEnvVar: "9", "GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/pull/42/merge"}, "9"},
+	}
+
+	for _, testCase := range testCases {
+		// capture range variable so that it doesn't change as the loop advances
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			info := collect(fakeEnv(testCase.env), fakeGitInfo)
+			assert.Equal(t, testCase.expected, info.PullRequest)
+		})
+	}
+}
+
+func TestTagsLabelsAndTerraformEnvVars(t *testing.T) {
+	t.Parallel()
+
+	info := &RunInfo{
+		RunID:     "abc123",
+		GitSha:    "0123456789abcdef",
+		GitBranch: "Feature/My-Branch",
+		CIJobURL:  "https://ci.example.com/jobs/1",
+	}
+
+	assert.Equal(t, map[string]string{
+		"terratest-run-id":     "abc123",
+		"terratest-git-sha":    "0123456789abcdef",
+		"terratest-git-branch": "Feature/My-Branch",
+		"terratest-ci-job-url": "https://ci.example.com/jobs/1",
+	}, info.Tags())
+
+	assert.Equal(t, map[string]string{
+		"terratest-run-id":     "abc123",
+		"terratest-git-sha":    "0123456789abcdef",
+		"terratest-git-branch": "feature_my-branch",
+	}, info.Labels())
+
+	envVars := info.TerraformEnvVars()
+	assert.Equal(t, "abc123", envVars["TF_VAR_terratest_run_id"])
+	assert.Equal(t, "https://ci.example.com/jobs/1", envVars["TF_VAR_terratest_ci_job_url"])
+
+	tags := map[string]string{}
+	require.NoError(t, json.Unmarshal([]byte(envVars["TF_VAR_terratest_tags"]), &tags))
+	assert.Equal(t, info.Tags(), tags)
+}
+
+func TestWriteManifest(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "runinfo")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	RecordArtifact(t, "test-ami", "ami-0123456789abcdef0")
+
+	path := filepath.Join(tmpDir, "nested", "manifest.json")
+	WriteManifest(t, path)
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, Get().RunID, manifest.RunID)
+	assert.Contains(t, manifest.Artifacts, Artifact{Name: "test-ami", Location: "ami-0123456789abcdef0"})
+}
@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StepStatus is the outcome of a step in a scenario.
+type StepStatus string
+
+const (
+	Passed  StepStatus = "PASSED"
+	Failed  StepStatus = "FAILED"
+	Skipped StepStatus = "SKIPPED" // The SKIP_<step> environment variable was set
+	NotRun  StepStatus = "NOT RUN" // An earlier step failed
+)
+
+// StepResult is the outcome of a single step in a scenario.
+type StepResult struct {
+	Name     string
+	Kind     StepKind
+	Status   StepStatus
+	Attempts int
+	Duration time.Duration
+	Err      error // The error of the last attempt, if the step failed
+}
+
+// Report is the outcome of all the steps of a scenario, in the order they were declared.
+type Report struct {
+	Scenario  string
+	StartedAt time.Time
+	Duration  time.Duration
+	Steps     []StepResult
+}
+
+// Failed returns true if any step of the scenario failed.
+func (report *Report) Failed() bool {
+	for _, step := range report.Steps {
+		if step.Status == Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// Step returns the result of the step with the given name, or nil if the scenario has no such step.
+func (report *Report) Step(name string) *StepResult {
+	for i := range report.Steps {
+		if report.Steps[i].Name == name {
+			return &report.Steps[i]
+		}
+	}
+	return nil
+}
+
+// String renders the report as a table with one row per step, followed by the total duration.
+func (report *Report) String() string {
+	nameWidth := len("STEP")
+	for _, step := range report.Steps {
+		if len(step.Name) > nameWidth {
+			nameWidth = len(step.Name)
+		}
+	}
+
+	var builder strings.Builder
+	rowFormat := fmt.Sprintf("%%-%ds  %%-9s  %%-7s  %%8s  %%10s  %%s\n", nameWidth)
+	fmt.Fprintf(&builder, rowFormat, "STEP", "KIND", "STATUS", "ATTEMPTS", "DURATION", "ERROR")
+	for _, step := range report.Steps {
+		errMessage := ""
+		if step.Err != nil {
+			errMessage = strings.SplitN(step.Err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(&builder, rowFormat, step.Name, step.Kind, step.Status, fmt.Sprint(step.Attempts), step.Duration.Round(time.Millisecond), errMessage)
+	}
+	fmt.Fprintf(&builder, "Total: %s", report.Duration.Round(time.Millisecond))
+
+	return builder.String()
+}
@@ -0,0 +1,189 @@
+// Package scenario lets a test declare an ordered infrastructure workflow (e.g. provision, verify, mutate, verify,
+// destroy) as a list of named steps, with per-step retries, SKIP_<step> environment variables to skip steps during
+// local development, timing capture and a report of the outcome of every step at the end.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+// SkipStepEnvVarPrefix is the prefix of the environment variables that skip a step, e.g. SKIP_destroy. It is the same
+// as the one used by test_structure.RunTestStage, so existing local development workflows keep working.
+const SkipStepEnvVarPrefix = "SKIP_"
+
+// StepKind is the kind of a step in a scenario.
+type StepKind string
+
+const (
+	// Provision steps create infrastructure, e.g. terraform.InitAndApply.
+	Provision StepKind = "provision"
+	// Verify steps check the infrastructure behaves as expected.
+	Verify StepKind = "verify"
+	// Mutate steps change the infrastructure, e.g. an upgrade, a config change or an injected failure.
+	Mutate StepKind = "mutate"
+	// Destroy steps tear down infrastructure. Unlike the other kinds, they run even if an earlier step failed.
+	Destroy StepKind = "destroy"
+)
+
+// Step is a single named step of a scenario.
+type Step struct {
+	Name string
+	Kind StepKind
+	Run  func() error
+
+	// MaxRetries is the number of times to retry Run if it returns an error. Zero means Run is only tried once.
+	MaxRetries          int
+	SleepBetweenRetries time.Duration
+
+	// SkipEnvVar is the environment variable that skips this step when set. Defaults to SKIP_<Name>.
+	SkipEnvVar string
+}
+
+// skipEnvVar returns the name of the environment variable that skips this step.
+func (step Step) skipEnvVar() string {
+	if step.SkipEnvVar != "" {
+		return step.SkipEnvVar
+	}
+	return SkipStepEnvVarPrefix + step.Name
+}
+
+// Scenario is an ordered list of steps. Steps run in the order they were added. Once a step fails, the remaining
+// steps are not run, except for Destroy steps, so that the infrastructure is still torn down.
+type Scenario struct {
+	Name  string
+	Steps []Step
+}
+
+// New creates an empty scenario with the given name.
+func New(name string) *Scenario {
+	return &Scenario{Name: name}
+}
+
+// AddStep adds the given step to the scenario.
+func (scenario *Scenario) AddStep(step Step) *Scenario {
+	scenario.Steps = append(scenario.Steps, step)
+	return scenario
+}
+
+// Provision adds a Provision step with the given name to the scenario.
+func (scenario *Scenario) Provision(name string, run func() error) *Scenario {
+	return scenario.AddStep(Step{Name: name, Kind: Provision, Run: run})
+}
+
+// Verify adds a Verify step with the given name to the scenario.
+func (scenario *Scenario) Verify(name string, run func() error) *Scenario {
+	return scenario.AddStep(Step{Name: name, Kind: Verify, Run: run})
+}
+
+// VerifyWithRetry adds a Verify step with the given name to the scenario that is retried up to the given number of
+// times, for checks that wait for the infrastructure to converge.
+func (scenario *Scenario) VerifyWithRetry(name string, maxRetries int, sleepBetweenRetries time.Duration, run func() error) *Scenario {
+	return scenario.AddStep(Step{Name: name, Kind: Verify, Run: run, MaxRetries: maxRetries, SleepBetweenRetries: sleepBetweenRetries})
+}
+
+// Mutate adds a Mutate step with the given name to the scenario.
+func (scenario *Scenario) Mutate(name string, run func() error) *Scenario {
+	return scenario.AddStep(Step{Name: name, Kind: Mutate, Run: run})
+}
+
+// Destroy adds a Destroy step with the given name to the scenario.
+func (scenario *Scenario) Destroy(name string, run func() error) *Scenario {
+	return scenario.AddStep(Step{Name: name, Kind: Destroy, Run: run})
+}
+
+// Run runs the steps of the scenario in order, logs the report and returns it. This function fails the test if any
+// step failed.
+func (scenario *Scenario) Run(t testing.TestingT) *Report {
+	report, err := scenario.RunE(t)
+	require.NoError(t, err)
+	return report
+}
+
+// RunE runs the steps of the scenario in order, logs the report and returns it, along with an error that combines the
+// errors of all the steps that failed.
+func (scenario *Scenario) RunE(t testing.TestingT) (*Report, error) {
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Scenario: scenario.Name, StartedAt: time.Now()}
+	var errorsOccurred = new(multierror.Error)
+
+	for _, step := range scenario.Steps {
+		result := StepResult{Name: step.Name, Kind: step.Kind}
+
+		switch {
+		case report.Failed() && step.Kind != Destroy:
+			result.Status = NotRun
+		case os.Getenv(step.skipEnvVar()) != "":
+			logger.Logf(t, "The '%s' environment variable is set, so skipping step '%s' of scenario '%s'.", step.skipEnvVar(), step.Name, scenario.Name)
+			result.Status = Skipped
+		default:
+			result = runStep(t, scenario.Name, step)
+			if result.Err != nil {
+				errorsOccurred = multierror.Append(errorsOccurred, fmt.Errorf("step '%s' failed: %s", step.Name, result.Err))
+			}
+		}
+
+		report.Steps = append(report.Steps, result)
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	logger.Logf(t, "Report of scenario '%s':\n%s", scenario.Name, report)
+
+	return report, errorsOccurred.ErrorOrNil()
+}
+
+// validate checks that every step of the scenario has a unique name and something to run.
+func (scenario *Scenario) validate() error {
+	names := map[string]bool{}
+	for i, step := range scenario.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("Step %d of scenario '%s' has no name", i, scenario.Name)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("Scenario '%s' has more than one step named '%s'", scenario.Name, step.Name)
+		}
+		if step.Run == nil {
+			return fmt.Errorf("Step '%s' of scenario '%s' has nothing to run", step.Name, scenario.Name)
+		}
+		names[step.Name] = true
+	}
+	return nil
+}
+
+// runStep runs the given step, retrying it as configured, and returns its result. Unlike retry.DoWithRetryE, the
+// error of the last attempt is kept, which reads better in the report than a retry summary.
+func runStep(t testing.TestingT, scenarioName string, step Step) StepResult {
+	result := StepResult{Name: step.Name, Kind: step.Kind}
+
+	logger.Logf(t, "Running %s step '%s' of scenario '%s'.", step.Kind, step.Name, scenarioName)
+	start := time.Now()
+
+	for {
+		result.Attempts++
+		result.Err = step.Run()
+		if result.Err == nil || result.Attempts > step.MaxRetries {
+			break
+		}
+
+		logger.Logf(t, "Step '%s' of scenario '%s' returned an error: %s. Sleeping for %s and will try again.", step.Name, scenarioName, result.Err, step.SleepBetweenRetries)
+		time.Sleep(step.SleepBetweenRetries)
+	}
+
+	result.Duration = time.Since(start)
+	if result.Err != nil {
+		result.Status = Failed
+	} else {
+		result.Status = Passed
+	}
+
+	return result
+}
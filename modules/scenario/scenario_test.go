@@ -0,0 +1,114 @@
+package scenario
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunScenarioInOrder(t *testing.T) {
+	t.Parallel()
+
+	ran := []string{}
+	record := func(name string) func() error {
+		return func() error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+
+	report, err := New("in-order").
+		Provision("apply", record("apply")).
+		Verify("check", record("check")).
+		Mutate("upgrade", record("upgrade")).
+		Verify("check-again", record("check-again")).
+		Destroy("destroy", record("destroy")).
+		RunE(t)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"apply", "check", "upgrade", "check-again", "destroy"}, ran)
+	assert.False(t, report.Failed())
+	for _, step := range report.Steps {
+		assert.Equal(t, Passed, step.Status)
+		assert.Equal(t, 1, step.Attempts)
+	}
+}
+
+func TestRunScenarioStopsAfterFailureButStillDestroys(t *testing.T) {
+	t.Parallel()
+
+	destroyed := false
+	report, err := New("failing").
+		Provision("apply", func() error { return nil }).
+		Verify("check", func() error { return errors.New("unexpected response") }).
+		Mutate("upgrade", func() error { return nil }).
+		Destroy("destroy", func() error {
+			destroyed = true
+			return nil
+		}).
+		RunE(t)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected response")
+	assert.True(t, destroyed)
+	assert.True(t, report.Failed())
+	assert.Equal(t, Failed, report.Step("check").Status)
+	assert.Equal(t, NotRun, report.Step("upgrade").Status)
+	assert.Equal(t, Passed, report.Step("destroy").Status)
+}
+
+func TestRunScenarioRetriesSteps(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	report, err := New("retries").
+		VerifyWithRetry("eventually", 3, 0, func() error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		}).
+		AddStep(Step{Name: "never", Kind: Verify, MaxRetries: 1, Run: func() error { return errors.New("never") }}).
+		RunE(t)
+
+	require.Error(t, err)
+	assert.Equal(t, Passed, report.Step("eventually").Status)
+	assert.Equal(t, 3, report.Step("eventually").Attempts)
+	assert.Equal(t, Failed, report.Step("never").Status)
+	assert.Equal(t, 2, report.Step("never").Attempts)
+	assert.EqualError(t, report.Step("never").Err, "never")
+}
+
+// Not parallel, as it sets an environment variable
+func TestRunScenarioSkipsStepsWithEnvVar(t *testing.T) {
+	os.Setenv("SKIP_scenario_test_destroy", "true")
+	defer os.Unsetenv("SKIP_scenario_test_destroy")
+
+	destroyed := false
+	report, err := New("skip").
+		Provision("apply", func() error { return nil }).
+		Destroy("scenario_test_destroy", func() error {
+			destroyed = true
+			return nil
+		}).
+		RunE(t)
+	require.NoError(t, err)
+
+	assert.False(t, destroyed)
+	assert.Equal(t, Skipped, report.Step("scenario_test_destroy").Status)
+	assert.Contains(t, report.String(), "SKIPPED")
+}
+
+func TestRunScenarioRejectsDuplicateStepNames(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("duplicates").
+		Verify("check", func() error { return nil }).
+		Verify("check", func() error { return nil }).
+		RunE(t)
+	assert.Error(t, err)
+}
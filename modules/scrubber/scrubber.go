@@ -0,0 +1,239 @@
+// Package scrubber removes account IDs, IP addresses, secrets and other sensitive values from test artifacts, such as
+// fetched files, logs and plans, so that they can be shared outside the team.
+package scrubber
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Rule replaces every match of a regular expression. Each distinct match is replaced by a numbered placeholder based on
+// the rule name, e.g. "<ACCOUNT_ID_1>", so that scrubbed artifacts can still be correlated with each other. If the
+// pattern has a capture group named "value", only that group is replaced, e.g. to keep the key of "password=...".
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Options configure what a Scrubber removes.
+type Options struct {
+	Rules  []Rule   // The regex rules applied to all content. See DefaultRules.
+	Fields []string // The keys of JSON objects whose values are fully replaced, matched case-insensitively. See DefaultFields.
+}
+
+// DefaultRules returns rules for AWS account IDs and access keys, IPv4 addresses, private keys, bearer tokens and
+// secret-like key/value pairs.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "PRIVATE_KEY", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+		{Name: "ACCESS_KEY_ID", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: "SECRET", Pattern: regexp.MustCompile(`(?i)(?:password|passwd|secret|token|secret_access_key)["']?\s*[:=]\s*["']?(?P<value>[^\s"',}]+)`)},
+		{Name: "TOKEN", Pattern: regexp.MustCompile(`(?i)bearer\s+(?P<value>[A-Za-z0-9\-._~+/]+=*)`)},
+		{Name: "ACCOUNT_ID", Pattern: regexp.MustCompile(`\b\d{12}\b`)},
+		{Name: "IP", Pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)},
+	}
+}
+
+// DefaultFields returns the JSON keys that commonly hold secrets, such as in Terraform plans and state.
+func DefaultFields() []string {
+	return []string{"password", "secret", "token", "private_key", "secret_access_key", "access_key", "client_secret", "connection_string"}
+}
+
+// Scrubber replaces sensitive values in artifacts. The same value gets the same placeholder across all the content
+// scrubbed by one Scrubber, so create a single one for all the artifacts of a test run. It is safe for concurrent use.
+type Scrubber struct {
+	rules        []Rule
+	fields       map[string]bool
+	mutex        sync.Mutex
+	placeholders map[string]map[string]string
+}
+
+// NewScrubber creates a Scrubber with the given options. Use nil for DefaultRules and DefaultFields.
+func NewScrubber(options *Options) *Scrubber {
+	if options == nil {
+		options = &Options{Rules: DefaultRules(), Fields: DefaultFields()}
+	}
+
+	fields := map[string]bool{}
+	for _, field := range options.Fields {
+		fields[strings.ToLower(field)] = true
+	}
+
+	return &Scrubber{
+		rules:        options.Rules,
+		fields:       fields,
+		placeholders: map[string]map[string]string{},
+	}
+}
+
+// Scrub returns the given content with the sensitive values replaced. If the content is JSON, the values of the
+// sensitive fields are replaced too, and the result is re-indented.
+func (scrubber *Scrubber) Scrub(content string) string {
+	var document interface{}
+	if json.Unmarshal([]byte(content), &document) == nil {
+		switch document.(type) {
+		case map[string]interface{}, []interface{}:
+			var scrubbed bytes.Buffer
+			encoder := json.NewEncoder(&scrubbed)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(scrubber.scrubJSONValue("", document)); err == nil {
+				return strings.TrimSuffix(scrubbed.String(), "\n")
+			}
+		}
+	}
+
+	return scrubber.scrubText(content)
+}
+
+// ScrubFile scrubs the given file in place. This will fail the test if there is an error.
+func (scrubber *Scrubber) ScrubFile(t testing.TestingT, path string) {
+	require.NoError(t, scrubber.ScrubFileE(t, path))
+}
+
+// ScrubFileE scrubs the given file in place. Binary files are left untouched.
+func (scrubber *Scrubber) ScrubFileE(t testing.TestingT, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if isBinary(content) {
+		logger.Logf(t, "Not scrubbing binary file %s", path)
+		return nil
+	}
+
+	scrubbed := scrubber.Scrub(string(content))
+	if scrubbed == string(content) {
+		return nil
+	}
+
+	return ioutil.WriteFile(path, []byte(scrubbed), info.Mode())
+}
+
+// ScrubDirectory scrubs all the files in the given directory and its subdirectories in place. This will fail the test
+// if there is an error.
+func (scrubber *Scrubber) ScrubDirectory(t testing.TestingT, dir string) {
+	require.NoError(t, scrubber.ScrubDirectoryE(t, dir))
+}
+
+// ScrubDirectoryE scrubs all the files in the given directory and its subdirectories in place.
+func (scrubber *Scrubber) ScrubDirectoryE(t testing.TestingT, dir string) error {
+	logger.Logf(t, "Scrubbing sensitive values from the files in %s", dir)
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return scrubber.ScrubFileE(t, path)
+	})
+}
+
+// scrubJSONValue scrubs the given decoded JSON value, which is stored under the given key of its parent object.
+func (scrubber *Scrubber) scrubJSONValue(key string, value interface{}) interface{} {
+	if scrubber.fields[strings.ToLower(key)] && value != nil {
+		if _, isObject := value.(map[string]interface{}); !isObject {
+			return scrubber.placeholder("FIELD", fmt.Sprint(value))
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for childKey, child := range typed {
+			typed[childKey] = scrubber.scrubJSONValue(childKey, child)
+		}
+		return typed
+	case []interface{}:
+		for i, child := range typed {
+			typed[i] = scrubber.scrubJSONValue(key, child)
+		}
+		return typed
+	case string:
+		return scrubber.scrubText(typed)
+	default:
+		return typed
+	}
+}
+
+// scrubText applies the regex rules to the given text.
+func (scrubber *Scrubber) scrubText(text string) string {
+	for _, rule := range scrubber.rules {
+		rule := rule
+		valueIndex := valueGroupIndex(rule.Pattern)
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if valueIndex < 0 {
+				return scrubber.placeholder(rule.Name, match)
+			}
+
+			groups := rule.Pattern.FindStringSubmatchIndex(match)
+			if groups == nil || groups[2*valueIndex] < 0 {
+				return scrubber.placeholder(rule.Name, match)
+			}
+			start, end := groups[2*valueIndex], groups[2*valueIndex+1]
+			return match[:start] + scrubber.placeholder(rule.Name, match[start:end]) + match[end:]
+		})
+	}
+	return text
+}
+
+// placeholder returns the numbered placeholder for the given value of the given rule, e.g. "<IP_2>".
+func (scrubber *Scrubber) placeholder(ruleName string, value string) string {
+	if strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">") {
+		// Already scrubbed, e.g. by a field rule
+		return value
+	}
+
+	scrubber.mutex.Lock()
+	defer scrubber.mutex.Unlock()
+
+	values, ok := scrubber.placeholders[ruleName]
+	if !ok {
+		values = map[string]string{}
+		scrubber.placeholders[ruleName] = values
+	}
+
+	placeholder, ok := values[value]
+	if !ok {
+		placeholder = fmt.Sprintf("<%s_%d>", ruleName, len(values)+1)
+		values[value] = placeholder
+	}
+	return placeholder
+}
+
+// valueGroupIndex returns the index of the capture group named "value" of the given pattern, or -1 if there is none.
+func valueGroupIndex(pattern *regexp.Regexp) int {
+	for i, name := range pattern.SubexpNames() {
+		if name == "value" {
+			return i
+		}
+	}
+	return -1
+}
+
+// isBinary returns whether the given content looks like a binary file, using the same heuristic as git.
+func isBinary(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) >= 0
+}
@@ -93,6 +93,11 @@ func (e *ErrWithCmdOutput) Error() string {
 	return fmt.Sprintf("error while running command: %v; %s", e.Underlying, e.Output.Stderr())
 }
 
+// Unwrap returns the underlying error, e.g. the *exec.ExitError of a command that exited with a non-zero status.
+func (e *ErrWithCmdOutput) Unwrap() error {
+	return e.Underlying
+}
+
 // runCommand runs a shell command and stores each line from stdout and stderr in Output. Depending on the logger, the
 // stdout and stderr of that command will also be printed to the stdout and stderr of this Go program to make debugging
 // easier.
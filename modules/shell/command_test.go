@@ -2,7 +2,9 @@ package shell
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"os/exec"
 	"regexp"
 	"strings"
 	"testing"
@@ -79,6 +81,22 @@ func TestRunCommandGetExitCode(t *testing.T) {
 	assert.Equal(t, code, 42)
 }
 
+func TestRunCommandErrorUnwrapsToExitError(t *testing.T) {
+	t.Parallel()
+
+	cmd := Command{
+		Command: "bash",
+		Args:    []string{"-c", "exit 3"},
+		Logger:  logger.Discard,
+	}
+
+	_, err := RunCommandAndGetOutputE(t, cmd)
+	var exitErr *exec.ExitError
+	if assert.True(t, errors.As(err, &exitErr)) {
+		assert.Equal(t, 3, exitErr.ExitCode())
+	}
+}
+
 func TestRunCommandAndGetOutputConcurrency(t *testing.T) {
 	t.Parallel()
 
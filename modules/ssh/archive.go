@@ -0,0 +1,95 @@
+package ssh
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// downloadFilesAsArchiveE downloads the files in options.RemoteDir that match the filters of the options as a single
+// tar.gz archive, made on the remote host by tar, and unpacks them into options.LocalDir with the same layout as
+// downloading them one at a time does. It returns the map from remote to local path of the files it unpacked.
+func downloadFilesAsArchiveE(t testing.TestingT, options ScpDownloadOptions, useSudo bool) (map[string]string, error) {
+	sudo := ""
+	if useSudo {
+		sudo = "sudo "
+	}
+	// Pass the file names NUL-separated, so that names with spaces or newlines survive the pipe
+	command := fmt.Sprintf("%s -print0 | %star -czf - --null -T -", findFilesCommand(options, useSudo), sudo)
+
+	archive, err := ioutil.TempFile("", "terratest-scp-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	logger.Logf(t, "Copying remote files in %s as an archive to local directory %s", options.RemoteDir, options.LocalDir)
+	if err := streamCommandOutputE(t, options.RemoteHost, command, archive); err != nil {
+		return nil, err
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return unpackFilesE(archive, options.RemoteDir, options.LocalDir)
+}
+
+// unpackFilesE unpacks the regular files of the given tar.gz archive of files in remoteDir into localDir, each directly
+// under localDir by its base name, and returns the map from remote to local path of the files.
+func unpackFilesE(archive io.Reader, remoteDir string, localDir string) (map[string]string, error) {
+	gzipReader, err := gzip.NewReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	unpacked := map[string]string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return unpacked, nil
+		}
+		if err != nil {
+			return unpacked, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// tar strips the leading slash of absolute paths
+		remotePath := header.Name
+		if strings.HasPrefix(remoteDir, "/") && !strings.HasPrefix(remotePath, "/") {
+			remotePath = "/" + remotePath
+		}
+
+		localPath := filepath.Join(localDir, filepath.Base(header.Name))
+		if err := writeFileE(localPath, tarReader); err != nil {
+			return unpacked, err
+		}
+		unpacked[remotePath] = localPath
+	}
+}
+
+// writeFileE writes the contents of the given reader to the file at the given path.
+func writeFileE(path string, contents io.Reader) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, contents)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package ssh
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackFiles(t *testing.T) {
+	t.Parallel()
+
+	archive := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range map[string]string{
+		"var/log/app/app.log":        "started",
+		"var/log/app/nested/err.log": "failed",
+	} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(contents))}))
+		_, err := tarWriter.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "var/log/app/nested/", Typeflag: tar.TypeDir, Mode: 0755}))
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	localDir, err := ioutil.TempDir("", "terratest-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(localDir)
+
+	unpacked, err := unpackFilesE(archive, "/var/log/app", localDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"/var/log/app/app.log":        filepath.Join(localDir, "app.log"),
+		"/var/log/app/nested/err.log": filepath.Join(localDir, "err.log"),
+	}, unpacked)
+
+	contents, err := ioutil.ReadFile(filepath.Join(localDir, "err.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "failed", string(contents))
+}
+
+func TestFindFilesCommand(t *testing.T) {
+	t.Parallel()
+
+	options := ScpDownloadOptions{RemoteDir: "/var/log", FileNameFilters: []string{"*.log", "*.txt"}, MaxFileSizeMB: 5}
+	assert.Equal(t, `sudo find /var/log -type f \( -name '*.log' -o -name '*.txt' \) -size -5M`, findFilesCommand(options, true))
+}
@@ -0,0 +1,93 @@
+package ssh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+)
+
+// verifyChecksumsE computes the sha256 of each of the given remote files on the given host in a single command, and
+// compares it with the sha256 of its local copy, given as the map value. It returns a ChecksumMismatch for each file
+// that differs.
+func verifyChecksumsE(t testing.TestingT, host Host, remoteToLocalPaths map[string]string, useSudo bool) error {
+	remotePaths := []string{}
+	for remotePath := range remoteToLocalPaths {
+		remotePaths = append(remotePaths, remotePath)
+	}
+	sort.Strings(remotePaths)
+
+	quotedPaths := []string{}
+	for _, remotePath := range remotePaths {
+		// due to inconsistent bash behavior we need to wrap the
+		// path in single quotes
+		quotedPaths = append(quotedPaths, fmt.Sprintf("'%s'", remotePath))
+	}
+
+	command := fmt.Sprintf("sha256sum %s", strings.Join(quotedPaths, " "))
+	if useSudo {
+		command = fmt.Sprintf("sudo %s", command)
+	}
+
+	// sha256sum fails if any of the files can't be read, but still prints the checksums of the others
+	output, err := CheckSshCommandE(t, host, command)
+	remoteChecksums := parseSha256sumOutput(output)
+	if err != nil && len(remoteChecksums) == 0 {
+		return err
+	}
+
+	var errorsOccurred *multierror.Error
+	for _, remotePath := range remotePaths {
+		localPath := remoteToLocalPaths[remotePath]
+		localChecksum, err := sha256OfFileE(localPath)
+		if err != nil {
+			errorsOccurred = multierror.Append(errorsOccurred, err)
+			continue
+		}
+
+		if remoteChecksum := remoteChecksums[remotePath]; remoteChecksum != localChecksum {
+			errorsOccurred = multierror.Append(errorsOccurred, ChecksumMismatch{
+				RemotePath:     remotePath,
+				LocalPath:      localPath,
+				RemoteChecksum: remoteChecksum,
+				LocalChecksum:  localChecksum,
+			})
+		}
+	}
+
+	return errorsOccurred.ErrorOrNil()
+}
+
+// parseSha256sumOutput parses the output of sha256sum, which has a "<checksum>  <path>" line per file, into a map from
+// path to checksum.
+func parseSha256sumOutput(output string) map[string]string {
+	checksums := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimRight(line, "\r"), "  ", 2)
+		if len(parts) == 2 {
+			checksums[parts[1]] = parts[0]
+		}
+	}
+	return checksums
+}
+
+// sha256OfFileE returns the hex encoded sha256 of the contents of the file at the given path.
+func sha256OfFileE(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
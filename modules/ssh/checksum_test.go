@@ -0,0 +1,39 @@
+package ssh
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSha256sumOutput(t *testing.T) {
+	t.Parallel()
+
+	output := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  /var/log/app.log\n" +
+		"sha256sum: /var/log/secret.log: Permission denied\n" +
+		"486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7  /var/log/with space.log\n"
+
+	assert.Equal(t, map[string]string{
+		"/var/log/app.log":        "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"/var/log/with space.log": "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7",
+	}, parseSha256sumOutput(output))
+}
+
+func TestSha256OfFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "terratest-checksum")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hello.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("hello"), 0644))
+
+	checksum, err := sha256OfFileE(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", checksum)
+}
@@ -0,0 +1,77 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The kinds of errors returned when connecting to a host. The errors returned by the functions in this package that
+// connect to a host match one of them with errors.Is, so tests and retry logic can branch on the kind of an error
+// rather than on its message, e.g. to stop retrying on errors.Is(err, ssh.ErrAuthFailed).
+var (
+	// ErrAuthFailed is returned when the host rejects all the configured authentication methods, e.g. because the key
+	// pair is not authorized for the user.
+	ErrAuthFailed = errors.New("ssh authentication failed")
+
+	// ErrConnectionFailed is returned when no SSH connection could be established to the host, e.g. because it is not
+	// reachable yet.
+	ErrConnectionFailed = errors.New("ssh connection failed")
+)
+
+// ConnectionFailed is returned when connecting to a host fails. Its message is the one of the underlying error.
+type ConnectionFailed struct {
+	Address    string
+	Kind       error // ErrAuthFailed or ErrConnectionFailed
+	Underlying error
+}
+
+func (err ConnectionFailed) Error() string {
+	return err.Underlying.Error()
+}
+
+// Is returns true if the target is the kind of the error.
+func (err ConnectionFailed) Is(target error) bool {
+	return target == err.Kind
+}
+
+// Unwrap returns the underlying error.
+func (err ConnectionFailed) Unwrap() error {
+	return err.Underlying
+}
+
+// newConnectionFailed wraps the given error returned when connecting to the given address into a ConnectionFailed of
+// the matching kind. The ssh library does not export a type for authentication failures, so they are recognized by
+// their message.
+func newConnectionFailed(address string, err error) error {
+	kind := ErrConnectionFailed
+	if strings.Contains(err.Error(), "unable to authenticate") {
+		kind = ErrAuthFailed
+	}
+	return ConnectionFailed{Address: address, Kind: kind, Underlying: err}
+}
+
+// FileTooLarge is returned when streaming a remote file that is larger than the maximum size allowed.
+type FileTooLarge struct {
+	Path     string
+	MaxBytes int64
+}
+
+func (err FileTooLarge) Error() string {
+	return fmt.Sprintf("Remote file %s is larger than the maximum of %d bytes", err.Path, err.MaxBytes)
+}
+
+// ChecksumMismatch is returned when the sha256 of a downloaded file differs from the one of the remote file.
+type ChecksumMismatch struct {
+	RemotePath     string
+	LocalPath      string
+	RemoteChecksum string // Empty if the remote checksum could not be computed
+	LocalChecksum  string
+}
+
+func (err ChecksumMismatch) Error() string {
+	if err.RemoteChecksum == "" {
+		return fmt.Sprintf("Could not compute the sha256 of remote file %s to verify local copy %s", err.RemotePath, err.LocalPath)
+	}
+	return fmt.Sprintf("Local copy %s of remote file %s has sha256 %s, expected %s", err.LocalPath, err.RemotePath, err.LocalChecksum, err.RemoteChecksum)
+}
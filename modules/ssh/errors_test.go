@@ -0,0 +1,23 @@
+package ssh
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConnectionFailedClassifiesKind(t *testing.T) {
+	t.Parallel()
+
+	authErr := newConnectionFailed("10.0.0.1:22", errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey], no supported methods remain"))
+	assert.True(t, errors.Is(authErr, ErrAuthFailed))
+	assert.False(t, errors.Is(authErr, ErrConnectionFailed))
+	assert.Contains(t, authErr.Error(), "unable to authenticate")
+
+	dialErr := errors.New("dial tcp 10.0.0.1:22: connect: connection refused")
+	connErr := newConnectionFailed("10.0.0.1:22", dialErr)
+	assert.True(t, errors.Is(connErr, ErrConnectionFailed))
+	assert.True(t, errors.Is(connErr, dialErr))
+	assert.Equal(t, dialErr.Error(), connErr.Error())
+}
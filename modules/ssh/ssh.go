@@ -2,6 +2,7 @@
 package ssh
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +33,7 @@ type Host struct {
 	OverrideSshAgent *SshAgent // enable an in process `SshAgent` for connections to this host (disabled by default)
 	Password         string    // plain text password (blank by default)
 	CustomPort       int       // port number to use to connect to the host (port 22 will be used if unset)
+	JumpHost         *Host     // bastion host to proxy the connection through, e.g. for hosts in private subnets (disabled by default)
 }
 
 type ScpDownloadOptions struct {
@@ -40,6 +42,8 @@ type ScpDownloadOptions struct {
 	RemoteDir       string   //Copy from this directory on the remote machine
 	LocalDir        string   //Copy RemoteDir to this directory on the local machine
 	RemoteHost      Host     //Connection information for the remote machine
+	VerifyChecksums bool     //Compare the sha256 of each downloaded file with the one computed on the remote machine, to detect truncated transfers
+	Archive         bool     //Transfer the matching files as a single tar.gz archive instead of one at a time, which is much faster for many small files. Requires GNU tar on the remote machine.
 }
 
 // ScpFileToE uploads the contents using SCP to the given host and fails the test if the connection fails.
@@ -52,24 +56,17 @@ func ScpFileTo(t testing.TestingT, host Host, mode os.FileMode, remotePath, cont
 
 // ScpFileToE uploads the contents using SCP to the given host and return an error if the process fails.
 func ScpFileToE(t testing.TestingT, host Host, mode os.FileMode, remotePath, contents string) error {
-	authMethods, err := createAuthMethodsForHost(host)
-	if err != nil {
-		return err
-	}
 	dir, file := filepath.Split(remotePath)
 
-	hostOptions := SshConnectionOptions{
-		Username:    host.SshUserName,
-		Address:     host.Hostname,
-		Port:        host.getPort(),
-		Command:     "/usr/bin/scp -t " + dir,
-		AuthMethods: authMethods,
+	hostOptions, err := createSshConnectionOptions(host, "/usr/bin/scp -t "+dir)
+	if err != nil {
+		return err
 	}
 
 	scp := sendScpCommandsToCopyFile(mode, file, contents)
 
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 		Input:    &scp,
 	}
@@ -91,24 +88,15 @@ func ScpFileFrom(t testing.TestingT, host Host, remotePath string, localDestinat
 
 // ScpFileFromE downloads the file from remotePath on the given host using SCP and returns an error if the process fails.
 func ScpFileFromE(t testing.TestingT, host Host, remotePath string, localDestination *os.File, useSudo bool) error {
-	authMethods, err := createAuthMethodsForHost(host)
+	dir := filepath.Dir(remotePath)
 
+	hostOptions, err := createSshConnectionOptions(host, "/usr/bin/scp -t "+dir)
 	if err != nil {
 		return err
 	}
 
-	dir := filepath.Dir(remotePath)
-
-	hostOptions := SshConnectionOptions{
-		Username:    host.SshUserName,
-		Address:     host.Hostname,
-		Port:        host.getPort(),
-		Command:     "/usr/bin/scp -t " + dir,
-		AuthMethods: authMethods,
-	}
-
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 	}
 
@@ -129,23 +117,18 @@ func ScpDirFrom(t testing.TestingT, options ScpDownloadOptions, useSudo bool) {
 // ScpDirFromE downloads all the files from remotePath on the given host using SCP
 // and returns an error if the process fails. NOTE: only files within remotePath will
 // be downloaded. This function will not recursively download subdirectories or follow
-// symlinks.
+// symlinks. If options.VerifyChecksums is true, a ChecksumMismatch error is returned for each downloaded file whose
+// sha256 differs from the one computed on the remote machine after the download, e.g. because the transfer was
+// truncated. Files that change while they are downloaded, such as logs that are still being written, will mismatch too.
+// If options.Archive is true, the files are transferred as a single tar.gz archive and unpacked into the same layout.
 func ScpDirFromE(t testing.TestingT, options ScpDownloadOptions, useSudo bool) error {
-	authMethods, err := createAuthMethodsForHost(options.RemoteHost)
+	hostOptions, err := createSshConnectionOptions(options.RemoteHost, "/usr/bin/scp -t "+options.RemoteDir)
 	if err != nil {
 		return err
 	}
 
-	hostOptions := SshConnectionOptions{
-		Username:    options.RemoteHost.SshUserName,
-		Address:     options.RemoteHost.Hostname,
-		Port:        options.RemoteHost.getPort(),
-		Command:     "/usr/bin/scp -t " + options.RemoteDir,
-		AuthMethods: authMethods,
-	}
-
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 	}
 
@@ -166,6 +149,14 @@ func ScpDirFromE(t testing.TestingT, options ScpDownloadOptions, useSudo bool) e
 	}
 
 	var errorsOccurred = new(multierror.Error)
+	downloadedFiles := map[string]string{}
+
+	if options.Archive {
+		downloadedFiles, err = downloadFilesAsArchiveE(t, options, useSudo)
+		errorsOccurred = multierror.Append(errorsOccurred, err)
+		// The archive already holds all the files, so there are none left to copy one at a time
+		filesInDir = nil
+	}
 
 	for _, fullRemoteFilePath := range filesInDir {
 		fileName := filepath.Base(fullRemoteFilePath)
@@ -181,6 +172,13 @@ func ScpDirFromE(t testing.TestingT, options ScpDownloadOptions, useSudo bool) e
 
 		err = copyFileFromRemote(t, sshSession, localFile, fullRemoteFilePath, useSudo)
 		errorsOccurred = multierror.Append(errorsOccurred, err)
+		if err == nil {
+			downloadedFiles[fullRemoteFilePath] = localFilePath
+		}
+	}
+
+	if options.VerifyChecksums && len(downloadedFiles) > 0 {
+		errorsOccurred = multierror.Append(errorsOccurred, verifyChecksumsE(t, options.RemoteHost, downloadedFiles, useSudo))
 	}
 
 	return errorsOccurred.ErrorOrNil()
@@ -238,21 +236,13 @@ func CheckSshCommand(t testing.TestingT, host Host, command string) string {
 
 // CheckSshCommandE checks that you can connect via SSH to the given host and run the given command. Returns the stdout/stderr.
 func CheckSshCommandE(t testing.TestingT, host Host, command string) (string, error) {
-	authMethods, err := createAuthMethodsForHost(host)
+	hostOptions, err := createSshConnectionOptions(host, command)
 	if err != nil {
 		return "", err
 	}
 
-	hostOptions := SshConnectionOptions{
-		Username:    host.SshUserName,
-		Address:     host.Hostname,
-		Port:        host.getPort(),
-		Command:     command,
-		AuthMethods: authMethods,
-	}
-
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 	}
 
@@ -287,6 +277,72 @@ func CheckSshCommandWithRetryE(t testing.TestingT, host Host, command string, re
 	})
 }
 
+// CaptureShellOutput connects to the given host via SSH, opens an interactive shell on a pseudo-terminal and returns
+// everything the remote side writes to it during the given duration. This is useful for endpoints that stream output
+// rather than run commands, such as the EC2 serial console.
+func CaptureShellOutput(t testing.TestingT, host Host, duration time.Duration) string {
+	out, err := CaptureShellOutputE(t, host, duration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// CaptureShellOutputE connects to the given host via SSH, opens an interactive shell on a pseudo-terminal and returns
+// everything the remote side writes to it during the given duration. This is useful for endpoints that stream output
+// rather than run commands, such as the EC2 serial console.
+func CaptureShellOutputE(t testing.TestingT, host Host, duration time.Duration) (string, error) {
+	hostOptions, err := createSshConnectionOptions(host, "")
+	if err != nil {
+		return "", err
+	}
+
+	sshSession := &SshSession{
+		Options:  hostOptions,
+		JumpHost: &JumpHostSession{},
+	}
+
+	defer sshSession.Cleanup(t)
+
+	logger.Logf(t, "Capturing shell output from %s@%s for %s", hostOptions.Username, hostOptions.Address, duration)
+	if err := setUpSSHClient(sshSession); err != nil {
+		return "", err
+	}
+
+	if err := setUpSSHSession(sshSession); err != nil {
+		return "", err
+	}
+
+	stdout, err := sshSession.Session.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+
+	if err := sshSession.Session.RequestPty("vt100", 80, 200, ssh.TerminalModes{ssh.ECHO: 0}); err != nil {
+		return "", err
+	}
+
+	if err := sshSession.Session.Shell(); err != nil {
+		return "", err
+	}
+
+	var output bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(&output, stdout)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(duration):
+		sshSession.Session.Close()
+		<-done
+	}
+
+	return output.String(), nil
+}
+
 // CheckPrivateSshConnection attempts to connect to privateHost (which is not addressable from the Internet) via a
 // separate publicHost (which is addressable from the Internet) and then executes "command" on privateHost and returns
 // its output. It is useful for checking that it's possible to SSH from a Bastion Host to a private instance.
@@ -392,6 +448,26 @@ func listFileInRemoteDir(t testing.TestingT, sshSession *SshSession, options Scp
 	logger.Logf(t, "Running command %s on %s@%s", sshSession.Options.Command, sshSession.Options.Username, sshSession.Options.Address)
 
 	var result []string
+
+	finalCommandString := findFilesCommand(options, useSudo)
+	resultString, err := CheckSshCommandE(t, options.RemoteHost, finalCommandString)
+
+	if err != nil {
+		return result, err
+	}
+
+	// The last character returned is `\n` this results in an extra "" array
+	// member when we do the split below. Cut off the last character to avoid
+	// having to remove the blank entry in the array.
+	resultString = resultString[:len(resultString)-1]
+
+	result = append(result, strings.Split(resultString, "\n")...)
+	return result, nil
+}
+
+// findFilesCommand returns the command that lists the files in options.RemoteDir that match options.FileNameFilters and
+// options.MaxFileSizeMB, using sudo if useSudo is true.
+func findFilesCommand(options ScpDownloadOptions, useSudo bool) string {
 	var findCommandArgs []string
 
 	if useSudo {
@@ -423,20 +499,7 @@ func listFileInRemoteDir(t testing.TestingT, sshSession *SshSession, options Scp
 		findCommandArgs = append(findCommandArgs, "-size", fmt.Sprintf("-%dM", options.MaxFileSizeMB))
 	}
 
-	finalCommandString := strings.Join(findCommandArgs, " ")
-	resultString, err := CheckSshCommandE(t, options.RemoteHost, finalCommandString)
-
-	if err != nil {
-		return result, err
-	}
-
-	// The last character returned is `\n` this results in an extra "" array
-	// member when we do the split below. Cut off the last character to avoid
-	// having to remove the blank entry in the array.
-	resultString = resultString[:len(resultString)-1]
-
-	result = append(result, strings.Split(resultString, "\n")...)
-	return result, nil
+	return strings.Join(findCommandArgs, " ")
 }
 
 // Added based on code: https://github.com/bramvdbogaerde/go-scp/pull/6/files
@@ -522,13 +585,13 @@ func fillSSHClientForJumpHost(sshSession *SshSession) error {
 
 	hostVirtualConn, err := jumpHostClient.Dial("tcp", sshSession.Options.ConnectionString())
 	if err != nil {
-		return err
+		return newConnectionFailed(sshSession.Options.ConnectionString(), err)
 	}
 	sshSession.JumpHost.HostVirtualConnection = hostVirtualConn
 
 	hostConn, hostIncomingChannels, hostIncomingRequests, err := ssh.NewClientConn(hostVirtualConn, sshSession.Options.ConnectionString(), createSSHClientConfig(sshSession.Options))
 	if err != nil {
-		return err
+		return newConnectionFailed(sshSession.Options.ConnectionString(), err)
 	}
 	sshSession.JumpHost.HostConnection = hostConn
 
@@ -548,7 +611,11 @@ func setUpSSHSession(sshSession *SshSession) error {
 
 func createSSHClient(options *SshConnectionOptions) (*ssh.Client, error) {
 	sshClientConfig := createSSHClientConfig(options)
-	return ssh.Dial("tcp", options.ConnectionString(), sshClientConfig)
+	client, err := ssh.Dial("tcp", options.ConnectionString(), sshClientConfig)
+	if err != nil {
+		return nil, newConnectionFailed(options.ConnectionString(), err)
+	}
+	return client, nil
 }
 
 func createSSHClientConfig(hostOptions *SshConnectionOptions) *ssh.ClientConfig {
@@ -570,6 +637,33 @@ func NoOpHostKeyCallback(hostname string, remote net.Addr, key ssh.PublicKey) er
 	return nil
 }
 
+// Returns the options to connect to the given host and run the given command, proxying the connection through the
+// host's JumpHost if it has one
+func createSshConnectionOptions(host Host, command string) (*SshConnectionOptions, error) {
+	authMethods, err := createAuthMethodsForHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	hostOptions := SshConnectionOptions{
+		Username:    host.SshUserName,
+		Address:     host.Hostname,
+		Port:        host.getPort(),
+		Command:     command,
+		AuthMethods: authMethods,
+	}
+
+	if host.JumpHost != nil {
+		jumpHostOptions, err := createSshConnectionOptions(*host.JumpHost, "")
+		if err != nil {
+			return nil, err
+		}
+		hostOptions.JumpHost = jumpHostOptions
+	}
+
+	return &hostOptions, nil
+}
+
 // Returns an array of authentication methods
 func createAuthMethodsForHost(host Host) ([]ssh.AuthMethod, error) {
 	var methods []ssh.AuthMethod
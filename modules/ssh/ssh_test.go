@@ -26,6 +26,31 @@ func TestHostWithCustomPort(t *testing.T) {
 	assert.Equal(t, customPort, host.getPort(), "host.getPort() did not return the custom port number")
 }
 
+func TestCreateSshConnectionOptionsWithJumpHost(t *testing.T) {
+	t.Parallel()
+
+	bastion := Host{Hostname: "bastion", SshUserName: "bastion-user", Password: "bastion-password", CustomPort: 2222}
+	host := Host{Hostname: "10.0.0.1", SshUserName: "user", Password: "password", JumpHost: &bastion}
+
+	options, err := createSshConnectionOptions(host, "echo hi")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "10.0.0.1:22", options.ConnectionString())
+		assert.Equal(t, "echo hi", options.Command)
+		if assert.NotNil(t, options.JumpHost) {
+			assert.Equal(t, "bastion-user", options.JumpHost.Username)
+			assert.Equal(t, "bastion:2222", options.JumpHost.ConnectionString())
+		}
+	}
+
+	options, err = createSshConnectionOptions(Host{Hostname: "host", Password: "password"}, "")
+	if assert.NoError(t, err) {
+		assert.Nil(t, options.JumpHost)
+	}
+
+	_, err = createSshConnectionOptions(Host{Hostname: "host", Password: "password", JumpHost: &Host{Hostname: "bastion"}}, "")
+	assert.Error(t, err)
+}
+
 // global var for use in mock callback
 var timesCalled int
 
@@ -0,0 +1,169 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// StreamOptions are the options for streaming the contents of a remote file.
+type StreamOptions struct {
+	MaxBytes   int64               // Fail with FileTooLarge if the file is larger than this many bytes. Zero means no limit.
+	OnProgress func(written int64) // Called with the total number of bytes written so far each time more are written.
+}
+
+// StreamContentsOfFile connects to the given host via SSH and writes the contents of the file at the given filePath to
+// the given writer as they are received, without holding the whole file in memory. If useSudo is true, then the
+// contents will be retrieved using sudo. This method returns the number of bytes written.
+func StreamContentsOfFile(t testing.TestingT, host Host, useSudo bool, filePath string, writer io.Writer, options StreamOptions) int64 {
+	written, err := StreamContentsOfFileE(t, host, useSudo, filePath, writer, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return written
+}
+
+// StreamContentsOfFileE connects to the given host via SSH and writes the contents of the file at the given filePath to
+// the given writer as they are received, without holding the whole file in memory. If useSudo is true, then the
+// contents will be retrieved using sudo. If the file is larger than options.MaxBytes, only that many bytes are written
+// and a FileTooLarge error is returned. This method returns the number of bytes written.
+func StreamContentsOfFileE(t testing.TestingT, host Host, useSudo bool, filePath string, writer io.Writer, options StreamOptions) (int64, error) {
+	command := fmt.Sprintf("cat %s", filePath)
+	if options.MaxBytes > 0 {
+		// Read one more byte than allowed, so that larger files can be told apart without transferring all of them
+		command = fmt.Sprintf("head -c %d %s", options.MaxBytes+1, filePath)
+	}
+	if useSudo {
+		command = fmt.Sprintf("sudo %s", command)
+	}
+
+	out := &streamWriter{writer: writer, options: options}
+	if err := streamCommandOutputE(t, host, command, out); err != nil {
+		if out.err != nil {
+			return out.written, out.err
+		}
+		return out.written, err
+	}
+	if out.err != nil {
+		return out.written, out.err
+	}
+	if out.exceeded {
+		return out.written, FileTooLarge{Path: filePath, MaxBytes: options.MaxBytes}
+	}
+
+	return out.written, nil
+}
+
+// DownloadFile connects to the given host via SSH and streams the file at the given filePath to the given local path,
+// creating its parent directories if needed. If useSudo is true, then the contents will be retrieved using sudo. This
+// method returns the number of bytes written.
+func DownloadFile(t testing.TestingT, host Host, useSudo bool, filePath string, localPath string, options StreamOptions) int64 {
+	written, err := DownloadFileE(t, host, useSudo, filePath, localPath, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return written
+}
+
+// DownloadFileE connects to the given host via SSH and streams the file at the given filePath to the given local path,
+// creating its parent directories if needed. If useSudo is true, then the contents will be retrieved using sudo. If the
+// download fails, e.g. because the file is larger than options.MaxBytes, the partial local file is removed. This method
+// returns the number of bytes written.
+func DownloadFileE(t testing.TestingT, host Host, useSudo bool, filePath string, localPath string, options StreamOptions) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(localPath)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := StreamContentsOfFileE(t, host, useSudo, filePath, file, options)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(localPath)
+		return written, err
+	}
+
+	return written, nil
+}
+
+// streamWriter writes to the underlying writer up to the maximum size of the options, reporting progress to them. It
+// never returns an error itself, so that the SSH session always reads the remote output to the end instead of
+// blocking; check err and exceeded once the command is done instead.
+type streamWriter struct {
+	writer   io.Writer
+	options  StreamOptions
+	written  int64
+	exceeded bool
+	err      error
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if w.err != nil {
+		return n, nil
+	}
+
+	if w.options.MaxBytes > 0 && w.written+int64(len(p)) > w.options.MaxBytes {
+		p = p[:w.options.MaxBytes-w.written]
+		w.exceeded = true
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+
+	written, err := w.writer.Write(p)
+	w.written += int64(written)
+	if err != nil {
+		w.err = err
+		return n, nil
+	}
+
+	if w.options.OnProgress != nil {
+		w.options.OnProgress(w.written)
+	}
+	return n, nil
+}
+
+// streamCommandOutputE connects to the given host via SSH and runs the given command, writing its output to the given
+// writer as it is received. If the command fails, the error includes its stderr.
+func streamCommandOutputE(t testing.TestingT, host Host, command string, writer io.Writer) error {
+	hostOptions, err := createSshConnectionOptions(host, command)
+	if err != nil {
+		return err
+	}
+
+	sshSession := &SshSession{
+		Options:  hostOptions,
+		JumpHost: &JumpHostSession{},
+	}
+
+	defer sshSession.Cleanup(t)
+
+	logger.Logf(t, "Running command %s on %s@%s", sshSession.Options.Command, sshSession.Options.Username, sshSession.Options.Address)
+	if err := setUpSSHClient(sshSession); err != nil {
+		return err
+	}
+
+	if err := setUpSSHSession(sshSession); err != nil {
+		return err
+	}
+
+	stderr := &bytes.Buffer{}
+	sshSession.Session.Stdout = writer
+	sshSession.Session.Stderr = stderr
+
+	if err := sshSession.Session.Run(command); err != nil {
+		return fmt.Errorf("%s: %s", err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package ssh
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWriterReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	var progress []int64
+	out := &bytes.Buffer{}
+	w := &streamWriter{writer: out, options: StreamOptions{OnProgress: func(written int64) { progress = append(progress, written) }}}
+
+	w.Write([]byte("hello "))
+	w.Write([]byte("world"))
+
+	assert.Equal(t, "hello world", out.String())
+	assert.Equal(t, []int64{6, 11}, progress)
+	assert.False(t, w.exceeded)
+}
+
+func TestStreamWriterStopsAtMaxBytes(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	w := &streamWriter{writer: out, options: StreamOptions{MaxBytes: 8}}
+
+	n, err := w.Write([]byte("hello "))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	n, err = w.Write([]byte("world"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	w.Write([]byte("!"))
+
+	assert.Equal(t, "hello wo", out.String())
+	assert.Equal(t, int64(8), w.written)
+	assert.True(t, w.exceeded)
+}
@@ -1,8 +1,6 @@
 package terraform
 
 import (
-	"errors"
-
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -86,7 +84,7 @@ func ApplyAndIdempotentE(t testing.TestingT, options *Options) (string, error) {
 	}
 
 	if exitCode != 0 {
-		return out, errors.New("terraform configuration not idempotent")
+		return out, ErrPlanDiff
 	}
 
 	return out, nil
@@ -2,9 +2,11 @@ package terraform
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/gruntwork-io/terratest/modules/collections"
 	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/runinfo"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
@@ -14,12 +16,27 @@ func generateCommand(options *Options, args ...string) shell.Command {
 		Command:    options.TerraformBinary,
 		Args:       args,
 		WorkingDir: options.TerraformDir,
-		Env:        options.EnvVars,
+		Env:        withRunInfoEnvVars(options.EnvVars),
 		Logger:     options.Logger,
 	}
 	return cmd
 }
 
+// withRunInfoEnvVars returns the given environment variables along with the TF_VAR_terratest_* variables that expose
+// the metadata of the test run to Terraform (see the runinfo package), unless those are set explicitly.
+func withRunInfoEnvVars(envVars map[string]string) map[string]string {
+	merged := map[string]string{}
+	for key, value := range runinfo.Get().TerraformEnvVars() {
+		if _, isSet := os.LookupEnv(key); !isSet {
+			merged[key] = value
+		}
+	}
+	for key, value := range envVars {
+		merged[key] = value
+	}
+	return merged
+}
+
 var commandsWithParallelism = []string{
 	"plan",
 	"apply",
@@ -0,0 +1,26 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/runinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCommandExposesRunInfo(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{
+		TerraformBinary: "terraform",
+		EnvVars:         map[string]string{"TF_VAR_terratest_git_branch": "explicit", "FOO": "bar"},
+	}
+
+	cmd := generateCommand(options, "plan")
+
+	assert.Equal(t, runinfo.Get().RunID, cmd.Env["TF_VAR_terratest_run_id"])
+	assert.Equal(t, "explicit", cmd.Env["TF_VAR_terratest_git_branch"])
+	assert.Equal(t, "bar", cmd.Env["FOO"])
+
+	// The options are not modified
+	assert.Len(t, options.EnvVars, 2)
+}
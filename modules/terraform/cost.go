@@ -0,0 +1,255 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// HoursPerMonth is the number of hours used to convert hourly prices to monthly costs, the same as cloud provider
+// pricing pages use.
+const HoursPerMonth = 730
+
+// PricingSource returns the monthly cost of a resource of the given type with the given attribute values (as found in
+// the before or after values of a plan resource change). The known return value is false when the source has no price
+// for the resource, e.g. for types it does not cover.
+type PricingSource interface {
+	MonthlyCost(resourceType string, values map[string]interface{}) (cost float64, known bool, err error)
+}
+
+// ResourcePrice is the price of a resource type in a PriceTable. If SizeAttribute is set, the price of a resource is
+// looked up in HourlyPrices by the value of that attribute (e.g. the instance_type of an aws_instance), otherwise
+// MonthlyPrice is used for every resource of the type.
+type ResourcePrice struct {
+	SizeAttribute string             `json:"size_attribute,omitempty"`
+	HourlyPrices  map[string]float64 `json:"hourly_prices,omitempty"`
+	MonthlyPrice  float64            `json:"monthly_price,omitempty"`
+}
+
+// PriceTable is a PricingSource backed by a static table of prices per resource type, e.g.:
+//
+//	PriceTable{
+//		"aws_instance": {SizeAttribute: "instance_type", HourlyPrices: map[string]float64{"t3.micro": 0.0104}},
+//		"aws_nat_gateway": {MonthlyPrice: 32.85},
+//	}
+//
+// Types that are free (e.g. aws_security_group) should be added with a zero MonthlyPrice, so that they are not
+// reported as unpriced.
+type PriceTable map[string]ResourcePrice
+
+// MonthlyCost implements PricingSource.
+func (table PriceTable) MonthlyCost(resourceType string, values map[string]interface{}) (float64, bool, error) {
+	price, ok := table[resourceType]
+	if !ok {
+		return 0, false, nil
+	}
+
+	if price.SizeAttribute == "" {
+		return price.MonthlyPrice, true, nil
+	}
+
+	size, ok := values[price.SizeAttribute].(string)
+	if !ok {
+		// The size is unknown until apply, or not set at all
+		return 0, false, nil
+	}
+
+	hourlyPrice, ok := price.HourlyPrices[size]
+	if !ok {
+		return 0, false, nil
+	}
+	return hourlyPrice * HoursPerMonth, true, nil
+}
+
+// LoadPriceTable loads a PriceTable from the given JSON file, which maps resource types to objects with the
+// size_attribute, hourly_prices and monthly_price fields of ResourcePrice.
+func LoadPriceTable(t testing.TestingT, path string) PriceTable {
+	table, err := LoadPriceTableE(t, path)
+	require.NoError(t, err)
+	return table
+}
+
+// LoadPriceTableE loads a PriceTable from the given JSON file, which maps resource types to objects with the
+// size_attribute, hourly_prices and monthly_price fields of ResourcePrice.
+func LoadPriceTableE(t testing.TestingT, path string) (PriceTable, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	table := PriceTable{}
+	if err := json.Unmarshal(contents, &table); err != nil {
+		return nil, fmt.Errorf("Failed to parse price table %s: %s", path, err)
+	}
+	return table, nil
+}
+
+// ResourceCostChange is the estimated monthly cost of a resource before and after applying a plan.
+type ResourceCostChange struct {
+	Address    string
+	Type       string
+	Actions    tfjson.Actions
+	CostBefore float64
+	CostAfter  float64
+}
+
+// Delta returns the change of the monthly cost of the resource.
+func (change ResourceCostChange) Delta() float64 {
+	return change.CostAfter - change.CostBefore
+}
+
+// PlanCostEstimate is the estimated change of the monthly cost of the resources of a plan.
+type PlanCostEstimate struct {
+	MonthlyDelta float64
+	Changes      []ResourceCostChange // Changes of the priced resources, sorted by address
+	Unpriced     []string             // Addresses of the changed resources the pricing source has no price for
+}
+
+// String renders the estimate with one line per resource whose cost changes.
+func (estimate *PlanCostEstimate) String() string {
+	lines := []string{}
+	for _, change := range estimate.Changes {
+		if change.Delta() != 0 {
+			lines = append(lines, fmt.Sprintf("  %s (%s): %.2f -> %.2f (%+.2f)", change.Address, strings.Join(actionsToStrings(change.Actions), ", "), change.CostBefore, change.CostAfter, change.Delta()))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("  Total monthly delta: %+.2f", estimate.MonthlyDelta))
+	if len(estimate.Unpriced) > 0 {
+		lines = append(lines, fmt.Sprintf("  Unpriced: %v", estimate.Unpriced))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EstimatePlanCost walks the resource changes of the given plan and estimates how much the plan changes the monthly
+// cost, using the given pricing source. Created resources add their cost, destroyed resources subtract theirs, and
+// updated or replaced resources add the difference.
+func EstimatePlanCost(t testing.TestingT, plan *PlanStruct, source PricingSource) *PlanCostEstimate {
+	estimate, err := EstimatePlanCostE(t, plan, source)
+	require.NoError(t, err)
+	return estimate
+}
+
+// EstimatePlanCostE walks the resource changes of the given plan and estimates how much the plan changes the monthly
+// cost, using the given pricing source. Created resources add their cost, destroyed resources subtract theirs, and
+// updated or replaced resources add the difference.
+func EstimatePlanCostE(t testing.TestingT, plan *PlanStruct, source PricingSource) (*PlanCostEstimate, error) {
+	estimate := &PlanCostEstimate{Changes: []ResourceCostChange{}, Unpriced: []string{}}
+
+	addresses := []string{}
+	for address := range plan.ResourceChangesMap {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		resourceChange := plan.ResourceChangesMap[address]
+		if resourceChange.Mode != tfjson.ManagedResourceMode || resourceChange.Change == nil {
+			continue
+		}
+
+		actions := resourceChange.Change.Actions
+		if actions.NoOp() || actions.Read() {
+			continue
+		}
+
+		change := ResourceCostChange{Address: address, Type: resourceChange.Type, Actions: actions}
+		known := true
+
+		if !actions.Create() {
+			cost, costKnown, err := source.MonthlyCost(resourceChange.Type, toValuesMap(resourceChange.Change.Before))
+			if err != nil {
+				return nil, err
+			}
+			change.CostBefore = cost
+			known = known && costKnown
+		}
+
+		if !actions.Delete() {
+			cost, costKnown, err := source.MonthlyCost(resourceChange.Type, toValuesMap(resourceChange.Change.After))
+			if err != nil {
+				return nil, err
+			}
+			change.CostAfter = cost
+			known = known && costKnown
+		}
+
+		if !known {
+			estimate.Unpriced = append(estimate.Unpriced, address)
+			continue
+		}
+
+		estimate.Changes = append(estimate.Changes, change)
+		estimate.MonthlyDelta += change.Delta()
+	}
+
+	return estimate, nil
+}
+
+// PlanCostGateOptions configure CheckPlanCost.
+type PlanCostGateOptions struct {
+	Source          PricingSource
+	MaxMonthlyDelta float64 // The maximum allowed increase of the monthly cost
+	WarnOnly        bool    // Log a warning instead of failing when the threshold is exceeded
+	FailOnUnpriced  bool    // Fail when the plan changes resources without a price, instead of ignoring them
+}
+
+// CheckPlanCost estimates the monthly cost change of the given plan and fails the test if it is above the threshold
+// of the given options (or only logs a warning if WarnOnly is set). Returns the estimate.
+func CheckPlanCost(t testing.TestingT, plan *PlanStruct, options PlanCostGateOptions) *PlanCostEstimate {
+	estimate, err := CheckPlanCostE(t, plan, options)
+	require.NoError(t, err)
+	return estimate
+}
+
+// CheckPlanCostE estimates the monthly cost change of the given plan and returns a PlanCostThresholdExceeded error if
+// it is above the threshold of the given options (or only logs a warning if WarnOnly is set). Returns the estimate.
+func CheckPlanCostE(t testing.TestingT, plan *PlanStruct, options PlanCostGateOptions) (*PlanCostEstimate, error) {
+	estimate, err := EstimatePlanCostE(t, plan, options.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logf(t, "Estimated monthly cost change of the plan:\n%s", estimate)
+
+	if len(estimate.Unpriced) > 0 {
+		if options.FailOnUnpriced {
+			return estimate, UnpricedResourcesInPlan{Addresses: estimate.Unpriced}
+		}
+		logger.Logf(t, "WARNING: the cost of %d resources is not included in the estimate, as no price is known for them: %v", len(estimate.Unpriced), estimate.Unpriced)
+	}
+
+	if estimate.MonthlyDelta > options.MaxMonthlyDelta {
+		err := PlanCostThresholdExceeded{MonthlyDelta: estimate.MonthlyDelta, MaxMonthlyDelta: options.MaxMonthlyDelta, Estimate: estimate}
+		if !options.WarnOnly {
+			return estimate, err
+		}
+		logger.Logf(t, "WARNING: %s", err)
+	}
+
+	return estimate, nil
+}
+
+// toValuesMap returns the given before or after values of a resource change as a map, or an empty map if there are
+// none (e.g. the before values of a created resource).
+func toValuesMap(values interface{}) map[string]interface{} {
+	if valuesMap, ok := values.(map[string]interface{}); ok {
+		return valuesMap
+	}
+	return map[string]interface{}{}
+}
+
+// actionsToStrings converts the given plan actions to strings.
+func actionsToStrings(actions tfjson.Actions) []string {
+	out := []string{}
+	for _, action := range actions {
+		out = append(out, string(action))
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const costTestPlanJson = `
+{
+  "format_version": "0.1",
+  "resource_changes": [
+    {
+      "address": "aws_instance.new",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "new",
+      "change": {"actions": ["create"], "before": null, "after": {"instance_type": "m5.large"}}
+    },
+    {
+      "address": "aws_instance.resized",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "resized",
+      "change": {"actions": ["update"], "before": {"instance_type": "t3.micro"}, "after": {"instance_type": "t3.small"}}
+    },
+    {
+      "address": "aws_nat_gateway.old",
+      "mode": "managed",
+      "type": "aws_nat_gateway",
+      "name": "old",
+      "change": {"actions": ["delete"], "before": {"subnet_id": "subnet-1"}, "after": null}
+    },
+    {
+      "address": "aws_security_group.unchanged",
+      "mode": "managed",
+      "type": "aws_security_group",
+      "name": "unchanged",
+      "change": {"actions": ["no-op"], "before": {}, "after": {}}
+    },
+    {
+      "address": "aws_lb.new",
+      "mode": "managed",
+      "type": "aws_lb",
+      "name": "new",
+      "change": {"actions": ["create"], "before": null, "after": {"name": "lb"}}
+    }
+  ]
+}
+`
+
+var costTestPriceTable = PriceTable{
+	"aws_instance":    {SizeAttribute: "instance_type", HourlyPrices: map[string]float64{"t3.micro": 0.01, "t3.small": 0.02, "m5.large": 0.1}},
+	"aws_nat_gateway": {MonthlyPrice: 30},
+}
+
+func TestEstimatePlanCost(t *testing.T) {
+	t.Parallel()
+
+	plan, err := parsePlanJson(costTestPlanJson)
+	require.NoError(t, err)
+
+	estimate := EstimatePlanCost(t, plan, costTestPriceTable)
+
+	// +73 for the new m5.large, +7.3 for the resize and -30 for the deleted NAT gateway
+	assert.InDelta(t, 50.3, estimate.MonthlyDelta, 0.001)
+	assert.Equal(t, []string{"aws_lb.new"}, estimate.Unpriced)
+	require.Len(t, estimate.Changes, 3)
+	assert.Equal(t, "aws_instance.new", estimate.Changes[0].Address)
+	assert.InDelta(t, 73, estimate.Changes[0].CostAfter, 0.001)
+	assert.InDelta(t, 7.3, estimate.Changes[1].CostBefore, 0.001)
+	assert.InDelta(t, -30, estimate.Changes[2].Delta(), 0.001)
+}
+
+func TestCheckPlanCost(t *testing.T) {
+	t.Parallel()
+
+	plan, err := parsePlanJson(costTestPlanJson)
+	require.NoError(t, err)
+
+	_, err = CheckPlanCostE(t, plan, PlanCostGateOptions{Source: costTestPriceTable, MaxMonthlyDelta: 100})
+	assert.NoError(t, err)
+
+	_, err = CheckPlanCostE(t, plan, PlanCostGateOptions{Source: costTestPriceTable, MaxMonthlyDelta: 50})
+	assert.IsType(t, PlanCostThresholdExceeded{}, err)
+
+	_, err = CheckPlanCostE(t, plan, PlanCostGateOptions{Source: costTestPriceTable, MaxMonthlyDelta: 50, WarnOnly: true})
+	assert.NoError(t, err)
+
+	_, err = CheckPlanCostE(t, plan, PlanCostGateOptions{Source: costTestPriceTable, MaxMonthlyDelta: 100, FailOnUnpriced: true})
+	assert.IsType(t, UnpricedResourcesInPlan{}, err)
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	t.Parallel()
+
+	file, err := ioutil.TempFile("", "prices*.json")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{"aws_instance": {"size_attribute": "instance_type", "hourly_prices": {"t3.micro": 0.0104}}, "aws_eip": {"monthly_price": 3.6}}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	table := LoadPriceTable(t, file.Name())
+
+	cost, known, err := table.MonthlyCost("aws_instance", map[string]interface{}{"instance_type": "t3.micro"})
+	require.NoError(t, err)
+	assert.True(t, known)
+	assert.InDelta(t, 7.592, cost, 0.001)
+
+	_, known, err = table.MonthlyCost("aws_instance", map[string]interface{}{"instance_type": "x1.32xlarge"})
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	cost, known, _ = table.MonthlyCost("aws_eip", nil)
+	assert.True(t, known)
+	assert.Equal(t, 3.6, cost)
+}
@@ -0,0 +1,266 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/collections"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// DependencyLockFileName is the name of the file terraform init records the selected provider versions in.
+	DependencyLockFileName = ".terraform.lock.hcl"
+
+	// DefaultRegistryHost is the registry providers and modules come from when their source has no host.
+	DefaultRegistryHost = "registry.terraform.io"
+)
+
+// ModuleSourceType is the kind of location a module is installed from.
+type ModuleSourceType string
+
+// The kinds of module sources the dependency report distinguishes.
+const (
+	ModuleSourceLocal    ModuleSourceType = "local"
+	ModuleSourceRegistry ModuleSourceType = "registry"
+	ModuleSourceGit      ModuleSourceType = "git"
+	ModuleSourceOther    ModuleSourceType = "other"
+)
+
+// exactVersionConstraintRegexp matches a version constraint that allows a single version, e.g. "3.50.0" or "= 3.50.0".
+var exactVersionConstraintRegexp = regexp.MustCompile(`^=?\s*v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// ProviderDependency is a provider recorded in the dependency lock file.
+type ProviderDependency struct {
+	Source      string // The full source address, e.g. registry.terraform.io/hashicorp/aws
+	Version     string // The selected version
+	Constraints string // The version constraints of the configuration, if any
+}
+
+// Registry returns the host of the registry the provider comes from.
+func (provider ProviderDependency) Registry() string {
+	return strings.SplitN(provider.Source, "/", 2)[0]
+}
+
+// ModuleDependency is a module recorded in the module manifest that terraform init writes.
+type ModuleDependency struct {
+	Key     string // The path of the module in the configuration, e.g. "vpc" or "vpc.subnets"
+	Source  string // The source address of the module
+	Version string // The selected version, for registry modules
+	Dir     string // The directory the module was installed into
+}
+
+// SourceType returns the kind of location the module is installed from.
+func (module ModuleDependency) SourceType() ModuleSourceType {
+	return getModuleSourceType(module.Source)
+}
+
+// Registry returns the host of the registry the module comes from, or an empty string if it is not a registry module.
+func (module ModuleDependency) Registry() string {
+	if module.SourceType() != ModuleSourceRegistry {
+		return ""
+	}
+	if parts := strings.Split(strings.SplitN(module.Source, "//", 2)[0], "/"); len(parts) == 4 {
+		return parts[0]
+	}
+	return DefaultRegistryHost
+}
+
+// DependencyReport lists the providers and modules a Terraform configuration uses.
+type DependencyReport struct {
+	Providers []ProviderDependency
+	Modules   []ModuleDependency
+}
+
+// DependencyPolicy restricts the providers and modules a configuration may use. The zero value allows everything.
+type DependencyPolicy struct {
+	AllowedRegistries     []string // The registry hosts providers and registry modules may come from. Empty allows any.
+	DenyGitModules        bool     // Reject modules installed from git repositories
+	DenyOtherModules      bool     // Reject modules installed from other remote sources, such as HTTP, S3 and GCS
+	RequirePinnedVersions bool     // Reject providers whose constraints allow more than one version, and git modules without a ref. The manifest does not record the constraints of registry modules, so they are not checked.
+}
+
+// lockFile is the structure of the dependency lock file.
+type lockFile struct {
+	Providers []struct {
+		Source      string   `hcl:"source,label"`
+		Version     string   `hcl:"version"`
+		Constraints string   `hcl:"constraints,optional"`
+		Hashes      []string `hcl:"hashes,optional"`
+	} `hcl:"provider,block"`
+}
+
+// moduleManifest is the structure of the module manifest.
+type moduleManifest struct {
+	Modules []ModuleDependency `json:"Modules"`
+}
+
+// GetDependencyReport reads the dependency lock file and module manifest in options.TerraformDir and returns the
+// providers and modules the configuration uses. terraform init must have been run. This will fail the test if there
+// is an error.
+func GetDependencyReport(t testing.TestingT, options *Options) *DependencyReport {
+	report, err := GetDependencyReportE(t, options)
+	require.NoError(t, err)
+	return report
+}
+
+// GetDependencyReportE reads the dependency lock file and module manifest in options.TerraformDir and returns the
+// providers and modules the configuration uses. terraform init must have been run.
+func GetDependencyReportE(t testing.TestingT, options *Options) (*DependencyReport, error) {
+	providers, err := ParseDependencyLockFileE(filepath.Join(options.TerraformDir, DependencyLockFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := parseModuleManifestE(filepath.Join(options.TerraformDir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DependencyReport{Providers: providers, Modules: modules}, nil
+}
+
+// ParseDependencyLockFileE parses the given dependency lock file and returns the providers in it, sorted by source.
+func ParseDependencyLockFileE(path string) ([]ProviderDependency, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, diags := hclparse.NewParser().ParseHCL(contents, path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var parsed lockFile
+	if diags := gohcl.DecodeBody(file.Body, &hcl.EvalContext{}, &parsed); diags.HasErrors() {
+		return nil, diags
+	}
+
+	providers := []ProviderDependency{}
+	for _, provider := range parsed.Providers {
+		providers = append(providers, ProviderDependency{
+			Source:      provider.Source,
+			Version:     provider.Version,
+			Constraints: provider.Constraints,
+		})
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Source < providers[j].Source })
+	return providers, nil
+}
+
+// CheckDependencyPolicy checks the providers and modules in options.TerraformDir against the given policy, and fails
+// the test with the list of violations if there are any.
+func CheckDependencyPolicy(t testing.TestingT, options *Options, policy DependencyPolicy) {
+	require.NoError(t, CheckDependencyPolicyE(t, options, policy))
+}
+
+// CheckDependencyPolicyE checks the providers and modules in options.TerraformDir against the given policy, and
+// returns a DependencyPolicyViolations error listing the violations if there are any.
+func CheckDependencyPolicyE(t testing.TestingT, options *Options, policy DependencyPolicy) error {
+	report, err := GetDependencyReportE(t, options)
+	if err != nil {
+		return err
+	}
+
+	violations := report.Violations(policy)
+	for _, violation := range violations {
+		options.Logger.Logf(t, "Dependency policy violation: %s", violation)
+	}
+	if len(violations) > 0 {
+		return DependencyPolicyViolations(violations)
+	}
+	return nil
+}
+
+// Violations returns a description of each provider and module in the report that the given policy does not allow.
+func (report *DependencyReport) Violations(policy DependencyPolicy) []string {
+	violations := []string{}
+
+	for _, provider := range report.Providers {
+		if len(policy.AllowedRegistries) > 0 && !collections.ListContains(policy.AllowedRegistries, provider.Registry()) {
+			violations = append(violations, fmt.Sprintf("provider %s comes from a registry that is not allowed", provider.Source))
+		}
+		if policy.RequirePinnedVersions && !exactVersionConstraintRegexp.MatchString(strings.TrimSpace(provider.Constraints)) {
+			violations = append(violations, fmt.Sprintf("provider %s has version constraints %q instead of a pinned version", provider.Source, provider.Constraints))
+		}
+	}
+
+	for _, module := range report.Modules {
+		switch module.SourceType() {
+		case ModuleSourceRegistry:
+			if len(policy.AllowedRegistries) > 0 && !collections.ListContains(policy.AllowedRegistries, module.Registry()) {
+				violations = append(violations, fmt.Sprintf("module %s comes from registry %s, which is not allowed", module.Key, module.Registry()))
+			}
+		case ModuleSourceGit:
+			if policy.DenyGitModules {
+				violations = append(violations, fmt.Sprintf("module %s comes from git source %s", module.Key, module.Source))
+			} else if policy.RequirePinnedVersions && !strings.Contains(module.Source, "ref=") {
+				violations = append(violations, fmt.Sprintf("module %s comes from git source %s without a ref", module.Key, module.Source))
+			}
+		case ModuleSourceOther:
+			if policy.DenyOtherModules {
+				violations = append(violations, fmt.Sprintf("module %s comes from remote source %s", module.Key, module.Source))
+			}
+		}
+	}
+
+	return violations
+}
+
+// parseModuleManifestE parses the given module manifest and returns the modules in it, sorted by key and without the
+// root module. A missing manifest means the configuration uses no modules.
+func parseModuleManifestE(path string) ([]ModuleDependency, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []ModuleDependency{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest moduleManifest
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return nil, err
+	}
+
+	modules := []ModuleDependency{}
+	for _, module := range manifest.Modules {
+		if module.Key != "" {
+			modules = append(modules, module)
+		}
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Key < modules[j].Key })
+	return modules, nil
+}
+
+// getModuleSourceType returns the kind of location the given module source address refers to, following the rules
+// Terraform uses to interpret them.
+func getModuleSourceType(source string) ModuleSourceType {
+	switch {
+	case strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../"):
+		return ModuleSourceLocal
+	case strings.HasPrefix(source, "git::") || strings.HasPrefix(source, "git@") ||
+		strings.HasPrefix(source, "github.com/") || strings.HasPrefix(source, "bitbucket.org/"):
+		return ModuleSourceGit
+	case strings.Contains(source, "::") || strings.Contains(source, "://"):
+		return ModuleSourceOther
+	}
+
+	// Registry addresses are [<HOSTNAME>/]<NAMESPACE>/<NAME>/<PROVIDER>, optionally followed by //<SUBDIRECTORY>
+	parts := strings.Split(strings.SplitN(source, "//", 2)[0], "/")
+	if len(parts) == 3 || (len(parts) == 4 && strings.Contains(parts[0], ".")) {
+		return ModuleSourceRegistry
+	}
+	return ModuleSourceOther
+}
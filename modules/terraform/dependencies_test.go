@@ -0,0 +1,124 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLockFile = `
+# This file is maintained automatically by "terraform init".
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "3.50.0"
+  constraints = "~> 3.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version     = "3.1.0"
+  constraints = "3.1.0"
+}
+
+provider "example.com/acme/internal" {
+  version = "1.0.0"
+}
+`
+
+const testModuleManifest = `{"Modules":[
+	{"Key":"","Source":"","Dir":"."},
+	{"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"3.2.0","Dir":".terraform/modules/vpc"},
+	{"Key":"iam","Source":"terraform-aws-modules/iam/aws//modules/iam-role","Version":"4.1.0","Dir":".terraform/modules/iam/modules/iam-role"},
+	{"Key":"network","Source":"git::https://github.com/acme/network.git?ref=v1.2.0","Dir":".terraform/modules/network"},
+	{"Key":"legacy","Source":"github.com/acme/legacy","Dir":".terraform/modules/legacy"},
+	{"Key":"artifacts","Source":"s3::https://s3.amazonaws.com/acme/module.zip","Dir":".terraform/modules/artifacts"},
+	{"Key":"vpc.subnets","Source":"./modules/subnets","Dir":".terraform/modules/vpc/modules/subnets"}
+]}`
+
+func TestGetDependencyReport(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{TerraformDir: writeTestDependencyFiles(t)}
+	report := GetDependencyReport(t, options)
+
+	require.Len(t, report.Providers, 3)
+	assert.Equal(t, ProviderDependency{Source: "example.com/acme/internal", Version: "1.0.0"}, report.Providers[0])
+	assert.Equal(t, "registry.terraform.io", report.Providers[1].Registry())
+	assert.Equal(t, "~> 3.0", report.Providers[1].Constraints)
+
+	keys := []string{}
+	for _, module := range report.Modules {
+		keys = append(keys, module.Key)
+	}
+	assert.Equal(t, []string{"artifacts", "iam", "legacy", "network", "vpc", "vpc.subnets"}, keys)
+	assert.Equal(t, ModuleSourceOther, report.Modules[0].SourceType())
+	assert.Equal(t, DefaultRegistryHost, report.Modules[1].Registry())
+	assert.Equal(t, ModuleSourceGit, report.Modules[2].SourceType())
+	assert.Equal(t, ModuleSourceLocal, report.Modules[5].SourceType())
+}
+
+func TestDependencyReportViolations(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{TerraformDir: writeTestDependencyFiles(t)}
+	report := GetDependencyReport(t, options)
+
+	assert.Empty(t, report.Violations(DependencyPolicy{}))
+
+	violations := report.Violations(DependencyPolicy{
+		AllowedRegistries:     []string{DefaultRegistryHost},
+		DenyGitModules:        true,
+		DenyOtherModules:      true,
+		RequirePinnedVersions: true,
+	})
+	assert.Equal(t, []string{
+		`provider example.com/acme/internal comes from a registry that is not allowed`,
+		`provider example.com/acme/internal has version constraints "" instead of a pinned version`,
+		`provider registry.terraform.io/hashicorp/aws has version constraints "~> 3.0" instead of a pinned version`,
+		`module artifacts comes from remote source s3::https://s3.amazonaws.com/acme/module.zip`,
+		`module legacy comes from git source github.com/acme/legacy`,
+		`module network comes from git source git::https://github.com/acme/network.git?ref=v1.2.0`,
+	}, violations)
+
+	err := CheckDependencyPolicyE(t, options, DependencyPolicy{RequirePinnedVersions: true, AllowedRegistries: []string{DefaultRegistryHost, "example.com"}})
+	require.Error(t, err)
+	assert.Equal(t, DependencyPolicyViolations{
+		`provider example.com/acme/internal has version constraints "" instead of a pinned version`,
+		`provider registry.terraform.io/hashicorp/aws has version constraints "~> 3.0" instead of a pinned version`,
+		`module legacy comes from git source github.com/acme/legacy without a ref`,
+	}, err)
+}
+
+func TestGetModuleSourceType(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]ModuleSourceType{
+		"./modules/vpc":                           ModuleSourceLocal,
+		"../shared":                               ModuleSourceLocal,
+		"hashicorp/consul/aws":                    ModuleSourceRegistry,
+		"app.terraform.io/acme/vpc/aws":           ModuleSourceRegistry,
+		"git@github.com:acme/vpc.git":             ModuleSourceGit,
+		"bitbucket.org/acme/vpc":                  ModuleSourceGit,
+		"https://example.com/vpc-module.zip":      ModuleSourceOther,
+		"gcs::https://www.googleapis.com/b/m.zip": ModuleSourceOther,
+	}
+	for source, expected := range testCases {
+		assert.Equal(t, expected, getModuleSourceType(source), source)
+	}
+}
+
+func writeTestDependencyFiles(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "terratest-dependencies")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".terraform", "modules"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, DependencyLockFileName), []byte(testLockFile), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".terraform", "modules", "modules.json"), []byte(testModuleManifest), 0644))
+	return dir
+}
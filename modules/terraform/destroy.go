@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -21,6 +22,10 @@ func TgDestroyAll(t testing.TestingT, options *Options) string {
 
 // DestroyE runs terraform destroy with the given options and return stdout/stderr.
 func DestroyE(t testing.TestingT, options *Options) (string, error) {
+	if dryrun.Skip(t, "run terraform destroy in %s", options.TerraformDir) {
+		return "", nil
+	}
+
 	return RunTerraformCommandE(t, options, FormatArgs(options, "destroy", "-auto-approve", "-input=false")...)
 }
 
@@ -30,5 +35,9 @@ func TgDestroyAllE(t testing.TestingT, options *Options) (string, error) {
 		return "", TgInvalidBinary(options.TerraformBinary)
 	}
 
+	if dryrun.Skip(t, "run terragrunt run-all destroy in %s", options.TerraformDir) {
+		return "", nil
+	}
+
 	return RunTerraformCommandE(t, options, FormatArgs(options, "run-all", "destroy", "-auto-approve", "-input=false")...)
 }
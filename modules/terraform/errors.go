@@ -1,8 +1,28 @@
 package terraform
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+)
+
+// The kinds of errors returned by the functions in this package. Every typed error of this package matches the kind it
+// belongs to with errors.Is, so tests and retry logic can branch on the kind of an error rather than on its message,
+// e.g. errors.Is(err, terraform.ErrPlanDiff).
+var (
+	// ErrPlanDiff is returned when a plan that should be empty, e.g. right after an apply, has changes.
+	ErrPlanDiff = errors.New("terraform configuration not idempotent")
+
+	// ErrNotFound is the kind of the errors returned when an output, var file, variable or workspace does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnexpectedOutputType is the kind of the errors returned when an output is not of the requested type.
+	ErrUnexpectedOutputType = errors.New("unexpected output type")
+
+	// ErrPolicyViolation is the kind of the errors returned when a configuration or its plan violates a policy, e.g. a
+	// cost threshold or a dependency policy.
+	ErrPolicyViolation = errors.New("policy violation")
 )
 
 // TgInvalidBinary occurs when a terragrunt function is called and the TerraformBinary is
@@ -21,6 +41,11 @@ func (err OutputKeyNotFound) Error() string {
 	return fmt.Sprintf("output doesn't contain a value for the key %q", string(err))
 }
 
+// Is returns true if the target is ErrNotFound.
+func (err OutputKeyNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // OutputValueNotMap occures when casting a found output value to a map fails
 type OutputValueNotMap struct {
 	Value interface{}
@@ -30,6 +55,11 @@ func (err OutputValueNotMap) Error() string {
 	return fmt.Sprintf("Output value %q is not a map", err.Value)
 }
 
+// Is returns true if the target is ErrUnexpectedOutputType.
+func (err OutputValueNotMap) Is(target error) bool {
+	return target == ErrUnexpectedOutputType
+}
+
 // OutputValueNotList occurs when casting a found output value to a
 // list of interfaces fails
 type OutputValueNotList struct {
@@ -40,6 +70,11 @@ func (err OutputValueNotList) Error() string {
 	return fmt.Sprintf("Output value %q is not a list", err.Value)
 }
 
+// Is returns true if the target is ErrUnexpectedOutputType.
+func (err OutputValueNotList) Is(target error) bool {
+	return target == ErrUnexpectedOutputType
+}
+
 // EmptyOutput is an error that occurs when an output is empty.
 type EmptyOutput string
 
@@ -47,6 +82,19 @@ func (outputName EmptyOutput) Error() string {
 	return fmt.Sprintf("Required output %s was empty", string(outputName))
 }
 
+// SensitiveOutput is an error that occurs when outputs marked sensitive are requested through a function that would log
+// them while options.FailOnSensitiveOutput is set.
+type SensitiveOutput []string
+
+func (outputNames SensitiveOutput) Error() string {
+	return fmt.Sprintf("Outputs %v are marked sensitive and would be logged. Use OutputSensitive to read them.", []string(outputNames))
+}
+
+// Is returns true if the target is ErrPolicyViolation.
+func (outputNames SensitiveOutput) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
 // UnexpectedOutputType is an error that occurs when the output is not of the type we expect
 type UnexpectedOutputType struct {
 	Key          string
@@ -58,6 +106,11 @@ func (err UnexpectedOutputType) Error() string {
 	return fmt.Sprintf("Expected output '%s' to be of type '%s' but got '%s'", err.Key, err.ExpectedType, err.ActualType)
 }
 
+// Is returns true if the target is ErrUnexpectedOutputType.
+func (err UnexpectedOutputType) Is(target error) bool {
+	return target == ErrUnexpectedOutputType
+}
+
 // VarFileNotFound is an error that occurs when a var file cannot be found in an option's VarFile list
 type VarFileNotFound struct {
 	Path string
@@ -67,6 +120,11 @@ func (err VarFileNotFound) Error() string {
 	return fmt.Sprintf("Var file '%s' not found", err.Path)
 }
 
+// Is returns true if the target is ErrNotFound.
+func (err VarFileNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // InputFileKeyNotFound occurs when tfvar file does not contain a value for the key
 // specified in the function call
 type InputFileKeyNotFound struct {
@@ -78,6 +136,11 @@ func (err InputFileKeyNotFound) Error() string {
 	return fmt.Sprintf("tfvar file %q doesn't contain a value for the key %q", err.FilePath, err.Key)
 }
 
+// Is returns true if the target is ErrNotFound.
+func (err InputFileKeyNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // PanicWhileParsingVarFile is returned when the HCL parsing routine panics due to errors.
 type PanicWhileParsingVarFile struct {
 	ConfigFile     string
@@ -101,3 +164,63 @@ type WorkspaceDoesNotExist string
 func (err WorkspaceDoesNotExist) Error() string {
 	return fmt.Sprintf("The workspace %q does not exist.", string(err))
 }
+
+// Is returns true if the target is ErrNotFound.
+func (err WorkspaceDoesNotExist) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// RemoteStateBackendNotSet is returned when the producer of a remote state stack has a backend config, but the stack
+// does not say which backend it is for.
+type RemoteStateBackendNotSet struct {
+	ProducerDir string
+}
+
+func (err RemoteStateBackendNotSet) Error() string {
+	return fmt.Sprintf("The producer in %s has a backend config, but no Backend is set on the remote state stack", err.ProducerDir)
+}
+
+// PlanCostThresholdExceeded is returned when the estimated monthly cost change of a plan is above the allowed
+// threshold.
+type PlanCostThresholdExceeded struct {
+	MonthlyDelta    float64
+	MaxMonthlyDelta float64
+	Estimate        *PlanCostEstimate
+}
+
+func (err PlanCostThresholdExceeded) Error() string {
+	return fmt.Sprintf("Plan increases the estimated monthly cost by %.2f, which is above the allowed %.2f:\n%s", err.MonthlyDelta, err.MaxMonthlyDelta, err.Estimate)
+}
+
+// Is returns true if the target is ErrPolicyViolation.
+func (err PlanCostThresholdExceeded) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// UnpricedResourcesInPlan is returned when the plan changes resources the pricing source has no price for, and the
+// cost gate is configured to not ignore them.
+type UnpricedResourcesInPlan struct {
+	Addresses []string
+}
+
+func (err UnpricedResourcesInPlan) Error() string {
+	return fmt.Sprintf("No price is known for the following resources in the plan: %v", err.Addresses)
+}
+
+// Is returns true if the target is ErrPolicyViolation.
+func (err UnpricedResourcesInPlan) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// DependencyPolicyViolations is an error that occurs when a configuration uses providers or modules that a dependency
+// policy does not allow.
+type DependencyPolicyViolations []string
+
+func (violations DependencyPolicyViolations) Error() string {
+	return fmt.Sprintf("Found %d dependency policy violations:\n%s", len(violations), strings.Join(violations, "\n"))
+}
+
+// Is returns true if the target is ErrPolicyViolation.
+func (violations DependencyPolicyViolations) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
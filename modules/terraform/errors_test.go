@@ -0,0 +1,22 @@
+package terraform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, errors.Is(OutputKeyNotFound("vpc_id"), ErrNotFound))
+	assert.True(t, errors.Is(WorkspaceDoesNotExist("staging"), ErrNotFound))
+	assert.True(t, errors.Is(UnexpectedOutputType{Key: "ids", ExpectedType: "list", ActualType: "string"}, ErrUnexpectedOutputType))
+	assert.True(t, errors.Is(DependencyPolicyViolations{"module foo uses a git source"}, ErrPolicyViolation))
+	assert.False(t, errors.Is(OutputKeyNotFound("vpc_id"), ErrPolicyViolation))
+
+	// The kind is preserved when the error is wrapped in a FatalError
+	assert.True(t, errors.Is(retry.FatalError{Underlying: SensitiveOutput{"password"}}, ErrPolicyViolation))
+}
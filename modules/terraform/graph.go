@@ -0,0 +1,322 @@
+package terraform
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// graphEdgeRegexp matches an edge in the DOT output of terraform graph, e.g. "[root] a (expand)" -> "[root] b".
+	graphEdgeRegexp = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"\s*->\s*"((?:[^"\\]|\\.)*)"`)
+
+	// graphNodeRegexp matches a node declaration in the DOT output of terraform graph, e.g. "[root] a" [label = "a"].
+	graphNodeRegexp = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"\s*\[`)
+
+	// graphNodeSuffixRegexp matches the suffix Terraform adds to the nodes that expand a resource or module.
+	graphNodeSuffixRegexp = regexp.MustCompile(` \(expand\)$`)
+)
+
+// ResourceGraph is the dependency graph of a Terraform module, as returned by terraform graph. The nodes are addresses
+// such as "aws_instance.web", "module.vpc.aws_vpc.main", "var.region" and "provider[\"registry.terraform.io/hashicorp/aws\"]",
+// and an edge from A to B means that A depends on B.
+type ResourceGraph struct {
+	dependencies map[string]map[string]bool
+	dependents   map[string]map[string]bool
+}
+
+// Graph calls terraform graph with the given options and returns the parsed dependency graph. If PlanFilePath is set on
+// the options, this will graph the plan file. This will fail the test if there is an error.
+func Graph(t testing.TestingT, options *Options) *ResourceGraph {
+	graph, err := GraphE(t, options)
+	require.NoError(t, err)
+	return graph
+}
+
+// GraphE calls terraform graph with the given options and returns the parsed dependency graph. If PlanFilePath is set
+// on the options, this will graph the plan file.
+func GraphE(t testing.TestingT, options *Options) (*ResourceGraph, error) {
+	// We manually construct the args here instead of using `FormatArgs`, because graph does not accept vars.
+	args := []string{"graph"}
+	if options.PlanFilePath != "" {
+		args = append(args, "-plan="+options.PlanFilePath)
+	}
+
+	out, err := RunTerraformCommandAndGetStdoutE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGraph(out), nil
+}
+
+// ParseGraph parses the DOT output of terraform graph into a ResourceGraph. The "[root] " prefix and " (expand)" suffix
+// are stripped from the node names, and the nodes Terraform adds that do not correspond to anything in the
+// configuration (such as "root", "meta.count-boundary" and the " (close)" nodes of modules and providers) are dropped.
+func ParseGraph(dot string) *ResourceGraph {
+	graph := &ResourceGraph{
+		dependencies: map[string]map[string]bool{},
+		dependents:   map[string]map[string]bool{},
+	}
+
+	for _, line := range strings.Split(dot, "\n") {
+		if match := graphEdgeRegexp.FindStringSubmatch(line); match != nil {
+			// The close nodes of modules and providers depend on everything in them, which would look like cycles
+			if isCloseGraphNode(match[1]) || isCloseGraphNode(match[2]) {
+				continue
+			}
+			from, to := normalizeGraphNode(match[1]), normalizeGraphNode(match[2])
+			if isMetaGraphNode(from) || isMetaGraphNode(to) {
+				continue
+			}
+			graph.addNode(from)
+			graph.addNode(to)
+			graph.dependencies[from][to] = true
+			graph.dependents[to][from] = true
+		} else if match := graphNodeRegexp.FindStringSubmatch(line); match != nil {
+			if node := normalizeGraphNode(match[1]); !isCloseGraphNode(match[1]) && !isMetaGraphNode(node) {
+				graph.addNode(node)
+			}
+		}
+	}
+
+	return graph
+}
+
+// Nodes returns the sorted addresses of all the nodes in the graph.
+func (graph *ResourceGraph) Nodes() []string {
+	return sortedKeys(graph.dependencies)
+}
+
+// HasNode returns true if the graph contains the given address.
+func (graph *ResourceGraph) HasNode(node string) bool {
+	_, ok := graph.dependencies[node]
+	return ok
+}
+
+// DirectDependencies returns the sorted addresses the given node depends on directly.
+func (graph *ResourceGraph) DirectDependencies(node string) []string {
+	return sortedSet(graph.dependencies[node])
+}
+
+// DirectDependents returns the sorted addresses that depend directly on the given node.
+func (graph *ResourceGraph) DirectDependents(node string) []string {
+	return sortedSet(graph.dependents[node])
+}
+
+// Dependencies returns the sorted addresses the given node depends on, directly or transitively.
+func (graph *ResourceGraph) Dependencies(node string) []string {
+	return sortedSet(reachable(graph.dependencies, node))
+}
+
+// Dependents returns the sorted addresses that depend on the given node, directly or transitively.
+func (graph *ResourceGraph) Dependents(node string) []string {
+	return sortedSet(reachable(graph.dependents, node))
+}
+
+// DependsOn returns true if the from node depends on the to node, directly or transitively.
+func (graph *ResourceGraph) DependsOn(from string, to string) bool {
+	return len(graph.DependencyPath(from, to)) > 0
+}
+
+// DependencyPath returns a shortest chain of dependencies from the from node to the to node, including both, or nil
+// if from does not depend on to.
+func (graph *ResourceGraph) DependencyPath(from string, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, next := range graph.DirectDependencies(node) {
+			if _, seen := previous[next]; seen {
+				continue
+			}
+			previous[next] = node
+			if next == to {
+				path := []string{to}
+				for step := node; step != ""; step = previous[step] {
+					path = append([]string{step}, path...)
+				}
+				return path
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil
+}
+
+// DependentsOutsideModule returns the sorted addresses outside the given module (e.g. "module.kms") that depend on the
+// given node, directly or transitively. Use it to assert that nothing outside a module depends on one of its
+// resources.
+func (graph *ResourceGraph) DependentsOutsideModule(node string, module string) []string {
+	outside := []string{}
+	for _, dependent := range graph.Dependents(node) {
+		if !IsInModule(dependent, module) {
+			outside = append(outside, dependent)
+		}
+	}
+	return outside
+}
+
+// Orphans returns the sorted addresses of the nodes that neither depend on nor are depended on by any other node.
+func (graph *ResourceGraph) Orphans() []string {
+	orphans := []string{}
+	for _, node := range graph.Nodes() {
+		if len(withoutNode(graph.dependencies[node], node)) == 0 && len(withoutNode(graph.dependents[node], node)) == 0 {
+			orphans = append(orphans, node)
+		}
+	}
+	return orphans
+}
+
+// Cycles returns the dependency cycles in the graph, each as the sorted addresses of the nodes in it. Terraform refuses
+// to apply a module with a cycle, so this is mostly useful to explain one across modules.
+func (graph *ResourceGraph) Cycles() [][]string {
+	// Tarjan's strongly connected components algorithm
+	index := 0
+	indexes := map[string]int{}
+	lowLinks := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	cycles := [][]string{}
+
+	var visit func(node string)
+	visit = func(node string) {
+		indexes[node] = index
+		lowLinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range graph.DirectDependencies(node) {
+			if _, visited := indexes[next]; !visited {
+				visit(next)
+				lowLinks[node] = minInt(lowLinks[node], lowLinks[next])
+			} else if onStack[next] {
+				lowLinks[node] = minInt(lowLinks[node], indexes[next])
+			}
+		}
+
+		if lowLinks[node] != indexes[node] {
+			return
+		}
+
+		component := []string{}
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == node {
+				break
+			}
+		}
+		if len(component) > 1 || graph.dependencies[node][node] {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, node := range graph.Nodes() {
+		if _, visited := indexes[node]; !visited {
+			visit(node)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// IsInModule returns true if the given address belongs to the given module (e.g. "module.vpc") or one of its child
+// modules.
+func IsInModule(address string, module string) bool {
+	return strings.HasPrefix(address, strings.TrimSuffix(module, ".")+".")
+}
+
+// addNode adds the given node to the graph if it is not there yet.
+func (graph *ResourceGraph) addNode(node string) {
+	if _, ok := graph.dependencies[node]; !ok {
+		graph.dependencies[node] = map[string]bool{}
+		graph.dependents[node] = map[string]bool{}
+	}
+}
+
+// normalizeGraphNode strips the "[root] " prefix and " (expand)" suffix from a node name in the output of
+// terraform graph, and unescapes its quotes.
+func normalizeGraphNode(name string) string {
+	name = strings.ReplaceAll(name, `\"`, `"`)
+	name = strings.TrimPrefix(name, "[root] ")
+	return graphNodeSuffixRegexp.ReplaceAllString(name, "")
+}
+
+// isCloseGraphNode returns true if the given raw node name is the node that closes a module or provider.
+func isCloseGraphNode(name string) bool {
+	return strings.HasSuffix(name, " (close)")
+}
+
+// isMetaGraphNode returns true for the nodes Terraform adds to the graph that do not correspond to anything in the
+// configuration.
+func isMetaGraphNode(node string) bool {
+	return node == "root" || strings.HasPrefix(node, "meta.")
+}
+
+// reachable returns the nodes reachable from the given node by following the given edges, excluding the node itself
+// unless it is part of a cycle.
+func reachable(edges map[string]map[string]bool, node string) map[string]bool {
+	seen := map[string]bool{}
+	queue := []string{node}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for next := range edges[current] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen
+}
+
+// withoutNode returns the given set of nodes without the given node.
+func withoutNode(set map[string]bool, node string) map[string]bool {
+	result := map[string]bool{}
+	for key := range set {
+		if key != node {
+			result[key] = true
+		}
+	}
+	return result
+}
+
+// sortedSet returns the sorted members of the given set.
+func sortedSet(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedKeys returns the sorted keys of the given adjacency map.
+func sortedKeys(edges map[string]map[string]bool) []string {
+	keys := []string{}
+	for key := range edges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
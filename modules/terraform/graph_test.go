@@ -0,0 +1,95 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGraphDot = `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] aws_instance.web (expand)" [label = "aws_instance.web", shape = "box"]
+		"[root] module.kms.aws_kms_key.main (expand)" [label = "module.kms.aws_kms_key.main", shape = "box"]
+		"[root] module.kms.aws_kms_alias.main (expand)" [label = "module.kms.aws_kms_alias.main", shape = "box"]
+		"[root] aws_s3_bucket.logs (expand)" [label = "aws_s3_bucket.logs", shape = "box"]
+		"[root] null_resource.unused (expand)" [label = "null_resource.unused", shape = "box"]
+		"[root] provider[\"registry.terraform.io/hashicorp/aws\"]" [label = "provider[\"registry.terraform.io/hashicorp/aws\"]", shape = "diamond"]
+		"[root] aws_instance.web (expand)" -> "[root] aws_s3_bucket.logs (expand)"
+		"[root] aws_instance.web (expand)" -> "[root] provider[\"registry.terraform.io/hashicorp/aws\"]"
+		"[root] aws_s3_bucket.logs (expand)" -> "[root] module.kms.aws_kms_key.main (expand)"
+		"[root] module.kms.aws_kms_alias.main (expand)" -> "[root] module.kms.aws_kms_key.main (expand)"
+		"[root] module.kms.aws_kms_key.main (expand)" -> "[root] provider[\"registry.terraform.io/hashicorp/aws\"]"
+		"[root] meta.count-boundary (EachMode fixup)" -> "[root] aws_instance.web (expand)"
+		"[root] module.kms (close)" -> "[root] module.kms.aws_kms_alias.main (expand)"
+		"[root] provider[\"registry.terraform.io/hashicorp/aws\"] (close)" -> "[root] aws_instance.web (expand)"
+		"[root] root" -> "[root] module.kms (close)"
+	}
+}
+`
+
+func TestParseGraph(t *testing.T) {
+	t.Parallel()
+
+	graph := ParseGraph(testGraphDot)
+	provider := `provider["registry.terraform.io/hashicorp/aws"]`
+
+	assert.Equal(t, []string{
+		"aws_instance.web",
+		"aws_s3_bucket.logs",
+		"module.kms.aws_kms_alias.main",
+		"module.kms.aws_kms_key.main",
+		"null_resource.unused",
+		provider,
+	}, graph.Nodes())
+	assert.Equal(t, []string{"aws_s3_bucket.logs", provider}, graph.DirectDependencies("aws_instance.web"))
+	assert.Equal(t, []string{"aws_s3_bucket.logs", "module.kms.aws_kms_key.main", provider}, graph.Dependencies("aws_instance.web"))
+	assert.Empty(t, graph.Cycles())
+}
+
+func TestResourceGraphDependencyPath(t *testing.T) {
+	t.Parallel()
+
+	graph := ParseGraph(testGraphDot)
+
+	assert.True(t, graph.DependsOn("aws_instance.web", "module.kms.aws_kms_key.main"))
+	assert.Equal(t, []string{"aws_instance.web", "aws_s3_bucket.logs", "module.kms.aws_kms_key.main"}, graph.DependencyPath("aws_instance.web", "module.kms.aws_kms_key.main"))
+	assert.False(t, graph.DependsOn("module.kms.aws_kms_key.main", "aws_instance.web"))
+	assert.Nil(t, graph.DependencyPath("module.kms.aws_kms_key.main", "aws_instance.web"))
+}
+
+func TestResourceGraphDependentsOutsideModule(t *testing.T) {
+	t.Parallel()
+
+	graph := ParseGraph(testGraphDot)
+
+	assert.Equal(t, []string{"aws_instance.web", "aws_s3_bucket.logs"}, graph.DependentsOutsideModule("module.kms.aws_kms_key.main", "module.kms"))
+	assert.Empty(t, graph.DependentsOutsideModule("module.kms.aws_kms_alias.main", "module.kms"))
+	assert.True(t, IsInModule("module.kms.module.inner.aws_kms_key.main", "module.kms"))
+	assert.False(t, IsInModule("module.kms_other.aws_kms_key.main", "module.kms"))
+}
+
+func TestResourceGraphOrphans(t *testing.T) {
+	t.Parallel()
+
+	graph := ParseGraph(testGraphDot)
+
+	assert.Equal(t, []string{"null_resource.unused"}, graph.Orphans())
+}
+
+func TestResourceGraphCycles(t *testing.T) {
+	t.Parallel()
+
+	graph := ParseGraph(`digraph {
+		"[root] module.a.aws_iam_role.main (expand)" -> "[root] module.b.aws_iam_policy.main (expand)"
+		"[root] module.b.aws_iam_policy.main (expand)" -> "[root] module.c.aws_s3_bucket.main (expand)"
+		"[root] module.c.aws_s3_bucket.main (expand)" -> "[root] module.a.aws_iam_role.main (expand)"
+		"[root] aws_instance.web (expand)" -> "[root] module.a.aws_iam_role.main (expand)"
+	}`)
+
+	cycles := graph.Cycles()
+	require.Len(t, cycles, 1)
+	assert.Equal(t, []string{"module.a.aws_iam_role.main", "module.b.aws_iam_policy.main", "module.c.aws_s3_bucket.main"}, cycles[0])
+}
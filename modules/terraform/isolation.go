@@ -0,0 +1,148 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/runinfo"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// IsolationIDEnvVar is the environment variable the isolation ID is passed to Terraform in, so that modules can declare
+// a terratest_isolation_id variable to use in their resource names.
+const IsolationIDEnvVar = "TF_VAR_terratest_isolation_id"
+
+// Isolation derives names, workspaces and state keys that are unique to a pull request and CI run, so that the tests of
+// several pull requests (or several runs of the same one) against the same module and account never collide.
+type Isolation struct {
+	// ID identifies the pull request and run, e.g. "pr42-k3x9ab" for run k3x9ab of pull request 42, or "run-k3x9ab"
+	// outside of pull requests. It is lowercase and only contains letters, digits and dashes, so it fits most resource
+	// names.
+	ID string
+
+	// PullRequestID identifies the pull request across its runs, e.g. "pr42", or is empty outside of pull requests.
+	// Useful to find the leftovers of earlier runs of the same pull request.
+	PullRequestID string
+}
+
+// NewIsolation returns the isolation for the current test run, using the pull request and run ID of runinfo.Get().
+func NewIsolation() Isolation {
+	return NewIsolationForRun(runinfo.Get())
+}
+
+// NewIsolationForRun returns the isolation for the test run with the given metadata.
+func NewIsolationForRun(info *runinfo.RunInfo) Isolation {
+	runID := toIsolationName(info.RunID)
+	if info.PullRequest == "" {
+		return Isolation{ID: "run-" + runID}
+	}
+
+	pullRequestID := "pr" + toIsolationName(info.PullRequest)
+	return Isolation{ID: pullRequestID + "-" + runID, PullRequestID: pullRequestID}
+}
+
+// Name returns the isolated name of a resource with the given base name, e.g. "my-bucket-pr42-k3x9ab".
+func (isolation Isolation) Name(base string) string {
+	return toIsolationName(base) + "-" + isolation.ID
+}
+
+// ShortName returns the isolated name of a resource with the given base name, shortening the base name so that the
+// whole name is at most maxLength characters long, for resources with short name limits (e.g. 32 characters for load
+// balancers). The ID is never shortened, so that names stay unique.
+func (isolation Isolation) ShortName(base string, maxLength int) string {
+	base = toIsolationName(base)
+	if maxBase := maxLength - len(isolation.ID) - 1; len(base) > maxBase {
+		if maxBase <= 0 {
+			return isolation.ID
+		}
+		base = strings.TrimRight(base[:maxBase], "-")
+	}
+	return base + "-" + isolation.ID
+}
+
+// Workspace returns the isolated Terraform workspace, e.g. "terratest-pr42-k3x9ab".
+func (isolation Isolation) Workspace() string {
+	return "terratest-" + isolation.ID
+}
+
+// StateKey returns the isolated key of the state with the given base key in a remote backend, e.g.
+// "pr42-k3x9ab/vpc/terraform.tfstate" for "vpc/terraform.tfstate".
+func (isolation Isolation) StateKey(base string) string {
+	return isolation.ID + "/" + strings.TrimPrefix(base, "/")
+}
+
+// IsolationOptions configures how WithIsolation applies an Isolation to Options.
+type IsolationOptions struct {
+	// Terraform variables to set to isolated names, mapped to their base names, e.g. {"bucket_name": "logs"} sets
+	// bucket_name to "logs-pr42-k3x9ab".
+	NameVars map[string]string
+
+	// The key of BackendConfig that holds the path of the state, e.g. "key" for the s3 backend or "prefix" for the gcs
+	// backend. Its value is isolated with StateKey. Defaults to "key"; nothing happens if BackendConfig does not have it.
+	StateKeyConfig string
+}
+
+// WithIsolation returns a copy of the given Options isolated with the given Isolation: the NameVars are set to isolated
+// names, the state key in BackendConfig is isolated with StateKey, and the isolation ID is passed to Terraform as the
+// terratest_isolation_id variable. To use an isolated workspace too, run
+// WorkspaceSelectOrNew(t, options, isolation.Workspace()) after terraform init. This will fail the test if there is an
+// error.
+func WithIsolation(t testing.TestingT, originalOptions *Options, isolation Isolation, isolationOptions IsolationOptions) *Options {
+	options, err := WithIsolationE(t, originalOptions, isolation, isolationOptions)
+	require.NoError(t, err)
+	return options
+}
+
+// WithIsolationE returns a copy of the given Options isolated with the given Isolation: the NameVars are set to
+// isolated names, the state key in BackendConfig is isolated with StateKey, and the isolation ID is passed to Terraform
+// as the terratest_isolation_id variable. To use an isolated workspace too, run
+// WorkspaceSelectOrNewE(t, options, isolation.Workspace()) after terraform init.
+func WithIsolationE(t testing.TestingT, originalOptions *Options, isolation Isolation, isolationOptions IsolationOptions) (*Options, error) {
+	options, err := originalOptions.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	// Clone copies maps by reference, so copy the ones that change to leave the original options untouched
+	options.Vars = copyInterfaceMap(options.Vars)
+	for name, base := range isolationOptions.NameVars {
+		options.Vars[name] = isolation.Name(base)
+	}
+
+	stateKeyConfig := isolationOptions.StateKeyConfig
+	if stateKeyConfig == "" {
+		stateKeyConfig = "key"
+	}
+	if stateKey, hasStateKey := options.BackendConfig[stateKeyConfig]; hasStateKey {
+		options.BackendConfig = copyInterfaceMap(options.BackendConfig)
+		options.BackendConfig[stateKeyConfig] = isolation.StateKey(fmt.Sprint(stateKey))
+	}
+
+	envVars := map[string]string{}
+	for key, value := range options.EnvVars {
+		envVars[key] = value
+	}
+	envVars[IsolationIDEnvVar] = isolation.ID
+	options.EnvVars = envVars
+
+	return options, nil
+}
+
+// copyInterfaceMap returns a copy of the given map, or an empty map if it is nil.
+func copyInterfaceMap(in map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(in))
+	for key, value := range in {
+		out[key] = value
+	}
+	return out
+}
+
+// invalidIsolationNameCharacters matches the characters that are not allowed in isolated names.
+var invalidIsolationNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// toIsolationName converts the given value into lowercase letters, digits and dashes.
+func toIsolationName(value string) string {
+	return strings.Trim(invalidIsolationNameCharacters.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
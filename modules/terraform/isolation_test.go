@@ -0,0 +1,52 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/runinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIsolationForRun(t *testing.T) {
+	t.Parallel()
+
+	isolation := NewIsolationForRun(&runinfo.RunInfo{RunID: "K3x9ab", PullRequest: "42"})
+	assert.Equal(t, Isolation{ID: "pr42-k3x9ab", PullRequestID: "pr42"}, isolation)
+	assert.Equal(t, "my-bucket-pr42-k3x9ab", isolation.Name("My_Bucket"))
+	assert.Equal(t, "terratest-pr42-k3x9ab", isolation.Workspace())
+	assert.Equal(t, "pr42-k3x9ab/vpc/terraform.tfstate", isolation.StateKey("/vpc/terraform.tfstate"))
+
+	assert.Equal(t, Isolation{ID: "run-k3x9ab"}, NewIsolationForRun(&runinfo.RunInfo{RunID: "k3x9ab"}))
+}
+
+func TestIsolationShortName(t *testing.T) {
+	t.Parallel()
+
+	isolation := Isolation{ID: "pr42-k3x9ab"}
+	assert.Equal(t, "internal-alb-pr42-k3x9ab", isolation.ShortName("internal-alb", 32))
+	assert.Equal(t, "internal-a-pr42-k3x9ab", isolation.ShortName("internal-alb", 22))
+	assert.Equal(t, "internal-pr42-k3x9ab", isolation.ShortName("internal-alb", 21))
+	assert.Equal(t, "pr42-k3x9ab", isolation.ShortName("internal-alb", 5))
+}
+
+func TestWithIsolation(t *testing.T) {
+	t.Parallel()
+
+	original := &Options{
+		TerraformDir:  "fixtures/vpc",
+		Vars:          map[string]interface{}{"region": "us-east-1"},
+		BackendConfig: map[string]interface{}{"bucket": "state", "key": "vpc/terraform.tfstate"},
+	}
+	isolation := Isolation{ID: "pr42-k3x9ab", PullRequestID: "pr42"}
+
+	options := WithIsolation(t, original, isolation, IsolationOptions{NameVars: map[string]string{"vpc_name": "vpc"}})
+
+	assert.Equal(t, map[string]interface{}{"region": "us-east-1", "vpc_name": "vpc-pr42-k3x9ab"}, options.Vars)
+	assert.Equal(t, map[string]interface{}{"bucket": "state", "key": "pr42-k3x9ab/vpc/terraform.tfstate"}, options.BackendConfig)
+	assert.Equal(t, "pr42-k3x9ab", options.EnvVars[IsolationIDEnvVar])
+
+	// The original options are left untouched
+	assert.Equal(t, map[string]interface{}{"region": "us-east-1"}, original.Vars)
+	assert.Equal(t, "vpc/terraform.tfstate", original.BackendConfig["key"])
+	assert.Nil(t, original.EnvVars)
+}
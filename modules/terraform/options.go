@@ -70,6 +70,7 @@ type Options struct {
 	Parallelism              int                    // Set the parallelism setting for Terraform
 	PlanFilePath             string                 // The path to output a plan file to (for the plan command) or read one from (for the apply command)
 	PluginDir                string                 // The path of downloaded plugins to pass to the terraform init command (-plugin-dir)
+	FailOnSensitiveOutput    bool                   // If set, Output and the other output functions fail instead of logging outputs marked sensitive. Use OutputSensitive for those.
 }
 
 // Clone makes a deep copy of most fields on the Options object and returns it.
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 
+	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -29,6 +31,33 @@ func OutputE(t testing.TestingT, options *Options, key string) (string, error) {
 	return fmt.Sprintf("%v", val), err
 }
 
+// OutputSensitive calls terraform output for the given variable and return its string value representation, without
+// ever logging the value. Use it for outputs marked sensitive, such as passwords and keys.
+// It only designed to work with primitive terraform types: string, number and bool.
+func OutputSensitive(t testing.TestingT, options *Options, key string) string {
+	out, err := OutputSensitiveE(t, options, key)
+	require.NoError(t, err)
+	return out
+}
+
+// OutputSensitiveE calls terraform output for the given variable and return its string value representation, without
+// ever logging the value. Use it for outputs marked sensitive, such as passwords and keys.
+// It only designed to work with primitive terraform types: string, number and bool.
+func OutputSensitiveE(t testing.TestingT, options *Options, key string) (string, error) {
+	options.Logger.Logf(t, "Retrieving output %s without logging its value", key)
+
+	out, err := RunTerraformCommandAndGetStdoutE(t, withoutLogging(options), "output", "-no-color", "-json", key)
+	if err != nil {
+		return "", err
+	}
+
+	var val interface{}
+	if err := json.Unmarshal([]byte(out), &val); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v", val), nil
+}
+
 // OutputRequired calls terraform output for the given variable and return its value. If the value is empty, fail the test.
 func OutputRequired(t testing.TestingT, options *Options, key string) string {
 	out, err := OutputRequiredE(t, options, key)
@@ -273,10 +302,20 @@ func OutputJson(t testing.TestingT, options *Options, key string) string {
 // OutputJsonE calls terraform output for the given variable and returns the
 // result as the json string.
 // If key is an empty string, it will return all the output variables.
+// If options.FailOnSensitiveOutput is set, it returns a SensitiveOutput error instead when the variable, or any
+// variable if key is empty, is marked sensitive.
 func OutputJsonE(t testing.TestingT, options *Options, key string) (string, error) {
 	args := []string{"output", "-no-color", "-json"}
+	var keys []string
 	if key != "" {
 		args = append(args, key)
+		keys = []string{key}
+	}
+
+	if options.FailOnSensitiveOutput {
+		if err := checkOutputNotSensitiveE(t, options, keys); err != nil {
+			return "", err
+		}
 	}
 
 	return RunTerraformCommandAndGetStdoutE(t, options, args...)
@@ -306,8 +345,19 @@ func OutputStructE(t testing.TestingT, options *Options, key string, v interface
 
 // OutputForKeysE calls terraform output for the given key list and returns values as a map.
 // The returned values are of type interface{} and need to be type casted as necessary. Refer to output_test.go
+// If options.FailOnSensitiveOutput is set, it returns a SensitiveOutput error instead when any of the given keys, or
+// any output if keys is nil, is marked sensitive.
 func OutputForKeysE(t testing.TestingT, options *Options, keys []string) (map[string]interface{}, error) {
-	out, err := OutputJsonE(t, options, "")
+	outputOptions := options
+	if options.FailOnSensitiveOutput {
+		if err := checkOutputNotSensitiveE(t, options, keys); err != nil {
+			return nil, err
+		}
+		// All the outputs are read, including the sensitive ones that were not requested, so they must not be logged
+		outputOptions = withoutLogging(options)
+	}
+
+	out, err := RunTerraformCommandAndGetStdoutE(t, outputOptions, "output", "-no-color", "-json")
 	if err != nil {
 		return nil, err
 	}
@@ -348,3 +398,57 @@ func OutputAll(t testing.TestingT, options *Options) map[string]interface{} {
 func OutputAllE(t testing.TestingT, options *Options) (map[string]interface{}, error) {
 	return OutputForKeysE(t, options, nil)
 }
+
+// checkOutputNotSensitiveE returns a SensitiveOutput error if any of the given outputs, or any output if keys is nil,
+// is marked sensitive. The outputs are read without logging them.
+func checkOutputNotSensitiveE(t testing.TestingT, options *Options, keys []string) error {
+	out, err := RunTerraformCommandAndGetStdoutE(t, withoutLogging(options), "output", "-no-color", "-json")
+	if err != nil {
+		return err
+	}
+
+	sensitive, err := getSensitiveOutputNames(out, keys)
+	if err != nil {
+		return err
+	}
+
+	if len(sensitive) > 0 {
+		return SensitiveOutput(sensitive)
+	}
+	return nil
+}
+
+// getSensitiveOutputNames returns the sorted names of the outputs marked sensitive in the given output of
+// terraform output -json, among the given keys, or among all the outputs if keys is nil.
+func getSensitiveOutputNames(outputJSON string, keys []string) ([]string, error) {
+	outputMap := map[string]struct {
+		Sensitive bool `json:"sensitive"`
+	}{}
+	if err := json.Unmarshal([]byte(outputJSON), &outputMap); err != nil {
+		return nil, err
+	}
+
+	if keys == nil {
+		keys = make([]string, 0, len(outputMap))
+		for name := range outputMap {
+			keys = append(keys, name)
+		}
+	}
+
+	names := []string{}
+	for _, name := range keys {
+		if outputMap[name].Sensitive {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// withoutLogging returns a copy of the given options that discards the logs of the Terraform commands run with it,
+// which would otherwise include their stdout.
+func withoutLogging(options *Options) *Options {
+	silentOptions := *options
+	silentOptions.Logger = logger.Discard
+	return &silentOptions
+}
@@ -433,3 +433,66 @@ func TestOutputsForKeysError(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestOutputSensitive(t *testing.T) {
+	t.Parallel()
+
+	testFolder, err := files.CopyTerraformFolderToTemp("../../test/fixtures/terraform-output-sensitive", t.Name())
+	require.NoError(t, err)
+
+	options := &Options{
+		TerraformDir:          testFolder,
+		FailOnSensitiveOutput: true,
+	}
+
+	InitAndApply(t, options)
+
+	password := OutputSensitive(t, options, "password")
+	require.Equal(t, "correct horse battery staple", password)
+
+	username := Output(t, options, "username")
+	require.Equal(t, "admin", username)
+
+	_, err = OutputE(t, options, "password")
+	require.Equal(t, SensitiveOutput{"password"}, err)
+
+	_, err = OutputAllE(t, options)
+	require.Equal(t, SensitiveOutput{"password"}, err)
+
+	outputs, err := OutputForKeysE(t, options, []string{"username"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"username": "admin"}, outputs)
+
+	_, err = OutputForKeysE(t, options, []string{"username", "password"})
+	require.Equal(t, SensitiveOutput{"password"}, err)
+}
+
+func TestGetSensitiveOutputNames(t *testing.T) {
+	t.Parallel()
+
+	names, err := getSensitiveOutputNames(`{
+		"username": {"sensitive": false, "type": "string", "value": "admin"},
+		"token": {"sensitive": true, "type": "string", "value": "abc"},
+		"password": {"sensitive": true, "type": "string", "value": "xyz"}
+	}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"password", "token"}, names)
+}
+
+func TestGetSensitiveOutputNamesForKeys(t *testing.T) {
+	t.Parallel()
+
+	outputJSON := `{
+		"username": {"sensitive": false, "type": "string", "value": "admin"},
+		"token": {"sensitive": true, "type": "string", "value": "abc"},
+		"password": {"sensitive": true, "type": "string", "value": "xyz"}
+	}`
+
+	names, err := getSensitiveOutputNames(outputJSON, []string{"username"})
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	names, err = getSensitiveOutputNames(outputJSON, []string{"username", "token", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"token"}, names)
+}
@@ -0,0 +1,138 @@
+package terraform
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ProvisionerOutput is the output a provisioner (e.g. local-exec or remote-exec) printed while running for a resource,
+// as reported in the machine readable logs of terraform apply -json.
+type ProvisionerOutput struct {
+	Address     string   // The address of the resource, e.g. "module.app.null_resource.bootstrap[0]"
+	Provisioner string   // The type of the provisioner, e.g. "local-exec"
+	Lines       []string // The lines the provisioner printed, in order
+	Failed      bool     // Whether the provisioner failed
+	Error       string   // The error message, if the provisioner failed
+}
+
+// ProvisionerOutputs is the output of all the provisioners that ran during an apply, in the order they started.
+type ProvisionerOutputs []ProvisionerOutput
+
+// ForResource returns the output of the provisioners of the resource with the given address, in the order they ran.
+func (outputs ProvisionerOutputs) ForResource(address string) []ProvisionerOutput {
+	matching := []ProvisionerOutput{}
+	for _, output := range outputs {
+		if output.Address == address {
+			matching = append(matching, output)
+		}
+	}
+	return matching
+}
+
+// Text returns the lines printed by all the provisioners of the resource with the given address, joined by newlines.
+func (outputs ProvisionerOutputs) Text(address string) string {
+	lines := []string{}
+	for _, output := range outputs.ForResource(address) {
+		lines = append(lines, output.Lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// provisionerLogMessage is the subset of a message in the machine readable logs of terraform that describes the
+// progress of a provisioner.
+type provisionerLogMessage struct {
+	Message string `json:"@message"`
+	Type    string `json:"type"`
+	Hook    struct {
+		Resource struct {
+			Addr string `json:"addr"`
+		} `json:"resource"`
+		Provisioner string `json:"provisioner"`
+		Output      string `json:"output"`
+	} `json:"hook"`
+}
+
+// ApplyJson runs terraform apply with the given options and the -json flag, and returns the machine readable logs
+// (one JSON message per line). Requires Terraform 0.15.3 or newer. This will fail the test if there is an error.
+func ApplyJson(t testing.TestingT, options *Options) string {
+	out, err := ApplyJsonE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// ApplyJsonE runs terraform apply with the given options and the -json flag, and returns the machine readable logs
+// (one JSON message per line). Requires Terraform 0.15.3 or newer.
+func ApplyJsonE(t testing.TestingT, options *Options) (string, error) {
+	return RunTerraformCommandAndGetStdoutE(t, options, FormatArgs(options, "apply", "-input=false", "-auto-approve", "-json")...)
+}
+
+// InitAndApplyAndGetProvisionerOutput runs terraform init and apply -json with the given options, and returns the
+// output of the provisioners that ran. This will fail the test if there is an error.
+func InitAndApplyAndGetProvisionerOutput(t testing.TestingT, options *Options) ProvisionerOutputs {
+	outputs, err := InitAndApplyAndGetProvisionerOutputE(t, options)
+	require.NoError(t, err)
+	return outputs
+}
+
+// InitAndApplyAndGetProvisionerOutputE runs terraform init and apply -json with the given options, and returns the
+// output of the provisioners that ran. If the apply fails, the error is returned along with the output of the
+// provisioners that ran until then, which often explains the failure.
+func InitAndApplyAndGetProvisionerOutputE(t testing.TestingT, options *Options) (ProvisionerOutputs, error) {
+	if _, err := InitE(t, options); err != nil {
+		return nil, err
+	}
+
+	out, applyErr := ApplyJsonE(t, options)
+	outputs, err := ParseProvisionerOutputE(out)
+	if err != nil {
+		return nil, err
+	}
+	return outputs, applyErr
+}
+
+// ParseProvisionerOutputE extracts the output of the provisioners from the given machine readable logs of terraform
+// apply -json. Lines that are not JSON messages, such as those the -json flag does not cover, are ignored.
+func ParseProvisionerOutputE(jsonLog string) (ProvisionerOutputs, error) {
+	outputs := ProvisionerOutputs{}
+	indexes := map[string]int{}
+
+	for _, line := range strings.Split(jsonLog, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var message provisionerLogMessage
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(message.Type, "provision_") {
+			continue
+		}
+
+		// A resource can have several provisioners of the same type, but they run one after the other
+		key := message.Hook.Resource.Addr + "\n" + message.Hook.Provisioner
+		index, running := indexes[key]
+		if message.Type == "provision_start" || !running {
+			outputs = append(outputs, ProvisionerOutput{Address: message.Hook.Resource.Addr, Provisioner: message.Hook.Provisioner, Lines: []string{}})
+			index = len(outputs) - 1
+			indexes[key] = index
+		}
+
+		switch message.Type {
+		case "provision_progress":
+			outputs[index].Lines = append(outputs[index].Lines, message.Hook.Output)
+		case "provision_errored":
+			outputs[index].Failed = true
+			outputs[index].Error = message.Message
+			delete(indexes, key)
+		case "provision_complete":
+			delete(indexes, key)
+		}
+	}
+
+	return outputs, nil
+}
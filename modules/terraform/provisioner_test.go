@@ -0,0 +1,44 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApplyJsonLog = `{"@level":"info","@message":"Terraform 1.0.2","type":"version","terraform":"1.0.2","ui":"0.1.0"}
+{"@level":"info","@message":"null_resource.bootstrap: Creating...","type":"apply_start","hook":{"resource":{"addr":"null_resource.bootstrap"},"action":"create"}}
+{"@level":"info","@message":"null_resource.bootstrap: Provisioning with 'local-exec'...","type":"provision_start","hook":{"resource":{"addr":"null_resource.bootstrap"},"provisioner":"local-exec"}}
+{"@level":"info","@message":"null_resource.bootstrap: (local-exec): installing","type":"provision_progress","hook":{"resource":{"addr":"null_resource.bootstrap"},"provisioner":"local-exec","output":"installing"}}
+{"@level":"info","@message":"null_resource.bootstrap: (local-exec): done","type":"provision_progress","hook":{"resource":{"addr":"null_resource.bootstrap"},"provisioner":"local-exec","output":"done"}}
+{"@level":"info","@message":"null_resource.bootstrap: (local-exec) Provisioning complete","type":"provision_complete","hook":{"resource":{"addr":"null_resource.bootstrap"},"provisioner":"local-exec"}}
+{"@level":"info","@message":"module.app.null_resource.check[0]: Provisioning with 'local-exec'...","type":"provision_start","hook":{"resource":{"addr":"module.app.null_resource.check[0]"},"provisioner":"local-exec"}}
+{"@level":"info","@message":"module.app.null_resource.check[0]: (local-exec): checking","type":"provision_progress","hook":{"resource":{"addr":"module.app.null_resource.check[0]"},"provisioner":"local-exec","output":"checking"}}
+{"@level":"error","@message":"module.app.null_resource.check[0]: (local-exec) Provisioning errored","type":"provision_errored","hook":{"resource":{"addr":"module.app.null_resource.check[0]"},"provisioner":"local-exec"}}
+Warning: this line is not JSON
+{"@level":"info","@message":"Apply complete! Resources: 1 added, 0 changed, 0 destroyed.","type":"change_summary","changes":{"add":1,"change":0,"remove":0,"operation":"apply"}}
+`
+
+func TestParseProvisionerOutput(t *testing.T) {
+	t.Parallel()
+
+	outputs, err := ParseProvisionerOutputE(testApplyJsonLog)
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+
+	assert.Equal(t, ProvisionerOutput{
+		Address:     "null_resource.bootstrap",
+		Provisioner: "local-exec",
+		Lines:       []string{"installing", "done"},
+	}, outputs[0])
+	assert.Equal(t, "installing\ndone", outputs.Text("null_resource.bootstrap"))
+
+	failed := outputs.ForResource("module.app.null_resource.check[0]")
+	require.Len(t, failed, 1)
+	assert.True(t, failed[0].Failed)
+	assert.Equal(t, "module.app.null_resource.check[0]: (local-exec) Provisioning errored", failed[0].Error)
+	assert.Equal(t, []string{"checking"}, failed[0].Lines)
+
+	assert.Empty(t, outputs.ForResource("null_resource.missing"))
+}
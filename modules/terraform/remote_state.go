@@ -0,0 +1,144 @@
+package terraform
+
+import (
+	"path/filepath"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// DefaultRemoteStateBackendVar is the consumer variable that ApplyRemoteStateStack sets to the backend of the
+	// producer state by default.
+	DefaultRemoteStateBackendVar = "producer_state_backend"
+
+	// DefaultRemoteStateConfigVar is the consumer variable that ApplyRemoteStateStack sets to the backend config of the
+	// producer state by default.
+	DefaultRemoteStateConfigVar = "producer_state_config"
+)
+
+// RemoteStateStack is a producer module and a consumer module that reads the state of the producer with a
+// terraform_remote_state data source, e.g.:
+//
+//	data "terraform_remote_state" "producer" {
+//	  backend = var.producer_state_backend
+//	  config  = var.producer_state_config
+//	}
+//
+// ApplyRemoteStateStack applies both and wires the backend of the producer into the consumer, so that cross-stack data
+// source patterns can be tested without hard-coding state locations.
+type RemoteStateStack struct {
+	Producer *Options
+	Consumer *Options
+
+	// The backend that stores the state of the producer, e.g. "s3". Defaults to "local" if the producer has no
+	// BackendConfig, in which case its state is read from the terraform.tfstate file in its TerraformDir.
+	Backend string
+
+	// The consumer variables to set to the backend and the backend config of the producer state. Default to
+	// DefaultRemoteStateBackendVar and DefaultRemoteStateConfigVar. The config variable should be of type any, as the
+	// config of each backend has different attributes.
+	BackendVar string
+	ConfigVar  string
+}
+
+// ApplyRemoteStateStack runs terraform init and apply on the producer of the given stack, then on its consumer with the
+// backend of the producer state passed to it, and returns the options the consumer was applied with, e.g. to read its
+// outputs. Use DestroyRemoteStateStack to clean up. This will fail the test if there is an error.
+func ApplyRemoteStateStack(t testing.TestingT, stack RemoteStateStack) *Options {
+	consumerOptions, err := ApplyRemoteStateStackE(t, stack)
+	require.NoError(t, err)
+	return consumerOptions
+}
+
+// ApplyRemoteStateStackE runs terraform init and apply on the producer of the given stack, then on its consumer with
+// the backend of the producer state passed to it, and returns the options the consumer was applied with, e.g. to read
+// its outputs. Use DestroyRemoteStateStackE to clean up, which destroys the consumer first, as it depends on the
+// producer.
+func ApplyRemoteStateStackE(t testing.TestingT, stack RemoteStateStack) (*Options, error) {
+	if _, err := InitAndApplyE(t, stack.Producer); err != nil {
+		return nil, err
+	}
+
+	consumerOptions, err := remoteStateConsumerOptionsE(stack)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := InitAndApplyE(t, consumerOptions); err != nil {
+		return consumerOptions, err
+	}
+	return consumerOptions, nil
+}
+
+// DestroyRemoteStateStack runs terraform destroy on the consumer of the given stack and then on its producer. This will
+// fail the test if there is an error.
+func DestroyRemoteStateStack(t testing.TestingT, stack RemoteStateStack) {
+	require.NoError(t, DestroyRemoteStateStackE(t, stack))
+}
+
+// DestroyRemoteStateStackE runs terraform destroy on the consumer of the given stack, with the backend of the producer
+// state passed to it as ApplyRemoteStateStackE does, and then on its producer. The producer is destroyed even if
+// destroying the consumer fails, so that a test does not leak the resources of both; the first error is returned.
+func DestroyRemoteStateStackE(t testing.TestingT, stack RemoteStateStack) error {
+	consumerOptions, err := remoteStateConsumerOptionsE(stack)
+	if err == nil {
+		_, err = DestroyE(t, consumerOptions)
+	}
+
+	if _, producerErr := DestroyE(t, stack.Producer); producerErr != nil && err == nil {
+		err = producerErr
+	}
+	return err
+}
+
+// remoteStateConsumerOptionsE returns a copy of the consumer options of the given stack with the backend of the
+// producer state set in its variables.
+func remoteStateConsumerOptionsE(stack RemoteStateStack) (*Options, error) {
+	consumerOptions, err := stack.Consumer.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	backend, config, err := remoteStateBackendConfigE(stack)
+	if err != nil {
+		return nil, err
+	}
+
+	backendVar := stack.BackendVar
+	if backendVar == "" {
+		backendVar = DefaultRemoteStateBackendVar
+	}
+	configVar := stack.ConfigVar
+	if configVar == "" {
+		configVar = DefaultRemoteStateConfigVar
+	}
+
+	// Clone copies maps by reference, so copy the vars to leave the original options untouched
+	consumerOptions.Vars = copyInterfaceMap(consumerOptions.Vars)
+	consumerOptions.Vars[backendVar] = backend
+	consumerOptions.Vars[configVar] = config
+	return consumerOptions, nil
+}
+
+// remoteStateBackendConfigE returns the backend and the backend config of the producer state of the given stack, as a
+// terraform_remote_state data source expects them.
+func remoteStateBackendConfigE(stack RemoteStateStack) (string, map[string]interface{}, error) {
+	backend := stack.Backend
+	if backend == "" && len(stack.Producer.BackendConfig) == 0 {
+		backend = "local"
+	}
+	if backend == "" {
+		return "", nil, RemoteStateBackendNotSet{ProducerDir: stack.Producer.TerraformDir}
+	}
+
+	if backend == "local" && len(stack.Producer.BackendConfig) == 0 {
+		// The consumer runs in another directory, so the path must be absolute
+		statePath, err := filepath.Abs(filepath.Join(stack.Producer.TerraformDir, "terraform.tfstate"))
+		if err != nil {
+			return "", nil, err
+		}
+		return backend, map[string]interface{}{"path": statePath}, nil
+	}
+	return backend, copyInterfaceMap(stack.Producer.BackendConfig), nil
+}
@@ -0,0 +1,91 @@
+package terraform
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRemoteStateStack(t *testing.T) {
+	t.Parallel()
+
+	producerFolder, err := files.CopyTerraformFolderToTemp("../../test/fixtures/terraform-remote-state-producer", t.Name())
+	require.NoError(t, err)
+	consumerFolder, err := files.CopyTerraformFolderToTemp("../../test/fixtures/terraform-remote-state-consumer", t.Name())
+	require.NoError(t, err)
+
+	stack := RemoteStateStack{
+		Producer: &Options{
+			TerraformDir: producerFolder,
+			Vars:         map[string]interface{}{"name": "remote-state-test"},
+		},
+		Consumer: &Options{
+			TerraformDir: consumerFolder,
+		},
+	}
+	defer DestroyRemoteStateStack(t, stack)
+
+	consumerOptions := ApplyRemoteStateStack(t, stack)
+
+	assert.Equal(t, "remote-state-test", Output(t, consumerOptions, "producer_name"))
+	assert.Empty(t, stack.Consumer.Vars)
+}
+
+func TestRemoteStateConsumerOptionsLocalBackend(t *testing.T) {
+	t.Parallel()
+
+	stack := RemoteStateStack{
+		Producer: &Options{TerraformDir: "producer"},
+		Consumer: &Options{TerraformDir: "consumer", Vars: map[string]interface{}{"foo": "bar"}},
+	}
+
+	consumerOptions, err := remoteStateConsumerOptionsE(stack)
+	require.NoError(t, err)
+
+	statePath, err := filepath.Abs(filepath.Join("producer", "terraform.tfstate"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"foo":                        "bar",
+		DefaultRemoteStateBackendVar: "local",
+		DefaultRemoteStateConfigVar:  map[string]interface{}{"path": statePath},
+	}, consumerOptions.Vars)
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, stack.Consumer.Vars)
+}
+
+func TestRemoteStateConsumerOptionsBackendConfig(t *testing.T) {
+	t.Parallel()
+
+	stack := RemoteStateStack{
+		Producer: &Options{
+			TerraformDir:  "producer",
+			BackendConfig: map[string]interface{}{"bucket": "my-bucket", "key": "producer/terraform.tfstate"},
+		},
+		Consumer:   &Options{TerraformDir: "consumer"},
+		Backend:    "s3",
+		BackendVar: "backend",
+		ConfigVar:  "config",
+	}
+
+	consumerOptions, err := remoteStateConsumerOptionsE(stack)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"backend": "s3",
+		"config":  map[string]interface{}{"bucket": "my-bucket", "key": "producer/terraform.tfstate"},
+	}, consumerOptions.Vars)
+}
+
+func TestRemoteStateConsumerOptionsBackendNotSet(t *testing.T) {
+	t.Parallel()
+
+	stack := RemoteStateStack{
+		Producer: &Options{TerraformDir: "producer", BackendConfig: map[string]interface{}{"bucket": "my-bucket"}},
+		Consumer: &Options{TerraformDir: "consumer"},
+	}
+
+	_, err := remoteStateConsumerOptionsE(stack)
+	assert.Equal(t, RemoteStateBackendNotSet{ProducerDir: "producer"}, err)
+}
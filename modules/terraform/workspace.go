@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/gruntwork-io/terratest/modules/dryrun"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -67,6 +68,10 @@ func nameMatchesWorkspace(name string, workspace string) bool {
 // If the workspace to delete is the current one, then it tries to switch to the "default" workspace.
 // Deleting the workspace "default" is not supported.
 func WorkspaceDeleteE(t testing.TestingT, options *Options, name string) (string, error) {
+	if dryrun.Skip(t, "delete terraform workspace %s in %s", name, options.TerraformDir) {
+		return "", nil
+	}
+
 	currentWorkspace, err := RunTerraformCommandE(t, options, "workspace", "show")
 	if err != nil {
 		return currentWorkspace, err
@@ -0,0 +1,56 @@
+package wait
+
+import "github.com/gruntwork-io/terratest/modules/retry"
+
+// Condition checks whether something waited for holds. If it returns an error, e.g. because an API call failed, the
+// error is recorded and waiting continues, unless the error is a retry.FatalError.
+type Condition func() (bool, error)
+
+// All returns a condition that holds when all the given conditions hold. The conditions are checked in order, and
+// checking stops at the first one that does not hold or returns an error.
+func All(conditions ...Condition) Condition {
+	return func() (bool, error) {
+		for _, condition := range conditions {
+			done, err := condition()
+			if err != nil || !done {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// Any returns a condition that holds when any of the given conditions holds. The conditions are checked in order, and
+// checking stops at the first one that holds, even if an earlier one returned an error. If none holds, the first
+// retry.FatalError is returned, or else the first error.
+func Any(conditions ...Condition) Condition {
+	return func() (bool, error) {
+		var firstErr error
+		for _, condition := range conditions {
+			done, err := condition()
+			if err == nil && done {
+				return true, nil
+			}
+			if _, isFatalErr := err.(retry.FatalError); isFatalErr {
+				if _, firstIsFatal := firstErr.(retry.FatalError); !firstIsFatal {
+					firstErr = err
+				}
+			} else if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return false, firstErr
+	}
+}
+
+// Not returns a condition that holds when the given condition does not. Errors are returned as is, so a condition that
+// can't be checked does not count as not holding.
+func Not(condition Condition) Condition {
+	return func() (bool, error) {
+		done, err := condition()
+		if err != nil {
+			return false, err
+		}
+		return !done, nil
+	}
+}
@@ -0,0 +1,89 @@
+package wait
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func holds(done bool, err error) Condition {
+	return func() (bool, error) { return done, err }
+}
+
+func TestAll(t *testing.T) {
+	t.Parallel()
+
+	checkErr := errors.New("check failed")
+	testCases := []struct {
+		name        string
+		conditions  []Condition
+		expected    bool
+		expectedErr error
+	}{
+		{"none", nil, true, nil},
+		{"all hold", []Condition{holds(true, nil), holds(true, nil)}, true, nil},
+		{"one does not hold", []Condition{holds(true, nil), holds(false, nil)}, false, nil},
+		{"error", []Condition{holds(true, nil), holds(false, checkErr)}, false, checkErr},
+		{"stops at first that does not hold", []Condition{holds(false, nil), holds(false, checkErr)}, false, nil},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			done, err := All(testCase.conditions...)()
+			assert.Equal(t, testCase.expected, done)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+func TestAny(t *testing.T) {
+	t.Parallel()
+
+	checkErr := errors.New("check failed")
+	fatalErr := retry.FatalError{Underlying: errors.New("failed for good")}
+	testCases := []struct {
+		name        string
+		conditions  []Condition
+		expected    bool
+		expectedErr error
+	}{
+		{"none", nil, false, nil},
+		{"one holds", []Condition{holds(false, nil), holds(true, nil)}, true, nil},
+		{"none hold", []Condition{holds(false, nil), holds(false, nil)}, false, nil},
+		{"holds despite error", []Condition{holds(false, checkErr), holds(true, nil)}, true, nil},
+		{"first error", []Condition{holds(false, checkErr), holds(false, errors.New("other"))}, false, checkErr},
+		{"fatal error first", []Condition{holds(false, checkErr), holds(false, fatalErr)}, false, fatalErr},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			done, err := Any(testCase.conditions...)()
+			assert.Equal(t, testCase.expected, done)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+func TestNot(t *testing.T) {
+	t.Parallel()
+
+	checkErr := errors.New("check failed")
+
+	done, err := Not(holds(true, nil))()
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	done, err = Not(holds(false, nil))()
+	assert.True(t, done)
+	assert.NoError(t, err)
+
+	done, err = Not(holds(false, checkErr))()
+	assert.False(t, done)
+	assert.Equal(t, checkErr, err)
+}
@@ -0,0 +1,21 @@
+package wait
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimedOut is returned by UntilE when the condition does not hold before the timeout passes.
+type TimedOut struct {
+	Description string
+	Timeout     time.Duration
+	Checks      int
+	LastError   error // The error the last check returned, if any
+}
+
+func (err TimedOut) Error() string {
+	if err.LastError != nil {
+		return fmt.Sprintf("Timed out after %s and %d checks waiting for %s. Last check failed: %s", err.Timeout, err.Checks, err.Description, err.LastError)
+	}
+	return fmt.Sprintf("Timed out after %s and %d checks waiting for %s", err.Timeout, err.Checks, err.Description)
+}
@@ -0,0 +1,135 @@
+// Package wait contains logic to wait for conditions that are composed of smaller conditions, e.g. "all the pods are
+// ready and none of them restarted", with a timeout. Conditions are checked on a poll interval, whenever a channel
+// receives, e.g. from a watch, or both:
+//
+//	err := wait.UntilE(t, wait.Options{
+//		Description: "deployment my-app to roll out",
+//		Timeout:     5 * time.Minute,
+//		Trigger:     wait.Poll(5 * time.Second),
+//	}, wait.All(deploymentAvailable, wait.Not(podsCrashLooping)))
+//
+// The clock the timeout and poll interval are measured with can be replaced, so that code built on this package can be
+// unit tested without sleeping. See the clock package.
+package wait
+
+import (
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/clock"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// DefaultTimeout is how long Until waits if the options have no timeout.
+	DefaultTimeout = 5 * time.Minute
+
+	// DefaultPollInterval is how often Until checks the condition if the options have no trigger.
+	DefaultPollInterval = 1 * time.Second
+)
+
+// Trigger says when to check a condition again after it did not hold. If both fields are set, the condition is checked
+// on whichever comes first, e.g. on every event of a watch, and every minute in case an event was missed.
+type Trigger struct {
+	Interval time.Duration   // Check the condition again after this long
+	Signal   <-chan struct{} // Check the condition again whenever this receives. Closing it stops it from triggering.
+}
+
+// Poll returns a trigger that checks a condition again every interval.
+func Poll(interval time.Duration) Trigger {
+	return Trigger{Interval: interval}
+}
+
+// OnSignal returns a trigger that checks a condition again whenever the given channel receives, e.g. when a watch sees
+// a change to the resource waited for.
+func OnSignal(signal <-chan struct{}) Trigger {
+	return Trigger{Signal: signal}
+}
+
+// Options configures how to wait for a condition.
+type Options struct {
+	Description string        // What is waited for, e.g. "pod my-pod to be ready", used in logs and errors
+	Timeout     time.Duration // How long to wait for. Defaults to DefaultTimeout.
+	Trigger     Trigger       // When to check the condition again. Defaults to Poll(DefaultPollInterval).
+	Clock       clock.Clock   // The clock to measure the timeout and poll interval with. Defaults to clock.Default().
+}
+
+// Until checks the given condition until it holds, or the timeout of the given options passes. This will fail the test
+// if the condition does not hold in time.
+func Until(t testing.TestingT, options Options, condition Condition) {
+	require.NoError(t, UntilE(t, options, condition))
+}
+
+// UntilE checks the given condition right away and then every time the trigger of the given options fires, until it
+// holds. If the condition returns an error, the error is recorded and waiting continues, unless the error is a
+// retry.FatalError, e.g. because the resource reached a failed state it can't recover from, in which case its
+// underlying error is returned right away. If the condition does not hold before the timeout passes, this returns a
+// TimedOut error with the error of the last check, if any.
+func UntilE(t testing.TestingT, options Options, condition Condition) error {
+	waitClock := options.Clock
+	if waitClock == nil {
+		waitClock = clock.Default()
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	trigger := options.Trigger
+	if trigger.Interval <= 0 && trigger.Signal == nil {
+		trigger = Poll(DefaultPollInterval)
+	}
+
+	start := waitClock.Now()
+	deadline := waitClock.After(timeout)
+	checks := 0
+
+	for {
+		done, err := condition()
+		checks++
+		elapsed := waitClock.Now().Sub(start)
+
+		if fatalErr, isFatalErr := err.(retry.FatalError); isFatalErr {
+			logger.Logf(t, "Stopped waiting for %s after %s: %s", options.Description, elapsed, fatalErr.Underlying)
+			return fatalErr.Underlying
+		}
+		if err == nil && done {
+			logger.Logf(t, "Done waiting for %s after %s", options.Description, elapsed)
+			return nil
+		}
+		if err != nil {
+			logger.Logf(t, "Waiting for %s: check failed: %s (elapsed %s, timeout %s)", options.Description, err, elapsed, timeout)
+		} else {
+			logger.Logf(t, "Waiting for %s (elapsed %s, timeout %s)", options.Description, elapsed, timeout)
+		}
+
+		// A nil channel never receives, so only the parts of the trigger that are set fire
+		var interval <-chan time.Time
+		if trigger.Interval > 0 {
+			interval = waitClock.After(trigger.Interval)
+		}
+		if !waitForTrigger(deadline, interval, &trigger) {
+			return TimedOut{Description: options.Description, Timeout: timeout, Checks: checks, LastError: err}
+		}
+	}
+}
+
+// waitForTrigger blocks until the given interval passes or the signal of the given trigger receives, and returns false
+// if the deadline passes first.
+func waitForTrigger(deadline <-chan time.Time, interval <-chan time.Time, trigger *Trigger) bool {
+	for {
+		select {
+		case <-deadline:
+			return false
+		case <-interval:
+			return true
+		case _, open := <-trigger.Signal:
+			if open {
+				return true
+			}
+			// A closed channel receives right away, so stop waiting on it
+			trigger.Signal = nil
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package wait
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/clock"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCondition returns a condition that holds from the given check on, and the number of times it was checked.
+func countingCondition(holdsFrom int32) (Condition, *int32) {
+	var checks int32
+	return func() (bool, error) {
+		return atomic.AddInt32(&checks, 1) >= holdsFrom, nil
+	}, &checks
+}
+
+func TestUntilReturnsRightAwayIfConditionHolds(t *testing.T) {
+	t.Parallel()
+
+	condition, checks := countingCondition(1)
+	err := UntilE(t, Options{Description: t.Name(), Clock: clock.NewFake(time.Now())}, condition)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(checks))
+}
+
+func TestUntilPollsOnInterval(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Now())
+	condition, checks := countingCondition(3)
+	result := make(chan error, 1)
+	go func() {
+		result <- UntilE(t, Options{Description: t.Name(), Timeout: time.Minute, Trigger: Poll(10 * time.Second), Clock: fakeClock}, condition)
+	}()
+
+	for i := 1; i < 3; i++ {
+		// The deadline and the poll interval
+		fakeClock.BlockUntil(2)
+		assert.Equal(t, int32(i), atomic.LoadInt32(checks))
+		fakeClock.Advance(10 * time.Second)
+	}
+
+	require.NoError(t, <-result)
+	assert.Equal(t, int32(3), atomic.LoadInt32(checks))
+}
+
+func TestUntilTimesOut(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Now())
+	checkErr := errors.New("check failed")
+	result := make(chan error, 1)
+	go func() {
+		result <- UntilE(t, Options{Description: t.Name(), Timeout: 15 * time.Second, Trigger: Poll(10 * time.Second), Clock: fakeClock}, holds(false, checkErr))
+	}()
+
+	fakeClock.BlockUntil(2)
+	fakeClock.Advance(10 * time.Second)
+	// Only the deadline is due, not the next poll
+	fakeClock.BlockUntil(2)
+	fakeClock.Advance(5 * time.Second)
+
+	assert.Equal(t, TimedOut{Description: t.Name(), Timeout: 15 * time.Second, Checks: 2, LastError: checkErr}, <-result)
+}
+
+func TestUntilStopsOnFatalError(t *testing.T) {
+	t.Parallel()
+
+	failedErr := errors.New("failed for good")
+	err := UntilE(t, Options{Description: t.Name(), Clock: clock.NewFake(time.Now())}, holds(false, retry.FatalError{Underlying: failedErr}))
+
+	assert.Equal(t, failedErr, err)
+}
+
+func TestUntilChecksOnSignal(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Now())
+	signal := make(chan struct{})
+	condition, checks := countingCondition(3)
+	result := make(chan error, 1)
+	go func() {
+		result <- UntilE(t, Options{Description: t.Name(), Timeout: time.Minute, Trigger: OnSignal(signal), Clock: fakeClock}, condition)
+	}()
+
+	// The signal is unbuffered, so each send is received by a wait for the trigger
+	signal <- struct{}{}
+	signal <- struct{}{}
+
+	require.NoError(t, <-result)
+	assert.Equal(t, int32(3), atomic.LoadInt32(checks))
+}
+
+func TestUntilFallsBackToIntervalWhenSignalCloses(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Now())
+	signal := make(chan struct{})
+	close(signal)
+	condition, checks := countingCondition(2)
+	result := make(chan error, 1)
+	go func() {
+		result <- UntilE(t, Options{Description: t.Name(), Timeout: time.Minute, Trigger: Trigger{Interval: 10 * time.Second, Signal: signal}, Clock: fakeClock}, condition)
+	}()
+
+	fakeClock.BlockUntil(2)
+	assert.Equal(t, int32(1), atomic.LoadInt32(checks))
+	fakeClock.Advance(10 * time.Second)
+
+	require.NoError(t, <-result)
+	assert.Equal(t, int32(2), atomic.LoadInt32(checks))
+}
@@ -0,0 +1,46 @@
+package winrm
+
+import "fmt"
+
+// NoCredentials is returned when connecting to a host without a password or a client certificate.
+type NoCredentials struct {
+	Hostname string
+}
+
+func (err NoCredentials) Error() string {
+	return fmt.Sprintf("No WinRM credentials for %s: set either Password or ClientCertificate and ClientKey", err.Hostname)
+}
+
+// AuthFailed is returned when the host rejects the credentials, e.g. because the password is wrong or Basic
+// authentication is not enabled on the WinRM service.
+type AuthFailed struct {
+	Hostname string
+	Username string
+}
+
+func (err AuthFailed) Error() string {
+	return fmt.Sprintf("WinRM authentication as %s to %s failed: check the credentials and that the WinRM service allows the authentication method", err.Username, err.Hostname)
+}
+
+// CommandFailed is returned when a command exits with a non-zero code.
+type CommandFailed struct {
+	Hostname string
+	Command  string
+	ExitCode int
+	Stderr   string
+}
+
+func (err CommandFailed) Error() string {
+	return fmt.Sprintf("Command %s on %s exited with code %d: %s", err.Command, err.Hostname, err.ExitCode, err.Stderr)
+}
+
+// UnexpectedResponse is returned when the host responds to a WinRM request in an unexpected way.
+type UnexpectedResponse struct {
+	Hostname string
+	Action   string
+	Reason   string
+}
+
+func (err UnexpectedResponse) Error() string {
+	return fmt.Sprintf("Unexpected response from %s to WinRM action %s: %s", err.Hostname, err.Action, err.Reason)
+}
@@ -0,0 +1,110 @@
+package winrm
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/hashicorp/go-multierror"
+)
+
+// FetchContentsOfFile connects to the given host over WinRM and returns the contents of the file at the given path as
+// a string. This will fail the test if there is an error.
+func FetchContentsOfFile(t testing.TestingT, host Host, filePath string) string {
+	out, err := FetchContentsOfFileE(t, host, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// FetchContentsOfFileE connects to the given host over WinRM and returns the contents of the file at the given path as
+// a string.
+func FetchContentsOfFileE(t testing.TestingT, host Host, filePath string) (string, error) {
+	contents, err := fetchFileE(t, host, filePath)
+	return string(contents), err
+}
+
+// fetchFileE returns the contents of the file at the given path on the given host. The file is transferred base64
+// encoded, as command output is text.
+func fetchFileE(t testing.TestingT, host Host, filePath string) ([]byte, error) {
+	out, err := RunPowerShellE(t, host, "[Convert]::ToBase64String([IO.File]::ReadAllBytes("+quotePowerShell(filePath)+"))")
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(out))
+}
+
+// FetchFiles connects to the given host over WinRM, downloads the files in remoteDirectory (and its subdirectories)
+// whose names match any of filenameFilters, and stores them directly in localDirectory. This will fail the test if
+// there is an error.
+func FetchFiles(t testing.TestingT, host Host, remoteDirectory string, localDirectory string, filenameFilters []string) {
+	err := FetchFilesE(t, host, remoteDirectory, localDirectory, filenameFilters)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// FetchFilesE connects to the given host over WinRM, downloads the files in remoteDirectory (and its subdirectories)
+// whose names match any of filenameFilters, and stores them directly in localDirectory. The filters support
+// PowerShell wildcards, e.g. *.log, and match all files if empty. All files are attempted even if some fail, and all
+// errors are returned together.
+func FetchFilesE(t testing.TestingT, host Host, remoteDirectory string, localDirectory string, filenameFilters []string) error {
+	remoteFilePaths, err := listFilesE(t, host, remoteDirectory, filenameFilters)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(localDirectory, 0755); err != nil {
+		return err
+	}
+
+	var errorsOccurred = new(multierror.Error)
+	for _, remoteFilePath := range remoteFilePaths {
+		// The remote paths use backslashes, which filepath only treats as separators on Windows
+		localFilePath := filepath.Join(localDirectory, remoteFilePath[strings.LastIndex(remoteFilePath, `\`)+1:])
+		logger.Logf(t, "Copying remote file: %s to local path %s", remoteFilePath, localFilePath)
+
+		contents, err := fetchFileE(t, host, remoteFilePath)
+		if err == nil {
+			err = ioutil.WriteFile(localFilePath, contents, 0644)
+		}
+		errorsOccurred = multierror.Append(errorsOccurred, err)
+	}
+
+	return errorsOccurred.ErrorOrNil()
+}
+
+// listFilesE returns the full paths of the files in the given remote directory and its subdirectories whose names
+// match any of the given filters, or all of them if there are no filters.
+func listFilesE(t testing.TestingT, host Host, remoteDirectory string, filenameFilters []string) ([]string, error) {
+	out, err := RunPowerShellE(t, host, listFilesScript(remoteDirectory, filenameFilters))
+	if err != nil {
+		return nil, err
+	}
+
+	remoteFilePaths := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			remoteFilePaths = append(remoteFilePaths, line)
+		}
+	}
+	return remoteFilePaths, nil
+}
+
+// listFilesScript returns the PowerShell script that lists the files listFilesE returns.
+func listFilesScript(remoteDirectory string, filenameFilters []string) string {
+	script := "Get-ChildItem -LiteralPath " + quotePowerShell(remoteDirectory) + " -File -Recurse"
+	if len(filenameFilters) > 0 {
+		conditions := []string{}
+		for _, filter := range filenameFilters {
+			conditions = append(conditions, "$_.Name -like "+quotePowerShell(filter))
+		}
+		script += " | Where-Object { " + strings.Join(conditions, " -or ") + " }"
+	}
+	return script + " | ForEach-Object { $_.FullName }"
+}
@@ -0,0 +1,306 @@
+package winrm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The URIs of the WS-Management protocol used to run commands in a remote shell.
+const (
+	shellResourceURI = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/cmd"
+
+	actionCreate  = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Create"
+	actionDelete  = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Delete"
+	actionCommand = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Command"
+	actionReceive = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Receive"
+	actionSignal  = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Signal"
+
+	commandStateDone = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/CommandState/Done"
+	signalTerminate  = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/signal/terminate"
+
+	// certificateAuthorization is the Authorization header WinRM expects for certificate authentication.
+	certificateAuthorization = "http://schemas.dmtf.org/wbem/wsman/1/wsman/secprofile/https/mutual"
+
+	// operationTimedOutCode is the WinRM error code of a Receive that timed out before the command produced output.
+	operationTimedOutCode = "2150858793"
+)
+
+// client sends WS-Management requests to the WinRM endpoint of a host.
+type client struct {
+	host             Host
+	endpoint         string
+	http             *http.Client
+	operationTimeout time.Duration
+}
+
+// runE creates a shell, runs the given command in it, waits for it to finish and returns its stdout. The shell is
+// deleted afterwards.
+func (c *client) runE(command string, args []string) (string, error) {
+	shellID, err := c.createShellE()
+	if err != nil {
+		return "", err
+	}
+	defer c.deleteShell(shellID)
+
+	commandID, err := c.startCommandE(shellID, command, args)
+	if err != nil {
+		return "", err
+	}
+	defer c.terminateCommand(shellID, commandID)
+
+	stdout, stderr, exitCode, err := c.receiveOutputE(shellID, commandID)
+	if err != nil {
+		return "", err
+	}
+	if exitCode != 0 {
+		return stdout, CommandFailed{Hostname: c.host.Hostname, Command: command, ExitCode: exitCode, Stderr: stderr}
+	}
+	return stdout, nil
+}
+
+func (c *client) createShellE() (string, error) {
+	body := `<rsp:Shell><rsp:InputStreams>stdin</rsp:InputStreams><rsp:OutputStreams>stdout stderr</rsp:OutputStreams></rsp:Shell>`
+	options := map[string]string{"WINRS_NOPROFILE": "FALSE", "WINRS_CODEPAGE": "65001"}
+
+	response := struct {
+		ShellID string `xml:"Body>Shell>ShellId"`
+	}{}
+	if err := c.sendE(actionCreate, "", options, body, &response); err != nil {
+		return "", err
+	}
+	if response.ShellID == "" {
+		return "", UnexpectedResponse{Hostname: c.host.Hostname, Action: actionCreate, Reason: "no shell ID"}
+	}
+	return response.ShellID, nil
+}
+
+func (c *client) startCommandE(shellID string, command string, args []string) (string, error) {
+	var body strings.Builder
+	body.WriteString(`<rsp:CommandLine><rsp:Command>`)
+	xml.EscapeText(&body, []byte(command))
+	body.WriteString(`</rsp:Command>`)
+	for _, arg := range args {
+		body.WriteString(`<rsp:Arguments>`)
+		xml.EscapeText(&body, []byte(arg))
+		body.WriteString(`</rsp:Arguments>`)
+	}
+	body.WriteString(`</rsp:CommandLine>`)
+	options := map[string]string{"WINRS_CONSOLEMODE_STDIN": "TRUE", "WINRS_SKIP_CMD_SHELL": "TRUE"}
+
+	response := struct {
+		CommandID string `xml:"Body>CommandResponse>CommandId"`
+	}{}
+	if err := c.sendE(actionCommand, shellID, options, body.String(), &response); err != nil {
+		return "", err
+	}
+	if response.CommandID == "" {
+		return "", UnexpectedResponse{Hostname: c.host.Hostname, Action: actionCommand, Reason: "no command ID"}
+	}
+	return response.CommandID, nil
+}
+
+// receiveResponse is the response to a Receive request: the next chunks of the output streams of a command, and its
+// state.
+type receiveResponse struct {
+	Streams []struct {
+		Name    string `xml:"Name,attr"`
+		Content string `xml:",chardata"` // base64
+	} `xml:"Body>ReceiveResponse>Stream"`
+	State struct {
+		State    string `xml:"State,attr"`
+		ExitCode int    `xml:"ExitCode"`
+	} `xml:"Body>ReceiveResponse>CommandState"`
+}
+
+// receiveOutputE receives the output of the given command until it is done, and returns its stdout, stderr and exit
+// code.
+func (c *client) receiveOutputE(shellID string, commandID string) (string, string, int, error) {
+	var stdout, stderr bytes.Buffer
+	body := fmt.Sprintf(`<rsp:Receive><rsp:DesiredStream CommandId="%s">stdout stderr</rsp:DesiredStream></rsp:Receive>`, commandID)
+
+	for {
+		response := receiveResponse{}
+		err := c.sendE(actionReceive, shellID, nil, body, &response)
+		if fault, isFault := err.(soapFault); isFault && fault.code == operationTimedOutCode {
+			// The command has not written anything before the operation timeout: keep waiting
+			continue
+		}
+		if err != nil {
+			return stdout.String(), stderr.String(), 0, err
+		}
+
+		for _, stream := range response.Streams {
+			content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stream.Content))
+			if err != nil {
+				return stdout.String(), stderr.String(), 0, err
+			}
+			if stream.Name == "stderr" {
+				stderr.Write(content)
+			} else {
+				stdout.Write(content)
+			}
+		}
+
+		if response.State.State == commandStateDone {
+			return stdout.String(), stderr.String(), response.State.ExitCode, nil
+		}
+	}
+}
+
+// terminateCommand tells the host to clean up after the given command. Errors are ignored, as the command is done
+// anyway.
+func (c *client) terminateCommand(shellID string, commandID string) {
+	body := fmt.Sprintf(`<rsp:Signal CommandId="%s"><rsp:Code>%s</rsp:Code></rsp:Signal>`, commandID, signalTerminate)
+	c.sendE(actionSignal, shellID, nil, body, nil)
+}
+
+// deleteShell deletes the given shell. Errors are ignored, as the host deletes idle shells eventually.
+func (c *client) deleteShell(shellID string) {
+	c.sendE(actionDelete, shellID, nil, "", nil)
+}
+
+// sendE sends a WS-Management request with the given action and body, for the given shell if not empty, and decodes
+// the response into out if not nil. Returns a soapFault if the host responds with a SOAP fault.
+func (c *client) sendE(action string, shellID string, options map[string]string, body string, out interface{}) error {
+	envelope, err := c.envelopeE(action, shellID, options, body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, strings.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/soap+xml;charset=UTF-8")
+	if c.host.ClientCertificate != "" {
+		req.Header.Set("Authorization", certificateAuthorization)
+	} else {
+		req.SetBasicAuth(c.host.Username, c.host.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return AuthFailed{Hostname: c.host.Hostname, Username: c.host.Username}
+	}
+	if resp.StatusCode != http.StatusOK {
+		if fault, ok := parseSoapFault(respBody); ok {
+			return fault
+		}
+		return UnexpectedResponse{Hostname: c.host.Hostname, Action: action, Reason: fmt.Sprintf("status %d: %s", resp.StatusCode, respBody)}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return xml.Unmarshal(respBody, out)
+}
+
+// envelopeE returns the SOAP envelope of a WS-Management request.
+func (c *client) envelopeE(action string, shellID string, options map[string]string, body string) (string, error) {
+	messageID, err := newUUIDE()
+	if err != nil {
+		return "", err
+	}
+
+	var header strings.Builder
+	header.WriteString(`<wsa:To>`)
+	xml.EscapeText(&header, []byte(c.endpoint))
+	header.WriteString(`</wsa:To>`)
+	fmt.Fprintf(&header, `<wsman:ResourceURI s:mustUnderstand="true">%s</wsman:ResourceURI>`, shellResourceURI)
+	header.WriteString(`<wsa:ReplyTo><wsa:Address s:mustUnderstand="true">http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous</wsa:Address></wsa:ReplyTo>`)
+	fmt.Fprintf(&header, `<wsa:Action s:mustUnderstand="true">%s</wsa:Action>`, action)
+	fmt.Fprintf(&header, `<wsa:MessageID>uuid:%s</wsa:MessageID>`, messageID)
+	header.WriteString(`<wsman:MaxEnvelopeSize s:mustUnderstand="true">153600</wsman:MaxEnvelopeSize>`)
+	fmt.Fprintf(&header, `<wsman:OperationTimeout>PT%dS</wsman:OperationTimeout>`, int(c.operationTimeout.Seconds()))
+	header.WriteString(`<wsman:Locale xml:lang="en-US" s:mustUnderstand="false"/>`)
+	if shellID != "" {
+		fmt.Fprintf(&header, `<wsman:SelectorSet><wsman:Selector Name="ShellId">%s</wsman:Selector></wsman:SelectorSet>`, shellID)
+	}
+	if len(options) > 0 {
+		header.WriteString(`<wsman:OptionSet>`)
+		for _, name := range sortedKeys(options) {
+			fmt.Fprintf(&header, `<wsman:Option Name="%s">%s</wsman:Option>`, name, options[name])
+		}
+		header.WriteString(`</wsman:OptionSet>`)
+	}
+
+	return `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"` +
+		` xmlns:wsa="http://schemas.xmlsoap.org/ws/2004/08/addressing"` +
+		` xmlns:wsman="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd"` +
+		` xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell">` +
+		`<s:Header>` + header.String() + `</s:Header>` +
+		`<s:Body>` + body + `</s:Body>` +
+		`</s:Envelope>`, nil
+}
+
+// soapFault is a SOAP fault returned by WinRM.
+type soapFault struct {
+	code   string // The WinRM error code, if any
+	reason string
+}
+
+func (fault soapFault) Error() string {
+	if fault.code != "" {
+		return fmt.Sprintf("WinRM fault %s: %s", fault.code, fault.reason)
+	}
+	return fmt.Sprintf("WinRM fault: %s", fault.reason)
+}
+
+// parseSoapFault parses the SOAP fault in the given response body, if any.
+func parseSoapFault(body []byte) (soapFault, bool) {
+	envelope := struct {
+		Reason string `xml:"Body>Fault>Reason>Text"`
+		Detail struct {
+			Code    string `xml:"Code,attr"`
+			Message string `xml:"Message"`
+		} `xml:"Body>Fault>Detail>WSManFault"`
+	}{}
+	if err := xml.Unmarshal(body, &envelope); err != nil || (envelope.Reason == "" && envelope.Detail.Code == "") {
+		return soapFault{}, false
+	}
+
+	reason := strings.TrimSpace(envelope.Reason)
+	if message := strings.TrimSpace(envelope.Detail.Message); message != "" {
+		reason = message
+	}
+	return soapFault{code: envelope.Detail.Code, reason: reason}, true
+}
+
+// sortedKeys returns the keys of the given map in order, so that requests are deterministic.
+func sortedKeys(values map[string]string) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newUUIDE returns a random (version 4) UUID, used to identify WS-Management messages.
+func newUUIDE() (string, error) {
+	uuid := make([]byte, 16)
+	if _, err := rand.Read(uuid); err != nil {
+		return "", err
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
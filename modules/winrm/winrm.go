@@ -0,0 +1,158 @@
+// Package winrm allows to run commands and fetch files on Windows hosts over WinRM (Windows Remote Management), for
+// testing Windows machines that don't run an SSH server.
+//
+// Commands run in a remote shell created with the WS-Management protocol over HTTPS (or HTTP), authenticating with a
+// password (Basic authentication, which must be enabled on the host) or with a client certificate mapped to a local
+// user:
+//
+//	host := winrm.Host{Hostname: ip, Username: "Administrator", Password: password, InsecureSkipVerify: true}
+//	out := winrm.RunPowerShell(t, host, "Get-Service W32Time | Select-Object -ExpandProperty Status")
+package winrm
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+const (
+	// DefaultHTTPSPort is the port WinRM listens on for HTTPS.
+	DefaultHTTPSPort = 5986
+
+	// DefaultHTTPPort is the port WinRM listens on for HTTP.
+	DefaultHTTPPort = 5985
+
+	// defaultTimeout is how long to wait for each WinRM request by default.
+	defaultTimeout = 60 * time.Second
+)
+
+// Host is a Windows host to connect to over WinRM. Set either Password, for password authentication, or
+// ClientCertificate and ClientKey, for certificate authentication.
+type Host struct {
+	Hostname          string // host name or ip address
+	Port              int    // Defaults to 5986 with HTTPS and 5985 with HTTP
+	Username          string
+	Password          string
+	ClientCertificate string // The PEM-encoded client certificate to authenticate with, mapped to Username on the host
+	ClientKey         string // The PEM-encoded private key of the client certificate
+
+	UseHTTP            bool          // Connect over plain HTTP instead of HTTPS. Only use this on trusted networks, as credentials are sent in the clear.
+	InsecureSkipVerify bool          // Don't verify the certificate of the host, e.g. the self-signed ones WinRM listeners usually use
+	Timeout            time.Duration // How long to wait for each WinRM request. Defaults to 60 seconds.
+}
+
+// endpoint returns the URL of the WS-Management endpoint of the host.
+func (host Host) endpoint() string {
+	scheme := "https"
+	port := DefaultHTTPSPort
+	if host.UseHTTP {
+		scheme = "http"
+		port = DefaultHTTPPort
+	}
+	if host.Port != 0 {
+		port = host.Port
+	}
+	return fmt.Sprintf("%s://%s:%d/wsman", scheme, host.Hostname, port)
+}
+
+// newClientE returns the client for the WinRM requests to the host.
+func (host Host) newClientE() (*client, error) {
+	if host.Password == "" && host.ClientCertificate == "" {
+		return nil, NoCredentials{Hostname: host.Hostname}
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: host.InsecureSkipVerify}
+	if host.ClientCertificate != "" {
+		certificate, err := tls.X509KeyPair([]byte(host.ClientCertificate), []byte(host.ClientKey))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	timeout := host.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	return &client{
+		host:     host,
+		endpoint: host.endpoint(),
+		http: &http.Client{
+			// Leave room for the operation timeout of the requests, after which WinRM responds by itself
+			Timeout:   timeout + 10*time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+		operationTimeout: timeout,
+	}, nil
+}
+
+// RunCommand runs the given command with the given arguments on the given host and returns its stdout. This will fail
+// the test if the command fails or exits with a non-zero code.
+func RunCommand(t testing.TestingT, host Host, command string, args ...string) string {
+	out, err := RunCommandE(t, host, command, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// RunCommandE runs the given command with the given arguments on the given host and returns its stdout. If the command
+// exits with a non-zero code, a CommandFailed error with its stderr is returned.
+func RunCommandE(t testing.TestingT, host Host, command string, args ...string) (string, error) {
+	client, err := host.newClientE()
+	if err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Running command %s on %s@%s over WinRM", command, host.Username, host.Hostname)
+	return client.runE(command, args)
+}
+
+// RunPowerShell runs the given PowerShell script on the given host and returns its stdout. This will fail the test if
+// the script fails.
+func RunPowerShell(t testing.TestingT, host Host, script string) string {
+	out, err := RunPowerShellE(t, host, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// RunPowerShellE runs the given PowerShell script on the given host and returns its stdout. The script stops at the
+// first error, in which case a CommandFailed error with the error is returned.
+func RunPowerShellE(t testing.TestingT, host Host, script string) (string, error) {
+	client, err := host.newClientE()
+	if err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Running PowerShell script on %s@%s over WinRM", host.Username, host.Hostname)
+	return client.runE("powershell.exe", powerShellArgs(script))
+}
+
+// powerShellArgs returns the arguments to run the given script with powershell.exe. The script is passed encoded, so
+// that it needs no quoting.
+func powerShellArgs(script string) []string {
+	// Don't render progress bars, which PowerShell writes to stderr as CLIXML
+	script = "$ErrorActionPreference = 'Stop'; $ProgressPreference = 'SilentlyContinue'; " + script
+
+	// -EncodedCommand takes the script as base64 of UTF-16LE
+	encoded := []byte{}
+	for _, unit := range utf16.Encode([]rune(script)) {
+		encoded = append(encoded, byte(unit), byte(unit>>8))
+	}
+	return []string{"-NoLogo", "-NoProfile", "-NonInteractive", "-EncodedCommand", base64.StdEncoding.EncodeToString(encoded)}
+}
+
+// quotePowerShell returns the given value as a single-quoted PowerShell string literal.
+func quotePowerShell(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
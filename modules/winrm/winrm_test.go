@@ -0,0 +1,154 @@
+package winrm
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWinRM is a WinRM endpoint that runs every command by returning the given stdout and exit code, with one timed out
+// Receive first.
+type fakeWinRM struct {
+	stdout   string
+	exitCode int
+	scripts  []string // The decoded PowerShell scripts of the commands run
+	actions  []string
+	timedOut bool
+}
+
+func (fake *fakeWinRM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, password, ok := r.BasicAuth(); !ok || user != "Administrator" || password != "hunter2" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	request := struct {
+		Action    string   `xml:"Header>Action"`
+		Arguments []string `xml:"Body>CommandLine>Arguments"`
+	}{}
+	if err := xml.Unmarshal(body, &request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	fake.actions = append(fake.actions, request.Action[strings.LastIndex(request.Action, "/")+1:])
+
+	switch request.Action {
+	case actionCreate:
+		fmt.Fprint(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell"><s:Body><rsp:Shell><rsp:ShellId>SHELL-1</rsp:ShellId></rsp:Shell></s:Body></s:Envelope>`)
+	case actionCommand:
+		fake.scripts = append(fake.scripts, decodePowerShellArg(request.Arguments[len(request.Arguments)-1]))
+		fmt.Fprint(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell"><s:Body><rsp:CommandResponse><rsp:CommandId>COMMAND-1</rsp:CommandId></rsp:CommandResponse></s:Body></s:Envelope>`)
+	case actionReceive:
+		if !fake.timedOut {
+			fake.timedOut = true
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Body><s:Fault><s:Reason><s:Text>The WS-Management service cannot complete the operation within the time specified in OperationTimeout.</s:Text></s:Reason><s:Detail><f:WSManFault xmlns:f="http://schemas.microsoft.com/wbem/wsman/1/wsmanfault" Code="%s"/></s:Detail></s:Fault></s:Body></s:Envelope>`, operationTimedOutCode)
+			return
+		}
+		half := len(fake.stdout) / 2
+		fmt.Fprintf(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell"><s:Body><rsp:ReceiveResponse>`+
+			`<rsp:Stream Name="stdout" CommandId="COMMAND-1">%s</rsp:Stream>`+
+			`<rsp:Stream Name="stdout" CommandId="COMMAND-1">%s</rsp:Stream>`+
+			`<rsp:Stream Name="stderr" CommandId="COMMAND-1">%s</rsp:Stream>`+
+			`<rsp:CommandState CommandId="COMMAND-1" State="%s"><rsp:ExitCode>%d</rsp:ExitCode></rsp:CommandState>`+
+			`</rsp:ReceiveResponse></s:Body></s:Envelope>`,
+			base64.StdEncoding.EncodeToString([]byte(fake.stdout[:half])),
+			base64.StdEncoding.EncodeToString([]byte(fake.stdout[half:])),
+			base64.StdEncoding.EncodeToString([]byte("some error")),
+			commandStateDone, fake.exitCode)
+		fake.timedOut = false
+	default:
+		fmt.Fprint(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Body/></s:Envelope>`)
+	}
+}
+
+func decodePowerShellArg(arg string) string {
+	encoded, _ := base64.StdEncoding.DecodeString(arg)
+	units := []uint16{}
+	for i := 0; i+1 < len(encoded); i += 2 {
+		units = append(units, uint16(encoded[i])|uint16(encoded[i+1])<<8)
+	}
+	return string(utf16.Decode(units))
+}
+
+func newFakeHost(t *testing.T, fake *fakeWinRM) Host {
+	server := httptest.NewTLSServer(fake)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	return Host{Hostname: serverURL.Hostname(), Port: port, Username: "Administrator", Password: "hunter2", InsecureSkipVerify: true}
+}
+
+func TestFetchContentsOfFile(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeWinRM{stdout: base64.StdEncoding.EncodeToString([]byte("hello from windows")) + "\r\n"}
+	host := newFakeHost(t, fake)
+
+	contents := FetchContentsOfFile(t, host, `C:\ProgramData\app's\app.log`)
+
+	assert.Equal(t, "hello from windows", contents)
+	assert.Equal(t, []string{"Create", "Command", "Receive", "Receive", "Signal", "Delete"}, fake.actions)
+	require.Len(t, fake.scripts, 1)
+	assert.Contains(t, fake.scripts[0], `[IO.File]::ReadAllBytes('C:\ProgramData\app''s\app.log')`)
+}
+
+func TestRunPowerShellFailed(t *testing.T) {
+	t.Parallel()
+
+	host := newFakeHost(t, &fakeWinRM{exitCode: 1})
+
+	_, err := RunPowerShellE(t, host, "throw 'boom'")
+
+	failed := CommandFailed{}
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, 1, failed.ExitCode)
+	assert.Equal(t, "some error", failed.Stderr)
+}
+
+func TestRunCommandAuthFailed(t *testing.T) {
+	t.Parallel()
+
+	host := newFakeHost(t, &fakeWinRM{})
+	host.Password = "wrong"
+
+	_, err := RunCommandE(t, host, "hostname")
+	assert.Equal(t, AuthFailed{Hostname: host.Hostname, Username: "Administrator"}, err)
+
+	host.Password = ""
+	_, err = RunCommandE(t, host, "hostname")
+	assert.Equal(t, NoCredentials{Hostname: host.Hostname}, err)
+}
+
+func TestListFilesScript(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		`Get-ChildItem -LiteralPath 'C:\logs' -File -Recurse | Where-Object { $_.Name -like '*.log' -or $_.Name -like '*.txt' } | ForEach-Object { $_.FullName }`,
+		listFilesScript(`C:\logs`, []string{"*.log", "*.txt"}))
+	assert.Equal(t, `Get-ChildItem -LiteralPath 'C:\logs' -File -Recurse | ForEach-Object { $_.FullName }`, listFilesScript(`C:\logs`, nil))
+}
+
+func TestHostEndpoint(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "https://10.0.0.1:5986/wsman", Host{Hostname: "10.0.0.1"}.endpoint())
+	assert.Equal(t, "http://10.0.0.1:5985/wsman", Host{Hostname: "10.0.0.1", UseHTTP: true}.endpoint())
+}
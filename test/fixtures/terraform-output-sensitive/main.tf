@@ -0,0 +1,8 @@
+output "password" {
+  value     = "correct horse battery staple"
+  sensitive = true
+}
+
+output "username" {
+  value = "admin"
+}
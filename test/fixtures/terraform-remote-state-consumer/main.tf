@@ -0,0 +1,16 @@
+variable "producer_state_backend" {
+  type = string
+}
+
+variable "producer_state_config" {
+  type = any
+}
+
+data "terraform_remote_state" "producer" {
+  backend = var.producer_state_backend
+  config  = var.producer_state_config
+}
+
+output "producer_name" {
+  value = data.terraform_remote_state.producer.outputs.name
+}
@@ -0,0 +1,8 @@
+variable "name" {
+  type    = string
+  default = "producer"
+}
+
+output "name" {
+  value = var.name
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// An example of how to test the Terraform module in examples/terraform-aws-client-vpn-example using Terratest. This
+// connects to the Client VPN endpoint with OpenVPN, so it must run with the privileges to create a tun device.
+func TestTerraformAwsClientVpnExample(t *testing.T) {
+	t.Parallel()
+
+	// Client VPN is not available in every region, so pick one of the regions that has it
+	region := aws.GetRandomStableRegion(t, []string{"us-east-1", "us-east-2", "us-west-2", "eu-west-1"}, nil)
+	instanceType := aws.GetRecommendedInstanceType(t, region, []string{"t2.micro", "t3.micro"})
+	name := fmt.Sprintf("terratest-client-vpn-%s", random.UniqueId())
+
+	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
+		TerraformDir: "../examples/terraform-aws-client-vpn-example",
+		Vars: map[string]interface{}{
+			"region":        region,
+			"name":          name,
+			"instance_type": instanceType,
+		},
+	})
+	defer terraform.Destroy(t, terraformOptions)
+
+	terraform.InitAndApply(t, terraformOptions)
+
+	endpointID := terraform.Output(t, terraformOptions, "client_vpn_endpoint_id")
+	clientCertificate := terraform.Output(t, terraformOptions, "client_certificate_pem")
+	clientPrivateKey := terraform.Output(t, terraformOptions, "client_private_key_pem")
+	instancePrivateIP := terraform.Output(t, terraformOptions, "instance_private_ip")
+
+	// Associating the endpoint with a subnet takes several minutes
+	aws.WaitForClientVpnEndpointAvailable(t, region, endpointID, 60, 10*time.Second)
+
+	configuration := aws.GetClientVpnClientConfiguration(t, region, endpointID)
+	configuration = aws.AddClientCertificateToOpenVpnConfig(configuration, clientCertificate, clientPrivateKey)
+
+	connection := aws.ConnectToClientVpn(t, configuration, 2*time.Minute)
+	defer connection.Close()
+
+	// The instance has no public IP, so it's only reachable through the VPN
+	aws.WaitForTcpReachableOverClientVpn(t, fmt.Sprintf("%s:22", instancePrivateIP), 30, 10*time.Second)
+}